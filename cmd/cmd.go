@@ -2,11 +2,13 @@ package cmd
 
 import (
 	"os"
+	"path/filepath"
 
 	"fmt"
 
 	"github.com/kopecmaciej/vi-mongo/internal/config"
 	"github.com/kopecmaciej/vi-mongo/internal/tui"
+	"github.com/kopecmaciej/vi-mongo/internal/util"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 	"github.com/spf13/cobra"
@@ -19,19 +21,33 @@ var (
 	debug          bool
 	welcomePage    bool
 	connectionPage bool
-	rootCmd        = &cobra.Command{
-		Use:   "vi-mongo",
-		Short: "MongoDB TUI client",
-		Long:  `A Terminal User Interface (TUI) client for MongoDB`,
-		Run:   runApp,
+	accessible     bool
+	reducedMotion  bool
+	configDir      string
+	portable       bool
+	profile        string
+	jsonErrors     bool
+	// portableMode is true if config is stored next to the binary
+	portableMode bool
+	rootCmd      = &cobra.Command{
+		Use:               "vi-mongo",
+		Short:             "MongoDB TUI client",
+		Long:              `A Terminal User Interface (TUI) client for MongoDB`,
+		PersistentPreRunE: setConfigDir,
+		Run:               runApp,
+		// errors are written by Execute, as JSON if it's requested
+		SilenceErrors: true,
 	}
 
 	version = "v0.0.0"
 )
 
+// Execute runs the command and writes its error to stderr,
+// ExitCode of the error is the code the process exits with
 func Execute() error {
 	err := rootCmd.Execute()
 	if err != nil {
+		writeError(rootCmd.ErrOrStderr(), err, jsonErrors)
 		return err
 	}
 	return nil
@@ -39,10 +55,36 @@ func Execute() error {
 
 func init() {
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.config/vi-mongo/config.yaml)")
+	rootCmd.PersistentFlags().StringVar(&configDir, "config-dir", "", fmt.Sprintf("directory of all config files, can be set with %s", util.ConfigDirEnv))
+	rootCmd.PersistentFlags().StringVar(&profile, "profile", "", fmt.Sprintf("profile with its own connections, history and keybindings, can be set with %s", util.ProfileEnv))
+	rootCmd.PersistentFlags().BoolVar(&portable, "portable", false, fmt.Sprintf("store config files in %s next to the binary, on if the directory exists", util.PortableDir))
+	rootCmd.PersistentFlags().BoolVar(&jsonErrors, "json-errors", false, "write errors to stderr as JSON objects with the kind and the exit code")
+	rootCmd.SetFlagErrorFunc(func(cmd *cobra.Command, err error) error {
+		return withExitCode(ExitUsage, err)
+	})
+	_ = rootCmd.RegisterFlagCompletionFunc("profile", completeProfiles)
+	_ = rootCmd.MarkPersistentFlagFilename("config", "yaml", "yml")
+	_ = rootCmd.MarkPersistentFlagDirname("config-dir")
 	rootCmd.Flags().BoolVar(&showVersion, "version", false, "Show version")
 	rootCmd.Flags().BoolVar(&debug, "debug", false, "Enable debug mode")
 	rootCmd.Flags().BoolVar(&welcomePage, "welcome-page", false, "Show welcome page on startup")
 	rootCmd.Flags().BoolVar(&connectionPage, "connection-page", false, "Show connection page on startup")
+	rootCmd.Flags().BoolVar(&accessible, "accessible", false, "High contrast and screen reader friendly mode")
+	rootCmd.Flags().BoolVar(&reducedMotion, "reduced-motion", false, "Redraw the screen as rarely as possible, e.g. over SSH")
+}
+
+// setConfigDir points config files at the directory and the profile
+// from the flags, before anything is loaded
+func setConfigDir(cmd *cobra.Command, args []string) error {
+	var err error
+	portableMode, err = util.ConfigureConfigDir(configDir, portable)
+	if err != nil {
+		return err
+	}
+	if profile == "" {
+		profile = os.Getenv(util.ProfileEnv)
+	}
+	return util.SetProfile(profile)
 }
 
 func runApp(cmd *cobra.Command, args []string) {
@@ -82,6 +124,10 @@ func runApp(cmd *cobra.Command, args []string) {
 			cfg.ShowWelcomePage = welcomePage
 		case "connection-page":
 			cfg.ShowConnectionPage = connectionPage
+		case "accessible":
+			cfg.SetAccessibleFlag(accessible)
+		case "reduced-motion":
+			cfg.SetReducedMotionFlag(reducedMotion)
 		}
 	})
 
@@ -90,7 +136,7 @@ func runApp(cmd *cobra.Command, args []string) {
 		logLevel = zerolog.DebugLevel
 	}
 
-	logFile := logging(cfg.Log.Path, logLevel, cfg.Log.PrettyPrint)
+	logFile := logging(logFilePath(cfg), logLevel, cfg.Log.PrettyPrint)
 	defer func() {
 		err := logFile.Close()
 		if err != nil {
@@ -103,22 +149,39 @@ func runApp(cmd *cobra.Command, args []string) {
 	}
 	log.Info().Msg("Mongo UI started")
 
+	if err := cfg.MigratePasswords(); err != nil {
+		log.Error().Err(err).Msg("Error moving passwords out of the config file")
+	}
+
 	if os.Getenv("ENV") == "vi-dev" {
 		log.Info().Msg("Dev mode enabled, keys and styles will be loaded from default values")
 	}
 
 	app := tui.NewApp(cfg)
+	app.SetVersion(version)
 	err = app.Init()
 	if err != nil {
 		log.Fatal().Err(err).Msg("Error initializing app")
 	}
 	app.Render()
 	err = app.Run()
+	app.Shutdown()
 	if err != nil {
 		log.Fatal().Err(err).Msg("Error running app")
 	}
 }
 
+// logFilePath returns the path of the log file, the default one is kept
+// in the portable directory in portable mode, so nothing is left outside
+func logFilePath(cfg *config.Config) string {
+	if portableMode && cfg.Log.Path == config.LogPath {
+		if dir, err := util.GetConfigDir(); err == nil {
+			return filepath.Join(dir, "vi-mongo.log")
+		}
+	}
+	return cfg.Log.Path
+}
+
 func logging(path string, logLevel zerolog.Level, pretty bool) *os.File {
 	logFile, err := os.OpenFile(path, os.O_APPEND|os.O_RDWR|os.O_CREATE, 0644)
 	if err != nil {
@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"strings"
+
+	"github.com/kopecmaciej/vi-mongo/internal/config"
+	"github.com/kopecmaciej/vi-mongo/internal/util"
+	"github.com/spf13/cobra"
+)
+
+// Shell completions are generated by the "completion" command cobra adds
+// to the root, e.g. "vi-mongo completion zsh". Functions below complete
+// values of flags from the config, they're called by the generated script.
+
+// completeConnections completes names of saved connections,
+// hosts are shown as descriptions in shells that support them
+func completeConnections(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	// hooks don't run while completing, so the config dir is set here
+	if err := setConfigDir(cmd, args); err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	var names []string
+	for _, conn := range cfg.Connections {
+		if !strings.HasPrefix(conn.Name, toComplete) {
+			continue
+		}
+		// uri isn't shown, as it may contain the password
+		description := conn.Host
+		if conn.Uri != "" {
+			description = "uri"
+		}
+		names = append(names, conn.Name+"\t"+description)
+	}
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeProfiles completes names of existing profiles
+func completeProfiles(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if _, err := util.ConfigureConfigDir(configDir, portable); err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	profiles, err := util.ListProfiles()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	var names []string
+	for _, name := range profiles {
+		if strings.HasPrefix(name, toComplete) {
+			names = append(names, name)
+		}
+	}
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
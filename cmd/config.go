@@ -0,0 +1,246 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+
+	"github.com/kopecmaciej/vi-mongo/internal/config"
+	"github.com/kopecmaciej/vi-mongo/internal/util"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	configTarget      = "config"
+	keybindingsTarget = "keybindings"
+	styleTarget       = "style"
+)
+
+var (
+	configCmd = &cobra.Command{
+		Use:   "config",
+		Short: "Inspect and manage configuration files",
+	}
+
+	configShowCmd = &cobra.Command{
+		Use:       "show [config|keybindings|style]",
+		Short:     "Print the effective configuration merged with defaults",
+		Args:      cobra.MatchAll(cobra.MaximumNArgs(1), cobra.OnlyValidArgs),
+		ValidArgs: []string{configTarget, keybindingsTarget, styleTarget},
+		RunE:      runConfigShow,
+	}
+
+	configPathCmd = &cobra.Command{
+		Use:   "path",
+		Short: "Print paths of configuration files",
+		Args:  cobra.NoArgs,
+		RunE:  runConfigPath,
+	}
+
+	configEditCmd = &cobra.Command{
+		Use:       "edit [config|keybindings|style]",
+		Short:     "Open configuration file in the editor",
+		Args:      cobra.MatchAll(cobra.MaximumNArgs(1), cobra.OnlyValidArgs),
+		ValidArgs: []string{configTarget, keybindingsTarget, styleTarget},
+		RunE:      runConfigEdit,
+	}
+
+	configResetCmd = &cobra.Command{
+		Use:   "reset <config|keybindings> [section]",
+		Short: "Reset configuration section to default values",
+		Long: `Reset configuration section to default values.
+Without section, all keybindings are reset, for config section is required,
+e.g. "vi-mongo config reset config log" or "vi-mongo config reset keybindings history".
+Saved connections can't be reset.`,
+		Args:      cobra.MatchAll(cobra.RangeArgs(1, 2), validResetTarget),
+		ValidArgs: []string{configTarget, keybindingsTarget},
+		RunE:      runConfigReset,
+	}
+)
+
+func init() {
+	configCmd.AddCommand(configShowCmd, configPathCmd, configEditCmd, configResetCmd)
+	rootCmd.AddCommand(configCmd)
+}
+
+func runConfigShow(cmd *cobra.Command, args []string) error {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return err
+	}
+
+	var out []byte
+	switch targetArg(args) {
+	case keybindingsTarget:
+		keys, err := config.LoadKeybindings()
+		if err != nil {
+			return err
+		}
+		out, err = json.MarshalIndent(keys, "", "  ")
+		if err != nil {
+			return err
+		}
+		out = append(out, '\n')
+	case styleTarget:
+		styles, err := config.LoadStyles(cfg.Styles.CurrentStyle, cfg.Styles.BetterSymbols)
+		if err != nil {
+			return err
+		}
+		out, err = yaml.Marshal(styles)
+		if err != nil {
+			return err
+		}
+	default:
+		out, err = yaml.Marshal(cfg)
+		if err != nil {
+			return err
+		}
+	}
+
+	// unknown keys don't stop files from loading, but may be typos
+	for _, warning := range util.ConfigWarnings() {
+		fmt.Fprintf(cmd.ErrOrStderr(), "warning: %s\n", warning)
+	}
+
+	_, err = cmd.OutOrStdout().Write(out)
+	return err
+}
+
+func runConfigPath(cmd *cobra.Command, args []string) error {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return err
+	}
+
+	configDir, err := util.GetConfigDir()
+	if err != nil {
+		return err
+	}
+	configPath, err := config.GetConfigPath()
+	if err != nil {
+		return err
+	}
+	keybindingsPath, err := config.GetKeybindingsPath()
+	if err != nil {
+		return err
+	}
+	stylePath, err := config.GetStylePath(cfg.Styles.CurrentStyle)
+	if err != nil {
+		return err
+	}
+	historyPath, err := config.GetHistoryPath()
+	if err != nil {
+		return err
+	}
+	sortsPath, err := config.GetSortsPath()
+	if err != nil {
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+	fmt.Fprintf(out, "profile:     %s\n", util.GetProfile())
+	fmt.Fprintf(out, "config dir:  %s\n", configDir)
+	fmt.Fprintf(out, "config:      %s\n", configPath)
+	fmt.Fprintf(out, "keybindings: %s\n", keybindingsPath)
+	fmt.Fprintf(out, "style:       %s\n", stylePath)
+	fmt.Fprintf(out, "history:     %s\n", historyPath)
+	fmt.Fprintf(out, "sorts:       %s\n", sortsPath)
+	fmt.Fprintf(out, "log:         %s\n", logFilePath(cfg))
+
+	return nil
+}
+
+func runConfigEdit(cmd *cobra.Command, args []string) error {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		// the config may be the invalid file that needs fixing
+		fmt.Fprintln(cmd.ErrOrStderr(), err)
+		cfg = &config.Config{Editor: config.EditorConfig{Env: "EDITOR"}}
+	}
+
+	var path string
+	switch targetArg(args) {
+	case keybindingsTarget:
+		path, err = config.GetKeybindingsPath()
+		if err != nil {
+			return err
+		}
+		// make sure the file exists before it's opened
+		if _, statErr := os.Stat(path); os.IsNotExist(statErr) {
+			err = config.ResetKeybindings("")
+		}
+	case styleTarget:
+		path, err = config.GetStylePath(cfg.Styles.CurrentStyle)
+	default:
+		path, err = config.GetConfigPath()
+	}
+	if err != nil {
+		return err
+	}
+
+	ed, err := cfg.GetEditorCmd()
+	if err != nil {
+		return fmt.Errorf("error getting editor command: %w", err)
+	}
+	editor, err := exec.LookPath(ed)
+	if err != nil {
+		return fmt.Errorf("error looking for editor: %w", err)
+	}
+
+	editCmd := exec.Command(editor, path)
+	editCmd.Stdin = os.Stdin
+	editCmd.Stdout = os.Stdout
+	editCmd.Stderr = os.Stderr
+
+	return editCmd.Run()
+}
+
+func runConfigReset(cmd *cobra.Command, args []string) error {
+	section := ""
+	if len(args) > 1 {
+		section = args[1]
+	}
+
+	switch args[0] {
+	case keybindingsTarget:
+		if err := config.ResetKeybindings(section); err != nil {
+			return err
+		}
+	default:
+		if section == "" {
+			return fmt.Errorf("section is required when resetting config")
+		}
+		cfg, err := config.LoadConfig()
+		if err != nil {
+			// invalid file is read without validation while resetting,
+			// so the broken section can be fixed
+			cfg = &config.Config{}
+		}
+		if err := cfg.ResetSection(section); err != nil {
+			return err
+		}
+	}
+
+	if section == "" {
+		section = "all"
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "%s: %s reset to defaults\n", args[0], section)
+
+	return nil
+}
+
+func validResetTarget(cmd *cobra.Command, args []string) error {
+	if args[0] != configTarget && args[0] != keybindingsTarget {
+		return fmt.Errorf("invalid argument %q, expected config or keybindings", args[0])
+	}
+	return nil
+}
+
+func targetArg(args []string) string {
+	if len(args) == 0 {
+		return configTarget
+	}
+	return args[0]
+}
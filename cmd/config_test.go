@@ -0,0 +1,30 @@
+package cmd
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/kopecmaciej/vi-mongo/internal/config"
+	"github.com/kopecmaciej/vi-mongo/internal/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogFilePath(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, util.SetConfigDir(dir))
+	t.Cleanup(func() {
+		util.SetConfigDir("")
+		portableMode = false
+	})
+	cfg := &config.Config{Log: config.LogConfig{Path: config.LogPath}}
+
+	assert.Equal(t, config.LogPath, logFilePath(cfg))
+
+	portableMode = true
+	assert.Equal(t, filepath.Join(dir, "vi-mongo.log"), logFilePath(cfg))
+
+	// log path set in the config is kept
+	cfg.Log.Path = "/var/log/vi-mongo.log"
+	assert.Equal(t, "/var/log/vi-mongo.log", logFilePath(cfg))
+}
@@ -0,0 +1,207 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/kopecmaciej/vi-mongo/internal/config"
+	"github.com/kopecmaciej/vi-mongo/internal/mongo"
+	"github.com/rs/zerolog"
+	"github.com/spf13/cobra"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var (
+	execConnection string
+	execDatabase   string
+	execCollection string
+	execFilter     string
+	execSort       string
+	execPipeline   string
+	execLimit      int64
+	execFormat     string
+	execAllowEmpty bool
+
+	execCmd = &cobra.Command{
+		Use:   "exec",
+		Short: "Run the query without the TUI and print its results",
+		Long: `Run the query, or the aggregation pipeline, on the collection and print
+all results to stdout as NDJSON or a JSON array, e.g.
+
+  vi-mongo exec --db shop --collection orders --filter '{ "status": "new" }'
+
+The filter or the pipeline is read from stdin when it's set to "-". When neither
+is set and the input is piped, it's read as the pipeline if it starts with "[",
+otherwise as the filter, e.g.
+
+  cat pipeline.json | vi-mongo exec --db shop --collection orders
+
+Exit codes: 0 success, 1 other error, 2 invalid usage, 3 connection failure,
+4 authentication failure, 5 query error, 6 no results.`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			return withExitCode(ExitUsage, cobra.NoArgs(cmd, args))
+		},
+		RunE:         runExec,
+		SilenceUsage: true,
+	}
+)
+
+func init() {
+	execCmd.Flags().StringVar(&execConnection, "connection", "", "name of the connection (default is the current connection)")
+	execCmd.Flags().StringVarP(&execDatabase, "db", "d", "", "database of the collection (default is the database of the connection)")
+	execCmd.Flags().StringVarP(&execCollection, "collection", "c", "", "collection the query is run on")
+	execCmd.Flags().StringVar(&execFilter, "filter", "{}", "filter of the query, - to read it from stdin")
+	execCmd.Flags().StringVar(&execSort, "sort", "", "sort of the query")
+	execCmd.Flags().StringVar(&execPipeline, "pipeline", "", "aggregation pipeline run instead of the filter and sort, - to read it from stdin")
+	execCmd.Flags().Int64VarP(&execLimit, "limit", "l", 0, "maximum number of documents, 0 for all")
+	execCmd.Flags().StringVarP(&execFormat, "format", "f", "ndjson", "output format, ndjson or json")
+	execCmd.Flags().BoolVar(&execAllowEmpty, "allow-empty", false, "exit with 0 instead of 6 when there are no results")
+	_ = execCmd.RegisterFlagCompletionFunc("connection", completeConnections)
+	_ = execCmd.RegisterFlagCompletionFunc("format", cobra.FixedCompletions([]string{"ndjson", "json"}, cobra.ShellCompDirectiveNoFileComp))
+	rootCmd.AddCommand(execCmd)
+}
+
+func runExec(cmd *cobra.Command, args []string) error {
+	// stderr is left for errors, which scripts may parse
+	zerolog.SetGlobalLevel(zerolog.Disabled)
+
+	if execCollection == "" {
+		return withExitCode(ExitUsage, fmt.Errorf("collection not set, use --collection"))
+	}
+	format := mongo.FormatNDJSON
+	switch execFormat {
+	case "ndjson":
+	case "json":
+		format = mongo.FormatJSON
+	default:
+		return withExitCode(ExitUsage, fmt.Errorf("invalid format %q, expected ndjson or json", execFormat))
+	}
+	if execLimit < 0 {
+		return withExitCode(ExitUsage, fmt.Errorf("limit can't be negative"))
+	}
+
+	filterText, pipelineText := execFilter, execPipeline
+	if !cmd.Flags().Changed("filter") && pipelineText == "" && stdinPiped() {
+		filterText, pipelineText = stdinQuery, stdinQuery
+	}
+	filterText, pipelineText, err := readQueryInput(cmd.InOrStdin(), filterText, pipelineText)
+	if err != nil {
+		return withExitCode(ExitUsage, err)
+	}
+
+	var filter primitive.M
+	var sort primitive.D
+	var pipeline mongo.Pipeline
+	if pipelineText != "" {
+		pipeline, err = mongo.ParseShellPipeline(pipelineText)
+	} else {
+		filter, err = mongo.ParseStringQuery(filterText)
+		if err == nil {
+			sort, err = mongo.ParseStringSort(execSort)
+		}
+	}
+	if err != nil {
+		return withExitCode(ExitQuery, err)
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return err
+	}
+	conn := cfg.GetCurrentConnection()
+	if execConnection != "" {
+		conn = cfg.GetConnection(execConnection)
+	}
+	if conn == nil {
+		return withExitCode(ExitUsage, fmt.Errorf("connection not found, use --connection or set the current connection"))
+	}
+	db := execDatabase
+	if db == "" {
+		db = conn.Database
+	}
+	if db == "" {
+		return withExitCode(ExitUsage, fmt.Errorf("database not set, use --db"))
+	}
+
+	client := mongo.NewClient(conn)
+	if err := client.Connect(); err != nil {
+		return withExitCode(ExitConnection, err)
+	}
+	ctx := context.Background()
+	defer client.Close(ctx)
+	// connection and credentials are checked first, so their
+	// errors aren't reported as errors of the query
+	if err := client.Ping(); err != nil {
+		return databaseError(err, ExitConnection)
+	}
+
+	out := bufio.NewWriter(cmd.OutOrStdout())
+	stream, err := mongo.NewDocumentStream(out, format)
+	if err != nil {
+		return err
+	}
+	state := &mongo.CollectionState{Db: db, Coll: execCollection, Limit: execLimit}
+	err = mongo.NewDao(client.Client, conn).StreamDocuments(ctx, state, filter, sort, pipeline, stream.Write)
+	if err != nil {
+		out.Flush()
+		return databaseError(err, ExitQuery)
+	}
+	if err := stream.Close(); err != nil {
+		return err
+	}
+	if err := out.Flush(); err != nil {
+		return err
+	}
+
+	if stream.Count() == 0 && !execAllowEmpty {
+		return withExitCode(ExitNoResults, fmt.Errorf("no documents found in %s.%s", db, execCollection))
+	}
+	return nil
+}
+
+// stdinQuery is the value of --filter or --pipeline read from stdin
+const stdinQuery = "-"
+
+// stdinPiped returns true if stdin is a pipe or a file, not the terminal
+func stdinPiped() bool {
+	stat, err := os.Stdin.Stat()
+	return err == nil && stat.Mode()&os.ModeCharDevice == 0
+}
+
+// readQueryInput returns the filter and the pipeline, the one which is "-"
+// is read from stdin. If both are, stdin is the pipeline if it starts
+// with "[", otherwise the filter, empty stdin leaves the filter empty.
+func readQueryInput(stdin io.Reader, filter, pipeline string) (string, string, error) {
+	if filter != stdinQuery && pipeline != stdinQuery {
+		return filter, pipeline, nil
+	}
+	input, err := io.ReadAll(stdin)
+	if err != nil {
+		return "", "", fmt.Errorf("reading query from stdin: %w", err)
+	}
+	text := strings.TrimSpace(string(input))
+
+	switch {
+	case filter == stdinQuery && pipeline == stdinQuery:
+		if strings.HasPrefix(text, "[") {
+			return "{}", text, nil
+		}
+		if text == "" {
+			text = "{}"
+		}
+		return text, "", nil
+	case pipeline == stdinQuery:
+		if text == "" {
+			return "", "", fmt.Errorf("no pipeline on stdin")
+		}
+		return filter, text, nil
+	}
+	if text == "" {
+		return "", "", fmt.Errorf("no filter on stdin")
+	}
+	return text, pipeline, nil
+}
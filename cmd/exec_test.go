@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadQueryInput(t *testing.T) {
+	filter, pipeline, err := readQueryInput(strings.NewReader("ignored"), `{ "a": 1 }`, "")
+	assert.NoError(t, err)
+	assert.Equal(t, `{ "a": 1 }`, filter)
+	assert.Equal(t, "", pipeline)
+
+	filter, pipeline, err = readQueryInput(strings.NewReader("  { \"status\": \"new\" }\n"), stdinQuery, "")
+	assert.NoError(t, err)
+	assert.Equal(t, `{ "status": "new" }`, filter)
+	assert.Equal(t, "", pipeline)
+
+	filter, pipeline, err = readQueryInput(strings.NewReader(`[{ "$match": {} }]`), "{}", stdinQuery)
+	assert.NoError(t, err)
+	assert.Equal(t, "{}", filter)
+	assert.Equal(t, `[{ "$match": {} }]`, pipeline)
+
+	// piped input is detected by its first character
+	filter, pipeline, err = readQueryInput(strings.NewReader("\n[{ \"$limit\": 1 }]"), stdinQuery, stdinQuery)
+	assert.NoError(t, err)
+	assert.Equal(t, "{}", filter)
+	assert.Equal(t, `[{ "$limit": 1 }]`, pipeline)
+
+	filter, pipeline, err = readQueryInput(strings.NewReader(""), stdinQuery, stdinQuery)
+	assert.NoError(t, err)
+	assert.Equal(t, "{}", filter)
+	assert.Equal(t, "", pipeline)
+
+	_, _, err = readQueryInput(strings.NewReader(" \n"), stdinQuery, "")
+	assert.Error(t, err)
+}
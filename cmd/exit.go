@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/kopecmaciej/vi-mongo/internal/mongo"
+)
+
+// Exit codes of commands run without the TUI, they don't change
+// between versions, so scripts can react to failures
+const (
+	ExitOK         = 0
+	ExitError      = 1
+	ExitUsage      = 2
+	ExitConnection = 3
+	ExitAuth       = 4
+	ExitQuery      = 5
+	ExitNoResults  = 6
+)
+
+// exitKinds are names of exit codes in machine-readable errors
+var exitKinds = map[int]string{
+	ExitError:      "error",
+	ExitUsage:      "usage",
+	ExitConnection: "connection",
+	ExitAuth:       "auth",
+	ExitQuery:      "query",
+	ExitNoResults:  "no_results",
+}
+
+// exitError is the error with the exit code of the process
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string {
+	return e.err.Error()
+}
+
+func (e *exitError) Unwrap() error {
+	return e.err
+}
+
+func withExitCode(code int, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &exitError{code: code, err: err}
+}
+
+// databaseError sets the exit code of the error returned by the server,
+// errors of connecting and of credentials get their own codes
+func databaseError(err error, code int) error {
+	switch {
+	case err == nil:
+		return nil
+	case mongo.IsAuthError(err):
+		return withExitCode(ExitAuth, err)
+	case mongo.IsConnectionError(err):
+		return withExitCode(ExitConnection, err)
+	}
+	return withExitCode(code, err)
+}
+
+// ExitCode returns the code the process exits with after the error
+func ExitCode(err error) int {
+	if err == nil {
+		return ExitOK
+	}
+	var exitErr *exitError
+	if errors.As(err, &exitErr) {
+		return exitErr.code
+	}
+	return ExitError
+}
+
+// writeError writes the error as cobra does, or as a JSON object
+// with its kind and exit code if errors are machine-readable
+func writeError(w io.Writer, err error, asJSON bool) {
+	code := ExitCode(err)
+	if !asJSON {
+		fmt.Fprintf(w, "Error: %s\n", err)
+		return
+	}
+	jsoned, marshalErr := json.Marshal(struct {
+		Error    string `json:"error"`
+		Message  string `json:"message"`
+		ExitCode int    `json:"exitCode"`
+	}{exitKinds[code], err.Error(), code})
+	if marshalErr != nil {
+		fmt.Fprintf(w, "Error: %s\n", err)
+		return
+	}
+	fmt.Fprintf(w, "%s\n", jsoned)
+}
@@ -0,0 +1,34 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestExitCode(t *testing.T) {
+	assert.Equal(t, ExitOK, ExitCode(nil))
+	assert.Equal(t, ExitError, ExitCode(errors.New("failed")))
+	assert.Equal(t, ExitNoResults, ExitCode(withExitCode(ExitNoResults, errors.New("empty"))))
+	assert.Equal(t, ExitUsage, ExitCode(fmt.Errorf("wrapped: %w", withExitCode(ExitUsage, errors.New("bad flag")))))
+	assert.Nil(t, withExitCode(ExitQuery, nil))
+
+	assert.Equal(t, ExitAuth, ExitCode(databaseError(mongo.CommandError{Code: 18}, ExitQuery)))
+	assert.Equal(t, ExitQuery, ExitCode(databaseError(mongo.CommandError{Code: 2}, ExitQuery)))
+}
+
+func TestWriteError(t *testing.T) {
+	err := withExitCode(ExitQuery, errors.New(`unknown operator "$foo"`))
+
+	var buf bytes.Buffer
+	writeError(&buf, err, false)
+	assert.Equal(t, "Error: unknown operator \"$foo\"\n", buf.String())
+
+	buf.Reset()
+	writeError(&buf, err, true)
+	assert.JSONEq(t, `{"error":"query","message":"unknown operator \"$foo\"","exitCode":5}`, buf.String())
+}
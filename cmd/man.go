@@ -0,0 +1,133 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/kopecmaciej/vi-mongo/internal/util"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+var (
+	manOutput string
+
+	manCmd = &cobra.Command{
+		Use:   "man",
+		Short: "Generate the man page",
+		Long: `Generate the man page of vi-mongo with all its commands, written to stdout
+or to vi-mongo.1 in the directory, e.g. "vi-mongo man -o ~/.local/share/man/man1".`,
+		Args: cobra.NoArgs,
+		RunE: runMan,
+	}
+)
+
+func init() {
+	manCmd.Flags().StringVarP(&manOutput, "output", "o", "", "directory the page is written to (default is stdout)")
+	_ = manCmd.MarkFlagDirname("output")
+	rootCmd.AddCommand(manCmd)
+}
+
+func runMan(cmd *cobra.Command, args []string) error {
+	var out bytes.Buffer
+	if err := writeManPage(&out, cmd.Root(), time.Now()); err != nil {
+		return err
+	}
+
+	if manOutput == "" {
+		_, err := cmd.OutOrStdout().Write(out.Bytes())
+		return err
+	}
+	if err := os.MkdirAll(manOutput, 0755); err != nil {
+		return err
+	}
+	path := filepath.Join(manOutput, cmd.Root().Name()+".1")
+	if err := util.WriteFileAtomic(path, out.Bytes(), 0644); err != nil {
+		return err
+	}
+	fmt.Fprintf(cmd.ErrOrStderr(), "man page written to %s\n", path)
+	return nil
+}
+
+// writeManPage writes the roff man page of the root command,
+// every available subcommand has its own subsection
+func writeManPage(w io.Writer, root *cobra.Command, date time.Time) error {
+	name := root.Name()
+	var page strings.Builder
+	fmt.Fprintf(&page, ".TH %q 1 %q %q \"User Commands\"\n", strings.ToUpper(name), date.Format("January 2006"), name+" "+version)
+	fmt.Fprintf(&page, ".SH NAME\n%s \\- %s\n", name, roffEscape(root.Short))
+	fmt.Fprintf(&page, ".SH SYNOPSIS\n.B %s\n[command] [flags]\n", name)
+	fmt.Fprintf(&page, ".SH DESCRIPTION\n%s\n", roffEscape(description(root)))
+
+	page.WriteString(".SH OPTIONS\n")
+	writeManFlags(&page, root.NonInheritedFlags())
+
+	page.WriteString(".SH COMMANDS\n")
+	var walk func(cmd *cobra.Command)
+	walk = func(cmd *cobra.Command) {
+		for _, sub := range cmd.Commands() {
+			if !sub.IsAvailableCommand() || sub.IsAdditionalHelpTopicCommand() {
+				continue
+			}
+			// scripts of every shell are described in SEE ALSO
+			if sub.Name() == "completion" {
+				fmt.Fprintf(&page, ".SS %s\n%s\n", roffEscape(sub.UseLine()), roffEscape(sub.Short))
+				continue
+			}
+			fmt.Fprintf(&page, ".SS %s\n%s\n", roffEscape(sub.UseLine()), roffEscape(description(sub)))
+			writeManFlags(&page, sub.LocalNonPersistentFlags())
+			walk(sub)
+		}
+	}
+	walk(root)
+
+	page.WriteString(".SH FILES\n")
+	fmt.Fprintf(&page, "Config files are stored in the user config directory, in $%s if it's set.\n", util.ConfigDirEnv)
+	page.WriteString(".SH SEE ALSO\n")
+	fmt.Fprintf(&page, "Shell completions are generated with \\fB%s completion bash|zsh|fish\\fR.\n", roffEscape(name))
+
+	_, err := io.WriteString(w, page.String())
+	return err
+}
+
+// writeManFlags writes every visible flag as a tagged paragraph
+func writeManFlags(page *strings.Builder, flags *pflag.FlagSet) {
+	flags.VisitAll(func(flag *pflag.Flag) {
+		if flag.Hidden || flag.Name == "help" {
+			return
+		}
+		varname, usage := pflag.UnquoteUsage(flag)
+		tag := "\\fB\\-\\-" + roffEscape(flag.Name) + "\\fR"
+		if flag.Shorthand != "" {
+			tag = "\\fB\\-" + flag.Shorthand + "\\fR, " + tag
+		}
+		if varname != "" {
+			tag += " \\fI" + varname + "\\fR"
+		}
+		fmt.Fprintf(page, ".TP\n%s\n%s\n", tag, roffEscape(usage))
+	})
+}
+
+func description(cmd *cobra.Command) string {
+	if cmd.Long != "" {
+		return strings.TrimSpace(cmd.Long)
+	}
+	return cmd.Short
+}
+
+// roffEscape escapes text, so it's not read as roff requests or escapes
+func roffEscape(text string) string {
+	text = strings.NewReplacer(`\`, `\e`, "-", `\-`).Replace(text)
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		if strings.HasPrefix(line, ".") || strings.HasPrefix(line, "'") {
+			lines[i] = `\&` + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,31 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteManPage(t *testing.T) {
+	root := &cobra.Command{Use: "tool", Short: "Test tool", Run: func(*cobra.Command, []string) {}}
+	root.Flags().Bool("debug", false, "Enable debug mode")
+	sub := &cobra.Command{Use: "dump", Short: "Dump data", Long: ".hidden-looking line", Run: func(*cobra.Command, []string) {}}
+	sub.Flags().StringP("output", "o", "", "output `file`")
+	hidden := &cobra.Command{Use: "secret", Hidden: true, Run: func(*cobra.Command, []string) {}}
+	root.AddCommand(sub, hidden)
+
+	var buf bytes.Buffer
+	require.NoError(t, writeManPage(&buf, root, time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)))
+	page := buf.String()
+
+	assert.Contains(t, page, `.TH "TOOL" 1 "May 2024"`)
+	assert.Contains(t, page, "tool \\- Test tool\n")
+	assert.Contains(t, page, ".TP\n\\fB\\-\\-debug\\fR\nEnable debug mode\n")
+	assert.Contains(t, page, ".SS tool dump [flags]\n\\&.hidden\\-looking line\n")
+	assert.Contains(t, page, ".TP\n\\fB\\-o\\fR, \\fB\\-\\-output\\fR \\fIfile\\fR\noutput file\n")
+	assert.NotContains(t, page, "secret")
+}
@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/kopecmaciej/vi-mongo/internal/config"
+	"github.com/kopecmaciej/vi-mongo/internal/mongo"
+	"github.com/kopecmaciej/vi-mongo/internal/util"
+	"github.com/spf13/cobra"
+)
+
+const (
+	metricsFormatJSON       = "json"
+	metricsFormatPrometheus = "prometheus"
+)
+
+var (
+	metricsConnection string
+	metricsFormat     string
+	metricsOutput     string
+
+	metricsCmd = &cobra.Command{
+		Use:   "metrics",
+		Short: "Dump a snapshot of server and database metrics",
+		Long: `Dump a point-in-time snapshot of key serverStatus and dbStats metrics,
+e.g. "vi-mongo metrics --format prometheus -o snapshot.prom".`,
+		Args: cobra.NoArgs,
+		RunE: runMetrics,
+	}
+)
+
+func init() {
+	metricsCmd.Flags().StringVar(&metricsConnection, "connection", "", "name of the connection (default is the current connection)")
+	metricsCmd.Flags().StringVarP(&metricsFormat, "format", "f", metricsFormatJSON, "output format, json or prometheus")
+	metricsCmd.Flags().StringVarP(&metricsOutput, "output", "o", "", "output file (default is stdout)")
+	_ = metricsCmd.RegisterFlagCompletionFunc("connection", completeConnections)
+	_ = metricsCmd.RegisterFlagCompletionFunc("format", cobra.FixedCompletions([]string{metricsFormatJSON, metricsFormatPrometheus}, cobra.ShellCompDirectiveNoFileComp))
+	rootCmd.AddCommand(metricsCmd)
+}
+
+func runMetrics(cmd *cobra.Command, args []string) error {
+	if metricsFormat != metricsFormatJSON && metricsFormat != metricsFormatPrometheus {
+		return fmt.Errorf("invalid format %q, expected json or prometheus", metricsFormat)
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return err
+	}
+	conn := cfg.GetCurrentConnection()
+	if metricsConnection != "" {
+		conn = cfg.GetConnection(metricsConnection)
+	}
+	if conn == nil {
+		return fmt.Errorf("connection not found, use --connection or set the current connection")
+	}
+
+	client := mongo.NewClient(conn)
+	if err := client.Connect(); err != nil {
+		return withExitCode(ExitConnection, err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(conn.Timeout)*time.Second)
+	defer cancel()
+	defer client.Close(ctx)
+
+	snapshot, err := mongo.NewDao(client.Client, conn).MetricsSnapshot(ctx)
+	if err != nil {
+		return databaseError(err, ExitError)
+	}
+
+	var out bytes.Buffer
+	if metricsFormat == metricsFormatPrometheus {
+		err = snapshot.WritePrometheus(&out)
+	} else {
+		err = snapshot.WriteJSON(&out)
+	}
+	if err != nil {
+		return err
+	}
+
+	if metricsOutput == "" {
+		_, err = cmd.OutOrStdout().Write(out.Bytes())
+		return err
+	}
+	if err := util.WriteFileAtomic(metricsOutput, out.Bytes(), 0644); err != nil {
+		return err
+	}
+	fmt.Fprintf(cmd.ErrOrStderr(), "metrics written to %s\n", metricsOutput)
+	return nil
+}
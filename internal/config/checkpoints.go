@@ -0,0 +1,146 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/kopecmaciej/vi-mongo/internal/util"
+)
+
+const (
+	CheckpointsFile = "checkpoints.json"
+)
+
+// ImportCheckpoint is the progress of the import saved after every written
+// batch, so the interrupted import can be resumed. The import is identified
+// by the connection, the namespace (db.collection) and the source file, size
+// and modification time of the file tell if it was changed since.
+type ImportCheckpoint struct {
+	Connection      string         `json:"connection"`
+	Namespace       string         `json:"namespace"`
+	Source          string         `json:"source"`
+	SourceSize      int64          `json:"sourceSize"`
+	SourceModTime   time.Time      `json:"sourceModTime"`
+	Columns         []ImportColumn `json:"columns"`
+	UpsertKey       string         `json:"upsertKey,omitempty"`
+	BatchSize       int            `json:"batchSize,omitempty"`
+	WritesPerSecond int            `json:"writesPerSecond,omitempty"`
+	Done            int            `json:"done"`
+	Total           int            `json:"total"`
+	UpdatedAt       time.Time      `json:"updatedAt"`
+}
+
+// ImportColumn is the mapping of the column of the source file
+type ImportColumn struct {
+	Column string `json:"column"`
+	Field  string `json:"field"`
+	Type   string `json:"type"`
+}
+
+// Matches returns true if the checkpoint is of the same import
+func (c ImportCheckpoint) Matches(connection, namespace, source string) bool {
+	return c.Connection == connection && c.Namespace == namespace && c.Source == source
+}
+
+// LoadCheckpoints loads checkpoints of interrupted imports
+func LoadCheckpoints() ([]ImportCheckpoint, error) {
+	checkpointsPath, err := GetCheckpointsPath()
+	if err != nil {
+		return nil, err
+	}
+
+	bytes, err := os.ReadFile(checkpointsPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var checkpoints []ImportCheckpoint
+	if len(strings.TrimSpace(string(bytes))) == 0 {
+		return checkpoints, nil
+	}
+	if err := json.Unmarshal(bytes, &checkpoints); err != nil {
+		return nil, err
+	}
+
+	return checkpoints, nil
+}
+
+// FindCheckpoint returns the checkpoint of the import,
+// or nil if it was never interrupted
+func FindCheckpoint(connection, namespace, source string) (*ImportCheckpoint, error) {
+	checkpoints, err := LoadCheckpoints()
+	if err != nil {
+		return nil, err
+	}
+	for _, checkpoint := range checkpoints {
+		if checkpoint.Matches(connection, namespace, source) {
+			return &checkpoint, nil
+		}
+	}
+	return nil, nil
+}
+
+// SaveCheckpoint saves the checkpoint, replacing the previous one of the import
+func SaveCheckpoint(checkpoint ImportCheckpoint) error {
+	checkpoint.UpdatedAt = time.Now()
+	return updateCheckpoints(func(checkpoints []ImportCheckpoint) []ImportCheckpoint {
+		checkpoints = removeCheckpoint(checkpoints, checkpoint.Connection, checkpoint.Namespace, checkpoint.Source)
+		return append(checkpoints, checkpoint)
+	})
+}
+
+// RemoveCheckpoint removes the checkpoint of the finished import
+func RemoveCheckpoint(connection, namespace, source string) error {
+	return updateCheckpoints(func(checkpoints []ImportCheckpoint) []ImportCheckpoint {
+		return removeCheckpoint(checkpoints, connection, namespace, source)
+	})
+}
+
+func removeCheckpoint(checkpoints []ImportCheckpoint, connection, namespace, source string) []ImportCheckpoint {
+	kept := checkpoints[:0]
+	for _, checkpoint := range checkpoints {
+		if !checkpoint.Matches(connection, namespace, source) {
+			kept = append(kept, checkpoint)
+		}
+	}
+	return kept
+}
+
+func updateCheckpoints(update func([]ImportCheckpoint) []ImportCheckpoint) error {
+	checkpointsPath, err := GetCheckpointsPath()
+	if err != nil {
+		return err
+	}
+
+	return util.WithFileLock(checkpointsPath, func() error {
+		checkpoints, err := LoadCheckpoints()
+		if err != nil {
+			return err
+		}
+
+		bytes, err := json.MarshalIndent(update(checkpoints), "", "  ")
+		if err != nil {
+			return err
+		}
+
+		return util.WriteFileAtomic(checkpointsPath, bytes, 0644)
+	})
+}
+
+// GetCheckpointsPath returns the path to the file with checkpoints of imports
+func GetCheckpointsPath() (string, error) {
+	configDir, err := util.GetConfigDir()
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(configDir, 0755); err != nil {
+		return "", err
+	}
+
+	return configDir + "/" + CheckpointsFile, nil
+}
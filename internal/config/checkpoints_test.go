@@ -0,0 +1,61 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/adrg/xdg"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImportCheckpoints(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	xdg.Reload()
+	t.Cleanup(xdg.Reload)
+
+	checkpoint, err := FindCheckpoint("local", "shop.products", "/tmp/products.csv")
+	require.NoError(t, err)
+	assert.Nil(t, checkpoint)
+
+	require.NoError(t, SaveCheckpoint(ImportCheckpoint{
+		Connection: "local",
+		Namespace:  "shop.products",
+		Source:     "/tmp/products.csv",
+		Columns:    []ImportColumn{{Column: "sku", Field: "_id", Type: "string"}},
+		Done:       1000,
+		Total:      5000,
+	}))
+	require.NoError(t, SaveCheckpoint(ImportCheckpoint{
+		Connection: "prod",
+		Namespace:  "shop.products",
+		Source:     "/tmp/products.csv",
+		Done:       200,
+	}))
+	require.NoError(t, SaveCheckpoint(ImportCheckpoint{
+		Connection: "local",
+		Namespace:  "shop.products",
+		Source:     "/tmp/products.csv",
+		Done:       2000,
+		Total:      5000,
+	}))
+
+	checkpoints, err := LoadCheckpoints()
+	require.NoError(t, err)
+	assert.Len(t, checkpoints, 2)
+
+	checkpoint, err = FindCheckpoint("local", "shop.products", "/tmp/products.csv")
+	require.NoError(t, err)
+	require.NotNil(t, checkpoint)
+	assert.Equal(t, 2000, checkpoint.Done)
+	assert.False(t, checkpoint.UpdatedAt.IsZero())
+
+	require.NoError(t, RemoveCheckpoint("local", "shop.products", "/tmp/products.csv"))
+	checkpoint, err = FindCheckpoint("local", "shop.products", "/tmp/products.csv")
+	require.NoError(t, err)
+	assert.Nil(t, checkpoint)
+
+	checkpoint, err = FindCheckpoint("prod", "shop.products", "/tmp/products.csv")
+	require.NoError(t, err)
+	require.NotNil(t, checkpoint)
+	assert.Equal(t, 200, checkpoint.Done)
+}
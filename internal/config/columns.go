@@ -0,0 +1,184 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+
+	"github.com/kopecmaciej/vi-mongo/internal/util"
+)
+
+const (
+	ColumnsFile = "columns.json"
+)
+
+// ColumnLayout is the layout of the table view of a collection
+type ColumnLayout struct {
+	// AutoFit sizes columns to their widest value instead
+	// of splitting the width of the table evenly
+	AutoFit bool `json:"autoFit,omitempty"`
+	// Columns are settings of single columns, keyed by field
+	Columns map[string]ColumnWidth `json:"columns,omitempty"`
+	// Pinned are fields of columns which stay visible on the left
+	// while scrolling horizontally, in the order they were pinned
+	Pinned []string `json:"pinned,omitempty"`
+}
+
+// ColumnWidth bounds the width of the column. Width is set when
+// the column is widened or narrowed by hand, and then it's used
+// as it is, otherwise the width is kept between Min and Max.
+// Zero values are not set.
+type ColumnWidth struct {
+	Min   int `json:"min,omitempty"`
+	Max   int `json:"max,omitempty"`
+	Width int `json:"width,omitempty"`
+}
+
+// Width returns the width of the column of the field, computed is
+// the width from the even split or auto-fit, before bounds are applied
+func (l ColumnLayout) Width(field string, computed int) int {
+	column := l.Columns[field]
+	if column.Width > 0 {
+		return column.Width
+	}
+	if column.Max > 0 && computed > column.Max {
+		computed = column.Max
+	}
+	if computed < column.Min {
+		computed = column.Min
+	}
+	return computed
+}
+
+// SetWidth sets the width of the column by hand
+func (l *ColumnLayout) SetWidth(field string, width int) {
+	if l.Columns == nil {
+		l.Columns = map[string]ColumnWidth{}
+	}
+	column := l.Columns[field]
+	column.Width = width
+	l.Columns[field] = column
+}
+
+// TogglePin pins the column of the field, or unpins it if it's pinned
+func (l *ColumnLayout) TogglePin(field string) {
+	for i, pinned := range l.Pinned {
+		if pinned == field {
+			l.Pinned = append(l.Pinned[:i:i], l.Pinned[i+1:]...)
+			return
+		}
+	}
+	l.Pinned = append(l.Pinned, field)
+}
+
+// PinnedFirst returns indexes of fields in the order their columns are
+// shown, pinned ones go first, and the number of pinned columns.
+// Pinned fields missing in fields are skipped.
+func (l ColumnLayout) PinnedFirst(fields []string) ([]int, int) {
+	indexes := make(map[string]int, len(fields))
+	for i, field := range fields {
+		indexes[field] = i
+	}
+
+	order := make([]int, 0, len(fields))
+	isPinned := make(map[int]bool, len(l.Pinned))
+	for _, field := range l.Pinned {
+		if i, ok := indexes[field]; ok && !isPinned[i] {
+			isPinned[i] = true
+			order = append(order, i)
+		}
+	}
+	pinned := len(order)
+	for i := range fields {
+		if !isPinned[i] {
+			order = append(order, i)
+		}
+	}
+	return order, pinned
+}
+
+// IsZero returns true if nothing differs from the default layout
+func (l ColumnLayout) IsZero() bool {
+	return !l.AutoFit && len(l.Columns) == 0 && len(l.Pinned) == 0
+}
+
+// LoadColumnLayouts loads layouts saved per collection,
+// keyed by namespace (db.collection)
+func LoadColumnLayouts() (map[string]ColumnLayout, error) {
+	columnsPath, err := GetColumnsPath()
+	if err != nil {
+		return nil, err
+	}
+
+	bytes, err := os.ReadFile(columnsPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return map[string]ColumnLayout{}, nil
+		}
+		return nil, err
+	}
+
+	layouts := map[string]ColumnLayout{}
+	if len(strings.TrimSpace(string(bytes))) == 0 {
+		return layouts, nil
+	}
+	if err := json.Unmarshal(bytes, &layouts); err != nil {
+		return nil, err
+	}
+
+	return layouts, nil
+}
+
+// LoadColumnLayout returns layout saved for the namespace,
+// or the default one if there is none
+func LoadColumnLayout(namespace string) (ColumnLayout, error) {
+	layouts, err := LoadColumnLayouts()
+	if err != nil {
+		return ColumnLayout{}, err
+	}
+
+	return layouts[namespace], nil
+}
+
+// SaveColumnLayout saves layout of the namespace,
+// default layout removes it
+func SaveColumnLayout(namespace string, layout ColumnLayout) error {
+	columnsPath, err := GetColumnsPath()
+	if err != nil {
+		return err
+	}
+
+	return util.WithFileLock(columnsPath, func() error {
+		layouts, err := LoadColumnLayouts()
+		if err != nil {
+			return err
+		}
+
+		if layout.IsZero() {
+			delete(layouts, namespace)
+		} else {
+			layouts[namespace] = layout
+		}
+
+		bytes, err := json.MarshalIndent(layouts, "", "  ")
+		if err != nil {
+			return err
+		}
+
+		return util.WriteFileAtomic(columnsPath, bytes, 0644)
+	})
+}
+
+// GetColumnsPath returns the path to the file with column layouts
+// saved per collection
+func GetColumnsPath() (string, error) {
+	configDir, err := util.GetConfigDir()
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(configDir, 0755); err != nil {
+		return "", err
+	}
+
+	return configDir + "/" + ColumnsFile, nil
+}
@@ -0,0 +1,77 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/adrg/xdg"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestColumnLayoutWidth(t *testing.T) {
+	layout := ColumnLayout{Columns: map[string]ColumnWidth{
+		"name":  {Min: 20},
+		"bio":   {Max: 40},
+		"email": {Min: 10, Max: 40, Width: 60},
+	}}
+
+	assert.Equal(t, 25, layout.Width("_id", 25))
+	assert.Equal(t, 20, layout.Width("name", 15))
+	assert.Equal(t, 40, layout.Width("bio", 80))
+	assert.Equal(t, 60, layout.Width("email", 15))
+
+	layout.SetWidth("name", 12)
+	assert.Equal(t, 12, layout.Width("name", 15))
+	assert.Equal(t, 20, layout.Columns["name"].Min)
+
+	var empty ColumnLayout
+	empty.SetWidth("age", 8)
+	assert.Equal(t, 8, empty.Width("age", 30))
+}
+
+func TestColumnLayoutPinned(t *testing.T) {
+	fields := []string{"_id", "name", "email", "age"}
+	var layout ColumnLayout
+
+	order, pinned := layout.PinnedFirst(fields)
+	assert.Equal(t, []int{0, 1, 2, 3}, order)
+	assert.Equal(t, 0, pinned)
+
+	layout.TogglePin("email")
+	layout.TogglePin("_id")
+	layout.TogglePin("missing")
+	order, pinned = layout.PinnedFirst(fields)
+	assert.Equal(t, []int{2, 0, 1, 3}, order)
+	assert.Equal(t, 2, pinned)
+
+	layout.TogglePin("email")
+	layout.TogglePin("missing")
+	assert.Equal(t, []string{"_id"}, layout.Pinned)
+	order, pinned = layout.PinnedFirst(fields)
+	assert.Equal(t, []int{0, 1, 2, 3}, order)
+	assert.Equal(t, 1, pinned)
+	assert.False(t, layout.IsZero())
+}
+
+func TestSaveColumnLayout(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	xdg.Reload()
+	t.Cleanup(xdg.Reload)
+
+	layout, err := LoadColumnLayout("db.users")
+	require.NoError(t, err)
+	assert.True(t, layout.IsZero())
+
+	users := ColumnLayout{AutoFit: true, Columns: map[string]ColumnWidth{"name": {Width: 12}}}
+	require.NoError(t, SaveColumnLayout("db.users", users))
+	require.NoError(t, SaveColumnLayout("db.orders", ColumnLayout{AutoFit: true}))
+
+	layout, err = LoadColumnLayout("db.users")
+	require.NoError(t, err)
+	assert.Equal(t, users, layout)
+
+	require.NoError(t, SaveColumnLayout("db.users", ColumnLayout{}))
+	layouts, err := LoadColumnLayouts()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]ColumnLayout{"db.orders": {AutoFit: true}}, layouts)
+}
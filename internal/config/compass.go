@@ -0,0 +1,85 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const (
+	compassExportType = "Compass Connections"
+	// compassTimeout is the timeout of imported connections,
+	// the same as the default of the connection form
+	compassTimeout = 5
+)
+
+// compassExport is the file written by "Export connections" in Compass
+type compassExport struct {
+	Type        string `json:"type"`
+	Connections []struct {
+		ID                string `json:"id"`
+		ConnectionOptions struct {
+			ConnectionString string `json:"connectionString"`
+		} `json:"connectionOptions"`
+		Favorite *struct {
+			Name string `json:"name"`
+		} `json:"favorite"`
+	} `json:"connections"`
+}
+
+// LoadCompassConnections reads connections exported from Compass
+func LoadCompassConnections(path string) ([]MongoConfig, error) {
+	bytes, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseCompassConnections(bytes)
+}
+
+// ParseCompassConnections parses connections exported from Compass.
+// Connections are named as their favorite, or by the host otherwise.
+// Passwords exported with a passphrase are encrypted and not imported.
+func ParseCompassConnections(data []byte) ([]MongoConfig, error) {
+	var export compassExport
+	if err := json.Unmarshal(data, &export); err != nil {
+		return nil, fmt.Errorf("invalid Compass export: %w", err)
+	}
+	if export.Type != "" && export.Type != compassExportType {
+		return nil, fmt.Errorf("invalid Compass export type %q", export.Type)
+	}
+
+	connections := make([]MongoConfig, 0, len(export.Connections))
+	for _, c := range export.Connections {
+		uri := c.ConnectionOptions.ConnectionString
+		host, port, db, err := ParseMongoDBURI(uri)
+		if err != nil {
+			return nil, fmt.Errorf("connection %s: %w", c.ID, err)
+		}
+
+		// of replica sets only the first host is kept, the uri has all of them
+		port, _, _ = strings.Cut(port, ",")
+		host, _, _ = strings.Cut(host, ",")
+		intPort, err := strconv.Atoi(port)
+		if err != nil {
+			return nil, fmt.Errorf("connection %s: invalid port %q", c.ID, port)
+		}
+
+		name := host + ":" + port
+		if c.Favorite != nil && c.Favorite.Name != "" {
+			name = c.Favorite.Name
+		}
+
+		connections = append(connections, MongoConfig{
+			Name:     name,
+			Uri:      uri,
+			Host:     host,
+			Port:     intPort,
+			Database: db,
+			Timeout:  compassTimeout,
+		})
+	}
+
+	return connections, nil
+}
@@ -0,0 +1,74 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/adrg/xdg"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const compassExportJSON = `{
+  "type": "Compass Connections",
+  "version": {"$numberInt": "1"},
+  "connections": [
+    {
+      "id": "1",
+      "connectionOptions": {"connectionString": "mongodb://localhost:27017/shop"},
+      "favorite": {"name": "Local shop", "color": "color1"}
+    },
+    {
+      "id": "2",
+      "connectionOptions": {"connectionString": "mongodb://user@db1:27018,db2:27018/?replicaSet=rs0"}
+    }
+  ]
+}`
+
+func TestParseCompassConnections(t *testing.T) {
+	connections, err := ParseCompassConnections([]byte(compassExportJSON))
+	require.NoError(t, err)
+	require.Len(t, connections, 2)
+
+	assert.Equal(t, MongoConfig{
+		Name:     "Local shop",
+		Uri:      "mongodb://localhost:27017/shop",
+		Host:     "localhost",
+		Port:     27017,
+		Database: "shop",
+		Timeout:  compassTimeout,
+	}, connections[0])
+
+	assert.Equal(t, "db1:27018", connections[1].Name)
+	assert.Equal(t, "db1", connections[1].Host)
+	assert.Equal(t, 27018, connections[1].Port)
+	assert.Equal(t, "mongodb://user@db1:27018,db2:27018/?replicaSet=rs0", connections[1].Uri)
+
+	_, err = ParseCompassConnections([]byte(`{"type": "Something else", "connections": []}`))
+	assert.Error(t, err)
+	_, err = ParseCompassConnections([]byte(`{"connections": [{"connectionOptions": {"connectionString": "http://host"}}]}`))
+	assert.Error(t, err)
+	_, err = ParseCompassConnections([]byte(`not json`))
+	assert.Error(t, err)
+}
+
+func TestConfigImportConnections(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	xdg.Reload()
+	t.Cleanup(xdg.Reload)
+
+	cfg, err := LoadConfig()
+	require.NoError(t, err)
+	require.NoError(t, cfg.AddConnection(&MongoConfig{Name: "Local shop", Uri: "mongodb://old"}))
+
+	connections, err := ParseCompassConnections([]byte(compassExportJSON))
+	require.NoError(t, err)
+	added, err := cfg.ImportConnections(connections)
+	require.NoError(t, err)
+	assert.Equal(t, 1, added)
+
+	loaded, err := LoadConfig()
+	require.NoError(t, err)
+	require.Len(t, loaded.Connections, 2)
+	assert.Equal(t, "mongodb://old", loaded.Connections[0].Uri)
+	assert.Equal(t, "db1:27018", loaded.Connections[1].Name)
+}
@@ -3,6 +3,7 @@ package config
 import (
 	"fmt"
 	"os"
+	"path"
 	"strconv"
 	"strings"
 
@@ -14,6 +15,16 @@ import (
 const (
 	ConfigFile = "config.yaml"
 	LogPath    = "/tmp/vi-mongo.log"
+
+	// connectionsSection is the section of saved connections
+	connectionsSection = "connections"
+)
+
+const (
+	// ConfirmDestructive asks for confirmation before deleting anything
+	ConfirmDestructive = "destructive"
+	// ConfirmNone never asks for confirmation
+	ConfirmNone = "none"
 )
 
 type MongoConfig struct {
@@ -25,6 +36,105 @@ type MongoConfig struct {
 	Password string `yaml:"password"`
 	Name     string `yaml:"name"`
 	Timeout  int    `yaml:"timeout"`
+	// PasswordStore is where the password is saved instead of
+	// the config, "keyring" or "file", empty if it's in the config
+	PasswordStore string `yaml:"passwordStore,omitempty"`
+
+	// settings below override global behavior for this connection only
+	ReadOnly bool   `yaml:"readOnly,omitempty"`
+	Confirm  string `yaml:"confirm,omitempty"`
+	PageSize int    `yaml:"pageSize,omitempty"`
+	// EstimatedCount uses collection metadata for the number of documents
+	// when there is no filter, which is much faster on large collections
+	EstimatedCount bool `yaml:"estimatedCount,omitempty"`
+	// Prefetch loads the next page in the background after
+	// the page is rendered, so moving to it is instant
+	Prefetch bool `yaml:"prefetch,omitempty"`
+	// DocumentHistory saves the previous version of every document
+	// modified in the app into <collection>.__vi_mongo_history
+	DocumentHistory bool `yaml:"documentHistory,omitempty"`
+	// SoftDelete lists collections where documents are marked
+	// as deleted instead of being removed
+	SoftDelete []SoftDeleteConfig `yaml:"softDelete,omitempty"`
+	// NamespaceTemplates are names of per-tenant collections with
+	// a placeholder for the tenant, e.g. "tenant_{id}_orders"
+	NamespaceTemplates []string `yaml:"namespaceTemplates,omitempty"`
+	// Masking redacts values of sensitive fields when they are
+	// shown, copied or exported, e.g. on production data
+	Masking []MaskRule `yaml:"masking,omitempty"`
+	// KeyVault is used to decrypt fields encrypted on the client side
+	// when documents are peeked, encrypted fields are never edited
+	KeyVault *KeyVaultConfig `yaml:"keyVault,omitempty"`
+	// TLS connects with the client certificate or a custom CA,
+	// without putting the tls options into the URI
+	TLS *TLSConfig `yaml:"tls,omitempty"`
+	// WritesPerSecond limits imports for this connection, e.g. on
+	// production, instead of the global import limit
+	WritesPerSecond int `yaml:"writesPerSecond,omitempty"`
+	// ScheduledQueries are counted in the background while the app
+	// is open, an alert is shown when the count crosses the threshold
+	ScheduledQueries []ScheduledQueryConfig `yaml:"scheduledQueries,omitempty"`
+	// Keybindings are keyed by the path of the key in keybindings file,
+	// e.g. "content.deleteDocument"
+	Keybindings map[string]Key `yaml:"keybindings,omitempty"`
+}
+
+// SoftDeleteConfig is a convention of marking documents as deleted.
+// Namespace is "db.collection" and can contain wildcards, e.g. "shop.*".
+// Field is set to the time of deletion, documents with this field
+// are hidden unless deleted documents are shown.
+type SoftDeleteConfig struct {
+	Namespace string `yaml:"namespace"`
+	Field     string `yaml:"field"`
+}
+
+// ScheduledQueryConfig is a query of the collection, which documents
+// are counted every Interval seconds. Namespace is "db.collection",
+// Threshold is the operator and the number, e.g. "> 0".
+type ScheduledQueryConfig struct {
+	Name      string `yaml:"name"`
+	Namespace string `yaml:"namespace"`
+	Filter    string `yaml:"filter,omitempty"`
+	Interval  int    `yaml:"interval"`
+	Threshold string `yaml:"threshold"`
+}
+
+const (
+	// MaskFull replaces the whole value
+	MaskFull = "full"
+	// MaskPartial keeps first and last characters of the value
+	MaskPartial = "partial"
+	// MaskEmail keeps the first character and domain of the email
+	MaskEmail = "email"
+)
+
+// MaskRule masks fields which name or dotted path matches
+// the Field pattern, e.g. "email" or "*token*"
+type MaskRule struct {
+	Field string `yaml:"field"`
+	Mode  string `yaml:"mode,omitempty"`
+}
+
+// KeyVaultConfig is the key vault of client side field level encryption,
+// only the local KMS provider is supported
+type KeyVaultConfig struct {
+	// Namespace is "db.collection" of the key vault, e.g. "encryption.__keyVault"
+	Namespace string `yaml:"namespace"`
+	// LocalKeyFile is the path of the file with the 96 bytes master key
+	LocalKeyFile string `yaml:"localKeyFile"`
+}
+
+// TLSConfig enables TLS of the connection, files are PEM encoded
+type TLSConfig struct {
+	// CAFile verifies the certificate of the server instead of system CAs
+	CAFile string `yaml:"caFile,omitempty"`
+	// CertFile is the client certificate, KeyFile is its private key,
+	// which can be omitted if it's in the certificate file
+	CertFile string `yaml:"certFile,omitempty"`
+	KeyFile  string `yaml:"keyFile,omitempty"`
+	// InsecureSkipVerify accepts any certificate of the server,
+	// e.g. of a local cluster, it must not be used in production
+	InsecureSkipVerify bool `yaml:"insecureSkipVerify,omitempty"`
 }
 
 type LogConfig struct {
@@ -38,20 +148,206 @@ type EditorConfig struct {
 	Env     string `yaml:"env"`
 }
 
+type HistoryConfig struct {
+	MaxEntries int `yaml:"maxEntries"`
+}
+
+// ServerStatusConfig controls how often the header polls serverStatus,
+// all values are in seconds. Interval set to 0 disables polling. When the
+// server is slow or fails to respond, the interval is doubled up to
+// MaxInterval. Polling is paused after IdleTimeout without any key press.
+// Polled status is saved to the stats history of the connection at most
+// every HistoryInterval seconds, 0 disables it, and samples older than
+// HistoryRetention hours are removed. The server dashboard polls
+// the status every DashboardInterval seconds while it's open.
+type ServerStatusConfig struct {
+	// Interval and HistoryInterval are pointers, as 0 set in the file
+	// turns them off and isn't replaced by the default
+	Interval          *int `yaml:"interval"`
+	MaxInterval       int  `yaml:"maxInterval"`
+	IdleTimeout       int  `yaml:"idleTimeout"`
+	HistoryInterval   *int `yaml:"historyInterval"`
+	HistoryRetention  int  `yaml:"historyRetention"`
+	DashboardInterval int  `yaml:"dashboardInterval"`
+}
+
+// PollInterval returns the interval of polling in seconds, 0 if it's off
+func (s ServerStatusConfig) PollInterval() int {
+	if s.Interval == nil {
+		return 0
+	}
+	return *s.Interval
+}
+
+// SampleInterval returns the interval of saving samples to the stats
+// history in seconds, 0 if they aren't saved
+func (s ServerStatusConfig) SampleInterval() int {
+	if s.HistoryInterval == nil {
+		return 0
+	}
+	return *s.HistoryInterval
+}
+
+// SlowOpsConfig controls the slow operations panel, operations
+// running longer than Threshold milliseconds are listed
+// and the list is refreshed every Interval seconds
+type SlowOpsConfig struct {
+	Threshold int `yaml:"threshold"`
+	Interval  int `yaml:"interval"`
+}
+
+// TableConfig controls the table view. FlattenDepth is how many levels
+// of embedded documents are shown as dotted columns, e.g. address.city,
+// when flattening is toggled on. RefreshInterval is how often, in seconds,
+// the page is reloaded when auto-refresh is toggled on.
+type TableConfig struct {
+	FlattenDepth    int `yaml:"flattenDepth"`
+	RefreshInterval int `yaml:"refreshInterval"`
+}
+
+// DisplayConfig controls how values are displayed in the table and
+// the peeker. Digits of numbers are grouped by ThousandsSeparator, e.g.
+// ",", doubles which absolute value is at least ScientificThreshold, or
+// below its inverse, are shown in scientific notation and strings longer
+// than MaxStringLength end with "...". Zero values turn formatting off.
+// ApplyToExports formats values of CSV and Markdown exports the same
+// way, JSON exports always keep values as they are.
+type DisplayConfig struct {
+	ThousandsSeparator  string  `yaml:"thousandsSeparator"`
+	ScientificThreshold float64 `yaml:"scientificThreshold"`
+	MaxStringLength     int     `yaml:"maxStringLength"`
+	ApplyToExports      bool    `yaml:"applyToExports"`
+}
+
+// ValueFormat returns the format of displayed values
+func (d DisplayConfig) ValueFormat() util.ValueFormat {
+	return util.ValueFormat{
+		ThousandsSeparator:  d.ThousandsSeparator,
+		ScientificThreshold: d.ScientificThreshold,
+		MaxStringLength:     d.MaxStringLength,
+	}
+}
+
+// ExportFormat returns the format of values of tabular exports
+func (d DisplayConfig) ExportFormat() util.ValueFormat {
+	if !d.ApplyToExports {
+		return util.ValueFormat{}
+	}
+	return d.ValueFormat()
+}
+
+// ImportConfig controls how imported documents are written, in batches
+// of BatchSize documents and at most WritesPerSecond documents per second.
+// WritesPerSecond set to 0 doesn't limit imports.
+type ImportConfig struct {
+	BatchSize       int `yaml:"batchSize"`
+	WritesPerSecond int `yaml:"writesPerSecond"`
+}
+
+// SnippetConfig is an abbreviation that is expanded in the query bar,
+// Body can contain <$0>, <$1>... placeholders, which are visited
+// from left to right
+type SnippetConfig struct {
+	Trigger     string `yaml:"trigger"`
+	Body        string `yaml:"body"`
+	Description string `yaml:"description,omitempty"`
+}
+
+// ExporterConfig is an external command documents can be exported with.
+// Command is run by the shell, it receives documents as NDJSON on stdin
+// and its output is saved to the file with the Extension, e.g. "parquet".
+type ExporterConfig struct {
+	Name      string `yaml:"name"`
+	Command   string `yaml:"command"`
+	Extension string `yaml:"extension,omitempty"`
+}
+
 type StylesConfig struct {
 	BetterSymbols bool   `yaml:"betterSymbols"`
 	CurrentStyle  string `yaml:"currentStyle"`
+	// TypeColors colors table cells by the type of their value,
+	// colors are set in the style file
+	TypeColors bool `yaml:"typeColors"`
+	// Accessible uses high contrast colors, marks selection and state
+	// with text instead of color only and describes the selected row
+	// or node in a line of plain text, which screen readers can announce
+	Accessible bool `yaml:"accessible"`
+	// ReducedMotion keeps redraws to a minimum, e.g. over slow SSH
+	// connections or in terminal multiplexers, updates made in the
+	// background are drawn at most every RedrawInterval milliseconds
+	ReducedMotion  bool `yaml:"reducedMotion"`
+	RedrawInterval int  `yaml:"redrawInterval"`
 }
 
 type Config struct {
-	Version            string        `yaml:"version"`
-	Log                LogConfig     `yaml:"log"`
-	Editor             EditorConfig  `yaml:"editor"`
-	ShowConnectionPage bool          `yaml:"showConnectionPage"`
-	ShowWelcomePage    bool          `yaml:"showWelcomePage"`
-	CurrentConnection  string        `yaml:"currentConnection"`
-	Connections        []MongoConfig `yaml:"connections"`
-	Styles             StylesConfig  `yaml:"styles"`
+	Version            string             `yaml:"version"`
+	Log                LogConfig          `yaml:"log"`
+	Editor             EditorConfig       `yaml:"editor"`
+	ShowConnectionPage bool               `yaml:"showConnectionPage"`
+	ShowWelcomePage    bool               `yaml:"showWelcomePage"`
+	CurrentConnection  string             `yaml:"currentConnection"`
+	Connections        []MongoConfig      `yaml:"connections"`
+	Styles             StylesConfig       `yaml:"styles"`
+	History            HistoryConfig      `yaml:"history"`
+	Snippets           []SnippetConfig    `yaml:"snippets"`
+	ServerStatus       ServerStatusConfig `yaml:"serverStatus"`
+	SlowOps            SlowOpsConfig      `yaml:"slowOps"`
+	Table              TableConfig        `yaml:"table"`
+	Display            DisplayConfig      `yaml:"display"`
+	Import             ImportConfig       `yaml:"import"`
+	Exporters          []ExporterConfig   `yaml:"exporters,omitempty"`
+	// CheckUpdates checks GitHub releases on startup and shows
+	// the changelog when a new version is available
+	CheckUpdates bool `yaml:"checkUpdates"`
+	// Locale is the name of the catalog in the locales directory
+	// texts of the UI are taken from, e.g. "de" for locales/de.yaml
+	Locale string `yaml:"locale"`
+	// PasswordStorage is where passwords of connections are saved,
+	// "keyring", "file" or "config" to keep them in this file
+	PasswordStorage string `yaml:"passwordStorage"`
+
+	// flags are set from the command line for the current run,
+	// they take precedence over the file and are never saved to it
+	flags runFlags
+}
+
+type runFlags struct {
+	accessible    *bool
+	reducedMotion *bool
+}
+
+// SetAccessibleFlag turns the accessible mode on or off for the current run
+func (c *Config) SetAccessibleFlag(on bool) {
+	c.flags.accessible = &on
+}
+
+// SetReducedMotionFlag turns the reduced motion on or off for the current run
+func (c *Config) SetReducedMotionFlag(on bool) {
+	c.flags.reducedMotion = &on
+}
+
+// KeepFlags sets flags of the current run of the previous config,
+// e.g. when the config of another profile is loaded
+func (c *Config) KeepFlags(previous *Config) {
+	c.flags = previous.flags
+}
+
+// IsAccessible returns true if the accessible mode is on,
+// the flag takes precedence over the setting
+func (c *Config) IsAccessible() bool {
+	if c.flags.accessible != nil {
+		return *c.flags.accessible
+	}
+	return c.Styles.Accessible
+}
+
+// IsReducedMotion returns true if the reduced motion is on,
+// the flag takes precedence over the setting
+func (c *Config) IsReducedMotion() bool {
+	if c.flags.reducedMotion != nil {
+		return *c.flags.reducedMotion
+	}
+	return c.Styles.ReducedMotion
 }
 
 // LoadConfig loads the config file
@@ -82,11 +378,75 @@ func (c *Config) loadDefaults() {
 		Env:     "EDITOR",
 	}
 	c.Styles = StylesConfig{
-		BetterSymbols: true,
-		CurrentStyle:  "default.yaml",
+		BetterSymbols:  true,
+		CurrentStyle:   "default.yaml",
+		RedrawInterval: 1000,
+	}
+	c.History = HistoryConfig{
+		MaxEntries: defaultMaxHistory,
+	}
+	interval, historyInterval := 10, 60
+	c.ServerStatus = ServerStatusConfig{
+		Interval:          &interval,
+		MaxInterval:       120,
+		IdleTimeout:       300,
+		HistoryInterval:   &historyInterval,
+		HistoryRetention:  24,
+		DashboardInterval: 2,
+	}
+	c.SlowOps = SlowOpsConfig{
+		Threshold: 100,
+		Interval:  2,
+	}
+	c.Table = TableConfig{
+		FlattenDepth:    1,
+		RefreshInterval: 5,
+	}
+	c.Import = ImportConfig{
+		BatchSize:       1000,
+		WritesPerSecond: 0,
+	}
+	c.Snippets = []SnippetConfig{
+		{
+			Trigger:     "oid",
+			Body:        `ObjectID("<$0>")`,
+			Description: "ObjectID",
+		},
+		{
+			Trigger:     "dr",
+			Body:        `{ $gte: { $date: "<$0>" }, $lt: { $date: "<$1>" } }`,
+			Description: "Date range",
+		},
+		{
+			Trigger:     "re",
+			Body:        `{ $regex: "<$0>", $options: "i" }`,
+			Description: "Case insensitive regex",
+		},
 	}
 	c.ShowConnectionPage = true
 	c.ShowWelcomePage = false
+	c.Locale = DefaultLocale
+	c.PasswordStorage = PasswordKeyring
+}
+
+// GetImportLimits returns batch size and writes per second of imports
+// into the connection, the connection can have its own limit of writes
+func (c *Config) GetImportLimits(conn *MongoConfig) ImportConfig {
+	limits := c.Import
+	if conn != nil && conn.WritesPerSecond > 0 {
+		limits.WritesPerSecond = conn.WritesPerSecond
+	}
+	return limits
+}
+
+// GetSnippet returns snippet with given trigger
+func (c *Config) GetSnippet(trigger string) (SnippetConfig, bool) {
+	for _, snippet := range c.Snippets {
+		if snippet.Trigger == trigger {
+			return snippet, true
+		}
+	}
+	return SnippetConfig{}, false
 }
 
 // GetConfigPath returns the path to the config file
@@ -99,19 +459,72 @@ func GetConfigPath() (string, error) {
 	return fmt.Sprintf("%s/%s", configPath, ConfigFile), nil
 }
 
-// UpdateConfig updates the config file with the new settings
+// UpdateConfig updates the config file with the new settings,
+// connections are managed separately so the ones saved in the file are kept
 func (c *Config) UpdateConfig() error {
-	updatedConfig, err := yaml.Marshal(c)
+	return c.updateConfigFile(func(onDisk *Config) error {
+		settings := *c
+		settings.Connections = onDisk.Connections
+		settings.CurrentConnection = onDisk.CurrentConnection
+		*onDisk = settings
+		return nil
+	})
+}
+
+// updateConfigFile reads the config file, applies update to it and writes
+// it back while holding the config lock. As only the changed part is applied
+// to the file content, changes made by other running instances are not lost.
+func (c *Config) updateConfigFile(update func(onDisk *Config) error) error {
+	configPath, err := GetConfigPath()
 	if err != nil {
 		return err
 	}
 
-	configPath, err := GetConfigPath()
-	if err != nil {
+	return util.WithFileLock(configPath, func() error {
+		onDisk := &Config{}
+		bytes, err := os.ReadFile(configPath)
+		if err != nil {
+			if !os.IsNotExist(err) {
+				return err
+			}
+			*onDisk = *c
+		} else if err := yaml.Unmarshal(bytes, onDisk); err != nil {
+			return err
+		}
+
+		if err := update(onDisk); err != nil {
+			return err
+		}
+
+		updatedConfig, err := yaml.Marshal(onDisk)
+		if err != nil {
+			return err
+		}
+		if err := util.WriteFileAtomic(configPath, updatedConfig, 0644); err != nil {
+			return err
+		}
+
+		c.Connections = onDisk.Connections
+		return nil
+	})
+}
+
+// ResetSection resets given section of the config file to default values,
+// saved connections can't be reset, as their passwords would be left behind
+func (c *Config) ResetSection(section string) error {
+	if section == connectionsSection {
+		return fmt.Errorf("%s can't be reset, delete them on the connection page instead", connectionsSection)
+	}
+	defaultConfig := &Config{}
+	defaultConfig.loadDefaults()
+
+	if err := util.ResetSection(c, defaultConfig, "yaml", section); err != nil {
 		return err
 	}
 
-	return os.WriteFile(configPath, updatedConfig, 0644)
+	return c.updateConfigFile(func(onDisk *Config) error {
+		return util.ResetSection(onDisk, defaultConfig, "yaml", section)
+	})
 }
 
 // GetEditorCmd returns the editor command from the config file
@@ -132,54 +545,51 @@ func (c *Config) SetCurrentConnection(name string) error {
 	// we don't want to save the current connection
 	c.CurrentConnection = name
 
-	updatedConfig, err := yaml.Marshal(c)
-	if err != nil {
-		return err
-	}
-
-	configPath, err := GetConfigPath()
-	if err != nil {
-		return err
-	}
-
-	return os.WriteFile(configPath, updatedConfig, 0644)
+	return c.updateConfigFile(func(onDisk *Config) error {
+		onDisk.CurrentConnection = name
+		return nil
+	})
 }
 
-// GetCurrentConnection gets the current connection from the config file
+// GetCurrentConnection gets the current connection from the config file,
+// with the password read from its store
 func (c *Config) GetCurrentConnection() *MongoConfig {
 	for _, connection := range c.Connections {
 		if connection.Name == c.CurrentConnection {
-			return &connection
+			return withPassword(connection)
 		}
 	}
 
 	return nil
 }
 
-// AddConnection adds a MongoDB connection to the config file
-func (c *Config) AddConnection(mongoConfig *MongoConfig) error {
-	log.Info().Msgf("Adding connection: %s", mongoConfig.Name)
-	if c.Connections == nil {
-		c.Connections = []MongoConfig{}
-	}
+// GetConnection returns the connection with the name, nil if there is none,
+// with the password read from its store
+func (c *Config) GetConnection(name string) *MongoConfig {
 	for _, connection := range c.Connections {
-		if connection.Name == mongoConfig.Name {
-			return fmt.Errorf("connection with name %s already exists", mongoConfig.Name)
+		if connection.Name == name {
+			return withPassword(connection)
 		}
 	}
-	c.Connections = append(c.Connections, *mongoConfig)
 
-	updatedConfig, err := yaml.Marshal(c)
-	if err != nil {
-		return err
-	}
-
-	configPath, err := GetConfigPath()
-	if err != nil {
-		return err
-	}
+	return nil
+}
 
-	return os.WriteFile(configPath, updatedConfig, 0644)
+// AddConnection adds a MongoDB connection to the config file
+func (c *Config) AddConnection(mongoConfig *MongoConfig) error {
+	log.Info().Msgf("Adding connection: %s", mongoConfig.Name)
+	return c.updateConfigFile(func(onDisk *Config) error {
+		for _, connection := range onDisk.Connections {
+			if connection.Name == mongoConfig.Name {
+				return fmt.Errorf("connection with name %s already exists", mongoConfig.Name)
+			}
+		}
+		if err := c.storePassword(mongoConfig); err != nil {
+			return err
+		}
+		onDisk.Connections = append(onDisk.Connections, *mongoConfig)
+		return nil
+	})
 }
 
 // AddConnectionFromUri adds a MongoDB connection to the config file
@@ -200,27 +610,61 @@ func (c *Config) AddConnectionFromUri(mongoConfig *MongoConfig) error {
 	return c.AddConnection(mongoConfig)
 }
 
+// ImportConnections adds connections to the config file, ones with
+// the name of an existing connection are skipped. It returns
+// the number of added connections.
+func (c *Config) ImportConnections(connections []MongoConfig) (int, error) {
+	added := 0
+	err := c.updateConfigFile(func(onDisk *Config) error {
+		added = 0
+		names := map[string]bool{}
+		for _, connection := range onDisk.Connections {
+			names[connection.Name] = true
+		}
+		for _, connection := range connections {
+			if names[connection.Name] {
+				continue
+			}
+			names[connection.Name] = true
+			if err := c.storePassword(&connection); err != nil {
+				return err
+			}
+			onDisk.Connections = append(onDisk.Connections, connection)
+			added++
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	log.Info().Msgf("Imported %d connections", added)
+	return added, nil
+}
+
 // DeleteConnection deletes a config from the config file by name
 func (c *Config) DeleteConnection(name string) error {
 	log.Info().Msgf("Deleting connection: %s", name)
-	for i, connection := range c.Connections {
-		if connection.Name == name {
-			connection = MongoConfig{}
-			c.Connections = append(c.Connections[:i], c.Connections[i+1:]...)
+	var deleted []MongoConfig
+	err := c.updateConfigFile(func(onDisk *Config) error {
+		connections := []MongoConfig{}
+		deleted = nil
+		for _, connection := range onDisk.Connections {
+			if connection.Name != name {
+				connections = append(connections, connection)
+			} else if connection.PasswordStore != "" {
+				deleted = append(deleted, connection)
+			}
 		}
-	}
-
-	updatedConfig, err := yaml.Marshal(c)
+		onDisk.Connections = connections
+		return nil
+	})
 	if err != nil {
 		return err
 	}
-
-	configPath, err := GetConfigPath()
-	if err != nil {
-		return err
+	for _, connection := range deleted {
+		deletePassword(connection)
 	}
-
-	return os.WriteFile(configPath, updatedConfig, 0644)
+	return nil
 }
 
 // GetUri returns the URI or builds it from the config
@@ -238,6 +682,280 @@ func (m *MongoConfig) GetUri() string {
 	return uri
 }
 
+// ShouldConfirm returns true if destructive operations
+// should be confirmed for this connection
+func (m *MongoConfig) ShouldConfirm() bool {
+	return m.Confirm != ConfirmNone
+}
+
+// GetSoftDelete returns the soft delete convention of the collection,
+// the first matching namespace is used
+func (m *MongoConfig) GetSoftDelete(db, coll string) (SoftDeleteConfig, bool) {
+	for _, softDelete := range m.SoftDelete {
+		if matched, _ := path.Match(softDelete.Namespace, db+"."+coll); matched {
+			return softDelete, true
+		}
+	}
+	return SoftDeleteConfig{}, false
+}
+
+// Validate checks connection specific overrides
+func (c *Config) Validate() []util.ConfigError {
+	var errs []util.ConfigError
+
+	defaultKeybindings := &KeyBindings{}
+	defaultKeybindings.loadDefaults()
+
+	triggers := make(map[string]bool)
+	for _, snippet := range c.Snippets {
+		switch {
+		case snippet.Trigger == "" || strings.ContainsAny(snippet.Trigger, " \t"):
+			errs = append(errs, util.ConfigError{
+				Value: snippet.Body,
+				Msg:   fmt.Sprintf("snippet trigger must be a single word, got %q", snippet.Trigger),
+			})
+		case triggers[snippet.Trigger]:
+			errs = append(errs, util.ConfigError{
+				Value: snippet.Trigger,
+				Msg:   fmt.Sprintf("snippet trigger %q is defined more than once", snippet.Trigger),
+			})
+		case snippet.Body == "":
+			errs = append(errs, util.ConfigError{
+				Value: snippet.Trigger,
+				Msg:   fmt.Sprintf("snippet %q has empty body", snippet.Trigger),
+			})
+		}
+		triggers[snippet.Trigger] = true
+	}
+
+	status := c.ServerStatus
+	interval := status.PollInterval()
+	switch {
+	case interval < 0 || status.MaxInterval < 0 || status.IdleTimeout < 0:
+		errs = append(errs, util.ConfigError{
+			Value: "serverStatus",
+			Msg:   "serverStatus interval, maxInterval and idleTimeout can't be negative",
+		})
+	case status.SampleInterval() < 0 || status.HistoryRetention < 0:
+		errs = append(errs, util.ConfigError{
+			Value: "serverStatus",
+			Msg:   "serverStatus historyInterval and historyRetention can't be negative",
+		})
+	case interval > 0 && status.MaxInterval < interval:
+		errs = append(errs, util.ConfigError{
+			Value: "serverStatus",
+			Msg:   fmt.Sprintf("serverStatus maxInterval (%d) must not be lower than interval (%d)", status.MaxInterval, interval),
+		})
+	}
+
+	if c.SlowOps.Threshold < 0 || c.SlowOps.Interval < 0 {
+		errs = append(errs, util.ConfigError{
+			Value: "slowOps",
+			Msg:   "slowOps threshold and interval can't be negative",
+		})
+	}
+
+	if c.Table.FlattenDepth < 0 {
+		errs = append(errs, util.ConfigError{
+			Value: strconv.Itoa(c.Table.FlattenDepth),
+			Msg:   "table flattenDepth can't be negative",
+		})
+	}
+	if c.Table.RefreshInterval < 0 {
+		errs = append(errs, util.ConfigError{
+			Value: strconv.Itoa(c.Table.RefreshInterval),
+			Msg:   "table refreshInterval can't be negative",
+		})
+	}
+
+	if c.Display.ScientificThreshold < 0 || c.Display.MaxStringLength < 0 {
+		errs = append(errs, util.ConfigError{
+			Value: "display",
+			Msg:   "display scientificThreshold and maxStringLength can't be negative",
+		})
+	}
+	if strings.ContainsAny(c.Display.ThousandsSeparator, "0123456789") {
+		errs = append(errs, util.ConfigError{
+			Value: c.Display.ThousandsSeparator,
+			Msg:   "display thousandsSeparator can't contain digits",
+		})
+	}
+
+	if c.Import.BatchSize < 0 || c.Import.WritesPerSecond < 0 {
+		errs = append(errs, util.ConfigError{
+			Value: "import",
+			Msg:   "import batchSize and writesPerSecond can't be negative",
+		})
+	}
+
+	exporters := make(map[string]bool)
+	for _, exporter := range c.Exporters {
+		switch {
+		case exporter.Name == "":
+			errs = append(errs, util.ConfigError{
+				Value: exporter.Command,
+				Msg:   "exporter name can't be empty",
+			})
+		case exporters[exporter.Name]:
+			errs = append(errs, util.ConfigError{
+				Value: exporter.Name,
+				Msg:   fmt.Sprintf("exporter %q is defined more than once", exporter.Name),
+			})
+		case strings.TrimSpace(exporter.Command) == "":
+			errs = append(errs, util.ConfigError{
+				Value: exporter.Name,
+				Msg:   fmt.Sprintf("exporter %q has empty command", exporter.Name),
+			})
+		case strings.ContainsAny(exporter.Extension, "/\\ "):
+			errs = append(errs, util.ConfigError{
+				Value: exporter.Extension,
+				Msg:   fmt.Sprintf("exporter %q extension must be a single word", exporter.Name),
+			})
+		}
+		exporters[exporter.Name] = true
+	}
+
+	switch c.PasswordStorage {
+	case "", PasswordKeyring, PasswordFile, PasswordConfig:
+	default:
+		errs = append(errs, util.ConfigError{
+			Value: c.PasswordStorage,
+			Msg:   fmt.Sprintf("passwordStorage must be %q, %q or %q, got %q", PasswordKeyring, PasswordFile, PasswordConfig, c.PasswordStorage),
+		})
+	}
+
+	for _, conn := range c.Connections {
+		switch conn.Confirm {
+		case "", ConfirmDestructive, ConfirmNone:
+		default:
+			errs = append(errs, util.ConfigError{
+				Value: conn.Confirm,
+				Msg:   fmt.Sprintf("connection %s: confirm must be %q or %q, got %q", conn.Name, ConfirmDestructive, ConfirmNone, conn.Confirm),
+			})
+		}
+		if conn.WritesPerSecond < 0 {
+			errs = append(errs, util.ConfigError{
+				Value: strconv.Itoa(conn.WritesPerSecond),
+				Msg:   fmt.Sprintf("connection %s: writesPerSecond can't be negative", conn.Name),
+			})
+		}
+		if conn.PageSize < 0 {
+			errs = append(errs, util.ConfigError{
+				Value: "pageSize",
+				Msg:   fmt.Sprintf("connection %s: pageSize must be positive", conn.Name),
+			})
+		}
+		for _, softDelete := range conn.SoftDelete {
+			if _, err := path.Match(softDelete.Namespace, ""); err != nil || softDelete.Namespace == "" {
+				errs = append(errs, util.ConfigError{
+					Value: softDelete.Namespace,
+					Msg:   fmt.Sprintf("connection %s: invalid soft delete namespace %q", conn.Name, softDelete.Namespace),
+				})
+			}
+			if softDelete.Field == "" || strings.HasPrefix(softDelete.Field, "$") {
+				errs = append(errs, util.ConfigError{
+					Value: softDelete.Field,
+					Msg:   fmt.Sprintf("connection %s: invalid soft delete field %q", conn.Name, softDelete.Field),
+				})
+			}
+		}
+
+		for _, rule := range conn.Masking {
+			if _, err := path.Match(rule.Field, ""); err != nil || rule.Field == "" {
+				errs = append(errs, util.ConfigError{
+					Value: rule.Field,
+					Msg:   fmt.Sprintf("connection %s: invalid masking field %q", conn.Name, rule.Field),
+				})
+			}
+			switch rule.Mode {
+			case "", MaskFull, MaskPartial, MaskEmail:
+			default:
+				errs = append(errs, util.ConfigError{
+					Value: rule.Mode,
+					Msg:   fmt.Sprintf("connection %s: masking mode must be %q, %q or %q, got %q", conn.Name, MaskFull, MaskPartial, MaskEmail, rule.Mode),
+				})
+			}
+		}
+		if keyVault := conn.KeyVault; keyVault != nil {
+			if db, coll, ok := strings.Cut(keyVault.Namespace, "."); !ok || db == "" || coll == "" {
+				errs = append(errs, util.ConfigError{
+					Value: keyVault.Namespace,
+					Msg:   fmt.Sprintf("connection %s: key vault namespace must be \"db.collection\"", conn.Name),
+				})
+			}
+			if keyVault.LocalKeyFile == "" {
+				errs = append(errs, util.ConfigError{
+					Value: "keyVault",
+					Msg:   fmt.Sprintf("connection %s: key vault localKeyFile is required", conn.Name),
+				})
+			}
+		}
+		switch conn.PasswordStore {
+		case "", PasswordKeyring, PasswordFile:
+		default:
+			errs = append(errs, util.ConfigError{
+				Value: conn.PasswordStore,
+				Msg:   fmt.Sprintf("connection %s: passwordStore must be %q or %q, got %q", conn.Name, PasswordKeyring, PasswordFile, conn.PasswordStore),
+			})
+		}
+		if tlsConfig := conn.TLS; tlsConfig != nil && tlsConfig.KeyFile != "" && tlsConfig.CertFile == "" {
+			errs = append(errs, util.ConfigError{
+				Value: tlsConfig.KeyFile,
+				Msg:   fmt.Sprintf("connection %s: tls keyFile requires certFile", conn.Name),
+			})
+		}
+		for _, query := range conn.ScheduledQueries {
+			if query.Name == "" {
+				errs = append(errs, util.ConfigError{
+					Value: query.Namespace,
+					Msg:   fmt.Sprintf("connection %s: scheduled query name is required", conn.Name),
+				})
+			}
+			if db, coll, ok := strings.Cut(query.Namespace, "."); !ok || db == "" || coll == "" {
+				errs = append(errs, util.ConfigError{
+					Value: query.Namespace,
+					Msg:   fmt.Sprintf("connection %s: scheduled query %q namespace must be \"db.collection\"", conn.Name, query.Name),
+				})
+			}
+			if query.Interval <= 0 {
+				errs = append(errs, util.ConfigError{
+					Value: strconv.Itoa(query.Interval),
+					Msg:   fmt.Sprintf("connection %s: scheduled query %q interval must be positive", conn.Name, query.Name),
+				})
+			}
+			if _, err := util.ParseThreshold(query.Threshold); err != nil {
+				errs = append(errs, util.ConfigError{
+					Value: query.Threshold,
+					Msg:   fmt.Sprintf("connection %s: scheduled query %q %s", conn.Name, query.Name, err),
+				})
+			}
+		}
+		for _, template := range conn.NamespaceTemplates {
+			if _, err := ParseNamespaceTemplate(template); err != nil {
+				errs = append(errs, util.ConfigError{
+					Value: template,
+					Msg:   fmt.Sprintf("connection %s: %s", conn.Name, err),
+				})
+			}
+		}
+
+		// overrides are applied to a copy, just to check if they are valid
+		keybindings := *defaultKeybindings
+		if err := keybindings.ApplyOverrides(conn.Keybindings); err != nil {
+			errs = append(errs, util.ConfigError{
+				Value: "keybindings",
+				Msg:   fmt.Sprintf("connection %s: %s", conn.Name, err),
+			})
+		}
+		for _, keyErr := range keybindings.Validate() {
+			keyErr.Msg = fmt.Sprintf("connection %s: %s", conn.Name, keyErr.Msg)
+			errs = append(errs, keyErr)
+		}
+	}
+
+	return errs
+}
+
 // GetSafeUri returns the URI with the password replaced by asterisks
 func (m *MongoConfig) GetSafeUri() string {
 	uri := m.GetUri()
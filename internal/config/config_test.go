@@ -1,7 +1,16 @@
 package config
 
 import (
+	"bytes"
+	"os"
+	"path/filepath"
 	"testing"
+
+	"github.com/adrg/xdg"
+	"github.com/kopecmaciej/vi-mongo/internal/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
 )
 
 func TestParseMongoDBURI(t *testing.T) {
@@ -74,3 +83,394 @@ func TestParseMongoDBURI(t *testing.T) {
 		})
 	}
 }
+
+func TestConfigMergeOnWrite(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	xdg.Reload()
+	t.Cleanup(xdg.Reload)
+
+	first, err := LoadConfig()
+	require.NoError(t, err)
+	second, err := LoadConfig()
+	require.NoError(t, err)
+
+	require.NoError(t, first.AddConnection(&MongoConfig{Name: "first"}))
+	require.NoError(t, second.AddConnection(&MongoConfig{Name: "second"}))
+	assert.Len(t, second.Connections, 2)
+
+	// settings change should not drop connections added by other instance
+	first.ShowWelcomePage = true
+	require.NoError(t, first.UpdateConfig())
+
+	loaded, err := LoadConfig()
+	require.NoError(t, err)
+	assert.True(t, loaded.ShowWelcomePage)
+	assert.Len(t, loaded.Connections, 2)
+
+	require.NoError(t, second.DeleteConnection("first"))
+	loaded, err = LoadConfig()
+	require.NoError(t, err)
+	require.Len(t, loaded.Connections, 1)
+	assert.Equal(t, "second", loaded.Connections[0].Name)
+}
+
+func TestConfigFlagsAreNotSaved(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	xdg.Reload()
+	t.Cleanup(xdg.Reload)
+
+	cfg, err := LoadConfig()
+	require.NoError(t, err)
+	cfg.SetAccessibleFlag(true)
+	cfg.SetReducedMotionFlag(true)
+	assert.True(t, cfg.IsAccessible())
+	assert.True(t, cfg.IsReducedMotion())
+
+	cfg.ShowWelcomePage = true
+	require.NoError(t, cfg.UpdateConfig())
+
+	loaded, err := LoadConfig()
+	require.NoError(t, err)
+	assert.True(t, loaded.ShowWelcomePage)
+	assert.False(t, loaded.IsAccessible())
+	assert.False(t, loaded.IsReducedMotion())
+
+	// the flag turns the setting off as well
+	loaded.Styles.Accessible = true
+	loaded.SetAccessibleFlag(false)
+	assert.False(t, loaded.IsAccessible())
+}
+
+func TestEmbeddedStylesAreValid(t *testing.T) {
+	entries, err := stylesFS.ReadDir("styles")
+	require.NoError(t, err)
+
+	for _, entry := range entries {
+		t.Run(entry.Name(), func(t *testing.T) {
+			content, err := stylesFS.ReadFile("styles/" + entry.Name())
+			require.NoError(t, err)
+
+			decoder := yaml.NewDecoder(bytes.NewReader(content))
+			decoder.KnownFields(true)
+			assert.NoError(t, decoder.Decode(&Styles{}))
+		})
+	}
+}
+
+func TestTypeColorsForType(t *testing.T) {
+	colors := TypeColorsStyle{StringColor: "#111111", NumberColor: "#222222"}
+
+	color, ok := colors.ForType(util.TypeString)
+	assert.True(t, ok)
+	assert.Equal(t, Style("#111111"), color)
+
+	color, ok = colors.ForType(util.TypeDouble)
+	assert.True(t, ok)
+	assert.Equal(t, Style("#222222"), color)
+
+	_, ok = colors.ForType(util.TypeDate)
+	assert.False(t, ok)
+	_, ok = colors.ForType(util.TypeArray)
+	assert.False(t, ok)
+}
+
+func TestConfigValidate(t *testing.T) {
+	cfg := &Config{
+		Connections: []MongoConfig{
+			{Name: "dev", Confirm: ConfirmNone},
+			{Name: "prod", ReadOnly: true, Confirm: "sometimes", Keybindings: map[string]Key{
+				"content.deleteDocument": {Keys: []string{"Ctrl+Nope"}},
+			}},
+		},
+	}
+
+	errs := cfg.Validate()
+	assert.Len(t, errs, 2)
+	assert.Equal(t, "sometimes", errs[0].Value)
+	assert.Equal(t, "Ctrl+Nope", errs[1].Value)
+	assert.Contains(t, errs[1].Msg, "connection prod")
+}
+
+func TestConfigValidateSnippets(t *testing.T) {
+	cfg := &Config{}
+	cfg.loadDefaults()
+	assert.Empty(t, cfg.Validate())
+
+	cfg.Snippets = append(cfg.Snippets,
+		SnippetConfig{Trigger: "oid", Body: "ObjectID()"},
+		SnippetConfig{Trigger: "two words", Body: "{}"},
+		SnippetConfig{Trigger: "empty"},
+	)
+
+	errs := cfg.Validate()
+	assert.Len(t, errs, 3)
+	assert.Contains(t, errs[0].Msg, `"oid" is defined more than once`)
+	assert.Contains(t, errs[1].Msg, "single word")
+	assert.Contains(t, errs[2].Msg, "empty body")
+
+	snippet, ok := cfg.GetSnippet("dr")
+	assert.True(t, ok)
+	assert.Contains(t, snippet.Body, "$gte")
+}
+
+func TestConfigValidateServerStatus(t *testing.T) {
+	cfg := &Config{}
+	cfg.loadDefaults()
+
+	seconds := func(n int) *int { return &n }
+
+	cfg.ServerStatus = ServerStatusConfig{Interval: seconds(0), MaxInterval: 0}
+	assert.Empty(t, cfg.Validate())
+
+	cfg.ServerStatus = ServerStatusConfig{Interval: seconds(30), MaxInterval: 10}
+	errs := cfg.Validate()
+	assert.Len(t, errs, 1)
+	assert.Contains(t, errs[0].Msg, "maxInterval")
+
+	cfg.ServerStatus = ServerStatusConfig{Interval: seconds(-1), MaxInterval: 10}
+	errs = cfg.Validate()
+	assert.Len(t, errs, 1)
+	assert.Contains(t, errs[0].Msg, "negative")
+
+	cfg.ServerStatus = ServerStatusConfig{Interval: seconds(10), MaxInterval: 10, HistoryRetention: -1}
+	errs = cfg.Validate()
+	assert.Len(t, errs, 1)
+	assert.Contains(t, errs[0].Msg, "historyRetention")
+}
+
+func TestLoadConfigPartialServerStatus(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	xdg.Reload()
+	t.Cleanup(xdg.Reload)
+
+	configPath, err := GetConfigPath()
+	require.NoError(t, err)
+	require.NoError(t, os.MkdirAll(filepath.Dir(configPath), 0755))
+	require.NoError(t, os.WriteFile(configPath, []byte("serverStatus:\n  interval: 30\n  historyInterval: 0\n"), 0644))
+
+	cfg, err := LoadConfig()
+	require.NoError(t, err)
+	assert.Equal(t, 30, cfg.ServerStatus.PollInterval())
+	assert.Equal(t, 120, cfg.ServerStatus.MaxInterval)
+	assert.Equal(t, 0, cfg.ServerStatus.SampleInterval())
+	assert.Equal(t, 24, cfg.ServerStatus.HistoryRetention)
+}
+
+func TestConfigValidateTable(t *testing.T) {
+	cfg := &Config{}
+	cfg.loadDefaults()
+	assert.Equal(t, 1, cfg.Table.FlattenDepth)
+
+	cfg.Table.FlattenDepth = 0
+	assert.Empty(t, cfg.Validate())
+
+	cfg.Table.FlattenDepth = -1
+	errs := cfg.Validate()
+	assert.Len(t, errs, 1)
+	assert.Contains(t, errs[0].Msg, "flattenDepth")
+
+	cfg.Table.FlattenDepth = 1
+	cfg.Table.RefreshInterval = -5
+	errs = cfg.Validate()
+	assert.Len(t, errs, 1)
+	assert.Contains(t, errs[0].Msg, "refreshInterval")
+}
+
+func TestConfigValidateDisplay(t *testing.T) {
+	cfg := &Config{}
+	cfg.loadDefaults()
+	assert.Equal(t, util.ValueFormat{}, cfg.Display.ValueFormat())
+
+	cfg.Display = DisplayConfig{ThousandsSeparator: ",", ScientificThreshold: 1e9, MaxStringLength: 80}
+	assert.Empty(t, cfg.Validate())
+	assert.Equal(t, util.ValueFormat{}, cfg.Display.ExportFormat())
+	cfg.Display.ApplyToExports = true
+	assert.Equal(t, cfg.Display.ValueFormat(), cfg.Display.ExportFormat())
+
+	cfg.Display.MaxStringLength = -1
+	errs := cfg.Validate()
+	assert.Len(t, errs, 1)
+	assert.Contains(t, errs[0].Msg, "maxStringLength")
+
+	cfg.Display.MaxStringLength = 0
+	cfg.Display.ThousandsSeparator = "0"
+	errs = cfg.Validate()
+	assert.Len(t, errs, 1)
+	assert.Contains(t, errs[0].Msg, "thousandsSeparator")
+}
+
+func TestConfigValidateExporters(t *testing.T) {
+	cfg := &Config{}
+	cfg.loadDefaults()
+	cfg.Exporters = []ExporterConfig{
+		{Name: "Parquet", Command: "duckdb -c \"COPY (SELECT * FROM read_json('/dev/stdin')) TO '/dev/stdout' (FORMAT parquet)\"", Extension: "parquet"},
+	}
+	assert.Empty(t, cfg.Validate())
+
+	cfg.Exporters = append(cfg.Exporters,
+		ExporterConfig{Name: "Parquet", Command: "cat"},
+		ExporterConfig{Name: "Empty", Command: " "},
+		ExporterConfig{Name: "Xlsx", Command: "in2csv", Extension: "../xlsx"},
+		ExporterConfig{Command: "cat"},
+	)
+
+	errs := cfg.Validate()
+	assert.Len(t, errs, 4)
+	assert.Contains(t, errs[0].Msg, `"Parquet" is defined more than once`)
+	assert.Contains(t, errs[1].Msg, "empty command")
+	assert.Contains(t, errs[2].Msg, "single word")
+	assert.Contains(t, errs[3].Msg, "name can't be empty")
+}
+
+func TestConfigImportLimits(t *testing.T) {
+	cfg := &Config{}
+	cfg.loadDefaults()
+	assert.Equal(t, ImportConfig{BatchSize: 1000}, cfg.GetImportLimits(nil))
+
+	prod := &MongoConfig{Name: "prod", WritesPerSecond: 200}
+	assert.Equal(t, ImportConfig{BatchSize: 1000, WritesPerSecond: 200}, cfg.GetImportLimits(prod))
+
+	cfg.Import.WritesPerSecond = -1
+	cfg.Connections = []MongoConfig{{Name: "prod", WritesPerSecond: -5}}
+	errs := cfg.Validate()
+	assert.Len(t, errs, 2)
+	assert.Contains(t, errs[0].Msg, "import batchSize")
+	assert.Contains(t, errs[1].Msg, "connection prod: writesPerSecond")
+}
+
+func TestMongoConfigGetSoftDelete(t *testing.T) {
+	conn := &MongoConfig{
+		SoftDelete: []SoftDeleteConfig{
+			{Namespace: "shop.users", Field: "removedAt"},
+			{Namespace: "shop.*", Field: "deletedAt"},
+		},
+	}
+
+	softDelete, ok := conn.GetSoftDelete("shop", "users")
+	assert.True(t, ok)
+	assert.Equal(t, "removedAt", softDelete.Field)
+
+	softDelete, ok = conn.GetSoftDelete("shop", "orders")
+	assert.True(t, ok)
+	assert.Equal(t, "deletedAt", softDelete.Field)
+
+	_, ok = conn.GetSoftDelete("analytics", "events")
+	assert.False(t, ok)
+
+	cfg := &Config{Connections: []MongoConfig{{
+		Name:       "dev",
+		SoftDelete: []SoftDeleteConfig{{Namespace: "shop.[", Field: "$deleted"}},
+	}}}
+	errs := cfg.Validate()
+	assert.Len(t, errs, 2)
+	assert.Contains(t, errs[0].Msg, "namespace")
+	assert.Contains(t, errs[1].Msg, "field")
+}
+
+func TestConfigValidateMasking(t *testing.T) {
+	cfg := &Config{}
+	cfg.loadDefaults()
+	cfg.Connections = []MongoConfig{
+		{Name: "prod", Masking: []MaskRule{
+			{Field: "email", Mode: MaskEmail},
+			{Field: "token"},
+			{Field: "[", Mode: MaskFull},
+			{Field: "card", Mode: "stars"},
+		}},
+	}
+
+	errs := cfg.Validate()
+	require.Len(t, errs, 2)
+	assert.Contains(t, errs[0].Msg, `invalid masking field "["`)
+	assert.Contains(t, errs[1].Msg, `got "stars"`)
+}
+
+func TestConfigValidateKeyVault(t *testing.T) {
+	cfg := &Config{}
+	cfg.loadDefaults()
+	cfg.Connections = []MongoConfig{
+		{Name: "valid", KeyVault: &KeyVaultConfig{Namespace: "encryption.__keyVault", LocalKeyFile: "master.key"}},
+		{Name: "invalid", KeyVault: &KeyVaultConfig{Namespace: "encryption"}},
+	}
+
+	errs := cfg.Validate()
+	require.Len(t, errs, 2)
+	assert.Contains(t, errs[0].Msg, `connection invalid: key vault namespace must be "db.collection"`)
+	assert.Contains(t, errs[1].Msg, "connection invalid: key vault localKeyFile is required")
+}
+
+func TestConfigValidateTLS(t *testing.T) {
+	cfg := &Config{}
+	cfg.loadDefaults()
+	cfg.Connections = []MongoConfig{
+		{Name: "valid", TLS: &TLSConfig{CAFile: "ca.pem", CertFile: "client.pem"}},
+		{Name: "insecure", TLS: &TLSConfig{InsecureSkipVerify: true}},
+		{Name: "invalid", TLS: &TLSConfig{KeyFile: "client.key"}},
+	}
+
+	errs := cfg.Validate()
+	require.Len(t, errs, 1)
+	assert.Contains(t, errs[0].Msg, "connection invalid: tls keyFile requires certFile")
+}
+
+func TestConfigValidateScheduledQueries(t *testing.T) {
+	cfg := &Config{}
+	cfg.loadDefaults()
+	cfg.Connections = []MongoConfig{
+		{Name: "prod", ScheduledQueries: []ScheduledQueryConfig{
+			{Name: "failed jobs", Namespace: "app.jobs", Filter: `{ status: "failed" }`, Interval: 60, Threshold: "> 0"},
+			{Name: "backlog", Namespace: "app", Interval: 0, Threshold: "lots"},
+		}},
+	}
+
+	errs := cfg.Validate()
+	require.Len(t, errs, 3)
+	assert.Contains(t, errs[0].Msg, `scheduled query "backlog" namespace must be "db.collection"`)
+	assert.Contains(t, errs[1].Msg, "interval must be positive")
+	assert.Contains(t, errs[2].Msg, `got "lots"`)
+}
+
+func TestLoadStylesOfOlderVersion(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	xdg.Reload()
+	t.Cleanup(xdg.Reload)
+
+	stylePath, err := GetStylePath("dark-blue.yaml")
+	require.NoError(t, err)
+	require.NoError(t, os.MkdirAll(filepath.Dir(stylePath), 0755))
+	require.NoError(t, os.WriteFile(stylePath, []byte("global:\n"+
+		"  backgroundColor: \"#1E1E2E\"\n"+
+		"  inverseTextColor: \"#3D3D4D\"\n"+
+		"  typoColor: \"#FF9580\"\n"+
+		"others:\n"+
+		"  deleteButtonTextColor: \"#E0E0E0\"\n"+
+		"  modalTextColor: \"#E0E0E0\"\n"), 0644))
+
+	styles, err := LoadStyles("dark-blue.yaml", false)
+	require.NoError(t, err)
+	assert.Equal(t, Style("#1E1E2E"), styles.Global.BackgroundColor)
+
+	// removed keys are dropped from the file, others are only reported
+	content, err := os.ReadFile(stylePath)
+	require.NoError(t, err)
+	assert.NotContains(t, string(content), "inverseTextColor")
+	assert.NotContains(t, string(content), "deleteButtonTextColor")
+	var warnings []string
+	for _, warning := range util.ConfigWarnings() {
+		if warning.Path == stylePath {
+			warnings = append(warnings, warning.Error())
+		}
+	}
+	require.Len(t, warnings, 1)
+	assert.Contains(t, warnings[0], "dark-blue.yaml:3: field typoColor not found")
+}
+
+func TestConfigResetSectionKeepsConnections(t *testing.T) {
+	cfg := &Config{}
+	cfg.loadDefaults()
+	cfg.Connections = []MongoConfig{{Name: "prod", Host: "localhost", Port: 27017}}
+
+	err := cfg.ResetSection("connections")
+	assert.ErrorContains(t, err, "connections can't be reset")
+	assert.Len(t, cfg.Connections, 1)
+}
@@ -0,0 +1,119 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/kopecmaciej/vi-mongo/internal/util"
+)
+
+const (
+	// GrowthSampleInterval is the minimal time between samples
+	// of the same collection, so reopening it doesn't add samples
+	GrowthSampleInterval = time.Hour
+
+	maxGrowthSamples = 90
+)
+
+// GrowthSample is the size of the collection at the time it was opened
+type GrowthSample struct {
+	Time        time.Time `json:"time"`
+	Count       int64     `json:"count"`
+	StorageSize int64     `json:"storageSize"`
+}
+
+// LoadGrowthHistory loads samples of the collection,
+// from the oldest to the newest
+func LoadGrowthHistory(connection, namespace string) ([]GrowthSample, error) {
+	history, err := loadGrowthHistory(connection)
+	if err != nil {
+		return nil, err
+	}
+	return history[namespace], nil
+}
+
+// AddGrowthSample adds the sample of the collection, unless the last
+// sample was taken less than GrowthSampleInterval before. Only the newest
+// maxGrowthSamples samples of every collection are kept.
+func AddGrowthSample(connection, namespace string, sample GrowthSample) error {
+	historyPath, err := GetGrowthHistoryPath(connection)
+	if err != nil {
+		return err
+	}
+
+	return util.WithFileLock(historyPath, func() error {
+		history, err := loadGrowthHistory(connection)
+		if err != nil {
+			return err
+		}
+
+		samples := history[namespace]
+		if len(samples) > 0 && sample.Time.Sub(samples[len(samples)-1].Time) < GrowthSampleInterval {
+			return nil
+		}
+		samples = append(samples, sample)
+		if len(samples) > maxGrowthSamples {
+			samples = samples[len(samples)-maxGrowthSamples:]
+		}
+		history[namespace] = samples
+
+		bytes, err := json.Marshal(history)
+		if err != nil {
+			return err
+		}
+
+		return util.WriteFileAtomic(historyPath, bytes, 0644)
+	})
+}
+
+// GrowthPerDay returns how much the value grew per day
+// between the oldest and the newest sample
+func GrowthPerDay(samples []GrowthSample, value func(GrowthSample) int64) float64 {
+	if len(samples) < 2 {
+		return 0
+	}
+	first, last := samples[0], samples[len(samples)-1]
+	days := last.Time.Sub(first.Time).Hours() / 24
+	if days <= 0 {
+		return 0
+	}
+	return float64(value(last)-value(first)) / days
+}
+
+func loadGrowthHistory(connection string) (map[string][]GrowthSample, error) {
+	historyPath, err := GetGrowthHistoryPath(connection)
+	if err != nil {
+		return nil, err
+	}
+
+	history := map[string][]GrowthSample{}
+	bytes, err := os.ReadFile(historyPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return history, nil
+		}
+		return nil, err
+	}
+	if len(strings.TrimSpace(string(bytes))) == 0 {
+		return history, nil
+	}
+	if err := json.Unmarshal(bytes, &history); err != nil {
+		return nil, err
+	}
+
+	return history, nil
+}
+
+// GetGrowthHistoryPath returns the path to the file with growth
+// of collections of the connection, it's kept next to its stats history
+func GetGrowthHistoryPath(connection string) (string, error) {
+	statsPath, err := GetStatsHistoryPath(connection)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSuffix(statsPath, filepath.Ext(statsPath)) + ".growth.json", nil
+}
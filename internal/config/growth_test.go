@@ -0,0 +1,46 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/adrg/xdg"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGrowthHistory(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	xdg.Reload()
+	t.Cleanup(xdg.Reload)
+
+	samples, err := LoadGrowthHistory("local", "shop.orders")
+	require.NoError(t, err)
+	assert.Empty(t, samples)
+
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	require.NoError(t, AddGrowthSample("local", "shop.orders", GrowthSample{Time: start, Count: 100}))
+	// reopened shortly after, the sample is skipped
+	require.NoError(t, AddGrowthSample("local", "shop.orders", GrowthSample{Time: start.Add(time.Minute), Count: 101}))
+	require.NoError(t, AddGrowthSample("local", "shop.orders", GrowthSample{Time: start.Add(48 * time.Hour), Count: 300}))
+	require.NoError(t, AddGrowthSample("local", "shop.users", GrowthSample{Time: start, Count: 5}))
+
+	samples, err = LoadGrowthHistory("local", "shop.orders")
+	require.NoError(t, err)
+	require.Len(t, samples, 2)
+	assert.Equal(t, int64(300), samples[1].Count)
+	assert.Equal(t, 100.0, GrowthPerDay(samples, func(s GrowthSample) int64 { return s.Count }))
+
+	samples, err = LoadGrowthHistory("local", "shop.users")
+	require.NoError(t, err)
+	assert.Len(t, samples, 1)
+	assert.Equal(t, 0.0, GrowthPerDay(samples, func(s GrowthSample) int64 { return s.Count }))
+
+	for i := 0; i < maxGrowthSamples+5; i++ {
+		require.NoError(t, AddGrowthSample("local", "shop.events", GrowthSample{Time: start.Add(time.Duration(i) * GrowthSampleInterval), Count: int64(i)}))
+	}
+	samples, err = LoadGrowthHistory("local", "shop.events")
+	require.NoError(t, err)
+	require.Len(t, samples, maxGrowthSamples)
+	assert.Equal(t, int64(5), samples[0].Count)
+}
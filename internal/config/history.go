@@ -0,0 +1,357 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/kopecmaciej/vi-mongo/internal/util"
+)
+
+const (
+	HistoryFile       = "history.json"
+	legacyHistoryFile = "history.txt"
+
+	defaultMaxHistory = 10
+)
+
+type (
+	// HistoryEntry is a single query saved in the history file
+	HistoryEntry struct {
+		Query     string    `json:"query"`
+		Namespace string    `json:"namespace,omitempty"`
+		Timestamp time.Time `json:"timestamp"`
+		Count     int       `json:"count"`
+		// Pinned entries are never pruned and are displayed first
+		Pinned bool `json:"pinned,omitempty"`
+	}
+
+	// HistorySort defines order in which history entries are displayed
+	HistorySort int
+)
+
+const (
+	SortByRecency HistorySort = iota
+	SortByFrequency
+)
+
+// String returns the name of the sort mode
+func (s HistorySort) String() string {
+	switch s {
+	case SortByFrequency:
+		return "frequency"
+	default:
+		return "recency"
+	}
+}
+
+// Next returns the next sort mode
+func (s HistorySort) Next() HistorySort {
+	if s == SortByRecency {
+		return SortByFrequency
+	}
+	return SortByRecency
+}
+
+// LoadHistory loads history entries from the history file,
+// entries are returned from the oldest to the newest
+func LoadHistory() ([]HistoryEntry, error) {
+	historyPath, err := GetHistoryPath()
+	if err != nil {
+		return nil, err
+	}
+
+	bytes, err := os.ReadFile(historyPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return loadLegacyHistory()
+		}
+		return nil, err
+	}
+
+	entries := []HistoryEntry{}
+	if len(strings.TrimSpace(string(bytes))) == 0 {
+		return entries, nil
+	}
+	if err := json.Unmarshal(bytes, &entries); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// SaveHistory overwrites the history file with given entries
+func SaveHistory(entries []HistoryEntry) error {
+	return updateHistory(func([]HistoryEntry) []HistoryEntry {
+		return entries
+	})
+}
+
+// AddToHistory saves query to the history file. If a semantically identical
+// query is already there, it's replaced and its usage count is increased.
+// When history is full, the least recently used entries are removed.
+func AddToHistory(query, namespace string, maxEntries int) error {
+	entry := HistoryEntry{
+		Query:     query,
+		Namespace: namespace,
+		Timestamp: time.Now(),
+		Count:     1,
+	}
+
+	return updateHistory(func(entries []HistoryEntry) []HistoryEntry {
+		return addHistoryEntry(entries, entry, maxEntries)
+	})
+}
+
+// PruneHistory removes entries that were not used for longer than given
+// duration and returns the number of removed entries
+func PruneHistory(olderThan time.Duration) (int, error) {
+	removed := 0
+	err := updateHistory(func(entries []HistoryEntry) []HistoryEntry {
+		pruned := pruneHistoryEntries(entries, time.Now().Add(-olderThan))
+		removed = len(entries) - len(pruned)
+		return pruned
+	})
+
+	return removed, err
+}
+
+// TogglePinned pins or unpins entry with given query
+func TogglePinned(query string) error {
+	normalized := util.StripJsonWhitespaces(query)
+
+	return updateHistory(func(entries []HistoryEntry) []HistoryEntry {
+		for i := range entries {
+			if util.StripJsonWhitespaces(entries[i].Query) == normalized {
+				entries[i].Pinned = !entries[i].Pinned
+			}
+		}
+		return entries
+	})
+}
+
+// ClearHistory removes all entries except pinned ones from the history file
+func ClearHistory() error {
+	return updateHistory(func(entries []HistoryEntry) []HistoryEntry {
+		pinned := []HistoryEntry{}
+		for _, e := range entries {
+			if e.Pinned {
+				pinned = append(pinned, e)
+			}
+		}
+		return pinned
+	})
+}
+
+// SortHistory returns a copy of entries sorted by given mode,
+// pinned entries are placed first, then the most relevant ones
+func SortHistory(entries []HistoryEntry, by HistorySort) []HistoryEntry {
+	sorted := make([]HistoryEntry, len(entries))
+	copy(sorted, entries)
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Pinned != sorted[j].Pinned {
+			return sorted[i].Pinned
+		}
+		if by == SortByFrequency && sorted[i].Count != sorted[j].Count {
+			return sorted[i].Count > sorted[j].Count
+		}
+		return sorted[i].Timestamp.After(sorted[j].Timestamp)
+	})
+
+	return sorted
+}
+
+// GroupHistory sorts entries by given mode and splits them into pinned
+// entries, displayed in their own section at the top, and all others
+func GroupHistory(entries []HistoryEntry, by HistorySort) (pinned, others []HistoryEntry) {
+	for _, e := range SortHistory(entries, by) {
+		if e.Pinned {
+			pinned = append(pinned, e)
+		} else {
+			others = append(others, e)
+		}
+	}
+	return pinned, others
+}
+
+// FilterHistory returns entries which query or namespace fuzzy matches
+// the pattern, order of entries is preserved
+func FilterHistory(entries []HistoryEntry, pattern string) []HistoryEntry {
+	if pattern == "" {
+		return entries
+	}
+
+	filtered := []HistoryEntry{}
+	for _, e := range entries {
+		if _, ok := util.FuzzyMatch(pattern, e.Query); ok {
+			filtered = append(filtered, e)
+		} else if _, ok := util.FuzzyMatch(pattern, e.Namespace); ok {
+			filtered = append(filtered, e)
+		}
+	}
+
+	return filtered
+}
+
+// RecentQueries returns up to limit most recently executed queries
+// in the namespace, starting from the newest one. Entries saved without
+// namespace are included, as it's unknown where they were executed.
+func RecentQueries(entries []HistoryEntry, namespace string, limit int) []string {
+	matching := []HistoryEntry{}
+	for _, e := range entries {
+		if e.Namespace == "" || e.Namespace == namespace {
+			matching = append(matching, e)
+		}
+	}
+	// pinned entries are not placed first, only recency matters here
+	sort.SliceStable(matching, func(i, j int) bool {
+		return matching[i].Timestamp.After(matching[j].Timestamp)
+	})
+
+	queries := []string{}
+	for _, e := range matching {
+		if len(queries) >= limit {
+			break
+		}
+		queries = append(queries, strings.TrimSpace(e.Query))
+	}
+
+	return queries
+}
+
+// updateHistory reads the history file, applies update to its entries
+// and writes the result back. The whole operation holds the history lock,
+// so multiple running instances won't overwrite each other's changes.
+func updateHistory(update func([]HistoryEntry) []HistoryEntry) error {
+	historyPath, err := GetHistoryPath()
+	if err != nil {
+		return err
+	}
+
+	return util.WithFileLock(historyPath, func() error {
+		entries, err := LoadHistory()
+		if err != nil {
+			return err
+		}
+
+		bytes, err := json.MarshalIndent(update(entries), "", "  ")
+		if err != nil {
+			return err
+		}
+
+		return util.WriteFileAtomic(historyPath, bytes, 0644)
+	})
+}
+
+// addHistoryEntry adds entry to the history, merging it with duplicates
+// and evicting least recently used entries above the maxEntries limit.
+// Pinned entries are not counted to the limit and are never evicted.
+func addHistoryEntry(entries []HistoryEntry, entry HistoryEntry, maxEntries int) []HistoryEntry {
+	if maxEntries <= 0 {
+		maxEntries = defaultMaxHistory
+	}
+	normalized := util.StripJsonWhitespaces(entry.Query)
+
+	updated := make([]HistoryEntry, 0, len(entries)+1)
+	for _, e := range entries {
+		if util.StripJsonWhitespaces(e.Query) == normalized {
+			entry.Count += e.Count
+			entry.Pinned = entry.Pinned || e.Pinned
+			continue
+		}
+		updated = append(updated, e)
+	}
+	updated = append(updated, entry)
+
+	// keep entries ordered from the least to the most recently used
+	sort.SliceStable(updated, func(i, j int) bool {
+		return updated[i].Timestamp.Before(updated[j].Timestamp)
+	})
+
+	unpinned := 0
+	for _, e := range updated {
+		if !e.Pinned {
+			unpinned++
+		}
+	}
+
+	limited := make([]HistoryEntry, 0, len(updated))
+	for _, e := range updated {
+		if !e.Pinned && unpinned > maxEntries {
+			unpinned--
+			continue
+		}
+		limited = append(limited, e)
+	}
+
+	return limited
+}
+
+// pruneHistoryEntries returns entries that were used after given time
+// and all pinned entries
+func pruneHistoryEntries(entries []HistoryEntry, before time.Time) []HistoryEntry {
+	pruned := []HistoryEntry{}
+	for _, e := range entries {
+		if !e.Pinned && e.Timestamp.Before(before) {
+			continue
+		}
+		pruned = append(pruned, e)
+	}
+
+	return pruned
+}
+
+// loadLegacyHistory loads history from the old plain text file,
+// where every line was a single query
+func loadLegacyHistory() ([]HistoryEntry, error) {
+	configDir, err := util.GetConfigDir()
+	if err != nil {
+		return nil, err
+	}
+
+	legacyPath := configDir + "/" + legacyHistoryFile
+	bytes, err := os.ReadFile(legacyPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []HistoryEntry{}, nil
+		}
+		return nil, err
+	}
+
+	modTime := time.Now()
+	if info, err := os.Stat(legacyPath); err == nil {
+		modTime = info.ModTime()
+	}
+
+	entries := []HistoryEntry{}
+	lines := strings.Split(string(bytes), "\n")
+	for i, line := range lines {
+		if line == "" {
+			continue
+		}
+		entries = append(entries, HistoryEntry{
+			Query: line,
+			// keep the order of the old file
+			Timestamp: modTime.Add(time.Duration(i-len(lines)) * time.Second),
+			Count:     1,
+		})
+	}
+
+	return entries, nil
+}
+
+// GetHistoryPath returns the path to the history file
+func GetHistoryPath() (string, error) {
+	configDir, err := util.GetConfigDir()
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(configDir, 0755); err != nil {
+		return "", err
+	}
+
+	return configDir + "/" + HistoryFile, nil
+}
@@ -0,0 +1,169 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSortHistory(t *testing.T) {
+	now := time.Now()
+	entries := []HistoryEntry{
+		{Query: `{ "a": 1 }`, Timestamp: now.Add(-3 * time.Hour), Count: 5},
+		{Query: `{ "b": 1 }`, Timestamp: now.Add(-1 * time.Hour), Count: 1},
+		{Query: `{ "c": 1 }`, Timestamp: now.Add(-2 * time.Hour), Count: 3},
+	}
+
+	byRecency := SortHistory(entries, SortByRecency)
+	assert.Equal(t, `{ "b": 1 }`, byRecency[0].Query)
+	assert.Equal(t, `{ "c": 1 }`, byRecency[1].Query)
+	assert.Equal(t, `{ "a": 1 }`, byRecency[2].Query)
+
+	byFrequency := SortHistory(entries, SortByFrequency)
+	assert.Equal(t, `{ "a": 1 }`, byFrequency[0].Query)
+	assert.Equal(t, `{ "c": 1 }`, byFrequency[1].Query)
+	assert.Equal(t, `{ "b": 1 }`, byFrequency[2].Query)
+
+	// original slice should not be modified
+	assert.Equal(t, `{ "a": 1 }`, entries[0].Query)
+}
+
+func TestGroupHistory(t *testing.T) {
+	now := time.Now()
+	entries := []HistoryEntry{
+		{Query: `{ "a": 1 }`, Timestamp: now.Add(-1 * time.Hour), Count: 9},
+		{Query: `{ "b": 1 }`, Timestamp: now.Add(-4 * time.Hour), Count: 7, Pinned: true},
+		{Query: `{ "c": 1 }`, Timestamp: now.Add(-2 * time.Hour), Count: 2},
+		{Query: `{ "d": 1 }`, Timestamp: now.Add(-3 * time.Hour), Count: 5, Pinned: true},
+	}
+	queries := func(entries []HistoryEntry) []string {
+		out := []string{}
+		for _, e := range entries {
+			out = append(out, e.Query)
+		}
+		return out
+	}
+
+	// pinned entries are grouped before others in every sort mode
+	pinned, others := GroupHistory(entries, SortByRecency)
+	assert.Equal(t, []string{`{ "d": 1 }`, `{ "b": 1 }`}, queries(pinned))
+	assert.Equal(t, []string{`{ "a": 1 }`, `{ "c": 1 }`}, queries(others))
+
+	pinned, others = GroupHistory(entries, SortByFrequency)
+	assert.Equal(t, []string{`{ "b": 1 }`, `{ "d": 1 }`}, queries(pinned))
+	assert.Equal(t, []string{`{ "a": 1 }`, `{ "c": 1 }`}, queries(others))
+
+	pinned, others = GroupHistory(entries[:1], SortByRecency)
+	assert.Empty(t, pinned)
+	assert.Len(t, others, 1)
+}
+
+func TestFilterHistory(t *testing.T) {
+	entries := []HistoryEntry{
+		{Query: `{ "name": "John" }`, Namespace: "shop.users"},
+		{Query: `{ "total": { "$gt": 100 } }`, Namespace: "shop.orders"},
+	}
+
+	assert.Len(t, FilterHistory(entries, ""), 2)
+
+	filtered := FilterHistory(entries, "john")
+	assert.Len(t, filtered, 1)
+	assert.Equal(t, "shop.users", filtered[0].Namespace)
+
+	filtered = FilterHistory(entries, "orders")
+	assert.Len(t, filtered, 1)
+	assert.Equal(t, `{ "total": { "$gt": 100 } }`, filtered[0].Query)
+
+	assert.Empty(t, FilterHistory(entries, "xyz"))
+}
+
+func TestRecentQueries(t *testing.T) {
+	now := time.Now()
+	entries := []HistoryEntry{
+		{Query: `{ "a": 1 }`, Namespace: "shop.users", Timestamp: now.Add(-3 * time.Hour), Pinned: true},
+		{Query: `{ "b": 1 }`, Namespace: "shop.orders", Timestamp: now.Add(-1 * time.Hour)},
+		{Query: `{ "c": 1 } `, Namespace: "shop.users", Timestamp: now.Add(-2 * time.Hour)},
+		{Query: `{ "d": 1 }`, Timestamp: now.Add(-4 * time.Hour)},
+	}
+
+	assert.Equal(t, []string{`{ "c": 1 }`, `{ "a": 1 }`, `{ "d": 1 }`}, RecentQueries(entries, "shop.users", 5))
+	assert.Equal(t, []string{`{ "c": 1 }`, `{ "a": 1 }`}, RecentQueries(entries, "shop.users", 2))
+	assert.Equal(t, []string{`{ "d": 1 }`}, RecentQueries(entries, "shop.products", 5))
+}
+
+func TestAddHistoryEntry(t *testing.T) {
+	now := time.Now()
+	entries := []HistoryEntry{
+		{Query: `{ "a": 1 }`, Timestamp: now.Add(-3 * time.Hour), Count: 2},
+		{Query: `{ "b": 1 }`, Timestamp: now.Add(-2 * time.Hour), Count: 1},
+		{Query: `{ "c": 1 }`, Timestamp: now.Add(-1 * time.Hour), Count: 1},
+	}
+
+	t.Run("Deduplicate whitespace insensitive", func(t *testing.T) {
+		updated := addHistoryEntry(entries, HistoryEntry{Query: `{"a":1}`, Timestamp: now, Count: 1}, 10)
+		assert.Len(t, updated, 3)
+		assert.Equal(t, `{"a":1}`, updated[2].Query)
+		assert.Equal(t, 3, updated[2].Count)
+	})
+
+	t.Run("Whitespace inside strings is significant", func(t *testing.T) {
+		entries := []HistoryEntry{{Query: `{ "name": "John Doe" }`, Timestamp: now.Add(-time.Hour), Count: 1}}
+		updated := addHistoryEntry(entries, HistoryEntry{Query: `{ "name": "JohnDoe" }`, Timestamp: now, Count: 1}, 10)
+		assert.Len(t, updated, 2)
+	})
+
+	t.Run("Evict least recently used", func(t *testing.T) {
+		updated := addHistoryEntry(entries, HistoryEntry{Query: `{ "d": 1 }`, Timestamp: now, Count: 1}, 3)
+		assert.Len(t, updated, 3)
+		assert.Equal(t, `{ "b": 1 }`, updated[0].Query)
+		assert.Equal(t, `{ "d": 1 }`, updated[2].Query)
+	})
+}
+
+func TestPruneHistoryEntries(t *testing.T) {
+	now := time.Now()
+	entries := []HistoryEntry{
+		{Query: `{ "a": 1 }`, Timestamp: now.Add(-10 * 24 * time.Hour)},
+		{Query: `{ "b": 1 }`, Timestamp: now.Add(-2 * 24 * time.Hour)},
+		{Query: `{ "c": 1 }`, Timestamp: now},
+	}
+
+	pruned := pruneHistoryEntries(entries, now.Add(-7*24*time.Hour))
+	assert.Len(t, pruned, 2)
+	assert.Equal(t, `{ "b": 1 }`, pruned[0].Query)
+
+	assert.Empty(t, pruneHistoryEntries(entries, now.Add(time.Hour)))
+}
+
+func TestPinnedHistoryEntries(t *testing.T) {
+	now := time.Now()
+	entries := []HistoryEntry{
+		{Query: `{ "a": 1 }`, Timestamp: now.Add(-30 * 24 * time.Hour), Pinned: true},
+		{Query: `{ "b": 1 }`, Timestamp: now.Add(-2 * time.Hour)},
+		{Query: `{ "c": 1 }`, Timestamp: now.Add(-1 * time.Hour)},
+	}
+
+	t.Run("Never pruned", func(t *testing.T) {
+		pruned := pruneHistoryEntries(entries, now.Add(time.Hour))
+		assert.Len(t, pruned, 1)
+		assert.True(t, pruned[0].Pinned)
+	})
+
+	t.Run("Never evicted", func(t *testing.T) {
+		updated := addHistoryEntry(entries, HistoryEntry{Query: `{ "d": 1 }`, Timestamp: now}, 2)
+		assert.Len(t, updated, 3)
+		assert.Equal(t, `{ "a": 1 }`, updated[0].Query)
+		assert.Equal(t, `{ "c": 1 }`, updated[1].Query)
+	})
+
+	t.Run("Pin kept on duplicate", func(t *testing.T) {
+		updated := addHistoryEntry(entries, HistoryEntry{Query: `{"a":1}`, Timestamp: now}, 10)
+		assert.True(t, updated[len(updated)-1].Pinned)
+	})
+
+	t.Run("Displayed first", func(t *testing.T) {
+		sorted := SortHistory(entries, SortByRecency)
+		assert.Equal(t, `{ "a": 1 }`, sorted[0].Query)
+	})
+}
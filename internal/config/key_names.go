@@ -0,0 +1,194 @@
+package config
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// modifiers in the order in which tcell reports them in key names,
+// Meta is treated as Alt, as terminals report Option/Alt key differently
+var modifiers = []struct {
+	name string
+	mask tcell.ModMask
+}{
+	{"Shift", tcell.ModShift},
+	{"Alt", tcell.ModAlt},
+	{"Meta", tcell.ModAlt},
+	{"Ctrl", tcell.ModCtrl},
+}
+
+// keyAliases maps key names that are reported differently
+// by terminals to a single name
+var keyAliases = map[string]string{
+	// in some terminals ctrl+H often is seen as backspace
+	"Backspace": "Ctrl+H",
+	"Shift+Tab": "Backtab",
+	"Escape":    "Esc",
+	"Return":    "Enter",
+}
+
+// parsedKey is a key name split into modifiers and the base key
+type parsedKey struct {
+	mod tcell.ModMask
+	// key is set for named keys, e.g. Enter or F1
+	key tcell.Key
+	// ch is set for runes, e.g. Alt+a
+	ch rune
+}
+
+// normalizeKeyName returns canonical name of the key, so names
+// from config and names reported by tcell can be compared,
+// e.g. "alt+Rune[a]" and "Meta+a" both become "Alt+a"
+func normalizeKeyName(name string) (string, bool) {
+	parsed, ok := parseKeyName(name)
+	if !ok {
+		return name, false
+	}
+
+	return parsed.String(), true
+}
+
+// parseKeyName parses key name in format used by tcell, with optional
+// modifiers, e.g. "Enter", "Ctrl+D", "Alt+Rune[a]", "Alt+a", "Shift+F5"
+func parseKeyName(name string) (parsedKey, bool) {
+	parsed := parsedKey{key: tcell.KeyRune}
+
+	if alias, ok := keyAliases[name]; ok {
+		name = alias
+	}
+
+	base := name
+	for {
+		prefix, rest, found := strings.Cut(base, "+")
+		if !found || rest == "" {
+			break
+		}
+		mask, ok := modifierMask(prefix)
+		if !ok {
+			break
+		}
+		parsed.mod |= mask
+		base = rest
+	}
+	if base == "" {
+		return parsed, false
+	}
+
+	if alias, ok := keyAliases[base]; ok && !strings.Contains(alias, "+") {
+		base = alias
+	}
+
+	if strings.HasPrefix(base, "Rune[") && strings.HasSuffix(base, "]") {
+		base = strings.TrimSuffix(strings.TrimPrefix(base, "Rune["), "]")
+	}
+	if base == " " || strings.EqualFold(base, "Space") {
+		parsed.ch = ' '
+		return parsed.normalize(), true
+	}
+
+	if utf8.RuneCountInString(base) == 1 {
+		parsed.ch, _ = utf8.DecodeRuneInString(base)
+		if upper := unicode.ToUpper(parsed.ch); parsed.mod&tcell.ModCtrl != 0 && upper >= 'A' && upper <= 'Z' {
+			// Ctrl+letter is reported by tcell as a control key
+			parsed.key = tcell.KeyCtrlA + tcell.Key(upper-'A')
+			parsed.ch = 0
+		}
+		return parsed.normalize(), true
+	}
+
+	for k, keyName := range tcell.KeyNames {
+		if strings.EqualFold(keyName, base) {
+			parsed.key = k
+			return parsed.normalize(), true
+		}
+		if parsed.mod&tcell.ModCtrl != 0 && strings.EqualFold(keyName, "Ctrl-"+base) {
+			parsed.key = k
+			return parsed.normalize(), true
+		}
+	}
+
+	return parsed, false
+}
+
+func modifierMask(name string) (tcell.ModMask, bool) {
+	for _, m := range modifiers {
+		if strings.EqualFold(m.name, name) {
+			return m.mask, true
+		}
+	}
+	return tcell.ModNone, false
+}
+
+// normalize handles keys that terminals report in more than one way
+func (p parsedKey) normalize() parsedKey {
+	switch {
+	case p.key == tcell.KeyBacktab:
+		p.mod &^= tcell.ModShift
+	case p.key == tcell.KeyTab && p.mod&tcell.ModShift != 0:
+		p.key = tcell.KeyBacktab
+		p.mod &^= tcell.ModShift
+	case p.key == tcell.KeyRune && p.ch != ' ':
+		// shift is already part of the rune, e.g. "A"
+		p.mod &^= tcell.ModShift
+	case p.key >= tcell.KeyF13 && p.key <= tcell.KeyF24 && p.mod == tcell.ModNone:
+		// some terminals report Shift+F1-F12 as F13-F24
+		p.key -= tcell.KeyF13 - tcell.KeyF1
+		p.mod = tcell.ModShift
+	}
+	return p
+}
+
+// String returns the name of the key in the format used in keybindings
+func (p parsedKey) String() string {
+	var name string
+	switch {
+	case p.key == tcell.KeyRune && p.ch == ' ':
+		name = "Space"
+	case p.key == tcell.KeyRune:
+		name = string(p.ch)
+	case p.key >= tcell.KeyCtrlA && p.key <= tcell.KeyCtrlZ:
+		// control keys share codes with some named keys, e.g. Ctrl+I is Tab
+		keyName, ok := tcell.KeyNames[p.key]
+		if ok && p.mod&tcell.ModCtrl == 0 && !strings.HasPrefix(keyName, "Ctrl-") {
+			name = keyName
+		} else {
+			name = string(rune('A' + p.key - tcell.KeyCtrlA))
+			p.mod |= tcell.ModCtrl
+		}
+	default:
+		name = strings.TrimPrefix(tcell.KeyNames[p.key], "Ctrl-")
+		if name != tcell.KeyNames[p.key] {
+			p.mod |= tcell.ModCtrl
+		}
+	}
+
+	mods := []string{}
+	for _, m := range modifiers {
+		if m.name != "Meta" && p.mod&m.mask != 0 {
+			mods = append(mods, m.name)
+		}
+	}
+	mods = append(mods, name)
+
+	return strings.Join(mods, "+")
+}
+
+// EventKey returns tcell event that is triggered by the key
+func (p parsedKey) EventKey() *tcell.EventKey {
+	return tcell.NewEventKey(p.key, p.ch, p.mod)
+}
+
+// isValidKeyName checks if name is a key name as reported by tcell,
+// optionally prefixed with modifiers, e.g. "Enter" or "Ctrl+D".
+// Single characters without modifiers are runes, not keys.
+func isValidKeyName(name string) bool {
+	parsed, ok := parseKeyName(name)
+	if !ok {
+		return false
+	}
+
+	return parsed.key != tcell.KeyRune || parsed.mod != tcell.ModNone || parsed.ch == ' '
+}
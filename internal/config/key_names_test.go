@@ -0,0 +1,136 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeKeyName(t *testing.T) {
+	testCases := []struct {
+		name     string
+		expected string
+	}{
+		{"Enter", "Enter"},
+		{"enter", "Enter"},
+		{"Ctrl+D", "Ctrl+D"},
+		{"ctrl+d", "Ctrl+D"},
+		{"Ctrl-D", "Ctrl+D"},
+		{"Backspace", "Ctrl+H"},
+		{"Ctrl+H", "Ctrl+H"},
+		{"Backspace2", "Backspace2"},
+		{"Alt+Rune[a]", "Alt+a"},
+		{"Meta+a", "Alt+a"},
+		{"Alt+Shift+Rune[A]", "Alt+A"},
+		{"Shift+Tab", "Backtab"},
+		{"Backtab", "Backtab"},
+		{"Shift+Up", "Shift+Up"},
+		{"Ctrl+Left", "Ctrl+Left"},
+		{"F1", "F1"},
+		{"F12", "F12"},
+		{"Shift+F5", "Shift+F5"},
+		{"F17", "Shift+F5"},
+		{"Rune[ ]", "Space"},
+		{"Ctrl+Space", "Ctrl+Space"},
+		{"Tab", "Tab"},
+		{"Esc", "Esc"},
+		{"Escape", "Esc"},
+		{"Alt+Enter", "Alt+Enter"},
+		{"Alt++", "Alt++"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			normalized, ok := normalizeKeyName(tc.name)
+			assert.True(t, ok)
+			assert.Equal(t, tc.expected, normalized)
+		})
+	}
+
+	_, ok := normalizeKeyName("Ctrl+Nope")
+	assert.False(t, ok)
+}
+
+func TestContainsModifiedKeys(t *testing.T) {
+	kb := &KeyBindings{}
+	testCases := []struct {
+		name      string
+		configKey Key
+		event     *tcell.EventKey
+		expected  bool
+	}{
+		{"Alt with rune", Key{Keys: []string{"Alt+a"}}, tcell.NewEventKey(tcell.KeyRune, 'a', tcell.ModAlt), true},
+		{"Alt with other rune", Key{Keys: []string{"Alt+a"}}, tcell.NewEventKey(tcell.KeyRune, 'b', tcell.ModAlt), false},
+		{"Rune without Alt", Key{Keys: []string{"Alt+a"}}, tcell.NewEventKey(tcell.KeyRune, 'a', tcell.ModNone), false},
+		{"Meta as Alt", Key{Keys: []string{"Meta+x"}}, tcell.NewEventKey(tcell.KeyRune, 'x', tcell.ModAlt), true},
+		{"Function key", Key{Keys: []string{"F5"}}, tcell.NewEventKey(tcell.KeyF5, 0, tcell.ModNone), true},
+		{"Shift function key", Key{Keys: []string{"Shift+F5"}}, tcell.NewEventKey(tcell.KeyF5, 0, tcell.ModShift), true},
+		{"Shift function key as F17", Key{Keys: []string{"Shift+F5"}}, tcell.NewEventKey(tcell.KeyF17, 0, tcell.ModNone), true},
+		{"Shift arrow", Key{Keys: []string{"Shift+Down"}}, tcell.NewEventKey(tcell.KeyDown, 0, tcell.ModShift), true},
+		{"Arrow without shift", Key{Keys: []string{"Shift+Down"}}, tcell.NewEventKey(tcell.KeyDown, 0, tcell.ModNone), false},
+		{"Shift+Tab as Backtab", Key{Keys: []string{"Shift+Tab"}}, tcell.NewEventKey(tcell.KeyBacktab, 0, tcell.ModNone), true},
+		{"Ctrl+H as Backspace", Key{Keys: []string{"Ctrl+H"}}, tcell.NewEventKey(tcell.KeyBackspace, 0, tcell.ModNone), true},
+		{"Backspace as Ctrl+H", Key{Keys: []string{"Backspace"}}, tcell.NewEventKey(tcell.KeyCtrlH, 0, tcell.ModCtrl), true},
+		{"Ctrl letter", Key{Keys: []string{"Ctrl+D"}}, tcell.NewEventKey(tcell.KeyCtrlD, 0, tcell.ModCtrl), true},
+		{"Space", Key{Keys: []string{"Space"}}, tcell.NewEventKey(tcell.KeyRune, ' ', tcell.ModNone), true},
+		{"Rune", Key{Runes: []string{"a"}}, tcell.NewEventKey(tcell.KeyRune, 'a', tcell.ModNone), true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, kb.Contains(tc.configKey, tc.event.Name()))
+		})
+	}
+}
+
+func TestConvertStrKeyToTcellKey(t *testing.T) {
+	kb := &KeyBindings{}
+
+	event, ok := kb.ConvertStrKeyToTcellKey("Alt+a")
+	assert.True(t, ok)
+	assert.Equal(t, tcell.KeyRune, event.Key())
+	assert.Equal(t, 'a', event.Rune())
+	assert.Equal(t, tcell.ModAlt, event.Modifiers())
+
+	event, ok = kb.ConvertStrKeyToTcellKey("Shift+F5")
+	assert.True(t, ok)
+	assert.Equal(t, tcell.KeyF5, event.Key())
+	assert.Equal(t, tcell.ModShift, event.Modifiers())
+
+	event, ok = kb.ConvertStrKeyToTcellKey("Ctrl+D")
+	assert.True(t, ok)
+	assert.Equal(t, tcell.KeyCtrlD, event.Key())
+
+	_, ok = kb.ConvertStrKeyToTcellKey("Hyper+x")
+	assert.False(t, ok)
+}
+
+func TestContainsMultiByteRunes(t *testing.T) {
+	kb := &KeyBindings{}
+	testCases := []struct {
+		name      string
+		configKey Key
+		ch        rune
+		expected  bool
+	}{
+		{"German umlaut", Key{Runes: []string{"ä"}}, 'ä', true},
+		{"Sharp s", Key{Runes: []string{"ß"}}, 'ß', true},
+		{"Different umlaut", Key{Runes: []string{"ö"}}, 'ä', false},
+		{"Multi-byte does not match its first byte", Key{Runes: []string{"\xc3"}}, 'ä', false},
+		{"Polish letter", Key{Runes: []string{"ł"}}, 'ł', true},
+		{"CJK", Key{Runes: []string{"中"}}, '中', true},
+		{"Closing bracket", Key{Runes: []string{"]"}}, ']', true},
+		{"ASCII", Key{Runes: []string{"a"}}, 'a', true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			event := tcell.NewEventKey(tcell.KeyRune, tc.ch, tcell.ModNone)
+			assert.Equal(t, tc.expected, kb.Contains(tc.configKey, event.Name()))
+		})
+	}
+
+	altEvent := tcell.NewEventKey(tcell.KeyRune, 'ö', tcell.ModAlt)
+	assert.True(t, kb.Contains(Key{Keys: []string{"Alt+ö"}}, altEvent.Name()))
+}
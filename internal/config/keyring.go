@@ -0,0 +1,114 @@
+package config
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+const (
+	// keyringService is the service passwords are saved under in the keyring
+	keyringService = "vi-mongo"
+	// keyringAccountEnv passes the account to the PowerShell script,
+	// so the name of the connection doesn't have to be quoted
+	keyringAccountEnv = "VI_MONGO_KEYRING_ACCOUNT"
+	// windowsVault loads the Credential Manager vault of the user
+	windowsVault = `$ErrorActionPreference = 'Stop'; ` +
+		`[void][Windows.Security.Credentials.PasswordVault,Windows.Security.Credentials,ContentType=WindowsRuntime]; ` +
+		`$vault = New-Object Windows.Security.Credentials.PasswordVault; `
+)
+
+// systemKeyring keeps passwords in the keyring of the system with its
+// command line tools: security on macOS, secret-tool of Secret Service
+// on Linux and PowerShell with Credential Manager on Windows. Passwords
+// are passed on stdin, so they aren't visible in the list of processes.
+type systemKeyring struct{}
+
+func (systemKeyring) Get(account string) (string, error) {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("security", "find-generic-password", "-s", keyringService, "-a", account, "-w")
+	case "windows":
+		cmd = powerShell(account, `$credential = $vault.Retrieve('`+keyringService+`', $env:`+keyringAccountEnv+`); `+
+			`$credential.RetrievePassword(); [Console]::Out.Write($credential.Password)`)
+	default:
+		cmd = exec.Command("secret-tool", "lookup", "service", keyringService, "account", account)
+	}
+
+	password, err := runKeyring(cmd, "")
+	if err != nil {
+		return "", err
+	}
+	// security ends the password with the new line
+	password = strings.TrimSuffix(password, "\n")
+	if password == "" {
+		return "", fmt.Errorf("no password of connection %s in the keyring", account)
+	}
+	return password, nil
+}
+
+func (systemKeyring) Set(account, password string) error {
+	var cmd *exec.Cmd
+	var input string
+	switch runtime.GOOS {
+	case "darwin":
+		// commands of the interactive mode are read from stdin,
+		// the password is given in hex so it needs no quoting
+		cmd = exec.Command("security", "-i")
+		input = fmt.Sprintf("add-generic-password -U -s %s -a %s -X %s\n",
+			keyringService, strconv.Quote(account), hex.EncodeToString([]byte(password)))
+	case "windows":
+		cmd = powerShell(account, `$vault.Add((New-Object Windows.Security.Credentials.PasswordCredential(`+
+			`'`+keyringService+`', $env:`+keyringAccountEnv+`, [Console]::In.ReadToEnd())))`)
+		input = password
+	default:
+		cmd = exec.Command("secret-tool", "store", "--label", keyringService+": "+account,
+			"service", keyringService, "account", account)
+		input = password
+	}
+
+	_, err := runKeyring(cmd, input)
+	return err
+}
+
+func (systemKeyring) Delete(account string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("security", "delete-generic-password", "-s", keyringService, "-a", account)
+	case "windows":
+		cmd = powerShell(account, `$vault.Remove($vault.Retrieve('`+keyringService+`', $env:`+keyringAccountEnv+`))`)
+	default:
+		cmd = exec.Command("secret-tool", "clear", "service", keyringService, "account", account)
+	}
+
+	_, err := runKeyring(cmd, "")
+	return err
+}
+
+func powerShell(account, script string) *exec.Cmd {
+	cmd := exec.Command("powershell", "-NoProfile", "-NonInteractive", "-Command", windowsVault+script)
+	cmd.Env = append(os.Environ(), keyringAccountEnv+"="+account)
+	return cmd
+}
+
+// runKeyring runs the command with the input on stdin and returns
+// its output, errors contain what the command wrote to stderr
+func runKeyring(cmd *exec.Cmd, input string) (string, error) {
+	cmd.Stdin = strings.NewReader(input)
+	out, err := cmd.Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+			return "", fmt.Errorf("%s: %w: %s", cmd.Args[0], err, strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return "", fmt.Errorf("%s: %w", cmd.Args[0], err)
+	}
+	return string(out), nil
+}
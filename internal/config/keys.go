@@ -1,10 +1,12 @@
 package config
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 	"reflect"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/gdamore/tcell/v2"
 	"github.com/kopecmaciej/vi-mongo/internal/util"
@@ -36,9 +38,9 @@ type (
 	// It holds the keys and runes that are used to trigger the action
 	// and a description of the action that will be displayed in the help
 	Key struct {
-		Keys        []string `json:"keys,omitempty"`
-		Runes       []string `json:"runes,omitempty"`
-		Description string   `json:"description"`
+		Keys        []string `json:"keys,omitempty" yaml:"keys,omitempty"`
+		Runes       []string `json:"runes,omitempty" yaml:"runes,omitempty"`
+		Description string   `json:"description" yaml:"description,omitempty"`
 	}
 
 	// GlobalKeys is a struct that holds the global keybindings
@@ -48,6 +50,10 @@ type (
 		ToggleFullScreenHelp Key `json:"toggleFullScreenHelp"`
 		OpenConnection       Key `json:"openConnection"`
 		ShowStyleModal       Key `json:"showStyleModal"`
+		ShowJobs             Key `json:"showJobs"`
+		// CopyScreen and SaveScreen capture the screen as plain text
+		CopyScreen Key `json:"copyScreen"`
+		SaveScreen Key `json:"saveScreen"`
 	}
 
 	MainKeys struct {
@@ -56,6 +62,12 @@ type (
 		FocusContent   Key `json:"focusContent"`
 		HideDatabase   Key `json:"hideDatabases"`
 		ShowServerInfo Key `json:"showServerInfo"`
+		// ToggleReadPreference switches reads between primary and secondaryPreferred
+		ToggleReadPreference Key `json:"toggleReadPreference"`
+		ShowSlowOps          Key `json:"showSlowOps"`
+		ShowSharding         Key `json:"showSharding"`
+		ToggleTutorial       Key `json:"toggleTutorial"`
+		ShowUsers            Key `json:"showUsers"`
 	}
 
 	DatabaseKeys struct {
@@ -64,6 +76,8 @@ type (
 		CollapseAll      Key `json:"collapseAll"`
 		AddCollection    Key `json:"addCollection"`
 		DeleteCollection Key `json:"deleteCollection"`
+		Dump             Key `json:"dump"`
+		Restore          Key `json:"restore"`
 	}
 
 	ContentKeys struct {
@@ -83,15 +97,49 @@ type (
 		NextPage          Key `json:"nextPage"`
 		PreviousPage      Key `json:"previousPage"`
 		ToggleSort        Key `json:"toggleSort"`
+		FilterBuilder     Key `json:"filterBuilder"`
+		QueryOptions      Key `json:"queryOptions"`
+		ToggleCount       Key `json:"toggleCount"`
+		ToggleDeleted     Key `json:"toggleDeleted"`
+		DocumentHistory   Key `json:"documentHistory"`
+		FanOutQuery       Key `json:"fanOutQuery"`
+		SwitchTenant      Key `json:"switchTenant"`
+		Search            Key `json:"search"`
+		VectorSearch      Key `json:"vectorSearch"`
+		WidenColumn       Key `json:"widenColumn"`
+		NarrowColumn      Key `json:"narrowColumn"`
+		AutoFitColumns    Key `json:"autoFitColumns"`
+		PinColumn         Key `json:"pinColumn"`
+		FlattenColumns    Key `json:"flattenColumns"`
+		LookupBuilder     Key `json:"lookupBuilder"`
+		AutoRefresh       Key `json:"autoRefresh"`
+		OnlyChanges       Key `json:"onlyChanges"`
+		CountByField      Key `json:"countByField"`
+		CopyResults       Key `json:"copyResults"`
+		ExportResults     Key `json:"exportResults"`
+		ImportCSV         Key `json:"importCSV"`
+		ImportJSON        Key `json:"importJSON"`
+		Aggregation       Key `json:"aggregation"`
+		CollectionStats   Key `json:"collectionStats"`
+		Watch             Key `json:"watch"`
+		GeoQuery          Key `json:"geoQuery"`
+		CopyMapLink       Key `json:"copyMapLink"`
+		TimeRange         Key `json:"timeRange"`
+		Archive           Key `json:"archive"`
 
 		// MultipleSelect    Key      `json:"multipleSelect"`
 		// ClearSelection   Key      `json:"clearSelection"`
 	}
 
 	QueryBar struct {
-		ShowHistory Key `json:"showHistory"`
-		ClearInput  Key `json:"clearInput"`
-		Paste       Key `json:"paste"`
+		ShowHistory   Key `json:"showHistory"`
+		ClearInput    Key `json:"clearInput"`
+		Paste         Key `json:"paste"`
+		ExpandSnippet Key `json:"expandSnippet"`
+		FormatQuery   Key `json:"formatQuery"`
+		MinifyQuery   Key `json:"minifyQuery"`
+		PreviousQuery Key `json:"previousQuery"`
+		NextQuery     Key `json:"nextQuery"`
 	}
 
 	SortBar struct {
@@ -137,6 +185,10 @@ type (
 		ClearHistory Key `json:"clearHistory"`
 		AcceptEntry  Key `json:"acceptEntry"`
 		CloseHistory Key `json:"closeHistory"`
+		Search       Key `json:"search"`
+		ToggleSort   Key `json:"toggleSort"`
+		PruneHistory Key `json:"pruneHistory"`
+		TogglePin    Key `json:"togglePin"`
 	}
 )
 
@@ -154,6 +206,18 @@ func (k *KeyBindings) loadDefaults() {
 			Keys:        []string{"Ctrl+T"},
 			Description: "Toggle style change modal",
 		},
+		ShowJobs: Key{
+			Keys:        []string{"Ctrl+B"},
+			Description: "Show background jobs",
+		},
+		CopyScreen: Key{
+			Keys:        []string{"F12"},
+			Description: "Copy screen as text",
+		},
+		SaveScreen: Key{
+			Keys:        []string{"Shift+F12"},
+			Description: "Save screen to text file",
+		},
 	}
 
 	k.Main = MainKeys{
@@ -175,7 +239,27 @@ func (k *KeyBindings) loadDefaults() {
 		},
 		ShowServerInfo: Key{
 			Keys:        []string{"Ctrl+K"},
-			Description: "Show server info",
+			Description: "Show server dashboard",
+		},
+		ToggleReadPreference: Key{
+			Keys:        []string{"Ctrl+R"},
+			Description: "Toggle read from secondary",
+		},
+		ShowSlowOps: Key{
+			Keys:        []string{"Ctrl+P"},
+			Description: "Show slow operations",
+		},
+		ShowSharding: Key{
+			Keys:        []string{"Ctrl+E"},
+			Description: "Show sharded cluster topology",
+		},
+		ToggleTutorial: Key{
+			Keys:        []string{"Ctrl+G"},
+			Description: "Start or stop the guided tour",
+		},
+		ShowUsers: Key{
+			Keys:        []string{"Ctrl+U"},
+			Description: "Manage users and roles",
 		},
 	}
 
@@ -200,6 +284,14 @@ func (k *KeyBindings) loadDefaults() {
 			Runes:       []string{"D"},
 			Description: "Delete collection",
 		},
+		Dump: Key{
+			Runes:       []string{"X"},
+			Description: "Dump database or collection",
+		},
+		Restore: Key{
+			Runes:       []string{"R"},
+			Description: "Restore dump",
+		},
 	}
 
 	k.Content = ContentKeys{
@@ -258,7 +350,123 @@ func (k *KeyBindings) loadDefaults() {
 		},
 		ToggleSort: Key{
 			Runes:       []string{"s"},
-			Description: "Toggle sort",
+			Description: "Edit sort",
+		},
+		FilterBuilder: Key{
+			Runes:       []string{"F"},
+			Description: "Build filter",
+		},
+		QueryOptions: Key{
+			Runes:       []string{"o"},
+			Description: "Query options",
+		},
+		ToggleCount: Key{
+			Runes:       []string{"#"},
+			Description: "Toggle estimated count",
+		},
+		ToggleDeleted: Key{
+			Runes:       []string{"x"},
+			Description: "Toggle deleted",
+		},
+		DocumentHistory: Key{
+			Runes:       []string{"H"},
+			Description: "Document history",
+		},
+		FanOutQuery: Key{
+			Runes:       []string{"m"},
+			Description: "Query multiple collections",
+		},
+		SwitchTenant: Key{
+			Runes:       []string{"t"},
+			Description: "Switch tenant",
+		},
+		Search: Key{
+			Runes:       []string{"S"},
+			Description: "Atlas Search",
+		},
+		VectorSearch: Key{
+			Runes:       []string{"V"},
+			Description: "Vector search",
+		},
+		WidenColumn: Key{
+			Runes:       []string{">"},
+			Description: "Widen column",
+		},
+		NarrowColumn: Key{
+			Runes:       []string{"<"},
+			Description: "Narrow column",
+		},
+		AutoFitColumns: Key{
+			Runes:       []string{"="},
+			Description: "Toggle auto-fit columns",
+		},
+		PinColumn: Key{
+			Runes:       []string{"|"},
+			Description: "Pin column",
+		},
+		FlattenColumns: Key{
+			Runes:       []string{"."},
+			Description: "Toggle nested fields as columns",
+		},
+		LookupBuilder: Key{
+			Runes:       []string{"L"},
+			Description: "Build $lookup stage",
+		},
+		AutoRefresh: Key{
+			Runes:       []string{"A"},
+			Description: "Toggle auto-refresh",
+		},
+		OnlyChanges: Key{
+			Runes:       []string{"~"},
+			Description: "Toggle only changes",
+		},
+		CountByField: Key{
+			Runes:       []string{"B"},
+			Description: "Count by column",
+		},
+		CopyResults: Key{
+			Runes:       []string{"Y"},
+			Description: "Copy results",
+		},
+		ExportResults: Key{
+			Runes:       []string{"X"},
+			Description: "Export results to file",
+		},
+		ImportCSV: Key{
+			Runes:       []string{"I"},
+			Description: "Import CSV",
+		},
+		ImportJSON: Key{
+			Runes:       []string{"J"},
+			Description: "Import JSON",
+		},
+		Aggregation: Key{
+			Runes:       []string{"g"},
+			Description: "Aggregation pipeline",
+		},
+		GeoQuery: Key{
+			Runes:       []string{"G"},
+			Description: "Geo query",
+		},
+		CopyMapLink: Key{
+			Runes:       []string{"M"},
+			Description: "Copy map link of location",
+		},
+		TimeRange: Key{
+			Runes:       []string{"T"},
+			Description: "Time range of time series",
+		},
+		Archive: Key{
+			Runes:       []string{"Z"},
+			Description: "Archive matching documents",
+		},
+		CollectionStats: Key{
+			Runes:       []string{"i"},
+			Description: "Collection stats",
+		},
+		Watch: Key{
+			Runes:       []string{"w"},
+			Description: "Watch changes",
 		},
 		NextDocument: Key{
 			Runes:       []string{"]"},
@@ -291,6 +499,26 @@ func (k *KeyBindings) loadDefaults() {
 			Keys:        []string{"Ctrl+V"},
 			Description: "Paste from clipboard",
 		},
+		ExpandSnippet: Key{
+			Keys:        []string{"Alt+s"},
+			Description: "Expand snippet or go to next placeholder",
+		},
+		FormatQuery: Key{
+			Keys:        []string{"Alt+f"},
+			Description: "Format query",
+		},
+		MinifyQuery: Key{
+			Keys:        []string{"Alt+m"},
+			Description: "Minify query",
+		},
+		PreviousQuery: Key{
+			Keys:        []string{"Up"},
+			Description: "Previous recent query",
+		},
+		NextQuery: Key{
+			Keys:        []string{"Down"},
+			Description: "Next recent query",
+		},
 	}
 
 	k.SortBar = SortBar{
@@ -389,6 +617,22 @@ func (k *KeyBindings) loadDefaults() {
 			Keys:        []string{"Esc", "Ctrl+Y"},
 			Description: "Close history",
 		},
+		Search: Key{
+			Runes:       []string{"/"},
+			Description: "Search history",
+		},
+		ToggleSort: Key{
+			Runes:       []string{"s"},
+			Description: "Sort by recency/frequency",
+		},
+		PruneHistory: Key{
+			Runes:       []string{"P"},
+			Description: "Remove entries older than N days",
+		},
+		TogglePin: Key{
+			Runes:       []string{"p"},
+			Description: "Pin/unpin entry",
+		},
 	}
 }
 
@@ -402,7 +646,7 @@ func LoadKeybindings() (*KeyBindings, error) {
 		return defaultKeybindings, nil
 	}
 
-	keybindingsPath, err := getKeybindingsPath()
+	keybindingsPath, err := GetKeybindingsPath()
 	if err != nil {
 		return nil, err
 	}
@@ -410,6 +654,121 @@ func LoadKeybindings() (*KeyBindings, error) {
 	return util.LoadConfigFile(defaultKeybindings, keybindingsPath)
 }
 
+// Validate checks if all configured keys are valid key names
+// and all runes are single characters
+func (kb *KeyBindings) Validate() []util.ConfigError {
+	return validateKeysInStruct(reflect.ValueOf(*kb), "")
+}
+
+func validateKeysInStruct(val reflect.Value, path string) []util.ConfigError {
+	var errs []util.ConfigError
+
+	for i := 0; i < val.NumField(); i++ {
+		field := val.Field(i)
+		name := strings.Split(val.Type().Field(i).Tag.Get("json"), ",")[0]
+		if path != "" {
+			name = path + "." + name
+		}
+
+		if field.Type() == reflect.TypeOf(Key{}) {
+			key := field.Interface().(Key)
+			for _, k := range key.Keys {
+				if k != DisabledKey && !isValidKeyName(k) {
+					errs = append(errs, util.ConfigError{
+						Value: k,
+						Msg:   fmt.Sprintf("%s: invalid key name %q", name, k),
+					})
+				}
+			}
+			for _, r := range key.Runes {
+				if r != DisabledKey && utf8.RuneCountInString(r) != 1 {
+					errs = append(errs, util.ConfigError{
+						Value: r,
+						Msg:   fmt.Sprintf("%s: rune %q must be a single character", name, r),
+					})
+				}
+			}
+		} else if field.Kind() == reflect.Struct {
+			errs = append(errs, validateKeysInStruct(field, name)...)
+		}
+	}
+
+	return errs
+}
+
+// ResetKeybindings resets given section of the keybindings file
+// to default values, or the whole file if section is empty
+func ResetKeybindings(section string) error {
+	defaultKeybindings := &KeyBindings{}
+	defaultKeybindings.loadDefaults()
+
+	keybindingsPath, err := GetKeybindingsPath()
+	if err != nil {
+		return err
+	}
+
+	if section == "" {
+		return util.WriteConfigFile(defaultKeybindings, keybindingsPath)
+	}
+
+	keybindings := &KeyBindings{}
+	bytes, err := os.ReadFile(keybindingsPath)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err == nil {
+		// file may be invalid, so it's read without strict validation
+		if err := json.Unmarshal(bytes, keybindings); err != nil {
+			return fmt.Errorf("%s is not a valid json, reset all keybindings instead: %w", keybindingsPath, err)
+		}
+	}
+
+	if err := util.ResetSection(keybindings, defaultKeybindings, "json", section); err != nil {
+		return err
+	}
+	util.MergeConfigs(keybindings, defaultKeybindings)
+
+	return util.WriteConfigFile(keybindings, keybindingsPath)
+}
+
+// ApplyOverrides replaces keys under given paths, path is built from
+// json names of sections and keys, e.g. "content.deleteDocument"
+func (kb *KeyBindings) ApplyOverrides(overrides map[string]Key) error {
+	for path, key := range overrides {
+		field := reflect.ValueOf(kb).Elem()
+		for _, name := range strings.Split(path, ".") {
+			field = fieldByJsonName(field, name)
+			if !field.IsValid() {
+				return fmt.Errorf("unknown key %q", path)
+			}
+		}
+		if field.Type() != reflect.TypeOf(Key{}) {
+			return fmt.Errorf("%q is a section, not a key", path)
+		}
+
+		// keep the description if it's not overridden
+		if key.Description == "" {
+			key.Description = field.Interface().(Key).Description
+		}
+		field.Set(reflect.ValueOf(key))
+	}
+
+	return nil
+}
+
+// fieldByJsonName returns field of the struct with given json name
+func fieldByJsonName(val reflect.Value, name string) reflect.Value {
+	if val.Kind() != reflect.Struct {
+		return reflect.Value{}
+	}
+	for i := 0; i < val.NumField(); i++ {
+		if strings.Split(val.Type().Field(i).Tag.Get("json"), ",")[0] == name {
+			return val.Field(i)
+		}
+	}
+	return reflect.Value{}
+}
+
 // extractKeysFromStruct extracts all Key structs from a reflect.Value
 func extractKeysFromStruct(val reflect.Value) []Key {
 	var keys []Key
@@ -417,7 +776,10 @@ func extractKeysFromStruct(val reflect.Value) []Key {
 	for i := 0; i < val.NumField(); i++ {
 		field := val.Field(i)
 		if field.Type() == reflect.TypeOf(Key{}) {
-			keys = append(keys, field.Interface().(Key))
+			key := field.Interface().(Key)
+			if !key.IsDisabled() {
+				keys = append(keys, key)
+			}
 		} else if field.Kind() == reflect.Struct {
 			keys = append(keys, extractKeysFromStruct(field)...)
 		}
@@ -469,14 +831,68 @@ func (kb KeyBindings) GetKeysForElement(elementId string) ([]OrderedKeys, error)
 	return keys, nil
 }
 
-// ConvertStrKeyToTcellKey converts string key to tcell key
-func (kb *KeyBindings) ConvertStrKeyToTcellKey(key string) (tcell.Key, bool) {
-	for k, v := range tcell.KeyNames {
-		if v == key {
-			return k, true
+// ConvertStrKeyToTcellKey converts string key, optionally with modifiers,
+// to tcell event that is triggered by this key, e.g. "Alt+a" or "Shift+F5"
+func (kb *KeyBindings) ConvertStrKeyToTcellKey(key string) (*tcell.EventKey, bool) {
+	parsed, ok := parseKeyName(key)
+	if !ok {
+		return nil, false
+	}
+	return parsed.EventKey(), true
+}
+
+// DisabledKey is a value that unbinds the action, it can be used
+// in place of the whole key or as a single key or rune
+const DisabledKey = "none"
+
+// UnmarshalJSON allows to disable the key with "none" instead of
+// the whole object, empty lists are kept, so they are not replaced
+// by defaults while merging. Unknown fields are reported as warnings
+// of the whole file.
+func (k *Key) UnmarshalJSON(data []byte) error {
+	var value string
+	if err := json.Unmarshal(data, &value); err == nil {
+		if !strings.EqualFold(value, DisabledKey) {
+			return fmt.Errorf("key must be an object or %q, got %q", DisabledKey, value)
 		}
+		*k = Key{Keys: []string{}, Runes: []string{}}
+		return nil
 	}
-	return -1, false
+
+	type key Key
+	return json.Unmarshal(data, (*key)(k))
+}
+
+// MarshalJSON keeps empty lists, as they mean the key was disabled
+func (k Key) MarshalJSON() ([]byte, error) {
+	out := struct {
+		Keys        *[]string `json:"keys,omitempty"`
+		Runes       *[]string `json:"runes,omitempty"`
+		Description string    `json:"description"`
+	}{Description: k.Description}
+	if k.Keys != nil {
+		out.Keys = &k.Keys
+	}
+	if k.Runes != nil {
+		out.Runes = &k.Runes
+	}
+
+	return json.Marshal(out)
+}
+
+// IsDisabled returns true if no key or rune triggers the action
+func (k *Key) IsDisabled() bool {
+	for _, key := range k.Keys {
+		if key != DisabledKey {
+			return false
+		}
+	}
+	for _, r := range k.Runes {
+		if r != DisabledKey {
+			return false
+		}
+	}
+	return true
 }
 
 // Contains checks if the keybindings contains the key
@@ -485,24 +901,27 @@ func (kb *KeyBindings) Contains(configKey Key, namedKey string) bool {
 	if namedKey == "Rune[ ]" {
 		namedKey = "Space"
 	}
-	// in some terminals ctrl+H often is seen as backspace
-	if namedKey == "Backspace" {
-		namedKey = "Ctrl+H"
-	}
 
-	if strings.HasPrefix(namedKey, "Rune") {
-		namedKey = strings.TrimPrefix(namedKey, "Rune")
+	// rune can be longer than one byte, e.g. "Rune[ä]"
+	if r, ok := strings.CutPrefix(namedKey, "Rune["); ok && strings.HasSuffix(r, "]") {
+		r = strings.TrimSuffix(r, "]")
 		for _, k := range configKey.Runes {
-			if k == namedKey[1:2] {
+			if k == r {
 				return true
 			}
 		}
 	}
 
+	// names are normalized, so e.g. "Alt+Rune[a]" matches "Alt+a"
+	// and "Backspace" matches "Ctrl+H"
+	eventKey, eventOk := normalizeKeyName(namedKey)
 	for _, k := range configKey.Keys {
 		if k == namedKey {
 			return true
 		}
+		if configKey, ok := normalizeKeyName(k); ok && eventOk && configKey == eventKey {
+			return true
+		}
 	}
 
 	return false
@@ -527,7 +946,8 @@ func (k *Key) String() string {
 	return keyString
 }
 
-func getKeybindingsPath() (string, error) {
+// GetKeybindingsPath returns the path to the keybindings file
+func GetKeybindingsPath() (string, error) {
 	configDir, err := util.GetConfigDir()
 	if err != nil {
 		return "", err
@@ -0,0 +1,156 @@
+package config
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/kopecmaciej/vi-mongo/internal/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeyBindingsValidate(t *testing.T) {
+	kb := &KeyBindings{}
+	kb.loadDefaults()
+	assert.Empty(t, kb.Validate())
+
+	kb.Global.ToggleFullScreenHelp.Keys = []string{"Ctrl+Foo"}
+	kb.History.Search.Runes = []string{"ab"}
+
+	errs := kb.Validate()
+	assert.Len(t, errs, 2)
+	assert.Equal(t, "Ctrl+Foo", errs[0].Value)
+	assert.Contains(t, errs[0].Msg, "global.toggleFullScreenHelp")
+	assert.Equal(t, "ab", errs[1].Value)
+}
+
+// TestInputBarKeysDontConflict checks that keys of bars aren't used by
+// global and main keys, as those are handled first and never reach bars
+func TestInputBarKeysDontConflict(t *testing.T) {
+	kb := &KeyBindings{}
+	kb.loadDefaults()
+
+	keysOf := func(section interface{}) map[string]Key {
+		keys := map[string]Key{}
+		val := reflect.ValueOf(section)
+		for i := 0; i < val.NumField(); i++ {
+			if key, ok := val.Field(i).Interface().(Key); ok {
+				keys[val.Type().Field(i).Name] = key
+			}
+		}
+		return keys
+	}
+	handledFirst := keysOf(kb.Global)
+	for name, key := range keysOf(kb.Main) {
+		handledFirst[name] = key
+	}
+
+	for section, bar := range map[string]interface{}{"queryBar": kb.QueryBar, "sortBar": kb.SortBar} {
+		for name, key := range keysOf(bar) {
+			for _, k := range key.Keys {
+				for otherName, other := range handledFirst {
+					assert.False(t, kb.Contains(other, k), "%s.%s key %s is used by %s", section, name, k, otherName)
+				}
+			}
+		}
+	}
+}
+
+func TestExpandSnippetWithoutShift(t *testing.T) {
+	kb := &KeyBindings{}
+	kb.loadDefaults()
+
+	event := tcell.NewEventKey(tcell.KeyRune, 's', tcell.ModAlt)
+	assert.True(t, kb.Contains(kb.QueryBar.ExpandSnippet, event.Name()))
+}
+
+func TestFormatQueryKeysWithoutShift(t *testing.T) {
+	kb := &KeyBindings{}
+	kb.loadDefaults()
+
+	format := tcell.NewEventKey(tcell.KeyRune, 'f', tcell.ModAlt)
+	assert.True(t, kb.Contains(kb.QueryBar.FormatQuery, format.Name()))
+	minify := tcell.NewEventKey(tcell.KeyRune, 'm', tcell.ModAlt)
+	assert.True(t, kb.Contains(kb.QueryBar.MinifyQuery, minify.Name()))
+}
+
+func TestIsValidKeyName(t *testing.T) {
+	testCases := []struct {
+		name     string
+		expected bool
+	}{
+		{"Enter", true},
+		{"Esc", true},
+		{"Ctrl+D", true},
+		{"Ctrl+Left", true},
+		{"Backtab", true},
+		{"Space", true},
+		{"Alt+Enter", true},
+		{"F5", true},
+		{"Enterr", false},
+		{"Ctrl+", false},
+		{"Ctrl+H", true},
+		{"", false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, isValidKeyName(tc.name))
+		})
+	}
+}
+
+func TestKeyBindingsApplyOverrides(t *testing.T) {
+	kb := &KeyBindings{}
+	kb.loadDefaults()
+	description := kb.Content.DeleteDocument.Description
+
+	err := kb.ApplyOverrides(map[string]Key{
+		"content.deleteDocument":                   {Keys: []string{"Ctrl+D"}},
+		"connection.connectionForm.saveConnection": {Runes: []string{"S"}, Description: "Save"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Ctrl+D"}, kb.Content.DeleteDocument.Keys)
+	assert.Empty(t, kb.Content.DeleteDocument.Runes)
+	assert.Equal(t, description, kb.Content.DeleteDocument.Description)
+	assert.Equal(t, "Save", kb.Connection.ConnectionForm.SaveConnection.Description)
+
+	assert.Error(t, kb.ApplyOverrides(map[string]Key{"content.unknown": {}}))
+	assert.Error(t, kb.ApplyOverrides(map[string]Key{"content": {}}))
+}
+
+func TestDisabledKey(t *testing.T) {
+	defaults := &KeyBindings{}
+	defaults.loadDefaults()
+
+	data := []byte(`{
+		"content": {
+			"deleteDocument": "none",
+			"addDocument": {"keys": [], "runes": []},
+			"editDocument": {"runes": ["none"]}
+		}
+	}`)
+	kb := &KeyBindings{}
+	assert.NoError(t, json.Unmarshal(data, kb))
+	util.MergeConfigs(kb, defaults)
+
+	assert.True(t, kb.Content.DeleteDocument.IsDisabled())
+	assert.Equal(t, defaults.Content.DeleteDocument.Description, kb.Content.DeleteDocument.Description)
+	assert.True(t, kb.Content.AddDocument.IsDisabled())
+	assert.False(t, kb.Contains(kb.Content.AddDocument, "Rune[a]"))
+	assert.True(t, kb.Content.EditDocument.IsDisabled())
+	assert.False(t, kb.Content.ViewDocument.IsDisabled())
+	assert.Empty(t, kb.Validate())
+
+	// disabled keys must survive writing the file again
+	bytes, err := json.Marshal(kb)
+	assert.NoError(t, err)
+	reloaded := &KeyBindings{}
+	assert.NoError(t, json.Unmarshal(bytes, reloaded))
+	util.MergeConfigs(reloaded, defaults)
+	assert.True(t, reloaded.Content.DeleteDocument.IsDisabled())
+	assert.True(t, reloaded.Content.AddDocument.IsDisabled())
+
+	assert.Error(t, json.Unmarshal([]byte(`{"content": {"deleteDocument": "off"}}`), &KeyBindings{}))
+}
@@ -0,0 +1,276 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/kopecmaciej/vi-mongo/internal/util"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// DefaultLocale is the locale of texts built into the app
+	DefaultLocale = "en"
+	LocalesDir    = "locales"
+	// keyMessagePrefix prefixes ids of keybinding descriptions,
+	// e.g. "keys.content.deleteDocument"
+	keyMessagePrefix = "keys."
+)
+
+// Messages is a catalog of texts shown in the UI keyed by message id,
+// e.g. "help.title". Descriptions of keybindings are keyed by "keys."
+// and the path of the key, e.g. "keys.content.deleteDocument".
+type Messages map[string]string
+
+// defaultMessages are english texts, used for ids missing in the locale
+var defaultMessages = Messages{
+	"button.ok":               "Ok",
+	"button.cancel":           "Cancel",
+	"button.delete":           "Delete",
+	"button.add":              "Add",
+	"button.apply":            "Apply",
+	"button.archive":          "Archive",
+	"button.archiveAndDelete": "Archive and delete",
+	"button.back":             "Back",
+	"button.change":           "Change",
+	"button.changeExpiry":     "Change expiry",
+	"button.close":            "Close",
+	"button.create":           "Create",
+	"button.createIndex":      "Create index",
+	"button.drop":             "Drop",
+	"button.edit":             "Edit",
+	"button.editRaw":          "Edit raw",
+	"button.exit":             "Exit",
+	"button.finish":           "Finish",
+	"button.import":           "Import",
+	"button.kill":             "Kill",
+	"button.load":             "Load",
+	"button.next":             "Next",
+	"button.removeLast":       "Remove last",
+	"button.replace":          "Replace",
+	"button.reset":            "Reset",
+	"button.run":              "Run",
+	"button.runWithoutIndex":  "Run without index",
+	"button.save":             "Save",
+	"button.saveAndConnect":   "Save and Connect",
+	"button.search":           "Search",
+
+	"modal.error.title":               "Error",
+	"modal.info.title":                "Info",
+	"modal.delete.title":              "Delete",
+	"modal.aggregation.confirm.title": "Replace collection",
+	"modal.aggregation.info.title":    "Info",
+	"modal.aggregation.title":         "Aggregation (Ctrl+R - run, Ctrl+N - add stage, Ctrl+P - explain, Esc - close)",
+	"modal.archive.confirm.title":     "Delete archived documents",
+	"modal.archive.title":             "Archive documents",
+	"modal.csvImport.columns.title":   "Columns (Enter - rename, t - type, s - skip, k - upsert key, d - dry run, r - start over, i - import)",
+	"modal.csvImport.preview.title":   "Existing documents affected",
+	"modal.fanOut.title":              "Query multiple collections (Tab - switch, Esc - close)",
+	"modal.filter.title":              "Filter builder",
+	"modal.geoQuery.confirm.title":    "Missing 2dsphere index",
+	"modal.geoQuery.title":            "Geo query",
+	"modal.history.pinned":            "Pinned",
+	"modal.history.others":            "History",
+	"modal.history.prune.title":       "Prune history",
+	"modal.jobs.logs.title":           "Log",
+	"modal.jobs.title":                "Jobs (c - cancel, x - clear finished, Esc - close)",
+	"modal.jsonImport.rejected.title": "Rejected documents",
+	"modal.loading.title":             "Loading",
+	"modal.lookup.title":              "$lookup builder",
+	"modal.queryOptions.title":        "Query options",
+	"modal.search.title":              "Atlas Search (Tab - switch, Esc - close)",
+	"modal.sharding.chunks.title":     "Chunks of sharded collections",
+	"modal.sharding.title":            "Sharding",
+	"modal.slowOps.confirm.title":     "Kill operation",
+	"modal.slowOps.details.title":     "Query shape",
+	"modal.slowOps.title":             "Slow operations (K - kill, Esc - close)",
+	"modal.sort.title":                "Sort builder",
+	"modal.stagePicker.title":         "Add stage (Enter - add, Esc - close)",
+	"modal.stats.expiry.title":        "Expire measurements after",
+	"modal.style.title":               "Change Style",
+	"modal.timeRange.title":           "Time range",
+	"modal.users.create.title":        "Create user",
+	"modal.users.drop.title":          "Drop user",
+	"modal.vectorSearch.title":        "Vector Search (Tab - switch, Esc - close)",
+	"modal.versions.preview.title":    "Version",
+	"modal.versions.title":            "Document history (Enter - restore, Esc - close)",
+
+	"welcome.recent.title": "Recent (1-9 - open, Tab - settings)",
+	"welcome.title":        "Welcome to Vi Mongo",
+
+	"connection.form.title": "New connection",
+	"connection.list.title": "Saved connections",
+
+	"header.title": "Basic Info",
+
+	"databases.add.title": "Add collection",
+	"databases.title":     "Databases",
+
+	"content.delete.confirm":     "Are you sure you want to delete document of id: [blue]%s",
+	"content.delete.confirmSoft": "Are you sure you want to mark document of id: [blue]%s[-] as deleted? It will have %s set",
+	"content.delete.error":       "Error deleting document",
+	"content.export.title":       "Export results",
+	"content.json.title":         "JSON View",
+	"content.title":              "Content",
+
+	"peeker.title": "Document Details",
+
+	"help.title":              "Help",
+	"help.section.Global":     "Global",
+	"help.section.Help":       "Help",
+	"help.section.Welcome":    "Welcome",
+	"help.section.Connection": "Connection",
+	"help.section.Main":       "Main Layout",
+	"help.section.Database":   "Database",
+	"help.section.Content":    "Content",
+	"help.section.QueryBar":   "QueryBar",
+	"help.section.SortBar":    "SortBar",
+	"help.section.Peeker":     "Peeker",
+	"help.section.History":    "History",
+}
+
+var (
+	messagesMu sync.RWMutex
+	messages   = defaultMessages
+)
+
+// LoadMessages loads the catalog of the locale from the locales directory
+// of the config dir, e.g. locales/de.yaml, texts missing in the file
+// are taken from the english catalog
+func LoadMessages(locale string) (Messages, error) {
+	loaded := make(Messages, len(defaultMessages))
+	for id, text := range defaultMessages {
+		loaded[id] = text
+	}
+
+	if os.Getenv("ENV") == "vi-dev" {
+		return loaded, nil
+	}
+	if err := ExtractLocales(); err != nil {
+		return nil, err
+	}
+	if locale == "" || locale == DefaultLocale {
+		return loaded, nil
+	}
+
+	localePath, err := GetLocalePath(locale)
+	if err != nil {
+		return nil, err
+	}
+	bytes, err := os.ReadFile(localePath)
+	if err != nil {
+		return nil, fmt.Errorf("locale %q not found: %w", locale, err)
+	}
+
+	var texts map[string]string
+	if err := yaml.Unmarshal(bytes, &texts); err != nil {
+		return nil, fmt.Errorf("%s is not a valid locale file: %w", localePath, err)
+	}
+	for id, text := range texts {
+		if text != "" {
+			loaded[id] = text
+		}
+	}
+
+	return loaded, nil
+}
+
+// SetMessages sets the catalog texts of the UI are taken from
+func SetMessages(m Messages) {
+	messagesMu.Lock()
+	defer messagesMu.Unlock()
+	messages = m
+}
+
+// Msg returns the text of the message with given id from the current
+// catalog, the english text if it's not translated, or the id if it's unknown
+func Msg(id string) string {
+	messagesMu.RLock()
+	defer messagesMu.RUnlock()
+	if text, ok := messages[id]; ok {
+		return text
+	}
+	if text, ok := defaultMessages[id]; ok {
+		return text
+	}
+	return id
+}
+
+// Msgf formats the text of the message with given id
+func Msgf(id string, args ...any) string {
+	return fmt.Sprintf(Msg(id), args...)
+}
+
+// Translate replaces descriptions of keys with texts from the catalog,
+// keys without translation keep their description
+func (kb *KeyBindings) Translate(m Messages) {
+	forEachKey(reflect.ValueOf(kb).Elem(), "", func(keyPath string, key *Key) {
+		if text, ok := m[keyMessagePrefix+keyPath]; ok && text != "" {
+			key.Description = text
+		}
+	})
+}
+
+// forEachKey calls fn with every key of the struct and its json path
+func forEachKey(val reflect.Value, path string, fn func(keyPath string, key *Key)) {
+	for i := 0; i < val.NumField(); i++ {
+		field := val.Field(i)
+		name := strings.Split(val.Type().Field(i).Tag.Get("json"), ",")[0]
+		if path != "" {
+			name = path + "." + name
+		}
+
+		if field.Type() == reflect.TypeOf(Key{}) {
+			fn(name, field.Addr().Interface().(*Key))
+		} else if field.Kind() == reflect.Struct {
+			forEachKey(field, name, fn)
+		}
+	}
+}
+
+// ExtractLocales writes the english catalog, with descriptions of all keys,
+// to the locales directory, so it can be copied and translated
+func ExtractLocales() error {
+	localePath, err := GetLocalePath(DefaultLocale)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(localePath); err == nil {
+		return nil
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+
+	texts := make(Messages, len(defaultMessages))
+	for id, text := range defaultMessages {
+		texts[id] = text
+	}
+	defaultKeybindings := &KeyBindings{}
+	defaultKeybindings.loadDefaults()
+	forEachKey(reflect.ValueOf(defaultKeybindings).Elem(), "", func(keyPath string, key *Key) {
+		texts[keyMessagePrefix+keyPath] = key.Description
+	})
+
+	bytes, err := yaml.Marshal(texts)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(path.Dir(localePath), 0755); err != nil {
+		return err
+	}
+	return util.WriteFileAtomic(localePath, bytes, 0644)
+}
+
+// GetLocalePath returns the path to the catalog file of the locale
+func GetLocalePath(locale string) (string, error) {
+	configDir, err := util.GetConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%s/%s/%s.yaml", configDir, LocalesDir, locale), nil
+}
@@ -0,0 +1,84 @@
+package config
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/adrg/xdg"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadMessages(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	xdg.Reload()
+	t.Cleanup(xdg.Reload)
+
+	messages, err := LoadMessages(DefaultLocale)
+	require.NoError(t, err)
+	assert.Equal(t, "Main Layout", messages["help.section.Main"])
+
+	// english catalog is written as a template for translations
+	enPath, err := GetLocalePath(DefaultLocale)
+	require.NoError(t, err)
+	en, err := os.ReadFile(enPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(en), "keys.content.deleteDocument: Delete")
+
+	_, err = LoadMessages("de")
+	assert.Error(t, err)
+
+	dePath, err := GetLocalePath("de")
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(dePath, []byte(
+		"help.title: Hilfe\n"+
+			"button.ok: \"\"\n"+
+			"keys.content.deleteDocument: Dokument löschen\n"), 0644))
+
+	messages, err = LoadMessages("de")
+	require.NoError(t, err)
+	assert.Equal(t, "Hilfe", messages["help.title"])
+	assert.Equal(t, "Ok", messages["button.ok"])
+	assert.Equal(t, "Main Layout", messages["help.section.Main"])
+
+	kb := &KeyBindings{}
+	kb.loadDefaults()
+	kb.Translate(messages)
+	assert.Equal(t, "Dokument löschen", kb.Content.DeleteDocument.Description)
+	assert.Equal(t, "Add new", kb.Content.AddDocument.Description)
+}
+
+func TestMsg(t *testing.T) {
+	t.Cleanup(func() { SetMessages(defaultMessages) })
+
+	SetMessages(Messages{"help.title": "Hilfe"})
+	assert.Equal(t, "Hilfe", Msg("help.title"))
+	assert.Equal(t, "Ok", Msg("button.ok"))
+	assert.Equal(t, "unknown.id", Msg("unknown.id"))
+	assert.Equal(t, "Are you sure you want to delete document of id: [blue]1", Msgf("content.delete.confirm", "1"))
+}
+
+func TestUIMessagesAreDefined(t *testing.T) {
+	// ids passed to Msg and Msgf, or kept in constants of button labels
+	used := regexp.MustCompile(`Msgf?\("([\w.]+)"[,)]|Button\s*=\s*"([\w.]+)"`)
+	err := filepath.WalkDir("../tui", func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !strings.HasSuffix(path, ".go") {
+			return err
+		}
+		src, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		for _, match := range used.FindAllStringSubmatch(string(src), -1) {
+			id := match[1] + match[2]
+			_, ok := defaultMessages[id]
+			assert.True(t, ok, "%s: message %q is missing in the catalog", path, id)
+		}
+		return nil
+	})
+	require.NoError(t, err)
+}
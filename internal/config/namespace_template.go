@@ -0,0 +1,81 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// NamespaceTemplate is a name of collection with a single placeholder
+// for the tenant, e.g. "tenant_{id}_orders" matches "tenant_42_orders"
+// with tenant "42"
+type NamespaceTemplate struct {
+	template string
+	prefix   string
+	suffix   string
+}
+
+// ParseNamespaceTemplate parses the template, it must contain exactly
+// one placeholder in curly braces
+func ParseNamespaceTemplate(template string) (NamespaceTemplate, error) {
+	start := strings.Index(template, "{")
+	end := strings.Index(template, "}")
+	if start == -1 || end < start+2 {
+		return NamespaceTemplate{}, fmt.Errorf("namespace template %q must contain a placeholder, e.g. {id}", template)
+	}
+	suffix := template[end+1:]
+	if strings.ContainsAny(suffix, "{}") || strings.Contains(template[:start], "}") {
+		return NamespaceTemplate{}, fmt.Errorf("namespace template %q must contain only one placeholder", template)
+	}
+
+	return NamespaceTemplate{template: template, prefix: template[:start], suffix: suffix}, nil
+}
+
+// String returns the template as it was defined
+func (t NamespaceTemplate) String() string {
+	return t.template
+}
+
+// Match returns the tenant of the collection,
+// ok is false if the collection doesn't match the template
+func (t NamespaceTemplate) Match(coll string) (tenant string, ok bool) {
+	if len(coll) <= len(t.prefix)+len(t.suffix) {
+		return "", false
+	}
+	if !strings.HasPrefix(coll, t.prefix) || !strings.HasSuffix(coll, t.suffix) {
+		return "", false
+	}
+	return coll[len(t.prefix) : len(coll)-len(t.suffix)], true
+}
+
+// Collection returns the name of the collection of the tenant
+func (t NamespaceTemplate) Collection(tenant string) string {
+	return t.prefix + tenant + t.suffix
+}
+
+// Tenants returns sorted tenants of collections matching the template
+func (t NamespaceTemplate) Tenants(colls []string) []string {
+	tenants := []string{}
+	for _, coll := range colls {
+		if tenant, ok := t.Match(coll); ok {
+			tenants = append(tenants, tenant)
+		}
+	}
+	sort.Strings(tenants)
+	return tenants
+}
+
+// GetNamespaceTemplate returns the first template matching
+// the collection together with the tenant of the collection
+func (m *MongoConfig) GetNamespaceTemplate(coll string) (NamespaceTemplate, string, bool) {
+	for _, template := range m.NamespaceTemplates {
+		parsed, err := ParseNamespaceTemplate(template)
+		if err != nil {
+			continue
+		}
+		if tenant, ok := parsed.Match(coll); ok {
+			return parsed, tenant, true
+		}
+	}
+	return NamespaceTemplate{}, "", false
+}
@@ -0,0 +1,62 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseNamespaceTemplate(t *testing.T) {
+	for _, template := range []string{"tenant_{id}_orders", "{tenant}", "orders_{id}"} {
+		_, err := ParseNamespaceTemplate(template)
+		assert.NoError(t, err, template)
+	}
+	for _, template := range []string{"orders", "tenant_{}_orders", "{a}_{b}", "tenant_}{"} {
+		_, err := ParseNamespaceTemplate(template)
+		assert.Error(t, err, template)
+	}
+}
+
+func TestNamespaceTemplateMatch(t *testing.T) {
+	template, err := ParseNamespaceTemplate("tenant_{id}_orders")
+	require.NoError(t, err)
+
+	tenant, ok := template.Match("tenant_42_orders")
+	assert.True(t, ok)
+	assert.Equal(t, "42", tenant)
+
+	for _, coll := range []string{"tenant__orders", "tenant_42_users", "orders", "tenant_42"} {
+		_, ok := template.Match(coll)
+		assert.False(t, ok, coll)
+	}
+
+	assert.Equal(t, "tenant_7_orders", template.Collection("7"))
+	assert.Equal(t, "tenant_{id}_orders", template.String())
+	assert.Equal(t, []string{"1", "2"}, template.Tenants([]string{"tenant_2_orders", "users", "tenant_1_orders", "tenant_1_users"}))
+}
+
+func TestGetNamespaceTemplate(t *testing.T) {
+	conn := MongoConfig{NamespaceTemplates: []string{"invalid", "tenant_{id}_orders", "{id}_orders"}}
+
+	template, tenant, ok := conn.GetNamespaceTemplate("acme_orders")
+	require.True(t, ok)
+	assert.Equal(t, "acme", tenant)
+	assert.Equal(t, "globex_orders", template.Collection("globex"))
+
+	_, _, ok = conn.GetNamespaceTemplate("users")
+	assert.False(t, ok)
+}
+
+func TestConfigValidateNamespaceTemplates(t *testing.T) {
+	cfg := &Config{}
+	cfg.loadDefaults()
+	cfg.Connections = []MongoConfig{
+		{Name: "dev", NamespaceTemplates: []string{"tenant_{id}_orders", "orders"}},
+	}
+
+	errs := cfg.Validate()
+	require.Len(t, errs, 1)
+	assert.Equal(t, "orders", errs[0].Value)
+	assert.Contains(t, errs[0].Msg, "connection dev")
+}
@@ -0,0 +1,102 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/kopecmaciej/vi-mongo/internal/util"
+)
+
+const (
+	RecentFile = "recent.json"
+	// maxRecentNamespaces is the number of namespaces kept in the recent file
+	maxRecentNamespaces = 9
+)
+
+// RecentNamespace is a collection opened recently on the given connection
+type RecentNamespace struct {
+	Connection string    `json:"connection"`
+	Db         string    `json:"db"`
+	Coll       string    `json:"coll"`
+	OpenedAt   time.Time `json:"openedAt"`
+}
+
+// LoadRecentNamespaces loads recently opened namespaces, newest first
+func LoadRecentNamespaces() ([]RecentNamespace, error) {
+	recentPath, err := GetRecentPath()
+	if err != nil {
+		return nil, err
+	}
+
+	bytes, err := os.ReadFile(recentPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []RecentNamespace{}, nil
+		}
+		return nil, err
+	}
+
+	recent := []RecentNamespace{}
+	if len(strings.TrimSpace(string(bytes))) == 0 {
+		return recent, nil
+	}
+	if err := json.Unmarshal(bytes, &recent); err != nil {
+		return nil, err
+	}
+
+	return recent, nil
+}
+
+// AddRecentNamespace moves the namespace to the top of recently opened
+// namespaces, only the newest maxRecentNamespaces are kept
+func AddRecentNamespace(connection, db, coll string) error {
+	recentPath, err := GetRecentPath()
+	if err != nil {
+		return err
+	}
+
+	return util.WithFileLock(recentPath, func() error {
+		recent, err := LoadRecentNamespaces()
+		if err != nil {
+			return err
+		}
+
+		updated := []RecentNamespace{{
+			Connection: connection,
+			Db:         db,
+			Coll:       coll,
+			OpenedAt:   time.Now().UTC(),
+		}}
+		for _, rn := range recent {
+			if rn.Connection == connection && rn.Db == db && rn.Coll == coll {
+				continue
+			}
+			if len(updated) == maxRecentNamespaces {
+				break
+			}
+			updated = append(updated, rn)
+		}
+
+		bytes, err := json.MarshalIndent(updated, "", "  ")
+		if err != nil {
+			return err
+		}
+
+		return util.WriteFileAtomic(recentPath, bytes, 0644)
+	})
+}
+
+// GetRecentPath returns the path to the file with recently opened namespaces
+func GetRecentPath() (string, error) {
+	configDir, err := util.GetConfigDir()
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(configDir, 0755); err != nil {
+		return "", err
+	}
+
+	return configDir + "/" + RecentFile, nil
+}
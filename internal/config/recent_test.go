@@ -0,0 +1,49 @@
+package config
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/adrg/xdg"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAddRecentNamespace(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	xdg.Reload()
+	t.Cleanup(xdg.Reload)
+
+	recent, err := LoadRecentNamespaces()
+	require.NoError(t, err)
+	assert.Empty(t, recent)
+
+	require.NoError(t, AddRecentNamespace("local", "db", "users"))
+	require.NoError(t, AddRecentNamespace("local", "db", "orders"))
+	require.NoError(t, AddRecentNamespace("prod", "db", "users"))
+	require.NoError(t, AddRecentNamespace("local", "db", "users"))
+
+	recent, err = LoadRecentNamespaces()
+	require.NoError(t, err)
+	require.Len(t, recent, 3)
+	assert.Equal(t, "local", recent[0].Connection)
+	assert.Equal(t, "users", recent[0].Coll)
+	assert.Equal(t, "prod", recent[1].Connection)
+	assert.Equal(t, "orders", recent[2].Coll)
+}
+
+func TestAddRecentNamespaceKeepsNewest(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	xdg.Reload()
+	t.Cleanup(xdg.Reload)
+
+	for i := 0; i < maxRecentNamespaces+3; i++ {
+		require.NoError(t, AddRecentNamespace("local", "db", fmt.Sprintf("coll%d", i)))
+	}
+
+	recent, err := LoadRecentNamespaces()
+	require.NoError(t, err)
+	require.Len(t, recent, maxRecentNamespaces)
+	assert.Equal(t, fmt.Sprintf("coll%d", maxRecentNamespaces+2), recent[0].Coll)
+	assert.Equal(t, "coll3", recent[maxRecentNamespaces-1].Coll)
+}
@@ -0,0 +1,351 @@
+package config
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/kopecmaciej/vi-mongo/internal/util"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	SecretsFile    = "secrets.enc"
+	SecretsKeyFile = "secrets.key"
+	// secretsKeySize is the size of the AES-256 key of the secrets file
+	secretsKeySize = 32
+)
+
+// Where passwords of connections are saved
+const (
+	// PasswordKeyring saves passwords in the keyring of the system,
+	// the encrypted file is used when the keyring isn't available
+	PasswordKeyring = "keyring"
+	// PasswordFile saves passwords in the encrypted file
+	PasswordFile = "file"
+	// PasswordConfig leaves passwords in plain text in the config file
+	PasswordConfig = "config"
+)
+
+// SecretStore keeps passwords of connections by the connection name
+type SecretStore interface {
+	Get(account string) (string, error)
+	Set(account, password string) error
+	Delete(account string) error
+}
+
+// keyring is the keyring of the system, it's replaced in tests
+var keyring SecretStore = systemKeyring{}
+
+func secretStore(name string) (SecretStore, error) {
+	switch name {
+	case PasswordKeyring:
+		return scopedKeyring{keyring}, nil
+	case PasswordFile:
+		return fileSecrets{}, nil
+	}
+	return nil, fmt.Errorf("unknown password store %q", name)
+}
+
+// plainPassword returns the password the connection keeps in the config,
+// the one in the URI if the URI is set, as then the password field isn't used
+func plainPassword(conn *MongoConfig) string {
+	if conn.Uri != "" {
+		_, password := util.SplitPasswordFromUri(conn.Uri)
+		return password
+	}
+	return conn.Password
+}
+
+// storePassword moves the password of the connection from the config to
+// the password store and records which store keeps it. The encrypted
+// file is used if the password can't be saved in the keyring.
+func (c *Config) storePassword(conn *MongoConfig) error {
+	store := c.PasswordStorage
+	if store != PasswordKeyring && store != PasswordFile {
+		return nil
+	}
+	password := plainPassword(conn)
+	if password == "" || conn.PasswordStore != "" {
+		return nil
+	}
+
+	if store == PasswordKeyring {
+		if err := (scopedKeyring{keyring}).Set(conn.Name, password); err != nil {
+			log.Warn().Err(err).Msgf("Keyring not available, password of %s is saved in the encrypted file", conn.Name)
+			store = PasswordFile
+		}
+	}
+	if store == PasswordFile {
+		if err := (fileSecrets{}).Set(conn.Name, password); err != nil {
+			return fmt.Errorf("saving password of %s: %w", conn.Name, err)
+		}
+	}
+
+	if conn.Uri != "" {
+		conn.Uri, _ = util.SplitPasswordFromUri(conn.Uri)
+	} else {
+		conn.Password = ""
+	}
+	conn.PasswordStore = store
+	return nil
+}
+
+// withPassword returns the copy of the connection with the password
+// read from its store, so it can be used to connect
+func withPassword(conn MongoConfig) *MongoConfig {
+	if conn.PasswordStore == "" {
+		return &conn
+	}
+	store, err := secretStore(conn.PasswordStore)
+	var password string
+	if err == nil {
+		password, err = store.Get(conn.Name)
+	}
+	if err != nil {
+		log.Error().Err(err).Msgf("Error reading password of connection %s", conn.Name)
+		return &conn
+	}
+
+	if conn.Uri != "" {
+		conn.Uri = util.SetPasswordInUri(conn.Uri, password)
+	} else {
+		conn.Password = password
+	}
+	return &conn
+}
+
+// deletePassword removes the password of the deleted connection from its store
+func deletePassword(conn MongoConfig) {
+	store, err := secretStore(conn.PasswordStore)
+	if err == nil {
+		err = store.Delete(conn.Name)
+	}
+	if err != nil {
+		log.Warn().Err(err).Msgf("Error deleting password of connection %s", conn.Name)
+	}
+}
+
+// MigratePasswords moves passwords kept in plain text in the config file
+// to the password store, e.g. on the first run after it's enabled. It's
+// run when the app starts, commands only reading the config don't change it.
+func (c *Config) MigratePasswords() error {
+	if c.PasswordStorage != PasswordKeyring && c.PasswordStorage != PasswordFile {
+		return nil
+	}
+	plain := false
+	for i := range c.Connections {
+		if c.Connections[i].PasswordStore == "" && plainPassword(&c.Connections[i]) != "" {
+			plain = true
+		}
+	}
+	if !plain {
+		return nil
+	}
+
+	migrated := 0
+	err := c.updateConfigFile(func(onDisk *Config) error {
+		migrated = 0
+		for i := range onDisk.Connections {
+			conn := &onDisk.Connections[i]
+			if conn.PasswordStore != "" || plainPassword(conn) == "" {
+				continue
+			}
+			if err := c.storePassword(conn); err != nil {
+				return err
+			}
+			migrated++
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	log.Info().Msgf("Moved passwords of %d connections out of the config file", migrated)
+	return nil
+}
+
+// scopedKeyring keeps passwords in the keyring under accounts prefixed
+// with the config directory. The keyring is shared by all profiles and
+// config directories, so connections with the same name don't overwrite
+// passwords of each other, the encrypted file is kept per directory.
+type scopedKeyring struct {
+	SecretStore
+}
+
+func (k scopedKeyring) Get(account string) (string, error) {
+	scoped, err := keyringAccount(account)
+	if err != nil {
+		return "", err
+	}
+	return k.SecretStore.Get(scoped)
+}
+
+func (k scopedKeyring) Set(account, password string) error {
+	scoped, err := keyringAccount(account)
+	if err != nil {
+		return err
+	}
+	return k.SecretStore.Set(scoped, password)
+}
+
+func (k scopedKeyring) Delete(account string) error {
+	scoped, err := keyringAccount(account)
+	if err != nil {
+		return err
+	}
+	return k.SecretStore.Delete(scoped)
+}
+
+// keyringAccount returns the account of the connection in the keyring,
+// the config directory of the current profile followed by the name
+func keyringAccount(name string) (string, error) {
+	configDir, err := util.GetConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return configDir + ":" + name, nil
+}
+
+// fileSecrets keeps passwords in the file encrypted with AES-GCM. The key
+// is generated when the first password is saved and kept in the config
+// directory, both files can be read only by the user.
+type fileSecrets struct{}
+
+func (fileSecrets) Get(account string) (string, error) {
+	secrets, err := loadSecrets()
+	if err != nil {
+		return "", err
+	}
+	password, ok := secrets[account]
+	if !ok {
+		return "", fmt.Errorf("no password of connection %s in %s", account, SecretsFile)
+	}
+	return password, nil
+}
+
+func (fileSecrets) Set(account, password string) error {
+	return updateSecrets(func(secrets map[string]string) {
+		secrets[account] = password
+	})
+}
+
+func (fileSecrets) Delete(account string) error {
+	return updateSecrets(func(secrets map[string]string) {
+		delete(secrets, account)
+	})
+}
+
+// GetSecretsPaths returns paths of the encrypted secrets file and its key
+func GetSecretsPaths() (string, string, error) {
+	configDir, err := util.GetConfigDir()
+	if err != nil {
+		return "", "", err
+	}
+	if err := os.MkdirAll(configDir, 0755); err != nil {
+		return "", "", err
+	}
+	return filepath.Join(configDir, SecretsFile), filepath.Join(configDir, SecretsKeyFile), nil
+}
+
+func loadSecrets() (map[string]string, error) {
+	secretsPath, keyPath, err := GetSecretsPaths()
+	if err != nil {
+		return nil, err
+	}
+
+	secrets := map[string]string{}
+	data, err := os.ReadFile(secretsPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return secrets, nil
+		}
+		return nil, err
+	}
+	key, err := os.ReadFile(keyPath)
+	if err != nil {
+		return nil, err
+	}
+	plain, err := decryptSecrets(key, data)
+	if err != nil {
+		return nil, fmt.Errorf("decrypting %s: %w", SecretsFile, err)
+	}
+	if err := json.Unmarshal(plain, &secrets); err != nil {
+		return nil, err
+	}
+	return secrets, nil
+}
+
+func updateSecrets(update func(secrets map[string]string)) error {
+	secretsPath, keyPath, err := GetSecretsPaths()
+	if err != nil {
+		return err
+	}
+
+	return util.WithFileLock(secretsPath, func() error {
+		secrets, err := loadSecrets()
+		if err != nil {
+			return err
+		}
+		update(secrets)
+
+		key, err := os.ReadFile(keyPath)
+		if os.IsNotExist(err) {
+			key = make([]byte, secretsKeySize)
+			if _, err := rand.Read(key); err != nil {
+				return err
+			}
+			err = util.WriteFileAtomic(keyPath, key, 0600)
+		}
+		if err != nil {
+			return err
+		}
+
+		plain, err := json.Marshal(secrets)
+		if err != nil {
+			return err
+		}
+		data, err := encryptSecrets(key, plain)
+		if err != nil {
+			return err
+		}
+		return util.WriteFileAtomic(secretsPath, data, 0600)
+	})
+}
+
+// encryptSecrets returns the random nonce followed by the encrypted data
+func encryptSecrets(key, plain []byte) ([]byte, error) {
+	gcm, err := secretsCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return gcm.Seal(nonce, nonce, plain, nil), nil
+}
+
+func decryptSecrets(key, data []byte) ([]byte, error) {
+	gcm, err := secretsCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < gcm.NonceSize() {
+		return nil, fmt.Errorf("file is too short")
+	}
+	nonce, encrypted := data[:gcm.NonceSize()], data[gcm.NonceSize():]
+	return gcm.Open(nil, nonce, encrypted, nil)
+}
+
+func secretsCipher(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,91 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+
+	"github.com/kopecmaciej/vi-mongo/internal/util"
+)
+
+const (
+	SortsFile = "sorts.json"
+)
+
+// LoadSorts loads sorts saved per collection, keyed by namespace (db.collection)
+func LoadSorts() (map[string]string, error) {
+	sortsPath, err := GetSortsPath()
+	if err != nil {
+		return nil, err
+	}
+
+	bytes, err := os.ReadFile(sortsPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return map[string]string{}, nil
+		}
+		return nil, err
+	}
+
+	sorts := map[string]string{}
+	if len(strings.TrimSpace(string(bytes))) == 0 {
+		return sorts, nil
+	}
+	if err := json.Unmarshal(bytes, &sorts); err != nil {
+		return nil, err
+	}
+
+	return sorts, nil
+}
+
+// LoadSort returns sort saved for the namespace, or empty string if
+// there is none
+func LoadSort(namespace string) (string, error) {
+	sorts, err := LoadSorts()
+	if err != nil {
+		return "", err
+	}
+
+	return sorts[namespace], nil
+}
+
+// SaveSort saves sort of the namespace, empty sort removes it
+func SaveSort(namespace, sort string) error {
+	sortsPath, err := GetSortsPath()
+	if err != nil {
+		return err
+	}
+
+	return util.WithFileLock(sortsPath, func() error {
+		sorts, err := LoadSorts()
+		if err != nil {
+			return err
+		}
+
+		if sort == "" {
+			delete(sorts, namespace)
+		} else {
+			sorts[namespace] = sort
+		}
+
+		bytes, err := json.MarshalIndent(sorts, "", "  ")
+		if err != nil {
+			return err
+		}
+
+		return util.WriteFileAtomic(sortsPath, bytes, 0644)
+	})
+}
+
+// GetSortsPath returns the path to the file with sorts saved per collection
+func GetSortsPath() (string, error) {
+	configDir, err := util.GetConfigDir()
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(configDir, 0755); err != nil {
+		return "", err
+	}
+
+	return configDir + "/" + SortsFile, nil
+}
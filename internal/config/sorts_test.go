@@ -0,0 +1,31 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/adrg/xdg"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSaveSort(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	xdg.Reload()
+	t.Cleanup(xdg.Reload)
+
+	sort, err := LoadSort("db.users")
+	require.NoError(t, err)
+	assert.Empty(t, sort)
+
+	require.NoError(t, SaveSort("db.users", `{ "age": -1, "name": 1 }`))
+	require.NoError(t, SaveSort("db.orders", `{ "createdAt": -1 }`))
+
+	sort, err = LoadSort("db.users")
+	require.NoError(t, err)
+	assert.Equal(t, `{ "age": -1, "name": 1 }`, sort)
+
+	require.NoError(t, SaveSort("db.users", ""))
+	sorts, err := LoadSorts()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"db.orders": `{ "createdAt": -1 }`}, sorts)
+}
@@ -0,0 +1,108 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/kopecmaciej/vi-mongo/internal/util"
+)
+
+const (
+	StatsHistoryDir = "stats"
+)
+
+// unsafeFileNameChars are replaced in the connection name,
+// so it can be used as the name of the file
+var unsafeFileNameChars = regexp.MustCompile(`[^A-Za-z0-9._-]`)
+
+// StatsSample is a single serverStatus sample saved in the stats history
+// of the connection. Op counters are cumulative, as reported by the server.
+type StatsSample struct {
+	Time        time.Time `json:"time"`
+	Connections int64     `json:"connections"`
+	ResidentMB  int64     `json:"residentMB"`
+	Insert      int64     `json:"insert"`
+	Query       int64     `json:"query"`
+	Update      int64     `json:"update"`
+	Delete      int64     `json:"delete"`
+}
+
+// LoadStatsHistory loads samples of the connection,
+// from the oldest to the newest
+func LoadStatsHistory(connection string) ([]StatsSample, error) {
+	historyPath, err := GetStatsHistoryPath(connection)
+	if err != nil {
+		return nil, err
+	}
+
+	bytes, err := os.ReadFile(historyPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var samples []StatsSample
+	if len(strings.TrimSpace(string(bytes))) == 0 {
+		return samples, nil
+	}
+	if err := json.Unmarshal(bytes, &samples); err != nil {
+		return nil, err
+	}
+
+	return samples, nil
+}
+
+// AddStatsSample appends the sample to the history of the connection,
+// samples older than retention are removed
+func AddStatsSample(connection string, sample StatsSample, retention time.Duration) error {
+	historyPath, err := GetStatsHistoryPath(connection)
+	if err != nil {
+		return err
+	}
+
+	return util.WithFileLock(historyPath, func() error {
+		samples, err := LoadStatsHistory(connection)
+		if err != nil {
+			return err
+		}
+
+		samples = append(pruneStatsSamples(samples, sample.Time.Add(-retention)), sample)
+		bytes, err := json.Marshal(samples)
+		if err != nil {
+			return err
+		}
+
+		return util.WriteFileAtomic(historyPath, bytes, 0644)
+	})
+}
+
+// pruneStatsSamples returns samples taken after given time
+func pruneStatsSamples(samples []StatsSample, before time.Time) []StatsSample {
+	kept := samples[:0]
+	for _, s := range samples {
+		if s.Time.After(before) {
+			kept = append(kept, s)
+		}
+	}
+	return kept
+}
+
+// GetStatsHistoryPath returns the path to the stats history file of the connection
+func GetStatsHistoryPath(connection string) (string, error) {
+	configDir, err := util.GetConfigDir()
+	if err != nil {
+		return "", err
+	}
+	statsDir := filepath.Join(configDir, StatsHistoryDir)
+	if err := os.MkdirAll(statsDir, 0755); err != nil {
+		return "", err
+	}
+
+	return filepath.Join(statsDir, unsafeFileNameChars.ReplaceAllString(connection, "_")+".json"), nil
+}
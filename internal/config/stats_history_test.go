@@ -0,0 +1,48 @@
+package config
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/adrg/xdg"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatsHistory(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	xdg.Reload()
+	t.Cleanup(xdg.Reload)
+
+	samples, err := LoadStatsHistory("local")
+	require.NoError(t, err)
+	assert.Empty(t, samples)
+
+	now := time.Now()
+	require.NoError(t, AddStatsSample("local", StatsSample{Time: now.Add(-3 * time.Hour), Insert: 1}, 2*time.Hour))
+	require.NoError(t, AddStatsSample("local", StatsSample{Time: now.Add(-time.Hour), Insert: 2}, 2*time.Hour))
+	require.NoError(t, AddStatsSample("local", StatsSample{Time: now, Insert: 3}, 2*time.Hour))
+	require.NoError(t, AddStatsSample("prod", StatsSample{Time: now, Insert: 10}, 2*time.Hour))
+
+	samples, err = LoadStatsHistory("local")
+	require.NoError(t, err)
+	require.Len(t, samples, 2)
+	assert.Equal(t, int64(2), samples[0].Insert)
+	assert.Equal(t, int64(3), samples[1].Insert)
+
+	samples, err = LoadStatsHistory("prod")
+	require.NoError(t, err)
+	require.Len(t, samples, 1)
+}
+
+func TestGetStatsHistoryPath(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	xdg.Reload()
+	t.Cleanup(xdg.Reload)
+
+	path, err := GetStatsHistoryPath("prod/eu west")
+	require.NoError(t, err)
+	assert.Equal(t, "prod_eu_west.json", filepath.Base(path))
+	assert.Equal(t, StatsHistoryDir, filepath.Base(filepath.Dir(path)))
+}
@@ -4,6 +4,8 @@ import (
 	"embed"
 	"fmt"
 	"os"
+	"path/filepath"
+	"regexp"
 	"strconv"
 
 	"github.com/gdamore/tcell/v2"
@@ -14,6 +16,10 @@ import (
 //go:embed styles
 var stylesFS embed.FS
 
+// removedStyleKeys matches keys which older versions extracted into
+// style files and are no longer used
+var removedStyleKeys = regexp.MustCompile(`(?m)^[ \t]+(inverseTextColor|tertiaryTextColor|contrastSecondaryTextColor|deleteButtonTextColor|deleteButtonBackgroundColor):.*(\n|$)`)
+
 // Styles is a struct that contains all the styles for the application
 type (
 	Style string
@@ -73,6 +79,9 @@ type (
 		ValueColor     Style `yaml:"valueColor"`
 		ActiveSymbol   Style `yaml:"activeSymbol"`
 		InactiveSymbol Style `yaml:"inactiveSymbol"`
+		WarningColor   Style `yaml:"warningColor"`
+		// DisabledColor is used for keys of actions the user lacks privileges for
+		DisabledColor Style `yaml:"disabledColor"`
 	}
 
 	// DatabasesStyle is a struct that contains all the styles for the databases
@@ -97,6 +106,30 @@ type (
 		SelectedRowColor         Style `yaml:"selectedRowColor"`
 		SeparatorSymbol          Style `yaml:"separatorSymbol"`
 		SeparatorColor           Style `yaml:"separatorColor"`
+		// TypeColors are used for cells when coloring by type is enabled
+		TypeColors TypeColorsStyle `yaml:"typeColors"`
+		// DiffColors are backgrounds of rows which differ
+		// from the previous run when auto-refresh is on
+		DiffColors DiffColorsStyle `yaml:"diffColors"`
+	}
+
+	// DiffColorsStyle highlights documents added, changed
+	// or removed since the previous run of the query
+	DiffColorsStyle struct {
+		AddedColor   Style `yaml:"addedColor"`
+		ChangedColor Style `yaml:"changedColor"`
+		RemovedColor Style `yaml:"removedColor"`
+	}
+
+	// TypeColorsStyle colors table cells by the type of their value,
+	// so e.g. string "5" and number 5 look different
+	TypeColorsStyle struct {
+		StringColor   Style `yaml:"stringColor"`
+		NumberColor   Style `yaml:"numberColor"`
+		DateColor     Style `yaml:"dateColor"`
+		ObjectIdColor Style `yaml:"objectIdColor"`
+		BoolColor     Style `yaml:"boolColor"`
+		NullColor     Style `yaml:"nullColor"`
 	}
 
 	// DocPeekerStyle is a struct that contains all the styles for the json peeker
@@ -188,6 +221,8 @@ func (s *Styles) loadDefaults() {
 		ValueColor:     "#387D44",
 		ActiveSymbol:   "●",
 		InactiveSymbol: "○",
+		WarningColor:   "#F87171",
+		DisabledColor:  "#6B7280",
 	}
 
 	s.Databases = DatabasesStyle{
@@ -210,6 +245,19 @@ func (s *Styles) loadDefaults() {
 		SelectedRowColor:         "#4ADE80",
 		SeparatorSymbol:          "|",
 		SeparatorColor:           "#334155",
+		TypeColors: TypeColorsStyle{
+			StringColor:   "#86EFAC",
+			NumberColor:   "#93C5FD",
+			DateColor:     "#F9A8D4",
+			ObjectIdColor: "#FDBA74",
+			BoolColor:     "#C4B5FD",
+			NullColor:     "#64748B",
+		},
+		DiffColors: DiffColorsStyle{
+			AddedColor:   "#14532D",
+			ChangedColor: "#713F12",
+			RemovedColor: "#7F1D1D",
+		},
 	}
 
 	s.DocPeeker = DocPeekerStyle{
@@ -258,6 +306,99 @@ func (s *Styles) loadDefaults() {
 	}
 }
 
+// ForType returns the color of values of the type, as returned by
+// util.GetMongoType, false if there is no color set for the type
+func (t *TypeColorsStyle) ForType(mongoType string) (Style, bool) {
+	var color Style
+	switch mongoType {
+	case util.TypeString:
+		color = t.StringColor
+	case util.TypeInt, util.TypeDouble:
+		color = t.NumberColor
+	case util.TypeDate:
+		color = t.DateColor
+	case util.TypeObjectId:
+		color = t.ObjectIdColor
+	case util.TypeBool:
+		color = t.BoolColor
+	case util.TypeNull:
+		color = t.NullColor
+	}
+	return color, color != ""
+}
+
+// ApplyHighContrast replaces colors of the style with white and yellow text
+// on black, and symbols with plain characters, which are read by screen
+// readers and don't depend on the font. Backgrounds of diff rows are kept,
+// as changes are marked with text as well.
+func (s *Styles) ApplyHighContrast() {
+	const (
+		black  = "#000000"
+		white  = "#FFFFFF"
+		yellow = "#FFFF00"
+		cyan   = "#00FFFF"
+		gray   = "#808080"
+	)
+
+	s.Global = GlobalStyles{
+		BackgroundColor:             black,
+		ContrastBackgroundColor:     black,
+		MoreContrastBackgroundColor: yellow,
+		TextColor:                   white,
+		SecondaryTextColor:          yellow,
+		BorderColor:                 white,
+		FocusColor:                  yellow,
+		TitleColor:                  white,
+		GraphicsColor:               white,
+	}
+
+	s.Header.KeyColor = yellow
+	s.Header.ValueColor = white
+	s.Header.ActiveSymbol = "connected"
+	s.Header.InactiveSymbol = "disconnected"
+	s.Header.DisabledColor = gray
+
+	s.Databases.NodeTextColor = yellow
+	s.Databases.LeafTextColor = white
+	s.Databases.NodeSymbolColor = yellow
+	s.Databases.LeafSymbolColor = white
+	s.Databases.OpenNodeSymbol = "-"
+	s.Databases.ClosedNodeSymbol = "+"
+	s.Databases.LeafSymbol = "*"
+
+	s.Content.StatusTextColor = yellow
+	s.Content.HeaderRowBackgroundColor = black
+	s.Content.ColumnKeyColor = yellow
+	s.Content.ColumnTypeColor = cyan
+	s.Content.CellTextColor = white
+	s.Content.ActiveRowColor = yellow
+	s.Content.SelectedRowColor = yellow
+	s.Content.SeparatorColor = white
+
+	s.DocPeeker.KeyColor = yellow
+	s.DocPeeker.ValueColor = white
+	s.DocPeeker.BracketColor = cyan
+
+	s.InputBar.LabelColor = yellow
+	s.InputBar.InputColor = white
+	s.InputBar.Autocomplete = AutocompleteStyle{
+		BackgroundColor:       black,
+		TextColor:             white,
+		ActiveBackgroundColor: yellow,
+		ActiveTextColor:       black,
+		SecondaryTextColor:    cyan,
+	}
+
+	s.History.TextColor = white
+	s.History.SelectedTextColor = black
+	s.History.SelectedBackgroundColor = yellow
+
+	s.Others.ButtonsTextColor = black
+	s.Others.ButtonsBackgroundColor = white
+	s.Others.ModalTextColor = white
+	s.Others.ModalSecondaryTextColor = yellow
+}
+
 func SymbolWithColor(symbol Style, color Style) string {
 	return fmt.Sprintf("[%s]%s[-:-:-]", color.String(), symbol.String())
 }
@@ -271,7 +412,7 @@ func LoadStyles(styleName string, useBetterSymbols bool) (*Styles, error) {
 		return defaultStyles, nil
 	}
 
-	stylePath, err := getStylePath(styleName)
+	stylePath, err := GetStylePath(styleName)
 	if err != nil {
 		return nil, err
 	}
@@ -345,7 +486,8 @@ func isHexColor(s string) bool {
 	return util.IsHexColor(s)
 }
 
-func getStylePath(styleName string) (string, error) {
+// GetStylePath returns the path to the style file with given name
+func GetStylePath(styleName string) (string, error) {
 	configPath, err := util.GetConfigDir()
 	if err != nil {
 		return "", err
@@ -388,8 +530,8 @@ func ExtractStyles() error {
 			return err
 		}
 		if len(entries) > 0 {
-			// Styles already exist, return early
-			return nil
+			// Styles already exist, they're only cleaned from removed keys
+			return dropRemovedStyleKeys(stylesDir, entries)
 		}
 	} else if os.IsNotExist(err) {
 		// Create styles directory if it doesn't exist
@@ -414,7 +556,7 @@ func ExtractStyles() error {
 				return err
 			}
 
-			err = os.WriteFile(stylesDir+"/"+entry.Name(), content, 0644)
+			err = util.WriteFileAtomic(stylesDir+"/"+entry.Name(), content, 0644)
 			if err != nil {
 				return err
 			}
@@ -423,3 +565,27 @@ func ExtractStyles() error {
 
 	return nil
 }
+
+// dropRemovedStyleKeys removes keys which are no longer used from style
+// files extracted by older versions, so they don't show up as unknown
+func dropRemovedStyleKeys(stylesDir string, entries []os.DirEntry) error {
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".yaml" {
+			continue
+		}
+		path := filepath.Join(stylesDir, entry.Name())
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		cleaned := removedStyleKeys.ReplaceAll(content, nil)
+		if len(cleaned) == len(content) {
+			continue
+		}
+		if err := util.WriteFileAtomic(path, cleaned, 0644); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
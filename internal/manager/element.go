@@ -8,8 +8,14 @@ import (
 )
 
 const (
+	// FocusChanged carries tview.Identifier of the focused element
 	FocusChanged MessageType = "focus_changed"
 	StyleChanged MessageType = "style_changed"
+	KeysChanged  MessageType = "keys_changed"
+	// ConnectionChanged carries ConnectionChangedData
+	ConnectionChanged MessageType = "connection_changed"
+	// DocumentUpdated carries DocumentUpdatedData
+	DocumentUpdated MessageType = "document_updated"
 )
 
 type (
@@ -20,6 +26,22 @@ type (
 		Data interface{}
 	}
 
+	// ConnectionChangedData is the payload of ConnectionChanged message
+	ConnectionChangedData struct {
+		Name string
+	}
+
+	// DocumentUpdatedData is the payload of DocumentUpdated message
+	DocumentUpdatedData struct {
+		Db   string
+		Coll string
+		ID   interface{}
+		// Document is JSON of the updated document, empty if it was deleted
+		Document string
+		// Deleted is true if the document was removed
+		Deleted bool
+	}
+
 	// EventMsg is a wrapper for tcell.EventKey that also contains
 	// the sender of the event
 	EventMsg struct {
@@ -28,30 +50,74 @@ type (
 		Message Message
 	}
 
+	// listener is a channel of the element together with
+	// message types the element is interested in
+	listener struct {
+		ch    chan EventMsg
+		types map[MessageType]bool
+	}
+
 	// ElementManager is a helper to manage different Elements
 	// and their key handlers, so that only the key handlers of the
 	// current element are executed
 	ElementManager struct {
 		mutex     sync.Mutex
-		listeners map[tview.Identifier]chan EventMsg
+		listeners map[tview.Identifier]listener
+		closed    bool
 	}
 )
 
+// DataOf returns data of the message as T,
+// ok is false if the message carries data of another type
+func DataOf[T any](msg Message) (data T, ok bool) {
+	data, ok = msg.Data.(T)
+	return data, ok
+}
+
 // NewElementManager creates a new ElementManager
 func NewElementManager() *ElementManager {
 	return &ElementManager{
 		mutex:     sync.Mutex{},
-		listeners: make(map[tview.Identifier]chan EventMsg),
+		listeners: make(map[tview.Identifier]listener),
 	}
 }
 
-// Subscribe subscribes to events from a specific element
-func (eh *ElementManager) Subscribe(element tview.Identifier) chan EventMsg {
+// Subscribe subscribes to events from a specific element. If types are
+// given, only broadcasted messages of those types are received,
+// events sent directly to the element are always received.
+func (eh *ElementManager) Subscribe(element tview.Identifier, types ...MessageType) chan EventMsg {
 	eh.mutex.Lock()
 	defer eh.mutex.Unlock()
-	listener := make(chan EventMsg, 1)
-	eh.listeners[element] = listener
-	return listener
+	ch := make(chan EventMsg, 1)
+	if eh.closed {
+		close(ch)
+		return ch
+	}
+
+	l := listener{ch: ch}
+	if len(types) > 0 {
+		l.types = make(map[MessageType]bool, len(types))
+		for _, t := range types {
+			l.types[t] = true
+		}
+	}
+	eh.listeners[element] = l
+	return ch
+}
+
+// Close closes all listeners, so elements stop handling events,
+// events sent after that are dropped
+func (eh *ElementManager) Close() {
+	eh.mutex.Lock()
+	defer eh.mutex.Unlock()
+	if eh.closed {
+		return
+	}
+	eh.closed = true
+	for element, l := range eh.listeners {
+		close(l.ch)
+		delete(eh.listeners, element)
+	}
 }
 
 // Unsubscribe unsubscribes from events from a specific element
@@ -61,12 +127,14 @@ func (eh *ElementManager) Unsubscribe(element tview.Identifier, listener chan Ev
 	delete(eh.listeners, element)
 }
 
-// Broadcast sends an event to all listeners
+// Broadcast sends an event to all listeners interested in its type
 func (eh *ElementManager) Broadcast(event EventMsg) {
 	eh.mutex.Lock()
 	defer eh.mutex.Unlock()
-	for _, listener := range eh.listeners {
-		listener <- event
+	for _, l := range eh.listeners {
+		if l.accepts(event.Message.Type) {
+			l.ch <- event
+		}
 	}
 }
 
@@ -74,7 +142,11 @@ func (eh *ElementManager) Broadcast(event EventMsg) {
 func (eh *ElementManager) SendTo(element tview.Identifier, event EventMsg) {
 	eh.mutex.Lock()
 	defer eh.mutex.Unlock()
-	if listener, exists := eh.listeners[element]; exists {
-		listener <- event
+	if l, exists := eh.listeners[element]; exists {
+		l.ch <- event
 	}
 }
+
+func (l listener) accepts(t MessageType) bool {
+	return l.types == nil || l.types[t]
+}
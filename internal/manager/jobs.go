@@ -0,0 +1,213 @@
+package manager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// maxJobLogs is the number of log lines kept for every job
+const maxJobLogs = 200
+
+type JobStatus string
+
+const (
+	JobRunning  JobStatus = "running"
+	JobDone     JobStatus = "done"
+	JobFailed   JobStatus = "failed"
+	JobCanceled JobStatus = "canceled"
+)
+
+// Job is a long running operation, e.g. an import, run in the background.
+// It's safe to use from any goroutine.
+type Job struct {
+	ID      int
+	Name    string
+	Started time.Time
+
+	mu       sync.Mutex
+	status   JobStatus
+	progress string
+	logs     []string
+	err      error
+	finished time.Time
+	cancel   context.CancelFunc
+	onChange func()
+}
+
+// SetProgress sets the description of the progress of the job
+func (j *Job) SetProgress(progress string) {
+	j.mu.Lock()
+	j.progress = progress
+	j.mu.Unlock()
+	j.onChange()
+}
+
+// Logf adds the line to the log of the job
+func (j *Job) Logf(format string, args ...interface{}) {
+	line := time.Now().Format(time.TimeOnly) + " " + fmt.Sprintf(format, args...)
+	j.mu.Lock()
+	j.logs = append(j.logs, line)
+	if len(j.logs) > maxJobLogs {
+		j.logs = j.logs[len(j.logs)-maxJobLogs:]
+	}
+	j.mu.Unlock()
+	j.onChange()
+}
+
+// Cancel stops the job, the job finishes when its function returns
+func (j *Job) Cancel() {
+	j.cancel()
+}
+
+func (j *Job) Status() JobStatus {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.status
+}
+
+func (j *Job) Progress() string {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.progress
+}
+
+// Logs returns a copy of log lines of the job
+func (j *Job) Logs() []string {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return append([]string{}, j.logs...)
+}
+
+// Err returns the error the job failed with
+func (j *Job) Err() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.err
+}
+
+// Duration returns how long the job runs, or did run if it's finished
+func (j *Job) Duration() time.Duration {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.finished.IsZero() {
+		return time.Since(j.Started)
+	}
+	return j.finished.Sub(j.Started)
+}
+
+func (j *Job) finish(err error) {
+	j.mu.Lock()
+	j.finished = time.Now()
+	j.err = err
+	switch {
+	case err == nil:
+		j.status = JobDone
+	case errors.Is(err, context.Canceled):
+		j.status = JobCanceled
+	default:
+		j.status = JobFailed
+	}
+	j.mu.Unlock()
+}
+
+// JobManager runs jobs in the background and keeps them,
+// so running and finished jobs can be listed
+type JobManager struct {
+	mu       sync.Mutex
+	jobs     []*Job
+	nextID   int
+	onChange func()
+}
+
+func NewJobManager() *JobManager {
+	return &JobManager{onChange: func() {}}
+}
+
+// SetChangedFunc sets the function called when any job changes,
+// it's called from the goroutine of the job
+func (m *JobManager) SetChangedFunc(onChange func()) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.onChange = onChange
+}
+
+// Start runs the job in the background, it's canceled together with ctx.
+// Error returned by run is the error of the job.
+func (m *JobManager) Start(ctx context.Context, name string, run func(ctx context.Context, job *Job) error) *Job {
+	ctx, cancel := context.WithCancel(ctx)
+
+	m.mu.Lock()
+	m.nextID++
+	job := &Job{
+		ID:       m.nextID,
+		Name:     name,
+		Started:  time.Now(),
+		status:   JobRunning,
+		cancel:   cancel,
+		onChange: m.changed,
+	}
+	m.jobs = append(m.jobs, job)
+	m.mu.Unlock()
+
+	job.Logf("Started")
+	go func() {
+		defer cancel()
+		err := run(ctx, job)
+		job.finish(err)
+		switch job.Status() {
+		case JobFailed:
+			job.Logf("Failed: %v", err)
+		case JobCanceled:
+			job.Logf("Canceled")
+		default:
+			job.Logf("Done")
+		}
+	}()
+	return job
+}
+
+// Jobs returns all jobs, the newest first
+func (m *JobManager) Jobs() []*Job {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	jobs := make([]*Job, len(m.jobs))
+	for i, job := range m.jobs {
+		jobs[len(m.jobs)-1-i] = job
+	}
+	return jobs
+}
+
+// Running returns the number of jobs which are still running
+func (m *JobManager) Running() int {
+	running := 0
+	for _, job := range m.Jobs() {
+		if job.Status() == JobRunning {
+			running++
+		}
+	}
+	return running
+}
+
+// ClearFinished removes jobs which are not running anymore
+func (m *JobManager) ClearFinished() {
+	m.mu.Lock()
+	kept := m.jobs[:0]
+	for _, job := range m.jobs {
+		if job.Status() == JobRunning {
+			kept = append(kept, job)
+		}
+	}
+	m.jobs = kept
+	m.mu.Unlock()
+	m.changed()
+}
+
+func (m *JobManager) changed() {
+	m.mu.Lock()
+	onChange := m.onChange
+	m.mu.Unlock()
+	onChange()
+}
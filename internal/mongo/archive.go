@@ -0,0 +1,199 @@
+package mongo
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	// ArchiveExtension is the extension of archives, BSON compressed
+	// with gzip as in files of mongodump --gzip
+	ArchiveExtension = "bson.gz"
+	// archiveDeleteBatch is the number of archived documents deleted at once
+	archiveDeleteBatch = 1000
+)
+
+// ArchiveResult is the number of documents matching the filter
+// when the archive started, written into the archive and deleted
+type ArchiveResult struct {
+	Matched  int64
+	Archived int64
+	Deleted  int64
+}
+
+// IsArchive returns true if the file is compressed with gzip
+func IsArchive(path string) bool {
+	return strings.HasSuffix(path, ".gz")
+}
+
+// ArchiveDocuments writes documents of the collection matching the filter
+// into the new archive, which is restored as the dumped BSON file of the
+// collection. Documents are archived as they're stored, masking doesn't
+// apply. The archive is read back and, if remove is set, archived documents
+// are deleted only if the archive holds as many documents as the filter
+// matched. They're deleted by _id and only if they still match the filter,
+// so documents added or changed meanwhile are kept. The archive is removed
+// if writing it fails and kept in every other case.
+func (d *Dao) ArchiveDocuments(ctx context.Context, path, db, coll string, filter primitive.M, remove bool, onProgress func(DumpProgress)) (ArchiveResult, error) {
+	var result ArchiveResult
+	if remove {
+		if err := d.checkWritable(); err != nil {
+			return result, err
+		}
+	}
+	if filter == nil {
+		filter = primitive.M{}
+	}
+	// documents are read from the primary, they may be deleted afterward
+	collection := d.client.Database(db).Collection(coll)
+	matched, err := collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return result, err
+	}
+	result.Matched = matched
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return result, err
+	}
+	ids, err := archiveDocuments(ctx, file, collection, filter, func(count int64) {
+		if onProgress != nil {
+			onProgress(DumpProgress{Collection: coll, Documents: count, Ratio: min(float64(count)/float64(max(matched, count, 1)), 1)})
+		}
+	})
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	result.Archived = int64(len(ids))
+	if err != nil {
+		os.Remove(path)
+		return result, err
+	}
+
+	stored, err := CountArchive(path)
+	if err != nil {
+		return result, fmt.Errorf("verifying archive: %w", err)
+	}
+	if stored != result.Archived {
+		return result, fmt.Errorf("verifying archive: %d documents archived, but the archive has %d", result.Archived, stored)
+	}
+	if !remove {
+		return result, nil
+	}
+	if result.Archived != matched {
+		return result, fmt.Errorf("%d documents archived, but %d matched the filter, none were deleted", result.Archived, matched)
+	}
+
+	for start := 0; start < len(ids); start += archiveDeleteBatch {
+		batch := ids[start:min(start+archiveDeleteBatch, len(ids))]
+		deleted, err := collection.DeleteMany(ctx, ArchivedFilter(filter, batch))
+		if err != nil {
+			return result, err
+		}
+		result.Deleted += deleted.DeletedCount
+		if onProgress != nil {
+			onProgress(DumpProgress{Collection: coll + " deleted", Documents: result.Deleted, Ratio: float64(start+len(batch)) / float64(len(ids))})
+		}
+	}
+	if result.Deleted != result.Archived {
+		return result, fmt.Errorf("%d of %d archived documents deleted, others were changed or deleted meanwhile", result.Deleted, result.Archived)
+	}
+	return result, nil
+}
+
+// archiveDocuments writes documents matching the filter compressed
+// into w and returns their _id values
+func archiveDocuments(ctx context.Context, w io.Writer, collection *mongo.Collection, filter primitive.M, progress func(count int64)) ([]interface{}, error) {
+	cursor, err := collection.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	out := bufio.NewWriter(w)
+	compressed := gzip.NewWriter(out)
+	var ids []interface{}
+	for cursor.Next(ctx) {
+		id, err := cursor.Current.LookupErr("_id")
+		if err != nil {
+			return ids, fmt.Errorf("document without _id can't be archived")
+		}
+		if _, err := compressed.Write(cursor.Current); err != nil {
+			return ids, err
+		}
+		ids = append(ids, id)
+		if len(ids)%dumpProgressEvery == 0 {
+			progress(int64(len(ids)))
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return ids, err
+	}
+	if err := compressed.Close(); err != nil {
+		return ids, err
+	}
+	if err := out.Flush(); err != nil {
+		return ids, err
+	}
+	progress(int64(len(ids)))
+	return ids, nil
+}
+
+// ArchivedFilter returns the filter of archived documents with the _id
+// values, which still match the filter they were archived with
+func ArchivedFilter(filter primitive.M, ids []interface{}) primitive.M {
+	byID := primitive.M{"_id": primitive.M{"$in": ids}}
+	if len(filter) == 0 {
+		return byID
+	}
+	return primitive.M{"$and": bson.A{filter, byID}}
+}
+
+// CountArchive returns the number of documents in the archive
+func CountArchive(path string) (int64, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+	compressed, err := gzip.NewReader(file)
+	if err != nil {
+		return 0, err
+	}
+	defer compressed.Close()
+
+	reader := NewBSONReader(compressed)
+	var count int64
+	for {
+		_, err := reader.Next()
+		if err == io.EOF {
+			return count, nil
+		}
+		if err != nil {
+			return count, err
+		}
+		count++
+	}
+}
+
+// countingReader counts bytes read from r,
+// e.g. of the compressed file as it's decompressed
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
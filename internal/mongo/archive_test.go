@@ -0,0 +1,49 @@
+package mongo
+
+import (
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestArchivedFilter(t *testing.T) {
+	ids := []interface{}{int32(1), int32(2)}
+	assert.Equal(t, primitive.M{"_id": primitive.M{"$in": ids}}, ArchivedFilter(primitive.M{}, ids))
+
+	filter := primitive.M{"status": "closed"}
+	assert.Equal(t, primitive.M{"$and": bson.A{filter, primitive.M{"_id": primitive.M{"$in": ids}}}}, ArchivedFilter(filter, ids))
+}
+
+func TestCountArchive(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "orders-20240501-102030."+ArchiveExtension)
+	file, err := os.Create(path)
+	require.NoError(t, err)
+	compressed := gzip.NewWriter(file)
+	for i := 0; i < 3; i++ {
+		raw, err := bson.Marshal(primitive.M{"_id": int32(i)})
+		require.NoError(t, err)
+		_, err = compressed.Write(raw)
+		require.NoError(t, err)
+	}
+	require.NoError(t, compressed.Close())
+	require.NoError(t, file.Close())
+
+	assert.True(t, IsArchive(path))
+	count, err := CountArchive(path)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), count)
+
+	// the archive isn't compressed
+	raw, err := bson.Marshal(primitive.M{"_id": int32(1)})
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, raw, 0644))
+	_, err = CountArchive(path)
+	assert.Error(t, err)
+	assert.False(t, IsArchive("orders.bson"))
+}
@@ -0,0 +1,115 @@
+package mongo
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	ChangeInsert  = "insert"
+	ChangeUpdate  = "update"
+	ChangeReplace = "replace"
+	ChangeDelete  = "delete"
+)
+
+// ChangeEvent is a change of a document received from the change stream
+type ChangeEvent struct {
+	Time          time.Time
+	OperationType string
+	DocumentKey   primitive.M
+	// FullDocument is set for inserts and replaces
+	FullDocument  primitive.M
+	UpdatedFields primitive.M
+	RemovedFields []string
+}
+
+// Watch opens the change stream of the collection and calls onEvent with
+// every insert, update, replace and delete until the context is canceled.
+// It returns nil when the context is canceled, the error otherwise,
+// e.g. when the server is not a replica set.
+func (d *Dao) Watch(ctx context.Context, db, coll string, onEvent func(event ChangeEvent)) error {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: primitive.M{
+			"operationType": primitive.M{"$in": primitive.A{ChangeInsert, ChangeUpdate, ChangeReplace, ChangeDelete}},
+		}}},
+	}
+	stream, err := d.client.Database(db).Collection(coll).Watch(ctx, pipeline)
+	if err != nil {
+		return err
+	}
+	defer stream.Close(context.Background())
+
+	for stream.Next(ctx) {
+		var raw primitive.M
+		if err := stream.Decode(&raw); err != nil {
+			return err
+		}
+		onEvent(ParseChangeEvent(raw))
+	}
+	if ctx.Err() != nil {
+		return nil
+	}
+	return stream.Err()
+}
+
+// ParseChangeEvent reads the event from the change stream document
+func ParseChangeEvent(raw primitive.M) ChangeEvent {
+	event := ChangeEvent{}
+	event.OperationType, _ = raw["operationType"].(string)
+	event.DocumentKey, _ = raw["documentKey"].(primitive.M)
+	event.FullDocument, _ = raw["fullDocument"].(primitive.M)
+	if ts, ok := raw["clusterTime"].(primitive.Timestamp); ok {
+		event.Time = time.Unix(int64(ts.T), 0)
+	} else {
+		event.Time = time.Now()
+	}
+
+	if description, ok := raw["updateDescription"].(primitive.M); ok {
+		event.UpdatedFields, _ = description["updatedFields"].(primitive.M)
+		if removed, ok := description["removedFields"].(primitive.A); ok {
+			for _, field := range removed {
+				if name, ok := field.(string); ok {
+					event.RemovedFields = append(event.RemovedFields, name)
+				}
+			}
+		}
+	}
+
+	return event
+}
+
+// Summary returns the id of the changed document followed by
+// the document for inserts and replaces, or changed fields for updates
+func (e ChangeEvent) Summary() string {
+	summary := fmt.Sprintf("_id: %s", changeJson(e.DocumentKey["_id"]))
+	switch e.OperationType {
+	case ChangeInsert, ChangeReplace:
+		if e.FullDocument != nil {
+			summary += " " + changeJson(e.FullDocument)
+		}
+	case ChangeUpdate:
+		if len(e.UpdatedFields) > 0 {
+			summary += " set " + changeJson(e.UpdatedFields)
+		}
+		if len(e.RemovedFields) > 0 {
+			summary += " unset " + strings.Join(e.RemovedFields, ", ")
+		}
+	}
+	return summary
+}
+
+// changeJson returns the value as relaxed extended JSON in one line
+func changeJson(value interface{}) string {
+	jsoned, err := bson.MarshalExtJSON(primitive.M{"v": value}, false, false)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	// value is wrapped, as only documents can be marshaled on their own
+	return strings.TrimSuffix(strings.TrimPrefix(string(jsoned), `{"v":`), "}")
+}
@@ -0,0 +1,43 @@
+package mongo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestParseChangeEvent(t *testing.T) {
+	id := primitive.NewObjectID()
+	event := ParseChangeEvent(primitive.M{
+		"operationType": "update",
+		"clusterTime":   primitive.Timestamp{T: 1714560000, I: 1},
+		"documentKey":   primitive.M{"_id": id},
+		"updateDescription": primitive.M{
+			"updatedFields": primitive.M{"status": "paid"},
+			"removedFields": primitive.A{"draft", "note"},
+		},
+	})
+
+	assert.Equal(t, ChangeUpdate, event.OperationType)
+	assert.Equal(t, time.Unix(1714560000, 0), event.Time)
+	assert.Equal(t, primitive.M{"status": "paid"}, event.UpdatedFields)
+	assert.Equal(t, []string{"draft", "note"}, event.RemovedFields)
+	assert.Equal(t, `_id: {"$oid":"`+id.Hex()+`"} set {"status":"paid"} unset draft, note`, event.Summary())
+}
+
+func TestChangeEventSummary(t *testing.T) {
+	insert := ChangeEvent{
+		OperationType: ChangeInsert,
+		DocumentKey:   primitive.M{"_id": int32(1)},
+		FullDocument:  primitive.M{"_id": int32(1)},
+	}
+	assert.Equal(t, `_id: 1 {"_id":1}`, insert.Summary())
+
+	deleted := ChangeEvent{
+		OperationType: ChangeDelete,
+		DocumentKey:   primitive.M{"_id": "a"},
+	}
+	assert.Equal(t, `_id: "a"`, deleted.Summary())
+}
@@ -0,0 +1,53 @@
+package mongo
+
+import (
+	"context"
+	"time"
+
+	"github.com/kopecmaciej/vi-mongo/internal/config"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// CollectionStats are the number of documents and sizes
+// of the collection, sizes are in bytes
+type CollectionStats struct {
+	Count          int64
+	Size           int64
+	StorageSize    int64
+	AvgObjSize     int64
+	TotalIndexSize int64
+	Indexes        int64
+}
+
+// GetCollectionStats returns stats of the collection from collStats
+func (d *Dao) GetCollectionStats(ctx context.Context, db, collection string) (*CollectionStats, error) {
+	result := primitive.M{}
+	command := primitive.D{{Key: "collStats", Value: collection}}
+	if err := d.client.Database(db).RunCommand(ctx, command).Decode(&result); err != nil {
+		return nil, err
+	}
+	stats := ParseCollectionStats(result)
+	return &stats, nil
+}
+
+// ParseCollectionStats reads stats from the collStats output, numbers
+// are int32, int64 or double depending on the server and their size
+func ParseCollectionStats(result primitive.M) CollectionStats {
+	return CollectionStats{
+		Count:          toInt64(result["count"]),
+		Size:           toInt64(result["size"]),
+		StorageSize:    toInt64(result["storageSize"]),
+		AvgObjSize:     toInt64(result["avgObjSize"]),
+		TotalIndexSize: toInt64(result["totalIndexSize"]),
+		Indexes:        toInt64(result["nindexes"]),
+	}
+}
+
+// GrowthSample returns the sample saved in the growth history of the collection
+func (s CollectionStats) GrowthSample(now time.Time) config.GrowthSample {
+	return config.GrowthSample{
+		Time:        now,
+		Count:       s.Count,
+		StorageSize: s.StorageSize,
+	}
+}
@@ -0,0 +1,32 @@
+package mongo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestParseCollectionStats(t *testing.T) {
+	stats := ParseCollectionStats(primitive.M{
+		"count":          int32(1200),
+		"size":           int64(5_000_000_000),
+		"storageSize":    float64(2_500_000_000),
+		"avgObjSize":     int32(417),
+		"totalIndexSize": int64(90_000),
+		"nindexes":       int32(3),
+	})
+
+	assert.Equal(t, CollectionStats{
+		Count:          1200,
+		Size:           5_000_000_000,
+		StorageSize:    2_500_000_000,
+		AvgObjSize:     417,
+		TotalIndexSize: 90_000,
+		Indexes:        3,
+	}, stats)
+	assert.Equal(t, int64(2_500_000_000), stats.GrowthSample(time.Now()).StorageSize)
+
+	assert.Equal(t, CollectionStats{}, ParseCollectionStats(primitive.M{}))
+}
@@ -0,0 +1,254 @@
+package mongo
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// CompassPipeline is a pipeline in the format MongoDB Compass saves
+// and exports it. Older versions of Compass keep stages separately
+// in Pipeline, newer ones keep the whole pipeline in PipelineText.
+type CompassPipeline struct {
+	ID             string         `json:"id"`
+	Name           string         `json:"name"`
+	Namespace      string         `json:"namespace"`
+	LibraryVersion int            `json:"libraryVersion"`
+	LastModified   int64          `json:"lastModified"`
+	PipelineText   string         `json:"pipelineText"`
+	Pipeline       []CompassStage `json:"pipeline,omitempty"`
+}
+
+// CompassStage is a single stage of the pipeline saved by older Compass
+type CompassStage struct {
+	StageOperator string `json:"stageOperator"`
+	Stage         string `json:"stage"`
+	IsEnabled     bool   `json:"isEnabled"`
+}
+
+var (
+	shellObjectIdRegex = regexp.MustCompile(`ObjectId\(\s*"([0-9a-fA-F]{24})"\s*\)`)
+	shellDateRegex     = regexp.MustCompile(`(?:ISODate|new Date)\(\s*"([^"]*)"\s*\)`)
+	shellNumberRegex   = regexp.MustCompile(`(NumberLong|NumberInt|NumberDecimal)\(\s*"?([-+.\deE]+)"?\s*\)`)
+	shellIdentifier    = regexp.MustCompile(`^[a-zA-Z_$][a-zA-Z\d_$]*$`)
+)
+
+// NewCompassPipeline returns the pipeline in the format of Compass,
+// the pipeline is written in the shell syntax, as Compass writes it
+func NewCompassPipeline(name, namespace string, pipeline Pipeline) CompassPipeline {
+	return CompassPipeline{
+		ID:             primitive.NewObjectID().Hex(),
+		Name:           name,
+		Namespace:      namespace,
+		LibraryVersion: 1,
+		LastModified:   time.Now().UnixMilli(),
+		PipelineText:   pipeline.ShellString(),
+	}
+}
+
+// ParseCompassPipeline parses the pipeline exported from Compass, either
+// the saved pipeline file or the pipeline text copied from Compass.
+// Disabled stages of the older format are skipped.
+func ParseCompassPipeline(data []byte) (CompassPipeline, Pipeline, error) {
+	trimmed := bytes.TrimSpace(data)
+	if bytes.HasPrefix(trimmed, []byte("[")) {
+		pipeline, err := ParseShellPipeline(string(trimmed))
+		return CompassPipeline{PipelineText: string(trimmed)}, pipeline, err
+	}
+
+	var saved CompassPipeline
+	if err := json.Unmarshal(trimmed, &saved); err != nil {
+		return saved, nil, fmt.Errorf("error reading Compass pipeline: %w", err)
+	}
+
+	text := saved.PipelineText
+	if text == "" {
+		stages := make([]string, 0, len(saved.Pipeline))
+		for _, stage := range saved.Pipeline {
+			if stage.IsEnabled && stage.StageOperator != "" {
+				stages = append(stages, fmt.Sprintf("{%s: %s}", stage.StageOperator, stage.Stage))
+			}
+		}
+		text = "[" + strings.Join(stages, ", ") + "]"
+	}
+
+	pipeline, err := ParseShellPipeline(text)
+	return saved, pipeline, err
+}
+
+// ParseShellPipeline parses the pipeline written in the shell syntax,
+// as used by Compass and mongosh: single quoted strings, comments,
+// trailing commas and helpers like ObjectId() or ISODate() are supported
+func ParseShellPipeline(text string) (Pipeline, error) {
+	text = normalizeShellText(text)
+
+	text = shellObjectIdRegex.ReplaceAllString(text, `{"$$oid": "$1"}`)
+	var dateErr error
+	text = shellDateRegex.ReplaceAllStringFunc(text, func(match string) string {
+		value := shellDateRegex.FindStringSubmatch(match)[1]
+		date, err := parseShellDate(value)
+		if err != nil {
+			dateErr = err
+			return match
+		}
+		return fmt.Sprintf(`{"$date": {"$numberLong": "%d"}}`, date.UnixMilli())
+	})
+	if dateErr != nil {
+		return nil, dateErr
+	}
+	text = shellNumberRegex.ReplaceAllStringFunc(text, func(match string) string {
+		parts := shellNumberRegex.FindStringSubmatch(match)
+		switch parts[1] {
+		case "NumberLong":
+			return fmt.Sprintf(`{"$numberLong": "%s"}`, parts[2])
+		case "NumberInt":
+			return fmt.Sprintf(`{"$numberInt": "%s"}`, parts[2])
+		default:
+			return fmt.Sprintf(`{"$numberDecimal": "%s"}`, parts[2])
+		}
+	})
+
+	return ParsePipeline(text)
+}
+
+func parseShellDate(value string) (time.Time, error) {
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02T15:04:05", "2006-01-02"} {
+		if date, err := time.Parse(layout, value); err == nil {
+			return date, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("error parsing date %q", value)
+}
+
+// normalizeShellText turns single quoted strings into double quoted ones,
+// removes comments and trailing commas, so the text can be parsed as JSON
+func normalizeShellText(text string) string {
+	var b strings.Builder
+	for i := 0; i < len(text); i++ {
+		ch := text[i]
+		switch {
+		case ch == '"' || ch == '\'':
+			end := i + 1
+			var value strings.Builder
+			for ; end < len(text) && text[end] != ch; end++ {
+				if text[end] == '\\' && end+1 < len(text) {
+					end++
+					if text[end] != '\'' {
+						value.WriteByte('\\')
+					}
+				} else if text[end] == '"' {
+					value.WriteByte('\\')
+				}
+				value.WriteByte(text[end])
+			}
+			b.WriteString(`"` + value.String() + `"`)
+			i = end
+		case ch == '/' && i+1 < len(text) && text[i+1] == '/':
+			for i < len(text) && text[i] != '\n' {
+				i++
+			}
+		case ch == '/' && i+1 < len(text) && text[i+1] == '*':
+			end := strings.Index(text[i+2:], "*/")
+			if end < 0 {
+				i = len(text)
+			} else {
+				i += end + 3
+			}
+		case ch == ',':
+			rest := strings.TrimLeft(text[i+1:], " \t\r\n")
+			if !strings.HasPrefix(rest, "}") && !strings.HasPrefix(rest, "]") {
+				b.WriteByte(ch)
+			}
+		default:
+			b.WriteByte(ch)
+		}
+	}
+	return b.String()
+}
+
+// ShellString returns the pipeline in the shell syntax used by Compass
+func (p Pipeline) ShellString() string {
+	stages := make([]string, 0, len(p))
+	for _, stage := range p {
+		stages = append(stages, shellValue(stage))
+	}
+	return "[" + strings.Join(stages, ", ") + "]"
+}
+
+// StageShellString returns the single stage in the shell syntax
+func StageShellString(stage primitive.D) string {
+	return shellValue(stage)
+}
+
+func shellValue(value interface{}) string {
+	switch v := value.(type) {
+	case primitive.D:
+		fields := make([]string, 0, len(v))
+		for _, elem := range v {
+			fields = append(fields, shellKey(elem.Key)+": "+shellValue(elem.Value))
+		}
+		return "{" + strings.Join(fields, ", ") + "}"
+	case primitive.M:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		ordered := make(primitive.D, 0, len(v))
+		for _, key := range keys {
+			ordered = append(ordered, primitive.E{Key: key, Value: v[key]})
+		}
+		return shellValue(ordered)
+	case primitive.A:
+		values := make([]string, 0, len(v))
+		for _, elem := range v {
+			values = append(values, shellValue(elem))
+		}
+		return "[" + strings.Join(values, ", ") + "]"
+	case string:
+		return strconv.Quote(v)
+	case int32:
+		return strconv.FormatInt(int64(v), 10)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		if v == math.Trunc(v) && math.Abs(v) < 1e15 {
+			return strconv.FormatFloat(v, 'f', 1, 64)
+		}
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	case nil:
+		return "null"
+	case primitive.ObjectID:
+		return fmt.Sprintf("ObjectId('%s')", v.Hex())
+	case primitive.DateTime:
+		return fmt.Sprintf("ISODate('%s')", v.Time().UTC().Format(time.RFC3339Nano))
+	case primitive.Decimal128:
+		return fmt.Sprintf("NumberDecimal('%s')", v.String())
+	case primitive.Regex:
+		return fmt.Sprintf("/%s/%s", v.Pattern, v.Options)
+	default:
+		jsoned, err := json.Marshal(ParseBsonValue(v))
+		if err != nil {
+			return fmt.Sprintf("%v", v)
+		}
+		return string(jsoned)
+	}
+}
+
+// shellKey returns the key unquoted if it's a valid identifier
+func shellKey(key string) string {
+	if shellIdentifier.MatchString(key) {
+		return key
+	}
+	return strconv.Quote(key)
+}
@@ -0,0 +1,101 @@
+package mongo
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestParseShellPipeline(t *testing.T) {
+	pipeline, err := ParseShellPipeline(`[
+		/** filter active users */
+		{ $match: { status: 'active', owner: ObjectId('5f1b7c3e9d1e8a0001a1b2c3'), created: { $gte: ISODate('2024-01-01') } } },
+		// newest first
+		{ $sort: { age: -1, name: 1, }, },
+		{ $limit: NumberLong(10) },
+	]`)
+	require.NoError(t, err)
+	require.Len(t, pipeline, 3)
+
+	owner, _ := primitive.ObjectIDFromHex("5f1b7c3e9d1e8a0001a1b2c3")
+	match := pipeline[0][0].Value.(primitive.D)
+	assert.Equal(t, primitive.E{Key: "status", Value: "active"}, match[0])
+	assert.Equal(t, primitive.E{Key: "owner", Value: owner}, match[1])
+	created := primitive.NewDateTimeFromTime(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	assert.Equal(t, primitive.D{{Key: "$gte", Value: created}}, match[2].Value)
+	assert.Equal(t, int64(10), pipeline[2][0].Value)
+
+	pipeline, err = ParseShellPipeline(`[{ $match: { name: 'it\'s "quoted"' } }]`)
+	require.NoError(t, err)
+	assert.Equal(t, `it's "quoted"`, pipeline[0][0].Value.(primitive.D)[0].Value)
+
+	_, err = ParseShellPipeline(`[{ $match: { created: ISODate('yesterday') } }]`)
+	assert.ErrorContains(t, err, "yesterday")
+}
+
+func TestParseCompassPipeline(t *testing.T) {
+	saved, pipeline, err := ParseCompassPipeline([]byte(`{
+		"id": "65a0c1f2e4b0a1b2c3d4e5f6",
+		"name": "Active users",
+		"namespace": "shop.users",
+		"libraryVersion": 1,
+		"pipelineText": "[{ $match: { status: 'active' } }]"
+	}`))
+	require.NoError(t, err)
+	assert.Equal(t, "Active users", saved.Name)
+	assert.Equal(t, "shop.users", saved.Namespace)
+	assert.Equal(t, Pipeline{{{Key: "$match", Value: primitive.D{{Key: "status", Value: "active"}}}}}, pipeline)
+
+	// older Compass keeps stages separately, disabled ones are skipped
+	_, pipeline, err = ParseCompassPipeline([]byte(`{
+		"name": "Legacy",
+		"pipeline": [
+			{"stageOperator": "$match", "stage": "{ age: { $gt: 18 } }", "isEnabled": true},
+			{"stageOperator": "$limit", "stage": "5", "isEnabled": false},
+			{"stageOperator": "$count", "stage": "'adults'", "isEnabled": true}
+		]
+	}`))
+	require.NoError(t, err)
+	require.Len(t, pipeline, 2)
+	assert.Equal(t, "$count", pipeline[1][0].Key)
+	assert.Equal(t, "adults", pipeline[1][0].Value)
+
+	_, pipeline, err = ParseCompassPipeline([]byte(`[{ $limit: 1 }]`))
+	require.NoError(t, err)
+	assert.Len(t, pipeline, 1)
+
+	_, _, err = ParseCompassPipeline([]byte(`{ "name": `))
+	assert.Error(t, err)
+}
+
+func TestCompassPipelineRoundTrip(t *testing.T) {
+	owner := primitive.NewObjectID()
+	created := primitive.NewDateTimeFromTime(time.Date(2024, 3, 1, 12, 30, 0, 0, time.UTC))
+	pipeline := Pipeline{
+		{{Key: "$match", Value: primitive.D{
+			{Key: "owner", Value: owner},
+			{Key: "created", Value: primitive.D{{Key: "$lt", Value: created}}},
+			{Key: "address.city", Value: "Kraków"},
+			{Key: "score", Value: 2.5},
+		}}},
+		{{Key: "$sort", Value: primitive.D{{Key: "score", Value: int32(-1)}, {Key: "_id", Value: int32(1)}}}},
+		{{Key: "$project", Value: primitive.D{{Key: "tags", Value: primitive.A{"a", true, nil}}}}},
+	}
+
+	exported := NewCompassPipeline("Scores", "shop.users", pipeline)
+	assert.Len(t, exported.ID, 24)
+	assert.Contains(t, exported.PipelineText, "ObjectId('"+owner.Hex()+"')")
+	assert.Contains(t, exported.PipelineText, "ISODate('2024-03-01T12:30:00Z')")
+	assert.Contains(t, exported.PipelineText, `"address.city": "Kraków"`)
+
+	data, err := json.Marshal(exported)
+	require.NoError(t, err)
+	saved, imported, err := ParseCompassPipeline(data)
+	require.NoError(t, err)
+	assert.Equal(t, "Scores", saved.Name)
+	assert.Equal(t, pipeline, imported)
+}
@@ -0,0 +1,198 @@
+package mongo
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ColumnType is the type values of the CSV column are converted to
+type ColumnType string
+
+const (
+	TypeString   ColumnType = "string"
+	TypeInt      ColumnType = "int"
+	TypeFloat    ColumnType = "float"
+	TypeDate     ColumnType = "date"
+	TypeObjectID ColumnType = "ObjectId"
+	// TypeSkip leaves the column out of imported documents
+	TypeSkip ColumnType = "skip"
+)
+
+// ColumnTypes are all types the column can be converted to
+var ColumnTypes = []ColumnType{TypeString, TypeInt, TypeFloat, TypeDate, TypeObjectID, TypeSkip}
+
+// csvDateLayouts are layouts of dates recognized in CSV files
+var csvDateLayouts = []string{time.RFC3339Nano, "2006-01-02T15:04:05", "2006-01-02 15:04:05", "2006-01-02"}
+
+// ColumnMapping maps the CSV column to the field of imported documents,
+// Field can be a dotted path, e.g. "address.city", to create embedded documents
+type ColumnMapping struct {
+	Column string
+	Field  string
+	Type   ColumnType
+}
+
+// CSVFile is the parsed CSV file, the first line is the header
+type CSVFile struct {
+	Header []string
+	Rows   [][]string
+}
+
+// ReadCSV reads the CSV file with the header line
+func ReadCSV(r io.Reader) (*CSVFile, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("error reading CSV: %w", err)
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("CSV file is empty")
+	}
+
+	header := records[0]
+	if len(header) > 0 {
+		header[0] = strings.TrimPrefix(header[0], "\ufeff")
+	}
+	return &CSVFile{Header: header, Rows: records[1:]}, nil
+}
+
+// DefaultMappings maps every column to the field of the same name, the
+// type is the narrowest one all non-empty values of the column can be
+// converted to
+func (f *CSVFile) DefaultMappings() []ColumnMapping {
+	mappings := make([]ColumnMapping, len(f.Header))
+	for col, column := range f.Header {
+		field := strings.TrimSpace(column)
+		if field == "" {
+			field = fmt.Sprintf("column%d", col+1)
+		}
+		mappings[col] = ColumnMapping{Column: column, Field: field, Type: f.guessType(col)}
+	}
+	return mappings
+}
+
+func (f *CSVFile) guessType(col int) ColumnType {
+	candidates := []ColumnType{TypeObjectID, TypeInt, TypeFloat, TypeDate}
+	seen := false
+	for _, row := range f.Rows {
+		if col >= len(row) || row[col] == "" {
+			continue
+		}
+		seen = true
+		remaining := candidates[:0]
+		for _, columnType := range candidates {
+			if _, err := ConvertCSVValue(row[col], columnType); err == nil {
+				remaining = append(remaining, columnType)
+			}
+		}
+		candidates = remaining
+		if len(candidates) == 0 {
+			break
+		}
+	}
+	if !seen || len(candidates) == 0 {
+		return TypeString
+	}
+	return candidates[0]
+}
+
+// Documents converts rows to documents with the mappings, up to limit
+// documents are returned if it's greater than 0. Empty values are left
+// out, unless the column is a string.
+func (f *CSVFile) Documents(mappings []ColumnMapping, limit int) ([]primitive.M, error) {
+	rows := f.Rows
+	if limit > 0 && len(rows) > limit {
+		rows = rows[:limit]
+	}
+
+	documents := make([]primitive.M, 0, len(rows))
+	for i, row := range rows {
+		doc := primitive.M{}
+		for col, mapping := range mappings {
+			if mapping.Type == TypeSkip || col >= len(row) {
+				continue
+			}
+			if row[col] == "" && mapping.Type != TypeString {
+				continue
+			}
+			value, err := ConvertCSVValue(row[col], mapping.Type)
+			if err != nil {
+				// first line is the header
+				return nil, fmt.Errorf("line %d, column %s: %w", i+2, mapping.Column, err)
+			}
+			if err := setPath(doc, mapping.Field, value); err != nil {
+				return nil, fmt.Errorf("line %d, column %s: %w", i+2, mapping.Column, err)
+			}
+		}
+		documents = append(documents, doc)
+	}
+	return documents, nil
+}
+
+// ConvertCSVValue converts the value of the CSV cell to the type
+func ConvertCSVValue(value string, columnType ColumnType) (interface{}, error) {
+	trimmed := strings.TrimSpace(value)
+	switch columnType {
+	case TypeString:
+		return value, nil
+	case TypeInt:
+		parsed, err := strconv.ParseInt(trimmed, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not an integer", value)
+		}
+		if parsed >= -1<<31 && parsed < 1<<31 {
+			return int32(parsed), nil
+		}
+		return parsed, nil
+	case TypeFloat:
+		parsed, err := strconv.ParseFloat(trimmed, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a number", value)
+		}
+		return parsed, nil
+	case TypeDate:
+		for _, layout := range csvDateLayouts {
+			if date, err := time.Parse(layout, trimmed); err == nil {
+				return primitive.NewDateTimeFromTime(date), nil
+			}
+		}
+		return nil, fmt.Errorf("%q is not a date", value)
+	case TypeObjectID:
+		id, err := primitive.ObjectIDFromHex(trimmed)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not an ObjectId", value)
+		}
+		return id, nil
+	}
+	return nil, fmt.Errorf("unsupported type %q", columnType)
+}
+
+// setPath sets the value of the dotted path, creating embedded documents
+func setPath(doc primitive.M, path string, value interface{}) error {
+	keys := strings.Split(path, ".")
+	for _, key := range keys[:len(keys)-1] {
+		nested, ok := doc[key]
+		if !ok {
+			nested = primitive.M{}
+			doc[key] = nested
+		}
+		nestedDoc, ok := nested.(primitive.M)
+		if !ok {
+			return fmt.Errorf("field %s is already set", key)
+		}
+		doc = nestedDoc
+	}
+	last := keys[len(keys)-1]
+	if _, ok := doc[last]; ok {
+		return fmt.Errorf("field %s is mapped more than once", path)
+	}
+	doc[last] = value
+	return nil
+}
@@ -0,0 +1,88 @@
+package mongo
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const testCSV = "\ufeffid,name,age,score,joined,city,\n" +
+	"65f1a2b3c4d5e6f7a8b9c0d1,Alice,30,9.5,2024-05-01,Paris,x\n" +
+	"65f1a2b3c4d5e6f7a8b9c0d2,Bob,,7,2024-05-02T10:00:00Z,,y\n"
+
+func TestReadCSVDefaultMappings(t *testing.T) {
+	file, err := ReadCSV(strings.NewReader(testCSV))
+	require.NoError(t, err)
+	assert.Len(t, file.Rows, 2)
+
+	assert.Equal(t, []ColumnMapping{
+		{Column: "id", Field: "id", Type: TypeObjectID},
+		{Column: "name", Field: "name", Type: TypeString},
+		{Column: "age", Field: "age", Type: TypeInt},
+		{Column: "score", Field: "score", Type: TypeFloat},
+		{Column: "joined", Field: "joined", Type: TypeDate},
+		{Column: "city", Field: "city", Type: TypeString},
+		{Column: "", Field: "column7", Type: TypeString},
+	}, file.DefaultMappings())
+
+	_, err = ReadCSV(strings.NewReader(""))
+	assert.Error(t, err)
+}
+
+func TestCSVFileDocuments(t *testing.T) {
+	file, err := ReadCSV(strings.NewReader(testCSV))
+	require.NoError(t, err)
+
+	mappings := file.DefaultMappings()
+	mappings[0].Field = "_id"
+	mappings[5].Field = "address.city"
+	mappings[6].Type = TypeSkip
+
+	docs, err := file.Documents(mappings, 1)
+	require.NoError(t, err)
+	require.Len(t, docs, 1)
+
+	id, _ := primitive.ObjectIDFromHex("65f1a2b3c4d5e6f7a8b9c0d1")
+	assert.Equal(t, primitive.M{
+		"_id":     id,
+		"name":    "Alice",
+		"age":     int32(30),
+		"score":   9.5,
+		"joined":  primitive.NewDateTimeFromTime(time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)),
+		"address": primitive.M{"city": "Paris"},
+	}, docs[0])
+
+	docs, err = file.Documents(mappings, 0)
+	require.NoError(t, err)
+	require.Len(t, docs, 2)
+	assert.NotContains(t, docs[1], "age")
+	assert.Equal(t, primitive.M{"city": ""}, docs[1]["address"])
+
+	mappings[1].Type = TypeInt
+	_, err = file.Documents(mappings, 0)
+	assert.EqualError(t, err, `line 2, column name: "Alice" is not an integer`)
+
+	mappings[1] = ColumnMapping{Column: "name", Field: "address", Type: TypeString}
+	_, err = file.Documents(mappings, 0)
+	assert.ErrorContains(t, err, "already set")
+}
+
+func TestConvertCSVValue(t *testing.T) {
+	value, err := ConvertCSVValue("5000000000", TypeInt)
+	require.NoError(t, err)
+	assert.Equal(t, int64(5000000000), value)
+
+	value, err = ConvertCSVValue(" 42 ", TypeInt)
+	require.NoError(t, err)
+	assert.Equal(t, int32(42), value)
+
+	_, err = ConvertCSVValue("2024-13-01", TypeDate)
+	assert.Error(t, err)
+
+	_, err = ConvertCSVValue("abc", TypeObjectID)
+	assert.Error(t, err)
+}
@@ -2,7 +2,11 @@ package mongo
 
 import (
 	"context"
+	"errors"
 	"reflect"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/kopecmaciej/vi-mongo/internal/config"
 
@@ -10,12 +14,22 @@ import (
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
-	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
+// ErrReadOnly is returned by write operations on read-only connection
+var ErrReadOnly = errors.New("connection is read-only")
+
 type Dao struct {
 	client *mongo.Client
 	Config *config.MongoConfig
+	// readSecondary is toggled at runtime, see SetReadSecondary
+	readSecondary atomic.Bool
+	// privileges of the user, see LoadPrivileges
+	privileges atomic.Pointer[Privileges]
+
+	encryptionMu sync.Mutex
+	// encryption decrypts values with the key vault of the connection
+	encryption *mongo.ClientEncryption
 }
 
 func NewDao(client *mongo.Client, config *config.MongoConfig) *Dao {
@@ -36,14 +50,19 @@ func (d *Dao) GetServerStatus(ctx context.Context) (*ServerStatus, error) {
 		return nil, err
 	}
 
+	start := time.Now()
 	isMaster, err := d.runAdminCommand(ctx, "isMaster", 1)
 	if err != nil {
 		return nil, err
 	}
-	var ok bool
-	status.Repl.IsMaster, ok = isMaster["ismaster"].(bool)
-	if !ok {
-		status.Repl.IsMaster = false
+	status.Ping = time.Since(start)
+	// missing fields mean false, e.g. secondary is not set on standalone
+	status.Repl.IsMaster, _ = isMaster["ismaster"].(bool)
+	status.Repl.Secondary, _ = isMaster["secondary"].(bool)
+	status.Repl.ArbiterOnly, _ = isMaster["arbiterOnly"].(bool)
+	status.Repl.SetName, _ = isMaster["setName"].(string)
+	if readOnly, ok := isMaster["readOnly"].(bool); ok && readOnly {
+		status.Repl.ReadOnly = true
 	}
 
 	return &status, nil
@@ -94,34 +113,27 @@ type Filter struct {
 	Value string
 }
 
-func (d *Dao) ListDocuments(ctx context.Context, state *CollectionState, filter primitive.M, sort primitive.M) ([]primitive.M, int64, error) {
-	count, err := d.client.Database(state.Db).Collection(state.Coll).CountDocuments(ctx, filter)
+// ListDocuments returns documents of the page, documents are decoded
+// only when their fields are read
+func (d *Dao) ListDocuments(ctx context.Context, state *CollectionState, filter primitive.M, sort primitive.D) ([]*LazyDocument, int64, error) {
+	coll := d.readCollection(state.Db, state.Coll)
+
+	count, err := d.countDocuments(ctx, coll, state, filter)
 	if err != nil {
 		return nil, 0, err
 	}
-	coll := d.client.Database(state.Db).Collection(state.Coll)
 
-	options := options.FindOptions{
-		Limit: &state.Limit,
-		Skip:  &state.Page,
-		Sort:  sort,
-	}
+	findOptions := state.Options.FindOptions(state.Page, state.Limit).SetSort(sort)
 
-	cursor, err := coll.Find(ctx, filter, &options)
+	cursor, err := coll.Find(ctx, filter, findOptions)
 	if err != nil {
 		return nil, 0, err
 	}
 	defer cursor.Close(ctx)
 
-	var documents []primitive.M
+	var documents []*LazyDocument
 	for cursor.Next(ctx) {
-		var document primitive.M
-		err := cursor.Decode(&document)
-		if err != nil {
-			return nil, 0, err
-		}
-
-		documents = append(documents, document)
+		documents = append(documents, NewLazyDocument(cursor.Current))
 	}
 
 	if err := cursor.Err(); err != nil {
@@ -131,6 +143,55 @@ func (d *Dao) ListDocuments(ctx context.Context, state *CollectionState, filter
 	return documents, count, nil
 }
 
+// StreamDocuments calls fn with every document matching the query of the
+// state, not only the page; documents are read from the cursor in batches,
+// so they're never held in memory at once. Limit of the state, if set, limits
+// found documents. The pipeline is run instead of the filter and sort when
+// it's not empty, pipelines writing with $out or $merge are rejected
+func (d *Dao) StreamDocuments(ctx context.Context, state *CollectionState, filter primitive.M, sort primitive.D, pipeline Pipeline, fn func(doc primitive.M) error) error {
+	if err := pipeline.checkReadOnly(); err != nil {
+		return err
+	}
+	coll := d.readCollection(state.Db, state.Coll)
+
+	var cursor *mongo.Cursor
+	var err error
+	if len(pipeline) > 0 {
+		cursor, err = coll.Aggregate(ctx, pipeline)
+	} else {
+		cursor, err = coll.Find(ctx, filter, state.Options.FindOptions(0, state.Limit).SetSort(sort))
+	}
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var doc primitive.M
+		if err := cursor.Decode(&doc); err != nil {
+			return err
+		}
+		if err := fn(doc); err != nil {
+			return err
+		}
+	}
+	return cursor.Err()
+}
+
+// countDocuments returns number of documents matching the filter,
+// or the estimate from metadata if state allows it
+func (d *Dao) countDocuments(ctx context.Context, coll *mongo.Collection, state *CollectionState, filter primitive.M) (int64, error) {
+	if !state.IsCountEstimated() {
+		return coll.CountDocuments(ctx, filter, state.Options.CountOptions())
+	}
+
+	count, err := coll.EstimatedDocumentCount(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return max(count-state.Options.Skip, 0), nil
+}
+
 func (d *Dao) GetDocument(ctx context.Context, db string, collection string, id primitive.ObjectID) (primitive.M, error) {
 	var document primitive.M
 	err := d.client.Database(db).Collection(collection).FindOne(ctx, primitive.M{"_id": id}).Decode(&document)
@@ -141,6 +202,9 @@ func (d *Dao) GetDocument(ctx context.Context, db string, collection string, id
 }
 
 func (d *Dao) InsetDocument(ctx context.Context, db string, collection string, document primitive.M) (interface{}, error) {
+	if err := d.checkWritable(); err != nil {
+		return nil, err
+	}
 	res, err := d.client.Database(db).Collection(collection).InsertOne(ctx, document)
 	if err != nil {
 		return nil, err
@@ -152,6 +216,9 @@ func (d *Dao) InsetDocument(ctx context.Context, db string, collection string, d
 }
 
 func (d *Dao) UpdateDocument(ctx context.Context, db string, collection string, id interface{}, originalDoc, document primitive.M) error {
+	if err := d.checkWritable(); err != nil {
+		return err
+	}
 	setOps := bson.M{}
 	unsetOps := bson.M{}
 
@@ -179,6 +246,10 @@ func (d *Dao) UpdateDocument(ctx context.Context, db string, collection string,
 		return nil
 	}
 
+	if err := d.savePreImage(ctx, db, collection, id, VersionUpdate); err != nil {
+		return err
+	}
+
 	updated, err := d.client.Database(db).Collection(collection).UpdateOne(ctx, primitive.M{"_id": id}, update)
 	if err != nil {
 		log.Error().Msgf("Error updating document: %v", err)
@@ -195,6 +266,13 @@ func (d *Dao) UpdateDocument(ctx context.Context, db string, collection string,
 }
 
 func (d *Dao) DeleteDocument(ctx context.Context, db string, collection string, id interface{}) error {
+	if err := d.checkWritable(); err != nil {
+		return err
+	}
+	if err := d.savePreImage(ctx, db, collection, id, VersionDelete); err != nil {
+		return err
+	}
+
 	deleted, err := d.client.Database(db).Collection(collection).DeleteOne(ctx, primitive.M{"_id": id})
 	if err != nil {
 		return err
@@ -209,7 +287,35 @@ func (d *Dao) DeleteDocument(ctx context.Context, db string, collection string,
 	return nil
 }
 
+// SoftDeleteDocument marks the document as deleted
+// by setting the field to the current time
+func (d *Dao) SoftDeleteDocument(ctx context.Context, db string, collection string, id interface{}, field string) error {
+	if err := d.checkWritable(); err != nil {
+		return err
+	}
+	if err := d.savePreImage(ctx, db, collection, id, VersionDelete); err != nil {
+		return err
+	}
+
+	update := primitive.M{"$set": primitive.M{field: primitive.NewDateTimeFromTime(time.Now())}}
+	updated, err := d.client.Database(db).Collection(collection).UpdateOne(ctx, primitive.M{"_id": id}, update)
+	if err != nil {
+		return err
+	}
+
+	if updated.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	log.Debug().Msgf("Document soft deleted, id: %v, db: %v, collection: %v", id, db, collection)
+
+	return nil
+}
+
 func (d *Dao) AddCollection(ctx context.Context, db string, collection string) error {
+	if err := d.checkWritable(); err != nil {
+		return err
+	}
 	err := d.client.Database(db).CreateCollection(ctx, collection)
 	if err != nil {
 		return err
@@ -221,6 +327,9 @@ func (d *Dao) AddCollection(ctx context.Context, db string, collection string) e
 }
 
 func (d *Dao) DeleteCollection(ctx context.Context, db string, collection string) error {
+	if err := d.checkWritable(); err != nil {
+		return err
+	}
 	err := d.client.Database(db).Collection(collection).Drop(ctx)
 	if err != nil {
 		return err
@@ -241,6 +350,14 @@ func (d *Dao) ForceClose(ctx context.Context) error {
 	return nil
 }
 
+// checkWritable returns ErrReadOnly if the connection is read-only
+func (d *Dao) checkWritable() error {
+	if d.Config != nil && d.Config.ReadOnly {
+		return ErrReadOnly
+	}
+	return nil
+}
+
 func (d *Dao) runAdminCommand(ctx context.Context, key string, value interface{}) (primitive.M, error) {
 	results := primitive.M{}
 	command := primitive.D{{Key: key, Value: value}}
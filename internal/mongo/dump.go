@@ -0,0 +1,461 @@
+package mongo
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	// dumpProgressEvery is the number of documents dumped
+	// between reports of the progress
+	dumpProgressEvery = 1000
+	// maxDumpDocument is the size of the largest document in the dump,
+	// the maximum size of BSON document with space for internal fields
+	maxDumpDocument = 16*1024*1024 + 16*1024
+	// errNamespaceExists is the code of the error returned when
+	// the collection being created already exists
+	errNamespaceExists = 48
+)
+
+// DumpProgress is the number of documents of the collection dumped
+// or restored so far, Ratio is the part of the collection already done
+type DumpProgress struct {
+	Collection string
+	Documents  int64
+	Ratio      float64
+}
+
+func (p DumpProgress) String() string {
+	return fmt.Sprintf("%s: %d documents (%d%%)", p.Collection, p.Documents, int(p.Ratio*100))
+}
+
+// dumpMetadata is the metadata file of the collection, the same as
+// of mongodump, so dumps can be restored by mongorestore and vice versa
+type dumpMetadata struct {
+	Indexes        []bson.Raw `bson:"indexes"`
+	UUID           string     `bson:"uuid,omitempty"`
+	CollectionName string     `bson:"collectionName"`
+	Type           string     `bson:"type,omitempty"`
+	Options        bson.Raw   `bson:"options,omitempty"`
+}
+
+// DumpPaths returns paths of the BSON file and the metadata
+// file of the collection dumped into the directory
+func DumpPaths(dir, db, coll string) (string, string) {
+	base := filepath.Join(dir, db, coll)
+	return base + ".bson", base + ".metadata.json"
+}
+
+// DumpDatabase dumps every collection of the database into dir/<db>,
+// views and system collections are skipped. It returns the number
+// of dumped collections.
+func (d *Dao) DumpDatabase(ctx context.Context, dir, db string, onProgress func(DumpProgress)) (int, error) {
+	specs, err := d.client.Database(db).ListCollectionSpecifications(ctx, primitive.M{"type": "collection"})
+	if err != nil {
+		return 0, err
+	}
+	dumped := 0
+	for _, spec := range specs {
+		if strings.HasPrefix(spec.Name, "system.") {
+			continue
+		}
+		if _, err := d.DumpCollection(ctx, dir, db, spec.Name, onProgress); err != nil {
+			return dumped, fmt.Errorf("%s: %w", spec.Name, err)
+		}
+		dumped++
+	}
+	return dumped, nil
+}
+
+// DumpCollection writes all documents of the collection into
+// dir/<db>/<coll>.bson and its indexes and options into the metadata
+// file, in the layout of mongodump. Existing files are overwritten,
+// the BSON file is removed if the dump fails. It returns the number
+// of dumped documents.
+func (d *Dao) DumpCollection(ctx context.Context, dir, db, coll string, onProgress func(DumpProgress)) (int64, error) {
+	bsonPath, metadataPath := DumpPaths(dir, db, coll)
+	if err := os.MkdirAll(filepath.Dir(bsonPath), 0755); err != nil {
+		return 0, err
+	}
+	metadata, err := d.collectionMetadata(ctx, db, coll)
+	if err != nil {
+		return 0, err
+	}
+	jsoned, err := bson.MarshalExtJSON(metadata, true, false)
+	if err != nil {
+		return 0, err
+	}
+	if err := os.WriteFile(metadataPath, jsoned, 0644); err != nil {
+		return 0, err
+	}
+
+	file, err := os.Create(bsonPath)
+	if err != nil {
+		return 0, err
+	}
+	count, err := d.dumpDocuments(ctx, file, db, coll, onProgress)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(bsonPath)
+		return count, err
+	}
+	return count, nil
+}
+
+func (d *Dao) dumpDocuments(ctx context.Context, w io.Writer, db, coll string, onProgress func(DumpProgress)) (int64, error) {
+	collection := d.client.Database(db).Collection(coll)
+	// the estimate is used for the progress only
+	total, _ := collection.EstimatedDocumentCount(ctx)
+	progress := func(count int64) {
+		if onProgress != nil {
+			onProgress(DumpProgress{Collection: coll, Documents: count, Ratio: min(float64(count)/float64(max(total, count, 1)), 1)})
+		}
+	}
+
+	cursor, err := collection.Find(ctx, primitive.M{})
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close(ctx)
+
+	out := bufio.NewWriter(w)
+	var count int64
+	for cursor.Next(ctx) {
+		if _, err := out.Write(cursor.Current); err != nil {
+			return count, err
+		}
+		count++
+		if count%dumpProgressEvery == 0 {
+			progress(count)
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return count, err
+	}
+	if err := out.Flush(); err != nil {
+		return count, err
+	}
+	progress(count)
+	return count, nil
+}
+
+// collectionMetadata returns indexes and options of the collection
+func (d *Dao) collectionMetadata(ctx context.Context, db, coll string) (*dumpMetadata, error) {
+	metadata := &dumpMetadata{CollectionName: coll, Type: "collection", Indexes: []bson.Raw{}}
+	specs, err := d.client.Database(db).ListCollectionSpecifications(ctx, primitive.M{"name": coll})
+	if err != nil {
+		return nil, err
+	}
+	if len(specs) > 0 {
+		if specs[0].Type == "view" {
+			return nil, fmt.Errorf("%s is a view, only collections can be dumped", coll)
+		}
+		metadata.Type = specs[0].Type
+		metadata.Options = specs[0].Options
+		if specs[0].UUID != nil {
+			metadata.UUID = hex.EncodeToString(specs[0].UUID.Data)
+		}
+	}
+
+	cursor, err := d.client.Database(db).Collection(coll).Indexes().List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+	for cursor.Next(ctx) {
+		metadata.Indexes = append(metadata.Indexes, append(bson.Raw{}, cursor.Current...))
+	}
+	return metadata, cursor.Err()
+}
+
+// RestoreOptions control how dumped documents are written, in batches
+// of BatchSize documents and at most WritesPerSecond documents per second
+type RestoreOptions struct {
+	BatchSize       int
+	WritesPerSecond int
+}
+
+// RestoreDatabase restores every collection dumped into the directory,
+// e.g. dump/shop, into the database. Collections are named after their
+// files and system collections are skipped.
+func (d *Dao) RestoreDatabase(ctx context.Context, dir, db string, opts RestoreOptions, onProgress func(DumpProgress)) (ImportResult, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.bson"))
+	if err != nil {
+		return ImportResult{}, err
+	}
+	if len(paths) == 0 {
+		return ImportResult{}, fmt.Errorf("no .bson files in %s", dir)
+	}
+
+	var result ImportResult
+	for _, path := range paths {
+		coll := strings.TrimSuffix(filepath.Base(path), ".bson")
+		if strings.HasPrefix(coll, "system.") {
+			continue
+		}
+		restored, err := d.RestoreCollection(ctx, path, db, coll, opts, onProgress)
+		result.Inserted += restored.Inserted
+		for _, rejected := range restored.Rejected {
+			rejected.Reason = coll + ": " + rejected.Reason
+			result.Rejected = append(result.Rejected, rejected)
+		}
+		if err != nil {
+			return result, fmt.Errorf("%s: %w", coll, err)
+		}
+	}
+	return result, nil
+}
+
+// RestoreCollection inserts documents of the dumped BSON file, or of
+// the archive compressed with gzip, into the collection. Options of the dumped collection are used if the collection
+// doesn't exist yet and indexes are created after documents are inserted.
+// Documents rejected by the server, e.g. with the _id which already exists,
+// don't stop the restore, same as in mongorestore.
+func (d *Dao) RestoreCollection(ctx context.Context, path, db, coll string, opts RestoreOptions, onProgress func(DumpProgress)) (ImportResult, error) {
+	if err := d.checkWritable(); err != nil {
+		return ImportResult{}, err
+	}
+	metadata, err := readDumpMetadata(strings.TrimSuffix(strings.TrimSuffix(path, ".gz"), ".bson") + ".metadata.json")
+	if err != nil {
+		return ImportResult{}, err
+	}
+	if err := d.createRestoredCollection(ctx, db, coll, metadata); err != nil {
+		return ImportResult{}, err
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return ImportResult{}, err
+	}
+	defer file.Close()
+	stat, err := file.Stat()
+	if err != nil {
+		return ImportResult{}, err
+	}
+
+	// the progress is of the file as it's read, compressed or not
+	counter := &countingReader{r: file}
+	var in io.Reader = counter
+	if IsArchive(path) {
+		compressed, err := gzip.NewReader(counter)
+		if err != nil {
+			return ImportResult{}, err
+		}
+		defer compressed.Close()
+		in = compressed
+	}
+	ratio := func() float64 {
+		return min(float64(counter.n)/float64(max(stat.Size(), 1)), 1)
+	}
+
+	result, err := d.restoreDocuments(ctx, NewBSONReader(in), ratio, db, coll, opts, onProgress)
+	if err != nil {
+		return result, err
+	}
+	if metadata != nil {
+		if err := d.createRestoredIndexes(ctx, db, coll, metadata.Indexes); err != nil {
+			return result, fmt.Errorf("creating indexes: %w", err)
+		}
+	}
+	return result, nil
+}
+
+func (d *Dao) restoreDocuments(ctx context.Context, reader *BSONReader, ratio func() float64, db, coll string, opts RestoreOptions, onProgress func(DumpProgress)) (ImportResult, error) {
+	batchSize := opts.BatchSize
+	if batchSize <= 0 {
+		batchSize = defaultImportBatchSize
+	}
+	if opts.WritesPerSecond > 0 {
+		batchSize = min(batchSize, opts.WritesPerSecond)
+	}
+	collection := d.client.Database(db).Collection(coll)
+
+	var result ImportResult
+	start := time.Now()
+	done := 0
+	batch := make([]interface{}, 0, batchSize)
+	insert := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		_, err := collection.InsertMany(ctx, batch, options.InsertMany().SetOrdered(false))
+		inserted := len(batch)
+		var bulkErr mongo.BulkWriteException
+		if errors.As(err, &bulkErr) && bulkErr.WriteConcernError == nil && len(bulkErr.WriteErrors) > 0 {
+			inserted -= len(bulkErr.WriteErrors)
+			for _, rejected := range rejectedDocuments(bulkErr.WriteErrors) {
+				rejected.Position += done
+				result.Rejected = append(result.Rejected, rejected)
+			}
+			err = nil
+		}
+		if err != nil {
+			return err
+		}
+		result.Inserted += inserted
+		done += len(batch)
+		batch = batch[:0]
+		if onProgress != nil {
+			onProgress(DumpProgress{Collection: coll, Documents: int64(done), Ratio: ratio()})
+		}
+		if delay := throttleDelay(done, opts.WritesPerSecond, time.Since(start)); delay > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(delay):
+			}
+		}
+		return nil
+	}
+
+	for {
+		doc, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return result, err
+		}
+		batch = append(batch, doc)
+		if len(batch) == batchSize {
+			if err := insert(); err != nil {
+				return result, err
+			}
+		}
+	}
+	return result, insert()
+}
+
+// readDumpMetadata reads the metadata file of the dumped collection,
+// it returns nil if the dump has no metadata
+func readDumpMetadata(path string) (*dumpMetadata, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var metadata dumpMetadata
+	if err := bson.UnmarshalExtJSON(data, true, &metadata); err != nil {
+		return nil, fmt.Errorf("reading %s: %w", filepath.Base(path), err)
+	}
+	return &metadata, nil
+}
+
+// createRestoredCollection creates the collection with dumped options,
+// e.g. of capped or time-series collections, unless it already exists
+func (d *Dao) createRestoredCollection(ctx context.Context, db, coll string, metadata *dumpMetadata) error {
+	if metadata == nil || len(metadata.Options) == 0 {
+		return nil
+	}
+	elements, err := metadata.Options.Elements()
+	if err != nil {
+		return err
+	}
+	if len(elements) == 0 {
+		return nil
+	}
+	command := bson.D{{Key: "create", Value: coll}}
+	for _, element := range elements {
+		command = append(command, bson.E{Key: element.Key(), Value: element.Value()})
+	}
+	err = d.client.Database(db).RunCommand(ctx, command).Err()
+	var cmdErr mongo.CommandError
+	if errors.As(err, &cmdErr) && cmdErr.Code == errNamespaceExists {
+		return nil
+	}
+	return err
+}
+
+// createRestoredIndexes creates dumped indexes, the _id index
+// is created with the collection
+func (d *Dao) createRestoredIndexes(ctx context.Context, db, coll string, indexes []bson.Raw) error {
+	specs := make(bson.A, 0, len(indexes))
+	for _, index := range indexes {
+		if name, _ := index.Lookup("name").StringValueOK(); name == "_id_" {
+			continue
+		}
+		specs = append(specs, RestoredIndexSpec(index))
+	}
+	if len(specs) == 0 {
+		return nil
+	}
+	command := bson.D{{Key: "createIndexes", Value: coll}, {Key: "indexes", Value: specs}}
+	return d.client.Database(db).RunCommand(ctx, command).Err()
+}
+
+// RestoredIndexSpec returns the dumped index without fields
+// which the server sets itself and rejects when they're given
+func RestoredIndexSpec(index bson.Raw) bson.D {
+	elements, _ := index.Elements()
+	spec := make(bson.D, 0, len(elements))
+	for _, element := range elements {
+		switch element.Key() {
+		case "v", "ns":
+			continue
+		}
+		spec = append(spec, bson.E{Key: element.Key(), Value: element.Value()})
+	}
+	return spec
+}
+
+// BSONReader reads documents of the BSON file one by one, documents
+// are written one after another, as in files of mongodump
+type BSONReader struct {
+	r      *bufio.Reader
+	offset int64
+}
+
+func NewBSONReader(r io.Reader) *BSONReader {
+	return &BSONReader{r: bufio.NewReader(r)}
+}
+
+// Next returns the next document, or io.EOF if there are no more
+func (r *BSONReader) Next() (bson.Raw, error) {
+	var header [4]byte
+	n, err := io.ReadFull(r.r, header[:])
+	if err == io.EOF {
+		return nil, io.EOF
+	}
+	if err != nil {
+		return nil, fmt.Errorf("document at byte %d: %w", r.offset, err)
+	}
+	size := int(int32(binary.LittleEndian.Uint32(header[:])))
+	if size < 5 || size > maxDumpDocument {
+		return nil, fmt.Errorf("document at byte %d: invalid size %d", r.offset, size)
+	}
+
+	doc := make(bson.Raw, size)
+	copy(doc, header[:])
+	if _, err := io.ReadFull(r.r, doc[n:]); err != nil {
+		return nil, fmt.Errorf("document at byte %d: %w", r.offset, err)
+	}
+	if err := doc.Validate(); err != nil {
+		return nil, fmt.Errorf("document at byte %d: %w", r.offset, err)
+	}
+	r.offset += int64(size)
+	return doc, nil
+}
+
+// Offset returns the number of bytes of documents read so far
+func (r *BSONReader) Offset() int64 {
+	return r.offset
+}
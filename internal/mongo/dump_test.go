@@ -0,0 +1,75 @@
+package mongo
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestBSONReader(t *testing.T) {
+	var dump bytes.Buffer
+	for _, doc := range []primitive.M{{"_id": int32(1), "name": "first"}, {"_id": int32(2)}} {
+		raw, err := bson.Marshal(doc)
+		require.NoError(t, err)
+		dump.Write(raw)
+	}
+	size := int64(dump.Len())
+
+	reader := NewBSONReader(&dump)
+	first, err := reader.Next()
+	require.NoError(t, err)
+	assert.Equal(t, "first", first.Lookup("name").StringValue())
+	second, err := reader.Next()
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), second.Lookup("_id").Int32())
+	_, err = reader.Next()
+	assert.Equal(t, io.EOF, err)
+	assert.Equal(t, size, reader.Offset())
+
+	// truncated document
+	raw, err := bson.Marshal(primitive.M{"_id": int32(1)})
+	require.NoError(t, err)
+	_, err = NewBSONReader(bytes.NewReader(raw[:len(raw)-2])).Next()
+	assert.ErrorContains(t, err, "byte 0")
+
+	_, err = NewBSONReader(bytes.NewReader([]byte{1, 0, 0, 0})).Next()
+	assert.ErrorContains(t, err, "invalid size")
+}
+
+func TestReadDumpMetadata(t *testing.T) {
+	dir := t.TempDir()
+	_, metadataPath := DumpPaths(dir, "shop", "orders")
+	require.NoError(t, os.MkdirAll(filepath.Dir(metadataPath), 0755))
+
+	metadata, err := readDumpMetadata(metadataPath)
+	require.NoError(t, err)
+	assert.Nil(t, metadata)
+
+	// as written by mongodump
+	content := `{"indexes":[{"v":{"$numberInt":"2"},"key":{"_id":{"$numberInt":"1"}},"name":"_id_"},` +
+		`{"v":{"$numberInt":"2"},"key":{"email":{"$numberInt":"1"}},"name":"email_1","unique":true}],` +
+		`"uuid":"0123456789abcdef0123456789abcdef","collectionName":"orders","type":"collection",` +
+		`"options":{"capped":true,"size":{"$numberInt":"4096"}}}`
+	require.NoError(t, os.WriteFile(metadataPath, []byte(content), 0644))
+
+	metadata, err = readDumpMetadata(metadataPath)
+	require.NoError(t, err)
+	assert.Equal(t, "orders", metadata.CollectionName)
+	assert.Len(t, metadata.Indexes, 2)
+	assert.True(t, metadata.Options.Lookup("capped").Boolean())
+
+	spec := RestoredIndexSpec(metadata.Indexes[1])
+	assert.Equal(t, []string{"key", "name", "unique"}, []string{spec[0].Key, spec[1].Key, spec[2].Key})
+	assert.Len(t, spec, 3)
+}
+
+func TestDumpProgress(t *testing.T) {
+	assert.Equal(t, "orders: 250 documents (25%)", DumpProgress{Collection: "orders", Documents: 250, Ratio: 0.25}.String())
+}
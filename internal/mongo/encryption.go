@@ -0,0 +1,179 @@
+package mongo
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	// EncryptedSubtype is the binary subtype of values encrypted
+	// with client side field level encryption or queryable encryption
+	EncryptedSubtype = 6
+	// EncryptedPlaceholder is shown instead of encrypted values
+	EncryptedPlaceholder = "<encrypted>"
+)
+
+// IsEncrypted returns true if the value is encrypted on the client side
+func IsEncrypted(value interface{}) bool {
+	binary, ok := value.(primitive.Binary)
+	return ok && binary.Subtype == EncryptedSubtype
+}
+
+// CheckEncryptedFields returns an error if any encrypted value of
+// the original document was changed or removed in the edited one,
+// as the app can't encrypt it again, so it would be corrupted
+func CheckEncryptedFields(original, edited primitive.M) error {
+	originalValues := encryptedValues("", map[string]interface{}(original))
+	editedValues := encryptedValues("", map[string]interface{}(edited))
+
+	var changed []string
+	for path, value := range originalValues {
+		if editedValue, ok := editedValues[path]; !ok || !bytes.Equal(value.Data, editedValue.Data) {
+			changed = append(changed, path)
+		}
+	}
+	if len(changed) == 0 {
+		return nil
+	}
+	sort.Strings(changed)
+	return fmt.Errorf("encrypted fields can't be edited: %s", strings.Join(changed, ", "))
+}
+
+// encryptedValues returns encrypted values of the value keyed by their path,
+// documents can be both decoded from BSON and parsed from JSON
+func encryptedValues(path string, value interface{}) map[string]primitive.Binary {
+	values := map[string]primitive.Binary{}
+	add := func(key string, nested interface{}) {
+		nestedPath := key
+		if path != "" {
+			nestedPath = path + "." + key
+		}
+		for p, v := range encryptedValues(nestedPath, nested) {
+			values[p] = v
+		}
+	}
+
+	switch v := value.(type) {
+	case primitive.Binary:
+		if v.Subtype == EncryptedSubtype {
+			values[path] = v
+		}
+	case primitive.M:
+		for key, nested := range v {
+			add(key, nested)
+		}
+	case map[string]interface{}:
+		for key, nested := range v {
+			add(key, nested)
+		}
+	case primitive.A:
+		for i, nested := range v {
+			add(fmt.Sprint(i), nested)
+		}
+	case []interface{}:
+		for i, nested := range v {
+			add(fmt.Sprint(i), nested)
+		}
+	}
+	return values
+}
+
+// DecryptDocument returns a copy of the document with encrypted values
+// decrypted using the key vault of the connection. It's meant only for
+// display, decrypted document must not be saved back.
+func (d *Dao) DecryptDocument(ctx context.Context, doc primitive.M) (primitive.M, error) {
+	clientEncryption, err := d.clientEncryption()
+	if err != nil {
+		return nil, err
+	}
+
+	decrypted := make(primitive.M, len(doc))
+	for key, value := range doc {
+		decryptedValue, err := d.decryptValue(ctx, clientEncryption, value)
+		if err != nil {
+			return nil, fmt.Errorf("error decrypting %s: %w", key, err)
+		}
+		decrypted[key] = decryptedValue
+	}
+	return decrypted, nil
+}
+
+func (d *Dao) decryptValue(ctx context.Context, clientEncryption *mongo.ClientEncryption, value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case primitive.Binary:
+		if v.Subtype != EncryptedSubtype {
+			return v, nil
+		}
+		raw, err := clientEncryption.Decrypt(ctx, v)
+		if err != nil {
+			return nil, err
+		}
+		var decrypted interface{}
+		if err := raw.Unmarshal(&decrypted); err != nil {
+			return nil, err
+		}
+		return decrypted, nil
+	case primitive.M:
+		return d.DecryptDocument(ctx, v)
+	case map[string]interface{}:
+		return d.DecryptDocument(ctx, primitive.M(v))
+	case primitive.A:
+		return d.decryptArray(ctx, clientEncryption, v)
+	case []interface{}:
+		return d.decryptArray(ctx, clientEncryption, primitive.A(v))
+	}
+	return value, nil
+}
+
+func (d *Dao) decryptArray(ctx context.Context, clientEncryption *mongo.ClientEncryption, values primitive.A) (primitive.A, error) {
+	decrypted := make(primitive.A, len(values))
+	for i, value := range values {
+		decryptedValue, err := d.decryptValue(ctx, clientEncryption, value)
+		if err != nil {
+			return nil, err
+		}
+		decrypted[i] = decryptedValue
+	}
+	return decrypted, nil
+}
+
+// clientEncryption returns explicit encryption client using the key vault
+// of the connection, it's created once. It requires the app to be built
+// with the cse tag, as decryption is done by libmongocrypt.
+func (d *Dao) clientEncryption() (*mongo.ClientEncryption, error) {
+	d.encryptionMu.Lock()
+	defer d.encryptionMu.Unlock()
+	if d.encryption != nil {
+		return d.encryption, nil
+	}
+
+	keyVault := d.Config.KeyVault
+	if keyVault == nil {
+		return nil, fmt.Errorf("key vault is not configured for the connection")
+	}
+	key, err := os.ReadFile(keyVault.LocalKeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("error reading local master key: %w", err)
+	}
+
+	opts := options.ClientEncryption().
+		SetKeyVaultNamespace(keyVault.Namespace).
+		SetKmsProviders(map[string]map[string]interface{}{
+			"local": {"key": key},
+		})
+	// client is not closed with the encryption, as it's the client of the connection
+	clientEncryption, err := mongo.NewClientEncryption(d.client, opts)
+	if err != nil {
+		return nil, err
+	}
+	d.encryption = clientEncryption
+	return clientEncryption, nil
+}
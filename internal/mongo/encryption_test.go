@@ -0,0 +1,49 @@
+package mongo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestCheckEncryptedFields(t *testing.T) {
+	ssn := primitive.Binary{Subtype: EncryptedSubtype, Data: []byte{1, 2, 3}}
+	original := primitive.M{
+		"name":    "John",
+		"ssn":     ssn,
+		"medical": primitive.M{"records": primitive.A{ssn}},
+	}
+
+	// documents parsed from JSON have nested maps and slices
+	unchanged := primitive.M{
+		"name":    "Jane",
+		"ssn":     ssn,
+		"medical": map[string]interface{}{"records": []interface{}{ssn}},
+	}
+	assert.NoError(t, CheckEncryptedFields(original, unchanged))
+
+	changed := primitive.M{
+		"name":    "John",
+		"ssn":     primitive.Binary{Subtype: EncryptedSubtype, Data: []byte{1, 2}},
+		"medical": map[string]interface{}{},
+	}
+	err := CheckEncryptedFields(original, changed)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "medical.records.0, ssn")
+}
+
+func TestEncryptedFieldSurvivesJson(t *testing.T) {
+	ssn := primitive.Binary{Subtype: EncryptedSubtype, Data: []byte{1, 2, 3}}
+	doc := primitive.M{"ssn": ssn, "nested": primitive.M{"ssn": ssn}}
+
+	jsoned, err := ParseBsonDocument(doc)
+	require.NoError(t, err)
+	assert.Contains(t, jsoned, `"subType":"06"`)
+
+	parsed, err := ParseJsonToBson(jsoned)
+	require.NoError(t, err)
+	assert.Equal(t, ssn, parsed["ssn"])
+	assert.NoError(t, CheckEncryptedFields(doc, parsed))
+}
@@ -0,0 +1,33 @@
+package mongo
+
+import (
+	"errors"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/x/mongo/driver/auth"
+	"go.mongodb.org/mongo-driver/x/mongo/driver/topology"
+)
+
+// codes of server errors caused by credentials or missing privileges
+const (
+	codeUnauthorized         = 13
+	codeAuthenticationFailed = 18
+)
+
+// IsAuthError returns true if the error is caused by failed
+// authentication or by missing privileges of the user
+func IsAuthError(err error) bool {
+	var authErr *auth.Error
+	if errors.As(err, &authErr) {
+		return true
+	}
+	var cmdErr mongo.CommandError
+	return errors.As(err, &cmdErr) && (cmdErr.Code == codeUnauthorized || cmdErr.Code == codeAuthenticationFailed)
+}
+
+// IsConnectionError returns true if the server can't be reached
+func IsConnectionError(err error) bool {
+	var selectionErr topology.ServerSelectionError
+	var connErr topology.ConnectionError
+	return errors.As(err, &selectionErr) || errors.As(err, &connErr) || mongo.IsNetworkError(err)
+}
@@ -0,0 +1,26 @@
+package mongo
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/x/mongo/driver/topology"
+)
+
+func TestErrorKinds(t *testing.T) {
+	authFailed := fmt.Errorf("listing: %w", mongo.CommandError{Code: 18, Message: "Authentication failed."})
+	assert.True(t, IsAuthError(authFailed))
+	assert.True(t, IsAuthError(mongo.CommandError{Code: 13}))
+	assert.False(t, IsConnectionError(authFailed))
+
+	unreachable := fmt.Errorf("ping: %w", topology.ServerSelectionError{Wrapped: errors.New("timeout")})
+	assert.True(t, IsConnectionError(unreachable))
+	assert.False(t, IsAuthError(unreachable))
+
+	syntax := mongo.CommandError{Code: 2, Message: "unknown operator: $foo"}
+	assert.False(t, IsAuthError(syntax))
+	assert.False(t, IsConnectionError(syntax))
+}
@@ -0,0 +1,183 @@
+package mongo
+
+import (
+	"context"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// cursorStage is the stage of the explain output covering the leading
+// stages of the pipeline which are pushed down to the query layer
+const cursorStage = "$cursor"
+
+// StageStats are execution statistics of a single stage of the pipeline
+type StageStats struct {
+	Stage    string
+	Returned int64
+	// Duration is the time spent in the stage itself, explain reports
+	// the time including preceding stages, which is kept in Cumulative
+	Duration   time.Duration
+	Cumulative time.Duration
+	// Pushdown is set for stages executed by the query layer, they
+	// share statistics of the $cursor stage
+	Pushdown bool
+	// Known is false if the stage wasn't found in the explain output,
+	// e.g. it was merged with another one by the optimizer
+	Known bool
+}
+
+// ExplainAggregate runs the pipeline with explain in executionStats mode
+// and returns statistics of every stage, in the order of the pipeline
+func (d *Dao) ExplainAggregate(ctx context.Context, db, collection string, pipeline Pipeline) ([]StageStats, error) {
+	command := primitive.D{
+		{Key: "explain", Value: primitive.D{
+			{Key: "aggregate", Value: collection},
+			{Key: "pipeline", Value: pipeline},
+			{Key: "cursor", Value: primitive.M{}},
+		}},
+		{Key: "verbosity", Value: "executionStats"},
+	}
+	result := primitive.M{}
+	if err := d.client.Database(db).RunCommand(ctx, command).Decode(&result); err != nil {
+		return nil, err
+	}
+
+	log.Debug().Msgf("Pipeline explained, db: %v, collection: %v", db, collection)
+
+	return AlignStageStats(pipeline, ParseExplainStages(result)), nil
+}
+
+// ParseExplainStages returns statistics of stages from the explain output
+// in the order they were executed. Stats of shards are summed up, with
+// the duration of the slowest shard.
+func ParseExplainStages(explain primitive.M) []StageStats {
+	if shards, ok := explain["shards"].(primitive.M); ok {
+		var merged []StageStats
+		for _, shard := range shards {
+			shardExplain, ok := shard.(primitive.M)
+			if !ok {
+				continue
+			}
+			merged = mergeShardStages(merged, ParseExplainStages(shardExplain))
+		}
+		return merged
+	}
+
+	stages, ok := explain["stages"].(primitive.A)
+	if !ok {
+		// whole pipeline was pushed down to the query layer
+		if execution, ok := explain["executionStats"].(primitive.M); ok {
+			stats := StageStats{Stage: cursorStage, Known: true}
+			stats.Returned = toInt64(execution["nReturned"])
+			stats.Cumulative = millis(execution["executionTimeMillis"])
+			stats.Duration = stats.Cumulative
+			return []StageStats{stats}
+		}
+		return nil
+	}
+
+	parsed := make([]StageStats, 0, len(stages))
+	var previous time.Duration
+	for _, s := range stages {
+		stage, ok := s.(primitive.M)
+		if !ok {
+			continue
+		}
+		stats := StageStats{Known: true}
+		for key := range stage {
+			if len(key) > 0 && key[0] == '$' {
+				stats.Stage = key
+			}
+		}
+		stats.Returned = toInt64(stage["nReturned"])
+		stats.Cumulative = millis(stage["executionTimeMillisEstimate"])
+		if cursor, ok := stage[cursorStage].(primitive.M); ok {
+			if execution, ok := cursor["executionStats"].(primitive.M); ok {
+				if _, ok := stage["nReturned"]; !ok {
+					stats.Returned = toInt64(execution["nReturned"])
+				}
+				if _, ok := stage["executionTimeMillisEstimate"]; !ok {
+					stats.Cumulative = millis(execution["executionTimeMillis"])
+				}
+			}
+		}
+		stats.Duration = max(stats.Cumulative-previous, 0)
+		previous = max(previous, stats.Cumulative)
+		parsed = append(parsed, stats)
+	}
+	return parsed
+}
+
+func mergeShardStages(merged, shard []StageStats) []StageStats {
+	if merged == nil {
+		return shard
+	}
+	for i := range merged {
+		if i >= len(shard) || merged[i].Stage != shard[i].Stage {
+			break
+		}
+		merged[i].Returned += shard[i].Returned
+		merged[i].Duration = max(merged[i].Duration, shard[i].Duration)
+		merged[i].Cumulative = max(merged[i].Cumulative, shard[i].Cumulative)
+	}
+	return merged
+}
+
+// AlignStageStats matches stats from the explain output with stages of
+// the pipeline. Leading stages missing in the output were pushed down
+// to the query layer and get stats of the $cursor stage.
+func AlignStageStats(pipeline Pipeline, stats []StageStats) []StageStats {
+	aligned := make([]StageStats, len(pipeline))
+	var cursor *StageStats
+	next := 0
+	if len(stats) > 0 && stats[0].Stage == cursorStage {
+		cursor = &stats[0]
+		next = 1
+	}
+
+	pushdown := cursor != nil
+	for i, stage := range pipeline {
+		aligned[i].Stage = stage[0].Key
+		if next < len(stats) && stats[next].Stage == aligned[i].Stage {
+			aligned[i] = stats[next]
+			next++
+			pushdown = false
+			continue
+		}
+		if pushdown {
+			aligned[i] = *cursor
+			aligned[i].Stage = stage[0].Key
+			aligned[i].Pushdown = true
+		}
+	}
+	return aligned
+}
+
+// SlowestStage returns the index of the stage with the longest
+// duration, or -1 if no stage took any time
+func SlowestStage(stats []StageStats) int {
+	slowest := -1
+	var longest time.Duration
+	for i, s := range stats {
+		// pushed down stages share the time, only the first one counts
+		if s.Pushdown && i > 0 && stats[i-1].Pushdown {
+			continue
+		}
+		if s.Duration > longest {
+			slowest, longest = i, s.Duration
+		}
+	}
+	return slowest
+}
+
+func toInt64(value interface{}) int64 {
+	n, _ := toFloat(value)
+	return int64(n)
+}
+
+func millis(value interface{}) time.Duration {
+	n, _ := toFloat(value)
+	return time.Duration(n) * time.Millisecond
+}
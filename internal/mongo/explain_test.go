@@ -0,0 +1,77 @@
+package mongo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func explainStages() primitive.M {
+	return primitive.M{
+		"stages": primitive.A{
+			primitive.M{
+				"$cursor": primitive.M{
+					"queryPlanner":   primitive.M{},
+					"executionStats": primitive.M{"nReturned": int32(500), "executionTimeMillis": int32(4)},
+				},
+				"nReturned":                   int64(500),
+				"executionTimeMillisEstimate": int64(4),
+			},
+			primitive.M{"$group": primitive.M{}, "nReturned": int64(20), "executionTimeMillisEstimate": int64(30)},
+			primitive.M{"$sort": primitive.M{}, "nReturned": int64(20), "executionTimeMillisEstimate": int64(31)},
+		},
+	}
+}
+
+func TestParseExplainStages(t *testing.T) {
+	stats := ParseExplainStages(explainStages())
+	require.Len(t, stats, 3)
+
+	assert.Equal(t, StageStats{Stage: "$cursor", Returned: 500, Duration: 4 * time.Millisecond, Cumulative: 4 * time.Millisecond, Known: true}, stats[0])
+	assert.Equal(t, "$group", stats[1].Stage)
+	assert.Equal(t, 26*time.Millisecond, stats[1].Duration)
+	assert.Equal(t, time.Millisecond, stats[2].Duration)
+	assert.Equal(t, int64(20), stats[2].Returned)
+
+	pushedDown := ParseExplainStages(primitive.M{
+		"executionStats": primitive.M{"nReturned": int32(3), "executionTimeMillis": int32(2)},
+	})
+	require.Len(t, pushedDown, 1)
+	assert.Equal(t, int64(3), pushedDown[0].Returned)
+
+	sharded := ParseExplainStages(primitive.M{"shards": primitive.M{
+		"rs0": explainStages(),
+		"rs1": explainStages(),
+	}})
+	require.Len(t, sharded, 3)
+	assert.Equal(t, int64(1000), sharded[0].Returned)
+	assert.Equal(t, 26*time.Millisecond, sharded[1].Duration)
+}
+
+func TestAlignStageStats(t *testing.T) {
+	pipeline := Pipeline{
+		{{Key: "$match", Value: primitive.D{}}},
+		{{Key: "$project", Value: primitive.D{}}},
+		{{Key: "$group", Value: primitive.D{}}},
+		{{Key: "$sort", Value: primitive.D{}}},
+		{{Key: "$limit", Value: int32(5)}},
+	}
+
+	aligned := AlignStageStats(pipeline, ParseExplainStages(explainStages()))
+	require.Len(t, aligned, 5)
+
+	assert.Equal(t, "$match", aligned[0].Stage)
+	assert.True(t, aligned[0].Pushdown)
+	assert.True(t, aligned[1].Pushdown)
+	assert.Equal(t, int64(500), aligned[1].Returned)
+	assert.Equal(t, "$group", aligned[2].Stage)
+	assert.False(t, aligned[2].Pushdown)
+	// $limit was merged with $sort by the optimizer
+	assert.Equal(t, StageStats{Stage: "$limit"}, aligned[4])
+
+	assert.Equal(t, 2, SlowestStage(aligned))
+	assert.Equal(t, -1, SlowestStage(AlignStageStats(pipeline, nil)))
+}
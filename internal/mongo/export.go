@@ -0,0 +1,371 @@
+package mongo
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kopecmaciej/vi-mongo/internal/util"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ExportFormat is the format documents are written in
+type ExportFormat string
+
+const (
+	FormatJSON     ExportFormat = "JSON"
+	FormatNDJSON   ExportFormat = "NDJSON"
+	FormatCSV      ExportFormat = "CSV"
+	FormatMarkdown ExportFormat = "Markdown"
+)
+
+// ExportFormats are all supported formats, in the order they are offered
+var ExportFormats = []ExportFormat{FormatJSON, FormatNDJSON, FormatCSV, FormatMarkdown}
+
+// ExportDocuments writes documents in the format. JSON formats keep
+// types in relaxed extended JSON, tabular formats have a column for
+// every top-level field, with embedded documents written as JSON,
+// and values of cells are formatted with values.
+func ExportDocuments(w io.Writer, documents []primitive.M, format ExportFormat, values util.ValueFormat) error {
+	switch format {
+	case FormatJSON:
+		return WriteDocuments(w, documents)
+	case FormatNDJSON:
+		return writeNDJSON(w, documents)
+	case FormatCSV:
+		return writeCSV(w, documents, values)
+	case FormatMarkdown:
+		return writeMarkdown(w, documents, values)
+	}
+	return fmt.Errorf("unsupported format %q", format)
+}
+
+// DocumentStream writes documents one by one as a JSON array, NDJSON
+// or CSV, so results of any size are written without holding them in memory
+type DocumentStream struct {
+	w      io.Writer
+	format ExportFormat
+	count  int64
+	// csv writes columns of CSV streams, the header is written first
+	csv     *csv.Writer
+	columns []string
+	values  util.ValueFormat
+}
+
+// NewDocumentStream returns the stream of documents written to w,
+// only JSON formats can be streamed without known columns
+func NewDocumentStream(w io.Writer, format ExportFormat) (*DocumentStream, error) {
+	if format != FormatJSON && format != FormatNDJSON {
+		return nil, fmt.Errorf("format %q can't be streamed", format)
+	}
+	return &DocumentStream{w: w, format: format}, nil
+}
+
+// NewCSVStream returns the stream of documents written to w as CSV with
+// the columns, which can be dotted paths of fields in embedded documents
+func NewCSVStream(w io.Writer, columns []string) *DocumentStream {
+	return &DocumentStream{w: w, format: FormatCSV, csv: csv.NewWriter(w), columns: columns}
+}
+
+// SetValueFormat sets the format of values of CSV columns
+func (s *DocumentStream) SetValueFormat(values util.ValueFormat) {
+	s.values = values
+}
+
+// Write writes the document in relaxed extended JSON,
+// or its columns if the stream is CSV
+func (s *DocumentStream) Write(doc primitive.M) error {
+	if s.format == FormatCSV {
+		return s.writeRecord(doc)
+	}
+	jsoned, err := bson.MarshalExtJSON(doc, false, false)
+	if err != nil {
+		return err
+	}
+	line := string(jsoned) + "\n"
+	if s.format == FormatJSON {
+		line = ",\n" + string(jsoned)
+		if s.count == 0 {
+			line = "[\n" + string(jsoned)
+		}
+	}
+	if _, err := io.WriteString(s.w, line); err != nil {
+		return err
+	}
+	s.count++
+	return nil
+}
+
+func (s *DocumentStream) writeRecord(doc primitive.M) error {
+	if s.count == 0 {
+		if err := s.csv.Write(s.columns); err != nil {
+			return err
+		}
+	}
+	record := make([]string, len(s.columns))
+	for i, column := range s.columns {
+		if value, ok := exportPath(doc, column); ok {
+			record[i] = exportValue(value, s.values)
+		}
+	}
+	if err := s.csv.Write(record); err != nil {
+		return err
+	}
+	s.count++
+	return nil
+}
+
+// Close ends the JSON array or flushes CSV, the output is the same
+// as of ExportDocuments
+func (s *DocumentStream) Close() error {
+	switch s.format {
+	case FormatCSV:
+		if s.count == 0 {
+			if err := s.csv.Write(s.columns); err != nil {
+				return err
+			}
+		}
+		s.csv.Flush()
+		return s.csv.Error()
+	case FormatJSON:
+		end := "\n]\n"
+		if s.count == 0 {
+			end = "[\n]\n"
+		}
+		_, err := io.WriteString(s.w, end)
+		return err
+	}
+	return nil
+}
+
+// Count returns the number of written documents
+func (s *DocumentStream) Count() int64 {
+	return s.count
+}
+
+// StreamFormat returns the format documents are streamed to the file in,
+// by the extension of the file, JSON is used for unknown extensions
+func StreamFormat(path string) ExportFormat {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".ndjson", ".jsonl":
+		return FormatNDJSON
+	case ".csv":
+		return FormatCSV
+	}
+	return FormatJSON
+}
+
+// CSVFields returns top-level fields with their types, _id goes
+// first and the rest is sorted, as columns of exported CSV
+func CSVFields(types map[string]string) []string {
+	fields := make([]string, 0, len(types))
+	for field := range types {
+		if field != "_id" {
+			fields = append(fields, field)
+		}
+	}
+	sort.Strings(fields)
+	if _, ok := types["_id"]; ok {
+		fields = append([]string{"_id"}, fields...)
+	}
+	return fields
+}
+
+// CSVColumns returns columns of the selected top-level fields. Fields
+// of embedded documents become dotted columns if they're in flatTypes,
+// otherwise the whole embedded document is written as JSON.
+func CSVColumns(selected []string, flatTypes map[string]string) []string {
+	columns := []string{}
+	for _, field := range selected {
+		var nested []string
+		for path := range flatTypes {
+			if strings.HasPrefix(path, field+".") {
+				nested = append(nested, path)
+			}
+		}
+		sort.Strings(nested)
+		if _, ok := flatTypes[field]; ok || len(nested) == 0 {
+			columns = append(columns, field)
+		}
+		columns = append(columns, nested...)
+	}
+	return columns
+}
+
+// RunExporter runs the command of the external exporter by the shell,
+// documents are written to its stdin as NDJSON and its output to w
+func RunExporter(ctx context.Context, command string, documents []primitive.M, w io.Writer) error {
+	var stdin bytes.Buffer
+	if err := writeNDJSON(&stdin, documents); err != nil {
+		return err
+	}
+
+	var stderr bytes.Buffer
+	cmd := shellCommand(ctx, command)
+	cmd.Stdin = &stdin
+	cmd.Stdout = w
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("%w: %s", err, msg)
+		}
+		return err
+	}
+	return nil
+}
+
+func shellCommand(ctx context.Context, command string) *exec.Cmd {
+	if runtime.GOOS == "windows" {
+		return exec.CommandContext(ctx, "cmd", "/C", command)
+	}
+	return exec.CommandContext(ctx, "sh", "-c", command)
+}
+
+// ExportFileName returns the name of the file documents of the
+// collection are exported to, the time keeps names unique
+func ExportFileName(coll, extension string, now time.Time) string {
+	if extension == "" {
+		extension = "out"
+	}
+	return fmt.Sprintf("%s-%s.%s", coll, now.Format("20060102-150405"), strings.TrimPrefix(extension, "."))
+}
+
+func writeNDJSON(w io.Writer, documents []primitive.M) error {
+	for _, doc := range documents {
+		jsoned, err := bson.MarshalExtJSON(doc, false, false)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(w, string(jsoned)+"\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeCSV(w io.Writer, documents []primitive.M, values util.ValueFormat) error {
+	columns := exportColumns(documents)
+	writer := csv.NewWriter(w)
+	if err := writer.Write(columns); err != nil {
+		return err
+	}
+	for _, doc := range documents {
+		record := make([]string, len(columns))
+		for i, column := range columns {
+			if value, ok := doc[column]; ok {
+				record[i] = exportValue(value, values)
+			}
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+func writeMarkdown(w io.Writer, documents []primitive.M, values util.ValueFormat) error {
+	return WriteMarkdownTable(w, documents, exportColumns(documents), values)
+}
+
+// WriteMarkdownTable writes documents as the GitHub-flavored Markdown
+// table with the columns, in the given order. Columns can be dotted
+// paths of fields in embedded documents, e.g. "address.city".
+func WriteMarkdownTable(w io.Writer, documents []primitive.M, columns []string, values util.ValueFormat) error {
+	if len(columns) == 0 {
+		return nil
+	}
+	rows := make([][]string, 0, len(documents)+2)
+	separator := make([]string, len(columns))
+	for i := range columns {
+		separator[i] = "---"
+	}
+	rows = append(rows, append([]string{}, columns...), separator)
+	for _, doc := range documents {
+		row := make([]string, len(columns))
+		for i, column := range columns {
+			if value, ok := exportPath(doc, column); ok {
+				row[i] = exportValue(value, values)
+			}
+		}
+		rows = append(rows, row)
+	}
+
+	for _, row := range rows {
+		for i, cell := range row {
+			row[i] = markdownEscaper.Replace(cell)
+		}
+		if _, err := io.WriteString(w, "| "+strings.Join(row, " | ")+" |\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// exportPath returns the value of the column, a field with dots
+// in its name goes before the path of the embedded field
+func exportPath(doc primitive.M, column string) (interface{}, bool) {
+	if value, ok := doc[column]; ok {
+		return value, true
+	}
+	return lookupPath(doc, column)
+}
+
+// markdownEscaper escapes characters breaking cells of the table
+var markdownEscaper = strings.NewReplacer("|", `\|`, "\r\n", "<br>", "\n", "<br>")
+
+// exportColumns returns top-level fields of all documents,
+// _id goes first and the rest is sorted
+func exportColumns(documents []primitive.M) []string {
+	seen := map[string]bool{}
+	columns := []string{}
+	hasID := false
+	for _, doc := range documents {
+		for key := range doc {
+			if key == "_id" {
+				hasID = true
+				continue
+			}
+			if !seen[key] {
+				seen[key] = true
+				columns = append(columns, key)
+			}
+		}
+	}
+	sort.Strings(columns)
+	if hasID {
+		columns = append([]string{"_id"}, columns...)
+	}
+	return columns
+}
+
+// exportValue returns the value as it's written in a cell,
+// embedded documents and arrays are written as JSON
+func exportValue(value interface{}, values util.ValueFormat) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case float64:
+		return values.Double(v, strconv.FormatFloat(v, 'f', -1, 64))
+	case primitive.M, primitive.D, primitive.A:
+		jsoned, err := bson.MarshalExtJSON(primitive.M{"v": v}, false, false)
+		if err != nil {
+			return util.GetValueByType(v)
+		}
+		// value is wrapped, as arrays can't be marshaled on their own
+		return strings.TrimSuffix(strings.TrimPrefix(string(jsoned), `{"v":`), "}")
+	case primitive.Decimal128:
+		return v.String()
+	}
+	return values.Value(value)
+}
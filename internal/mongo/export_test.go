@@ -0,0 +1,161 @@
+package mongo
+
+import (
+	"bytes"
+	"context"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/kopecmaciej/vi-mongo/internal/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func exportedDocuments() []primitive.M {
+	created := primitive.NewDateTimeFromTime(time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC))
+	return []primitive.M{
+		{"_id": int32(1), "name": "Alice", "score": 9.5, "created": created},
+		{"_id": int32(2), "name": "Bob | Jr.", "tags": primitive.A{"a", "b"}, "note": "line\nbreak"},
+	}
+}
+
+func TestExportDocumentsNDJSON(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, ExportDocuments(&buf, []primitive.M{{"_id": int32(1)}, {"_id": int32(2)}}, FormatNDJSON, util.ValueFormat{}))
+	assert.Equal(t, "{\"_id\":1}\n{\"_id\":2}\n", buf.String())
+}
+
+func TestExportDocumentsCSV(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, ExportDocuments(&buf, exportedDocuments(), FormatCSV, util.ValueFormat{}))
+
+	expected := "_id,created,name,note,score,tags\n" +
+		"1,2024-05-01T10:00:00Z,Alice,,9.5,\n" +
+		"2,,Bob | Jr.,\"line\nbreak\",,\"[\"\"a\"\",\"\"b\"\"]\"\n"
+	assert.Equal(t, expected, buf.String())
+}
+
+func TestExportDocumentsMarkdown(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, ExportDocuments(&buf, exportedDocuments(), FormatMarkdown, util.ValueFormat{}))
+
+	expected := "| _id | created | name | note | score | tags |\n" +
+		"| --- | --- | --- | --- | --- | --- |\n" +
+		"| 1 | 2024-05-01T10:00:00Z | Alice |  | 9.5 |  |\n" +
+		"| 2 |  | Bob \\| Jr. | line<br>break |  | [\"a\",\"b\"] |\n"
+	assert.Equal(t, expected, buf.String())
+
+	buf.Reset()
+	require.NoError(t, ExportDocuments(&buf, nil, FormatMarkdown, util.ValueFormat{}))
+	assert.Empty(t, buf.String())
+}
+
+func TestWriteMarkdownTable(t *testing.T) {
+	documents := []primitive.M{
+		{"_id": int32(1), "name": "Alice", "address": primitive.M{"city": "Paris", "zip": "75001"}},
+		{"_id": int32(2), "name": "Bob", "a.b": "dotted"},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteMarkdownTable(&buf, documents, []string{"name", "address.city", "a.b"}, util.ValueFormat{}))
+
+	expected := "| name | address.city | a.b |\n" +
+		"| --- | --- | --- |\n" +
+		"| Alice | Paris |  |\n" +
+		"| Bob |  | dotted |\n"
+	assert.Equal(t, expected, buf.String())
+}
+
+func TestExportDocumentsUnsupportedFormat(t *testing.T) {
+	var buf bytes.Buffer
+	assert.Error(t, ExportDocuments(&buf, nil, "XML", util.ValueFormat{}))
+}
+
+func TestRunExporter(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("exporter commands are run by sh")
+	}
+	documents := []primitive.M{{"_id": int32(1)}, {"_id": int32(2)}}
+
+	var buf bytes.Buffer
+	require.NoError(t, RunExporter(context.Background(), "wc -l | tr -d ' '", documents, &buf))
+	assert.Equal(t, "2\n", buf.String())
+
+	err := RunExporter(context.Background(), "echo 'no such format' >&2; exit 3", documents, &buf)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no such format")
+}
+
+func TestExportFileName(t *testing.T) {
+	now := time.Date(2024, 5, 1, 10, 20, 30, 0, time.UTC)
+	assert.Equal(t, "users-20240501-102030.parquet", ExportFileName("users", ".parquet", now))
+	assert.Equal(t, "users-20240501-102030.out", ExportFileName("users", "", now))
+}
+
+func TestDocumentStream(t *testing.T) {
+	documents := []primitive.M{{"_id": int32(1)}, {"_id": int32(2)}}
+	for _, format := range []ExportFormat{FormatJSON, FormatNDJSON} {
+		var streamed, exported bytes.Buffer
+		stream, err := NewDocumentStream(&streamed, format)
+		require.NoError(t, err)
+		for _, doc := range documents {
+			require.NoError(t, stream.Write(doc))
+		}
+		require.NoError(t, stream.Close())
+		require.NoError(t, ExportDocuments(&exported, documents, format, util.ValueFormat{}))
+
+		assert.Equal(t, exported.String(), streamed.String(), format)
+		assert.Equal(t, int64(2), stream.Count())
+	}
+
+	var empty bytes.Buffer
+	stream, err := NewDocumentStream(&empty, FormatJSON)
+	require.NoError(t, err)
+	require.NoError(t, stream.Close())
+	assert.Equal(t, "[\n]\n", empty.String())
+
+	_, err = NewDocumentStream(&empty, FormatCSV)
+	assert.Error(t, err)
+}
+
+func TestCSVStream(t *testing.T) {
+	var buf bytes.Buffer
+	stream := NewCSVStream(&buf, []string{"_id", "address.city", "tags", "address"})
+	require.NoError(t, stream.Write(primitive.M{"_id": int32(1), "address": primitive.M{"city": "Oslo, NO"}, "tags": primitive.A{"a"}}))
+	require.NoError(t, stream.Write(primitive.M{"_id": int32(2), "address": "unknown"}))
+	require.NoError(t, stream.Close())
+	assert.Equal(t, "_id,address.city,tags,address\n1,\"Oslo, NO\",\"[\"\"a\"\"]\",\"{\"\"city\"\":\"\"Oslo, NO\"\"}\"\n2,,,unknown\n", buf.String())
+
+	var empty bytes.Buffer
+	require.NoError(t, NewCSVStream(&empty, []string{"_id"}).Close())
+	assert.Equal(t, "_id\n", empty.String())
+
+	var formatted bytes.Buffer
+	stream = NewCSVStream(&formatted, []string{"total", "ratio", "note"})
+	stream.SetValueFormat(util.ValueFormat{ThousandsSeparator: "_", ScientificThreshold: 1e6, MaxStringLength: 4})
+	require.NoError(t, stream.Write(primitive.M{"total": int64(1234567), "ratio": 0.0000001, "note": "too long"}))
+	require.NoError(t, stream.Close())
+	assert.Equal(t, "total,ratio,note\n1_234_567,1e-07,too ...\n", formatted.String())
+}
+
+func TestCSVColumns(t *testing.T) {
+	types := map[string]string{"name": "String", "_id": "ObjectId", "address": "Object"}
+	assert.Equal(t, []string{"_id", "address", "name"}, CSVFields(types))
+
+	flat := map[string]string{"_id": "ObjectId", "name": "String", "address.city": "String", "address.geo.lat": "Double"}
+	assert.Equal(t, []string{"name", "address.city", "address.geo.lat"}, CSVColumns([]string{"name", "address"}, flat))
+	// field which is not a document in some documents keeps its own column
+	flat["address"] = "String"
+	assert.Equal(t, []string{"address", "address.city", "address.geo.lat"}, CSVColumns([]string{"address"}, flat))
+	assert.Equal(t, []string{"missing"}, CSVColumns([]string{"missing"}, flat))
+}
+
+func TestStreamFormat(t *testing.T) {
+	assert.Equal(t, FormatNDJSON, StreamFormat("out/users.ndjson"))
+	assert.Equal(t, FormatNDJSON, StreamFormat("users.JSONL"))
+	assert.Equal(t, FormatCSV, StreamFormat("users.csv"))
+	assert.Equal(t, FormatJSON, StreamFormat("users.json"))
+	assert.Equal(t, FormatJSON, StreamFormat("users"))
+}
@@ -0,0 +1,110 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	// FanOutCollectionField is added to every document returned by
+	// FanOutFind, it holds the name of the collection of the document
+	FanOutCollectionField = "__collection"
+	// maxFanOutQueries is the number of collections queried at once
+	maxFanOutQueries = 8
+)
+
+// MatchCollections returns collections matching any of comma separated
+// patterns, e.g. "tenant_*, shared". History collections are skipped,
+// as they don't have the same shape as collections they belong to.
+func MatchCollections(colls []string, patterns string) ([]string, error) {
+	var matched []string
+	for _, coll := range colls {
+		if IsHistoryCollection(coll) {
+			continue
+		}
+		for _, pattern := range strings.Split(patterns, ",") {
+			pattern = strings.TrimSpace(pattern)
+			if pattern == "" {
+				continue
+			}
+			ok, err := path.Match(pattern, coll)
+			if err != nil {
+				return nil, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+			}
+			if ok {
+				matched = append(matched, coll)
+				break
+			}
+		}
+	}
+	return matched, nil
+}
+
+// ListCollectionNames returns names of collections in the database
+func (d *Dao) ListCollectionNames(ctx context.Context, db string) ([]string, error) {
+	return d.client.Database(db).ListCollectionNames(ctx, primitive.M{})
+}
+
+// FanOutFind runs the same query against every collection concurrently,
+// at most limit documents are returned from each collection. Documents
+// are merged in the order of collections, with the name of the collection
+// in FanOutCollectionField. Errors of all collections are joined.
+func (d *Dao) FanOutFind(ctx context.Context, db string, colls []string, filter primitive.M, sort primitive.D, limit int64) ([]primitive.M, error) {
+	results := make([][]primitive.M, len(colls))
+	errs := make([]error, len(colls))
+	sem := make(chan struct{}, maxFanOutQueries)
+
+	var wg sync.WaitGroup
+	for i, coll := range colls {
+		wg.Add(1)
+		go func(i int, coll string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			findOptions := options.Find().SetLimit(limit)
+			if len(sort) > 0 {
+				findOptions.SetSort(sort)
+			}
+			cursor, err := d.readCollection(db, coll).Find(ctx, filter, findOptions)
+			if err != nil {
+				errs[i] = fmt.Errorf("%s: %w", coll, err)
+				return
+			}
+			defer cursor.Close(ctx)
+
+			var documents []primitive.M
+			if err := cursor.All(ctx, &documents); err != nil {
+				errs[i] = fmt.Errorf("%s: %w", coll, err)
+				return
+			}
+			results[i] = documents
+		}(i, coll)
+	}
+	wg.Wait()
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	return mergeFanOutResults(colls, results), nil
+}
+
+// mergeFanOutResults flattens documents of collections,
+// marking each one with the collection it comes from
+func mergeFanOutResults(colls []string, results [][]primitive.M) []primitive.M {
+	var merged []primitive.M
+	for i, documents := range results {
+		for _, doc := range documents {
+			doc[FanOutCollectionField] = colls[i]
+			merged = append(merged, doc)
+		}
+	}
+	return merged
+}
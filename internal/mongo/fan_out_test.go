@@ -0,0 +1,52 @@
+package mongo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestMatchCollections(t *testing.T) {
+	colls := []string{"tenant_a", "tenant_b", "tenant_a" + historySuffix, "shared", "users"}
+
+	tests := []struct {
+		name     string
+		patterns string
+		expected []string
+	}{
+		{"wildcard", "tenant_*", []string{"tenant_a", "tenant_b"}},
+		{"multiple patterns", "tenant_b, shared", []string{"tenant_b", "shared"}},
+		{"no match", "orders", nil},
+		{"empty patterns", " , ", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matched, err := MatchCollections(colls, tt.patterns)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, matched)
+		})
+	}
+
+	_, err := MatchCollections(colls, "tenant_[")
+	assert.Error(t, err)
+}
+
+func TestMergeFanOutResults(t *testing.T) {
+	merged := mergeFanOutResults(
+		[]string{"tenant_a", "tenant_b", "tenant_c"},
+		[][]primitive.M{
+			{{"_id": 1}, {"_id": 2}},
+			nil,
+			{{"_id": 1}},
+		},
+	)
+
+	assert.Equal(t, []primitive.M{
+		{"_id": 1, FanOutCollectionField: "tenant_a"},
+		{"_id": 2, FanOutCollectionField: "tenant_a"},
+		{"_id": 1, FanOutCollectionField: "tenant_c"},
+	}, merged)
+}
@@ -0,0 +1,118 @@
+package mongo
+
+import (
+	"sort"
+	"strconv"
+
+	"github.com/kopecmaciej/vi-mongo/internal/util"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// maxSuggestedValues is the maximum number of distinct values that field
+// can have in the sample to be treated as enum-like
+const maxSuggestedValues = 10
+
+// SampleFieldValues returns values of low-cardinality fields found in the
+// documents, formatted as they would be typed in the query, e.g. `"active"`.
+// Field is treated as enum-like if it has at most maxSuggestedValues
+// distinct values and at least one of them repeats in the sample.
+// Nested fields use dot notation.
+func SampleFieldValues(documents []primitive.M) map[string][]string {
+	values := make(map[string]map[string]bool)
+	occurrences := make(map[string]int)
+
+	walkFields(documents, func(field string, value interface{}) {
+		if field == "_id" {
+			return
+		}
+		text, ok := queryValue(value)
+		if !ok {
+			return
+		}
+		if values[field] == nil {
+			values[field] = make(map[string]bool)
+		}
+		values[field][text] = true
+		occurrences[field]++
+	})
+
+	result := make(map[string][]string)
+	for field, distinct := range values {
+		if len(distinct) > maxSuggestedValues || len(distinct) == occurrences[field] {
+			continue
+		}
+		fieldValues := make([]string, 0, len(distinct))
+		for value := range distinct {
+			fieldValues = append(fieldValues, value)
+		}
+		sort.Strings(fieldValues)
+		result[field] = fieldValues
+	}
+
+	return result
+}
+
+// SampleFieldTypes returns types of the fields found in the documents,
+// e.g. util.TypeString, or util.TypeMixed if the type differs between
+// documents. Nested fields use dot notation.
+func SampleFieldTypes(documents []primitive.M) map[string]string {
+	types := make(map[string]string)
+
+	walkFields(documents, func(field string, value interface{}) {
+		valueType := util.GetMongoType(value)
+		if current, ok := types[field]; ok && current != valueType {
+			valueType = util.TypeMixed
+		}
+		types[field] = valueType
+	})
+
+	return types
+}
+
+// walkFields calls fn for every field of the documents which is not
+// an object, nested objects are walked recursively
+func walkFields(documents []primitive.M, fn func(field string, value interface{})) {
+	var walk func(string, interface{})
+	walk = func(field string, value interface{}) {
+		var nested map[string]interface{}
+		switch v := value.(type) {
+		case primitive.M:
+			nested = v
+		case map[string]interface{}:
+			nested = v
+		default:
+			fn(field, value)
+			return
+		}
+		for key, val := range nested {
+			walk(field+"."+key, val)
+		}
+	}
+
+	for _, doc := range documents {
+		for key, value := range doc {
+			walk(key, value)
+		}
+	}
+}
+
+// queryValue formats scalar value as it's used in the query,
+// other types are not suggested
+func queryValue(value interface{}) (string, bool) {
+	switch v := value.(type) {
+	case string:
+		return strconv.Quote(v), true
+	case bool:
+		return strconv.FormatBool(v), true
+	case int32:
+		return strconv.FormatInt(int64(v), 10), true
+	case int64:
+		return strconv.FormatInt(v, 10), true
+	case int:
+		return strconv.Itoa(v), true
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), true
+	default:
+		return "", false
+	}
+}
@@ -0,0 +1,55 @@
+package mongo
+
+import (
+	"testing"
+
+	"github.com/kopecmaciej/vi-mongo/internal/util"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestSampleFieldValues(t *testing.T) {
+	documents := []primitive.M{
+		{"_id": primitive.NewObjectID(), "name": "John", "status": "active", "age": int32(30), "address": primitive.M{"country": "PL"}},
+		{"_id": primitive.NewObjectID(), "name": "Anna", "status": "inactive", "age": int32(25), "address": primitive.M{"country": "PL"}},
+		{"_id": primitive.NewObjectID(), "name": "Mark", "status": "active", "age": int32(41), "verified": true},
+		{"_id": primitive.NewObjectID(), "name": "Eve", "status": "active", "tags": primitive.A{"a"}, "verified": true},
+	}
+
+	values := SampleFieldValues(documents)
+
+	assert.Equal(t, []string{`"active"`, `"inactive"`}, values["status"])
+	assert.Equal(t, []string{`"PL"`}, values["address.country"])
+	assert.Equal(t, []string{"true"}, values["verified"])
+	assert.NotContains(t, values, "name", "all values are unique")
+	assert.NotContains(t, values, "age", "all values are unique")
+	assert.NotContains(t, values, "_id")
+	assert.NotContains(t, values, "tags", "arrays are not suggested")
+}
+
+func TestSampleFieldValues_TooManyValues(t *testing.T) {
+	documents := []primitive.M{}
+	for i := 0; i < maxSuggestedValues+1; i++ {
+		documents = append(documents, primitive.M{"n": int64(i)}, primitive.M{"n": int64(i)})
+	}
+
+	values := SampleFieldValues(documents)
+
+	assert.NotContains(t, values, "n")
+}
+
+func TestSampleFieldTypes(t *testing.T) {
+	documents := []primitive.M{
+		{"_id": primitive.NewObjectID(), "name": "John", "age": int32(30), "address": primitive.M{"city": "Warsaw"}},
+		{"_id": primitive.NewObjectID(), "name": "Anna", "age": "unknown", "active": true},
+	}
+
+	types := SampleFieldTypes(documents)
+
+	assert.Equal(t, util.TypeObjectId, types["_id"])
+	assert.Equal(t, util.TypeString, types["name"])
+	assert.Equal(t, util.TypeMixed, types["age"])
+	assert.Equal(t, util.TypeString, types["address.city"])
+	assert.Equal(t, util.TypeBool, types["active"])
+	assert.NotContains(t, types, "address")
+}
@@ -0,0 +1,184 @@
+package mongo
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kopecmaciej/vi-mongo/internal/util"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// FilterOperator is an operator that can be chosen in the filter builder
+type FilterOperator struct {
+	Label    string
+	Operator string
+}
+
+// FilterOperators are operators available in the filter builder
+var FilterOperators = []FilterOperator{
+	{"equals", "$eq"},
+	{"not equals", "$ne"},
+	{"greater than", "$gt"},
+	{"greater than or equal", "$gte"},
+	{"less than", "$lt"},
+	{"less than or equal", "$lte"},
+	{"one of (comma separated)", "$in"},
+	{"none of (comma separated)", "$nin"},
+	{"exists (true/false)", "$exists"},
+	{"matches regex", "$regex"},
+}
+
+// FilterCondition is a single condition of the filter builder
+type FilterCondition struct {
+	Field    string
+	Operator string
+	Value    string
+	// Type is the type of the field in sampled documents, e.g. util.TypeInt,
+	// used to convert the value, empty if the type is not known
+	Type string
+}
+
+// Query returns the condition as query, e.g. `{ "age": { "$gt": 18 } }`
+func (c FilterCondition) Query() (string, error) {
+	if c.Field == "" {
+		return "", fmt.Errorf("field is required")
+	}
+
+	var value string
+	var err error
+	switch c.Operator {
+	case "$exists":
+		var exists bool
+		exists, err = strconv.ParseBool(strings.TrimSpace(c.Value))
+		value = strconv.FormatBool(exists)
+	case "$regex":
+		value = strconv.Quote(c.Value)
+	case "$in", "$nin":
+		values := []string{}
+		for _, v := range strings.Split(c.Value, ",") {
+			v, err = typedValue(v, c.Type)
+			if err != nil {
+				break
+			}
+			values = append(values, v)
+		}
+		value = "[" + strings.Join(values, ", ") + "]"
+	default:
+		value, err = typedValue(c.Value, c.Type)
+	}
+	if err != nil {
+		return "", fmt.Errorf("invalid value for %s: %w", c.Field, err)
+	}
+
+	if c.Operator == "$eq" {
+		return fmt.Sprintf(`{ %q: %s }`, c.Field, value), nil
+	}
+	return fmt.Sprintf(`{ %q: { %q: %s } }`, c.Field, c.Operator, value), nil
+}
+
+// BuildFilter combines conditions with AND (or OR if useOr is true)
+// and returns the filter as it's typed in the query bar
+func BuildFilter(conditions []FilterCondition, useOr bool) (string, error) {
+	if len(conditions) == 0 {
+		return "{}", nil
+	}
+
+	queries := make([]string, 0, len(conditions))
+	fields := make(map[string]bool)
+	for _, condition := range conditions {
+		query, err := condition.Query()
+		if err != nil {
+			return "", err
+		}
+		queries = append(queries, query)
+		fields[condition.Field] = true
+	}
+
+	if len(queries) == 1 {
+		return util.FormatJson(queries[0]), nil
+	}
+
+	if useOr {
+		return util.FormatJson(`{ "$or": [` + strings.Join(queries, ", ") + `] }`), nil
+	}
+	// the same field can't be used twice in one object
+	if len(fields) < len(conditions) {
+		return util.FormatJson(`{ "$and": [` + strings.Join(queries, ", ") + `] }`), nil
+	}
+
+	parts := make([]string, 0, len(queries))
+	for _, query := range queries {
+		query = strings.TrimSpace(query)
+		parts = append(parts, query[1:len(query)-1])
+	}
+	return util.FormatJson("{" + strings.Join(parts, ",") + "}"), nil
+}
+
+// FilterValueHint returns hint how the value of given type should be typed
+func FilterValueHint(valueType string) string {
+	switch valueType {
+	case util.TypeString:
+		return "text"
+	case util.TypeInt, util.TypeDouble:
+		return "number"
+	case util.TypeBool:
+		return "true or false"
+	case util.TypeObjectId:
+		return "24 hex characters"
+	case util.TypeDate:
+		return "date, e.g. 2024-01-31 or RFC3339"
+	default:
+		return "JSON value, text is quoted"
+	}
+}
+
+// typedValue converts the value typed by the user to the query value
+// of the given type
+func typedValue(value, valueType string) (string, error) {
+	value = strings.TrimSpace(value)
+
+	switch valueType {
+	case util.TypeString:
+		if unquoted, err := strconv.Unquote(value); err == nil {
+			return strconv.Quote(unquoted), nil
+		}
+		return strconv.Quote(value), nil
+	case util.TypeInt, util.TypeDouble:
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			return "", fmt.Errorf("%q is not a number", value)
+		}
+		return value, nil
+	case util.TypeBool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return "", fmt.Errorf("%q is not true or false", value)
+		}
+		return strconv.FormatBool(b), nil
+	case util.TypeObjectId:
+		id, err := primitive.ObjectIDFromHex(strings.Trim(value, `"`))
+		if err != nil {
+			return "", fmt.Errorf("%q is not an ObjectID", value)
+		}
+		return fmt.Sprintf(`ObjectID("%s")`, id.Hex()), nil
+	case util.TypeDate:
+		for _, layout := range []string{time.RFC3339, time.DateTime, time.DateOnly} {
+			if t, err := time.Parse(layout, strings.Trim(value, `"`)); err == nil {
+				return fmt.Sprintf(`{ "$date": %q }`, t.Format(time.RFC3339)), nil
+			}
+		}
+		return "", fmt.Errorf("%q is not a date", value)
+	default:
+		if value == "true" || value == "false" || value == "null" {
+			return value, nil
+		}
+		if _, err := strconv.ParseFloat(value, 64); err == nil {
+			return value, nil
+		}
+		if unquoted, err := strconv.Unquote(value); err == nil {
+			return strconv.Quote(unquoted), nil
+		}
+		return strconv.Quote(value), nil
+	}
+}
@@ -0,0 +1,77 @@
+package mongo
+
+import (
+	"testing"
+
+	"github.com/kopecmaciej/vi-mongo/internal/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilterConditionQuery(t *testing.T) {
+	testCases := []struct {
+		name      string
+		condition FilterCondition
+		expected  string
+	}{
+		{"Equals string", FilterCondition{"name", "$eq", "John", util.TypeString}, `{ "name": "John" }`},
+		{"Quoted string", FilterCondition{"name", "$eq", `"John"`, util.TypeString}, `{ "name": "John" }`},
+		{"Greater than number", FilterCondition{"age", "$gt", "18", util.TypeInt}, `{ "age": { "$gt": 18 } }`},
+		{"In strings", FilterCondition{"status", "$in", "new, active", util.TypeString}, `{ "status": { "$in": ["new", "active"] } }`},
+		{"Exists", FilterCondition{"email", "$exists", "false", ""}, `{ "email": { "$exists": false } }`},
+		{"Regex", FilterCondition{"name", "$regex", "^Jo", util.TypeString}, `{ "name": { "$regex": "^Jo" } }`},
+		{"ObjectID", FilterCondition{"_id", "$eq", "5f8f9e5f1c9d440000d1b3c5", util.TypeObjectId}, `{ "_id": ObjectID("5f8f9e5f1c9d440000d1b3c5") }`},
+		{"Date", FilterCondition{"createdAt", "$gte", "2024-01-31", util.TypeDate}, `{ "createdAt": { "$gte": { "$date": "2024-01-31T00:00:00Z" } } }`},
+		{"Unknown type number", FilterCondition{"score", "$lt", "1.5", util.TypeMixed}, `{ "score": { "$lt": 1.5 } }`},
+		{"Unknown type text", FilterCondition{"tag", "$ne", "a b", ""}, `{ "tag": { "$ne": "a b" } }`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			query, err := tc.condition.Query()
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, query)
+		})
+	}
+}
+
+func TestFilterConditionQuery_InvalidValue(t *testing.T) {
+	invalid := []FilterCondition{
+		{"age", "$gt", "eighteen", util.TypeInt},
+		{"active", "$eq", "yes", util.TypeBool},
+		{"_id", "$eq", "123", util.TypeObjectId},
+		{"createdAt", "$lt", "yesterday", util.TypeDate},
+		{"email", "$exists", "maybe", ""},
+		{"", "$eq", "1", ""},
+	}
+
+	for _, condition := range invalid {
+		_, err := condition.Query()
+		assert.Error(t, err, condition)
+	}
+}
+
+func TestBuildFilter(t *testing.T) {
+	name := FilterCondition{"name", "$eq", "John", util.TypeString}
+	age := FilterCondition{"age", "$gt", "18", util.TypeInt}
+	maxAge := FilterCondition{"age", "$lt", "65", util.TypeInt}
+
+	filter, err := BuildFilter(nil, false)
+	require.NoError(t, err)
+	assert.Equal(t, "{}", filter)
+
+	filter, err = BuildFilter([]FilterCondition{name, age}, false)
+	require.NoError(t, err)
+	assert.Equal(t, `{ "name": "John", "age": { "$gt": 18 } }`, filter)
+
+	filter, err = BuildFilter([]FilterCondition{age, maxAge}, false)
+	require.NoError(t, err)
+	assert.Equal(t, `{ "$and": [ { "age": { "$gt": 18 } }, { "age": { "$lt": 65 } } ] }`, filter)
+
+	filter, err = BuildFilter([]FilterCondition{name, age}, true)
+	require.NoError(t, err)
+	assert.Equal(t, `{ "$or": [ { "name": "John" }, { "age": { "$gt": 18 } } ] }`, filter)
+
+	_, err = ParseStringQuery(filter)
+	assert.NoError(t, err, "generated filter can be parsed")
+}
@@ -0,0 +1,309 @@
+package mongo
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	GeoPoint   = "Point"
+	GeoPolygon = "Polygon"
+
+	// earthRadius is the radius of the Earth in meters,
+	// the one used by MongoDB for spherical geometry
+	earthRadius = 6378100
+	// maxMapZoom is the zoom of OpenStreetMap links of points
+	maxMapZoom = 16
+	// geoIndexType is the type of the index of GeoJSON fields
+	geoIndexType = "2dsphere"
+)
+
+// GeoJSON is the Point or Polygon in GeoJSON format, Points are
+// [longitude, latitude] of the point or of the outer ring of the polygon
+type GeoJSON struct {
+	Type   string
+	Points [][2]float64
+}
+
+// ParseGeoJSON returns the geometry if the value is the GeoJSON Point
+// or Polygon, e.g. { type: "Point", coordinates: [21.01, 52.22] }
+func ParseGeoJSON(value interface{}) (GeoJSON, bool) {
+	var geoType, coordinates interface{}
+	switch v := value.(type) {
+	case primitive.M:
+		geoType, coordinates = v["type"], v["coordinates"]
+	case map[string]interface{}:
+		geoType, coordinates = v["type"], v["coordinates"]
+	case primitive.D:
+		m := v.Map()
+		geoType, coordinates = m["type"], m["coordinates"]
+	default:
+		return GeoJSON{}, false
+	}
+
+	switch geoType {
+	case GeoPoint:
+		point, ok := geoPosition(coordinates)
+		if !ok {
+			return GeoJSON{}, false
+		}
+		return GeoJSON{Type: GeoPoint, Points: [][2]float64{point}}, true
+	case GeoPolygon:
+		rings, ok := geoArray(coordinates)
+		if !ok || len(rings) == 0 {
+			return GeoJSON{}, false
+		}
+		ring, ok := geoArray(rings[0])
+		if !ok || len(ring) < 4 {
+			return GeoJSON{}, false
+		}
+		geo := GeoJSON{Type: GeoPolygon, Points: make([][2]float64, 0, len(ring))}
+		for _, position := range ring {
+			point, ok := geoPosition(position)
+			if !ok {
+				return GeoJSON{}, false
+			}
+			geo.Points = append(geo.Points, point)
+		}
+		return geo, true
+	}
+	return GeoJSON{}, false
+}
+
+func geoArray(value interface{}) ([]interface{}, bool) {
+	switch v := value.(type) {
+	case primitive.A:
+		return v, true
+	case []interface{}:
+		return v, true
+	}
+	return nil, false
+}
+
+// geoPosition returns [longitude, latitude] of the position,
+// which must be within bounds of coordinates of the Earth
+func geoPosition(value interface{}) ([2]float64, bool) {
+	position, ok := geoArray(value)
+	if !ok || len(position) < 2 {
+		return [2]float64{}, false
+	}
+	lng, ok := geoNumber(position[0])
+	if !ok || math.Abs(lng) > 180 {
+		return [2]float64{}, false
+	}
+	lat, ok := geoNumber(position[1])
+	if !ok || math.Abs(lat) > 90 {
+		return [2]float64{}, false
+	}
+	return [2]float64{lng, lat}, true
+}
+
+func geoNumber(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	}
+	return 0, false
+}
+
+// Center returns longitude and latitude of the point, or the average
+// of vertices of the polygon, without the closing one
+func (g GeoJSON) Center() (float64, float64) {
+	vertices := g.Points
+	if g.Type == GeoPolygon && len(vertices) > 1 {
+		vertices = vertices[:len(vertices)-1]
+	}
+	var lng, lat float64
+	for _, point := range vertices {
+		lng += point[0]
+		lat += point[1]
+	}
+	count := float64(max(len(vertices), 1))
+	return lng / count, lat / count
+}
+
+func (g GeoJSON) String() string {
+	lng, lat := g.Center()
+	if g.Type == GeoPoint {
+		return fmt.Sprintf("Point %s", formatLatLng(lat, lng))
+	}
+	return fmt.Sprintf("Polygon of %d points around %s", len(g.Points)-1, formatLatLng(lat, lng))
+}
+
+// formatLatLng returns coordinates with hemispheres, e.g. 52.22970°N 21.01220°E
+func formatLatLng(lat, lng float64) string {
+	ns, ew := "N", "E"
+	if lat < 0 {
+		ns = "S"
+	}
+	if lng < 0 {
+		ew = "W"
+	}
+	return fmt.Sprintf("%.5f°%s %.5f°%s", math.Abs(lat), ns, math.Abs(lng), ew)
+}
+
+// MapLink returns the OpenStreetMap link with the marker in the center,
+// polygons are zoomed out so all their points are shown
+func (g GeoJSON) MapLink() string {
+	lng, lat := g.Center()
+	zoom := maxMapZoom
+	if g.Type == GeoPolygon {
+		minLng, minLat, maxLng, maxLat := 180.0, 90.0, -180.0, -90.0
+		for _, point := range g.Points {
+			minLng, maxLng = min(minLng, point[0]), max(maxLng, point[0])
+			minLat, maxLat = min(minLat, point[1]), max(maxLat, point[1])
+		}
+		if span := max(maxLng-minLng, maxLat-minLat); span > 0 {
+			zoom = max(1, min(maxMapZoom, int(math.Log2(360/span))))
+		}
+	}
+	return fmt.Sprintf("https://www.openstreetmap.org/?mlat=%.6f&mlon=%.6f#map=%d/%.6f/%.6f", lat, lng, zoom, lat, lng)
+}
+
+// FindGeoJSON returns the first GeoJSON field of the document, in order of
+// field names, embedded documents are searched as well. Skip tells which
+// fields aren't searched, e.g. the masked ones.
+func FindGeoJSON(doc primitive.M, skip func(field string) bool) (string, GeoJSON, bool) {
+	var find func(prefix string, doc map[string]interface{}) (string, GeoJSON, bool)
+	find = func(prefix string, doc map[string]interface{}) (string, GeoJSON, bool) {
+		keys := make([]string, 0, len(doc))
+		for key := range doc {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			field := prefix + key
+			if skip != nil && skip(field) {
+				continue
+			}
+			if geo, ok := ParseGeoJSON(doc[key]); ok {
+				return field, geo, true
+			}
+			if nested, ok := doc[key].(primitive.M); ok {
+				if field, geo, ok := find(field+".", nested); ok {
+					return field, geo, true
+				}
+			}
+		}
+		return "", GeoJSON{}, false
+	}
+	return find("", doc)
+}
+
+// GeoFields returns sorted paths of GeoJSON fields of the documents
+func GeoFields(documents []primitive.M) []string {
+	seen := make(map[string]bool)
+	var walk func(prefix string, doc map[string]interface{})
+	walk = func(prefix string, doc map[string]interface{}) {
+		for key, value := range doc {
+			if _, ok := ParseGeoJSON(value); ok {
+				seen[prefix+key] = true
+			} else if nested, ok := value.(primitive.M); ok {
+				walk(prefix+key+".", nested)
+			}
+		}
+	}
+	for _, doc := range documents {
+		walk("", doc)
+	}
+
+	fields := make([]string, 0, len(seen))
+	for field := range seen {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// GeoQuery finds documents which GeoJSON Field is within Radius meters
+// from the point, with $near documents are sorted from the nearest,
+// which needs the 2dsphere index, $geoWithin works without an index
+type GeoQuery struct {
+	Field     string
+	Longitude float64
+	Latitude  float64
+	Radius    float64
+	Near      bool
+}
+
+// Filter returns the query as it's typed in the query bar
+func (q GeoQuery) Filter() (string, error) {
+	switch {
+	case q.Field == "":
+		return "", fmt.Errorf("field is required")
+	case math.Abs(q.Longitude) > 180:
+		return "", fmt.Errorf("longitude must be between -180 and 180")
+	case math.Abs(q.Latitude) > 90:
+		return "", fmt.Errorf("latitude must be between -90 and 90")
+	case q.Radius <= 0:
+		return "", fmt.Errorf("radius must be greater than 0")
+	}
+
+	lng := strconv.FormatFloat(q.Longitude, 'f', -1, 64)
+	lat := strconv.FormatFloat(q.Latitude, 'f', -1, 64)
+	if q.Near {
+		return fmt.Sprintf(`{ %q: { "$near": { "$geometry": { "type": "Point", "coordinates": [%s, %s] }, "$maxDistance": %s } } }`,
+			q.Field, lng, lat, strconv.FormatFloat(q.Radius, 'f', -1, 64)), nil
+	}
+	// radius of $centerSphere is in radians
+	radians := strconv.FormatFloat(q.Radius/earthRadius, 'g', -1, 64)
+	return fmt.Sprintf(`{ %q: { "$geoWithin": { "$centerSphere": [[%s, %s], %s] } } }`, q.Field, lng, lat, radians), nil
+}
+
+// GeoIndexed reports whether any of the key patterns of indexes
+// has the 2dsphere key on the field, compound indexes included
+func GeoIndexed(keys []bson.D, field string) bool {
+	for _, key := range keys {
+		for _, element := range key {
+			if element.Key == field && element.Value == geoIndexType {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// HasGeoIndex reports whether the field of the collection has the 2dsphere
+// index, without it $near fails and $geoWithin scans the whole collection
+func (d *Dao) HasGeoIndex(ctx context.Context, db, coll, field string) (bool, error) {
+	cursor, err := d.client.Database(db).Collection(coll).Indexes().List(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer cursor.Close(ctx)
+
+	var indexes []struct {
+		Key bson.D `bson:"key"`
+	}
+	if err := cursor.All(ctx, &indexes); err != nil {
+		return false, err
+	}
+	keys := make([]bson.D, 0, len(indexes))
+	for _, index := range indexes {
+		keys = append(keys, index.Key)
+	}
+	return GeoIndexed(keys, field), nil
+}
+
+// CreateGeoIndex creates the 2dsphere index on the field
+// and returns its name
+func (d *Dao) CreateGeoIndex(ctx context.Context, db, coll, field string) (string, error) {
+	if err := d.checkWritable(); err != nil {
+		return "", err
+	}
+	model := mongo.IndexModel{Keys: bson.D{{Key: field, Value: geoIndexType}}}
+	return d.client.Database(db).Collection(coll).Indexes().CreateOne(ctx, model)
+}
@@ -0,0 +1,106 @@
+package mongo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestParseGeoJSON(t *testing.T) {
+	point, ok := ParseGeoJSON(primitive.M{"type": "Point", "coordinates": primitive.A{21.0122, 52.2297}})
+	require.True(t, ok)
+	assert.Equal(t, "Point 52.22970°N 21.01220°E", point.String())
+	assert.Equal(t, "https://www.openstreetmap.org/?mlat=52.229700&mlon=21.012200#map=16/52.229700/21.012200", point.MapLink())
+
+	polygon, ok := ParseGeoJSON(primitive.D{
+		{Key: "type", Value: "Polygon"},
+		{Key: "coordinates", Value: primitive.A{primitive.A{
+			primitive.A{int32(-1), int32(-1)}, primitive.A{int32(1), int32(-1)},
+			primitive.A{int32(1), int32(1)}, primitive.A{int32(-1), int32(1)},
+			primitive.A{int32(-1), int32(-1)},
+		}}},
+	})
+	require.True(t, ok)
+	lng, lat := polygon.Center()
+	assert.Equal(t, 0.0, lng)
+	assert.Equal(t, 0.0, lat)
+	assert.Equal(t, "Polygon of 4 points around 0.00000°N 0.00000°E", polygon.String())
+	assert.Contains(t, polygon.MapLink(), "#map=7/")
+
+	for _, value := range []interface{}{
+		"Point",
+		primitive.M{"type": "Point"},
+		primitive.M{"type": "Point", "coordinates": primitive.A{200.0, 10.0}},
+		primitive.M{"type": "LineString", "coordinates": primitive.A{primitive.A{1.0, 1.0}, primitive.A{2.0, 2.0}}},
+		primitive.M{"type": "Polygon", "coordinates": primitive.A{primitive.A{primitive.A{1.0, 1.0}}}},
+	} {
+		_, ok := ParseGeoJSON(value)
+		assert.False(t, ok, value)
+	}
+}
+
+func TestFindGeoJSON(t *testing.T) {
+	doc := primitive.M{
+		"name":    "Warsaw",
+		"address": primitive.M{"location": primitive.M{"type": "Point", "coordinates": primitive.A{21.0, 52.0}}},
+		"secret":  primitive.M{"type": "Point", "coordinates": primitive.A{1.0, 1.0}},
+	}
+	field, geo, ok := FindGeoJSON(doc, nil)
+	require.True(t, ok)
+	assert.Equal(t, "address.location", field)
+	assert.Equal(t, [2]float64{21.0, 52.0}, geo.Points[0])
+
+	_, _, ok = FindGeoJSON(doc, func(field string) bool { return field != "name" })
+	assert.False(t, ok)
+
+	other := primitive.M{"area": primitive.M{"type": "Polygon", "coordinates": primitive.A{primitive.A{
+		primitive.A{0.0, 0.0}, primitive.A{1.0, 0.0}, primitive.A{1.0, 1.0}, primitive.A{0.0, 0.0},
+	}}}}
+	assert.Equal(t, []string{"address.location", "area", "secret"}, GeoFields([]primitive.M{doc, other}))
+}
+
+func TestGeoQueryFilter(t *testing.T) {
+	query := GeoQuery{Field: "location", Longitude: 21.0122, Latitude: 52.2297, Radius: 500, Near: true}
+	filter, err := query.Filter()
+	require.NoError(t, err)
+	assert.Equal(t, `{ "location": { "$near": { "$geometry": { "type": "Point", "coordinates": [21.0122, 52.2297] }, "$maxDistance": 500 } } }`, filter)
+	_, err = ParseStringQuery(filter)
+	assert.NoError(t, err)
+
+	query.Near = false
+	query.Radius = 6378100
+	filter, err = query.Filter()
+	require.NoError(t, err)
+	assert.Equal(t, `{ "location": { "$geoWithin": { "$centerSphere": [[21.0122, 52.2297], 1] } } }`, filter)
+
+	query.Radius = 1
+	filter, err = query.Filter()
+	require.NoError(t, err)
+	_, err = ParseStringQuery(filter)
+	assert.NoError(t, err)
+
+	for _, invalid := range []GeoQuery{
+		{Longitude: 1, Latitude: 1, Radius: 1},
+		{Field: "location", Longitude: 181, Radius: 1},
+		{Field: "location", Latitude: -91, Radius: 1},
+		{Field: "location"},
+	} {
+		_, err := invalid.Filter()
+		assert.Error(t, err)
+	}
+}
+
+func TestGeoIndexed(t *testing.T) {
+	keys := []bson.D{
+		{{Key: "_id", Value: int32(1)}},
+		{{Key: "city", Value: int32(1)}, {Key: "address.location", Value: "2dsphere"}},
+		{{Key: "area", Value: "2d"}},
+	}
+	assert.True(t, GeoIndexed(keys, "address.location"))
+	assert.False(t, GeoIndexed(keys, "city"))
+	assert.False(t, GeoIndexed(keys, "area"))
+	assert.False(t, GeoIndexed(nil, "location"))
+}
@@ -0,0 +1,67 @@
+package mongo
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// FieldCount is the number of documents with the value of the field,
+// Value is nil for documents where the field is null or missing
+type FieldCount struct {
+	Value interface{}
+	Count int64
+}
+
+// CountByField returns the most common values of the field in documents
+// matching the filter, most common first, at most limit of them
+func (d *Dao) CountByField(ctx context.Context, db, collection string, filter primitive.M, field string, limit int64) ([]FieldCount, error) {
+	if field == "" || strings.HasPrefix(field, "$") {
+		return nil, fmt.Errorf("invalid field %q", field)
+	}
+	if filter == nil {
+		filter = primitive.M{}
+	}
+
+	pipeline := primitive.A{
+		primitive.M{"$match": filter},
+		primitive.M{"$sortByCount": "$" + field},
+		primitive.M{"$limit": limit},
+	}
+	cursor, err := d.readCollection(db, collection).Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var results []struct {
+		ID    interface{} `bson:"_id"`
+		Count int64       `bson:"count"`
+	}
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+
+	counts := make([]FieldCount, 0, len(results))
+	for _, result := range results {
+		counts = append(counts, FieldCount{Value: result.ID, Count: result.Count})
+	}
+	return counts, nil
+}
+
+// FieldValueFilter returns the filter matching documents where the field
+// equals the value, combined with the current filter if it's set
+func FieldValueFilter(current, field string, value interface{}) (string, error) {
+	jsoned, err := bson.MarshalExtJSON(primitive.D{{Key: field, Value: value}}, false, false)
+	if err != nil {
+		return "", err
+	}
+	current = strings.TrimSpace(current)
+	if current == "" || current == "{}" {
+		return string(jsoned), nil
+	}
+	return fmt.Sprintf(`{ "$and": [%s, %s] }`, current, jsoned), nil
+}
@@ -0,0 +1,29 @@
+package mongo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestFieldValueFilter(t *testing.T) {
+	filter, err := FieldValueFilter("", "status", "failed")
+	require.NoError(t, err)
+	assert.Equal(t, `{"status":"failed"}`, filter)
+
+	id := primitive.NewObjectID()
+	filter, err = FieldValueFilter(`{ age: { $gt: 18 } }`, "owner", id)
+	require.NoError(t, err)
+	parsed, err := ParseStringQuery(filter)
+	require.NoError(t, err)
+	and := parsed["$and"].(primitive.A)
+	require.Len(t, and, 2)
+	assert.Equal(t, primitive.M{"owner": id}, and[1])
+
+	// null matches documents without the field as well
+	filter, err = FieldValueFilter("{}", "address.city", nil)
+	require.NoError(t, err)
+	assert.Equal(t, `{"address.city":null}`, filter)
+}
@@ -0,0 +1,49 @@
+package mongo
+
+import "fmt"
+
+const (
+	RolePrimary    = "primary"
+	RoleSecondary  = "secondary"
+	RoleArbiter    = "arbiter"
+	RoleStandalone = "standalone"
+	RoleOther      = "other"
+)
+
+// Role returns the role of the node in the replica set,
+// or standalone if it's not a member of any
+func (s *ServerStatus) Role() string {
+	switch {
+	case s.Repl.SetName == "":
+		return RoleStandalone
+	case s.Repl.IsMaster:
+		return RolePrimary
+	case s.Repl.Secondary:
+		return RoleSecondary
+	case s.Repl.ArbiterOnly:
+		return RoleArbiter
+	default:
+		return RoleOther
+	}
+}
+
+// Writable reports whether the node accepts writes
+func (s *ServerStatus) Writable() bool {
+	return s.Repl.IsMaster && !s.Repl.ReadOnly
+}
+
+// HealthWarning returns a warning if the node accepted writes when
+// the session started but doesn't anymore, e.g. primary stepped down
+// or was made read-only. Empty string is returned otherwise.
+func HealthWarning(sessionStart, current *ServerStatus) string {
+	if sessionStart == nil || current == nil || !sessionStart.Writable() {
+		return ""
+	}
+	switch {
+	case !current.Repl.IsMaster:
+		return fmt.Sprintf("Node stepped down to %s", current.Role())
+	case current.Repl.ReadOnly:
+		return "Node became read-only"
+	}
+	return ""
+}
@@ -0,0 +1,53 @@
+package mongo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func replStatus(setName string, isMaster, secondary, readOnly bool) *ServerStatus {
+	status := &ServerStatus{}
+	status.Repl.SetName = setName
+	status.Repl.IsMaster = isMaster
+	status.Repl.Secondary = secondary
+	status.Repl.ReadOnly = readOnly
+	return status
+}
+
+func TestServerStatusRole(t *testing.T) {
+	assert.Equal(t, RoleStandalone, replStatus("", true, false, false).Role())
+	assert.Equal(t, RolePrimary, replStatus("rs0", true, false, false).Role())
+	assert.Equal(t, RoleSecondary, replStatus("rs0", false, true, false).Role())
+
+	arbiter := replStatus("rs0", false, false, false)
+	arbiter.Repl.ArbiterOnly = true
+	assert.Equal(t, RoleArbiter, arbiter.Role())
+	assert.Equal(t, RoleOther, replStatus("rs0", false, false, false).Role())
+}
+
+func TestHealthWarning(t *testing.T) {
+	primary := replStatus("rs0", true, false, false)
+	secondary := replStatus("rs0", false, true, false)
+	readOnly := replStatus("rs0", true, false, true)
+
+	tests := []struct {
+		name     string
+		start    *ServerStatus
+		current  *ServerStatus
+		expected string
+	}{
+		{"no status", nil, primary, ""},
+		{"still primary", primary, primary, ""},
+		{"stepped down", primary, secondary, "Node stepped down to secondary"},
+		{"read-only", primary, readOnly, "Node became read-only"},
+		{"secondary from the start", secondary, secondary, ""},
+		{"standalone", replStatus("", true, false, false), replStatus("", true, false, false), ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, HealthWarning(tt.start, tt.current))
+		})
+	}
+}
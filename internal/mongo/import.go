@@ -0,0 +1,347 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ImportResult is the number of documents inserted and updated by the import,
+// Rejected are documents the server refused to insert, if the import goes on
+// after errors, positions are numbers of documents counted from 1
+type ImportResult struct {
+	Inserted int
+	Updated  int
+	Rejected []RejectedDocument
+}
+
+// defaultImportBatchSize is the number of documents written at once
+// when the batch size is not set
+const defaultImportBatchSize = 1000
+
+// ImportOptions control how documents are written. Documents are upserted
+// by UpsertKey if it's set, and written in batches of BatchSize, at most
+// WritesPerSecond documents per second if it's greater than 0. First Skip
+// documents are already written, when the interrupted import is resumed.
+// Inserted documents rejected by the server, e.g. with duplicated _id,
+// don't stop the import if ContinueOnError is set.
+type ImportOptions struct {
+	UpsertKey       string
+	BatchSize       int
+	WritesPerSecond int
+	Skip            int
+	ContinueOnError bool
+}
+
+// ImportProgress is the number of documents written so far,
+// Skipped of them were written before the import was resumed
+type ImportProgress struct {
+	Done    int
+	Skipped int
+	Total   int
+	Elapsed time.Duration
+}
+
+// ETA estimates time left from the pace of writes so far
+func (p ImportProgress) ETA() time.Duration {
+	written := p.Done - p.Skipped
+	if written <= 0 || p.Done >= p.Total {
+		return 0
+	}
+	return p.Elapsed * time.Duration(p.Total-p.Done) / time.Duration(written)
+}
+
+func (p ImportProgress) String() string {
+	percent := 100
+	if p.Total > 0 {
+		percent = p.Done * 100 / p.Total
+	}
+	progress := fmt.Sprintf("%d/%d documents (%d%%)", p.Done, p.Total, percent)
+	if p.Done <= p.Skipped || p.Done >= p.Total {
+		return progress
+	}
+	return fmt.Sprintf("%s, ETA %s", progress, p.ETA().Round(time.Second))
+}
+
+// ImportDocuments writes documents in batches, waiting between them to keep
+// within the limit of writes per second. Progress is reported after every
+// batch. The import stops at the first error or when ctx is canceled, then
+// progress includes documents of the batch written before the error.
+func (d *Dao) ImportDocuments(ctx context.Context, db, collection string, documents []primitive.M, opts ImportOptions, onProgress func(ImportProgress)) (ImportResult, error) {
+	if opts.UpsertKey != "" {
+		// all documents are checked before anything is written
+		if _, err := UpsertModels(documents, opts.UpsertKey); err != nil {
+			return ImportResult{}, err
+		}
+	}
+
+	batchSize := opts.BatchSize
+	if batchSize <= 0 {
+		batchSize = defaultImportBatchSize
+	}
+	if opts.WritesPerSecond > 0 {
+		batchSize = min(batchSize, opts.WritesPerSecond)
+	}
+
+	var result ImportResult
+	start := time.Now()
+	skip := min(max(opts.Skip, 0), len(documents))
+	progress := func(done int) {
+		if onProgress != nil {
+			onProgress(ImportProgress{Done: done, Skipped: skip, Total: len(documents), Elapsed: time.Since(start)})
+		}
+	}
+	for done := skip; done < len(documents); {
+		batch := documents[done:min(done+batchSize, len(documents))]
+		var written ImportResult
+		var err error
+		switch {
+		case opts.UpsertKey != "":
+			written, err = d.UpsertDocuments(ctx, db, collection, batch, opts.UpsertKey)
+		case opts.ContinueOnError:
+			written, err = d.insertUnordered(ctx, db, collection, batch)
+		default:
+			written, err = d.InsertDocuments(ctx, db, collection, batch)
+		}
+		result.Inserted += written.Inserted
+		result.Updated += written.Updated
+		for _, rejected := range written.Rejected {
+			rejected.Position += done
+			result.Rejected = append(result.Rejected, rejected)
+		}
+		if err != nil {
+			if partial := written.Inserted + written.Updated; partial > 0 {
+				progress(done + partial)
+			}
+			return result, err
+		}
+
+		done += len(batch)
+		progress(done)
+		if done == len(documents) {
+			break
+		}
+		if delay := throttleDelay(done-skip, opts.WritesPerSecond, time.Since(start)); delay > 0 {
+			select {
+			case <-ctx.Done():
+				return result, ctx.Err()
+			case <-time.After(delay):
+			}
+		}
+	}
+	return result, nil
+}
+
+// throttleDelay returns how long to wait, so the number of documents
+// written isn't greater than perSecond for every second since the start
+func throttleDelay(written, perSecond int, elapsed time.Duration) time.Duration {
+	if perSecond <= 0 {
+		return 0
+	}
+	expected := time.Duration(written) * time.Second / time.Duration(perSecond)
+	return max(expected-elapsed, 0)
+}
+
+// InsertDocuments inserts documents into the collection, returns the
+// number of inserted documents, which is less than all on error. Documents
+// are inserted in order, so the ones before the first failed are inserted.
+func (d *Dao) InsertDocuments(ctx context.Context, db, collection string, documents []primitive.M) (ImportResult, error) {
+	if err := d.checkWritable(); err != nil {
+		return ImportResult{}, err
+	}
+	if len(documents) == 0 {
+		return ImportResult{}, nil
+	}
+
+	docs := make([]interface{}, len(documents))
+	for i, doc := range documents {
+		docs[i] = doc
+	}
+	res, err := d.client.Database(db).Collection(collection).InsertMany(ctx, docs)
+	if err != nil {
+		// ids of all documents are returned, even of those not inserted
+		var bulkErr mongo.BulkWriteException
+		if errors.As(err, &bulkErr) && len(bulkErr.WriteErrors) > 0 {
+			return ImportResult{Inserted: bulkErr.WriteErrors[0].Index}, err
+		}
+		return ImportResult{}, err
+	}
+	return ImportResult{Inserted: len(res.InsertedIDs)}, nil
+}
+
+// insertUnordered inserts documents, those rejected by the server don't
+// stop the insert of others, they're returned with positions in documents
+func (d *Dao) insertUnordered(ctx context.Context, db, collection string, documents []primitive.M) (ImportResult, error) {
+	if err := d.checkWritable(); err != nil {
+		return ImportResult{}, err
+	}
+	if len(documents) == 0 {
+		return ImportResult{}, nil
+	}
+
+	docs := make([]interface{}, len(documents))
+	for i, doc := range documents {
+		docs[i] = doc
+	}
+	_, err := d.client.Database(db).Collection(collection).InsertMany(ctx, docs, options.InsertMany().SetOrdered(false))
+	if err == nil {
+		return ImportResult{Inserted: len(documents)}, nil
+	}
+	var bulkErr mongo.BulkWriteException
+	if !errors.As(err, &bulkErr) || bulkErr.WriteConcernError != nil || len(bulkErr.WriteErrors) == 0 {
+		return ImportResult{}, err
+	}
+	return ImportResult{
+		Inserted: len(documents) - len(bulkErr.WriteErrors),
+		Rejected: rejectedDocuments(bulkErr.WriteErrors),
+	}, nil
+}
+
+// rejectedDocuments returns documents of write errors with their reasons
+func rejectedDocuments(writeErrors []mongo.BulkWriteError) []RejectedDocument {
+	rejected := make([]RejectedDocument, len(writeErrors))
+	for i, writeErr := range writeErrors {
+		rejected[i] = RejectedDocument{Position: writeErr.Index + 1, Reason: writeErr.Message}
+	}
+	return rejected
+}
+
+// UpsertDocuments replaces documents of the collection with the same value
+// of the key field, documents which don't exist yet are inserted
+func (d *Dao) UpsertDocuments(ctx context.Context, db, collection string, documents []primitive.M, key string) (ImportResult, error) {
+	if err := d.checkWritable(); err != nil {
+		return ImportResult{}, err
+	}
+	models, err := UpsertModels(documents, key)
+	if err != nil || len(models) == 0 {
+		return ImportResult{}, err
+	}
+
+	res, err := d.client.Database(db).Collection(collection).BulkWrite(ctx, models)
+	if res == nil {
+		return ImportResult{}, err
+	}
+	return ImportResult{Inserted: int(res.UpsertedCount), Updated: int(res.MatchedCount)}, err
+}
+
+// UpsertModels returns replacements of documents matched by the key
+// field, which can be a dotted path. All documents must have the key.
+func UpsertModels(documents []primitive.M, key string) ([]mongo.WriteModel, error) {
+	if key == "" {
+		return nil, fmt.Errorf("upsert key is not set")
+	}
+	seen := make(map[string]bool, len(documents))
+	models := make([]mongo.WriteModel, 0, len(documents))
+	for i, doc := range documents {
+		value, ok := exportPath(doc, key)
+		if !ok {
+			return nil, fmt.Errorf("document %d has no %s field", i+1, key)
+		}
+		// documents with the same key would replace each other
+		id := fmt.Sprintf("%T:%v", value, value)
+		if seen[id] {
+			return nil, fmt.Errorf("document %d has duplicated %s: %v", i+1, key, value)
+		}
+		seen[id] = true
+
+		models = append(models, mongo.NewReplaceOneModel().
+			SetFilter(primitive.M{key: value}).
+			SetReplacement(doc).
+			SetUpsert(true))
+	}
+	return models, nil
+}
+
+// dryRunSamples is the number of existing documents returned by the dry run
+const dryRunSamples = 5
+
+// dryRunBatch is the number of keys looked up by a single query
+const dryRunBatch = 1000
+
+// DryRunReport is what the import would do, without writing anything.
+// Conflicts are inserted documents with _id that already exists, Samples
+// are existing documents which would be replaced or conflict.
+type DryRunReport struct {
+	Inserted  int
+	Updated   int
+	Conflicts int
+	Samples   []primitive.M
+}
+
+func (r DryRunReport) String() string {
+	summary := fmt.Sprintf("%d would be inserted, %d updated", r.Inserted, r.Updated)
+	if r.Conflicts > 0 {
+		summary += fmt.Sprintf(", %d already exist and would fail", r.Conflicts)
+	}
+	return summary
+}
+
+// DryRunImport reports what importing documents would do, documents are
+// matched by the upsert key, or by _id when they are inserted
+func (d *Dao) DryRunImport(ctx context.Context, db, collection string, documents []primitive.M, upsertKey string) (DryRunReport, error) {
+	key := upsertKey
+	if key == "" {
+		key = "_id"
+	} else if _, err := UpsertModels(documents, key); err != nil {
+		return DryRunReport{}, err
+	}
+
+	existing := 0
+	var samples []primitive.M
+	coll := d.readCollection(db, collection)
+	values := importKeyValues(documents, key)
+	for start := 0; start < len(values); start += dryRunBatch {
+		batch := values[start:min(start+dryRunBatch, len(values))]
+		filter := primitive.M{key: primitive.M{"$in": batch}}
+		matched, err := coll.Distinct(ctx, key, filter)
+		if err != nil {
+			return DryRunReport{}, err
+		}
+		existing += len(matched)
+
+		if len(samples) < dryRunSamples {
+			opts := options.Find().SetLimit(int64(dryRunSamples - len(samples)))
+			cursor, err := coll.Find(ctx, filter, opts)
+			if err != nil {
+				return DryRunReport{}, err
+			}
+			var found []primitive.M
+			if err := cursor.All(ctx, &found); err != nil {
+				return DryRunReport{}, err
+			}
+			samples = append(samples, found...)
+		}
+	}
+
+	report := DryRunReport{Inserted: len(documents) - existing, Samples: samples}
+	if upsertKey != "" {
+		report.Updated = existing
+	} else {
+		report.Conflicts = existing
+	}
+	return report, nil
+}
+
+// importKeyValues returns distinct values of the key of documents,
+// documents without the key are skipped
+func importKeyValues(documents []primitive.M, key string) primitive.A {
+	seen := make(map[string]bool, len(documents))
+	values := make(primitive.A, 0, len(documents))
+	for _, doc := range documents {
+		value, ok := exportPath(doc, key)
+		if !ok {
+			continue
+		}
+		id := fmt.Sprintf("%T:%v", value, value)
+		if !seen[id] {
+			seen[id] = true
+			values = append(values, value)
+		}
+	}
+	return values
+}
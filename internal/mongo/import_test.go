@@ -0,0 +1,91 @@
+package mongo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestUpsertModels(t *testing.T) {
+	documents := []primitive.M{
+		{"code": "PL", "name": "Poland"},
+		{"code": "DE", "name": "Germany", "meta": primitive.M{"iso": int32(276)}},
+	}
+
+	models, err := UpsertModels(documents, "code")
+	require.NoError(t, err)
+	require.Len(t, models, 2)
+
+	replace, ok := models[1].(*mongo.ReplaceOneModel)
+	require.True(t, ok)
+	assert.Equal(t, primitive.M{"code": "DE"}, replace.Filter)
+	assert.Equal(t, documents[1], replace.Replacement)
+	assert.True(t, *replace.Upsert)
+
+	models, err = UpsertModels(documents[1:], "meta.iso")
+	require.NoError(t, err)
+	assert.Equal(t, primitive.M{"meta.iso": int32(276)}, models[0].(*mongo.ReplaceOneModel).Filter)
+
+	_, err = UpsertModels(documents, "meta.iso")
+	assert.EqualError(t, err, "document 1 has no meta.iso field")
+
+	_, err = UpsertModels(append(documents, primitive.M{"code": "PL"}), "code")
+	assert.EqualError(t, err, "document 3 has duplicated code: PL")
+
+	_, err = UpsertModels(documents, "")
+	assert.Error(t, err)
+}
+
+func TestImportKeyValues(t *testing.T) {
+	documents := []primitive.M{
+		{"_id": int32(1)},
+		{"_id": int64(1)},
+		{"_id": int32(1)},
+		{"name": "no id"},
+	}
+	assert.Equal(t, primitive.A{int32(1), int64(1)}, importKeyValues(documents, "_id"))
+}
+
+func TestDryRunReportString(t *testing.T) {
+	assert.Equal(t, "3 would be inserted, 2 updated", DryRunReport{Inserted: 3, Updated: 2}.String())
+	assert.Equal(t, "1 would be inserted, 0 updated, 2 already exist and would fail",
+		DryRunReport{Inserted: 1, Conflicts: 2}.String())
+}
+
+func TestThrottleDelay(t *testing.T) {
+	assert.Equal(t, time.Duration(0), throttleDelay(5000, 0, 0))
+	assert.Equal(t, 2*time.Second, throttleDelay(300, 100, time.Second))
+	assert.Equal(t, time.Duration(0), throttleDelay(100, 100, 2*time.Second))
+	assert.Equal(t, 500*time.Millisecond, throttleDelay(50, 50, 500*time.Millisecond))
+}
+
+func TestImportProgress(t *testing.T) {
+	progress := ImportProgress{Done: 250, Total: 1000, Elapsed: 10 * time.Second}
+	assert.Equal(t, 30*time.Second, progress.ETA())
+	assert.Equal(t, "250/1000 documents (25%), ETA 30s", progress.String())
+
+	progress.Done = 1000
+	assert.Equal(t, time.Duration(0), progress.ETA())
+	assert.Equal(t, "1000/1000 documents (100%)", progress.String())
+
+	assert.Equal(t, "0/0 documents (100%)", ImportProgress{}.String())
+
+	resumed := ImportProgress{Done: 600, Skipped: 500, Total: 1000, Elapsed: 10 * time.Second}
+	assert.Equal(t, 40*time.Second, resumed.ETA())
+	assert.Equal(t, "500/1000 documents (50%)", ImportProgress{Done: 500, Skipped: 500, Total: 1000}.String())
+}
+
+func TestRejectedDocuments(t *testing.T) {
+	writeErrors := []mongo.BulkWriteError{
+		{WriteError: mongo.WriteError{Index: 0, Code: 11000, Message: "E11000 duplicate key error"}},
+		{WriteError: mongo.WriteError{Index: 4, Code: 121, Message: "Document failed validation"}},
+	}
+	assert.Equal(t, []RejectedDocument{
+		{Position: 1, Reason: "E11000 duplicate key error"},
+		{Position: 5, Reason: "Document failed validation"},
+	}, rejectedDocuments(writeErrors))
+}
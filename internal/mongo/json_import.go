@@ -0,0 +1,155 @@
+package mongo
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// maxNDJSONLine is the size of the longest line of NDJSON files,
+// it's the maximum size of the BSON document
+const maxNDJSONLine = 16 * 1024 * 1024
+
+// RejectedDocument is the document which can't be imported, Position
+// is its line in NDJSON, or its number in the JSON array, or in
+// imported documents when it's rejected by the server
+type RejectedDocument struct {
+	Position int
+	Reason   string
+}
+
+func (r RejectedDocument) String() string {
+	return fmt.Sprintf("%d: %s", r.Position, r.Reason)
+}
+
+// JSONFile is the parsed JSON array or NDJSON file of documents in
+// extended JSON, documents which aren't valid are rejected, the rest
+// can still be imported
+type JSONFile struct {
+	Documents []primitive.M
+	Rejected  []RejectedDocument
+	// NDJSON is true if every line of the file is a document
+	NDJSON bool
+}
+
+// ReadJSON reads the file with the JSON array of documents or with
+// a document on every line. The JSON array which isn't valid as
+// a whole can't be read, while invalid lines of NDJSON are rejected.
+func ReadJSON(r io.Reader) (*JSONFile, error) {
+	reader := bufio.NewReader(r)
+	first, lines, err := firstNonSpace(reader)
+	if err == io.EOF {
+		return &JSONFile{NDJSON: true}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if first == '[' {
+		return readJSONArray(reader)
+	}
+	return readNDJSON(reader, lines+1)
+}
+
+// firstNonSpace returns the first byte which is not a white space, without
+// reading it, and the number of skipped lines. The byte order mark is skipped.
+func firstNonSpace(reader *bufio.Reader) (byte, int, error) {
+	if bom, err := reader.Peek(3); err == nil && bytes.Equal(bom, []byte{0xEF, 0xBB, 0xBF}) {
+		reader.Discard(3)
+	}
+	lines := 0
+	for {
+		b, err := reader.ReadByte()
+		if err != nil {
+			return 0, lines, err
+		}
+		switch b {
+		case '\n':
+			lines++
+			continue
+		case ' ', '\t', '\r':
+			continue
+		}
+		return b, lines, reader.UnreadByte()
+	}
+}
+
+func readJSONArray(r io.Reader) (*JSONFile, error) {
+	decoder := json.NewDecoder(r)
+	if _, err := decoder.Token(); err != nil {
+		return nil, err
+	}
+	file := &JSONFile{}
+	for position := 1; decoder.More(); position++ {
+		var raw json.RawMessage
+		if err := decoder.Decode(&raw); err != nil {
+			return nil, fmt.Errorf("document %d: %w", position, err)
+		}
+		file.add(position, raw)
+	}
+	if _, err := decoder.Token(); err != nil {
+		return nil, err
+	}
+	return file, nil
+}
+
+func readNDJSON(r io.Reader, firstLine int) (*JSONFile, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxNDJSONLine)
+	file := &JSONFile{NDJSON: true}
+	for line := firstLine; scanner.Scan(); line++ {
+		text := bytes.TrimSpace(scanner.Bytes())
+		if len(text) == 0 {
+			continue
+		}
+		file.add(line, text)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return file, nil
+}
+
+// add parses the document, it's rejected if it's not valid
+func (f *JSONFile) add(position int, raw []byte) {
+	var doc primitive.M
+	err := bson.UnmarshalExtJSON(raw, false, &doc)
+	if err == nil {
+		err = ValidateImportDocument(doc)
+	}
+	if err != nil {
+		f.Rejected = append(f.Rejected, RejectedDocument{Position: position, Reason: err.Error()})
+		return
+	}
+	f.Documents = append(f.Documents, doc)
+}
+
+// ValidateImportDocument returns an error if the server would reject
+// the document, so it's not sent at all
+func ValidateImportDocument(doc primitive.M) error {
+	if doc == nil {
+		return fmt.Errorf("not a document")
+	}
+	for key := range doc {
+		if key == "" {
+			return fmt.Errorf("empty field name")
+		}
+		if strings.HasPrefix(key, "$") {
+			return fmt.Errorf("field name %s starts with $", key)
+		}
+	}
+	switch doc["_id"].(type) {
+	case primitive.A:
+		return fmt.Errorf("_id can't be an array")
+	case primitive.Regex:
+		return fmt.Errorf("_id can't be a regular expression")
+	case primitive.Undefined:
+		return fmt.Errorf("_id can't be undefined")
+	}
+	return nil
+}
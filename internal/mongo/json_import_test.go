@@ -0,0 +1,55 @@
+package mongo
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestReadJSONArray(t *testing.T) {
+	file, err := ReadJSON(strings.NewReader(`
+[
+  {"_id": {"$oid": "65f1c0ffee0000000000abcd"}, "name": "Alice", "created": {"$date": "2024-05-01T10:00:00Z"}},
+  {"_id": [1, 2]},
+  42,
+  {"name": "Bob", "visits": {"$numberLong": "7"}}
+]`))
+	require.NoError(t, err)
+	assert.False(t, file.NDJSON)
+
+	require.Len(t, file.Documents, 2)
+	id, _ := primitive.ObjectIDFromHex("65f1c0ffee0000000000abcd")
+	assert.Equal(t, id, file.Documents[0]["_id"])
+	assert.Equal(t, primitive.NewDateTimeFromTime(time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)), file.Documents[0]["created"])
+	assert.Equal(t, int64(7), file.Documents[1]["visits"])
+
+	require.Len(t, file.Rejected, 2)
+	assert.Equal(t, RejectedDocument{Position: 2, Reason: "_id can't be an array"}, file.Rejected[0])
+	assert.Equal(t, 3, file.Rejected[1].Position)
+
+	_, err = ReadJSON(strings.NewReader(`[{"a": 1}, {"a": `))
+	assert.Error(t, err)
+}
+
+func TestReadNDJSON(t *testing.T) {
+	file, err := ReadJSON(strings.NewReader("\xEF\xBB\xBF{\"a\": 1}\n\n{\"a\": \n{\"$set\": 1}\n{\"a\": 2}\n"))
+	require.NoError(t, err)
+	assert.True(t, file.NDJSON)
+	assert.Equal(t, []primitive.M{{"a": int32(1)}, {"a": int32(2)}}, file.Documents)
+	require.Len(t, file.Rejected, 2)
+	assert.Equal(t, 3, file.Rejected[0].Position)
+	assert.Equal(t, "4: field name $set starts with $", file.Rejected[1].String())
+
+	// lines are counted from the start of the file
+	file, err = ReadJSON(strings.NewReader("\n\n{\"a\": \n"))
+	require.NoError(t, err)
+	assert.Equal(t, 3, file.Rejected[0].Position)
+
+	empty, err := ReadJSON(strings.NewReader("  \n"))
+	require.NoError(t, err)
+	assert.Empty(t, empty.Documents)
+}
@@ -1,5 +1,7 @@
 package mongo
 
+import "strings"
+
 var (
 	objectID = MongoKeyword{
 		Display:     "ObjectID(\"\"))",
@@ -489,6 +491,16 @@ type MongoKeyword struct {
 	Description string
 }
 
+// Skeleton returns text inserted when operator is completed in place
+// of the field value, e.g. "{ $in: [<$0>] }" for "status: $in"
+func (k *MongoKeyword) Skeleton() string {
+	text := k.InsertText
+	if !strings.Contains(text, "<$") {
+		text = strings.TrimSpace(text) + " <$0>"
+	}
+	return "{ " + text + " }"
+}
+
 type MongoAutocomplete struct {
 	Operators []MongoKeyword
 }
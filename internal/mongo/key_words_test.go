@@ -0,0 +1,15 @@
+package mongo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMongoKeywordSkeleton(t *testing.T) {
+	ma := NewMongoAutocomplete()
+
+	assert.Equal(t, "{ $in: [<$0>] }", ma.GetOperatorByDisplay("$in").Skeleton())
+	assert.Equal(t, "{ $gt: <$0> }", ma.GetOperatorByDisplay("$gt").Skeleton())
+	assert.Equal(t, "{ $elemMatch: {<$0>} }", ma.GetOperatorByDisplay("$elemMatch").Skeleton())
+}
@@ -0,0 +1,212 @@
+package mongo
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/kopecmaciej/vi-mongo/internal/util"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/x/bsonx/bsoncore"
+)
+
+// LazyDocument is a document kept as raw BSON, which fields are decoded
+// only when they are read. Table view displays only a few fields of every
+// document, so decoding whole documents would be a waste on large pages.
+type LazyDocument struct {
+	raw bson.Raw
+
+	// mu guards decoded values, as the document may be read
+	// by the table and decoded in the background at the same time
+	mu sync.Mutex
+	// values are fields that were already decoded
+	values map[string]interface{}
+	// doc is the whole decoded document
+	doc primitive.M
+}
+
+// NewLazyDocument returns a document backed by the copy of raw BSON,
+// so it can be used after the cursor moves to the next document
+func NewLazyDocument(raw bson.Raw) *LazyDocument {
+	rawCopy := make(bson.Raw, len(raw))
+	copy(rawCopy, raw)
+
+	return &LazyDocument{
+		raw:    rawCopy,
+		values: make(map[string]interface{}),
+	}
+}
+
+// NewDecodedDocument returns a document which is already decoded,
+// e.g. the one inserted or edited by the user
+func NewDecodedDocument(doc primitive.M) *LazyDocument {
+	return &LazyDocument{
+		values: make(map[string]interface{}),
+		doc:    doc,
+	}
+}
+
+// Get returns value of the top-level field, decoding only this field
+func (d *LazyDocument) Get(key string) (interface{}, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.doc != nil {
+		value, ok := d.doc[key]
+		return value, ok
+	}
+	if value, ok := d.values[key]; ok {
+		return value, true
+	}
+
+	rawValue, err := d.raw.LookupErr(key)
+	if err != nil {
+		return nil, false
+	}
+	// single field is decoded as a document, so nested documents
+	// have the same types as when the whole document is decoded
+	element := bsoncore.AppendValueElement(nil, key, bsoncore.Value{Type: rawValue.Type, Data: rawValue.Value})
+	var field primitive.M
+	if err := bson.Unmarshal(bsoncore.BuildDocumentFromElements(nil, element), &field); err != nil {
+		return nil, false
+	}
+
+	d.values[key] = field[key]
+	return field[key], true
+}
+
+// GetPath returns value of the field with given dotted path,
+// only the top-level field of the path is decoded
+func (d *LazyDocument) GetPath(path string) (interface{}, bool) {
+	if value, ok := d.Get(path); ok || !strings.Contains(path, ".") {
+		return value, ok
+	}
+
+	key, rest, _ := strings.Cut(path, ".")
+	value, ok := d.Get(key)
+	if !ok {
+		return nil, false
+	}
+	nested, ok := value.(primitive.M)
+	if !ok {
+		return nil, false
+	}
+	return lookupPath(nested, rest)
+}
+
+// Types returns types of top-level fields, as returned by util.GetMongoType,
+// values of the fields are not decoded
+func (d *LazyDocument) Types() map[string]string {
+	return d.FlatTypes(0)
+}
+
+// FlatTypes returns types of fields like Types, but fields of embedded
+// documents nested up to depth levels are returned with dotted paths
+// instead of the documents, e.g. "address.city". Empty embedded
+// documents are returned as they are.
+func (d *LazyDocument) FlatTypes(depth int) map[string]string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	types := make(map[string]string)
+	if d.doc != nil {
+		addDecodedTypes(types, "", d.doc, depth)
+		return types
+	}
+	addRawTypes(types, "", d.raw, depth)
+	return types
+}
+
+func addDecodedTypes(types map[string]string, prefix string, doc primitive.M, depth int) {
+	for key, value := range doc {
+		if nested, ok := value.(primitive.M); ok && depth > 0 && len(nested) > 0 {
+			addDecodedTypes(types, prefix+key+".", nested, depth-1)
+			continue
+		}
+		types[prefix+key] = util.GetMongoType(value)
+	}
+}
+
+func addRawTypes(types map[string]string, prefix string, raw bson.Raw, depth int) {
+	elements, err := raw.Elements()
+	if err != nil {
+		return
+	}
+	for _, element := range elements {
+		value := element.Value()
+		if nested, ok := value.DocumentOK(); ok && depth > 0 {
+			if nestedElements, err := nested.Elements(); err == nil && len(nestedElements) > 0 {
+				addRawTypes(types, prefix+element.Key()+".", nested, depth-1)
+				continue
+			}
+		}
+		types[prefix+element.Key()] = bsonTypeName(value)
+	}
+}
+
+// Decode returns the whole decoded document, decoded document is cached,
+// so the copy is returned to be safely modified
+func (d *LazyDocument) Decode() (primitive.M, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.doc == nil {
+		var doc primitive.M
+		if err := bson.Unmarshal(d.raw, &doc); err != nil {
+			return nil, err
+		}
+		d.doc = doc
+		d.values = nil
+	}
+
+	return deepCopy(d.doc), nil
+}
+
+// bsonTypeName returns the name of the type in the same way as
+// util.GetMongoType does for the decoded value
+func bsonTypeName(value bson.RawValue) string {
+	switch value.Type {
+	case bsontype.String:
+		return util.TypeString
+	case bsontype.Int32, bsontype.Int64:
+		return util.TypeInt
+	case bsontype.Double:
+		return util.TypeDouble
+	case bsontype.Boolean:
+		return util.TypeBool
+	case bsontype.ObjectID:
+		return util.TypeObjectId
+	case bsontype.DateTime:
+		return util.TypeDate
+	case bsontype.Binary:
+		if subtype, _, ok := value.BinaryOK(); ok && subtype == EncryptedSubtype {
+			return util.TypeEncrypted
+		}
+		return util.TypeBinary
+	case bsontype.Array:
+		return util.TypeArray
+	case bsontype.EmbeddedDocument:
+		return util.TypeObject
+	default:
+		return util.TypeNull
+	}
+}
+
+// DocumentsTypes returns types of top-level fields of the documents,
+// util.TypeMixed is used if the type differs between documents
+func DocumentsTypes(documents []*LazyDocument) map[string]string {
+	return DocumentsFlatTypes(documents, 0)
+}
+
+// DocumentsFlatTypes returns types of fields of the documents
+// like DocumentsTypes, flattened as in LazyDocument.FlatTypes
+func DocumentsFlatTypes(documents []*LazyDocument, depth int) map[string]string {
+	types := make(map[string]string)
+	for _, doc := range documents {
+		for key, valueType := range doc.FlatTypes(depth) {
+			if current, ok := types[key]; ok && current != valueType {
+				valueType = util.TypeMixed
+			}
+			types[key] = valueType
+		}
+	}
+	return types
+}
@@ -0,0 +1,127 @@
+package mongo
+
+import (
+	"testing"
+
+	"github.com/kopecmaciej/vi-mongo/internal/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestLazyDocument(t *testing.T) {
+	id := primitive.NewObjectID()
+	raw, err := bson.Marshal(primitive.D{
+		{Key: "_id", Value: id},
+		{Key: "name", Value: "John"},
+		{Key: "age", Value: int32(30)},
+		{Key: "address", Value: primitive.D{{Key: "city", Value: "Warsaw"}}},
+	})
+	require.NoError(t, err)
+
+	doc := NewLazyDocument(raw)
+	// raw buffer may be reused by the cursor
+	raw[len(raw)-2] = 0
+
+	name, ok := doc.Get("name")
+	assert.True(t, ok)
+	assert.Equal(t, "John", name)
+
+	address, ok := doc.Get("address")
+	assert.True(t, ok)
+	assert.Equal(t, primitive.M{"city": "Warsaw"}, address)
+
+	_, ok = doc.Get("missing")
+	assert.False(t, ok)
+
+	assert.Equal(t, map[string]string{
+		"_id":     util.TypeObjectId,
+		"name":    util.TypeString,
+		"age":     util.TypeInt,
+		"address": util.TypeObject,
+	}, doc.Types())
+
+	decoded, err := doc.Decode()
+	require.NoError(t, err)
+	assert.Equal(t, id, decoded["_id"])
+	assert.Equal(t, int32(30), decoded["age"])
+
+	// decoded document can be modified without affecting the cached one
+	decoded["name"] = "Jane"
+	name, _ = doc.Get("name")
+	assert.Equal(t, "John", name)
+}
+
+func TestDocumentsTypes(t *testing.T) {
+	first, err := bson.Marshal(primitive.M{"name": "John", "age": int32(30)})
+	require.NoError(t, err)
+
+	docs := []*LazyDocument{
+		NewLazyDocument(first),
+		NewDecodedDocument(primitive.M{"name": "Jane", "age": "unknown"}),
+	}
+
+	assert.Equal(t, map[string]string{
+		"name": util.TypeString,
+		"age":  util.TypeMixed,
+	}, DocumentsTypes(docs))
+}
+
+func TestLazyDocumentFlatTypes(t *testing.T) {
+	doc := primitive.D{
+		{Key: "name", Value: "John"},
+		{Key: "address", Value: primitive.D{
+			{Key: "city", Value: "Warsaw"},
+			{Key: "geo", Value: primitive.D{{Key: "lat", Value: 52.2}}},
+		}},
+		{Key: "meta", Value: primitive.D{}},
+		{Key: "tags", Value: primitive.A{"a"}},
+	}
+	raw, err := bson.Marshal(doc)
+	require.NoError(t, err)
+	decoded := primitive.M{}
+	require.NoError(t, bson.Unmarshal(raw, &decoded))
+
+	for name, lazy := range map[string]*LazyDocument{
+		"raw":     NewLazyDocument(raw),
+		"decoded": NewDecodedDocument(decoded),
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, map[string]string{
+				"name":         util.TypeString,
+				"address.city": util.TypeString,
+				"address.geo":  util.TypeObject,
+				"meta":         util.TypeObject,
+				"tags":         util.TypeArray,
+			}, lazy.FlatTypes(1))
+
+			types := lazy.FlatTypes(2)
+			assert.Equal(t, util.TypeDouble, types["address.geo.lat"])
+			assert.NotContains(t, types, "address.geo")
+
+			assert.Equal(t, lazy.Types(), lazy.FlatTypes(0))
+
+			city, ok := lazy.GetPath("address.city")
+			assert.True(t, ok)
+			assert.Equal(t, "Warsaw", city)
+			lat, ok := lazy.GetPath("address.geo.lat")
+			assert.True(t, ok)
+			assert.Equal(t, 52.2, lat)
+			_, ok = lazy.GetPath("name.first")
+			assert.False(t, ok)
+			_, ok = lazy.GetPath("address.zip")
+			assert.False(t, ok)
+		})
+	}
+}
+
+func TestDocumentsFlatTypes(t *testing.T) {
+	first, err := bson.Marshal(primitive.M{"address": primitive.M{"city": "Warsaw"}})
+	require.NoError(t, err)
+	second, err := bson.Marshal(primitive.M{"address": primitive.M{"city": int32(1)}})
+	require.NoError(t, err)
+
+	types := DocumentsFlatTypes([]*LazyDocument{NewLazyDocument(first), NewLazyDocument(second)}, 1)
+	assert.Equal(t, map[string]string{"address.city": util.TypeMixed}, types)
+}
@@ -0,0 +1,93 @@
+package mongo
+
+import "time"
+
+// LiveStats keeps the newest server statuses polled by the dashboard,
+// counters are turned into rates per second between consecutive statuses
+type LiveStats struct {
+	size    int
+	samples []liveSample
+}
+
+type liveSample struct {
+	time   time.Time
+	status *ServerStatus
+}
+
+// NewLiveStats creates live stats keeping at most size rates of every metric
+func NewLiveStats(size int) *LiveStats {
+	return &LiveStats{size: size}
+}
+
+// Add adds the status polled at given time, the oldest one is dropped
+// when there are more statuses than rates kept
+func (l *LiveStats) Add(status *ServerStatus, at time.Time) {
+	l.samples = append(l.samples, liveSample{time: at, status: status})
+	if len(l.samples) > l.size+1 {
+		l.samples = l.samples[len(l.samples)-l.size-1:]
+	}
+}
+
+// Reset removes all statuses, e.g. when the connection is changed
+func (l *LiveStats) Reset() {
+	l.samples = nil
+}
+
+// Latest returns the newest status or nil if there is none
+func (l *LiveStats) Latest() *ServerStatus {
+	if len(l.samples) == 0 {
+		return nil
+	}
+	return l.samples[len(l.samples)-1].status
+}
+
+// Gauges returns series of values of the server which go up and down,
+// e.g. current connections
+func (l *LiveStats) Gauges() []StatsSeries {
+	gauges := []StatsSeries{
+		{Name: "Connections"},
+		{Name: "Resident memory", Unit: "MB"},
+	}
+	for _, s := range l.samples {
+		gauges[0].Values = append(gauges[0].Values, float64(s.status.Connections.Current))
+		gauges[1].Values = append(gauges[1].Values, float64(s.status.Mem.Resident))
+	}
+	return gauges
+}
+
+// Rates returns series of counters of the server, e.g. inserts or bytes
+// received, as rates per second between consecutive statuses,
+// the rate is 0 when the counter was reset by the restart
+func (l *LiveStats) Rates() []StatsSeries {
+	counters := []struct {
+		name    string
+		unit    string
+		counter func(*ServerStatus) int64
+	}{
+		{"Inserts", "/s", func(s *ServerStatus) int64 { return s.OpCounters.Insert }},
+		{"Queries", "/s", func(s *ServerStatus) int64 { return s.OpCounters.Query }},
+		{"Updates", "/s", func(s *ServerStatus) int64 { return s.OpCounters.Update }},
+		{"Deletes", "/s", func(s *ServerStatus) int64 { return s.OpCounters.Delete }},
+		{"Getmores", "/s", func(s *ServerStatus) int64 { return s.OpCounters.GetMore }},
+		{"Commands", "/s", func(s *ServerStatus) int64 { return s.OpCounters.Command }},
+		{"Network in", "B/s", func(s *ServerStatus) int64 { return s.Network.BytesIn }},
+		{"Network out", "B/s", func(s *ServerStatus) int64 { return s.Network.BytesOut }},
+		{"Requests", "/s", func(s *ServerStatus) int64 { return s.Network.NumRequests }},
+	}
+
+	series := make([]StatsSeries, 0, len(counters))
+	for _, c := range counters {
+		rate := StatsSeries{Name: c.name, Unit: c.unit}
+		for i := 1; i < len(l.samples); i++ {
+			elapsed := l.samples[i].time.Sub(l.samples[i-1].time).Seconds()
+			diff := c.counter(l.samples[i].status) - c.counter(l.samples[i-1].status)
+			if elapsed <= 0 || diff < 0 {
+				rate.Values = append(rate.Values, 0)
+				continue
+			}
+			rate.Values = append(rate.Values, float64(diff)/elapsed)
+		}
+		series = append(series, rate)
+	}
+	return series
+}
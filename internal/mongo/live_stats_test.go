@@ -0,0 +1,47 @@
+package mongo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLiveStats(t *testing.T) {
+	status := func(conns int32, inserts, bytesIn int64) *ServerStatus {
+		s := &ServerStatus{}
+		s.Connections.Current = conns
+		s.OpCounters.Insert = inserts
+		s.Network.BytesIn = bytesIn
+		return s
+	}
+
+	stats := NewLiveStats(2)
+	assert.Nil(t, stats.Latest())
+	assert.Empty(t, stats.Rates()[0].Values)
+
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	stats.Add(status(5, 100, 1000), start)
+	stats.Add(status(6, 120, 3000), start.Add(2*time.Second))
+	// the server was restarted, counters start from 0
+	stats.Add(status(2, 10, 500), start.Add(4*time.Second))
+	stats.Add(status(3, 30, 1500), start.Add(6*time.Second))
+
+	assert.Equal(t, int32(3), stats.Latest().Connections.Current)
+
+	// only the last size rates are kept
+	gauges := stats.Gauges()
+	assert.Equal(t, "Connections", gauges[0].Name)
+	assert.Equal(t, []float64{6, 2, 3}, gauges[0].Values)
+
+	rates := stats.Rates()
+	require.Len(t, rates, 9)
+	assert.Equal(t, "Inserts", rates[0].Name)
+	assert.Equal(t, []float64{0, 10}, rates[0].Values)
+	assert.Equal(t, "Network in", rates[6].Name)
+	assert.Equal(t, []float64{0, 500}, rates[6].Values)
+
+	stats.Reset()
+	assert.Nil(t, stats.Latest())
+}
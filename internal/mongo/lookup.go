@@ -0,0 +1,88 @@
+package mongo
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// lookupSampleSize is the number of documents sampled
+// to find fields of the foreign collection
+const lookupSampleSize = 50
+
+// Lookup is the $lookup stage joining documents of the foreign
+// collection where ForeignField equals LocalField
+type Lookup struct {
+	From         string
+	LocalField   string
+	ForeignField string
+	As           string
+}
+
+// Stage returns the $lookup stage, As defaults to the name
+// of the foreign collection
+func (l Lookup) Stage() (primitive.D, error) {
+	if err := ValidateCollectionName(l.From); err != nil {
+		return nil, err
+	}
+	if l.LocalField == "" || l.ForeignField == "" {
+		return nil, fmt.Errorf("local and foreign fields must be set")
+	}
+	as := l.As
+	if as == "" {
+		as = l.From
+	}
+	if strings.HasPrefix(as, "$") {
+		return nil, fmt.Errorf("output field cannot start with $")
+	}
+
+	return primitive.D{{Key: "$lookup", Value: primitive.D{
+		{Key: "from", Value: l.From},
+		{Key: "localField", Value: l.LocalField},
+		{Key: "foreignField", Value: l.ForeignField},
+		{Key: "as", Value: as},
+	}}}, nil
+}
+
+// SuggestForeignField returns the field of the foreign collection most
+// likely referenced by the local field: the field with the same name,
+// or _id, as references usually point to it, e.g. userId to _id of users
+func SuggestForeignField(localField string, foreignFields []string) string {
+	suggested := ""
+	for _, field := range foreignFields {
+		if field == localField {
+			return field
+		}
+		if field == "_id" {
+			suggested = field
+		}
+	}
+	return suggested
+}
+
+// SampleFields returns sorted fields found in a random sample
+// of documents of the collection, nested fields use dot notation
+func (d *Dao) SampleFields(ctx context.Context, db, collection string) ([]string, error) {
+	pipeline := primitive.A{primitive.M{"$sample": primitive.M{"size": lookupSampleSize}}}
+	cursor, err := d.readCollection(db, collection).Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var documents []primitive.M
+	if err := cursor.All(ctx, &documents); err != nil {
+		return nil, err
+	}
+
+	types := SampleFieldTypes(documents)
+	fields := make([]string, 0, len(types))
+	for field := range types {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	return fields, nil
+}
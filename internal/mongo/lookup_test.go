@@ -0,0 +1,36 @@
+package mongo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestLookupStage(t *testing.T) {
+	stage, err := Lookup{From: "users", LocalField: "userId", ForeignField: "_id"}.Stage()
+	require.NoError(t, err)
+	assert.Equal(t, primitive.D{{Key: "$lookup", Value: primitive.D{
+		{Key: "from", Value: "users"},
+		{Key: "localField", Value: "userId"},
+		{Key: "foreignField", Value: "_id"},
+		{Key: "as", Value: "users"},
+	}}}, stage)
+	assert.Equal(t, `{$lookup: {from: "users", localField: "userId", foreignField: "_id", as: "users"}}`, StageShellString(stage))
+
+	_, err = Lookup{From: "users", LocalField: "userId"}.Stage()
+	assert.Error(t, err)
+	_, err = Lookup{LocalField: "a", ForeignField: "b"}.Stage()
+	assert.Error(t, err)
+	_, err = Lookup{From: "users", LocalField: "a", ForeignField: "b", As: "$a"}.Stage()
+	assert.Error(t, err)
+}
+
+func TestSuggestForeignField(t *testing.T) {
+	fields := []string{"_id", "email", "sku"}
+
+	assert.Equal(t, "_id", SuggestForeignField("userId", fields))
+	assert.Equal(t, "sku", SuggestForeignField("sku", fields))
+	assert.Equal(t, "", SuggestForeignField("price", []string{"name"}))
+}
@@ -0,0 +1,168 @@
+package mongo
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/kopecmaciej/vi-mongo/internal/config"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// maskSymbol replaces masked part of the value
+const maskSymbol = "****"
+
+// Masker redacts values of sensitive fields before they are shown,
+// copied or exported, encrypted values are always replaced with
+// EncryptedPlaceholder. Documents in the state are never masked,
+// so editing still works on the real values.
+type Masker struct {
+	rules []config.MaskRule
+}
+
+// NewMasker returns a masker using the rules, masker without
+// rules hides only encrypted values
+func NewMasker(rules []config.MaskRule) *Masker {
+	return &Masker{rules: rules}
+}
+
+// IsEnabled returns true if there is any masking rule
+func (m *Masker) IsEnabled() bool {
+	return m != nil && len(m.rules) > 0
+}
+
+// MaskValue masks the value of the field with given dotted path,
+// nested documents and arrays are masked field by field
+func (m *Masker) MaskValue(field string, value interface{}) interface{} {
+	if IsEncrypted(value) {
+		return EncryptedPlaceholder
+	}
+	if rule, ok := m.rule(field); ok {
+		return redact(rule.Mode, value)
+	}
+
+	switch v := value.(type) {
+	case primitive.M:
+		return m.maskDocument(field+".", v)
+	case map[string]interface{}:
+		return m.maskDocument(field+".", primitive.M(v))
+	case primitive.D:
+		masked := make(primitive.D, len(v))
+		for i, elem := range v {
+			masked[i] = primitive.E{Key: elem.Key, Value: m.MaskValue(field+"."+elem.Key, elem.Value)}
+		}
+		return masked
+	case primitive.A:
+		return m.maskArray(field, v)
+	case []interface{}:
+		return m.maskArray(field, primitive.A(v))
+	}
+	return value
+}
+
+// IsMasked returns true if values of the field are masked by a rule
+func (m *Masker) IsMasked(field string) bool {
+	_, ok := m.rule(field)
+	return ok
+}
+
+// MaskDocument returns a copy of the document with masked values
+func (m *Masker) MaskDocument(doc primitive.M) primitive.M {
+	if doc == nil {
+		return doc
+	}
+	return m.maskDocument("", doc)
+}
+
+// MaskJson masks the document in JSON and returns it indented
+func (m *Masker) MaskJson(doc string) (string, error) {
+	parsed, err := ParseJsonToBson(doc)
+	if err != nil {
+		return "", err
+	}
+	jsoned, err := ParseBsonDocument(m.MaskDocument(parsed))
+	if err != nil {
+		return "", err
+	}
+	indented, err := IndentJson(jsoned)
+	if err != nil {
+		return "", err
+	}
+	return indented.String(), nil
+}
+
+func (m *Masker) maskArray(field string, values primitive.A) primitive.A {
+	masked := make(primitive.A, len(values))
+	for i, value := range values {
+		masked[i] = m.MaskValue(field, value)
+	}
+	return masked
+}
+
+func (m *Masker) maskDocument(prefix string, doc primitive.M) primitive.M {
+	masked := make(primitive.M, len(doc))
+	for key, value := range doc {
+		masked[key] = m.MaskValue(prefix+key, value)
+	}
+	return masked
+}
+
+// rule returns the first rule matching the field or any of its parents,
+// either by the whole path or its last part, so fields of flattened
+// documents are masked as well. Matching is case insensitive.
+func (m *Masker) rule(field string) (config.MaskRule, bool) {
+	if !m.IsEnabled() {
+		return config.MaskRule{}, false
+	}
+	parts := strings.Split(strings.ToLower(field), ".")
+	for i := 1; i <= len(parts); i++ {
+		if rule, ok := m.pathRule(strings.Join(parts[:i], ".")); ok {
+			return rule, true
+		}
+	}
+	return config.MaskRule{}, false
+}
+
+// pathRule returns the first rule matching the whole path or its last part
+func (m *Masker) pathRule(field string) (config.MaskRule, bool) {
+	name := field[strings.LastIndex(field, ".")+1:]
+	for _, rule := range m.rules {
+		pattern := strings.ToLower(rule.Field)
+		if ok, _ := path.Match(pattern, field); ok {
+			return rule, true
+		}
+		if ok, _ := path.Match(pattern, name); ok {
+			return rule, true
+		}
+	}
+	return config.MaskRule{}, false
+}
+
+// redact returns the masked value as a string, so its original
+// type is not leaked either. Null values stay null.
+func redact(mode string, value interface{}) interface{} {
+	if value == nil {
+		return nil
+	}
+	text, ok := value.(string)
+	if !ok {
+		text = fmt.Sprint(value)
+	}
+	runes := []rune(text)
+
+	switch mode {
+	case config.MaskPartial:
+		if len(runes) <= 6 {
+			return maskSymbol
+		}
+		return string(runes[:2]) + maskSymbol + string(runes[len(runes)-2:])
+	case config.MaskEmail:
+		at := strings.LastIndex(text, "@")
+		if at <= 0 {
+			return maskSymbol
+		}
+		return string([]rune(text)[:1]) + maskSymbol + text[at:]
+	default:
+		return maskSymbol
+	}
+}
@@ -0,0 +1,100 @@
+package mongo
+
+import (
+	"testing"
+
+	"github.com/kopecmaciej/vi-mongo/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestMaskerMaskDocument(t *testing.T) {
+	masker := NewMasker([]config.MaskRule{
+		{Field: "email", Mode: config.MaskEmail},
+		{Field: "*token*", Mode: config.MaskPartial},
+		{Field: "billing.card"},
+	})
+
+	doc := primitive.M{
+		"_id":   1,
+		"name":  "John",
+		"email": "john.doe@example.com",
+		"contacts": primitive.A{
+			primitive.M{"email": "jane@example.com", "phone": "123"},
+		},
+		"apiToken": "abcdef123456",
+		"billing":  primitive.M{"card": int64(4111111111111111), "country": "PL"},
+		"card":     "not masked",
+	}
+
+	masked := masker.MaskDocument(doc)
+	assert.Equal(t, primitive.M{
+		"_id":   1,
+		"name":  "John",
+		"email": "j****@example.com",
+		"contacts": primitive.A{
+			primitive.M{"email": "j****@example.com", "phone": "123"},
+		},
+		"apiToken": "ab****56",
+		"billing":  primitive.M{"card": "****", "country": "PL"},
+		"card":     "not masked",
+	}, masked)
+
+	// original document is not changed
+	assert.Equal(t, "john.doe@example.com", doc["email"])
+}
+
+func TestMaskerRedact(t *testing.T) {
+	masker := NewMasker([]config.MaskRule{{Field: "secret", Mode: config.MaskPartial}, {Field: "mail", Mode: config.MaskEmail}})
+
+	assert.Equal(t, "****", masker.MaskValue("secret", "short"))
+	assert.Equal(t, "****", masker.MaskValue("mail", "not an email"))
+	assert.Nil(t, masker.MaskValue("secret", nil))
+	assert.Equal(t, "visible", masker.MaskValue("other", "visible"))
+}
+
+func TestMaskerDisabled(t *testing.T) {
+	masker := NewMasker(nil)
+	assert.False(t, masker.IsEnabled())
+
+	doc := primitive.M{"email": "john@example.com"}
+	assert.Equal(t, doc, masker.MaskDocument(doc))
+
+	json, err := masker.MaskJson(`{"email": "john@example.com"}`)
+	require.NoError(t, err)
+	assert.Contains(t, json, `"email": "john@example.com"`)
+}
+
+func TestMaskerHidesEncrypted(t *testing.T) {
+	encrypted := primitive.Binary{Subtype: EncryptedSubtype, Data: []byte{1, 2, 3}}
+	doc := primitive.M{
+		"ssn":     encrypted,
+		"patient": primitive.M{"records": primitive.A{encrypted}},
+		"photo":   primitive.Binary{Subtype: 0, Data: []byte{1}},
+	}
+
+	masked := NewMasker(nil).MaskDocument(doc)
+	assert.Equal(t, EncryptedPlaceholder, masked["ssn"])
+	assert.Equal(t, primitive.M{"records": primitive.A{EncryptedPlaceholder}}, masked["patient"])
+	assert.Equal(t, doc["photo"], masked["photo"])
+}
+
+func TestMaskerMaskJson(t *testing.T) {
+	masker := NewMasker([]config.MaskRule{{Field: "password"}})
+
+	json, err := masker.MaskJson(`{"user": "john", "password": "secret", "nested": {"password": "secret"}}`)
+	require.NoError(t, err)
+	assert.NotContains(t, json, "secret")
+	assert.Contains(t, json, `"user": "john"`)
+}
+
+func TestMaskerMasksFieldsOfMaskedParent(t *testing.T) {
+	masker := NewMasker([]config.MaskRule{{Field: "billing"}})
+
+	// flattened columns ask for nested fields directly
+	assert.True(t, masker.IsMasked("billing.card.number"))
+	assert.True(t, masker.IsMasked("user.billing.card"))
+	assert.NotEqual(t, "4111", masker.MaskValue("billing.card", "4111"))
+	assert.False(t, masker.IsMasked("shipping.card"))
+}
@@ -0,0 +1,205 @@
+package mongo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Metric is a single value of the metrics snapshot,
+// names follow Prometheus naming conventions
+type Metric struct {
+	Name   string            `json:"name"`
+	Help   string            `json:"-"`
+	Labels map[string]string `json:"labels,omitempty"`
+	Value  float64           `json:"value"`
+}
+
+// MetricsSnapshot is a point-in-time copy of key server
+// and database metrics, meant to be attached to tickets
+type MetricsSnapshot struct {
+	Connection string    `json:"connection"`
+	Host       string    `json:"host"`
+	Time       time.Time `json:"time"`
+	Metrics    []Metric  `json:"metrics"`
+}
+
+type metricSource struct {
+	name   string
+	path   string
+	help   string
+	labels map[string]string
+}
+
+// serverMetrics are read from serverStatus,
+// metrics missing on the server are skipped
+var serverMetrics = []metricSource{
+	{"mongodb_uptime_seconds", "uptime", "Time since the server started", nil},
+	{"mongodb_connections", "connections.current", "Number of connections", map[string]string{"state": "current"}},
+	{"mongodb_connections", "connections.available", "Number of connections", map[string]string{"state": "available"}},
+	{"mongodb_connections_created_total", "connections.totalCreated", "Connections created since the server started", nil},
+	{"mongodb_op_counters_total", "opcounters.insert", "Operations since the server started", map[string]string{"type": "insert"}},
+	{"mongodb_op_counters_total", "opcounters.query", "Operations since the server started", map[string]string{"type": "query"}},
+	{"mongodb_op_counters_total", "opcounters.update", "Operations since the server started", map[string]string{"type": "update"}},
+	{"mongodb_op_counters_total", "opcounters.delete", "Operations since the server started", map[string]string{"type": "delete"}},
+	{"mongodb_op_counters_total", "opcounters.getmore", "Operations since the server started", map[string]string{"type": "getmore"}},
+	{"mongodb_op_counters_total", "opcounters.command", "Operations since the server started", map[string]string{"type": "command"}},
+	{"mongodb_memory_megabytes", "mem.resident", "Memory used by the server", map[string]string{"type": "resident"}},
+	{"mongodb_memory_megabytes", "mem.virtual", "Memory used by the server", map[string]string{"type": "virtual"}},
+	{"mongodb_network_bytes_total", "network.bytesIn", "Network traffic of the server", map[string]string{"direction": "in"}},
+	{"mongodb_network_bytes_total", "network.bytesOut", "Network traffic of the server", map[string]string{"direction": "out"}},
+	{"mongodb_network_requests_total", "network.numRequests", "Requests received by the server", nil},
+	{"mongodb_wiredtiger_cache_bytes", "wiredTiger.cache.bytes currently in the cache", "WiredTiger cache size", map[string]string{"type": "used"}},
+	{"mongodb_wiredtiger_cache_bytes", "wiredTiger.cache.maximum bytes configured", "WiredTiger cache size", map[string]string{"type": "max"}},
+	{"mongodb_wiredtiger_cache_bytes", "wiredTiger.cache.tracked dirty bytes in the cache", "WiredTiger cache size", map[string]string{"type": "dirty"}},
+	{"mongodb_wiredtiger_cache_pages_evicted_total", "wiredTiger.cache.unmodified pages evicted", "Pages evicted from the WiredTiger cache", map[string]string{"type": "unmodified"}},
+	{"mongodb_wiredtiger_cache_pages_evicted_total", "wiredTiger.cache.modified pages evicted", "Pages evicted from the WiredTiger cache", map[string]string{"type": "modified"}},
+}
+
+// dbMetrics are read from dbStats of every database
+var dbMetrics = []metricSource{
+	{"mongodb_db_collections", "collections", "Number of collections in the database", nil},
+	{"mongodb_db_objects", "objects", "Number of documents in the database", nil},
+	{"mongodb_db_data_size_bytes", "dataSize", "Uncompressed size of documents in the database", nil},
+	{"mongodb_db_storage_size_bytes", "storageSize", "Storage allocated for documents in the database", nil},
+	{"mongodb_db_indexes", "indexes", "Number of indexes in the database", nil},
+	{"mongodb_db_index_size_bytes", "indexSize", "Storage allocated for indexes in the database", nil},
+}
+
+// MetricsSnapshot collects serverStatus and dbStats of all databases,
+// databases which stats can't be read are skipped
+func (d *Dao) MetricsSnapshot(ctx context.Context) (*MetricsSnapshot, error) {
+	serverStatus, err := d.runAdminCommand(ctx, "serverStatus", 1)
+	if err != nil {
+		return nil, err
+	}
+
+	dbNames, err := d.client.ListDatabaseNames(ctx, primitive.M{})
+	if err != nil {
+		return nil, err
+	}
+	dbStats := make(map[string]primitive.M, len(dbNames))
+	for _, db := range dbNames {
+		var stats primitive.M
+		if err := d.client.Database(db).RunCommand(ctx, primitive.D{{Key: "dbStats", Value: 1}}).Decode(&stats); err != nil {
+			log.Warn().Err(err).Str("db", db).Msg("Error reading database stats")
+			continue
+		}
+		dbStats[db] = stats
+	}
+
+	snapshot := NewMetricsSnapshot(serverStatus, dbStats, time.Now().UTC())
+	snapshot.Connection = d.Config.Name
+	snapshot.Host = d.Config.Host
+	return snapshot, nil
+}
+
+// NewMetricsSnapshot returns metrics of the server status and stats of databases
+func NewMetricsSnapshot(serverStatus primitive.M, dbStats map[string]primitive.M, at time.Time) *MetricsSnapshot {
+	snapshot := &MetricsSnapshot{Time: at}
+	snapshot.addMetrics(serverMetrics, serverStatus, nil)
+
+	dbs := make([]string, 0, len(dbStats))
+	for db := range dbStats {
+		dbs = append(dbs, db)
+	}
+	sort.Strings(dbs)
+	for _, db := range dbs {
+		snapshot.addMetrics(dbMetrics, dbStats[db], map[string]string{"db": db})
+	}
+	return snapshot
+}
+
+func (s *MetricsSnapshot) addMetrics(sources []metricSource, doc primitive.M, labels map[string]string) {
+	for _, source := range sources {
+		value, ok := lookupPath(doc, source.path)
+		if !ok {
+			continue
+		}
+		number, ok := toFloat(value)
+		if !ok {
+			continue
+		}
+
+		metricLabels := make(map[string]string, len(source.labels)+len(labels))
+		for k, v := range source.labels {
+			metricLabels[k] = v
+		}
+		for k, v := range labels {
+			metricLabels[k] = v
+		}
+		if len(metricLabels) == 0 {
+			metricLabels = nil
+		}
+		s.Metrics = append(s.Metrics, Metric{Name: source.name, Help: source.help, Labels: metricLabels, Value: number})
+	}
+}
+
+// WriteJSON writes the snapshot as indented JSON
+func (s *MetricsSnapshot) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(s)
+}
+
+// WritePrometheus writes the snapshot in Prometheus text format,
+// samples have the timestamp of the snapshot
+func (s *MetricsSnapshot) WritePrometheus(w io.Writer) error {
+	timestamp := s.Time.UnixMilli()
+	written := make(map[string]bool)
+	for _, metric := range s.Metrics {
+		if !written[metric.Name] {
+			written[metric.Name] = true
+			metricType := "gauge"
+			if strings.HasSuffix(metric.Name, "_total") {
+				metricType = "counter"
+			}
+			if _, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", metric.Name, metric.Help, metric.Name, metricType); err != nil {
+				return err
+			}
+		}
+		value := strconv.FormatFloat(metric.Value, 'g', -1, 64)
+		if _, err := fmt.Fprintf(w, "%s%s %s %d\n", metric.Name, formatLabels(metric.Labels), value, timestamp); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func formatLabels(labels map[string]string) string {
+	if len(labels) == 0 {
+		return ""
+	}
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	replacer := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	pairs := make([]string, 0, len(keys))
+	for _, k := range keys {
+		pairs = append(pairs, fmt.Sprintf(`%s="%s"`, k, replacer.Replace(labels[k])))
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+func toFloat(value interface{}) (float64, bool) {
+	switch n := value.(type) {
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+	return 0, false
+}
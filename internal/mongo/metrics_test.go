@@ -0,0 +1,73 @@
+package mongo
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestNewMetricsSnapshot(t *testing.T) {
+	serverStatus := primitive.M{
+		"uptime":      int64(120),
+		"connections": primitive.M{"current": int32(5)},
+		"opcounters":  primitive.M{"insert": int64(10), "query": "invalid"},
+	}
+	dbStats := map[string]primitive.M{
+		"shop":  {"collections": int32(3), "dataSize": 2048.5},
+		"admin": {"collections": int32(1)},
+	}
+
+	snapshot := NewMetricsSnapshot(serverStatus, dbStats, time.Unix(1700000000, 0))
+	assert.Equal(t, []Metric{
+		{Name: "mongodb_uptime_seconds", Help: "Time since the server started", Value: 120},
+		{Name: "mongodb_connections", Help: "Number of connections", Labels: map[string]string{"state": "current"}, Value: 5},
+		{Name: "mongodb_op_counters_total", Help: "Operations since the server started", Labels: map[string]string{"type": "insert"}, Value: 10},
+		{Name: "mongodb_db_collections", Help: "Number of collections in the database", Labels: map[string]string{"db": "admin"}, Value: 1},
+		{Name: "mongodb_db_collections", Help: "Number of collections in the database", Labels: map[string]string{"db": "shop"}, Value: 3},
+		{Name: "mongodb_db_data_size_bytes", Help: "Uncompressed size of documents in the database", Labels: map[string]string{"db": "shop"}, Value: 2048.5},
+	}, snapshot.Metrics)
+}
+
+func TestMetricsSnapshotWritePrometheus(t *testing.T) {
+	snapshot := &MetricsSnapshot{
+		Time: time.UnixMilli(1700000000000),
+		Metrics: []Metric{
+			{Name: "mongodb_op_counters_total", Help: "Operations", Labels: map[string]string{"type": "insert"}, Value: 10},
+			{Name: "mongodb_op_counters_total", Help: "Operations", Labels: map[string]string{"type": "query"}, Value: 2.5},
+			{Name: "mongodb_db_objects", Help: "Documents", Labels: map[string]string{"db": `a"b`}, Value: 3},
+		},
+	}
+
+	var out bytes.Buffer
+	require.NoError(t, snapshot.WritePrometheus(&out))
+	assert.Equal(t, `# HELP mongodb_op_counters_total Operations
+# TYPE mongodb_op_counters_total counter
+mongodb_op_counters_total{type="insert"} 10 1700000000000
+mongodb_op_counters_total{type="query"} 2.5 1700000000000
+# HELP mongodb_db_objects Documents
+# TYPE mongodb_db_objects gauge
+mongodb_db_objects{db="a\"b"} 3 1700000000000
+`, out.String())
+}
+
+func TestMetricsSnapshotWriteJSON(t *testing.T) {
+	snapshot := &MetricsSnapshot{
+		Connection: "local",
+		Time:       time.Unix(1700000000, 0).UTC(),
+		Metrics:    []Metric{{Name: "mongodb_uptime_seconds", Help: "Uptime", Value: 1}},
+	}
+
+	var out bytes.Buffer
+	require.NoError(t, snapshot.WriteJSON(&out))
+
+	var decoded map[string]interface{}
+	require.NoError(t, json.Unmarshal(out.Bytes(), &decoded))
+	assert.Equal(t, "local", decoded["connection"])
+	assert.Equal(t, "2023-11-14T22:13:20Z", decoded["time"])
+	assert.Equal(t, []interface{}{map[string]interface{}{"name": "mongodb_uptime_seconds", "value": 1.0}}, decoded["metrics"])
+}
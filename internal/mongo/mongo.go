@@ -1,23 +1,44 @@
 package mongo
 
+import "time"
+
 type ServerStatus struct {
-	Ok             int32  `bson:"ok"`
-	Version        string `bson:"version"`
-	Uptime         int32  `bson:"uptime"`
-	CurrentConns   int32  `bson:"connections.current"`
-	AvailableConns int32  `bson:"connections.available"`
-	OpCounters     struct {
-		Insert int32 `bson:"insert"`
-		Query  int32 `bson:"query"`
-		Update int32 `bson:"update"`
-		Delete int32 `bson:"delete"`
+	Ok          int32  `bson:"ok"`
+	Version     string `bson:"version"`
+	Uptime      int32  `bson:"uptime"`
+	Connections struct {
+		Current      int32 `bson:"current"`
+		Available    int32 `bson:"available"`
+		TotalCreated int64 `bson:"totalCreated"`
+	} `bson:"connections"`
+	OpCounters struct {
+		Insert  int64 `bson:"insert"`
+		Query   int64 `bson:"query"`
+		Update  int64 `bson:"update"`
+		Delete  int64 `bson:"delete"`
+		GetMore int64 `bson:"getmore"`
+		Command int64 `bson:"command"`
 	} `bson:"opcounters"`
 	Mem struct {
 		Resident int32 `bson:"resident"`
 		Virtual  int32 `bson:"virtual"`
 	} `bson:"mem"`
+	Network struct {
+		BytesIn     int64 `bson:"bytesIn"`
+		BytesOut    int64 `bson:"bytesOut"`
+		NumRequests int64 `bson:"numRequests"`
+	} `bson:"network"`
 	Repl struct {
-		ReadOnly bool `bson:"readOnly"`
-		IsMaster bool `bson:"ismaster"`
+		ReadOnly    bool   `bson:"readOnly"`
+		IsMaster    bool   `bson:"ismaster"`
+		Secondary   bool   `bson:"secondary"`
+		ArbiterOnly bool   `bson:"arbiterOnly"`
+		SetName     string `bson:"setName"`
 	} `bson:"repl"`
+	StorageEngine StorageEngine `bson:"storageEngine"`
+	// WiredTiger is nil if the server uses other storage engine
+	WiredTiger *WiredTigerStats `bson:"wiredTiger"`
+	Queues     Queues           `bson:"queues"`
+	// Ping is the round trip time of the isMaster command
+	Ping time.Duration `bson:"-"`
 }
@@ -0,0 +1,43 @@
+package mongo
+
+import (
+	"regexp"
+)
+
+// FilterDBsWithCollections returns databases which name contains the text,
+// with all of their collections, and databases with collections which name
+// contains the text, only with those collections. Matching is case
+// insensitive. Expand is true if any collection matched, so the databases
+// should be expanded to show it.
+func FilterDBsWithCollections(dbsWithColls []DBsWithCollections, text string) (filtered []DBsWithCollections, expand bool) {
+	if text == "" {
+		return dbsWithColls, false
+	}
+
+	filtered = []DBsWithCollections{}
+	re := regexp.MustCompile(`(?i)` + regexp.QuoteMeta(text))
+	for _, db := range dbsWithColls {
+		matchedDB := re.MatchString(db.DB)
+		matchedCollections := []string{}
+
+		for _, coll := range db.Collections {
+			if re.MatchString(coll) {
+				matchedCollections = append(matchedCollections, coll)
+			}
+		}
+
+		if matchedDB || len(matchedCollections) > 0 {
+			filteredDB := DBsWithCollections{
+				DB:          db.DB,
+				Collections: matchedCollections,
+			}
+			if matchedDB {
+				filteredDB.Collections = db.Collections
+			}
+			filtered = append(filtered, filteredDB)
+			expand = expand || len(matchedCollections) > 0
+		}
+	}
+
+	return filtered, expand
+}
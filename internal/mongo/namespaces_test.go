@@ -0,0 +1,32 @@
+package mongo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFilterDBsWithCollections(t *testing.T) {
+	dbs := []DBsWithCollections{
+		{DB: "shop", Collections: []string{"users", "orders"}},
+		{DB: "analytics", Collections: []string{"events", "user_sessions"}},
+	}
+
+	filtered, expand := FilterDBsWithCollections(dbs, "")
+	assert.Equal(t, dbs, filtered)
+	assert.False(t, expand)
+
+	filtered, expand = FilterDBsWithCollections(dbs, "USER")
+	assert.Equal(t, []DBsWithCollections{
+		{DB: "shop", Collections: []string{"users"}},
+		{DB: "analytics", Collections: []string{"user_sessions"}},
+	}, filtered)
+	assert.True(t, expand)
+
+	filtered, expand = FilterDBsWithCollections(dbs, "shop")
+	assert.Equal(t, []DBsWithCollections{dbs[0]}, filtered)
+	assert.False(t, expand)
+
+	filtered, _ = FilterDBsWithCollections(dbs, "(")
+	assert.Empty(t, filtered)
+}
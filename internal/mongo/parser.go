@@ -2,6 +2,8 @@ package mongo
 
 import (
 	"bytes"
+	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"strings"
@@ -53,6 +55,27 @@ func ParseBsonValue(value interface{}) interface{} {
 		parsed = primitive.M{
 			"$date": v.Time(),
 		}
+	case primitive.Binary:
+		// binary values, e.g. encrypted fields, have to survive
+		// editing, so they are kept in extended JSON format
+		parsed = primitive.M{
+			"$binary": primitive.M{
+				"base64":  base64.StdEncoding.EncodeToString(v.Data),
+				"subType": fmt.Sprintf("%02x", v.Subtype),
+			},
+		}
+	case primitive.M:
+		nested := make(primitive.M, len(v))
+		for key, value := range v {
+			nested[key] = ParseBsonValue(value)
+		}
+		parsed = nested
+	case primitive.A:
+		nested := make(primitive.A, len(v))
+		for i, value := range v {
+			nested[i] = ParseBsonValue(value)
+		}
+		parsed = nested
 	}
 
 	if parsed == nil {
@@ -132,6 +155,9 @@ func ParseJsonValue(value interface{}) (interface{}, error) {
 		if oid, ok := v["$oid"]; ok {
 			return primitive.ObjectIDFromHex(oid.(string))
 		}
+		if binary, ok := v["$binary"].(map[string]interface{}); ok {
+			return parseBinary(binary)
+		}
 		if date, ok := v["$date"]; ok {
 			t, err := time.Parse(time.RFC3339, date.(string))
 			if err != nil {
@@ -167,3 +193,18 @@ func ParseJsonValue(value interface{}) (interface{}, error) {
 		return v, nil
 	}
 }
+
+// parseBinary parses the value of $binary in extended JSON format
+func parseBinary(binary map[string]interface{}) (primitive.Binary, error) {
+	encoded, _ := binary["base64"].(string)
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return primitive.Binary{}, fmt.Errorf("error parsing binary: %w", err)
+	}
+	subType, _ := binary["subType"].(string)
+	subTypeBytes, err := hex.DecodeString(subType)
+	if err != nil || len(subTypeBytes) != 1 {
+		return primitive.Binary{}, fmt.Errorf("error parsing binary subtype %q", subType)
+	}
+	return primitive.Binary{Subtype: subTypeBytes[0], Data: data}, nil
+}
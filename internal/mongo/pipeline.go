@@ -0,0 +1,248 @@
+package mongo
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/kopecmaciej/vi-mongo/internal/util"
+	"github.com/rs/zerolog/log"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Pipeline is an aggregation pipeline. Stages are kept as ordered
+// documents, as the order of fields matters, e.g. in $sort.
+type Pipeline []primitive.D
+
+// ParsePipeline parses the pipeline written as a JSON array of stages,
+// keys can be unquoted and ObjectID("...") can be used as in queries
+func ParsePipeline(text string) (Pipeline, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return Pipeline{}, nil
+	}
+
+	text = util.QuoteUnquotedKeys(text)
+	text = strings.ReplaceAll(text, "ObjectID(\"", "{\"$oid\": \"")
+	text = strings.ReplaceAll(text, "\")", "\"}")
+	text, err := util.ParseDateToBson(text)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing date: %w", err)
+	}
+
+	// extended JSON can't be unmarshaled from an array directly
+	var wrapper struct {
+		Pipeline Pipeline `bson:"pipeline"`
+	}
+	if err := bson.UnmarshalExtJSON([]byte(`{"pipeline": `+text+`}`), false, &wrapper); err != nil {
+		return nil, fmt.Errorf("error parsing pipeline: %w", err)
+	}
+	for i, stage := range wrapper.Pipeline {
+		if len(stage) != 1 || !strings.HasPrefix(stage[0].Key, "$") {
+			return nil, fmt.Errorf("stage %d must have a single $ operator", i+1)
+		}
+	}
+	return wrapper.Pipeline, nil
+}
+
+// String returns the pipeline as a JSON array in relaxed extended JSON
+func (p Pipeline) String() string {
+	stages := make([]string, 0, len(p))
+	for _, stage := range p {
+		jsoned, err := bson.MarshalExtJSON(stage, false, false)
+		if err != nil {
+			log.Error().Err(err).Msg("Error marshaling pipeline stage")
+			continue
+		}
+		stages = append(stages, string(jsoned))
+	}
+	return "[" + strings.Join(stages, ", ") + "]"
+}
+
+// WithOut returns the pipeline with $out stage writing results into
+// the target collection of the database. The source collection can't be
+// the target, as it would be replaced, and the pipeline can't already
+// end with $out or $merge.
+func (p Pipeline) WithOut(db, source, target string) (Pipeline, error) {
+	if err := ValidateCollectionName(target); err != nil {
+		return nil, err
+	}
+	if target == source {
+		return nil, fmt.Errorf("target collection must be different from %s", source)
+	}
+	if len(p) > 0 {
+		switch p[len(p)-1][0].Key {
+		case "$out", "$merge":
+			return nil, fmt.Errorf("pipeline already ends with %s", p[len(p)-1][0].Key)
+		}
+	}
+
+	out := primitive.D{{Key: "$out", Value: primitive.D{
+		{Key: "db", Value: db},
+		{Key: "coll", Value: target},
+	}}}
+	return append(p[:len(p):len(p)], out), nil
+}
+
+// Paged returns the pipeline returning the page of its results. Pipelines
+// writing results with $out or $merge return no documents, so they can't
+// be paged.
+func (p Pipeline) Paged(skip, limit int64) (Pipeline, error) {
+	if err := p.checkReadOnly(); err != nil {
+		return nil, err
+	}
+	paged := append(p[:len(p):len(p)], primitive.D{{Key: "$skip", Value: skip}})
+	if limit > 0 {
+		paged = append(paged, primitive.D{{Key: "$limit", Value: limit}})
+	}
+	return paged, nil
+}
+
+// Count returns the pipeline counting results of the pipeline
+func (p Pipeline) Count() Pipeline {
+	return append(p[:len(p):len(p)], primitive.D{{Key: "$count", Value: "count"}})
+}
+
+func (p Pipeline) checkReadOnly() error {
+	for _, stage := range p {
+		switch stage[0].Key {
+		case "$out", "$merge":
+			return fmt.Errorf("%s writes results into a collection, they can't be listed", stage[0].Key)
+		}
+	}
+	return nil
+}
+
+// AppendStages appends stages to the pipeline text, stages are a single
+// stage or an array of stages. Text is kept as written, so comments and
+// formatting of the pipeline are not lost.
+func AppendStages(text, stages string) string {
+	stages = strings.TrimSpace(stages)
+	if strings.HasPrefix(stages, "[") && strings.HasSuffix(stages, "]") {
+		stages = strings.TrimSpace(stages[1 : len(stages)-1])
+	}
+
+	text = strings.TrimSpace(text)
+	end := strings.LastIndex(text, "]")
+	if !strings.HasPrefix(text, "[") || end < 0 {
+		return "[\n  " + stages + "\n]"
+	}
+	body := strings.TrimRight(text[1:end], " \t\r\n")
+	if strings.TrimSpace(body) == "" {
+		return "[\n  " + stages + "\n]"
+	}
+	return "[" + strings.TrimSuffix(body, ",") + ",\n  " + stages + "\n]"
+}
+
+// Aggregate runs the pipeline on the collection of the state and returns
+// the page of its results with the number of all results
+func (d *Dao) Aggregate(ctx context.Context, state *CollectionState, pipeline Pipeline) ([]*LazyDocument, int64, error) {
+	paged, err := pipeline.Paged(state.Page, state.Limit)
+	if err != nil {
+		return nil, 0, err
+	}
+	coll := d.readCollection(state.Db, state.Coll)
+
+	var counted struct {
+		Count int64 `bson:"count"`
+	}
+	countCursor, err := coll.Aggregate(ctx, pipeline.Count())
+	if err != nil {
+		return nil, 0, err
+	}
+	defer countCursor.Close(ctx)
+	// $count returns no document when there are no results
+	if countCursor.Next(ctx) {
+		if err := countCursor.Decode(&counted); err != nil {
+			return nil, 0, err
+		}
+	}
+	if err := countCursor.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	cursor, err := coll.Aggregate(ctx, paged)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	var documents []*LazyDocument
+	for cursor.Next(ctx) {
+		documents = append(documents, NewLazyDocument(cursor.Current))
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	log.Debug().Msgf("Pipeline run, db: %v, collection: %v, stages: %d", state.Db, state.Coll, len(pipeline))
+
+	return documents, counted.Count, nil
+}
+
+// ValidateCollectionName returns an error if the name
+// can't be used as the name of a collection
+func ValidateCollectionName(name string) error {
+	switch {
+	case name == "":
+		return fmt.Errorf("collection name cannot be empty")
+	case strings.HasPrefix(name, "system."):
+		return fmt.Errorf("collection name cannot start with system.")
+	case strings.ContainsAny(name, "$\x00"):
+		return fmt.Errorf("collection name cannot contain $ or null character")
+	}
+	return nil
+}
+
+// AggregateToCollection runs the pipeline on the collection and writes
+// its results into the target collection, which is replaced if it exists
+func (d *Dao) AggregateToCollection(ctx context.Context, db, collection string, pipeline Pipeline, target string) error {
+	if err := d.checkWritable(); err != nil {
+		return err
+	}
+	withOut, err := pipeline.WithOut(db, collection, target)
+	if err != nil {
+		return err
+	}
+
+	cursor, err := d.client.Database(db).Collection(collection).Aggregate(ctx, withOut)
+	if err != nil {
+		return err
+	}
+	// $out returns no documents, the cursor is drained to surface errors
+	defer cursor.Close(ctx)
+	for cursor.Next(ctx) {
+	}
+	if err := cursor.Err(); err != nil {
+		return err
+	}
+
+	log.Debug().Msgf("Pipeline results written, db: %v, collection: %v, target: %v", db, collection, target)
+
+	return nil
+}
+
+// WriteDocuments writes documents as a JSON array in relaxed
+// extended JSON, one document per line
+func WriteDocuments(w io.Writer, documents []primitive.M) error {
+	if _, err := io.WriteString(w, "["); err != nil {
+		return err
+	}
+	for i, doc := range documents {
+		jsoned, err := bson.MarshalExtJSON(doc, false, false)
+		if err != nil {
+			return err
+		}
+		separator := "\n"
+		if i > 0 {
+			separator = ",\n"
+		}
+		if _, err := io.WriteString(w, separator+string(jsoned)); err != nil {
+			return err
+		}
+	}
+	_, err := io.WriteString(w, "\n]\n")
+	return err
+}
@@ -0,0 +1,146 @@
+package mongo
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/kopecmaciej/vi-mongo/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestParsePipeline(t *testing.T) {
+	pipeline, err := ParsePipeline(`[
+		{ $match: { status: "active", owner: ObjectID("5f1b7c3e9d1e8a0001a1b2c3") } },
+		{ $sort: { age: -1, name: 1 } },
+		{ $limit: 10 }
+	]`)
+	require.NoError(t, err)
+	require.Len(t, pipeline, 3)
+
+	owner, _ := primitive.ObjectIDFromHex("5f1b7c3e9d1e8a0001a1b2c3")
+	match := pipeline[0][0].Value.(primitive.D)
+	assert.Equal(t, primitive.E{Key: "owner", Value: owner}, match[1])
+	// order of sort keys is kept
+	assert.Equal(t, primitive.D{{Key: "age", Value: int32(-1)}, {Key: "name", Value: int32(1)}}, pipeline[1][0].Value)
+
+	empty, err := ParsePipeline("  ")
+	require.NoError(t, err)
+	assert.Empty(t, empty)
+
+	_, err = ParsePipeline(`[{ $match: {} }, { status: "active" }]`)
+	assert.ErrorContains(t, err, "stage 2")
+	_, err = ParsePipeline(`{ $match: {} }`)
+	assert.Error(t, err)
+}
+
+func TestPipelineString(t *testing.T) {
+	pipeline, err := ParsePipeline(`[{ $match: { a: 1 } }, { $sort: { b: -1, a: 1 } }]`)
+	require.NoError(t, err)
+
+	assert.Equal(t, `[{"$match":{"a":1}}, {"$sort":{"b":-1,"a":1}}]`, pipeline.String())
+
+	parsed, err := ParsePipeline(pipeline.String())
+	require.NoError(t, err)
+	assert.Equal(t, pipeline, parsed)
+}
+
+func TestPipelineWithOut(t *testing.T) {
+	pipeline := Pipeline{{{Key: "$match", Value: primitive.D{}}}}
+
+	withOut, err := pipeline.WithOut("shop", "orders", "orders_summary")
+	require.NoError(t, err)
+	require.Len(t, withOut, 2)
+	assert.Equal(t, primitive.D{{Key: "$out", Value: primitive.D{
+		{Key: "db", Value: "shop"},
+		{Key: "coll", Value: "orders_summary"},
+	}}}, withOut[1])
+	assert.Len(t, pipeline, 1)
+
+	_, err = pipeline.WithOut("shop", "orders", "orders")
+	assert.ErrorContains(t, err, "different")
+	_, err = pipeline.WithOut("shop", "orders", "system.views")
+	assert.Error(t, err)
+	_, err = withOut.WithOut("shop", "orders", "other")
+	assert.ErrorContains(t, err, "$out")
+}
+
+func TestValidateCollectionName(t *testing.T) {
+	assert.NoError(t, ValidateCollectionName("orders_2024"))
+	assert.Error(t, ValidateCollectionName(""))
+	assert.Error(t, ValidateCollectionName("system.users"))
+	assert.Error(t, ValidateCollectionName("price$"))
+}
+
+func TestWriteDocuments(t *testing.T) {
+	id := primitive.NewObjectID()
+	var buf bytes.Buffer
+	require.NoError(t, WriteDocuments(&buf, []primitive.M{{"_id": id}, {"count": int32(2)}}))
+
+	assert.Equal(t, "[\n{\"_id\":{\"$oid\":\""+id.Hex()+"\"}},\n{\"count\":2}\n]\n", buf.String())
+
+	var wrapper struct {
+		Documents []primitive.M `bson:"documents"`
+	}
+	require.NoError(t, bson.UnmarshalExtJSON([]byte(`{"documents": `+buf.String()+`}`), false, &wrapper))
+	assert.Equal(t, id, wrapper.Documents[0]["_id"])
+
+	buf.Reset()
+	require.NoError(t, WriteDocuments(&buf, nil))
+	assert.Equal(t, "[\n]\n", buf.String())
+}
+
+func TestPipelinePaged(t *testing.T) {
+	pipeline, err := ParsePipeline(`[{ $group: { _id: "$status", count: { $sum: 1 } } }]`)
+	require.NoError(t, err)
+
+	paged, err := pipeline.Paged(20, 10)
+	require.NoError(t, err)
+	require.Len(t, paged, 3)
+	assert.Equal(t, primitive.D{{Key: "$skip", Value: int64(20)}}, paged[1])
+	assert.Equal(t, primitive.D{{Key: "$limit", Value: int64(10)}}, paged[2])
+	// the pipeline itself is not changed
+	assert.Len(t, pipeline, 1)
+
+	count := pipeline.Count()
+	require.Len(t, count, 2)
+	assert.Equal(t, "$count", count[1][0].Key)
+
+	withOut, err := pipeline.WithOut("shop", "orders", "summary")
+	require.NoError(t, err)
+	_, err = withOut.Paged(0, 10)
+	assert.ErrorContains(t, err, "$out")
+}
+
+func TestStreamDocumentsRejectsWrites(t *testing.T) {
+	pipeline, err := ParsePipeline(`[{ $match: {} }, { $merge: { into: "summary" } }]`)
+	require.NoError(t, err)
+
+	// the pipeline is rejected before the collection is used
+	dao := &Dao{Config: &config.MongoConfig{ReadOnly: true}}
+	state := &CollectionState{Db: "shop", Coll: "orders"}
+	err = dao.StreamDocuments(context.Background(), state, nil, nil, pipeline, func(primitive.M) error {
+		t.Fatal("no document expected")
+		return nil
+	})
+	assert.ErrorContains(t, err, "$merge")
+}
+
+func TestAppendStages(t *testing.T) {
+	assert.Equal(t, "[\n  { $match: {} }\n]", AppendStages("", "{ $match: {} }"))
+	assert.Equal(t, "[\n  { $match: {} }\n]", AppendStages("[ ]", "{ $match: {} }"))
+	assert.Equal(t,
+		"[\n  { $match: {} },\n  { $limit: 10 }\n]",
+		AppendStages("[\n  { $match: {} },\n]", "{ $limit: 10 }"))
+	// templates are arrays of stages
+	assert.Equal(t,
+		"[{ $match: {} },\n  { $sort: { a: 1 } },\n  { $limit: 1 }\n]",
+		AppendStages("[{ $match: {} }]", "[\n  { $sort: { a: 1 } },\n  { $limit: 1 }\n]"))
+
+	pipeline, err := ParseShellPipeline(AppendStages(AppendStages("", "{ $match: { a: 1 } }"), "{ $limit: 5 }"))
+	require.NoError(t, err)
+	assert.Len(t, pipeline, 2)
+}
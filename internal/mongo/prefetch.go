@@ -0,0 +1,104 @@
+package mongo
+
+import (
+	"context"
+	"sync"
+)
+
+// PageLoader loads documents of the page and the number of all documents
+type PageLoader func(ctx context.Context) ([]*LazyDocument, int64, error)
+
+// PagePrefetcher loads a single page in the background, so it can be
+// shown without waiting when the user moves to it. Only one page is kept,
+// starting a new prefetch cancels the previous one.
+type PagePrefetcher struct {
+	mu     sync.Mutex
+	query  PageQuery
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	documents []*LazyDocument
+	count     int64
+	err       error
+}
+
+func NewPagePrefetcher() *PagePrefetcher {
+	return &PagePrefetcher{}
+}
+
+// Start starts loading the page identified by the query,
+// loading is cancelled when ctx is done
+func (p *PagePrefetcher) Start(ctx context.Context, query PageQuery, load PageLoader) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.cancelLocked()
+
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	p.query = query
+	p.cancel = cancel
+	p.done = done
+
+	go func() {
+		defer close(done)
+		documents, count, err := load(ctx)
+
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		// prefetch could be replaced while loading
+		if p.done == done {
+			p.documents, p.count, p.err = documents, count, err
+		}
+	}()
+}
+
+// Take returns the prefetched page if it matches the query, waiting
+// for it if it's still loading. Prefetch is cleared in both cases,
+// as a page that doesn't match was made outdated by the query change.
+func (p *PagePrefetcher) Take(query PageQuery) ([]*LazyDocument, int64, bool) {
+	p.mu.Lock()
+	if p.done == nil {
+		p.mu.Unlock()
+		return nil, 0, false
+	}
+	if p.query != query {
+		p.cancelLocked()
+		p.mu.Unlock()
+		return nil, 0, false
+	}
+	done := p.done
+	p.mu.Unlock()
+
+	<-done
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.done != done {
+		return nil, 0, false
+	}
+	documents, count, err := p.documents, p.count, p.err
+	p.cancelLocked()
+	if err != nil || len(documents) == 0 {
+		return nil, 0, false
+	}
+	return documents, count, true
+}
+
+// Cancel stops loading and drops the prefetched page
+func (p *PagePrefetcher) Cancel() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.cancelLocked()
+}
+
+func (p *PagePrefetcher) cancelLocked() {
+	if p.cancel != nil {
+		p.cancel()
+	}
+	p.query = PageQuery{}
+	p.cancel = nil
+	p.done = nil
+	p.documents = nil
+	p.count = 0
+	p.err = nil
+}
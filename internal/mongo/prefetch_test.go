@@ -0,0 +1,62 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestPagePrefetcher(t *testing.T) {
+	state := &CollectionState{Db: "shop", Coll: "users", Limit: 10}
+	next := state.NextPage()
+	assert.Equal(t, int64(10), next.Page)
+
+	page := []*LazyDocument{NewDecodedDocument(primitive.M{"name": "John"})}
+	loader := func(ctx context.Context) ([]*LazyDocument, int64, error) {
+		return page, 25, nil
+	}
+
+	prefetcher := NewPagePrefetcher()
+	prefetcher.Start(context.Background(), next.Query(), loader)
+
+	documents, count, ok := prefetcher.Take(next.Query())
+	assert.True(t, ok)
+	assert.Equal(t, page, documents)
+	assert.Equal(t, int64(25), count)
+
+	// page can be taken only once
+	_, _, ok = prefetcher.Take(next.Query())
+	assert.False(t, ok)
+}
+
+func TestPagePrefetcher_CancelledOnQueryChange(t *testing.T) {
+	state := &CollectionState{Db: "shop", Coll: "users", Limit: 10}
+	next := state.NextPage()
+
+	cancelled := make(chan struct{})
+	prefetcher := NewPagePrefetcher()
+	prefetcher.Start(context.Background(), next.Query(), func(ctx context.Context) ([]*LazyDocument, int64, error) {
+		<-ctx.Done()
+		close(cancelled)
+		return nil, 0, ctx.Err()
+	})
+
+	state.UpdateFilter(`{"name":"John"}`)
+	_, _, ok := prefetcher.Take(state.NextPage().Query())
+	assert.False(t, ok)
+	<-cancelled
+}
+
+func TestPagePrefetcher_Error(t *testing.T) {
+	query := PageQuery{Db: "shop", Coll: "users", Page: 10, Limit: 10}
+	prefetcher := NewPagePrefetcher()
+	prefetcher.Start(context.Background(), query, func(ctx context.Context) ([]*LazyDocument, int64, error) {
+		return nil, 0, errors.New("connection lost")
+	})
+
+	_, _, ok := prefetcher.Take(query)
+	assert.False(t, ok)
+}
@@ -0,0 +1,135 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/rs/zerolog/log"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Actions checked before the operation is started, names are the same
+// as the names of privilege actions in MongoDB
+const (
+	ActionInsert           = "insert"
+	ActionUpdate           = "update"
+	ActionRemove           = "remove"
+	ActionCreateCollection = "createCollection"
+	ActionDropCollection   = "dropCollection"
+	ActionDropDatabase     = "dropDatabase"
+	ActionCreateIndex      = "createIndex"
+	ActionCollMod          = "collMod"
+)
+
+// ErrNotPermitted is returned if the user lacks the privilege for the action
+var ErrNotPermitted = errors.New("not permitted")
+
+// PrivilegeResource is the resource the actions of the privilege apply to,
+// empty db or collection means any database or collection
+type PrivilegeResource struct {
+	Db          string `bson:"db"`
+	Collection  string `bson:"collection"`
+	Cluster     bool   `bson:"cluster"`
+	AnyResource bool   `bson:"anyResource"`
+}
+
+// Privilege is a set of actions allowed on the resource
+type Privilege struct {
+	Resource PrivilegeResource `bson:"resource"`
+	Actions  []string          `bson:"actions"`
+}
+
+// Privileges of the authenticated user, privileges are not
+// enforced if the user is not authenticated, e.g. auth is disabled
+type Privileges struct {
+	Enforced   bool
+	Privileges []Privilege
+}
+
+type connectionStatus struct {
+	AuthInfo struct {
+		AuthenticatedUsers          []primitive.M `bson:"authenticatedUsers"`
+		AuthenticatedUserPrivileges []Privilege   `bson:"authenticatedUserPrivileges"`
+	} `bson:"authInfo"`
+}
+
+// Can reports whether the action is allowed on the collection,
+// empty collection means the action on the database itself
+func (p *Privileges) Can(action, db, coll string) bool {
+	if p == nil || !p.Enforced {
+		return true
+	}
+	for _, privilege := range p.Privileges {
+		if privilege.Resource.matches(db, coll) && slices.Contains(privilege.Actions, action) {
+			return true
+		}
+	}
+	return false
+}
+
+// CanAnywhere reports whether the action is allowed on any database
+func (p *Privileges) CanAnywhere(action string) bool {
+	if p == nil || !p.Enforced {
+		return true
+	}
+	for _, privilege := range p.Privileges {
+		if !privilege.Resource.Cluster && slices.Contains(privilege.Actions, action) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r PrivilegeResource) matches(db, coll string) bool {
+	switch {
+	case r.AnyResource:
+		return true
+	case r.Cluster:
+		return false
+	case r.Db != "" && r.Db != db:
+		return false
+	case r.Collection == "":
+		return true
+	default:
+		return r.Collection == coll
+	}
+}
+
+// LoadPrivileges loads privileges of the authenticated user,
+// so actions can be checked before they are started
+func (d *Dao) LoadPrivileges(ctx context.Context) error {
+	var status connectionStatus
+	command := primitive.D{{Key: "connectionStatus", Value: 1}, {Key: "showPrivileges", Value: true}}
+	if err := d.client.Database("admin").RunCommand(ctx, command).Decode(&status); err != nil {
+		return err
+	}
+
+	d.privileges.Store(&Privileges{
+		Enforced:   len(status.AuthInfo.AuthenticatedUsers) > 0,
+		Privileges: status.AuthInfo.AuthenticatedUserPrivileges,
+	})
+	log.Debug().Int("privileges", len(status.AuthInfo.AuthenticatedUserPrivileges)).Msg("Privileges loaded")
+
+	return nil
+}
+
+// Privileges returns privileges of the user,
+// nil if they were not loaded, which allows everything
+func (d *Dao) Privileges() *Privileges {
+	return d.privileges.Load()
+}
+
+// CheckPrivilege returns ErrNotPermitted if the user lacks
+// the privilege for the action on the collection
+func (d *Dao) CheckPrivilege(action, db, coll string) error {
+	if d.Privileges().Can(action, db, coll) {
+		return nil
+	}
+	namespace := db
+	if coll != "" {
+		namespace += "." + coll
+	}
+	return fmt.Errorf("%w: %s requires the %s privilege", ErrNotPermitted, namespace, action)
+}
@@ -0,0 +1,52 @@
+package mongo
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrivilegesCan(t *testing.T) {
+	privileges := &Privileges{
+		Enforced: true,
+		Privileges: []Privilege{
+			{Resource: PrivilegeResource{Db: "shop"}, Actions: []string{ActionInsert, ActionUpdate}},
+			{Resource: PrivilegeResource{Db: "shop", Collection: "orders"}, Actions: []string{ActionRemove}},
+			{Resource: PrivilegeResource{Collection: "logs"}, Actions: []string{ActionDropCollection}},
+			{Resource: PrivilegeResource{Cluster: true}, Actions: []string{ActionCreateCollection}},
+		},
+	}
+
+	assert.True(t, privileges.Can(ActionInsert, "shop", "users"))
+	assert.False(t, privileges.Can(ActionInsert, "blog", "users"))
+	assert.True(t, privileges.Can(ActionRemove, "shop", "orders"))
+	assert.False(t, privileges.Can(ActionRemove, "shop", "users"))
+	assert.True(t, privileges.Can(ActionDropCollection, "blog", "logs"))
+	assert.False(t, privileges.Can(ActionCreateCollection, "shop", "users"))
+
+	assert.True(t, privileges.CanAnywhere(ActionRemove))
+	assert.False(t, privileges.CanAnywhere(ActionCreateCollection))
+}
+
+func TestPrivilegesNotEnforced(t *testing.T) {
+	var notLoaded *Privileges
+	assert.True(t, notLoaded.Can(ActionRemove, "shop", "users"))
+	assert.True(t, (&Privileges{}).Can(ActionRemove, "shop", "users"))
+
+	anyResource := &Privileges{
+		Enforced:   true,
+		Privileges: []Privilege{{Resource: PrivilegeResource{AnyResource: true}, Actions: []string{ActionRemove}}},
+	}
+	assert.True(t, anyResource.Can(ActionRemove, "shop", "users"))
+}
+
+func TestCheckPrivilege(t *testing.T) {
+	dao := &Dao{}
+	assert.NoError(t, dao.CheckPrivilege(ActionInsert, "shop", "users"))
+
+	dao.privileges.Store(&Privileges{Enforced: true})
+	err := dao.CheckPrivilege(ActionInsert, "shop", "users")
+	assert.True(t, errors.Is(err, ErrNotPermitted))
+	assert.Contains(t, err.Error(), "shop.users requires the insert privilege")
+}
@@ -0,0 +1,91 @@
+package mongo
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// QueryOptions are find options that can be tuned by the user,
+// zero values leave the defaults of the driver and the server
+type QueryOptions struct {
+	// Limit overrides the page size
+	Limit int64
+	// Skip is added to the offset of the current page
+	Skip         int64
+	BatchSize    int32
+	AllowDiskUse bool
+	MaxTimeMS    int64
+}
+
+// Validate checks if none of the options is negative
+func (o QueryOptions) Validate() error {
+	switch {
+	case o.Limit < 0:
+		return fmt.Errorf("limit can't be negative")
+	case o.Skip < 0:
+		return fmt.Errorf("skip can't be negative")
+	case o.BatchSize < 0:
+		return fmt.Errorf("batch size can't be negative")
+	case o.MaxTimeMS < 0:
+		return fmt.Errorf("max time can't be negative")
+	}
+	return nil
+}
+
+// IsZero returns true if no option is set
+func (o QueryOptions) IsZero() bool {
+	return o == QueryOptions{}
+}
+
+// String returns options that are set, e.g. "skip: 10, allowDiskUse"
+func (o QueryOptions) String() string {
+	var parts []string
+	if o.Limit > 0 {
+		parts = append(parts, fmt.Sprintf("limit: %d", o.Limit))
+	}
+	if o.Skip > 0 {
+		parts = append(parts, fmt.Sprintf("skip: %d", o.Skip))
+	}
+	if o.BatchSize > 0 {
+		parts = append(parts, fmt.Sprintf("batchSize: %d", o.BatchSize))
+	}
+	if o.AllowDiskUse {
+		parts = append(parts, "allowDiskUse")
+	}
+	if o.MaxTimeMS > 0 {
+		parts = append(parts, fmt.Sprintf("maxTimeMS: %d", o.MaxTimeMS))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// FindOptions returns options for the find of the page starting at
+// given offset, with given page size
+func (o QueryOptions) FindOptions(offset, limit int64) *options.FindOptions {
+	opts := options.Find().SetSkip(o.Skip + offset).SetLimit(limit)
+	if o.BatchSize > 0 {
+		opts.SetBatchSize(o.BatchSize)
+	}
+	if o.AllowDiskUse {
+		opts.SetAllowDiskUse(true)
+	}
+	if o.MaxTimeMS > 0 {
+		opts.SetMaxTime(time.Duration(o.MaxTimeMS) * time.Millisecond)
+	}
+	return opts
+}
+
+// CountOptions returns options for counting documents, so the count
+// doesn't include skipped documents
+func (o QueryOptions) CountOptions() *options.CountOptions {
+	opts := options.Count()
+	if o.Skip > 0 {
+		opts.SetSkip(o.Skip)
+	}
+	if o.MaxTimeMS > 0 {
+		opts.SetMaxTime(time.Duration(o.MaxTimeMS) * time.Millisecond)
+	}
+	return opts
+}
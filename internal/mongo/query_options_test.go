@@ -0,0 +1,42 @@
+package mongo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueryOptionsValidate(t *testing.T) {
+	assert.NoError(t, QueryOptions{}.Validate())
+	assert.NoError(t, QueryOptions{Limit: 10, Skip: 5, BatchSize: 100, MaxTimeMS: 1000}.Validate())
+	assert.Error(t, QueryOptions{Skip: -1}.Validate())
+	assert.Error(t, QueryOptions{BatchSize: -1}.Validate())
+}
+
+func TestQueryOptionsString(t *testing.T) {
+	assert.Equal(t, "", QueryOptions{}.String())
+	assert.True(t, QueryOptions{}.IsZero())
+
+	opts := QueryOptions{Skip: 10, AllowDiskUse: true, MaxTimeMS: 500}
+	assert.Equal(t, "skip: 10, allowDiskUse, maxTimeMS: 500", opts.String())
+	assert.False(t, opts.IsZero())
+}
+
+func TestQueryOptionsFindOptions(t *testing.T) {
+	find := QueryOptions{}.FindOptions(20, 10)
+	assert.Equal(t, int64(20), *find.Skip)
+	assert.Equal(t, int64(10), *find.Limit)
+	assert.Nil(t, find.BatchSize)
+	assert.Nil(t, find.AllowDiskUse)
+	assert.Nil(t, find.MaxTime)
+
+	find = QueryOptions{Skip: 5, BatchSize: 50, AllowDiskUse: true, MaxTimeMS: 200}.FindOptions(20, 10)
+	assert.Equal(t, int64(25), *find.Skip)
+	assert.Equal(t, int32(50), *find.BatchSize)
+	assert.True(t, *find.AllowDiskUse)
+	assert.Equal(t, 200*time.Millisecond, *find.MaxTime)
+
+	count := QueryOptions{Skip: 5}.CountOptions()
+	assert.Equal(t, int64(5), *count.Skip)
+}
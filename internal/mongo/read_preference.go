@@ -0,0 +1,37 @@
+package mongo
+
+import (
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+)
+
+const (
+	ReadPrimary            = "primary"
+	ReadSecondaryPreferred = "secondaryPreferred"
+)
+
+// SetReadSecondary switches reads of documents between the primary
+// and secondaryPreferred, the connection is not changed
+func (d *Dao) SetReadSecondary(secondary bool) {
+	d.readSecondary.Store(secondary)
+}
+
+// ReadPreference returns the name of the read preference used for reads
+func (d *Dao) ReadPreference() string {
+	if d.readSecondary.Load() {
+		return ReadSecondaryPreferred
+	}
+	return ReadPrimary
+}
+
+// readCollection returns the collection used for reads,
+// which follows the read preference set at runtime.
+// Reads done right before writes should use the primary instead.
+func (d *Dao) readCollection(db, collection string) *mongo.Collection {
+	if !d.readSecondary.Load() {
+		return d.client.Database(db).Collection(collection)
+	}
+	opts := options.Collection().SetReadPreference(readpref.SecondaryPreferred())
+	return d.client.Database(db).Collection(collection, opts)
+}
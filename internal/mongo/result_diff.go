@@ -0,0 +1,145 @@
+package mongo
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+)
+
+// DocumentChange is how the document differs from the previous run
+type DocumentChange int
+
+const (
+	Unchanged DocumentChange = iota
+	Added
+	Changed
+	Removed
+)
+
+// String returns the name of the change, empty for unchanged documents
+func (c DocumentChange) String() string {
+	switch c {
+	case Added:
+		return "added"
+	case Changed:
+		return "changed"
+	case Removed:
+		return "removed"
+	}
+	return ""
+}
+
+// Marker returns the symbol of the change shown next to the document,
+// the same as in the counts of changes, e.g. "+" for added documents
+func (c DocumentChange) Marker() string {
+	switch c {
+	case Added:
+		return "+"
+	case Changed:
+		return "~"
+	case Removed:
+		return "-"
+	}
+	return ""
+}
+
+// ResultDiff is the difference between two runs of the same query,
+// documents are matched by _id
+type ResultDiff struct {
+	changes map[string]DocumentChange
+	// Removed are documents of the previous run missing
+	// in the current one, in their previous order
+	Removed []*LazyDocument
+}
+
+// DiffResults compares documents of the current run with the previous one
+func DiffResults(previous, current []*LazyDocument) *ResultDiff {
+	diff := &ResultDiff{changes: make(map[string]DocumentChange)}
+
+	before := make(map[string]*LazyDocument, len(previous))
+	for _, doc := range previous {
+		before[diffKey(doc)] = doc
+	}
+	seen := make(map[string]bool, len(current))
+	for _, doc := range current {
+		key := diffKey(doc)
+		seen[key] = true
+		old, ok := before[key]
+		switch {
+		case !ok:
+			diff.changes[key] = Added
+		case !sameDocument(old, doc):
+			diff.changes[key] = Changed
+		}
+	}
+	for _, doc := range previous {
+		if key := diffKey(doc); !seen[key] {
+			diff.changes[key] = Removed
+			diff.Removed = append(diff.Removed, doc)
+		}
+	}
+	return diff
+}
+
+// Change returns how the document differs from the previous run
+func (d *ResultDiff) Change(doc *LazyDocument) DocumentChange {
+	if d == nil {
+		return Unchanged
+	}
+	return d.changes[diffKey(doc)]
+}
+
+// Counts returns the number of added, changed and removed documents
+func (d *ResultDiff) Counts() (added, changed, removed int) {
+	if d == nil {
+		return 0, 0, 0
+	}
+	for _, change := range d.changes {
+		switch change {
+		case Added:
+			added++
+		case Changed:
+			changed++
+		}
+	}
+	return added, changed, len(d.Removed)
+}
+
+// OnlyChanges returns added and changed documents of the current run,
+// followed by removed ones
+func (d *ResultDiff) OnlyChanges(current []*LazyDocument) []*LazyDocument {
+	changed := []*LazyDocument{}
+	for _, doc := range current {
+		if d.Change(doc) != Unchanged {
+			changed = append(changed, doc)
+		}
+	}
+	if d == nil {
+		return changed
+	}
+	return append(changed, d.Removed...)
+}
+
+// diffKey returns the key of the document by its _id, the type is
+// included, so e.g. string "1" and number 1 are different documents
+func diffKey(doc *LazyDocument) string {
+	id, _ := doc.Get("_id")
+	return fmt.Sprintf("%T:%v", id, id)
+}
+
+// sameDocument compares raw BSON of documents if both have it,
+// otherwise decoded documents are compared
+func sameDocument(a, b *LazyDocument) bool {
+	if a.raw != nil && b.raw != nil {
+		return bytes.Equal(a.raw, b.raw)
+	}
+	aDoc, err := a.Decode()
+	if err != nil {
+		return false
+	}
+	bDoc, err := b.Decode()
+	if err != nil {
+		return false
+	}
+	return reflect.DeepEqual(aDoc, bDoc)
+}
@@ -0,0 +1,60 @@
+package mongo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func rawDocument(t *testing.T, doc primitive.D) *LazyDocument {
+	raw, err := bson.Marshal(doc)
+	require.NoError(t, err)
+	return NewLazyDocument(raw)
+}
+
+func TestDiffResults(t *testing.T) {
+	previous := []*LazyDocument{
+		rawDocument(t, primitive.D{{Key: "_id", Value: int32(1)}, {Key: "status", Value: "failed"}}),
+		rawDocument(t, primitive.D{{Key: "_id", Value: int32(2)}, {Key: "status", Value: "running"}}),
+		rawDocument(t, primitive.D{{Key: "_id", Value: int32(3)}, {Key: "status", Value: "done"}}),
+	}
+	current := []*LazyDocument{
+		rawDocument(t, primitive.D{{Key: "_id", Value: int32(1)}, {Key: "status", Value: "failed"}}),
+		rawDocument(t, primitive.D{{Key: "_id", Value: int32(2)}, {Key: "status", Value: "done"}}),
+		rawDocument(t, primitive.D{{Key: "_id", Value: "3"}, {Key: "status", Value: "new"}}),
+	}
+
+	diff := DiffResults(previous, current)
+	assert.Equal(t, Unchanged, diff.Change(current[0]))
+	assert.Equal(t, Changed, diff.Change(current[1]))
+	// _id of a different type is a different document
+	assert.Equal(t, Added, diff.Change(current[2]))
+	assert.Equal(t, Removed, diff.Change(previous[2]))
+	require.Len(t, diff.Removed, 1)
+
+	added, changed, removed := diff.Counts()
+	assert.Equal(t, []int{1, 1, 1}, []int{added, changed, removed})
+	assert.Equal(t, []*LazyDocument{current[1], current[2], previous[2]}, diff.OnlyChanges(current))
+}
+
+func TestDiffResultsOfDecodedDocuments(t *testing.T) {
+	previous := []*LazyDocument{NewDecodedDocument(primitive.M{"_id": int32(1), "tags": primitive.A{"a"}})}
+	same := []*LazyDocument{rawDocument(t, primitive.D{{Key: "_id", Value: int32(1)}, {Key: "tags", Value: primitive.A{"a"}}})}
+
+	assert.Equal(t, Unchanged, DiffResults(previous, same).Change(same[0]))
+
+	var noDiff *ResultDiff
+	assert.Equal(t, Unchanged, noDiff.Change(same[0]))
+	assert.Empty(t, noDiff.OnlyChanges(same))
+}
+
+func TestDocumentChangeText(t *testing.T) {
+	assert.Equal(t, "", Unchanged.String())
+	assert.Equal(t, "", Unchanged.Marker())
+	assert.Equal(t, "changed", Changed.String())
+	assert.Equal(t, "~", Changed.Marker())
+	assert.Equal(t, "-", Removed.Marker())
+}
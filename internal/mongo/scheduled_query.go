@@ -0,0 +1,153 @@
+package mongo
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kopecmaciej/vi-mongo/internal/config"
+	"github.com/kopecmaciej/vi-mongo/internal/util"
+	"github.com/rs/zerolog/log"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ScheduledQueryResult is the result of a single run of the scheduled query
+type ScheduledQueryResult struct {
+	Name      string
+	Count     int64
+	Threshold util.Threshold
+	// Alert is set when the count crosses the threshold
+	Alert bool
+	Err   error
+}
+
+// scheduledQuery is the parsed config of the scheduled query
+type scheduledQuery struct {
+	name      string
+	db        string
+	coll      string
+	filter    primitive.M
+	interval  time.Duration
+	threshold util.Threshold
+}
+
+// QueryScheduler counts documents of scheduled queries in the background,
+// every query on its own interval, as long as the app is open
+type QueryScheduler struct {
+	dao     *Dao
+	queries []scheduledQuery
+
+	// onResult is called from the scheduler goroutines after every run
+	onResult func(ScheduledQueryResult)
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+}
+
+// NewQueryScheduler returns a scheduler which is not started yet,
+// queries which can't be parsed are skipped and returned as an error
+func NewQueryScheduler(dao *Dao, configs []config.ScheduledQueryConfig, onResult func(ScheduledQueryResult)) (*QueryScheduler, error) {
+	s := &QueryScheduler{dao: dao, onResult: onResult}
+	var errs []string
+	for _, cfg := range configs {
+		query, err := parseScheduledQuery(cfg)
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		s.queries = append(s.queries, query)
+	}
+	if len(errs) > 0 {
+		return s, fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+	return s, nil
+}
+
+func parseScheduledQuery(cfg config.ScheduledQueryConfig) (scheduledQuery, error) {
+	db, coll, ok := strings.Cut(cfg.Namespace, ".")
+	if !ok || db == "" || coll == "" {
+		return scheduledQuery{}, fmt.Errorf("scheduled query %q: namespace must be \"db.collection\"", cfg.Name)
+	}
+	if cfg.Interval <= 0 {
+		return scheduledQuery{}, fmt.Errorf("scheduled query %q: interval must be positive", cfg.Name)
+	}
+	filter, err := ParseStringQuery(cfg.Filter)
+	if err != nil {
+		return scheduledQuery{}, fmt.Errorf("scheduled query %q: %w", cfg.Name, err)
+	}
+	threshold, err := util.ParseThreshold(cfg.Threshold)
+	if err != nil {
+		return scheduledQuery{}, fmt.Errorf("scheduled query %q: %w", cfg.Name, err)
+	}
+
+	return scheduledQuery{
+		name:      cfg.Name,
+		db:        db,
+		coll:      coll,
+		filter:    filter,
+		interval:  time.Duration(cfg.Interval) * time.Second,
+		threshold: threshold,
+	}, nil
+}
+
+// Start runs queries in the background until Stop is called or ctx
+// is done, it does nothing if the scheduler is already running
+func (s *QueryScheduler) Start(ctx context.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.queries) == 0 || s.cancel != nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	s.cancel = cancel
+	for _, query := range s.queries {
+		go s.run(ctx, query)
+	}
+}
+
+// Stop stops running queries, runs in progress are cancelled
+func (s *QueryScheduler) Stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.cancel != nil {
+		s.cancel()
+		s.cancel = nil
+	}
+}
+
+func (s *QueryScheduler) run(ctx context.Context, query scheduledQuery) {
+	alert := false
+	wait := time.Duration(0)
+	for {
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		wait = query.interval
+
+		runCtx, cancel := context.WithTimeout(ctx, query.interval)
+		count, err := s.dao.readCollection(query.db, query.coll).CountDocuments(runCtx, query.filter)
+		cancel()
+		if ctx.Err() != nil {
+			return
+		}
+
+		result := ScheduledQueryResult{Name: query.name, Count: count, Threshold: query.threshold, Err: err}
+		if err != nil {
+			log.Debug().Err(err).Str("query", query.name).Msg("Error running scheduled query")
+		} else {
+			result.Alert = query.threshold.Crossed(count)
+			if result.Alert && !alert {
+				log.Warn().Str("query", query.name).Int64("count", count).Msgf("Scheduled query crossed threshold %s", query.threshold)
+			}
+			alert = result.Alert
+		}
+		s.onResult(result)
+	}
+}
@@ -0,0 +1,45 @@
+package mongo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kopecmaciej/vi-mongo/internal/config"
+	"github.com/kopecmaciej/vi-mongo/internal/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseScheduledQuery(t *testing.T) {
+	query, err := parseScheduledQuery(config.ScheduledQueryConfig{
+		Name:      "failed jobs",
+		Namespace: "app.jobs.queue",
+		Filter:    `{ status: "failed" }`,
+		Interval:  30,
+		Threshold: "> 0",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "app", query.db)
+	assert.Equal(t, "jobs.queue", query.coll)
+	assert.Equal(t, "failed", query.filter["status"])
+	assert.Equal(t, 30*time.Second, query.interval)
+	assert.Equal(t, util.Threshold{Op: ">", Value: 0}, query.threshold)
+
+	query, err = parseScheduledQuery(config.ScheduledQueryConfig{Name: "all", Namespace: "app.jobs", Interval: 5, Threshold: "100"})
+	require.NoError(t, err)
+	assert.Empty(t, query.filter)
+}
+
+func TestNewQuerySchedulerSkipsInvalidQueries(t *testing.T) {
+	scheduler, err := NewQueryScheduler(nil, []config.ScheduledQueryConfig{
+		{Name: "valid", Namespace: "app.jobs", Interval: 5, Threshold: "> 0"},
+		{Name: "no interval", Namespace: "app.jobs", Threshold: "> 0"},
+		{Name: "bad filter", Namespace: "app.jobs", Filter: "{ status: ", Interval: 5, Threshold: "> 0"},
+	}, nil)
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `"no interval"`)
+	assert.Contains(t, err.Error(), `"bad filter"`)
+	require.Len(t, scheduler.queries, 1)
+	assert.Equal(t, "valid", scheduler.queries[0].name)
+}
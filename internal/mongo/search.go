@@ -0,0 +1,198 @@
+package mongo
+
+import (
+	"context"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Modes of Atlas Search query, each builds a different operator
+const (
+	SearchText         = "text"
+	SearchAutocomplete = "autocomplete"
+	SearchFuzzy        = "fuzzy"
+)
+
+const (
+	searchScoreField      = "__score"
+	searchHighlightsField = "__highlights"
+	// searchFuzzyMaxEdits is the max number of single character
+	// edits needed to match the term, 2 is the max allowed value
+	searchFuzzyMaxEdits = 2
+)
+
+// SearchIndex is an Atlas Search index of the collection
+type SearchIndex struct {
+	Name      string `bson:"name"`
+	Type      string `bson:"type"`
+	Status    string `bson:"status"`
+	Queryable bool   `bson:"queryable"`
+	// LatestDefinition is the definition of fields of the index
+	LatestDefinition primitive.M `bson:"latestDefinition"`
+}
+
+// IsSearch returns true for indexes used by $search, indexes
+// created before index types were introduced have no type
+func (i SearchIndex) IsSearch() bool {
+	return i.Type == "" || i.Type == "search"
+}
+
+// SearchQuery is a query of Atlas Search index
+type SearchQuery struct {
+	Index string
+	Mode  string
+	Query string
+	// Path is the searched field, all indexed fields are searched
+	// if it's empty, except autocomplete which requires it
+	Path string
+}
+
+// SearchHighlight is a highlighted part of the field matching the query
+type SearchHighlight struct {
+	Path  string
+	Texts []HighlightText
+}
+
+// HighlightText is a part of the highlight, Hit is true for matched terms
+type HighlightText struct {
+	Value string
+	Hit   bool
+}
+
+// SearchResult is a document found by Atlas Search with its score
+// and highlights, which are not part of the document
+type SearchResult struct {
+	Document   primitive.M
+	Score      float64
+	Highlights []SearchHighlight
+}
+
+// ListSearchIndexes returns Atlas Search indexes of the collection,
+// it fails on servers which don't support Atlas Search
+func (d *Dao) ListSearchIndexes(ctx context.Context, db, collection string) ([]SearchIndex, error) {
+	cursor, err := d.client.Database(db).Collection(collection).SearchIndexes().List(ctx, options.SearchIndexes())
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var indexes []SearchIndex
+	if err := cursor.All(ctx, &indexes); err != nil {
+		return nil, err
+	}
+	return indexes, nil
+}
+
+// Search runs the query and returns matching documents, best matches first
+func (d *Dao) Search(ctx context.Context, db, collection string, query SearchQuery, limit int64) ([]SearchResult, error) {
+	pipeline, err := query.Pipeline(limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return d.aggregateSearch(ctx, db, collection, pipeline)
+}
+
+// aggregateSearch runs the pipeline of the search and
+// returns found documents with their scores
+func (d *Dao) aggregateSearch(ctx context.Context, db, collection string, pipeline []primitive.M) ([]SearchResult, error) {
+	cursor, err := d.readCollection(db, collection).Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var documents []primitive.M
+	if err := cursor.All(ctx, &documents); err != nil {
+		return nil, err
+	}
+
+	results := make([]SearchResult, 0, len(documents))
+	for _, doc := range documents {
+		results = append(results, newSearchResult(doc))
+	}
+	return results, nil
+}
+
+// Pipeline returns the aggregation pipeline of the query,
+// score and highlights are added to the found documents
+func (q SearchQuery) Pipeline(limit int64) ([]primitive.M, error) {
+	if q.Query == "" {
+		return nil, fmt.Errorf("search query cannot be empty")
+	}
+
+	var path interface{} = primitive.M{"wildcard": "*"}
+	if q.Path != "" {
+		path = q.Path
+	}
+
+	search := primitive.M{}
+	if q.Index != "" {
+		search["index"] = q.Index
+	}
+	meta := primitive.M{searchScoreField: primitive.M{"$meta": "searchScore"}}
+
+	switch q.Mode {
+	case SearchText, "":
+		search["text"] = primitive.M{"query": q.Query, "path": path}
+	case SearchFuzzy:
+		search["text"] = primitive.M{"query": q.Query, "path": path, "fuzzy": primitive.M{"maxEdits": searchFuzzyMaxEdits}}
+	case SearchAutocomplete:
+		if q.Path == "" {
+			return nil, fmt.Errorf("autocomplete requires the path of the field")
+		}
+		search["autocomplete"] = primitive.M{"query": q.Query, "path": q.Path}
+	default:
+		return nil, fmt.Errorf("unknown search mode %q", q.Mode)
+	}
+
+	// highlighting is not supported by autocomplete
+	if q.Mode != SearchAutocomplete {
+		search["highlight"] = primitive.M{"path": path}
+		meta[searchHighlightsField] = primitive.M{"$meta": "searchHighlights"}
+	}
+
+	pipeline := []primitive.M{{"$search": search}}
+	if limit > 0 {
+		pipeline = append(pipeline, primitive.M{"$limit": limit})
+	}
+	return append(pipeline, primitive.M{"$addFields": meta}), nil
+}
+
+// newSearchResult moves the score and highlights added
+// by the pipeline from the document to the result
+func newSearchResult(doc primitive.M) SearchResult {
+	result := SearchResult{Document: doc}
+	switch score := doc[searchScoreField].(type) {
+	case float64:
+		result.Score = score
+	case int32:
+		result.Score = float64(score)
+	}
+
+	highlights, _ := doc[searchHighlightsField].(primitive.A)
+	for _, h := range highlights {
+		highlight, ok := h.(primitive.M)
+		if !ok {
+			continue
+		}
+		path, _ := highlight["path"].(string)
+		texts, _ := highlight["texts"].(primitive.A)
+		parsed := SearchHighlight{Path: path}
+		for _, t := range texts {
+			text, ok := t.(primitive.M)
+			if !ok {
+				continue
+			}
+			value, _ := text["value"].(string)
+			parsed.Texts = append(parsed.Texts, HighlightText{Value: value, Hit: text["type"] == "hit"})
+		}
+		result.Highlights = append(result.Highlights, parsed)
+	}
+
+	delete(doc, searchScoreField)
+	delete(doc, searchHighlightsField)
+	return result
+}
@@ -0,0 +1,68 @@
+package mongo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestSearchQueryPipeline(t *testing.T) {
+	pipeline, err := SearchQuery{Index: "default", Mode: SearchFuzzy, Query: "mongo"}.Pipeline(10)
+	require.NoError(t, err)
+	require.Len(t, pipeline, 3)
+
+	search := pipeline[0]["$search"].(primitive.M)
+	assert.Equal(t, "default", search["index"])
+	assert.Equal(t, primitive.M{
+		"query": "mongo",
+		"path":  primitive.M{"wildcard": "*"},
+		"fuzzy": primitive.M{"maxEdits": searchFuzzyMaxEdits},
+	}, search["text"])
+	assert.Equal(t, primitive.M{"path": primitive.M{"wildcard": "*"}}, search["highlight"])
+	assert.Equal(t, primitive.M{"$limit": int64(10)}, pipeline[1])
+	assert.Contains(t, pipeline[2]["$addFields"], searchHighlightsField)
+}
+
+func TestSearchQueryAutocomplete(t *testing.T) {
+	_, err := SearchQuery{Mode: SearchAutocomplete, Query: "mon"}.Pipeline(10)
+	assert.Error(t, err)
+
+	pipeline, err := SearchQuery{Mode: SearchAutocomplete, Query: "mon", Path: "title"}.Pipeline(0)
+	require.NoError(t, err)
+	require.Len(t, pipeline, 2)
+	search := pipeline[0]["$search"].(primitive.M)
+	assert.Equal(t, primitive.M{"query": "mon", "path": "title"}, search["autocomplete"])
+	assert.NotContains(t, search, "highlight")
+	assert.NotContains(t, pipeline[1]["$addFields"], searchHighlightsField)
+}
+
+func TestSearchQueryInvalid(t *testing.T) {
+	_, err := SearchQuery{Mode: SearchText}.Pipeline(10)
+	assert.Error(t, err)
+	_, err = SearchQuery{Mode: "regex", Query: "mongo"}.Pipeline(10)
+	assert.Error(t, err)
+}
+
+func TestNewSearchResult(t *testing.T) {
+	doc := primitive.M{
+		"_id":            1,
+		"title":          "MongoDB in action",
+		searchScoreField: 1.5,
+		searchHighlightsField: primitive.A{
+			primitive.M{"path": "title", "texts": primitive.A{
+				primitive.M{"value": "MongoDB", "type": "hit"},
+				primitive.M{"value": " in action", "type": "text"},
+			}},
+		},
+	}
+
+	result := newSearchResult(doc)
+	assert.Equal(t, 1.5, result.Score)
+	assert.Equal(t, []SearchHighlight{{Path: "title", Texts: []HighlightText{
+		{Value: "MongoDB", Hit: true},
+		{Value: " in action"},
+	}}}, result.Highlights)
+	assert.Equal(t, primitive.M{"_id": 1, "title": "MongoDB in action"}, result.Document)
+}
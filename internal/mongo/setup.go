@@ -28,6 +28,13 @@ func (m *Client) Connect() error {
 
 	uri := m.Config.GetUri()
 	opts := options.Client().ApplyURI(uri)
+	if m.Config.TLS != nil {
+		tlsConfig, err := TLSClientConfig(m.Config.TLS)
+		if err != nil {
+			return err
+		}
+		opts.SetTLSConfig(tlsConfig)
+	}
 	client, err := mongo.Connect(ctx, opts)
 	if err != nil {
 		return err
@@ -35,7 +42,7 @@ func (m *Client) Connect() error {
 
 	m.Client = client
 
-	log.Info().Msgf("Connected to %s", uri)
+	log.Info().Msgf("Connected to %s", m.Config.GetSafeUri())
 
 	return nil
 }
@@ -0,0 +1,184 @@
+package mongo
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Shard is a shard of the cluster as listed by listShards
+type Shard struct {
+	ID       string   `bson:"_id"`
+	Host     string   `bson:"host"`
+	State    int32    `bson:"state"`
+	Draining bool     `bson:"draining"`
+	Tags     []string `bson:"tags"`
+}
+
+// ChunkCount is the number of chunks of the collection on the shard
+type ChunkCount struct {
+	Shard  string
+	Chunks int64
+}
+
+// ShardedCollection is a collection sharded with the Key,
+// its chunks are counted per shard, sorted by shard id
+type ShardedCollection struct {
+	Namespace string
+	Key       primitive.D
+	Unique    bool
+	Chunks    []ChunkCount
+}
+
+// Total returns the number of chunks of the collection
+func (c ShardedCollection) Total() int64 {
+	var total int64
+	for _, count := range c.Chunks {
+		total += count.Chunks
+	}
+	return total
+}
+
+// KeyString returns the shard key as extended JSON, fields keep their order
+func (c ShardedCollection) KeyString() string {
+	jsoned, err := bson.MarshalExtJSON(c.Key, false, false)
+	if err != nil {
+		return fmt.Sprint(c.Key)
+	}
+	return string(jsoned)
+}
+
+// Imbalance returns the difference between the number of chunks on
+// the shard with the most chunks and the one with the least of them,
+// shards without chunks of the collection count as 0
+func (c ShardedCollection) Imbalance(shards int) int64 {
+	if len(c.Chunks) == 0 {
+		return 0
+	}
+	low, high := c.Chunks[0].Chunks, c.Chunks[0].Chunks
+	for _, count := range c.Chunks {
+		low = min(low, count.Chunks)
+		high = max(high, count.Chunks)
+	}
+	if len(c.Chunks) < shards {
+		low = 0
+	}
+	return high - low
+}
+
+// BalancerStatus is the state of the balancer, Mode is "full" if it's enabled
+type BalancerStatus struct {
+	Mode            string `bson:"mode"`
+	InBalancerRound bool   `bson:"inBalancerRound"`
+	NumRounds       int64  `bson:"numBalancerRounds"`
+}
+
+// Enabled returns true if the balancer moves chunks
+func (b BalancerStatus) Enabled() bool {
+	return b.Mode == "full"
+}
+
+// ShardingStatus is the topology of the sharded cluster,
+// equivalent of sh.status() in the shell
+type ShardingStatus struct {
+	Shards      []Shard
+	Collections []ShardedCollection
+	Balancer    BalancerStatus
+}
+
+// configCollection is the entry of the sharded collection in config.collections,
+// chunks are linked with the collection by uuid since MongoDB 5.0
+type configCollection struct {
+	ID      string      `bson:"_id"`
+	Key     primitive.D `bson:"key"`
+	Unique  bool        `bson:"unique"`
+	UUID    interface{} `bson:"uuid"`
+	Dropped bool        `bson:"dropped"`
+}
+
+// chunkGroup is the number of chunks of the collection on the shard
+type chunkGroup struct {
+	ID struct {
+		Ns    string      `bson:"ns"`
+		UUID  interface{} `bson:"uuid"`
+		Shard string      `bson:"shard"`
+	} `bson:"_id"`
+	Count int64 `bson:"count"`
+}
+
+// GetShardingStatus reads shards, sharded collections with their chunk
+// distribution and the balancer state, it works only through mongos
+func (d *Dao) GetShardingStatus(ctx context.Context) (*ShardingStatus, error) {
+	var shards struct {
+		Shards []Shard `bson:"shards"`
+	}
+	err := d.client.Database("admin").RunCommand(ctx, primitive.D{{Key: "listShards", Value: 1}}).Decode(&shards)
+	if err != nil {
+		return nil, fmt.Errorf("not a sharded cluster, connect through mongos: %w", err)
+	}
+	sort.Slice(shards.Shards, func(i, j int) bool {
+		return shards.Shards[i].ID < shards.Shards[j].ID
+	})
+
+	status := &ShardingStatus{Shards: shards.Shards}
+	err = d.client.Database("admin").RunCommand(ctx, primitive.D{{Key: "balancerStatus", Value: 1}}).Decode(&status.Balancer)
+	if err != nil {
+		return nil, err
+	}
+
+	configDb := d.client.Database("config")
+	cursor, err := configDb.Collection("collections").Find(ctx, primitive.M{"dropped": primitive.M{"$ne": true}})
+	if err != nil {
+		return nil, err
+	}
+	var collections []configCollection
+	if err := cursor.All(ctx, &collections); err != nil {
+		return nil, err
+	}
+
+	cursor, err = configDb.Collection("chunks").Aggregate(ctx, primitive.A{
+		primitive.M{"$group": primitive.M{
+			"_id":   primitive.M{"ns": "$ns", "uuid": "$uuid", "shard": "$shard"},
+			"count": primitive.M{"$sum": 1},
+		}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	var groups []chunkGroup
+	if err := cursor.All(ctx, &groups); err != nil {
+		return nil, err
+	}
+
+	status.Collections = shardedCollections(collections, groups)
+	return status, nil
+}
+
+// shardedCollections joins collections with counts of their chunks,
+// chunks are matched by namespace or by uuid of the collection
+func shardedCollections(collections []configCollection, groups []chunkGroup) []ShardedCollection {
+	sharded := make([]ShardedCollection, 0, len(collections))
+	for _, coll := range collections {
+		if coll.Dropped || coll.ID == "config.system.sessions" {
+			continue
+		}
+		collection := ShardedCollection{Namespace: coll.ID, Key: coll.Key, Unique: coll.Unique}
+		uuid := fmt.Sprint(coll.UUID)
+		for _, group := range groups {
+			if group.ID.Ns == coll.ID || (coll.UUID != nil && group.ID.UUID != nil && fmt.Sprint(group.ID.UUID) == uuid) {
+				collection.Chunks = append(collection.Chunks, ChunkCount{Shard: group.ID.Shard, Chunks: group.Count})
+			}
+		}
+		sort.Slice(collection.Chunks, func(i, j int) bool {
+			return collection.Chunks[i].Shard < collection.Chunks[j].Shard
+		})
+		sharded = append(sharded, collection)
+	}
+	sort.Slice(sharded, func(i, j int) bool {
+		return sharded[i].Namespace < sharded[j].Namespace
+	})
+	return sharded
+}
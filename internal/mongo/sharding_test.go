@@ -0,0 +1,50 @@
+package mongo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestShardedCollections(t *testing.T) {
+	uuid := primitive.Binary{Subtype: 4, Data: []byte("0123456789abcdef")}
+	collections := []configCollection{
+		{ID: "shop.orders", Key: primitive.D{{Key: "customerId", Value: "hashed"}}, UUID: uuid},
+		{ID: "config.system.sessions", Key: primitive.D{{Key: "_id", Value: 1}}},
+		{ID: "logs.events", Key: primitive.D{{Key: "ts", Value: 1}}},
+		{ID: "old.data", Dropped: true},
+	}
+	group := func(ns string, uuid interface{}, shard string, count int64) chunkGroup {
+		g := chunkGroup{Count: count}
+		g.ID.Ns, g.ID.UUID, g.ID.Shard = ns, uuid, shard
+		return g
+	}
+	groups := []chunkGroup{
+		// chunks of 5.0+ are linked by uuid
+		group("", uuid, "shard02", 3),
+		group("", uuid, "shard01", 7),
+		// chunks of older versions are linked by namespace
+		group("logs.events", nil, "shard01", 4),
+	}
+
+	sharded := shardedCollections(collections, groups)
+	require.Len(t, sharded, 2)
+
+	assert.Equal(t, "logs.events", sharded[0].Namespace)
+	assert.Equal(t, []ChunkCount{{Shard: "shard01", Chunks: 4}}, sharded[0].Chunks)
+	assert.Equal(t, int64(4), sharded[0].Imbalance(2))
+	assert.Equal(t, int64(0), sharded[0].Imbalance(1))
+
+	assert.Equal(t, "shop.orders", sharded[1].Namespace)
+	assert.Equal(t, []ChunkCount{{Shard: "shard01", Chunks: 7}, {Shard: "shard02", Chunks: 3}}, sharded[1].Chunks)
+	assert.Equal(t, int64(10), sharded[1].Total())
+	assert.Equal(t, `{"customerId":"hashed"}`, sharded[1].KeyString())
+	assert.Equal(t, int64(4), sharded[1].Imbalance(2))
+}
+
+func TestBalancerStatusEnabled(t *testing.T) {
+	assert.True(t, BalancerStatus{Mode: "full"}.Enabled())
+	assert.False(t, BalancerStatus{Mode: "off"}.Enabled())
+}
@@ -0,0 +1,206 @@
+package mongo
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// maxProfiledOps is the max number of profiled operations listed
+const maxProfiledOps = 50
+
+// SlowOp is an operation running longer than the threshold, either
+// still running, found with currentOp, or finished, found in the profiler
+type SlowOp struct {
+	// OpID is set only for running operations, as only they can be killed
+	OpID        interface{}
+	Running     bool
+	Op          string
+	Ns          string
+	Duration    time.Duration
+	Client      string
+	PlanSummary string
+	Command     primitive.M
+}
+
+// ListSlowOps returns running operations exceeding the threshold, longest
+// first, followed by operations of the database recorded by the profiler
+// since given time. Profiler is skipped if the database is empty, or if
+// it can't be read, e.g. profiling is disabled.
+func (d *Dao) ListSlowOps(ctx context.Context, db string, threshold time.Duration, since time.Time) ([]SlowOp, error) {
+	command := primitive.D{
+		{Key: "currentOp", Value: 1},
+		{Key: "active", Value: true},
+		{Key: "microsecs_running", Value: primitive.M{"$gte": threshold.Microseconds()}},
+	}
+	var result struct {
+		Inprog []primitive.M `bson:"inprog"`
+	}
+	if err := d.client.Database("admin").RunCommand(ctx, command).Decode(&result); err != nil {
+		return nil, err
+	}
+
+	ops := make([]SlowOp, 0, len(result.Inprog))
+	for _, op := range result.Inprog {
+		ops = append(ops, newRunningOp(op))
+	}
+	sort.SliceStable(ops, func(i, j int) bool {
+		return ops[i].Duration > ops[j].Duration
+	})
+
+	if db == "" {
+		return ops, nil
+	}
+	profiled, err := d.listProfiledOps(ctx, db, threshold, since)
+	if err != nil {
+		log.Debug().Err(err).Str("db", db).Msg("Error reading profiler")
+		return ops, nil
+	}
+	return append(ops, profiled...), nil
+}
+
+// KillOp kills the running operation
+func (d *Dao) KillOp(ctx context.Context, opID interface{}) error {
+	if err := d.checkWritable(); err != nil {
+		return err
+	}
+	if opID == nil {
+		return fmt.Errorf("only running operations can be killed")
+	}
+
+	command := primitive.D{{Key: "killOp", Value: 1}, {Key: "op", Value: opID}}
+	if err := d.client.Database("admin").RunCommand(ctx, command).Err(); err != nil {
+		return err
+	}
+
+	log.Debug().Msgf("Operation killed, opid: %v", opID)
+
+	return nil
+}
+
+func (d *Dao) listProfiledOps(ctx context.Context, db string, threshold time.Duration, since time.Time) ([]SlowOp, error) {
+	filter := primitive.M{
+		"millis": primitive.M{"$gte": threshold.Milliseconds()},
+		"ts":     primitive.M{"$gte": since},
+	}
+	opts := options.Find().SetSort(primitive.D{{Key: "ts", Value: -1}}).SetLimit(maxProfiledOps)
+	cursor, err := d.client.Database(db).Collection("system.profile").Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var entries []primitive.M
+	if err := cursor.All(ctx, &entries); err != nil {
+		return nil, err
+	}
+	ops := make([]SlowOp, 0, len(entries))
+	for _, entry := range entries {
+		ops = append(ops, newProfiledOp(entry))
+	}
+	return ops, nil
+}
+
+func newRunningOp(op primitive.M) SlowOp {
+	slowOp := SlowOp{OpID: op["opid"], Running: true}
+	slowOp.fill(op)
+	if micros, ok := toFloat(op["microsecs_running"]); ok {
+		slowOp.Duration = time.Duration(micros) * time.Microsecond
+	}
+	// mongos reports the client as client_s
+	if slowOp.Client == "" {
+		slowOp.Client, _ = op["client_s"].(string)
+	}
+	return slowOp
+}
+
+func newProfiledOp(entry primitive.M) SlowOp {
+	slowOp := SlowOp{}
+	slowOp.fill(entry)
+	if millis, ok := toFloat(entry["millis"]); ok {
+		slowOp.Duration = time.Duration(millis) * time.Millisecond
+	}
+	return slowOp
+}
+
+func (o *SlowOp) fill(doc primitive.M) {
+	o.Op, _ = doc["op"].(string)
+	o.Ns, _ = doc["ns"].(string)
+	o.Client, _ = doc["client"].(string)
+	o.PlanSummary, _ = doc["planSummary"].(string)
+	o.Command, _ = doc["command"].(primitive.M)
+}
+
+// QueryShape returns the command with values replaced with their types,
+// so operations differing only in values have the same shape. Top-level
+// values, like the name of the collection, and noise fields of the driver
+// are kept as they are.
+func QueryShape(command primitive.M) primitive.M {
+	shape := make(primitive.M, len(command))
+	for key, value := range command {
+		switch key {
+		case "lsid", "$clusterTime", "$readPreference":
+			continue
+		}
+		switch value.(type) {
+		case primitive.M, primitive.A, primitive.D:
+			shape[key] = shapeValue(value)
+		default:
+			shape[key] = value
+		}
+	}
+	return shape
+}
+
+func shapeValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case primitive.M:
+		shaped := make(primitive.M, len(v))
+		for key, nested := range v {
+			shaped[key] = shapeValue(nested)
+		}
+		return shaped
+	case primitive.D:
+		shaped := make(primitive.M, len(v))
+		for _, elem := range v {
+			shaped[elem.Key] = shapeValue(elem.Value)
+		}
+		return shaped
+	case primitive.A:
+		// lists of values, like in $in, differ only in length
+		shaped := primitive.A{}
+		seen := map[string]bool{}
+		for _, nested := range v {
+			s := shapeValue(nested)
+			if placeholder, ok := s.(string); ok {
+				if seen[placeholder] {
+					continue
+				}
+				seen[placeholder] = true
+			}
+			shaped = append(shaped, s)
+		}
+		return shaped
+	case string:
+		return "<string>"
+	case int32, int64, float64, primitive.Decimal128:
+		return "<number>"
+	case bool:
+		return "<bool>"
+	case primitive.DateTime, primitive.Timestamp:
+		return "<date>"
+	case primitive.ObjectID:
+		return "<objectId>"
+	case primitive.Regex:
+		return "<regex>"
+	case nil:
+		return "<null>"
+	default:
+		return "<value>"
+	}
+}
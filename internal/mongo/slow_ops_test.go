@@ -0,0 +1,82 @@
+package mongo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestQueryShape(t *testing.T) {
+	command := primitive.M{
+		"find": "users",
+		"filter": primitive.M{
+			"name":   "John",
+			"age":    primitive.M{"$gt": int32(30)},
+			"status": primitive.M{"$in": primitive.A{"active", "pending", int64(1)}},
+		},
+		"sort":            primitive.D{{Key: "age", Value: int32(-1)}},
+		"limit":           int64(10),
+		"lsid":            primitive.M{"id": "session"},
+		"$clusterTime":    primitive.M{"clusterTime": primitive.Timestamp{T: 1}},
+		"$readPreference": primitive.M{"mode": "primary"},
+	}
+
+	expected := primitive.M{
+		"find": "users",
+		"filter": primitive.M{
+			"name":   "<string>",
+			"age":    primitive.M{"$gt": "<number>"},
+			"status": primitive.M{"$in": primitive.A{"<string>", "<number>"}},
+		},
+		"sort":  primitive.M{"age": "<number>"},
+		"limit": int64(10),
+	}
+	assert.Equal(t, expected, QueryShape(command))
+}
+
+func TestQueryShapeSameForDifferentValues(t *testing.T) {
+	first := primitive.M{"find": "users", "filter": primitive.M{"_id": primitive.NewObjectID()}}
+	second := primitive.M{"find": "users", "filter": primitive.M{"_id": primitive.NewObjectID()}}
+
+	assert.Equal(t, QueryShape(first), QueryShape(second))
+	assert.Equal(t, primitive.M{"_id": "<objectId>"}, QueryShape(first)["filter"])
+}
+
+func TestNewRunningOp(t *testing.T) {
+	op := newRunningOp(primitive.M{
+		"opid":              int32(42),
+		"op":                "query",
+		"ns":                "db.users",
+		"microsecs_running": int64(1500000),
+		"client_s":          "10.0.0.1:5000",
+		"planSummary":       "COLLSCAN",
+		"command":           primitive.M{"find": "users"},
+	})
+
+	assert.True(t, op.Running)
+	assert.Equal(t, int32(42), op.OpID)
+	assert.Equal(t, "query", op.Op)
+	assert.Equal(t, "db.users", op.Ns)
+	assert.Equal(t, 1500*time.Millisecond, op.Duration)
+	assert.Equal(t, "10.0.0.1:5000", op.Client)
+	assert.Equal(t, "COLLSCAN", op.PlanSummary)
+	assert.Equal(t, primitive.M{"find": "users"}, op.Command)
+}
+
+func TestNewProfiledOp(t *testing.T) {
+	op := newProfiledOp(primitive.M{
+		"op":      "update",
+		"ns":      "db.orders",
+		"millis":  int32(250),
+		"client":  "127.0.0.1",
+		"command": primitive.M{"q": primitive.M{"status": "new"}},
+	})
+
+	assert.False(t, op.Running)
+	assert.Nil(t, op.OpID)
+	assert.Equal(t, "update", op.Op)
+	assert.Equal(t, 250*time.Millisecond, op.Duration)
+	assert.Equal(t, "127.0.0.1", op.Client)
+}
@@ -0,0 +1,21 @@
+package mongo
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ExcludeDeleted returns the filter that additionally skips documents
+// marked as deleted, which are the ones with the field set
+func ExcludeDeleted(filter primitive.M, field string) primitive.M {
+	notDeleted := primitive.M{field: nil}
+	if len(filter) == 0 {
+		return notDeleted
+	}
+	return primitive.M{"$and": primitive.A{filter, notDeleted}}
+}
+
+// IsDeleted returns true if the document is marked as deleted
+func IsDeleted(doc primitive.M, field string) bool {
+	value, ok := doc[field]
+	return ok && value != nil
+}
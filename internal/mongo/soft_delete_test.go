@@ -0,0 +1,25 @@
+package mongo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestExcludeDeleted(t *testing.T) {
+	assert.Equal(t, primitive.M{"deletedAt": nil}, ExcludeDeleted(primitive.M{}, "deletedAt"))
+
+	filter := primitive.M{"name": "John"}
+	assert.Equal(t, primitive.M{"$and": primitive.A{
+		primitive.M{"name": "John"},
+		primitive.M{"deletedAt": nil},
+	}}, ExcludeDeleted(filter, "deletedAt"))
+}
+
+func TestIsDeleted(t *testing.T) {
+	assert.False(t, IsDeleted(primitive.M{"name": "John"}, "deletedAt"))
+	assert.False(t, IsDeleted(primitive.M{"deletedAt": nil}, "deletedAt"))
+	assert.True(t, IsDeleted(primitive.M{"deletedAt": primitive.NewDateTimeFromTime(time.Now())}, "deletedAt"))
+}
@@ -0,0 +1,98 @@
+package mongo
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/kopecmaciej/vi-mongo/internal/util"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	SortAscending  = 1
+	SortDescending = -1
+)
+
+// SortKey is a single field of the sort with its direction
+type SortKey struct {
+	Field     string
+	Direction int
+}
+
+// SortKeys is a sort with multiple fields, the order of fields matters
+type SortKeys []SortKey
+
+// ParseStringSort parses sort from the string, keeping the order of fields,
+// as opposed to ParseStringQuery which returns unordered map
+func ParseStringSort(sort string) (primitive.D, error) {
+	if sort == "" {
+		return primitive.D{}, nil
+	}
+
+	var parsed primitive.D
+	err := bson.UnmarshalExtJSON([]byte(util.QuoteUnquotedKeys(sort)), false, &parsed)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing sort %s: %w", sort, err)
+	}
+
+	return parsed, nil
+}
+
+// ParseSortKeys parses sort like `{ "age": -1, "name": 1 }` into keys
+func ParseSortKeys(sort string) (SortKeys, error) {
+	parsed, err := ParseStringSort(sort)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make(SortKeys, 0, len(parsed))
+	for _, elem := range parsed {
+		direction, err := strconv.Atoi(fmt.Sprint(elem.Value))
+		if err != nil || (direction != SortAscending && direction != SortDescending) {
+			return nil, fmt.Errorf("sort direction of %s must be 1 or -1, got %v", elem.Key, elem.Value)
+		}
+		keys = append(keys, SortKey{Field: elem.Key, Direction: direction})
+	}
+
+	return keys, nil
+}
+
+// Set adds the field to the end of the sort, or changes its direction
+// if the field is already sorted
+func (s SortKeys) Set(field string, direction int) SortKeys {
+	for i, key := range s {
+		if key.Field == field {
+			s[i].Direction = direction
+			return s
+		}
+	}
+	return append(s, SortKey{Field: field, Direction: direction})
+}
+
+// Query returns the sort as it's typed in the sort bar
+func (s SortKeys) Query() string {
+	if len(s) == 0 {
+		return ""
+	}
+
+	parts := make([]string, 0, len(s))
+	for _, key := range s {
+		parts = append(parts, fmt.Sprintf("%q: %d", key.Field, key.Direction))
+	}
+	return "{ " + strings.Join(parts, ", ") + " }"
+}
+
+// String returns human readable sort, e.g. "age desc, name asc"
+func (s SortKeys) String() string {
+	parts := make([]string, 0, len(s))
+	for _, key := range s {
+		direction := "asc"
+		if key.Direction == SortDescending {
+			direction = "desc"
+		}
+		parts = append(parts, key.Field+" "+direction)
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,40 @@
+package mongo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestParseStringSort(t *testing.T) {
+	sort, err := ParseStringSort(`{ "name": 1, age: -1, "address.city": 1 }`)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"name", "age", "address.city"}, []string{sort[0].Key, sort[1].Key, sort[2].Key})
+
+	empty, err := ParseStringSort("")
+	require.NoError(t, err)
+	assert.Equal(t, primitive.D{}, empty)
+
+	_, err = ParseStringSort(`{ "name": `)
+	assert.Error(t, err)
+}
+
+func TestParseSortKeys(t *testing.T) {
+	keys, err := ParseSortKeys(`{"age":-1,"name":1}`)
+	require.NoError(t, err)
+	assert.Equal(t, SortKeys{{"age", SortDescending}, {"name", SortAscending}}, keys)
+	assert.Equal(t, `{ "age": -1, "name": 1 }`, keys.Query())
+	assert.Equal(t, "age desc, name asc", keys.String())
+
+	_, err = ParseSortKeys(`{"age": 2}`)
+	assert.Error(t, err)
+}
+
+func TestSortKeysSet(t *testing.T) {
+	keys := SortKeys{}.Set("age", SortAscending).Set("name", SortDescending).Set("age", SortDescending)
+
+	assert.Equal(t, SortKeys{{"age", SortDescending}, {"name", SortDescending}}, keys)
+	assert.Equal(t, "", SortKeys{}.Query())
+}
@@ -2,12 +2,18 @@ package mongo
 
 import (
 	"reflect"
+	"strings"
 	"sync"
 
 	"github.com/kopecmaciej/vi-mongo/internal/util"
+	"github.com/rs/zerolog/log"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// CollectionState holds the query and documents of the collection.
+// Query fields are changed only by the UI, while documents and count
+// are guarded by the mutex, as they are also read and modified by
+// background refreshes and by the peeker.
 type CollectionState struct {
 	Db     string
 	Coll   string
@@ -16,10 +22,46 @@ type CollectionState struct {
 	Count  int64
 	Sort   string
 	Filter string
-	docs   []primitive.M
+	// Pipeline, if set, is the aggregation pipeline whose results
+	// are listed instead of documents found with the filter and sort
+	Pipeline string
+	// Options are applied to the find along with the filter and sort
+	Options QueryOptions
+	// EstimatedCount uses estimatedDocumentCount instead of countDocuments
+	// for the total, if the filter is empty
+	EstimatedCount bool
+	// SoftDeleteField marks deleted documents, if the collection
+	// uses soft delete, they are hidden unless ShowDeleted is set
+	SoftDeleteField string
+	ShowDeleted     bool
+	// TimeSeries are options of the time-series collection, nil otherwise
+	TimeSeries *TimeSeries
+
+	mu   sync.Mutex
+	docs []primitive.M
+	// lazyDocs are documents fetched from the database, which are
+	// decoded into docs only when whole documents are needed
+	lazyDocs []*LazyDocument
+}
+
+// GetCount returns the number of documents matching the filter
+func (c *CollectionState) GetCount() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.Count
+}
+
+// SetCount sets the number of documents matching the filter
+func (c *CollectionState) SetCount(count int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.Count = count
 }
 
 func (c *CollectionState) GetAllDocs() []primitive.M {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.decodeDocs()
 	docsCopy := make([]primitive.M, len(c.docs))
 	for i, doc := range c.docs {
 		docsCopy[i] = deepCopy(doc)
@@ -28,6 +70,9 @@ func (c *CollectionState) GetAllDocs() []primitive.M {
 }
 
 func (c *CollectionState) GetDocById(id interface{}) primitive.M {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.decodeDocs()
 	for _, doc := range c.docs {
 		if reflect.TypeOf(doc["_id"]) == reflect.TypeOf(id) {
 			if doc["_id"] == id {
@@ -51,6 +96,68 @@ func (c *CollectionState) GetJsonDocById(id interface{}) (string, error) {
 	return indentedJson.String(), nil
 }
 
+// PageQuery identifies documents of the page, so the page loaded
+// in the background can be matched with the one that is requested
+type PageQuery struct {
+	Db             string
+	Coll           string
+	Page           int64
+	Limit          int64
+	Sort           string
+	Filter         string
+	Pipeline       string
+	Options        QueryOptions
+	EstimatedCount bool
+	ShowDeleted    bool
+}
+
+// Query returns the query of the current page
+func (c *CollectionState) Query() PageQuery {
+	return PageQuery{
+		Db:             c.Db,
+		Coll:           c.Coll,
+		Page:           c.Page,
+		Limit:          c.Limit,
+		Sort:           c.Sort,
+		Filter:         c.Filter,
+		Pipeline:       c.Pipeline,
+		Options:        c.Options,
+		EstimatedCount: c.EstimatedCount,
+		ShowDeleted:    c.ShowDeleted,
+	}
+}
+
+// NextPage returns the new state with the query of the next page,
+// documents are not copied
+func (c *CollectionState) NextPage() *CollectionState {
+	return &CollectionState{
+		Db:              c.Db,
+		Coll:            c.Coll,
+		Page:            c.Page + c.Limit,
+		Limit:           c.Limit,
+		Sort:            c.Sort,
+		Filter:          c.Filter,
+		Pipeline:        c.Pipeline,
+		Options:         c.Options,
+		EstimatedCount:  c.EstimatedCount,
+		SoftDeleteField: c.SoftDeleteField,
+		ShowDeleted:     c.ShowDeleted,
+		TimeSeries:      c.TimeSeries,
+	}
+}
+
+// HidesDeleted returns true if documents marked as deleted
+// should be filtered out
+func (c *CollectionState) HidesDeleted() bool {
+	return c.SoftDeleteField != "" && !c.ShowDeleted
+}
+
+// IsCountEstimated returns true if the count comes from collection
+// metadata, as the estimate can't be used with the filter
+func (c *CollectionState) IsCountEstimated() bool {
+	return c.EstimatedCount && c.Filter == "" && !c.HidesDeleted()
+}
+
 func (c *CollectionState) UpdateFilter(filter string) {
 	filter = util.CleanJsonWhitespaces(filter)
 	if util.IsJsonEmpty(filter) {
@@ -61,6 +168,13 @@ func (c *CollectionState) UpdateFilter(filter string) {
 	c.Page = 0
 }
 
+// UpdatePipeline sets the aggregation pipeline, the empty
+// pipeline switches back to listing found documents
+func (c *CollectionState) UpdatePipeline(pipeline string) {
+	c.Pipeline = strings.TrimSpace(pipeline)
+	c.Page = 0
+}
+
 func (c *CollectionState) UpdateSort(sort string) {
 	sort = util.CleanJsonWhitespaces(sort)
 	if util.IsJsonEmpty(sort) {
@@ -71,13 +185,66 @@ func (c *CollectionState) UpdateSort(sort string) {
 }
 
 func (c *CollectionState) PopulateDocs(docs []primitive.M) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.lazyDocs = nil
 	c.docs = make([]primitive.M, len(docs))
 	for i, doc := range docs {
 		c.docs[i] = deepCopy(doc)
 	}
 }
 
+// PopulateLazyDocs sets documents that are decoded when needed
+func (c *CollectionState) PopulateLazyDocs(docs []*LazyDocument) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.docs = nil
+	c.lazyDocs = make([]*LazyDocument, len(docs))
+	copy(c.lazyDocs, docs)
+}
+
+// GetLazyDocs returns documents without decoding them
+func (c *CollectionState) GetLazyDocs() []*LazyDocument {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.lazyDocs != nil {
+		docs := make([]*LazyDocument, len(c.lazyDocs))
+		copy(docs, c.lazyDocs)
+		return docs
+	}
+
+	docs := make([]*LazyDocument, len(c.docs))
+	for i, doc := range c.docs {
+		docs[i] = NewDecodedDocument(deepCopy(doc))
+	}
+	return docs
+}
+
+// decodeDocs decodes lazy documents, after that documents are only
+// kept decoded, so changes made to them are not lost.
+// It must be called with the lock held.
+func (c *CollectionState) decodeDocs() {
+	if c.lazyDocs == nil {
+		return
+	}
+
+	docs := make([]primitive.M, 0, len(c.lazyDocs))
+	for _, lazyDoc := range c.lazyDocs {
+		doc, err := lazyDoc.Decode()
+		if err != nil {
+			log.Error().Err(err).Msg("Error decoding document")
+			continue
+		}
+		docs = append(docs, doc)
+	}
+	c.docs = docs
+	c.lazyDocs = nil
+}
+
 func (c *CollectionState) UpdateRawDoc(doc string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.decodeDocs()
 	docMap, err := ParseJsonToBson(doc)
 	if err != nil {
 		return err
@@ -93,11 +260,17 @@ func (c *CollectionState) UpdateRawDoc(doc string) error {
 }
 
 func (c *CollectionState) AppendDoc(doc primitive.M) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.decodeDocs()
 	c.docs = append(c.docs, doc)
 	c.Count++
 }
 
 func (c *CollectionState) DeleteDoc(id interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.decodeDocs()
 	for i, doc := range c.docs {
 		if doc["_id"] == id {
 			c.docs = append(c.docs[:i], c.docs[i+1:]...)
@@ -1,9 +1,12 @@
 package mongo
 
 import (
+	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
@@ -21,6 +24,24 @@ func TestCollectionState_UpdateFilter(t *testing.T) {
 	assert.Equal(t, "", cs.Filter)
 }
 
+func TestCollectionState_IsCountEstimated(t *testing.T) {
+	cs := &CollectionState{}
+	assert.False(t, cs.IsCountEstimated())
+
+	cs.EstimatedCount = true
+	assert.True(t, cs.IsCountEstimated())
+
+	cs.UpdateFilter(`{"name": "test"}`)
+	assert.False(t, cs.IsCountEstimated())
+
+	// estimate can't skip documents marked as deleted
+	cs = &CollectionState{EstimatedCount: true, SoftDeleteField: "deletedAt"}
+	assert.True(t, cs.HidesDeleted())
+	assert.False(t, cs.IsCountEstimated())
+	cs.ShowDeleted = true
+	assert.True(t, cs.IsCountEstimated())
+}
+
 func TestCollectionState_UpdateSort(t *testing.T) {
 	cs := &CollectionState{Sort: `{"old": 1}`}
 
@@ -34,6 +55,19 @@ func TestCollectionState_UpdateSort(t *testing.T) {
 	assert.Equal(t, "", cs.Sort)
 }
 
+func TestCollectionState_UpdatePipeline(t *testing.T) {
+	cs := &CollectionState{Page: 40, Limit: 20}
+
+	cs.UpdatePipeline(" [{ $match: {} }]\n")
+	assert.Equal(t, "[{ $match: {} }]", cs.Pipeline)
+	assert.Equal(t, int64(0), cs.Page)
+	assert.Equal(t, cs.Pipeline, cs.NextPage().Pipeline)
+	assert.Equal(t, cs.Pipeline, cs.Query().Pipeline)
+
+	cs.UpdatePipeline("")
+	assert.Equal(t, "", cs.Pipeline)
+}
+
 func TestCollectionState_GetDocById(t *testing.T) {
 	cs := &CollectionState{
 		docs: []primitive.M{
@@ -104,3 +138,53 @@ func TestCollectionState_GetJsonDocById_DoesNotModifyState(t *testing.T) {
 	assert.Equal(t, primitive.M{"_id": id1, "value": 1}, cs.docs[0])
 	assert.Equal(t, primitive.M{"_id": id2, "value": 2}, cs.docs[1])
 }
+
+func TestCollectionState_LazyDocs(t *testing.T) {
+	raw, err := bson.Marshal(primitive.M{"_id": "1", "value": int32(1)})
+	require.NoError(t, err)
+
+	cs := &CollectionState{}
+	cs.PopulateLazyDocs([]*LazyDocument{NewLazyDocument(raw)})
+	assert.Len(t, cs.GetLazyDocs(), 1)
+
+	cs.AppendDoc(primitive.M{"_id": "2", "value": int32(2)})
+	docs := cs.GetLazyDocs()
+	require.Len(t, docs, 2)
+	value, ok := docs[1].Get("value")
+	assert.True(t, ok)
+	assert.Equal(t, int32(2), value)
+
+	assert.Equal(t, primitive.M{"_id": "1", "value": int32(1)}, cs.GetDocById("1"))
+}
+
+func TestCollectionState_ConcurrentAccess(t *testing.T) {
+	raw, err := bson.Marshal(primitive.M{"_id": "1", "value": int32(1)})
+	require.NoError(t, err)
+
+	cs := &CollectionState{}
+	cs.PopulateLazyDocs([]*LazyDocument{NewLazyDocument(raw)})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(3)
+		go func(i int) {
+			defer wg.Done()
+			cs.AppendDoc(primitive.M{"_id": i + 2})
+		}(i)
+		go func() {
+			defer wg.Done()
+			for _, doc := range cs.GetLazyDocs() {
+				doc.Get("value")
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			cs.GetAllDocs()
+			cs.GetCount()
+		}()
+	}
+	wg.Wait()
+
+	assert.Len(t, cs.GetAllDocs(), 11)
+	assert.Equal(t, int64(10), cs.GetCount())
+}
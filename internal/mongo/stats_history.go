@@ -0,0 +1,73 @@
+package mongo
+
+import (
+	"time"
+
+	"github.com/kopecmaciej/vi-mongo/internal/config"
+)
+
+// StatsSeries is a single metric of the stats history, e.g. inserts
+// per second, with values from the oldest to the newest sample
+type StatsSeries struct {
+	Name   string
+	Unit   string
+	Values []float64
+}
+
+// Last returns the newest value of the series
+func (s StatsSeries) Last() float64 {
+	if len(s.Values) == 0 {
+		return 0
+	}
+	return s.Values[len(s.Values)-1]
+}
+
+// StatsSample returns the sample of the status saved in the stats history
+func (s *ServerStatus) StatsSample(now time.Time) config.StatsSample {
+	return config.StatsSample{
+		Time:        now,
+		Connections: int64(s.Connections.Current),
+		ResidentMB:  int64(s.Mem.Resident),
+		Insert:      s.OpCounters.Insert,
+		Query:       s.OpCounters.Query,
+		Update:      s.OpCounters.Update,
+		Delete:      s.OpCounters.Delete,
+	}
+}
+
+// StatsHistorySeries returns series of the stats history shown as charts.
+// Op counters are turned into operations per second between consecutive
+// samples, the rate is 0 when the counter was reset by the restart.
+func StatsHistorySeries(samples []config.StatsSample) []StatsSeries {
+	connections := StatsSeries{Name: "Connections"}
+	memory := StatsSeries{Name: "Resident memory", Unit: "MB"}
+	for _, s := range samples {
+		connections.Values = append(connections.Values, float64(s.Connections))
+		memory.Values = append(memory.Values, float64(s.ResidentMB))
+	}
+
+	ops := []struct {
+		name    string
+		counter func(config.StatsSample) int64
+	}{
+		{"Inserts", func(s config.StatsSample) int64 { return s.Insert }},
+		{"Queries", func(s config.StatsSample) int64 { return s.Query }},
+		{"Updates", func(s config.StatsSample) int64 { return s.Update }},
+		{"Deletes", func(s config.StatsSample) int64 { return s.Delete }},
+	}
+	series := []StatsSeries{connections, memory}
+	for _, op := range ops {
+		rate := StatsSeries{Name: op.name, Unit: "/s"}
+		for i := 1; i < len(samples); i++ {
+			elapsed := samples[i].Time.Sub(samples[i-1].Time).Seconds()
+			diff := op.counter(samples[i]) - op.counter(samples[i-1])
+			if elapsed <= 0 || diff < 0 {
+				rate.Values = append(rate.Values, 0)
+				continue
+			}
+			rate.Values = append(rate.Values, float64(diff)/elapsed)
+		}
+		series = append(series, rate)
+	}
+	return series
+}
@@ -0,0 +1,36 @@
+package mongo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kopecmaciej/vi-mongo/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatsHistorySeries(t *testing.T) {
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	samples := []config.StatsSample{
+		{Time: start, Connections: 5, ResidentMB: 100, Insert: 100, Query: 1000},
+		{Time: start.Add(time.Minute), Connections: 7, ResidentMB: 120, Insert: 700, Query: 1060},
+		// the server was restarted, counters start from 0
+		{Time: start.Add(2 * time.Minute), Connections: 2, ResidentMB: 80, Insert: 10, Query: 5},
+	}
+
+	series := StatsHistorySeries(samples)
+	require.Len(t, series, 6)
+
+	assert.Equal(t, "Connections", series[0].Name)
+	assert.Equal(t, []float64{5, 7, 2}, series[0].Values)
+	assert.Equal(t, 2.0, series[0].Last())
+	assert.Equal(t, []float64{100, 120, 80}, series[1].Values)
+
+	assert.Equal(t, "Inserts", series[2].Name)
+	assert.Equal(t, []float64{10, 0}, series[2].Values)
+	assert.Equal(t, "Queries", series[3].Name)
+	assert.Equal(t, []float64{1, 0}, series[3].Values)
+
+	assert.Empty(t, StatsHistorySeries(nil)[2].Values)
+	assert.Equal(t, 0.0, StatsHistorySeries(nil)[0].Last())
+}
@@ -0,0 +1,117 @@
+package mongo
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// slowStatusResponse is the response time above which the server is
+// considered busy, so serverStatus is polled less often
+const slowStatusResponse = time.Second
+
+// StatusPoller polls serverStatus in the background. Polling is skipped
+// while the active func returns false, and the interval is increased
+// when the server is slow or fails, so admin commands are not sent
+// to the server that is already struggling.
+type StatusPoller struct {
+	dao         *Dao
+	interval    time.Duration
+	maxInterval time.Duration
+
+	// active reports whether the status is needed right now,
+	// it's called from the poller goroutine
+	active func() bool
+	// onStatus is called from the poller goroutine after every poll
+	onStatus func(status *ServerStatus, err error)
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+}
+
+// NewStatusPoller returns a poller which is not started yet
+func NewStatusPoller(dao *Dao, interval, maxInterval time.Duration, active func() bool, onStatus func(*ServerStatus, error)) *StatusPoller {
+	if maxInterval < interval {
+		maxInterval = interval
+	}
+	return &StatusPoller{
+		dao:         dao,
+		interval:    interval,
+		maxInterval: maxInterval,
+		active:      active,
+		onStatus:    onStatus,
+	}
+}
+
+// Start starts polling in the background until Stop is called or ctx
+// is done, it does nothing if the interval is not positive
+// or the poller is already running
+func (p *StatusPoller) Start(ctx context.Context) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.interval <= 0 || p.cancel != nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	p.cancel = cancel
+	go p.run(ctx)
+}
+
+// Stop stops polling, poll that is in progress is cancelled
+func (p *StatusPoller) Stop() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.cancel != nil {
+		p.cancel()
+		p.cancel = nil
+	}
+}
+
+func (p *StatusPoller) run(ctx context.Context) {
+	wait := time.Duration(0)
+	for {
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		if !p.active() {
+			wait = p.interval
+			continue
+		}
+
+		pollCtx, cancel := context.WithTimeout(ctx, p.maxInterval)
+		start := time.Now()
+		status, err := p.dao.GetServerStatus(pollCtx)
+		took := time.Since(start)
+		cancel()
+		if ctx.Err() != nil {
+			return
+		}
+
+		p.onStatus(status, err)
+		wait = NextPollInterval(wait, p.interval, p.maxInterval, took, err)
+	}
+}
+
+// NextPollInterval returns the interval to wait before the next poll.
+// The current interval is doubled, up to max, if the poll failed or took
+// longer than slowStatusResponse, otherwise it goes back to base.
+func NextPollInterval(current, base, max, took time.Duration, err error) time.Duration {
+	if err == nil && took <= slowStatusResponse {
+		return base
+	}
+
+	next := current * 2
+	if next < base {
+		next = base * 2
+	}
+	if next > max {
+		next = max
+	}
+	return next
+}
@@ -0,0 +1,26 @@
+package mongo
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNextPollInterval(t *testing.T) {
+	base := 10 * time.Second
+	max := 60 * time.Second
+	fast := 50 * time.Millisecond
+	errTimeout := errors.New("timeout")
+
+	assert.Equal(t, base, NextPollInterval(base, base, max, fast, nil))
+	assert.Equal(t, 20*time.Second, NextPollInterval(base, base, max, 2*time.Second, nil))
+	assert.Equal(t, 40*time.Second, NextPollInterval(20*time.Second, base, max, fast, errTimeout))
+	assert.Equal(t, max, NextPollInterval(40*time.Second, base, max, fast, errTimeout))
+	assert.Equal(t, max, NextPollInterval(max, base, max, fast, errTimeout))
+	// first poll is made without waiting
+	assert.Equal(t, 20*time.Second, NextPollInterval(0, base, max, fast, errTimeout))
+	// fast response resets the backoff
+	assert.Equal(t, base, NextPollInterval(max, base, max, fast, nil))
+}
@@ -0,0 +1,103 @@
+package mongo
+
+import "fmt"
+
+// WiredTiger starts eviction by application threads when the cache
+// or its dirty part exceeds these ratios, which slows down operations
+const (
+	cacheEvictionTrigger = 0.95
+	dirtyEvictionTrigger = 0.20
+)
+
+// StorageEngine is the storage engine section of serverStatus
+type StorageEngine struct {
+	Name string `bson:"name"`
+}
+
+// WiredTigerStats are the WiredTiger numbers from serverStatus
+// needed first when the server misbehaves
+type WiredTigerStats struct {
+	Cache struct {
+		MaxBytes              int64 `bson:"maximum bytes configured"`
+		Bytes                 int64 `bson:"bytes currently in the cache"`
+		DirtyBytes            int64 `bson:"tracked dirty bytes in the cache"`
+		PagesRead             int64 `bson:"pages read into cache"`
+		PagesWritten          int64 `bson:"pages written from cache"`
+		UnmodifiedEvicted     int64 `bson:"unmodified pages evicted"`
+		ModifiedEvicted       int64 `bson:"modified pages evicted"`
+		ApplicationEvicted    int64 `bson:"pages evicted by application threads"`
+		EvictionWorkerEvicted int64 `bson:"eviction worker thread evicting pages"`
+	} `bson:"cache"`
+	ConcurrentTransactions Tickets `bson:"concurrentTransactions"`
+}
+
+// Tickets are read and write tickets limiting concurrent
+// operations in the storage engine
+type Tickets struct {
+	Read  TicketUsage `bson:"read"`
+	Write TicketUsage `bson:"write"`
+}
+
+type TicketUsage struct {
+	Out       int64 `bson:"out"`
+	Available int64 `bson:"available"`
+	Total     int64 `bson:"totalTickets"`
+}
+
+// Queues is where MongoDB 7.0 and newer report tickets
+type Queues struct {
+	Execution Tickets `bson:"execution"`
+}
+
+// CacheUsage returns the used part of the cache, 0 if the size is unknown
+func (w *WiredTigerStats) CacheUsage() float64 {
+	if w.Cache.MaxBytes <= 0 {
+		return 0
+	}
+	return float64(w.Cache.Bytes) / float64(w.Cache.MaxBytes)
+}
+
+// DirtyUsage returns the part of the cache with modified data
+// not written to disk yet, 0 if the size is unknown
+func (w *WiredTigerStats) DirtyUsage() float64 {
+	if w.Cache.MaxBytes <= 0 {
+		return 0
+	}
+	return float64(w.Cache.DirtyBytes) / float64(w.Cache.MaxBytes)
+}
+
+// Tickets returns ticket usage, from execution queues if the server
+// reports them and from WiredTiger otherwise
+func (s *ServerStatus) Tickets() Tickets {
+	if s.Queues.Execution.Read.Total > 0 || s.Queues.Execution.Write.Total > 0 {
+		return s.Queues.Execution
+	}
+	if s.WiredTiger != nil {
+		return s.WiredTiger.ConcurrentTransactions
+	}
+	return Tickets{}
+}
+
+// StorageWarnings returns problems visible in storage engine stats,
+// like cache pressure forcing application threads into eviction
+// or all tickets being taken, so operations are queued
+func (s *ServerStatus) StorageWarnings() []string {
+	var warnings []string
+	if wt := s.WiredTiger; wt != nil {
+		if usage := wt.CacheUsage(); usage >= cacheEvictionTrigger {
+			warnings = append(warnings, fmt.Sprintf("Cache is %.0f%% full", usage*100))
+		}
+		if usage := wt.DirtyUsage(); usage >= dirtyEvictionTrigger {
+			warnings = append(warnings, fmt.Sprintf("Dirty data is %.0f%% of the cache", usage*100))
+		}
+	}
+
+	tickets := s.Tickets()
+	if tickets.Read.Total > 0 && tickets.Read.Available == 0 {
+		warnings = append(warnings, "No read tickets available")
+	}
+	if tickets.Write.Total > 0 && tickets.Write.Available == 0 {
+		warnings = append(warnings, "No write tickets available")
+	}
+	return warnings
+}
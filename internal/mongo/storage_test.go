@@ -0,0 +1,71 @@
+package mongo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func decodeServerStatus(t *testing.T, doc primitive.M) *ServerStatus {
+	raw, err := bson.Marshal(doc)
+	require.NoError(t, err)
+	var status ServerStatus
+	require.NoError(t, bson.Unmarshal(raw, &status))
+	return &status
+}
+
+func TestServerStatusWiredTiger(t *testing.T) {
+	status := decodeServerStatus(t, primitive.M{
+		"storageEngine": primitive.M{"name": "wiredTiger"},
+		"wiredTiger": primitive.M{
+			"cache": primitive.M{
+				"maximum bytes configured":         int64(1000),
+				"bytes currently in the cache":     int64(960),
+				"tracked dirty bytes in the cache": int64(100),
+				"modified pages evicted":           int64(7),
+			},
+			"concurrentTransactions": primitive.M{
+				"read":  primitive.M{"out": int32(128), "available": int32(0), "totalTickets": int32(128)},
+				"write": primitive.M{"out": int32(1), "available": int32(127), "totalTickets": int32(128)},
+			},
+		},
+	})
+
+	assert.Equal(t, "wiredTiger", status.StorageEngine.Name)
+	require.NotNil(t, status.WiredTiger)
+	assert.Equal(t, int64(7), status.WiredTiger.Cache.ModifiedEvicted)
+	assert.InDelta(t, 0.96, status.WiredTiger.CacheUsage(), 0.001)
+	assert.InDelta(t, 0.1, status.WiredTiger.DirtyUsage(), 0.001)
+	assert.Equal(t, int64(127), status.Tickets().Write.Available)
+	assert.Equal(t, []string{"Cache is 96% full", "No read tickets available"}, status.StorageWarnings())
+}
+
+func TestServerStatusExecutionQueues(t *testing.T) {
+	status := decodeServerStatus(t, primitive.M{
+		"wiredTiger": primitive.M{
+			"concurrentTransactions": primitive.M{
+				"read": primitive.M{"out": int32(0), "available": int32(0), "totalTickets": int32(0)},
+			},
+		},
+		"queues": primitive.M{
+			"execution": primitive.M{
+				"read":  primitive.M{"out": int32(2), "available": int32(6), "totalTickets": int32(8)},
+				"write": primitive.M{"out": int32(8), "available": int32(0), "totalTickets": int32(8)},
+			},
+		},
+	})
+
+	assert.Equal(t, int64(6), status.Tickets().Read.Available)
+	assert.Equal(t, []string{"No write tickets available"}, status.StorageWarnings())
+}
+
+func TestServerStatusWithoutWiredTiger(t *testing.T) {
+	status := decodeServerStatus(t, primitive.M{"storageEngine": primitive.M{"name": "inMemory"}})
+
+	assert.Nil(t, status.WiredTiger)
+	assert.Equal(t, Tickets{}, status.Tickets())
+	assert.Empty(t, status.StorageWarnings())
+}
@@ -0,0 +1,187 @@
+package mongo
+
+import (
+	"regexp"
+	"strings"
+)
+
+// templatePlaceholderRegex matches placeholders of templates, e.g. {{field}}
+var templatePlaceholderRegex = regexp.MustCompile(`\{\{(\w+)\}\}`)
+
+// PipelineTemplate is a common pipeline pattern, Text is written in the
+// shell syntax with placeholders for field names, e.g. {{field}}
+type PipelineTemplate struct {
+	Name        string
+	Description string
+	Tags        []string
+	Text        string
+}
+
+var pipelineTemplates = []PipelineTemplate{
+	{
+		Name:        "Group by count",
+		Description: "Counts documents per value of the field, most common first.",
+		Tags:        []string{"$group", "$sort", "count", "histogram"},
+		Text: `[
+  { $group: { _id: "${{field}}", count: { $sum: 1 } } },
+  { $sort: { count: -1 } }
+]`,
+	},
+	{
+		Name:        "Daily buckets",
+		Description: "Counts documents per day of the date field.",
+		Tags:        []string{"$group", "$dateTrunc", "date", "time", "bucket"},
+		Text: `[
+  { $group: { _id: { $dateTrunc: { date: "${{dateField}}", unit: "day" } }, count: { $sum: 1 } } },
+  { $sort: { _id: 1 } }
+]`,
+	},
+	{
+		Name:        "Unwind and match",
+		Description: "Unwinds the array field and keeps elements matching the value.",
+		Tags:        []string{"$unwind", "$match", "array"},
+		Text: `[
+  { $unwind: "${{arrayField}}" },
+  { $match: { "{{arrayField}}": "{{value}}" } }
+]`,
+	},
+	{
+		Name:        "Lookup join",
+		Description: "Joins documents of another collection by the local and foreign field.",
+		Tags:        []string{"$lookup", "join", "relation"},
+		Text: `[
+  { $lookup: { from: "{{collection}}", localField: "{{localField}}", foreignField: "{{foreignField}}", as: "{{as}}" } }
+]`,
+	},
+	{
+		Name:        "Top N",
+		Description: "Returns documents with the highest values of the field.",
+		Tags:        []string{"$sort", "$limit", "top"},
+		Text: `[
+  { $sort: { {{field}}: -1 } },
+  { $limit: 10 }
+]`,
+	},
+	{
+		Name:        "Distinct values",
+		Description: "Lists distinct values of the field.",
+		Tags:        []string{"$group", "distinct", "unique"},
+		Text: `[
+  { $group: { _id: null, values: { $addToSet: "${{field}}" } } }
+]`,
+	},
+	{
+		Name:        "Find duplicates",
+		Description: "Finds values of the field shared by more than one document.",
+		Tags:        []string{"$group", "$match", "duplicates", "unique"},
+		Text: `[
+  { $group: { _id: "${{field}}", ids: { $push: "$_id" }, count: { $sum: 1 } } },
+  { $match: { count: { $gt: 1 } } }
+]`,
+	},
+	{
+		Name:        "Sum by group",
+		Description: "Sums values of the numeric field per value of the group field.",
+		Tags:        []string{"$group", "$sum", "total"},
+		Text: `[
+  { $group: { _id: "${{groupField}}", total: { $sum: "${{numberField}}" } } },
+  { $sort: { total: -1 } }
+]`,
+	},
+}
+
+// stageTemplates are skeletons of single stages
+// the pipeline is composed of in the editor
+var stageTemplates = []PipelineTemplate{
+	{
+		Name:        "$match",
+		Description: "Keeps documents matching the filter.",
+		Text:        `{ $match: { {{field}}: "{{value}}" } }`,
+	},
+	{
+		Name:        "$group",
+		Description: "Groups documents by the field and counts them.",
+		Text:        `{ $group: { _id: "${{field}}", count: { $sum: 1 } } }`,
+	},
+	{
+		Name:        "$sort",
+		Description: "Sorts documents by the field, 1 ascending, -1 descending.",
+		Text:        `{ $sort: { {{field}}: 1 } }`,
+	},
+	{
+		Name:        "$project",
+		Description: "Keeps only the listed fields.",
+		Text:        `{ $project: { {{field}}: 1 } }`,
+	},
+	{
+		Name:        "$lookup",
+		Description: "Joins documents of another collection.",
+		Text:        `{ $lookup: { from: "{{collection}}", localField: "{{localField}}", foreignField: "{{foreignField}}", as: "{{as}}" } }`,
+	},
+	{
+		Name:        "$unwind",
+		Description: "Outputs a document for every element of the array field.",
+		Text:        `{ $unwind: "${{arrayField}}" }`,
+	},
+	{
+		Name:        "$limit",
+		Description: "Passes only the first documents.",
+		Text:        `{ $limit: 10 }`,
+	},
+}
+
+// StageTemplates returns skeletons of the most common stages
+func StageTemplates() []PipelineTemplate {
+	return stageTemplates
+}
+
+// PipelineTemplates returns the built-in library of pipeline templates
+func PipelineTemplates() []PipelineTemplate {
+	return pipelineTemplates
+}
+
+// SearchPipelineTemplates returns templates matching every word of the
+// query in their name, description or tags, ignoring case
+func SearchPipelineTemplates(query string) []PipelineTemplate {
+	words := strings.Fields(strings.ToLower(query))
+	matching := []PipelineTemplate{}
+	for _, template := range pipelineTemplates {
+		searchable := strings.ToLower(template.Name + " " + template.Description + " " + strings.Join(template.Tags, " "))
+		matches := true
+		for _, word := range words {
+			if !strings.Contains(searchable, word) {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			matching = append(matching, template)
+		}
+	}
+	return matching
+}
+
+// Placeholders returns names of placeholders of the template,
+// in the order they first appear
+func (t PipelineTemplate) Placeholders() []string {
+	placeholders := []string{}
+	seen := map[string]bool{}
+	for _, match := range templatePlaceholderRegex.FindAllStringSubmatch(t.Text, -1) {
+		if !seen[match[1]] {
+			seen[match[1]] = true
+			placeholders = append(placeholders, match[1])
+		}
+	}
+	return placeholders
+}
+
+// Fill returns the text of the template with placeholders replaced
+// by values, placeholders without a value are kept as they are
+func (t PipelineTemplate) Fill(values map[string]string) string {
+	return templatePlaceholderRegex.ReplaceAllStringFunc(t.Text, func(match string) string {
+		if value, ok := values[match[2:len(match)-2]]; ok {
+			return value
+		}
+		return match
+	})
+}
@@ -0,0 +1,56 @@
+package mongo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestPipelineTemplatesParse(t *testing.T) {
+	for _, template := range PipelineTemplates() {
+		values := map[string]string{}
+		for _, placeholder := range template.Placeholders() {
+			values[placeholder] = "name"
+		}
+		_, err := ParseShellPipeline(template.Fill(values))
+		assert.NoError(t, err, template.Name)
+	}
+}
+
+func TestStageTemplatesParse(t *testing.T) {
+	for _, template := range StageTemplates() {
+		values := map[string]string{}
+		for _, placeholder := range template.Placeholders() {
+			values[placeholder] = "name"
+		}
+		pipeline, err := ParseShellPipeline(AppendStages("", template.Fill(values)))
+		require.NoError(t, err, template.Name)
+		require.Len(t, pipeline, 1, template.Name)
+		assert.Equal(t, template.Name, pipeline[0][0].Key)
+	}
+}
+
+func TestSearchPipelineTemplates(t *testing.T) {
+	found := SearchPipelineTemplates("JOIN")
+	require.Len(t, found, 1)
+	assert.Equal(t, "Lookup join", found[0].Name)
+
+	assert.Len(t, SearchPipelineTemplates(""), len(PipelineTemplates()))
+	assert.Empty(t, SearchPipelineTemplates("group join"))
+	for _, template := range SearchPipelineTemplates("$group count") {
+		assert.Contains(t, template.Tags, "$group")
+	}
+}
+
+func TestPipelineTemplateFill(t *testing.T) {
+	template := PipelineTemplate{Text: `[{ $unwind: "${{arrayField}}" }, { $match: { "{{arrayField}}": "{{value}}" } }]`}
+
+	assert.Equal(t, []string{"arrayField", "value"}, template.Placeholders())
+	assert.Equal(t, `[{ $unwind: "$tags" }, { $match: { "tags": "{{value}}" } }]`, template.Fill(map[string]string{"arrayField": "tags"}))
+
+	pipeline, err := ParseShellPipeline(template.Fill(map[string]string{"arrayField": "tags", "value": "go"}))
+	require.NoError(t, err)
+	assert.Equal(t, primitive.D{{Key: "tags", Value: "go"}}, pipeline[1][0].Value)
+}
@@ -0,0 +1,196 @@
+package mongo
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// TimeSeries are options of the time-series collection, documents
+// are measurements taken at TimeField of the source in MetaField.
+// Measurements are stored in buckets spanning at most BucketMaxSpanSeconds,
+// which start at times rounded down to BucketRoundingSeconds.
+type TimeSeries struct {
+	TimeField             string `bson:"timeField"`
+	MetaField             string `bson:"metaField,omitempty"`
+	Granularity           string `bson:"granularity,omitempty"`
+	BucketMaxSpanSeconds  int64  `bson:"bucketMaxSpanSeconds,omitempty"`
+	BucketRoundingSeconds int64  `bson:"bucketRoundingSeconds,omitempty"`
+	// ExpireAfterSeconds is an option of the collection, not of
+	// time-series, measurements don't expire if it's nil
+	ExpireAfterSeconds *int64 `bson:"-"`
+}
+
+// granularitySpans are bucket spans of granularities, in seconds,
+// used if the server doesn't return the span, e.g. before 6.3
+var granularitySpans = map[string]int64{
+	"seconds": 3600,
+	"minutes": 24 * 3600,
+	"hours":   30 * 24 * 3600,
+}
+
+// TimeRange presets of the time-series filter
+const (
+	TimeRangeHour   = "Last hour"
+	TimeRangeDay    = "Last day"
+	TimeRangeWeek   = "Last week"
+	TimeRangeCustom = "Custom"
+)
+
+// TimeRanges are presets in the order they're offered
+var TimeRanges = []string{TimeRangeHour, TimeRangeDay, TimeRangeWeek, TimeRangeCustom}
+
+// timeLayouts are layouts of times typed in the custom range,
+// times without the zone are in the local time
+var timeLayouts = []string{time.RFC3339, "2006-01-02 15:04:05", "2006-01-02 15:04", "2006-01-02"}
+
+var timeRangeDurations = map[string]time.Duration{
+	TimeRangeHour: time.Hour,
+	TimeRangeDay:  24 * time.Hour,
+	TimeRangeWeek: 7 * 24 * time.Hour,
+}
+
+// GetTimeSeries returns options of the time-series collection,
+// nil if the collection isn't a time-series one
+func (d *Dao) GetTimeSeries(ctx context.Context, db, coll string) (*TimeSeries, error) {
+	specs, err := d.client.Database(db).ListCollectionSpecifications(ctx, primitive.M{"name": coll})
+	if err != nil {
+		return nil, err
+	}
+	if len(specs) == 0 || specs[0].Type != "timeseries" {
+		return nil, nil
+	}
+	return ParseTimeSeries(specs[0].Options)
+}
+
+// ParseTimeSeries returns time-series options of options
+// of the collection, nil if there are none
+func ParseTimeSeries(options bson.Raw) (*TimeSeries, error) {
+	value, err := options.LookupErr("timeseries")
+	if err != nil {
+		return nil, nil
+	}
+	timeSeries := &TimeSeries{}
+	if err := value.Unmarshal(timeSeries); err != nil {
+		return nil, fmt.Errorf("invalid time-series options: %w", err)
+	}
+	if timeSeries.TimeField == "" {
+		return nil, fmt.Errorf("invalid time-series options: timeField is missing")
+	}
+	if timeSeries.Granularity == "" && timeSeries.BucketMaxSpanSeconds == 0 {
+		timeSeries.Granularity = "seconds"
+	}
+	if expire, err := options.LookupErr("expireAfterSeconds"); err == nil {
+		if seconds, ok := expire.AsInt64OK(); ok {
+			timeSeries.ExpireAfterSeconds = &seconds
+		}
+	}
+	return timeSeries, nil
+}
+
+// BucketSpan returns the maximum time span of buckets in seconds,
+// of the granularity if the span isn't set explicitly
+func (t *TimeSeries) BucketSpan() int64 {
+	if t.BucketMaxSpanSeconds > 0 {
+		return t.BucketMaxSpanSeconds
+	}
+	return granularitySpans[t.Granularity]
+}
+
+// BucketRounding returns the time in seconds which starts of buckets
+// are rounded down to, the same as the span with granularity
+func (t *TimeSeries) BucketRounding() int64 {
+	if t.BucketRoundingSeconds > 0 {
+		return t.BucketRoundingSeconds
+	}
+	return t.BucketSpan()
+}
+
+// ParseExpireAfter parses the expiry of measurements typed as seconds,
+// or a number with s, m, h or d unit, e.g. 30d. Empty text or "off"
+// disables the expiry, which is returned as nil.
+func ParseExpireAfter(text string) (*int64, error) {
+	text = strings.ToLower(strings.TrimSpace(text))
+	if text == "" || text == "off" {
+		return nil, nil
+	}
+	units := map[byte]int64{'s': 1, 'm': 60, 'h': 3600, 'd': 86400}
+	multiplier := int64(1)
+	if unit, ok := units[text[len(text)-1]]; ok {
+		multiplier, text = unit, text[:len(text)-1]
+	}
+	n, err := strconv.ParseInt(strings.TrimSpace(text), 10, 64)
+	if err != nil || n < 0 {
+		return nil, fmt.Errorf("invalid expiry, expected seconds, e.g. 3600, a number with unit, e.g. 30d, or off")
+	}
+	seconds := n * multiplier
+	return &seconds, nil
+}
+
+// SetExpireAfter changes after how many seconds measurements of the
+// time-series collection expire, nil turns the expiry off
+func (d *Dao) SetExpireAfter(ctx context.Context, db, coll string, seconds *int64) error {
+	if err := d.checkWritable(); err != nil {
+		return err
+	}
+	var expire interface{} = "off"
+	if seconds != nil {
+		expire = *seconds
+	}
+	command := bson.D{{Key: "collMod", Value: coll}, {Key: "expireAfterSeconds", Value: expire}}
+	return d.client.Database(db).RunCommand(ctx, command).Err()
+}
+
+// DefaultSort returns the sort of newest measurements first
+func (t *TimeSeries) DefaultSort() string {
+	return fmt.Sprintf(`{ %q: -1 }`, t.TimeField)
+}
+
+func (t *TimeSeries) String() string {
+	info := "time: " + t.TimeField
+	if t.MetaField != "" {
+		info += ", meta: " + t.MetaField
+	}
+	if t.Granularity != "" {
+		info += ", granularity: " + t.Granularity
+	}
+	return info
+}
+
+// TimeRange returns the start and end of the preset range ending now
+func TimeRange(preset string, now time.Time) (time.Time, time.Time, error) {
+	duration, ok := timeRangeDurations[preset]
+	if !ok {
+		return time.Time{}, time.Time{}, fmt.Errorf("unknown time range %q", preset)
+	}
+	return now.Add(-duration), now, nil
+}
+
+// ParseTime parses the time typed as RFC 3339, or as the local
+// date with optional time, e.g. "2024-05-01 12:30"
+func ParseTime(text string) (time.Time, error) {
+	for _, layout := range timeLayouts {
+		if t, err := time.ParseInLocation(layout, text, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q, expected e.g. 2024-05-01 12:30 or 2024-05-01T12:30:00Z", text)
+}
+
+// TimeRangeFilter returns the filter of documents which field is
+// within [from, to), as it's typed in the query bar
+func TimeRangeFilter(field string, from, to time.Time) (string, error) {
+	switch {
+	case field == "":
+		return "", fmt.Errorf("field is required")
+	case !from.Before(to):
+		return "", fmt.Errorf("start of the range must be before its end")
+	}
+	return fmt.Sprintf(`{ %q: { "$gte": { "$date": %q }, "$lt": { "$date": %q } } }`,
+		field, from.UTC().Format(time.RFC3339), to.UTC().Format(time.RFC3339)), nil
+}
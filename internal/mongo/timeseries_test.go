@@ -0,0 +1,117 @@
+package mongo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestParseTimeSeries(t *testing.T) {
+	options, err := bson.Marshal(bson.D{{Key: "timeseries", Value: bson.D{
+		{Key: "timeField", Value: "ts"},
+		{Key: "metaField", Value: "sensor"},
+		{Key: "granularity", Value: "minutes"},
+	}}})
+	require.NoError(t, err)
+
+	timeSeries, err := ParseTimeSeries(options)
+	require.NoError(t, err)
+	require.NotNil(t, timeSeries)
+	assert.Equal(t, "time: ts, meta: sensor, granularity: minutes", timeSeries.String())
+	assert.Equal(t, `{ "ts": -1 }`, timeSeries.DefaultSort())
+	assert.Equal(t, int64(86400), timeSeries.BucketSpan())
+	assert.Equal(t, int64(86400), timeSeries.BucketRounding())
+	assert.Nil(t, timeSeries.ExpireAfterSeconds)
+
+	options, err = bson.Marshal(bson.D{
+		{Key: "timeseries", Value: bson.D{
+			{Key: "timeField", Value: "ts"},
+			{Key: "bucketMaxSpanSeconds", Value: int32(7200)},
+			{Key: "bucketRoundingSeconds", Value: int32(3600)},
+		}},
+		{Key: "expireAfterSeconds", Value: int64(2592000)},
+	})
+	require.NoError(t, err)
+	timeSeries, err = ParseTimeSeries(options)
+	require.NoError(t, err)
+	assert.Empty(t, timeSeries.Granularity)
+	assert.Equal(t, int64(7200), timeSeries.BucketSpan())
+	assert.Equal(t, int64(3600), timeSeries.BucketRounding())
+	require.NotNil(t, timeSeries.ExpireAfterSeconds)
+	assert.Equal(t, int64(2592000), *timeSeries.ExpireAfterSeconds)
+
+	options, err = bson.Marshal(bson.D{{Key: "capped", Value: true}})
+	require.NoError(t, err)
+	timeSeries, err = ParseTimeSeries(options)
+	require.NoError(t, err)
+	assert.Nil(t, timeSeries)
+
+	options, err = bson.Marshal(bson.D{{Key: "timeseries", Value: bson.D{{Key: "metaField", Value: "sensor"}}}})
+	require.NoError(t, err)
+	_, err = ParseTimeSeries(options)
+	assert.Error(t, err)
+}
+
+func TestTimeRangeFilter(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC)
+	from, to, err := TimeRange(TimeRangeDay, now)
+	require.NoError(t, err)
+	assert.Equal(t, now.Add(-24*time.Hour), from)
+	assert.Equal(t, now, to)
+	_, _, err = TimeRange(TimeRangeCustom, now)
+	assert.Error(t, err)
+
+	filter, err := TimeRangeFilter("ts", from, to)
+	require.NoError(t, err)
+	assert.Equal(t, `{ "ts": { "$gte": { "$date": "2024-04-30T12:30:00Z" }, "$lt": { "$date": "2024-05-01T12:30:00Z" } } }`, filter)
+
+	parsed, err := ParseStringQuery(filter)
+	require.NoError(t, err)
+	rangeFilter, ok := parsed["ts"].(primitive.M)
+	require.True(t, ok)
+	assert.Equal(t, primitive.NewDateTimeFromTime(from), rangeFilter["$gte"])
+
+	_, err = TimeRangeFilter("ts", to, from)
+	assert.Error(t, err)
+	_, err = TimeRangeFilter("", from, to)
+	assert.Error(t, err)
+}
+
+func TestParseTime(t *testing.T) {
+	parsed, err := ParseTime("2024-05-01T12:30:00Z")
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC), parsed.UTC())
+
+	parsed, err = ParseTime("2024-05-01 12:30")
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 5, 1, 12, 30, 0, 0, time.Local), parsed)
+
+	parsed, err = ParseTime("2024-05-01")
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 5, 1, 0, 0, 0, 0, time.Local), parsed)
+
+	_, err = ParseTime("yesterday")
+	assert.Error(t, err)
+}
+
+func TestParseExpireAfter(t *testing.T) {
+	for text, want := range map[string]int64{"3600": 3600, "30d": 2592000, "12h": 43200, "90 m": 5400, "0": 0} {
+		seconds, err := ParseExpireAfter(text)
+		require.NoError(t, err, text)
+		require.NotNil(t, seconds, text)
+		assert.Equal(t, want, *seconds, text)
+	}
+	for _, text := range []string{"", "off", " OFF "} {
+		seconds, err := ParseExpireAfter(text)
+		require.NoError(t, err)
+		assert.Nil(t, seconds)
+	}
+	for _, text := range []string{"-5", "d", "a week", "1.5h"} {
+		_, err := ParseExpireAfter(text)
+		assert.Error(t, err, text)
+	}
+}
@@ -0,0 +1,76 @@
+package mongo
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/kopecmaciej/vi-mongo/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeTestCertificate writes the self-signed certificate and its key
+// as PEM files and returns their paths
+func writeTestCertificate(t *testing.T, dir string) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "vi-mongo"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+		IsCA:         true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	certPath := filepath.Join(dir, "cert.pem")
+	keyPath := filepath.Join(dir, "key.pem")
+	require.NoError(t, os.WriteFile(certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	require.NoError(t, os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0o600))
+	return certPath, keyPath
+}
+
+func TestTLSClientConfig(t *testing.T) {
+	dir := t.TempDir()
+	certPath, keyPath := writeTestCertificate(t, dir)
+
+	tlsConfig, err := TLSClientConfig(&config.TLSConfig{CAFile: certPath, CertFile: certPath, KeyFile: keyPath})
+	require.NoError(t, err)
+	assert.NotNil(t, tlsConfig.RootCAs)
+	assert.Len(t, tlsConfig.Certificates, 1)
+	assert.False(t, tlsConfig.InsecureSkipVerify)
+
+	// the key can be in the same file as the certificate
+	combined := filepath.Join(dir, "combined.pem")
+	cert, err := os.ReadFile(certPath)
+	require.NoError(t, err)
+	key, err := os.ReadFile(keyPath)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(combined, append(cert, key...), 0o600))
+	tlsConfig, err = TLSClientConfig(&config.TLSConfig{CertFile: combined})
+	require.NoError(t, err)
+	assert.Len(t, tlsConfig.Certificates, 1)
+	assert.Nil(t, tlsConfig.RootCAs)
+
+	tlsConfig, err = TLSClientConfig(&config.TLSConfig{InsecureSkipVerify: true})
+	require.NoError(t, err)
+	assert.True(t, tlsConfig.InsecureSkipVerify)
+	assert.Empty(t, tlsConfig.Certificates)
+
+	_, err = TLSClientConfig(&config.TLSConfig{CAFile: keyPath})
+	assert.ErrorContains(t, err, "no PEM certificates")
+	_, err = TLSClientConfig(&config.TLSConfig{CertFile: filepath.Join(dir, "missing.pem")})
+	assert.ErrorContains(t, err, "loading client certificate")
+}
@@ -0,0 +1,183 @@
+package mongo
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Actions of user management, checked before the command is run
+const (
+	ActionCreateUser     = "createUser"
+	ActionDropUser       = "dropUser"
+	ActionChangePassword = "changePassword"
+)
+
+// RoleRef is a role granted to the user, roles are defined in a database
+// and granted in any of them, e.g. readWrite of "shop" to a user of "admin"
+type RoleRef struct {
+	Role string `bson:"role"`
+	Db   string `bson:"db"`
+}
+
+// String returns the role as "role@db"
+func (r RoleRef) String() string {
+	return r.Role + "@" + r.Db
+}
+
+// DbUser is a user defined in the database, its authentication database
+type DbUser struct {
+	User  string    `bson:"user"`
+	Db    string    `bson:"db"`
+	Roles []RoleRef `bson:"roles"`
+}
+
+// Role is a built-in or user-defined role of the database
+type Role struct {
+	Role      string    `bson:"role"`
+	Db        string    `bson:"db"`
+	IsBuiltin bool      `bson:"isBuiltin"`
+	Roles     []RoleRef `bson:"roles"`
+}
+
+// ParseRoles parses comma separated roles, e.g. "readWrite, read@admin",
+// roles without the database are granted in the database of the user
+func ParseRoles(text, db string) ([]RoleRef, error) {
+	roles := []RoleRef{}
+	for _, part := range strings.Split(text, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		role, roleDb, found := strings.Cut(part, "@")
+		if !found {
+			roleDb = db
+		}
+		role, roleDb = strings.TrimSpace(role), strings.TrimSpace(roleDb)
+		if role == "" || roleDb == "" {
+			return nil, fmt.Errorf("invalid role %q, expected role or role@db", part)
+		}
+		roles = append(roles, RoleRef{Role: role, Db: roleDb})
+	}
+	return roles, nil
+}
+
+// ListUsers returns users of the database sorted by name,
+// users of all databases if the database is empty
+func (d *Dao) ListUsers(ctx context.Context, db string) ([]DbUser, error) {
+	var command primitive.D
+	if db == "" {
+		db = "admin"
+		command = primitive.D{{Key: "usersInfo", Value: primitive.M{"forAllDBs": true}}}
+	} else {
+		command = primitive.D{{Key: "usersInfo", Value: 1}}
+	}
+
+	var result struct {
+		Users []DbUser `bson:"users"`
+	}
+	if err := d.client.Database(db).RunCommand(ctx, command).Decode(&result); err != nil {
+		return nil, err
+	}
+	sort.Slice(result.Users, func(i, j int) bool {
+		if result.Users[i].Db != result.Users[j].Db {
+			return result.Users[i].Db < result.Users[j].Db
+		}
+		return result.Users[i].User < result.Users[j].User
+	})
+	return result.Users, nil
+}
+
+// ListRoles returns built-in and user-defined roles of the database,
+// user-defined ones first
+func (d *Dao) ListRoles(ctx context.Context, db string) ([]Role, error) {
+	command := primitive.D{
+		{Key: "rolesInfo", Value: 1},
+		{Key: "showBuiltinRoles", Value: true},
+	}
+	var result struct {
+		Roles []Role `bson:"roles"`
+	}
+	if err := d.client.Database(db).RunCommand(ctx, command).Decode(&result); err != nil {
+		return nil, err
+	}
+	sort.SliceStable(result.Roles, func(i, j int) bool {
+		if result.Roles[i].IsBuiltin != result.Roles[j].IsBuiltin {
+			return !result.Roles[i].IsBuiltin
+		}
+		return result.Roles[i].Role < result.Roles[j].Role
+	})
+	return result.Roles, nil
+}
+
+// CreateUser creates the user in the database with given roles
+func (d *Dao) CreateUser(ctx context.Context, db, user, password string, roles []RoleRef) error {
+	if err := d.checkUserCommand(ActionCreateUser, db); err != nil {
+		return err
+	}
+	if user == "" || password == "" {
+		return fmt.Errorf("user name and password are required")
+	}
+
+	command := primitive.D{
+		{Key: "createUser", Value: user},
+		{Key: "pwd", Value: password},
+		{Key: "roles", Value: roles},
+	}
+	if err := d.client.Database(db).RunCommand(ctx, command).Err(); err != nil {
+		return err
+	}
+
+	log.Debug().Msgf("User created, db: %v, user: %v", db, user)
+
+	return nil
+}
+
+// ChangePassword sets the new password of the user
+func (d *Dao) ChangePassword(ctx context.Context, db, user, password string) error {
+	if err := d.checkUserCommand(ActionChangePassword, db); err != nil {
+		return err
+	}
+	if password == "" {
+		return fmt.Errorf("password is required")
+	}
+
+	command := primitive.D{
+		{Key: "updateUser", Value: user},
+		{Key: "pwd", Value: password},
+	}
+	if err := d.client.Database(db).RunCommand(ctx, command).Err(); err != nil {
+		return err
+	}
+
+	log.Debug().Msgf("Password changed, db: %v, user: %v", db, user)
+
+	return nil
+}
+
+// DropUser removes the user from the database
+func (d *Dao) DropUser(ctx context.Context, db, user string) error {
+	if err := d.checkUserCommand(ActionDropUser, db); err != nil {
+		return err
+	}
+
+	command := primitive.D{{Key: "dropUser", Value: user}}
+	if err := d.client.Database(db).RunCommand(ctx, command).Err(); err != nil {
+		return err
+	}
+
+	log.Debug().Msgf("User dropped, db: %v, user: %v", db, user)
+
+	return nil
+}
+
+func (d *Dao) checkUserCommand(action, db string) error {
+	if err := d.checkWritable(); err != nil {
+		return err
+	}
+	return d.CheckPrivilege(action, db, "")
+}
@@ -0,0 +1,29 @@
+package mongo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRoles(t *testing.T) {
+	roles, err := ParseRoles("readWrite, read@admin ,, dbAdmin @ reports", "shop")
+	require.NoError(t, err)
+	assert.Equal(t, []RoleRef{
+		{Role: "readWrite", Db: "shop"},
+		{Role: "read", Db: "admin"},
+		{Role: "dbAdmin", Db: "reports"},
+	}, roles)
+
+	roles, err = ParseRoles("", "shop")
+	require.NoError(t, err)
+	assert.Empty(t, roles)
+
+	_, err = ParseRoles("read@", "shop")
+	assert.Error(t, err)
+}
+
+func TestRoleRefString(t *testing.T) {
+	assert.Equal(t, "readWrite@shop", RoleRef{Role: "readWrite", Db: "shop"}.String())
+}
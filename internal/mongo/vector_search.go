@@ -0,0 +1,149 @@
+package mongo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	// vectorCandidatesRatio is the number of candidates per returned
+	// document, more candidates give better accuracy but are slower
+	vectorCandidatesRatio = 10
+	// maxVectorCandidates is the max number of candidates allowed by the server
+	maxVectorCandidates = 10000
+)
+
+// VectorSearchQuery is a query of Atlas Vector Search index
+type VectorSearchQuery struct {
+	Index string
+	// Path is the field with vectors
+	Path   string
+	Vector []float64
+	// NumCandidates is the number of nearest neighbors considered,
+	// it's derived from the limit if it's zero
+	NumCandidates int64
+}
+
+// IsVectorSearch returns true for indexes used by $vectorSearch
+func (i SearchIndex) IsVectorSearch() bool {
+	return i.Type == "vectorSearch"
+}
+
+// VectorPaths returns paths of fields indexed as vectors
+func (i SearchIndex) VectorPaths() []string {
+	fields, _ := i.LatestDefinition["fields"].(primitive.A)
+	var paths []string
+	for _, f := range fields {
+		field, ok := f.(primitive.M)
+		if !ok || field["type"] != "vector" {
+			continue
+		}
+		if path, ok := field["path"].(string); ok {
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
+// ParseQueryVector returns the vector given as JSON array of numbers,
+// or the vector in the field of the document, if the name of the field
+// is given, so documents similar to the document can be found
+func ParseQueryVector(input string, doc primitive.M) ([]float64, error) {
+	input = strings.TrimSpace(input)
+	if input == "" {
+		return nil, fmt.Errorf("vector cannot be empty")
+	}
+	if strings.HasPrefix(input, "[") {
+		var vector []float64
+		if err := json.Unmarshal([]byte(input), &vector); err != nil {
+			return nil, fmt.Errorf("invalid vector: %w", err)
+		}
+		return vector, nil
+	}
+
+	value, ok := lookupPath(doc, input)
+	if !ok {
+		return nil, fmt.Errorf("field %s not found in the selected document", input)
+	}
+	values, ok := value.(primitive.A)
+	if !ok {
+		return nil, fmt.Errorf("field %s is not an array of numbers", input)
+	}
+	vector := make([]float64, 0, len(values))
+	for _, v := range values {
+		n, ok := toFloat(v)
+		if !ok {
+			return nil, fmt.Errorf("field %s is not an array of numbers", input)
+		}
+		vector = append(vector, n)
+	}
+	return vector, nil
+}
+
+// VectorSearch runs the query and returns the most similar documents,
+// vectors are not returned, as they are too long to be shown
+func (d *Dao) VectorSearch(ctx context.Context, db, collection string, query VectorSearchQuery, limit int64) ([]SearchResult, error) {
+	pipeline, err := query.Pipeline(limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return d.aggregateSearch(ctx, db, collection, pipeline)
+}
+
+// Pipeline returns the aggregation pipeline of the query,
+// similarity score is added to the found documents
+func (q VectorSearchQuery) Pipeline(limit int64) ([]primitive.M, error) {
+	if q.Path == "" {
+		return nil, fmt.Errorf("vector search requires the path of the field")
+	}
+	if len(q.Vector) == 0 {
+		return nil, fmt.Errorf("vector cannot be empty")
+	}
+	if limit <= 0 {
+		return nil, fmt.Errorf("vector search requires a limit")
+	}
+
+	candidates := q.NumCandidates
+	if candidates == 0 {
+		candidates = min(limit*vectorCandidatesRatio, maxVectorCandidates)
+	}
+	if candidates < limit {
+		return nil, fmt.Errorf("number of candidates can't be lower than the limit %d", limit)
+	}
+
+	search := primitive.M{
+		"path":          q.Path,
+		"queryVector":   q.Vector,
+		"numCandidates": candidates,
+		"limit":         limit,
+	}
+	if q.Index != "" {
+		search["index"] = q.Index
+	}
+
+	return []primitive.M{
+		{"$vectorSearch": search},
+		{"$addFields": primitive.M{searchScoreField: primitive.M{"$meta": "vectorSearchScore"}}},
+		{"$project": primitive.M{q.Path: 0}},
+	}, nil
+}
+
+// lookupPath returns the value of the dotted path in the document
+func lookupPath(doc primitive.M, path string) (interface{}, bool) {
+	var value interface{} = doc
+	for _, key := range strings.Split(path, ".") {
+		nested, ok := value.(primitive.M)
+		if !ok {
+			return nil, false
+		}
+		if value, ok = nested[key]; !ok {
+			return nil, false
+		}
+	}
+	return value, true
+}
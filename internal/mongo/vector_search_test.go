@@ -0,0 +1,63 @@
+package mongo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestParseQueryVector(t *testing.T) {
+	vector, err := ParseQueryVector("[0.5, -1, 2]", nil)
+	require.NoError(t, err)
+	assert.Equal(t, []float64{0.5, -1, 2}, vector)
+
+	doc := primitive.M{"meta": primitive.M{"embedding": primitive.A{0.5, int32(1), int64(2)}}, "title": "MongoDB"}
+	vector, err = ParseQueryVector("meta.embedding", doc)
+	require.NoError(t, err)
+	assert.Equal(t, []float64{0.5, 1, 2}, vector)
+
+	_, err = ParseQueryVector("title", doc)
+	assert.Error(t, err)
+	_, err = ParseQueryVector("missing", doc)
+	assert.Error(t, err)
+	_, err = ParseQueryVector("[0.5, \"a\"]", doc)
+	assert.Error(t, err)
+	_, err = ParseQueryVector(" ", doc)
+	assert.Error(t, err)
+}
+
+func TestVectorSearchQueryPipeline(t *testing.T) {
+	query := VectorSearchQuery{Index: "vectors", Path: "embedding", Vector: []float64{0.1, 0.2}}
+	pipeline, err := query.Pipeline(5)
+	require.NoError(t, err)
+	require.Len(t, pipeline, 3)
+	assert.Equal(t, primitive.M{
+		"index":         "vectors",
+		"path":          "embedding",
+		"queryVector":   []float64{0.1, 0.2},
+		"numCandidates": int64(50),
+		"limit":         int64(5),
+	}, pipeline[0]["$vectorSearch"])
+	assert.Equal(t, primitive.M{"embedding": 0}, pipeline[2]["$project"])
+
+	query.NumCandidates = 2
+	_, err = query.Pipeline(5)
+	assert.Error(t, err)
+	_, err = VectorSearchQuery{Path: "embedding"}.Pipeline(5)
+	assert.Error(t, err)
+}
+
+func TestSearchIndexVectorPaths(t *testing.T) {
+	index := SearchIndex{
+		Type: "vectorSearch",
+		LatestDefinition: primitive.M{"fields": primitive.A{
+			primitive.M{"type": "vector", "path": "embedding", "numDimensions": 3},
+			primitive.M{"type": "filter", "path": "genre"},
+		}},
+	}
+	assert.True(t, index.IsVectorSearch())
+	assert.False(t, index.IsSearch())
+	assert.Equal(t, []string{"embedding"}, index.VectorPaths())
+}
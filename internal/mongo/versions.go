@@ -0,0 +1,119 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// historySuffix is appended to the name of the collection
+// which keeps previous versions of the documents
+const historySuffix = ".__vi_mongo_history"
+
+const (
+	VersionUpdate  = "update"
+	VersionDelete  = "delete"
+	VersionRestore = "restore"
+)
+
+// DocumentVersion is a copy of the document saved right before
+// it was modified, together with the operation that modified it
+type DocumentVersion struct {
+	ID         primitive.ObjectID `bson:"_id"`
+	DocumentID interface{}        `bson:"documentId"`
+	Operation  string             `bson:"operation"`
+	SavedAt    time.Time          `bson:"savedAt"`
+	Document   primitive.M        `bson:"document"`
+}
+
+// HistoryCollection returns the name of the collection
+// with previous versions of documents of the collection
+func HistoryCollection(coll string) string {
+	return coll + historySuffix
+}
+
+// IsHistoryCollection returns true if the collection
+// keeps previous versions of documents
+func IsHistoryCollection(coll string) bool {
+	return strings.HasSuffix(coll, historySuffix)
+}
+
+// savePreImage saves the current version of the document before it's
+// modified, if document history is enabled for the connection.
+// Missing document is not an error, as there is nothing to save.
+func (d *Dao) savePreImage(ctx context.Context, db, collection string, id interface{}, operation string) error {
+	if !d.Config.DocumentHistory || IsHistoryCollection(collection) {
+		return nil
+	}
+
+	var document primitive.M
+	err := d.client.Database(db).Collection(collection).FindOne(ctx, primitive.M{"_id": id}).Decode(&document)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil
+		}
+		return err
+	}
+
+	version := DocumentVersion{
+		ID:         primitive.NewObjectID(),
+		DocumentID: id,
+		Operation:  operation,
+		SavedAt:    time.Now().UTC(),
+		Document:   document,
+	}
+	if _, err := d.client.Database(db).Collection(HistoryCollection(collection)).InsertOne(ctx, version); err != nil {
+		return err
+	}
+
+	log.Debug().Msgf("Document version saved, id: %v, db: %v, collection: %v", id, db, collection)
+
+	return nil
+}
+
+// ListDocumentVersions returns saved versions of the document, newest first
+func (d *Dao) ListDocumentVersions(ctx context.Context, db, collection string, id interface{}) ([]DocumentVersion, error) {
+	findOptions := options.Find().SetSort(primitive.D{{Key: "savedAt", Value: -1}})
+	cursor, err := d.readCollection(db, HistoryCollection(collection)).Find(ctx, primitive.M{"documentId": id}, findOptions)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var versions []DocumentVersion
+	if err := cursor.All(ctx, &versions); err != nil {
+		return nil, err
+	}
+	return versions, nil
+}
+
+// RestoreDocumentVersion replaces the document with the saved version,
+// the document is inserted again if it was deleted. Current version is
+// saved before, so the restore can be undone as well.
+func (d *Dao) RestoreDocumentVersion(ctx context.Context, db, collection string, version DocumentVersion) error {
+	if err := d.checkWritable(); err != nil {
+		return err
+	}
+	if err := d.savePreImage(ctx, db, collection, version.DocumentID, VersionRestore); err != nil {
+		return err
+	}
+
+	_, err := d.client.Database(db).Collection(collection).ReplaceOne(ctx,
+		primitive.M{"_id": version.DocumentID},
+		version.Document,
+		options.Replace().SetUpsert(true),
+	)
+	if err != nil {
+		return err
+	}
+
+	log.Debug().Msgf("Document version restored, id: %v, db: %v, collection: %v", version.DocumentID, db, collection)
+
+	return nil
+}
@@ -0,0 +1,14 @@
+package mongo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHistoryCollection(t *testing.T) {
+	history := HistoryCollection("users")
+	assert.Equal(t, "users.__vi_mongo_history", history)
+	assert.True(t, IsHistoryCollection(history))
+	assert.False(t, IsHistoryCollection("users"))
+}
@@ -1,12 +1,30 @@
 package tui
 
 import (
+	"context"
+	"fmt"
+	"os"
+	"reflect"
+	"time"
+
+	"github.com/atotto/clipboard"
 	"github.com/gdamore/tcell/v2"
 	"github.com/kopecmaciej/vi-mongo/internal/config"
 	"github.com/kopecmaciej/vi-mongo/internal/mongo"
 	"github.com/kopecmaciej/vi-mongo/internal/tui/core"
 	"github.com/kopecmaciej/vi-mongo/internal/tui/modal"
 	"github.com/kopecmaciej/vi-mongo/internal/tui/page"
+	"github.com/kopecmaciej/vi-mongo/internal/util"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	keysWatchInterval = 2 * time.Second
+	// updateCheckTimeout limits the release check, so it doesn't
+	// keep running on slow networks
+	updateCheckTimeout = 5 * time.Second
+	// devVersion is the version of builds without the release version set
+	devVersion = "v0.0.0"
 )
 
 type (
@@ -18,6 +36,10 @@ type (
 		connection *page.Connection
 		main       *page.Main
 		help       *page.Help
+		jobs       *modal.Jobs
+		update     *modal.UpdateModal
+
+		version string
 	}
 )
 
@@ -30,6 +52,9 @@ func NewApp(appConfig *config.Config) *App {
 		connection: page.NewConnection(),
 		main:       page.NewMain(),
 		help:       page.NewHelp(),
+		jobs:       modal.NewJobsModal(),
+		update:     modal.NewUpdateModal(),
+		version:    devVersion,
 	}
 
 	return app
@@ -37,27 +62,42 @@ func NewApp(appConfig *config.Config) *App {
 
 // Init initializes app
 func (a *App) Init() error {
-	a.SetRoot(a.Pages, true).EnableMouse(true)
+	a.SetRoot(a.Pages, true).EnableMouse(true).EnablePaste(true)
 
 	err := a.help.Init(a.App)
 	if err != nil {
 		return err
 	}
+	if err := a.jobs.Init(a.App); err != nil {
+		return err
+	}
+	if err := a.update.Init(a.App); err != nil {
+		return err
+	}
 	a.setKeybindings()
 
 	if err := a.connection.Init(a.App); err != nil {
 		return err
 	}
 
+	go a.watchKeybindings()
+
 	return nil
 }
 
+// SetVersion sets the version of the app, newer releases
+// are looked up when update checks are enabled
+func (a *App) SetVersion(version string) {
+	a.version = version
+}
+
 func (a *App) Run() error {
 	return a.Application.Run()
 }
 
 func (a *App) setKeybindings() {
 	a.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		a.MarkActive()
 		switch {
 		case a.GetKeys().Contains(a.GetKeys().Global.OpenConnection, event.Name()):
 			a.renderConnection()
@@ -65,6 +105,13 @@ func (a *App) setKeybindings() {
 		case a.GetKeys().Contains(a.GetKeys().Global.ShowStyleModal, event.Name()):
 			a.ShowStyleChangeModal()
 			return nil
+		case a.GetKeys().Contains(a.GetKeys().Global.ShowJobs, event.Name()):
+			if a.Pages.HasPage(modal.JobsModal) {
+				a.Pages.RemovePage(modal.JobsModal)
+				return nil
+			}
+			a.jobs.Render()
+			return nil
 		case a.GetKeys().Contains(a.GetKeys().Global.ToggleFullScreenHelp, event.Name()):
 			if a.Pages.HasPage(page.HelpPage) {
 				a.Pages.RemovePage(page.HelpPage)
@@ -76,33 +123,78 @@ func (a *App) setKeybindings() {
 			}
 			a.Pages.AddPage(page.HelpPage, a.help, true, true)
 			return nil
+		case a.GetKeys().Contains(a.GetKeys().Global.CopyScreen, event.Name()):
+			a.copyScreen()
+			return nil
+		case a.GetKeys().Contains(a.GetKeys().Global.SaveScreen, event.Name()):
+			a.saveScreen()
+			return nil
 		}
 		return event
 	})
 }
 
-func (a *App) connectToMongo() error {
-	currConn := a.App.GetConfig().GetCurrentConnection()
-	if a.GetDao() != nil && *a.GetDao().Config == *currConn {
-		return nil
+// copyScreen copies the screen as plain text to the clipboard,
+// e.g. to paste it into an issue
+func (a *App) copyScreen() {
+	text, err := a.ScreenText()
+	if err != nil {
+		modal.ShowError(a.Pages, "Error capturing screen", err)
+		return
+	}
+	if err := clipboard.WriteAll(text); err != nil {
+		modal.ShowError(a.Pages, fmt.Sprintf("Error copying screen, press %s to save it to a file instead", a.GetKeys().Global.SaveScreen.String()), err)
+		return
+	}
+	modal.ShowInfo(a.Pages, "Screen copied to clipboard")
+}
+
+// saveScreen saves the screen as plain text to a file in the working directory
+func (a *App) saveScreen() {
+	text, err := a.ScreenText()
+	if err != nil {
+		modal.ShowError(a.Pages, "Error capturing screen", err)
+		return
+	}
+	fileName := util.ScreenshotFileName(time.Now())
+	if err := os.WriteFile(fileName, []byte(text), 0644); err != nil {
+		modal.ShowError(a.Pages, "Error saving screen", err)
+		return
 	}
+	modal.ShowInfo(a.Pages, fmt.Sprintf("Screen saved to %s", fileName))
+}
+
+// isConnectedTo returns true if the current dao uses the given connection
+func (a *App) isConnectedTo(conn *config.MongoConfig) bool {
+	return a.GetDao() != nil && reflect.DeepEqual(*a.GetDao().Config, *conn)
+}
 
-	client := mongo.NewClient(currConn)
+// dialMongo connects to the database, it doesn't touch the UI,
+// so it can be called outside of the main goroutine
+func dialMongo(conn *config.MongoConfig) (*mongo.Dao, error) {
+	client := mongo.NewClient(conn)
 	if err := client.Connect(); err != nil {
-		return err
+		return nil, err
 	}
 	if err := client.Ping(); err != nil {
-		return err
+		return nil, err
 	}
-	a.SetDao(mongo.NewDao(client.Client, client.Config))
-	return nil
+	dao := mongo.NewDao(client.Client, client.Config)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(conn.Timeout)*time.Second)
+	defer cancel()
+	// actions are not checked if privileges can't be loaded
+	if err := dao.LoadPrivileges(ctx); err != nil {
+		log.Warn().Err(err).Msg("Error loading privileges")
+	}
+	return dao, nil
 }
 
 // Render is the main render function
 // it renders the page based on the config
 func (a *App) Render() {
 	switch {
-	case a.App.GetConfig().ShowWelcomePage:
+	// on the first run the welcome page guides through the setup
+	case a.App.GetConfig().ShowWelcomePage, len(a.App.GetConfig().Connections) == 0:
 		if err := a.renderWelcome(); err != nil {
 			modal.ShowError(a.Pages, "Error while rendering welcome page", err)
 		}
@@ -113,17 +205,103 @@ func (a *App) Render() {
 	default:
 		// we need to init main view after connection is established
 		// as it depends on the dao
-		if err := a.initAndRenderMain(); err != nil {
+		a.connectAndRenderMain(nil, func(err error) {
 			modal.ShowError(a.Pages, "Error while initializing main view", err)
+		})
+	}
+
+	if a.App.GetConfig().CheckUpdates && a.version != devVersion {
+		go a.checkUpdate()
+	}
+}
+
+// checkUpdate looks up the latest release and shows its changelog
+// if it's newer than the current version
+func (a *App) checkUpdate() {
+	ctx, cancel := context.WithTimeout(a.Context(), updateCheckTimeout)
+	defer cancel()
+
+	release, err := util.GetLatestRelease(ctx, util.LatestReleaseURL)
+	if err != nil {
+		log.Warn().Err(err).Msg("Error checking for updates")
+		return
+	}
+	if !util.IsNewerVersion(a.version, release.Version) {
+		log.Debug().Str("latest", release.Version).Msg("Vi Mongo is up to date")
+		return
+	}
+	a.showUpdate(release)
+}
+
+// showUpdate shows the release once connecting is done,
+// otherwise the main page would be rendered over it
+func (a *App) showUpdate(release *util.Release) {
+	if a.Context().Err() != nil {
+		return
+	}
+	a.QueueUpdateDraw(func() {
+		if a.Pages.HasPage(modal.LoadingModal) {
+			time.AfterFunc(time.Second, func() {
+				a.showUpdate(release)
+			})
+			return
+		}
+		a.update.Render(a.version, release)
+	})
+}
+
+// connectAndRenderMain connects to the current connection in the background
+// and renders the main page when it's done. The loading modal is shown
+// meanwhile, so the first draw doesn't wait for the server.
+// onReady, if not nil, and onError are called from the main goroutine.
+func (a *App) connectAndRenderMain(onReady func(), onError func(err error)) {
+	conn := a.App.GetConfig().GetCurrentConnection()
+	if a.isConnectedTo(conn) {
+		if err := a.renderMain(); err != nil {
+			onError(err)
 			return
 		}
+		if onReady != nil {
+			onReady()
+		}
+		return
 	}
+
+	modal.ShowLoading(a.Pages, fmt.Sprintf("Connecting to %s...", conn.Name))
+	go func() {
+		start := time.Now()
+		dao, err := dialMongo(conn)
+		log.Debug().Dur("took", time.Since(start)).Str("connection", conn.Name).Msg("Connecting to the database finished")
+		if a.Context().Err() != nil {
+			// app was closed while connecting
+			if dao != nil {
+				dao.ForceClose(context.Background())
+			}
+			return
+		}
+		a.QueueUpdateDraw(func() {
+			modal.HideLoading(a.Pages)
+			if err != nil {
+				onError(err)
+				return
+			}
+			a.SetDao(dao)
+			if err := a.renderMain(); err != nil {
+				onError(err)
+				return
+			}
+			if onReady != nil {
+				onReady()
+			}
+		})
+	}()
 }
 
-// initAndRenderMain initializes and renders the main page
-// methods are combined as we need to establish connection first
-func (a *App) initAndRenderMain() error {
-	if err := a.connectToMongo(); err != nil {
+// renderMain initializes and renders the main page,
+// it requires the connection to be established
+func (a *App) renderMain() error {
+	// connection may override some of the keybindings
+	if err := a.ReloadKeys(); err != nil {
 		return err
 	}
 
@@ -145,11 +323,10 @@ func (a *App) initAndRenderMain() error {
 func (a *App) renderConnection() error {
 	a.connection.SetOnSubmitFunc(func() {
 		a.Pages.RemovePage(a.connection.GetIdentifier())
-		err := a.initAndRenderMain()
-		if err != nil {
+		a.connectAndRenderMain(nil, func(err error) {
 			a.Pages.AddPage(a.connection.GetIdentifier(), a.connection, true, true)
 			modal.ShowError(a.App.Pages, "Error while connecting to the database", err)
-		}
+		})
 	})
 
 	a.Pages.AddPage(a.connection.GetIdentifier(), a.connection, true, true)
@@ -173,11 +350,66 @@ func (a *App) renderWelcome() error {
 			modal.ShowError(a.Pages, "Error while rendering connection page", err)
 		}
 	})
+	welcome.SetOpenRecentFunc(func(rn config.RecentNamespace) {
+		if err := a.App.GetConfig().SetCurrentConnection(rn.Connection); err != nil {
+			modal.ShowError(a.Pages, "Error while setting the connection", err)
+			return
+		}
+		a.Pages.RemovePage(welcome.GetIdentifier())
+		a.connectAndRenderMain(func() {
+			if err := a.main.OpenNamespace(a.Context(), rn.Db, rn.Coll); err != nil {
+				modal.ShowError(a.Pages, "Error while opening the collection", err)
+			}
+		}, func(err error) {
+			a.Pages.AddPage(welcome.GetIdentifier(), welcome, true, true)
+			modal.ShowError(a.Pages, "Error while connecting to the database", err)
+		})
+	})
 	a.Pages.AddPage(welcome.GetIdentifier(), welcome, true, true)
 	welcome.Render()
 	return nil
 }
 
+// watchKeybindings checks periodically if the keybindings file was modified
+// and reloads keys, so changes are applied without restarting the app
+func (a *App) watchKeybindings() {
+	keybindingsPath, err := config.GetKeybindingsPath()
+	if err != nil {
+		log.Error().Err(err).Msg("Error getting keybindings path")
+		return
+	}
+
+	var lastModTime time.Time
+	if info, err := os.Stat(keybindingsPath); err == nil {
+		lastModTime = info.ModTime()
+	}
+
+	ticker := time.NewTicker(keysWatchInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-a.Context().Done():
+			return
+		case <-ticker.C:
+		}
+
+		info, err := os.Stat(keybindingsPath)
+		if err != nil || !info.ModTime().After(lastModTime) {
+			continue
+		}
+		lastModTime = info.ModTime()
+
+		a.QueueUpdateDraw(func() {
+			if err := a.ReloadKeys(); err != nil {
+				modal.ShowError(a.Pages, "Error while reloading keybindings", err)
+				return
+			}
+			log.Info().Msg("Keybindings reloaded")
+		})
+	}
+}
+
 func (a *App) ShowStyleChangeModal() {
 	styleChangeModal := modal.NewStyleChangeModal()
 	if err := styleChangeModal.Init(a.App); err != nil {
@@ -1,10 +1,18 @@
 package component
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
 	"regexp"
+	"slices"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/atotto/clipboard"
 	"github.com/gdamore/tcell/v2"
@@ -14,7 +22,10 @@ import (
 	"github.com/kopecmaciej/vi-mongo/internal/mongo"
 	"github.com/kopecmaciej/vi-mongo/internal/tui/core"
 	"github.com/kopecmaciej/vi-mongo/internal/tui/modal"
+	"github.com/kopecmaciej/vi-mongo/internal/tui/primitives"
 	"github.com/kopecmaciej/vi-mongo/internal/util"
+	"github.com/rivo/uniseg"
+	"github.com/rs/zerolog/log"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
@@ -24,6 +35,7 @@ const (
 	QueryBarComponent  = "QueryBar"
 	SortBarComponent   = "SortBar"
 	ContentDeleteModal = "ContentDeleteModal"
+	ExportPathModal    = "ExportPathModal"
 )
 
 type ViewType int
@@ -34,24 +46,103 @@ const (
 	SingleLineView
 )
 
+// width of the table view columns, the width of the table
+// is split between them within these bounds
+const (
+	minColumnWidth = 10
+	maxColumnWidth = 50
+	// maxAutoFitWidth bounds auto-fitted columns,
+	// so a single long value doesn't fill the table
+	maxAutoFitWidth = 80
+	// columnWidthStep is how much a column is widened or narrowed
+	columnWidthStep = 4
+)
+
+// defaultRefreshInterval is used when auto-refresh interval is not set
+const defaultRefreshInterval = 5 * time.Second
+
+// countByFieldLimit is the number of most common values shown
+const countByFieldLimit = 20
+
+// exportProgressEvery is how often progress of the export is updated
+const exportProgressEvery = 1000
+
+// importGaugeWidth is the width of the progress bar of the import,
+// maxRejectedLogs is the number of rejected documents listed in its logs
+const (
+	importGaugeWidth = 10
+	maxRejectedLogs  = 100
+)
+
 // Content is a view that displays documents in a table
 type Content struct {
 	*core.BaseElement
 	*core.Flex
 
-	tableFlex   *core.Flex
-	tableHeader *core.TextView
-	table       *core.Table
-	view        *core.TextView
-	style       *config.ContentStyle
-	queryBar    *InputBar
-	sortBar     *InputBar
-	peeker      *Peeker
-	deleteModal *modal.Delete
-	docModifier *DocModifier
-	state       *mongo.CollectionState
-	stateMap    *mongo.StateMap
-	currentView ViewType
+	tableFlex     *core.Flex
+	tableHeader   *core.TextView
+	table         *core.Table
+	view          *core.TextView
+	style         *config.ContentStyle
+	queryBar      *InputBar
+	sortBar       *InputBar
+	peeker        *Peeker
+	deleteModal   *modal.Delete
+	docModifier   *DocModifier
+	filterBuilder *modal.FilterBuilder
+	sortBuilder   *modal.SortBuilder
+	queryOptions  *modal.QueryOptions
+	versions      *modal.DocumentVersions
+	fanOut        *modal.FanOut
+	tenantPicker  *modal.TenantPicker
+	search        *modal.Search
+	vectorSearch  *modal.VectorSearch
+	lookupBuilder *modal.LookupBuilder
+	countByField  *modal.CountByField
+	exportFormat  *modal.ExportFormat
+	exportPath    *primitives.InputModal
+	csvFields     *modal.CSVFields
+	csvImport     *modal.CSVImport
+	jsonImport    *modal.JSONImport
+	aggregation   *modal.Aggregation
+	collStats     *modal.CollectionStatsModal
+	watch         *modal.Watch
+	geoQuery      *modal.GeoQuery
+	timeRange     *modal.TimeRange
+	archive       *modal.Archive
+	state         *mongo.CollectionState
+	stateMap      *mongo.StateMap
+	prefetcher    *mongo.PagePrefetcher
+	currentView   ViewType
+	// tableDocs and tableContent are rendered in the table view,
+	// they are kept so a single row can be updated
+	tableDocs    []*mongo.LazyDocument
+	tableFields  []string
+	tableContent *primitives.LazyTableContent
+	// tableWidths are widths of the table view columns,
+	// 0 until the column is drawn for the first time
+	tableWidths []int
+	// columns is the layout of the table view saved for the collection
+	columns config.ColumnLayout
+	// flatten shows fields of embedded documents as dotted columns
+	flatten bool
+	// drawnWidth and drawnHeight are the size of the table when
+	// it was last drawn, page is rendered again when it changes
+	drawnWidth, drawnHeight int
+	// autocompleteStale is set when documents changed since
+	// autocomplete keys were loaded
+	autocompleteStale bool
+	// stopAutoRefresh stops reloading of the page, it's nil
+	// when auto-refresh is off
+	stopAutoRefresh context.CancelFunc
+	// diff is the difference of the page from its previous
+	// auto-refresh, onlyChanges hides unchanged documents
+	diff        *mongo.ResultDiff
+	onlyChanges bool
+	// selectedRow is marked with text in accessible mode,
+	// onAnnounce receives the description of the selected cell
+	selectedRow int
+	onAnnounce  func(text string)
 }
 
 func NewContent() *Content {
@@ -59,18 +150,40 @@ func NewContent() *Content {
 		BaseElement: core.NewBaseElement(),
 		Flex:        core.NewFlex(),
 
-		tableFlex:   core.NewFlex(),
-		tableHeader: core.NewTextView(),
-		table:       core.NewTable(),
-		view:        core.NewTextView(),
-		queryBar:    NewInputBar(QueryBarComponent, "Query"),
-		sortBar:     NewInputBar(SortBarComponent, "Sort"),
-		peeker:      NewPeeker(),
-		deleteModal: modal.NewDeleteModal(ContentDeleteModal),
-		docModifier: NewDocModifier(),
-		state:       &mongo.CollectionState{},
-		stateMap:    mongo.NewStateMap(),
-		currentView: TableView,
+		tableFlex:     core.NewFlex(),
+		tableHeader:   core.NewTextView(),
+		table:         core.NewTable(),
+		view:          core.NewTextView(),
+		queryBar:      NewInputBar(QueryBarComponent, "Query"),
+		sortBar:       NewInputBar(SortBarComponent, "Sort"),
+		peeker:        NewPeeker(),
+		deleteModal:   modal.NewDeleteModal(ContentDeleteModal),
+		docModifier:   NewDocModifier(),
+		filterBuilder: modal.NewFilterBuilderModal(),
+		sortBuilder:   modal.NewSortBuilderModal(),
+		queryOptions:  modal.NewQueryOptionsModal(),
+		versions:      modal.NewDocumentVersionsModal(),
+		fanOut:        modal.NewFanOutModal(),
+		tenantPicker:  modal.NewTenantPickerModal(),
+		search:        modal.NewSearchModal(),
+		vectorSearch:  modal.NewVectorSearchModal(),
+		lookupBuilder: modal.NewLookupBuilderModal(),
+		countByField:  modal.NewCountByFieldModal(),
+		exportFormat:  modal.NewExportFormatModal(),
+		exportPath:    primitives.NewInputModal(),
+		csvFields:     modal.NewCSVFieldsModal(),
+		csvImport:     modal.NewCSVImportModal(),
+		jsonImport:    modal.NewJSONImportModal(),
+		aggregation:   modal.NewAggregationModal(),
+		collStats:     modal.NewCollectionStatsModal(),
+		watch:         modal.NewWatchModal(),
+		geoQuery:      modal.NewGeoQueryModal(),
+		timeRange:     modal.NewTimeRangeModal(),
+		archive:       modal.NewArchiveModal(),
+		state:         &mongo.CollectionState{},
+		stateMap:      mongo.NewStateMap(),
+		prefetcher:    mongo.NewPagePrefetcher(),
+		currentView:   TableView,
 	}
 
 	c.SetIdentifier(ContentComponent)
@@ -104,9 +217,71 @@ func (c *Content) init() error {
 	if err := c.sortBar.Init(c.App); err != nil {
 		return err
 	}
+	if err := c.filterBuilder.Init(c.App); err != nil {
+		return err
+	}
+	if err := c.sortBuilder.Init(c.App); err != nil {
+		return err
+	}
+	if err := c.queryOptions.Init(c.App); err != nil {
+		return err
+	}
+	if err := c.versions.Init(c.App); err != nil {
+		return err
+	}
+	if err := c.fanOut.Init(c.App); err != nil {
+		return err
+	}
+	if err := c.tenantPicker.Init(c.App); err != nil {
+		return err
+	}
+	if err := c.search.Init(c.App); err != nil {
+		return err
+	}
+	if err := c.vectorSearch.Init(c.App); err != nil {
+		return err
+	}
+	if err := c.lookupBuilder.Init(c.App); err != nil {
+		return err
+	}
+	if err := c.countByField.Init(c.App); err != nil {
+		return err
+	}
+	if err := c.exportFormat.Init(c.App); err != nil {
+		return err
+	}
+	if err := c.csvFields.Init(c.App); err != nil {
+		return err
+	}
+	if err := c.csvImport.Init(c.App); err != nil {
+		return err
+	}
+	if err := c.jsonImport.Init(c.App); err != nil {
+		return err
+	}
+	if err := c.aggregation.Init(c.App); err != nil {
+		return err
+	}
+	if err := c.collStats.Init(c.App); err != nil {
+		return err
+	}
+	if err := c.watch.Init(c.App); err != nil {
+		return err
+	}
+	if err := c.geoQuery.Init(c.App); err != nil {
+		return err
+	}
+	if err := c.timeRange.Init(c.App); err != nil {
+		return err
+	}
+	if err := c.archive.Init(c.App); err != nil {
+		return err
+	}
 
 	c.queryBar.EnableAutocomplete()
 	c.queryBar.EnableHistory()
+	c.queryBar.EnableSnippets()
+	c.queryBar.EnableFormatting()
 	c.queryBar.SetDefaultText("{ <$0> }")
 
 	c.sortBar.EnableAutocomplete()
@@ -114,11 +289,96 @@ func (c *Content) init() error {
 
 	c.queryBarListener(ctx)
 	c.sortBarListener(ctx)
-
-	c.peeker.SetDoneFunc(func() {
-		c.updateContent(ctx, true)
+	c.filterBuilder.SetApplyFunc(func(filter string) {
+		c.applyFilter(ctx, filter)
+	})
+	c.geoQuery.SetApplyFunc(func(filter string) {
+		c.applyFilter(ctx, filter)
+	})
+	c.collStats.SetExpireFunc(func(seconds *int64) error {
+		return c.setExpireAfter(ctx, seconds)
+	})
+	c.timeRange.SetApplyFunc(func(filter string) {
+		c.applyFilter(ctx, filter)
+	})
+	c.archive.SetArchiveFunc(func(path string, remove bool) {
+		c.archiveDocuments(ctx, path, remove)
+	})
+	c.geoQuery.SetIndexFuncs(func(field string) (bool, error) {
+		return c.Dao.HasGeoIndex(ctx, c.state.Db, c.state.Coll, field)
+	}, func(field string) error {
+		return c.createGeoIndex(ctx, field)
+	})
+	c.sortBuilder.SetApplyFunc(func(sort string) {
+		c.applySort(ctx, sort)
+		c.App.SetFocus(c.table)
+	})
+	c.sortBuilder.SetEditRawFunc(c.toggleSortBar)
+	c.queryOptions.SetApplyFunc(func(opts mongo.QueryOptions) {
+		c.applyQueryOptions(ctx, opts)
+	})
+	c.versions.SetRestoreFunc(func(version mongo.DocumentVersion) {
+		c.restoreVersion(ctx, version)
+	})
+	c.fanOut.SetRunFunc(func(patterns, filter string) {
+		c.runFanOut(ctx, patterns, filter)
+	})
+	c.tenantPicker.SetSelectFunc(func(tenant string) {
+		c.switchTenant(ctx, tenant)
+	})
+	c.search.SetRunFunc(func(query mongo.SearchQuery) {
+		c.runSearch(ctx, query)
+	})
+	c.vectorSearch.SetRunFunc(func(query mongo.VectorSearchQuery, vector string) {
+		c.runVectorSearch(ctx, query, vector)
+	})
+	c.lookupBuilder.SetLoadFieldsFunc(func(collection string) ([]string, error) {
+		return c.Dao.SampleFields(ctx, c.state.Db, collection)
+	})
+	c.lookupBuilder.SetApplyFunc(c.applyLookupStage)
+	c.countByField.SetApplyFunc(func(field string, value interface{}) {
+		c.filterByValue(ctx, field, value)
+	})
+	c.aggregation.SetRunFunc(func(pipeline string) {
+		c.runPipeline(ctx, pipeline)
+	})
+	c.aggregation.SetExplainFunc(func(pipeline mongo.Pipeline) ([]mongo.StageStats, error) {
+		return c.Dao.ExplainAggregate(ctx, c.state.Db, c.state.Coll, pipeline)
+	})
+	c.aggregation.SetLookupFunc(func() {
+		c.handleLookupBuilder(ctx)
+	})
+	c.aggregation.SetSaveFuncs(func(name string) (bool, error) {
+		colls, err := c.Dao.ListCollectionNames(ctx, c.state.Db)
+		return slices.Contains(colls, name), err
+	}, func(pipeline mongo.Pipeline, target string) {
+		c.savePipelineResults(ctx, pipeline, target)
+	})
+	c.exportFormat.SetSelectFunc(c.copyResults)
+	c.exportFormat.SetExporterFunc(func(exporter config.ExporterConfig) {
+		c.runExporter(ctx, exporter)
+	})
+	c.csvImport.SetImportFunc(func(documents []primitive.M, opts mongo.ImportOptions, checkpoint config.ImportCheckpoint) {
+		c.importDocuments(ctx, documents, opts, checkpoint)
+	})
+	c.csvImport.SetCheckpointFunc(func(source string) *config.ImportCheckpoint {
+		checkpoint, err := config.FindCheckpoint(c.Dao.Config.Name, c.state.Db+"."+c.state.Coll, source)
+		if err != nil {
+			log.Error().Err(err).Msg("Error loading import checkpoint")
+		}
+		return checkpoint
+	})
+	c.jsonImport.SetImportFunc(func(file *mongo.JSONFile, source string, opts mongo.ImportOptions) {
+		c.importJSON(ctx, file, source, opts)
+	})
+	c.csvImport.SetDryRunFunc(func(documents []primitive.M, upsertKey string) (mongo.DryRunReport, error) {
+		report, err := c.Dao.DryRunImport(ctx, c.state.Db, c.state.Coll, documents, upsertKey)
+		report.Samples = c.maskDocuments(report.Samples)
+		return report, err
 	})
 
+	c.table.SetSelectionChangedFunc(c.selectionChanged)
+
 	c.handleEvents()
 
 	return nil
@@ -129,15 +389,50 @@ func (c *Content) handleEvents() {
 		switch event.Message.Type {
 		case manager.StyleChanged:
 			c.setStyle()
-			c.updateContent(context.Background(), true)
+			// events are handled outside of the UI goroutine
+			go c.App.QueueUpdateDraw(c.rerender)
+		case manager.DocumentUpdated:
+			data, ok := manager.DataOf[manager.DocumentUpdatedData](event.Message)
+			if !ok {
+				return
+			}
+			go c.App.QueueUpdateDraw(func() {
+				c.handleDocumentUpdated(context.Background(), data)
+			})
+		case manager.ConnectionChanged:
+			// states of collections belong to the previous connection
+			go c.App.QueueUpdateDraw(func() {
+				c.stateMap = mongo.NewStateMap()
+			})
 		}
-	})
+	}, manager.StyleChanged, manager.DocumentUpdated, manager.ConnectionChanged)
 }
 
 func (c *Content) UpdateDao(dao *mongo.Dao) {
-	c.table.Clear()
+	c.prefetcher.Cancel()
+	c.setAutoRefresh(false)
+	c.table.SetContent(nil)
 	c.BaseElement.UpdateDao(dao)
 	c.docModifier.UpdateDao(dao)
+	c.peeker.UpdateDao(dao)
+}
+
+// masker masks sensitive fields of documents shown or copied
+func (c *Content) masker() *mongo.Masker {
+	return mongo.NewMasker(c.Dao.Config.Masking)
+}
+
+// valueFormat formats numbers and strings shown in the table
+func (c *Content) valueFormat() util.ValueFormat {
+	return c.App.GetConfig().Display.ValueFormat()
+}
+
+func (c *Content) maskDocuments(documents []primitive.M) []primitive.M {
+	masker := c.masker()
+	for i, doc := range documents {
+		documents[i] = masker.MaskDocument(doc)
+	}
+	return documents
 }
 
 func (c *Content) setStyle() {
@@ -155,22 +450,31 @@ func (c *Content) setStyle() {
 
 	c.table.SetBordersColor(c.style.SeparatorColor.Color())
 	c.table.SetSeparator(c.style.SeparatorSymbol.Rune())
+
+	c.exportPath.SetBorderColor(styles.Global.BorderColor.Color())
+	c.exportPath.SetBackgroundColor(styles.Global.BackgroundColor.Color())
+	c.exportPath.SetFieldTextColor(styles.Others.ModalTextColor.Color())
+	c.exportPath.SetFieldBackgroundColor(styles.Global.ContrastBackgroundColor.Color())
 }
 
 func (c *Content) setStaticLayout() {
 	c.tableFlex.SetBorder(true)
 	c.tableFlex.SetDirection(tview.FlexRow)
-	c.tableFlex.SetTitle(" Content ")
+	c.tableFlex.SetTitle(" " + config.Msg("content.title") + " ")
 	c.tableFlex.SetTitleAlign(tview.AlignCenter)
 	c.tableFlex.SetBorderPadding(0, 0, 1, 1)
 
 	c.tableHeader.SetText("Documents: 0, Page: 0, Limit: 0")
 
 	c.view.SetBorder(true)
-	c.view.SetTitle(" JSON View ")
+	c.view.SetTitle(" " + config.Msg("content.json.title") + " ")
 	c.view.SetTitleAlign(tview.AlignCenter)
 	c.view.SetBorderPadding(2, 0, 6, 0)
 
+	c.exportPath.SetBorder(true)
+	c.exportPath.SetTitle(" " + config.Msg("content.export.title") + " ")
+	c.exportPath.SetLabel("File path, .ndjson or .jsonl for NDJSON, .csv for CSV")
+
 	c.Flex.SetDirection(tview.FlexRow)
 }
 
@@ -199,6 +503,64 @@ func (c *Content) setKeybindings(ctx context.Context) {
 			return c.handleToggleQuery()
 		case k.Contains(k.Content.ToggleSort, event.Name()):
 			return c.handleToggleSort()
+		case k.Contains(k.Content.FilterBuilder, event.Name()):
+			return c.handleFilterBuilder()
+		case k.Contains(k.Content.QueryOptions, event.Name()):
+			return c.handleQueryOptions()
+		case k.Contains(k.Content.ToggleCount, event.Name()):
+			return c.handleToggleCount(ctx)
+		case k.Contains(k.Content.ToggleDeleted, event.Name()):
+			return c.handleToggleDeleted(ctx)
+		case k.Contains(k.Content.DocumentHistory, event.Name()):
+			return c.handleDocumentHistory(ctx, row, coll)
+		case k.Contains(k.Content.FanOutQuery, event.Name()):
+			return c.handleFanOut()
+		case k.Contains(k.Content.SwitchTenant, event.Name()):
+			return c.handleSwitchTenant(ctx)
+		case k.Contains(k.Content.Search, event.Name()):
+			return c.handleSearch(ctx)
+		case k.Contains(k.Content.VectorSearch, event.Name()):
+			return c.handleVectorSearch(ctx)
+		case k.Contains(k.Content.WidenColumn, event.Name()):
+			return c.handleResizeColumn(coll, columnWidthStep)
+		case k.Contains(k.Content.NarrowColumn, event.Name()):
+			return c.handleResizeColumn(coll, -columnWidthStep)
+		case k.Contains(k.Content.AutoFitColumns, event.Name()):
+			return c.handleToggleAutoFit()
+		case k.Contains(k.Content.PinColumn, event.Name()):
+			return c.handlePinColumn(coll)
+		case k.Contains(k.Content.FlattenColumns, event.Name()):
+			return c.handleToggleFlatten()
+		case k.Contains(k.Content.LookupBuilder, event.Name()):
+			return c.handleLookupBuilder(ctx)
+		case k.Contains(k.Content.AutoRefresh, event.Name()):
+			return c.handleToggleAutoRefresh()
+		case k.Contains(k.Content.OnlyChanges, event.Name()):
+			return c.handleToggleOnlyChanges()
+		case k.Contains(k.Content.CountByField, event.Name()):
+			return c.handleCountByField(ctx, coll)
+		case k.Contains(k.Content.CopyResults, event.Name()):
+			return c.handleCopyResults()
+		case k.Contains(k.Content.ExportResults, event.Name()):
+			return c.handleExportResults(ctx)
+		case k.Contains(k.Content.ImportCSV, event.Name()):
+			return c.handleImportCSV()
+		case k.Contains(k.Content.ImportJSON, event.Name()):
+			return c.handleImportJSON()
+		case k.Contains(k.Content.Aggregation, event.Name()):
+			return c.handleAggregation()
+		case k.Contains(k.Content.CollectionStats, event.Name()):
+			return c.handleCollectionStats(ctx)
+		case k.Contains(k.Content.Watch, event.Name()):
+			return c.handleWatch()
+		case k.Contains(k.Content.GeoQuery, event.Name()):
+			return c.handleGeoQuery(row, coll)
+		case k.Contains(k.Content.CopyMapLink, event.Name()):
+			return c.handleCopyMapLink(row, coll)
+		case k.Contains(k.Content.TimeRange, event.Name()):
+			return c.handleTimeRange()
+		case k.Contains(k.Content.Archive, event.Name()):
+			return c.handleArchive()
 		// TODO: Add automatic sort by given column
 		case k.Contains(k.Content.Refresh, event.Name()):
 			return c.handleRefresh(ctx)
@@ -227,8 +589,10 @@ func (c *Content) setKeybindings(ctx context.Context) {
 
 // HandleDatabaseSelection is called when a database/collection is selected in the DatabaseTree
 func (c *Content) HandleDatabaseSelection(ctx context.Context, db, coll string) error {
+	c.setAutoRefresh(false)
 	c.queryBar.SetText("")
 	c.sortBar.SetText("")
+	c.queryBar.SetNamespace(c.stateMap.Key(db, coll))
 
 	state, ok := c.stateMap.Get(c.stateMap.Key(db, coll))
 	if ok {
@@ -239,15 +603,42 @@ func (c *Content) HandleDatabaseSelection(ctx context.Context, db, coll string)
 			Db:   db,
 			Coll: coll,
 		}
-		_, _, _, height := c.table.GetInnerRect()
-		c.state.Limit = int64(height - 1)
+		c.state.Limit = c.pageSize()
+		c.state.EstimatedCount = c.Dao.Config.EstimatedCount
+		if softDelete, ok := c.Dao.Config.GetSoftDelete(db, coll); ok {
+			c.state.SoftDeleteField = softDelete.Field
+		}
+		timeSeries, err := c.Dao.GetTimeSeries(ctx, db, coll)
+		if err != nil {
+			log.Error().Err(err).Msg("Error loading time-series options")
+		}
+		c.state.TimeSeries = timeSeries
+		sort, err := config.LoadSort(c.stateMap.Key(db, coll))
+		if err != nil {
+			log.Error().Err(err).Msg("Error loading saved sort")
+		}
+		// measurements are listed from the newest, unless the sort is saved
+		if sort == "" && timeSeries != nil {
+			sort = timeSeries.DefaultSort()
+		}
+		c.state.Sort = sort
+	}
+	columns, err := config.LoadColumnLayout(c.stateMap.Key(db, coll))
+	if err != nil {
+		log.Error().Err(err).Msg("Error loading column layout")
 	}
+	c.columns = columns
 
-	err := c.updateContent(ctx, false)
+	err = c.updateContent(ctx, false)
 	if err != nil {
 		return err
 	}
 
+	if err := config.AddRecentNamespace(c.Dao.Config.Name, db, coll); err != nil {
+		log.Error().Err(err).Msg("Error saving recent namespace")
+	}
+	go c.sampleGrowth(c.Dao, db, coll)
+
 	c.App.SetFocus(c)
 	return nil
 }
@@ -281,47 +672,226 @@ func (c *Content) Render(setFocus bool) {
 	}
 }
 
-func (c *Content) renderTableView(startRow int, documents []primitive.M) {
+// Draw draws the content and renders the current page again if the
+// size of the table has changed, so column widths fit the new size
+func (c *Content) Draw(screen tcell.Screen) {
+	c.Flex.Draw(screen)
+
+	_, _, width, height := c.table.GetInnerRect()
+	if width == c.drawnWidth && height == c.drawnHeight {
+		return
+	}
+	c.drawnWidth, c.drawnHeight = width, height
+	if len(c.state.GetLazyDocs()) > 0 {
+		go c.App.QueueUpdateDraw(c.rerender)
+	}
+}
+
+// rerender renders the current page again from the documents
+// already loaded, selected cell and scroll position are kept
+func (c *Content) rerender() {
+	row, col := c.table.GetSelection()
+	rowOffset, colOffset := c.table.GetOffset()
+	if err := c.updateContent(c.App.Context(), true); err != nil {
+		modal.ShowError(c.App.Pages, "Error rendering content", err)
+		return
+	}
+	c.table.Select(row, col)
+	c.table.SetOffset(rowOffset, colOffset)
+}
+
+// renderTableView renders documents as rows and their keys as columns.
+// Cells are created only when they are drawn, as pages of wide documents
+// would have thousands of them
+func (c *Content) renderTableView(startRow int, documents []*mongo.LazyDocument) {
 	c.table.SetFixed(1, 0)
-	sortedKeys := util.GetSortedKeysWithTypes(documents, c.style.ColumnTypeColor.Color().String())
-
-	// Set the header row
-	for col, key := range sortedKeys {
-		c.table.SetCell(startRow, col, tview.NewTableCell(key).
-			SetTextColor(c.style.ColumnKeyColor.Color()).
-			SetSelectable(false).
-			SetBackgroundColor(c.style.HeaderRowBackgroundColor.Color()).
-			SetAlign(tview.AlignCenter))
-	}
-	startRow++
-
-	// Populate the table with document values
-	for row, doc := range documents {
-		for col, key := range sortedKeys {
-			var cellText string
-			if val, ok := doc[strings.Split(key, " ")[0]]; ok {
-				cellText = util.GetValueByType(val)
-			} else {
-				cellText = ""
-			}
-			if len(cellText) > 30 {
-				cellText = cellText[0:30] + "..."
+	if len(documents) == 0 {
+		return
+	}
+	keysWithTypes := util.FormatKeysWithTypes(mongo.DocumentsFlatTypes(documents, c.flattenDepth()), c.style.ColumnTypeColor.Color().String())
+	keyFields := make([]string, len(keysWithTypes))
+	for i, key := range keysWithTypes {
+		keyFields[i] = strings.Split(key, " ")[0]
+	}
+	// pinned columns are moved to the left, where they stay
+	// visible while the table is scrolled horizontally
+	order, pinned := c.columns.PinnedFirst(keyFields)
+	sortedKeys := make([]string, len(order))
+	fields := make([]string, len(order))
+	for col, i := range order {
+		sortedKeys[col] = keysWithTypes[i]
+		fields[col] = keyFields[i]
+	}
+	c.table.SetFixed(1, pinned)
+
+	c.tableDocs = documents
+	c.tableFields = fields
+	c.tableWidths = make([]int, len(fields))
+
+	masker := c.masker()
+	values := c.valueFormat()
+	typeColors := c.App.GetConfig().Styles.TypeColors
+	newCell := func(row, col int) *tview.TableCell {
+		width := c.columnWidth(col)
+		// header row
+		if row == startRow {
+			header := tview.NewTableCell(sortedKeys[col]).
+				SetTextColor(c.style.ColumnKeyColor.Color()).
+				SetSelectable(false).
+				SetBackgroundColor(c.style.HeaderRowBackgroundColor.Color()).
+				SetAlign(tview.AlignCenter)
+			// header is cut only if the width was set by hand
+			if c.columns.Columns[fields[col]].Width > 0 {
+				header.SetMaxWidth(width)
 			}
+			return header
+		}
 
-			cell := tview.NewTableCell(cellText).
-				SetAlign(tview.AlignLeft).
-				SetMaxWidth(30)
+		// only fields of drawn cells are decoded
+		doc := documents[row-startRow-1]
+		var cellText string
+		val, ok := doc.GetPath(fields[col])
+		if ok {
+			val = masker.MaskValue(fields[col], val)
+			cellText = formatCell(val, values)
+		}
+		cellText = util.TruncateByWidth(cellText, width)
+
+		cell := tview.NewTableCell(cellText).
+			SetAlign(tview.AlignLeft).
+			SetMaxWidth(width)
+		// missing fields are left without color, unlike null values
+		if typeColors && ok {
+			if color, ok := c.style.TypeColors.ForType(util.GetMongoType(val)); ok {
+				cell.SetTextColor(color.Color())
+			}
+		}
+		switch c.diff.Change(doc) {
+		case mongo.Added:
+			cell.SetBackgroundColor(c.style.DiffColors.AddedColor.Color())
+		case mongo.Changed:
+			cell.SetBackgroundColor(c.style.DiffColors.ChangedColor.Color())
+		case mongo.Removed:
+			cell.SetBackgroundColor(c.style.DiffColors.RemovedColor.Color())
+		}
 
-			// we'll set reference to _id for first column to not repeat the same _id in whole row
-			if col == 0 {
-				cell.SetReference(doc["_id"])
+		// we'll set reference to _id for first column to not repeat the same _id in whole row
+		if col == 0 {
+			id, _ := doc.Get("_id")
+			cell.SetReference(id)
+			if c.App.IsAccessible() {
+				cell.SetText(c.rowMarker(row, doc) + cell.Text)
 			}
-			c.table.SetCell(startRow+row, col, cell)
 		}
+		return cell
 	}
+
+	c.tableContent = primitives.NewLazyTableContent(startRow+len(documents)+1, len(sortedKeys), newCell)
+	c.table.SetContent(c.tableContent)
 	c.table.Select(1, 0)
 }
 
+// formatCell returns the value as it's shown in the table view,
+// GeoJSON locations are shown as their coordinates
+func formatCell(val interface{}, values util.ValueFormat) string {
+	if geo, ok := mongo.ParseGeoJSON(val); ok {
+		return geo.String()
+	}
+	return values.Value(val)
+}
+
+// rowMarker returns the text marking the row in accessible mode, the
+// selected row starts with ">" and changed ones with the change marker
+func (c *Content) rowMarker(row int, doc *mongo.LazyDocument) string {
+	selected := " "
+	if row == c.selectedRow {
+		selected = ">"
+	}
+	change := c.diff.Change(doc).Marker()
+	if change == "" {
+		change = " "
+	}
+	return selected + change + " "
+}
+
+// SetAnnounceFunc sets the function receiving the description
+// of the selected cell in accessible mode
+func (c *Content) SetAnnounceFunc(onAnnounce func(text string)) {
+	c.onAnnounce = onAnnounce
+}
+
+// selectionChanged moves the marker of the selected row
+// and announces the selected cell in accessible mode
+func (c *Content) selectionChanged(row, col int) {
+	if !c.App.IsAccessible() {
+		return
+	}
+	if c.currentView == TableView && c.tableContent != nil && row != c.selectedRow {
+		c.tableContent.ResetRow(c.selectedRow)
+		c.tableContent.ResetRow(row)
+	}
+	c.selectedRow = row
+	if c.onAnnounce != nil {
+		c.onAnnounce(c.describeSelection(row, col))
+	}
+}
+
+// describeSelection describes the selected cell in a line of plain
+// text, in the table view with the document, its field and value
+func (c *Content) describeSelection(row, col int) string {
+	if c.currentView != TableView {
+		cell := c.table.GetCell(row, col)
+		if cell == nil {
+			return ""
+		}
+		return util.Describe(fmt.Sprintf("Line %d", row+1), cell.Text)
+	}
+	if row < 1 || row > len(c.tableDocs) || col < 0 || col >= len(c.tableFields) {
+		return ""
+	}
+
+	doc := c.tableDocs[row-1]
+	field := c.tableFields[col]
+	value := "missing"
+	if val, ok := doc.GetPath(field); ok {
+		value = formatCell(c.masker().MaskValue(field, val), c.valueFormat())
+	}
+	return util.Describe(
+		fmt.Sprintf("Document %d of %d", row, len(c.tableDocs)),
+		fmt.Sprintf("%s: %s", field, value),
+		c.diff.Change(doc).String(),
+	)
+}
+
+// columnWidth returns the width of the column of the table view. The
+// width of the table is split evenly between columns, or in auto-fit
+// mode columns are as wide as their widest value, and then bounds
+// saved for the column are applied.
+func (c *Content) columnWidth(col int) int {
+	if c.tableWidths[col] > 0 {
+		return c.tableWidths[col]
+	}
+
+	field := c.tableFields[col]
+	_, _, tableWidth, _ := c.table.GetInnerRect()
+	computed := util.ColumnWidth(tableWidth, len(c.tableFields), minColumnWidth, maxColumnWidth)
+	if c.columns.AutoFit {
+		// values of the whole column are decoded, but only once it's drawn
+		computed = uniseg.StringWidth(field)
+		masker := c.masker()
+		values := c.valueFormat()
+		for _, doc := range c.tableDocs {
+			if val, ok := doc.GetPath(field); ok {
+				computed = max(computed, uniseg.StringWidth(formatCell(masker.MaskValue(field, val), values)))
+			}
+		}
+		computed = min(computed, maxAutoFitWidth)
+	}
+
+	c.tableWidths[col] = c.columns.Width(field, computed)
+	return c.tableWidths[col]
+}
+
 func (c *Content) renderJsonView(startRow int, documents []primitive.M) {
 	c.table.SetFixed(0, 0)
 	row := startRow
@@ -340,6 +910,7 @@ func (c *Content) renderJsonView(startRow int, documents []primitive.M) {
 }
 
 func (c *Content) renderSingleRowView(startRow int, documents []primitive.M) {
+	c.table.SetFixed(0, 0)
 	row := startRow
 	for _, d := range documents {
 		_id := d["_id"]
@@ -358,32 +929,86 @@ func (c *Content) renderSingleRowView(startRow int, documents []primitive.M) {
 	c.table.Select(0, 0)
 }
 
-func (c *Content) listDocuments(ctx context.Context) ([]primitive.M, int64, error) {
-	filter, err := mongo.ParseStringQuery(c.state.Filter)
-	if err != nil {
-		return nil, 0, err
+func (c *Content) listDocuments(ctx context.Context) ([]*mongo.LazyDocument, int64, error) {
+	if c.state.Pipeline != "" {
+		return c.aggregateDocuments(ctx)
 	}
-	sort, err := mongo.ParseStringQuery(c.state.Sort)
+	filter, sort, err := c.currentQuery()
 	if err != nil {
+		c.prefetcher.Cancel()
 		return nil, 0, err
 	}
 
-	documents, count, err := c.Dao.ListDocuments(ctx, c.state, filter, sort)
-	if err != nil {
-		return nil, 0, err
+	// prefetched page is dropped if the query doesn't match it anymore
+	documents, count, ok := c.prefetcher.Take(c.state.Query())
+	if !ok {
+		documents, count, err = c.Dao.ListDocuments(ctx, c.state, filter, sort)
+		if err != nil {
+			return nil, 0, err
+		}
 	}
 	if len(documents) == 0 {
 		return nil, 0, nil
 	}
+	c.prefetchNextPage(count, filter, sort)
 
-	c.state.Count = count
-	c.state.PopulateDocs(documents)
+	c.state.SetCount(count)
+	c.state.PopulateLazyDocs(documents)
 
-	c.loadAutocompleteKeys(documents)
+	// loading keys decodes all documents, so it's done when bars are opened
+	c.autocompleteStale = true
+	if c.queryBar.IsEnabled() || c.sortBar.IsEnabled() {
+		c.refreshAutocompleteKeys()
+	}
 
 	return documents, count, nil
 }
 
+// currentQuery returns the parsed filter and sort of the state,
+// the filter excludes soft deleted documents if they're hidden
+func (c *Content) currentQuery() (primitive.M, primitive.D, error) {
+	filter, err := mongo.ParseStringQuery(c.state.Filter)
+	if err != nil {
+		return nil, nil, err
+	}
+	sort, err := mongo.ParseStringSort(c.state.Sort)
+	if err != nil {
+		return nil, nil, err
+	}
+	if c.state.HidesDeleted() {
+		filter = mongo.ExcludeDeleted(filter, c.state.SoftDeleteField)
+	}
+	return filter, sort, nil
+}
+
+// prefetchNextPage loads the next page in the background,
+// if prefetching is enabled for the connection
+func (c *Content) prefetchNextPage(count int64, filter primitive.M, sort primitive.D) {
+	if !c.Dao.Config.Prefetch || c.state.Page+c.state.Limit >= count {
+		return
+	}
+
+	next := c.state.NextPage()
+	dao := c.Dao
+	c.prefetcher.Start(c.App.Context(), next.Query(), func(ctx context.Context) ([]*mongo.LazyDocument, int64, error) {
+		documents, count, err := dao.ListDocuments(ctx, next, filter, sort)
+		if err != nil && ctx.Err() == nil {
+			log.Warn().Err(err).Msg("Error while prefetching next page")
+		}
+		return documents, count, err
+	})
+}
+
+// refreshAutocompleteKeys loads autocomplete keys if documents
+// have changed since they were loaded
+func (c *Content) refreshAutocompleteKeys() {
+	if !c.autocompleteStale {
+		return
+	}
+	c.loadAutocompleteKeys(c.state.GetAllDocs())
+	c.autocompleteStale = false
+}
+
 // loadAutocompleteKeys loads the autocomplete keys for the query and sort bars
 func (c *Content) loadAutocompleteKeys(documents []primitive.M) {
 	uniqueKeys := make(map[string]bool)
@@ -424,18 +1049,20 @@ func (c *Content) loadAutocompleteKeys(documents []primitive.M) {
 	}
 
 	c.queryBar.LoadNewKeys(autocompleteKeys)
+	c.queryBar.LoadFieldValues(mongo.SampleFieldValues(documents))
 	c.sortBar.LoadNewKeys(autocompleteKeys)
 }
 
 func (c *Content) updateContent(ctx context.Context, useState bool) error {
-	c.table.Clear()
+	// content of the table view is replaced with the default one
+	c.table.SetContent(nil)
 
-	var documents []primitive.M
+	var documents []*mongo.LazyDocument
 	var count int64
 
 	if useState {
-		documents = c.state.GetAllDocs()
-		count = c.state.Count
+		documents = c.state.GetLazyDocs()
+		count = c.state.GetCount()
 	} else {
 		docs, c, err := c.listDocuments(ctx)
 		if err != nil {
@@ -444,17 +1071,59 @@ func (c *Content) updateContent(ctx context.Context, useState bool) error {
 		documents = docs
 		count = c
 	}
+	if !useState {
+		// changes are tracked only between auto-refreshes of the same page
+		c.diff = nil
+	}
+	if c.diff != nil {
+		// removed documents are kept on the page, so they can be highlighted
+		if c.onlyChanges {
+			documents = c.diff.OnlyChanges(documents)
+		} else {
+			documents = append(documents[:len(documents):len(documents)], c.diff.Removed...)
+		}
+	}
 
-	headerInfo := fmt.Sprintf("Documents: %d, Page: %d, Limit: %d", count, c.state.Page, c.state.Limit)
+	countInfo := fmt.Sprintf("%d", count)
+	if c.state.IsCountEstimated() {
+		countInfo = "~" + countInfo
+	}
+	headerInfo := fmt.Sprintf("Documents: %s, Page: %d, Limit: %d", countInfo, c.state.Page, c.state.Limit)
+	if c.state.TimeSeries != nil {
+		headerInfo += fmt.Sprintf(" | Time series (%s)", c.state.TimeSeries)
+	}
 
-	if c.state.Filter != "" {
+	if c.state.Pipeline != "" {
+		// filter, sort and options are not applied to the pipeline
+		headerInfo += fmt.Sprintf(" | Aggregation: %s", c.pipelineInfo())
+	} else if c.state.Filter != "" {
 		headerInfo += fmt.Sprintf(" | Filter: %s", c.state.Filter)
 		c.queryBar.SetText(c.state.Filter)
 	}
-	if c.state.Sort != "" {
-		headerInfo += fmt.Sprintf(" | Sort: %s", c.state.Sort)
+	if c.state.Sort != "" && c.state.Pipeline == "" {
+		sortInfo := c.state.Sort
+		if keys, err := mongo.ParseSortKeys(c.state.Sort); err == nil {
+			sortInfo = keys.String()
+		}
+		headerInfo += fmt.Sprintf(" | Sort: %s", sortInfo)
 		c.sortBar.SetText(c.state.Sort)
 	}
+	if !c.state.Options.IsZero() && c.state.Pipeline == "" {
+		headerInfo += fmt.Sprintf(" | Options: %s", c.state.Options.String())
+	}
+	if c.state.SoftDeleteField != "" && c.state.ShowDeleted && c.state.Pipeline == "" {
+		headerInfo += fmt.Sprintf(" | Showing deleted (%s)", c.state.SoftDeleteField)
+	}
+	if c.stopAutoRefresh != nil {
+		headerInfo += fmt.Sprintf(" | Auto-refresh: %s", c.refreshInterval())
+		if c.diff != nil {
+			added, changed, removed := c.diff.Counts()
+			headerInfo += fmt.Sprintf(" (+%d ~%d -%d)", added, changed, removed)
+		}
+		if c.onlyChanges {
+			headerInfo += ", only changes"
+		}
+	}
 	c.tableHeader.SetText(headerInfo)
 
 	c.stateMap.Set(c.stateMap.Key(c.state.Db, c.state.Coll), c.state)
@@ -470,9 +1139,9 @@ func (c *Content) updateContent(ctx context.Context, useState bool) error {
 	case TableView:
 		c.renderTableView(startRow, documents)
 	case JsonView:
-		c.renderJsonView(startRow, documents)
+		c.renderJsonView(startRow, c.maskDocuments(c.state.GetAllDocs()))
 	case SingleLineView:
-		c.renderSingleRowView(startRow, documents)
+		c.renderSingleRowView(startRow, c.maskDocuments(c.state.GetAllDocs()))
 	}
 
 	return nil
@@ -548,9 +1217,7 @@ func (c *Content) queryBarListener(ctx context.Context) {
 
 func (c *Content) sortBarListener(ctx context.Context) {
 	acceptFunc := func(text string) {
-		c.state.UpdateSort(text)
-		c.stateMap.Set(c.stateMap.Key(c.state.Db, c.state.Coll), c.state)
-		c.updateContent(ctx, false)
+		c.applySort(ctx, text)
 		c.Flex.RemoveItem(c.sortBar)
 		c.App.SetFocus(c.table)
 	}
@@ -562,12 +1229,6 @@ func (c *Content) sortBarListener(ctx context.Context) {
 	c.sortBar.DoneFuncHandler(acceptFunc, rejectFunc)
 }
 
-// refreshDocument refreshes the document in the table
-func (c *Content) refreshDocument(ctx context.Context, doc string) {
-	c.state.UpdateRawDoc(doc)
-	c.updateContentBasedOnState(ctx)
-}
-
 func (c *Content) viewJson(jsonString string) error {
 	c.view.Clear()
 
@@ -601,28 +1262,55 @@ func (c *Content) deleteDocument(ctx context.Context, jsonString string) error {
 
 	stringifyId := mongo.StringifyId(objectId)
 
-	c.deleteModal.SetText("Are you sure you want to delete document of id: [blue]" + stringifyId)
+	// documents already marked as deleted are removed for good
+	field := c.state.SoftDeleteField
+	softDelete := field != "" && !mongo.IsDeleted(c.state.GetDocById(objectId), field)
+	action := mongo.ActionRemove
+	if softDelete {
+		action = mongo.ActionUpdate
+	}
+	if !c.checkPrivilege(action) {
+		return nil
+	}
+
+	remove := func() error {
+		if !softDelete {
+			if err := c.Dao.DeleteDocument(ctx, c.state.Db, c.state.Coll, objectId); err != nil {
+				return err
+			}
+			c.broadcastDocumentDeleted(objectId)
+			return nil
+		}
+
+		if err := c.Dao.SoftDeleteDocument(ctx, c.state.Db, c.state.Coll, objectId, field); err != nil {
+			return err
+		}
+		// marked document is still shown, so it's loaded again
+		if c.state.ShowDeleted {
+			return c.updateContent(ctx, false)
+		}
+		c.broadcastDocumentDeleted(objectId)
+		return nil
+	}
+
+	if !c.Dao.Config.ShouldConfirm() {
+		return remove()
+	}
+
+	if softDelete {
+		c.deleteModal.SetText(config.Msgf("content.delete.confirmSoft", stringifyId, field))
+	} else {
+		c.deleteModal.SetText(config.Msgf("content.delete.confirm", stringifyId))
+	}
 	c.deleteModal.SetDoneFunc(func(buttonIndex int, buttonLabel string) {
 		defer c.App.Pages.RemovePage(c.deleteModal.GetIdentifier())
-		if buttonLabel == "Cancel" {
+		if buttonLabel == config.Msg("button.cancel") {
 			return
 		}
-		if buttonLabel == "Delete" {
-			err = c.Dao.DeleteDocument(ctx, c.state.Db, c.state.Coll, objectId)
-			if err != nil {
-				modal.ShowError(c.App.Pages, "Error deleting document", err)
-				return
+		if buttonLabel == config.Msg("button.delete") {
+			if err := remove(); err != nil {
+				modal.ShowError(c.App.Pages, config.Msg("content.delete.error"), err)
 			}
-			c.state.DeleteDoc(objectId)
-		}
-
-		c.updateContentBasedOnState(ctx)
-
-		row, col := c.table.GetSelection()
-		if row == c.table.GetRowCount() {
-			c.table.Select(row-1, col)
-		} else {
-			c.table.Select(row, col)
 		}
 	})
 
@@ -631,38 +1319,132 @@ func (c *Content) deleteDocument(ctx context.Context, jsonString string) error {
 	return nil
 }
 
-func (c *Content) getDocumentBasedOnView(row, coll int) (string, error) {
-	_id := c.getDocumentId(row, coll)
-	return c.state.GetJsonDocById(_id)
+// broadcastDocumentDeleted lets views know that the document
+// of the current collection was deleted
+func (c *Content) broadcastDocumentDeleted(id interface{}) {
+	c.BroadcastEvent(manager.EventMsg{
+		Message: manager.Message{
+			Type: manager.DocumentUpdated,
+			Data: manager.DocumentUpdatedData{Db: c.state.Db, Coll: c.state.Coll, ID: id, Deleted: true},
+		},
+	})
 }
 
-// get document id based on view
-func (c *Content) getDocumentId(row, coll int) interface{} {
-	switch c.currentView {
-	case JsonView:
-		forWithReference := c.table.GetCellAboveThatMatch(row, coll, func(cell *tview.TableCell) bool {
-			return strings.HasPrefix(cell.Text, `{`)
-		})
-		return forWithReference.GetReference()
-	case TableView:
-		return c.table.GetCell(row, 0).GetReference()
-	case SingleLineView:
-		return c.table.GetCell(row, 0).GetReference()
-	default:
-		return nil
+// handleDocumentUpdated applies the change of the document made e.g.
+// in the peeker. Edited document is updated in place if possible,
+// so the whole page doesn't have to be rendered again.
+func (c *Content) handleDocumentUpdated(ctx context.Context, data manager.DocumentUpdatedData) {
+	if data.Db != c.state.Db || data.Coll != c.state.Coll {
+		return
 	}
-}
 
-func (c *Content) handleScrolling(row int) {
-	if row == 1 && c.currentView == JsonView {
-		c.table.ScrollToBeginning()
+	row, col := c.table.GetSelection()
+	if data.Deleted {
+		c.state.DeleteDoc(data.ID)
+		if err := c.updateContentBasedOnState(ctx); err != nil {
+			modal.ShowError(c.App.Pages, "Error updating content", err)
+			return
+		}
+		if row >= c.table.GetRowCount() {
+			row = c.table.GetRowCount() - 1
+		}
+		c.table.Select(row, col)
+		return
 	}
-	if row == c.table.GetRowCount()-2 {
-		c.table.ScrollToEnd()
+
+	if err := c.state.UpdateRawDoc(data.Document); err != nil {
+		modal.ShowError(c.App.Pages, "Error updating document", err)
+		return
+	}
+	if c.updateRow(data.ID) {
+		return
+	}
+	if err := c.updateContent(ctx, true); err != nil {
+		modal.ShowError(c.App.Pages, "Error updating content", err)
+		return
 	}
+	c.table.Select(row, col)
 }
 
-func (c *Content) handleSwitchView(ctx context.Context) *tcell.EventKey {
+// updateRow redraws the row of the document with given _id, it returns
+// false if the row can't be updated in place, e.g. in the JSON view or
+// when the document has a field that is not shown in the table
+func (c *Content) updateRow(id interface{}) bool {
+	doc := c.state.GetDocById(id)
+	if doc == nil {
+		return false
+	}
+
+	switch c.currentView {
+	case TableView:
+		if c.tableContent == nil {
+			return false
+		}
+		shown := make(map[string]bool, len(c.tableFields))
+		for _, field := range c.tableFields {
+			shown[field] = true
+		}
+		for key := range mongo.NewDecodedDocument(doc).FlatTypes(c.flattenDepth()) {
+			if !shown[key] {
+				return false
+			}
+		}
+		for i, tableDoc := range c.tableDocs {
+			if tableID, _ := tableDoc.Get("_id"); reflect.DeepEqual(tableID, id) {
+				c.tableDocs[i] = mongo.NewDecodedDocument(doc)
+				// first row is the header
+				c.tableContent.ResetRow(i + 1)
+				return true
+			}
+		}
+	case SingleLineView:
+		jsoned, err := mongo.ParseBsonDocument(c.masker().MaskDocument(doc))
+		if err != nil {
+			return false
+		}
+		for row := 0; row < c.table.GetRowCount(); row++ {
+			cell := c.table.GetCell(row, 0)
+			if reflect.DeepEqual(cell.GetReference(), id) {
+				cell.SetText(jsoned)
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (c *Content) getDocumentBasedOnView(row, coll int) (string, error) {
+	_id := c.getDocumentId(row, coll)
+	return c.state.GetJsonDocById(_id)
+}
+
+// get document id based on view
+func (c *Content) getDocumentId(row, coll int) interface{} {
+	switch c.currentView {
+	case JsonView:
+		forWithReference := c.table.GetCellAboveThatMatch(row, coll, func(cell *tview.TableCell) bool {
+			return strings.HasPrefix(cell.Text, `{`)
+		})
+		return forWithReference.GetReference()
+	case TableView:
+		return c.table.GetCell(row, 0).GetReference()
+	case SingleLineView:
+		return c.table.GetCell(row, 0).GetReference()
+	default:
+		return nil
+	}
+}
+
+func (c *Content) handleScrolling(row int) {
+	if row == 1 && c.currentView == JsonView {
+		c.table.ScrollToBeginning()
+	}
+	if row == c.table.GetRowCount()-2 {
+		c.table.ScrollToEnd()
+	}
+}
+
+func (c *Content) handleSwitchView(ctx context.Context) *tcell.EventKey {
 	switch c.currentView {
 	case TableView:
 		c.currentView = JsonView
@@ -671,110 +1453,1313 @@ func (c *Content) handleSwitchView(ctx context.Context) *tcell.EventKey {
 	case SingleLineView:
 		c.currentView = TableView
 	}
-	c.updateContent(ctx, true)
+	c.updateContent(ctx, true)
+	return nil
+}
+
+func (c *Content) handlePeekDocument(ctx context.Context, row, coll int) *tcell.EventKey {
+	_id := c.getDocumentId(row, coll)
+	if _id == nil {
+		return nil
+	}
+	c.peeker.Render(ctx, c.state, _id)
+	return nil
+}
+
+func (c *Content) handleViewDocument(row, coll int) *tcell.EventKey {
+	doc, err := c.getDocumentBasedOnView(row, coll)
+	if err == nil {
+		doc, err = c.masker().MaskJson(doc)
+	}
+	if err != nil {
+		modal.ShowError(c.App.Pages, "Error viewing document", err)
+		return nil
+	}
+	err = c.viewJson(doc)
+	if err != nil {
+		modal.ShowError(c.App.Pages, "Error viewing document", err)
+		return nil
+	}
+	return nil
+}
+
+func (c *Content) handleAddDocument(ctx context.Context) *tcell.EventKey {
+	if !c.checkPrivilege(mongo.ActionInsert) {
+		return nil
+	}
+	id, err := c.docModifier.Insert(ctx, c.state.Db, c.state.Coll)
+	if err != nil {
+		modal.ShowError(c.App.Pages, "Error adding document", err)
+		return nil
+	}
+	insertedDoc, err := c.Dao.GetDocument(ctx, c.state.Db, c.state.Coll, id)
+	if err != nil {
+		modal.ShowError(c.App.Pages, "Error getting inserted document", err)
+		return nil
+	}
+	c.state.AppendDoc(insertedDoc)
+	c.updateContentBasedOnState(ctx)
+	return nil
+}
+
+func (c *Content) handleEditDocument(ctx context.Context, row, coll int) *tcell.EventKey {
+	if !c.checkDocumentsEditable() {
+		return nil
+	}
+	if !c.checkPrivilege(mongo.ActionUpdate) {
+		return nil
+	}
+	_id := c.getDocumentId(row, coll)
+	doc, err := c.state.GetJsonDocById(_id)
+	if err != nil {
+		modal.ShowError(c.App.Pages, "Error getting document", err)
+		return nil
+	}
+	// table is updated by the document updated event
+	if _, err := c.docModifier.Edit(ctx, c.state.Db, c.state.Coll, _id, doc); err != nil {
+		modal.ShowError(c.App.Pages, "Error editing document", err)
+	}
+	return nil
+}
+
+func (c *Content) handleDuplicateDocument(ctx context.Context, row, coll int) *tcell.EventKey {
+	if !c.checkDocumentsEditable() {
+		return nil
+	}
+	if !c.checkPrivilege(mongo.ActionInsert) {
+		return nil
+	}
+	doc, err := c.getDocumentBasedOnView(row, coll)
+	if err != nil {
+		modal.ShowError(c.App.Pages, "Error duplicating document", err)
+		return nil
+	}
+	id, err := c.docModifier.Duplicate(ctx, c.state.Db, c.state.Coll, doc)
+	if err != nil {
+		modal.ShowError(c.App.Pages, "Error duplicating document", err)
+		return nil
+	}
+	duplicatedDoc, err := c.Dao.GetDocument(ctx, c.state.Db, c.state.Coll, id)
+	if err != nil {
+		modal.ShowError(c.App.Pages, "Error getting inserted document", err)
+		return nil
+	}
+	c.state.AppendDoc(duplicatedDoc)
+	c.updateContentBasedOnState(ctx)
+	return nil
+}
+
+func (c *Content) handleToggleQuery() *tcell.EventKey {
+	c.refreshAutocompleteKeys()
+	if c.state.Filter != "" {
+		c.queryBar.Toggle(c.state.Filter)
+	} else {
+		c.queryBar.Toggle("")
+	}
+	c.Render(true)
+	return nil
+}
+
+func (c *Content) handleFilterBuilder() *tcell.EventKey {
+	c.filterBuilder.Render(mongo.SampleFieldTypes(c.state.GetAllDocs()))
+	return nil
+}
+
+// handleGeoQuery opens the query of GeoJSON fields found in documents
+// of the page, the center is the location of the selected document
+func (c *Content) handleGeoQuery(row, col int) *tcell.EventKey {
+	fields := mongo.GeoFields(c.state.GetAllDocs())
+	if len(fields) == 0 {
+		modal.ShowInfo(c.App.Pages, "No GeoJSON fields in documents of the page")
+		return nil
+	}
+	field, geo, ok := c.selectedGeoJSON(row, col)
+	if !ok {
+		c.geoQuery.Render(fields, "", nil)
+		return nil
+	}
+	c.geoQuery.Render(fields, field, &geo)
+	return nil
+}
+
+// createGeoIndex creates the 2dsphere index on the field of the current
+// collection, the privilege is checked first so the error is clear
+func (c *Content) createGeoIndex(ctx context.Context, field string) error {
+	if err := c.Dao.CheckPrivilege(mongo.ActionCreateIndex, c.state.Db, c.state.Coll); err != nil {
+		return err
+	}
+	name, err := c.Dao.CreateGeoIndex(ctx, c.state.Db, c.state.Coll, field)
+	if err != nil {
+		return err
+	}
+	log.Info().Str("index", name).Str("db", c.state.Db).Str("coll", c.state.Coll).Msg("Created 2dsphere index")
+	return nil
+}
+
+// setExpireAfter changes the expiry of measurements of the current
+// time-series collection, the privilege is checked first so the error is clear
+func (c *Content) setExpireAfter(ctx context.Context, seconds *int64) error {
+	if err := c.Dao.CheckPrivilege(mongo.ActionCollMod, c.state.Db, c.state.Coll); err != nil {
+		return err
+	}
+	if err := c.Dao.SetExpireAfter(ctx, c.state.Db, c.state.Coll, seconds); err != nil {
+		return err
+	}
+	if c.state.TimeSeries != nil {
+		c.state.TimeSeries.ExpireAfterSeconds = seconds
+	}
+	return nil
+}
+
+// handleTimeRange opens the filter of measurements of the time-series
+// collection taken within the time range
+func (c *Content) handleTimeRange() *tcell.EventKey {
+	if c.state.TimeSeries == nil {
+		modal.ShowInfo(c.App.Pages, "Time range is available for time-series collections")
+		return nil
+	}
+	c.timeRange.Render(c.state.TimeSeries)
+	return nil
+}
+
+// handleArchive asks where documents matching the current filter
+// are archived and if they're deleted afterward
+func (c *Content) handleArchive() *tcell.EventKey {
+	if c.state.Coll == "" {
+		return nil
+	}
+	if c.state.Pipeline != "" {
+		modal.ShowInfo(c.App.Pages, "Documents matching the filter are archived, close the pipeline first")
+		return nil
+	}
+	path := mongo.ExportFileName(c.state.Coll, mongo.ArchiveExtension, time.Now())
+	c.archive.Render(c.state.Db+"."+c.state.Coll, c.state.Filter, c.state.GetCount(), path)
+	return nil
+}
+
+// archiveDocuments archives documents matching the current filter into
+// the file as a background job and deletes them if remove is set. The
+// archive is restored as the BSON file of the collection in the database tree.
+func (c *Content) archiveDocuments(ctx context.Context, path string, remove bool) {
+	if remove && !c.checkPrivilege(mongo.ActionRemove) {
+		return
+	}
+	path, err := filepath.Abs(path)
+	if err != nil {
+		modal.ShowError(c.App.Pages, "Error archiving documents", err)
+		return
+	}
+	filter, _, err := c.currentQuery()
+	if err != nil {
+		modal.ShowError(c.App.Pages, "Error parsing query", err)
+		return
+	}
+
+	db, coll, dao := c.state.Db, c.state.Coll, c.Dao
+	name := fmt.Sprintf("Archive %s.%s to %s", db, coll, filepath.Base(path))
+	c.App.GetJobs().Start(ctx, name, func(ctx context.Context, job *manager.Job) error {
+		result, err := dao.ArchiveDocuments(ctx, path, db, coll, filter, remove, dumpProgress(job))
+		job.Logf("%d documents matched, %d archived into %s", result.Matched, result.Archived, path)
+		if remove {
+			job.Logf("%d deleted", result.Deleted)
+		}
+		if result.Deleted > 0 {
+			c.refreshImported(db, coll)
+		}
+		return err
+	})
+	c.showJobStarted(name)
+}
+
+// handleCopyMapLink copies the OpenStreetMap link of the selected GeoJSON
+// location, or of the first location of the selected document
+func (c *Content) handleCopyMapLink(row, col int) *tcell.EventKey {
+	field, geo, ok := c.selectedGeoJSON(row, col)
+	if !ok {
+		modal.ShowInfo(c.App.Pages, "No GeoJSON location in the selected document")
+		return nil
+	}
+	if err := clipboard.WriteAll(geo.MapLink()); err != nil {
+		modal.ShowError(c.App.Pages, "Error copying map link", err)
+		return nil
+	}
+	modal.ShowInfo(c.App.Pages, fmt.Sprintf("Map link of %s copied", field))
+	return nil
+}
+
+// selectedGeoJSON returns the GeoJSON value of the selected cell in the
+// table view, or the first GeoJSON field of the selected document,
+// masked fields are skipped
+func (c *Content) selectedGeoJSON(row, col int) (string, mongo.GeoJSON, bool) {
+	masker := c.masker()
+	if c.currentView == TableView && row > 0 && row <= len(c.tableDocs) && col < len(c.tableFields) {
+		field := c.tableFields[col]
+		if val, ok := c.tableDocs[row-1].GetPath(field); ok && !masker.IsMasked(field) {
+			if geo, ok := mongo.ParseGeoJSON(val); ok {
+				return field, geo, true
+			}
+		}
+	}
+	id := c.getDocumentId(row, col)
+	if id == nil {
+		return "", mongo.GeoJSON{}, false
+	}
+	doc := c.state.GetDocById(id)
+	if doc == nil {
+		return "", mongo.GeoJSON{}, false
+	}
+	return mongo.FindGeoJSON(doc, masker.IsMasked)
+}
+
+// applyFilter sets the filter built in the filter builder,
+// so it can be still changed in the query bar
+func (c *Content) applyFilter(ctx context.Context, filter string) {
+	c.queryBar.SetText(filter)
+	c.state.UpdateFilter(filter)
+	c.stateMap.Set(c.stateMap.Key(c.state.Db, c.state.Coll), c.state)
+	err := c.updateContent(ctx, false)
+	if err != nil {
+		modal.ShowError(c.App.Pages, "Error updating content", err)
+		return
+	}
+	c.App.SetFocus(c.table)
+}
+
+// handleToggleCount switches between exact and estimated count
+func (c *Content) handleToggleCount(ctx context.Context) *tcell.EventKey {
+	c.state.EstimatedCount = !c.state.EstimatedCount
+	c.stateMap.Set(c.stateMap.Key(c.state.Db, c.state.Coll), c.state)
+	if err := c.updateContent(ctx, false); err != nil {
+		modal.ShowError(c.App.Pages, "Error updating content", err)
+	}
+	return nil
+}
+
+// handleToggleDeleted shows or hides documents marked as deleted,
+// if the collection uses soft delete
+func (c *Content) handleToggleDeleted(ctx context.Context) *tcell.EventKey {
+	if c.state.SoftDeleteField == "" {
+		modal.ShowInfo(c.App.Pages, "Soft delete is not configured for this collection")
+		return nil
+	}
+	c.state.ShowDeleted = !c.state.ShowDeleted
+	c.state.Page = 0
+	c.stateMap.Set(c.stateMap.Key(c.state.Db, c.state.Coll), c.state)
+	if err := c.updateContent(ctx, false); err != nil {
+		modal.ShowError(c.App.Pages, "Error updating content", err)
+	}
+	return nil
+}
+
+// handleDocumentHistory shows versions of the document saved
+// before it was modified, if document history is enabled
+func (c *Content) handleDocumentHistory(ctx context.Context, row, coll int) *tcell.EventKey {
+	if !c.Dao.Config.DocumentHistory {
+		modal.ShowInfo(c.App.Pages, "Document history is not enabled for this connection")
+		return nil
+	}
+	_id := c.getDocumentId(row, coll)
+	if _id == nil {
+		return nil
+	}
+
+	versions, err := c.Dao.ListDocumentVersions(ctx, c.state.Db, c.state.Coll, _id)
+	if err != nil {
+		modal.ShowError(c.App.Pages, "Error listing document versions", err)
+		return nil
+	}
+	if len(versions) == 0 {
+		modal.ShowInfo(c.App.Pages, "There are no saved versions of this document")
+		return nil
+	}
+	c.versions.Render(versions)
+	return nil
+}
+
+// restoreVersion replaces the document with the saved version and
+// reloads the page, as the document may have been deleted before
+func (c *Content) restoreVersion(ctx context.Context, version mongo.DocumentVersion) {
+	if !c.checkPrivilege(mongo.ActionUpdate) {
+		return
+	}
+	if err := c.Dao.RestoreDocumentVersion(ctx, c.state.Db, c.state.Coll, version); err != nil {
+		modal.ShowError(c.App.Pages, "Error restoring document", err)
+		return
+	}
+	row, col := c.table.GetSelection()
+	if err := c.updateContent(ctx, false); err != nil {
+		modal.ShowError(c.App.Pages, "Error updating content", err)
+		return
+	}
+	c.table.Select(row, col)
+	c.App.SetFocus(c.table)
+}
+
+// handleFanOut opens the query of many collections of the current
+// database, prefilled with the current collection and filter
+func (c *Content) handleFanOut() *tcell.EventKey {
+	if c.state.Coll == "" {
+		return nil
+	}
+	c.fanOut.Render(c.state.Coll, c.state.Filter)
+	return nil
+}
+
+// runFanOut runs the filter against collections matching the patterns,
+// the sort of the current collection is applied to every one of them
+func (c *Content) runFanOut(ctx context.Context, patterns, filter string) {
+	parsedFilter, err := mongo.ParseStringQuery(filter)
+	if err != nil {
+		modal.ShowError(c.App.Pages, "Error parsing filter", err)
+		return
+	}
+	sort, err := mongo.ParseStringSort(c.state.Sort)
+	if err != nil {
+		modal.ShowError(c.App.Pages, "Error parsing sort", err)
+		return
+	}
+
+	allColls, err := c.Dao.ListCollectionNames(ctx, c.state.Db)
+	if err != nil {
+		modal.ShowError(c.App.Pages, "Error listing collections", err)
+		return
+	}
+	colls, err := mongo.MatchCollections(allColls, patterns)
+	if err != nil {
+		modal.ShowError(c.App.Pages, "Error matching collections", err)
+		return
+	}
+	if len(colls) == 0 {
+		modal.ShowInfo(c.App.Pages, "No collections match "+patterns)
+		return
+	}
+
+	documents, err := c.Dao.FanOutFind(ctx, c.state.Db, colls, parsedFilter, sort, c.pageSize())
+	if err != nil {
+		modal.ShowError(c.App.Pages, "Error querying collections", err)
+		return
+	}
+	c.fanOut.RenderResults(colls, c.maskDocuments(documents))
+}
+
+// handleToggleAutoRefresh turns reloading of the page
+// in the configured interval on or off
+func (c *Content) handleToggleAutoRefresh() *tcell.EventKey {
+	if c.state.Coll == "" {
+		return nil
+	}
+	c.setAutoRefresh(c.stopAutoRefresh == nil)
+	c.rerender()
+	return nil
+}
+
+// handleToggleOnlyChanges shows only documents which differ
+// from the previous auto-refresh, or all of them again
+func (c *Content) handleToggleOnlyChanges() *tcell.EventKey {
+	if c.stopAutoRefresh == nil {
+		modal.ShowInfo(c.App.Pages, "Changes are tracked only when auto-refresh is on")
+		return nil
+	}
+	c.onlyChanges = !c.onlyChanges
+	if err := c.updateContent(c.App.Context(), true); err != nil {
+		modal.ShowError(c.App.Pages, "Error rendering content", err)
+	}
+	return nil
+}
+
+// setAutoRefresh starts or stops reloading of the page,
+// tracked changes are dropped when it's stopped
+func (c *Content) setAutoRefresh(on bool) {
+	if c.stopAutoRefresh != nil {
+		c.stopAutoRefresh()
+		c.stopAutoRefresh = nil
+	}
+	c.diff = nil
+	c.onlyChanges = false
+	if !on {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(c.App.Context())
+	c.stopAutoRefresh = cancel
+	interval := c.refreshInterval()
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				c.App.QueueBackgroundUpdate(func() {
+					// auto-refresh may have been stopped while the update was queued
+					if ctx.Err() == nil {
+						c.autoRefresh(ctx)
+					}
+				})
+			}
+		}
+	}()
+}
+
+func (c *Content) refreshInterval() time.Duration {
+	interval := time.Duration(c.App.GetConfig().Table.RefreshInterval) * time.Second
+	if interval <= 0 {
+		return defaultRefreshInterval
+	}
+	return interval
+}
+
+// autoRefresh reloads the page and compares it with the previous one,
+// selected cell and scroll position are kept
+func (c *Content) autoRefresh(ctx context.Context) {
+	previous := c.state.GetLazyDocs()
+	c.prefetcher.Cancel()
+	documents, _, err := c.listDocuments(ctx)
+	if err != nil {
+		c.setAutoRefresh(false)
+		modal.ShowError(c.App.Pages, "Error refreshing documents, auto-refresh stopped", err)
+		return
+	}
+	if len(documents) == 0 {
+		c.state.SetCount(0)
+		c.state.PopulateLazyDocs(nil)
+	}
+	c.diff = mongo.DiffResults(previous, documents)
+
+	row, col := c.table.GetSelection()
+	rowOffset, colOffset := c.table.GetOffset()
+	if err := c.updateContent(ctx, true); err != nil {
+		modal.ShowError(c.App.Pages, "Error rendering content", err)
+		return
+	}
+	c.table.Select(row, col)
+	c.table.SetOffset(rowOffset, colOffset)
+}
+
+// handleCountByField shows the most common values of the selected
+// column in documents matching the current filter
+func (c *Content) handleCountByField(ctx context.Context, col int) *tcell.EventKey {
+	if c.currentView != TableView || col >= len(c.tableFields) {
+		return nil
+	}
+	field := c.tableFields[col]
+	if c.masker().IsMasked(field) {
+		modal.ShowInfo(c.App.Pages, "Values of masked fields can't be counted")
+		return nil
+	}
+
+	filter, err := mongo.ParseStringQuery(c.state.Filter)
+	if err != nil {
+		modal.ShowError(c.App.Pages, "Error parsing filter", err)
+		return nil
+	}
+	if c.state.HidesDeleted() {
+		filter = mongo.ExcludeDeleted(filter, c.state.SoftDeleteField)
+	}
+	counts, err := c.Dao.CountByField(ctx, c.state.Db, c.state.Coll, filter, field, countByFieldLimit)
+	if err != nil {
+		modal.ShowError(c.App.Pages, "Error counting values of "+field, err)
+		return nil
+	}
+
+	total := c.state.GetCount()
+	if c.state.IsCountEstimated() {
+		total = 0
+	}
+	c.countByField.Render(field, counts, total)
+	return nil
+}
+
+// filterByValue narrows the current filter to documents
+// where the field has the value
+func (c *Content) filterByValue(ctx context.Context, field string, value interface{}) {
+	filter, err := mongo.FieldValueFilter(c.state.Filter, field, value)
+	if err != nil {
+		modal.ShowError(c.App.Pages, "Error building filter", err)
+		return
+	}
+	c.applyFilter(ctx, filter)
+}
+
+// handleCopyResults asks for the format documents
+// of the current page are copied in
+func (c *Content) handleCopyResults() *tcell.EventKey {
+	if len(c.state.GetAllDocs()) == 0 {
+		modal.ShowInfo(c.App.Pages, "No documents to copy")
+		return nil
+	}
+	c.exportFormat.Render("Copy results as", c.App.GetConfig().Exporters)
+	return nil
+}
+
+// copyResults copies documents of the current page to the clipboard
+// in the format, masked fields stay masked. Markdown table copied from
+// the table view has the same columns as the table, in the same order.
+func (c *Content) copyResults(format mongo.ExportFormat) {
+	documents := c.maskDocuments(c.state.GetAllDocs())
+	var buf bytes.Buffer
+	var err error
+	if format == mongo.FormatMarkdown && c.currentView == TableView && len(c.tableFields) > 0 {
+		err = mongo.WriteMarkdownTable(&buf, documents, c.tableFields, c.App.GetConfig().Display.ExportFormat())
+	} else {
+		err = mongo.ExportDocuments(&buf, documents, format, c.App.GetConfig().Display.ExportFormat())
+	}
+	if err != nil {
+		modal.ShowError(c.App.Pages, "Error formatting documents", err)
+		return
+	}
+	if err := clipboard.WriteAll(buf.String()); err != nil {
+		modal.ShowError(c.App.Pages, "Error copying documents", err)
+		return
+	}
+	modal.ShowInfo(c.App.Pages, fmt.Sprintf("%d documents copied as %s", len(documents), format))
+}
+
+// runExporter exports documents of the current page with the external
+// command of the exporter as a background job, its output is saved
+// in the working directory
+func (c *Content) runExporter(ctx context.Context, exporter config.ExporterConfig) {
+	documents := c.maskDocuments(c.state.GetAllDocs())
+	fileName := mongo.ExportFileName(c.state.Coll, exporter.Extension, time.Now())
+
+	name := fmt.Sprintf("Export %d documents with %s to %s", len(documents), exporter.Name, fileName)
+	c.App.GetJobs().Start(ctx, name, func(ctx context.Context, job *manager.Job) error {
+		job.Logf("Running %s", exporter.Command)
+		if err := exportToFile(ctx, fileName, exporter.Command, documents); err != nil {
+			return err
+		}
+		job.SetProgress(fmt.Sprintf("%d documents", len(documents)))
+		return nil
+	})
+	c.showJobStarted(name)
+}
+
+// showJobStarted informs that the job runs in the background
+func (c *Content) showJobStarted(name string) {
+	modal.ShowInfo(c.App.Pages, fmt.Sprintf("%s started in the background, press %s to see jobs", name, c.App.GetKeys().Global.ShowJobs.String()))
+}
+
+// exportToFile runs the exporter command with its output written to the new
+// file, the file is removed when the command fails
+func exportToFile(ctx context.Context, fileName, command string, documents []primitive.M) error {
+	file, err := os.OpenFile(fileName, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return err
+	}
+	err = mongo.RunExporter(ctx, command, documents, file)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(fileName)
+	}
+	return err
+}
+
+// handleExportResults asks for the path of the file all results
+// of the current query are exported to
+func (c *Content) handleExportResults(ctx context.Context) *tcell.EventKey {
+	if c.state.Coll == "" || c.state.GetCount() == 0 {
+		modal.ShowInfo(c.App.Pages, "No documents to export")
+		return nil
+	}
+	c.exportPath.SetText(mongo.ExportFileName(c.state.Coll, "json", time.Now()))
+	c.exportPath.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyEnter:
+			path := strings.TrimSpace(c.exportPath.GetText())
+			if path == "" {
+				return nil
+			}
+			c.closeExportPath()
+			c.exportResults(ctx, path)
+			return nil
+		case tcell.KeyEscape:
+			c.closeExportPath()
+			return nil
+		}
+		return event
+	})
+	c.App.Pages.AddPage(ExportPathModal, c.exportPath, true, true)
+	return nil
+}
+
+func (c *Content) closeExportPath() {
+	c.exportPath.SetText("")
+	c.App.Pages.RemovePage(ExportPathModal)
+}
+
+// exportResults exports all results of the current query to the file in the
+// format picked by its extension, columns of CSV are chosen from fields
+// of documents of the current page
+func (c *Content) exportResults(ctx context.Context, path string) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		modal.ShowError(c.App.Pages, "Error exporting results", err)
+		return
+	}
+	format := mongo.StreamFormat(path)
+	if format != mongo.FormatCSV {
+		c.streamResults(ctx, path, func(w io.Writer) (*mongo.DocumentStream, error) {
+			return mongo.NewDocumentStream(w, format)
+		})
+		return
+	}
+
+	documents := c.state.GetLazyDocs()
+	types := mongo.DocumentsTypes(documents)
+	flatTypes := mongo.DocumentsFlatTypes(documents, max(c.App.GetConfig().Table.FlattenDepth, 1))
+	c.csvFields.SetExportFunc(func(fields []string, dotted bool) {
+		columns := fields
+		if dotted {
+			columns = mongo.CSVColumns(fields, flatTypes)
+		}
+		c.streamResults(ctx, path, func(w io.Writer) (*mongo.DocumentStream, error) {
+			stream := mongo.NewCSVStream(w, columns)
+			stream.SetValueFormat(c.App.GetConfig().Display.ExportFormat())
+			return stream, nil
+		})
+	})
+	c.csvFields.Render(mongo.CSVFields(types), types)
+}
+
+// streamResults streams all documents of the current query, or results
+// of the pipeline, to the new file as a background job, masked fields
+// stay masked. The file is removed if the export fails.
+func (c *Content) streamResults(ctx context.Context, path string, newStream func(w io.Writer) (*mongo.DocumentStream, error)) {
+	var err error
+	var filter primitive.M
+	var sort primitive.D
+	var pipeline mongo.Pipeline
+	if c.state.Pipeline != "" {
+		pipeline, err = mongo.ParseShellPipeline(c.state.Pipeline)
+	} else {
+		filter, sort, err = c.currentQuery()
+	}
+	if err != nil {
+		modal.ShowError(c.App.Pages, "Error parsing query", err)
+		return
+	}
+
+	// query is read in the background, so it's copied from the state
+	state := &mongo.CollectionState{Db: c.state.Db, Coll: c.state.Coll, Options: c.state.Options}
+	dao, masker := c.Dao, c.masker()
+	name := fmt.Sprintf("Export %s.%s to %s", state.Db, state.Coll, path)
+	c.App.GetJobs().Start(ctx, name, func(ctx context.Context, job *manager.Job) error {
+		file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if err != nil {
+			return err
+		}
+		stream, err := newStream(file)
+		if err == nil {
+			err = dao.StreamDocuments(ctx, state, filter, sort, pipeline, func(doc primitive.M) error {
+				if err := stream.Write(masker.MaskDocument(doc)); err != nil {
+					return err
+				}
+				if stream.Count()%exportProgressEvery == 0 {
+					job.SetProgress(fmt.Sprintf("%d documents", stream.Count()))
+				}
+				return nil
+			})
+		}
+		if err == nil {
+			err = stream.Close()
+		}
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			os.Remove(path)
+			return err
+		}
+		job.SetProgress(fmt.Sprintf("%d documents", stream.Count()))
+		return nil
+	})
+	c.showJobStarted(name)
+}
+
+// handleImportCSV opens the import of the CSV file into the collection
+func (c *Content) handleImportCSV() *tcell.EventKey {
+	if c.state.Coll == "" {
+		return nil
+	}
+	limits := c.App.GetConfig().GetImportLimits(c.Dao.Config)
+	c.csvImport.Render(c.state.Db, c.state.Coll, limits.BatchSize, limits.WritesPerSecond)
 	return nil
 }
 
-func (c *Content) handlePeekDocument(ctx context.Context, row, coll int) *tcell.EventKey {
-	_id := c.getDocumentId(row, coll)
-	if _id == nil {
+// importDocuments writes imported documents into the collection as
+// a background job, the page is reloaded when it's done. The checkpoint
+// is saved after every batch, so the interrupted import can be resumed,
+// and it's removed when the import is done.
+func (c *Content) importDocuments(ctx context.Context, documents []primitive.M, opts mongo.ImportOptions, checkpoint config.ImportCheckpoint) {
+	db, coll := c.state.Db, c.state.Coll
+	checkpoint.Connection = c.Dao.Config.Name
+	checkpoint.Namespace = db + "." + coll
+
+	name := fmt.Sprintf("Import %s into %s", filepath.Base(checkpoint.Source), checkpoint.Namespace)
+	c.App.GetJobs().Start(ctx, name, func(ctx context.Context, job *manager.Job) error {
+		if opts.Skip > 0 {
+			job.Logf("Resuming after %d documents", opts.Skip)
+		}
+		result, err := c.Dao.ImportDocuments(ctx, db, coll, documents, opts, func(progress mongo.ImportProgress) {
+			checkpoint.Done = progress.Done
+			if err := config.SaveCheckpoint(checkpoint); err != nil {
+				job.Logf("Error saving checkpoint: %v", err)
+			}
+			job.SetProgress(progress.String())
+			job.Logf("%d/%d documents written", progress.Done, progress.Total)
+		})
+		job.Logf("%d inserted, %d updated", result.Inserted, result.Updated)
+		if err == nil {
+			if err := config.RemoveCheckpoint(checkpoint.Connection, checkpoint.Namespace, checkpoint.Source); err != nil {
+				job.Logf("Error removing checkpoint: %v", err)
+			}
+		} else {
+			job.Logf("Import the file again to resume")
+		}
+
+		if result.Inserted+result.Updated > 0 {
+			c.refreshImported(db, coll)
+		}
+		return err
+	})
+	c.showJobStarted(name)
+}
+
+// refreshImported reloads documents after the import,
+// if the collection is still open
+func (c *Content) refreshImported(db, coll string) {
+	c.App.QueueUpdateDraw(func() {
+		if c.state.Db != db || c.state.Coll != coll {
+			return
+		}
+		c.autocompleteStale = true
+		if err := c.updateContent(c.App.Context(), false); err != nil {
+			modal.ShowError(c.App.Pages, "Error refreshing documents", err)
+		}
+	})
+}
+
+// handleImportJSON opens the import of the JSON file into the collection
+func (c *Content) handleImportJSON() *tcell.EventKey {
+	if c.state.Coll == "" {
 		return nil
 	}
-	c.peeker.Render(ctx, c.state, _id)
+	limits := c.App.GetConfig().GetImportLimits(c.Dao.Config)
+	c.jsonImport.Render(c.state.Db, c.state.Coll, limits.BatchSize, limits.WritesPerSecond)
 	return nil
 }
 
-func (c *Content) handleViewDocument(row, coll int) *tcell.EventKey {
-	doc, err := c.getDocumentBasedOnView(row, coll)
-	if err != nil {
-		modal.ShowError(c.App.Pages, "Error viewing document", err)
+// importJSON inserts valid documents of the file into the collection as
+// a background job, documents rejected by validation or by the server are
+// summarized in logs of the job
+func (c *Content) importJSON(ctx context.Context, file *mongo.JSONFile, source string, opts mongo.ImportOptions) {
+	db, coll := c.state.Db, c.state.Coll
+	position := "document"
+	if file.NDJSON {
+		position = "line"
+	}
+
+	name := fmt.Sprintf("Import %s into %s.%s", filepath.Base(source), db, coll)
+	c.App.GetJobs().Start(ctx, name, func(ctx context.Context, job *manager.Job) error {
+		logRejected(job, fmt.Sprintf("%d invalid documents skipped", len(file.Rejected)), position, file.Rejected)
+		result, err := c.Dao.ImportDocuments(ctx, db, coll, file.Documents, opts, func(progress mongo.ImportProgress) {
+			ratio := float64(progress.Done) / float64(max(progress.Total, 1))
+			job.SetProgress(util.Gauge(ratio, importGaugeWidth) + " " + progress.String())
+		})
+		job.Logf("%d inserted", result.Inserted)
+		logRejected(job, fmt.Sprintf("%d documents rejected by the server", len(result.Rejected)), "document", result.Rejected)
+
+		if result.Inserted > 0 {
+			c.refreshImported(db, coll)
+		}
+		return err
+	})
+	c.showJobStarted(name)
+}
+
+// logRejected logs the summary and first rejected documents with reasons
+func logRejected(job *manager.Job, summary, position string, rejected []mongo.RejectedDocument) {
+	if len(rejected) == 0 {
+		return
+	}
+	job.Logf("%s", summary)
+	for _, r := range rejected[:min(len(rejected), maxRejectedLogs)] {
+		job.Logf("  %s %s", position, r)
+	}
+	if len(rejected) > maxRejectedLogs {
+		job.Logf("  and %d more", len(rejected)-maxRejectedLogs)
+	}
+}
+
+// handleLookupBuilder opens the builder of the $lookup stage joining
+// the current collection with another collection of the database
+func (c *Content) handleLookupBuilder(ctx context.Context) *tcell.EventKey {
+	if c.state.Coll == "" {
 		return nil
 	}
-	err = c.viewJson(doc)
+	colls, err := c.Dao.ListCollectionNames(ctx, c.state.Db)
 	if err != nil {
-		modal.ShowError(c.App.Pages, "Error viewing document", err)
+		modal.ShowError(c.App.Pages, "Error listing collections", err)
 		return nil
 	}
+	sort.Strings(colls)
+
+	types := mongo.SampleFieldTypes(c.state.GetAllDocs())
+	fields := make([]string, 0, len(types))
+	for field := range types {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	c.lookupBuilder.Render(colls, fields)
 	return nil
 }
 
-func (c *Content) handleAddDocument(ctx context.Context) *tcell.EventKey {
-	id, err := c.docModifier.Insert(ctx, c.state.Db, c.state.Coll)
+// applyLookupStage adds the built $lookup stage to the pipeline when
+// the aggregation editor is open, otherwise it's copied to the clipboard
+func (c *Content) applyLookupStage(stage string) {
+	if c.App.Pages.HasPage(modal.AggregationModal) {
+		c.aggregation.AddStage(stage)
+		return
+	}
+	if err := clipboard.WriteAll(stage); err != nil {
+		modal.ShowError(c.App.Pages, "Error copying stage", err)
+		return
+	}
+	modal.ShowInfo(c.App.Pages, "$lookup stage copied to clipboard")
+}
+
+// handleCollectionStats shows stats of the collection and its growth,
+// current stats are saved as the sample of the growth history
+func (c *Content) handleCollectionStats(ctx context.Context) *tcell.EventKey {
+	if c.state.Coll == "" {
+		return nil
+	}
+	namespace := c.stateMap.Key(c.state.Db, c.state.Coll)
+	stats, err := c.Dao.GetCollectionStats(ctx, c.state.Db, c.state.Coll)
 	if err != nil {
-		modal.ShowError(c.App.Pages, "Error adding document", err)
+		modal.ShowError(c.App.Pages, "Error getting collection stats", err)
 		return nil
 	}
-	insertedDoc, err := c.Dao.GetDocument(ctx, c.state.Db, c.state.Coll, id)
+	if err := config.AddGrowthSample(c.Dao.Config.Name, namespace, stats.GrowthSample(time.Now())); err != nil {
+		log.Error().Err(err).Msg("Error saving collection growth")
+	}
+	samples, err := config.LoadGrowthHistory(c.Dao.Config.Name, namespace)
 	if err != nil {
-		modal.ShowError(c.App.Pages, "Error getting inserted document", err)
+		log.Error().Err(err).Msg("Error loading collection growth")
+	}
+	timeSeries, err := c.Dao.GetTimeSeries(ctx, c.state.Db, c.state.Coll)
+	if err != nil {
+		log.Error().Err(err).Msg("Error loading time-series options")
+	}
+	c.collStats.Render(namespace, stats, samples, timeSeries)
+	return nil
+}
+
+// sampleGrowth saves the size of the opened collection to its growth
+// history, collStats is skipped if the collection was sampled recently
+func (c *Content) sampleGrowth(dao *mongo.Dao, db, coll string) {
+	connection, namespace := dao.Config.Name, c.stateMap.Key(db, coll)
+	samples, err := config.LoadGrowthHistory(connection, namespace)
+	if err != nil {
+		log.Error().Err(err).Msg("Error loading collection growth")
+		return
+	}
+	if len(samples) > 0 && time.Since(samples[len(samples)-1].Time) < config.GrowthSampleInterval {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.App.Context(), 10*time.Second)
+	defer cancel()
+	stats, err := dao.GetCollectionStats(ctx, db, coll)
+	if err != nil {
+		log.Debug().Err(err).Msg("Error getting collection stats")
+		return
+	}
+	if err := config.AddGrowthSample(connection, namespace, stats.GrowthSample(time.Now())); err != nil {
+		log.Error().Err(err).Msg("Error saving collection growth")
+	}
+}
+
+// handleWatch tails changes of the collection, values of documents
+// are masked the same way as in the content
+func (c *Content) handleWatch() *tcell.EventKey {
+	if c.state.Coll == "" {
 		return nil
 	}
-	c.state.AppendDoc(insertedDoc)
-	c.updateContentBasedOnState(ctx)
+	dao, db, coll := c.Dao, c.state.Db, c.state.Coll
+	masker := c.masker()
+	c.watch.SetWatchFunc(func(ctx context.Context, onEvent func(event mongo.ChangeEvent)) error {
+		return dao.Watch(ctx, db, coll, func(event mongo.ChangeEvent) {
+			event.FullDocument = masker.MaskDocument(event.FullDocument)
+			event.UpdatedFields = masker.MaskDocument(event.UpdatedFields)
+			onEvent(event)
+		})
+	})
+	c.watch.Render(c.stateMap.Key(db, coll))
 	return nil
 }
 
-func (c *Content) handleEditDocument(ctx context.Context, row, coll int) *tcell.EventKey {
-	_id := c.getDocumentId(row, coll)
-	doc, err := c.state.GetJsonDocById(_id)
+// handleAggregation opens the editor of the pipeline run on the collection
+func (c *Content) handleAggregation() *tcell.EventKey {
+	if c.state.Coll == "" {
+		return nil
+	}
+	c.aggregation.Render(c.stateMap.Key(c.state.Db, c.state.Coll), c.state.Pipeline)
+	return nil
+}
+
+// savePipelineResults writes results of the pipeline into the target
+// collection of the database as a background job, the target is replaced
+func (c *Content) savePipelineResults(ctx context.Context, pipeline mongo.Pipeline, target string) {
+	db, coll := c.state.Db, c.state.Coll
+	if err := c.Dao.CheckPrivilege(mongo.ActionInsert, db, target); err != nil {
+		modal.ShowError(c.App.Pages, "Missing privilege", err)
+		return
+	}
+
+	dao := c.Dao
+	name := fmt.Sprintf("Save results of pipeline on %s.%s to %s", db, coll, target)
+	c.App.GetJobs().Start(ctx, name, func(ctx context.Context, job *manager.Job) error {
+		if err := dao.AggregateToCollection(ctx, db, coll, pipeline, target); err != nil {
+			return err
+		}
+		job.Logf("Results saved to %s.%s", db, target)
+		return nil
+	})
+	c.showJobStarted(name)
+}
+
+// runPipeline lists results of the pipeline instead of found documents,
+// the empty pipeline lists found documents again. When the pipeline fails,
+// the previous one is kept and the editor is opened again, so it can be fixed.
+func (c *Content) runPipeline(ctx context.Context, pipeline string) {
+	previous := c.state.Pipeline
+	c.state.UpdatePipeline(pipeline)
+	c.stateMap.Set(c.stateMap.Key(c.state.Db, c.state.Coll), c.state)
+	if err := c.updateContent(ctx, false); err != nil {
+		c.state.UpdatePipeline(previous)
+		c.aggregation.Render(c.stateMap.Key(c.state.Db, c.state.Coll), pipeline)
+		modal.ShowError(c.App.Pages, "Error running pipeline", err)
+		return
+	}
+	c.App.SetFocus(c.table)
+}
+
+// aggregateDocuments lists the page of results of the pipeline
+func (c *Content) aggregateDocuments(ctx context.Context) ([]*mongo.LazyDocument, int64, error) {
+	c.prefetcher.Cancel()
+	pipeline, err := mongo.ParseShellPipeline(c.state.Pipeline)
 	if err != nil {
-		modal.ShowError(c.App.Pages, "Error getting document", err)
+		return nil, 0, err
+	}
+	documents, count, err := c.Dao.Aggregate(ctx, c.state, pipeline)
+	if err != nil {
+		return nil, 0, err
+	}
+	c.state.SetCount(count)
+	c.state.PopulateLazyDocs(documents)
+	c.autocompleteStale = true
+	return documents, count, nil
+}
+
+// pipelineInfo returns operators of stages of the pipeline
+func (c *Content) pipelineInfo() string {
+	pipeline, err := mongo.ParseShellPipeline(c.state.Pipeline)
+	if err != nil {
+		return "invalid pipeline"
+	}
+	operators := make([]string, len(pipeline))
+	for i, stage := range pipeline {
+		operators[i] = stage[0].Key
+	}
+	return strings.Join(operators, " > ")
+}
+
+// handleSearch opens the search if the collection has Atlas Search indexes
+func (c *Content) handleSearch(ctx context.Context) *tcell.EventKey {
+	if c.state.Coll == "" {
 		return nil
 	}
-	updated, err := c.docModifier.Edit(ctx, c.state.Db, c.state.Coll, _id, doc)
+	indexes, err := c.Dao.ListSearchIndexes(ctx, c.state.Db, c.state.Coll)
 	if err != nil {
-		modal.ShowError(c.App.Pages, "Error editing document", err)
+		// servers without Atlas Search reject listing of search indexes
+		log.Debug().Err(err).Msg("Error listing search indexes")
+	}
+	var names []string
+	for _, index := range indexes {
+		if index.IsSearch() && index.Queryable {
+			names = append(names, index.Name)
+		}
+	}
+	if len(names) == 0 {
+		modal.ShowInfo(c.App.Pages, fmt.Sprintf("There are no queryable Atlas Search indexes on %s.%s", c.state.Db, c.state.Coll))
 		return nil
 	}
+	c.search.Render(names)
+	return nil
+}
 
-	if updated != "" {
-		c.refreshDocument(ctx, updated)
+// handleVectorSearch opens the vector search if the collection
+// has vector search indexes
+func (c *Content) handleVectorSearch(ctx context.Context) *tcell.EventKey {
+	if c.state.Coll == "" {
+		return nil
+	}
+	indexes, err := c.Dao.ListSearchIndexes(ctx, c.state.Db, c.state.Coll)
+	if err != nil {
+		log.Debug().Err(err).Msg("Error listing search indexes")
+	}
+	var vectorIndexes []mongo.SearchIndex
+	for _, index := range indexes {
+		if index.IsVectorSearch() && index.Queryable {
+			vectorIndexes = append(vectorIndexes, index)
+		}
+	}
+	if len(vectorIndexes) == 0 {
+		modal.ShowInfo(c.App.Pages, fmt.Sprintf("There are no queryable vector search indexes on %s.%s", c.state.Db, c.state.Coll))
+		return nil
 	}
+	c.vectorSearch.Render(vectorIndexes)
 	return nil
 }
 
-func (c *Content) handleDuplicateDocument(ctx context.Context, row, coll int) *tcell.EventKey {
-	doc, err := c.getDocumentBasedOnView(row, coll)
+// runVectorSearch finds documents similar to the vector, which can be
+// taken from the field of the document selected in the table
+func (c *Content) runVectorSearch(ctx context.Context, query mongo.VectorSearchQuery, vector string) {
+	row, col := c.table.GetSelection()
+	selected := c.state.GetDocById(c.getDocumentId(row, col))
+	parsed, err := mongo.ParseQueryVector(vector, selected)
 	if err != nil {
-		modal.ShowError(c.App.Pages, "Error duplicating document", err)
-		return nil
+		modal.ShowError(c.App.Pages, "Error parsing vector", err)
+		return
 	}
-	id, err := c.docModifier.Duplicate(ctx, c.state.Db, c.state.Coll, doc)
+	query.Vector = parsed
+
+	results, err := c.Dao.VectorSearch(ctx, c.state.Db, c.state.Coll, query, c.pageSize())
 	if err != nil {
-		modal.ShowError(c.App.Pages, "Error duplicating document", err)
+		modal.ShowError(c.App.Pages, "Error searching documents", err)
+		return
+	}
+	masker := c.masker()
+	for i, result := range results {
+		results[i].Document = masker.MaskDocument(result.Document)
+	}
+	c.vectorSearch.RenderResults(results)
+}
+
+// runSearch runs the search on the current collection, highlights
+// of masked fields are dropped, as they would reveal the values
+func (c *Content) runSearch(ctx context.Context, query mongo.SearchQuery) {
+	results, err := c.Dao.Search(ctx, c.state.Db, c.state.Coll, query, c.pageSize())
+	if err != nil {
+		modal.ShowError(c.App.Pages, "Error searching documents", err)
+		return
+	}
+
+	masker := c.masker()
+	for i, result := range results {
+		results[i].Document = masker.MaskDocument(result.Document)
+		highlights := result.Highlights[:0]
+		for _, highlight := range result.Highlights {
+			if !masker.IsMasked(highlight.Path) {
+				highlights = append(highlights, highlight)
+			}
+		}
+		results[i].Highlights = highlights
+	}
+	c.search.RenderResults(results)
+}
+
+// handleSwitchTenant lists tenants of the namespace template
+// matching the current collection
+func (c *Content) handleSwitchTenant(ctx context.Context) *tcell.EventKey {
+	template, tenant, ok := c.Dao.Config.GetNamespaceTemplate(c.state.Coll)
+	if !ok {
+		modal.ShowInfo(c.App.Pages, "Collection doesn't match any namespace template")
 		return nil
 	}
-	duplicatedDoc, err := c.Dao.GetDocument(ctx, c.state.Db, c.state.Coll, id)
+
+	colls, err := c.Dao.ListCollectionNames(ctx, c.state.Db)
 	if err != nil {
-		modal.ShowError(c.App.Pages, "Error getting inserted document", err)
+		modal.ShowError(c.App.Pages, "Error listing collections", err)
 		return nil
 	}
-	c.state.AppendDoc(duplicatedDoc)
-	c.updateContentBasedOnState(ctx)
+	c.tenantPicker.Render(template.String(), template.Tenants(colls), tenant)
 	return nil
 }
 
-func (c *Content) handleToggleQuery() *tcell.EventKey {
-	if c.state.Filter != "" {
-		c.queryBar.Toggle(c.state.Filter)
-	} else {
-		c.queryBar.Toggle("")
+// switchTenant opens the collection of the tenant, current filter
+// and sort are kept, as collections of tenants have the same shape
+func (c *Content) switchTenant(ctx context.Context, tenant string) {
+	template, _, ok := c.Dao.Config.GetNamespaceTemplate(c.state.Coll)
+	if !ok {
+		return
 	}
-	c.Render(true)
+	filter, sort := c.state.Filter, c.state.Sort
+
+	if err := c.HandleDatabaseSelection(ctx, c.state.Db, template.Collection(tenant)); err != nil {
+		modal.ShowError(c.App.Pages, "Error switching tenant", err)
+		return
+	}
+	if filter == c.state.Filter && sort == c.state.Sort {
+		return
+	}
+	c.state.UpdateFilter(filter)
+	c.state.UpdateSort(sort)
+	if err := c.updateContent(ctx, false); err != nil {
+		modal.ShowError(c.App.Pages, "Error updating content", err)
+	}
+}
+
+func (c *Content) handleQueryOptions() *tcell.EventKey {
+	c.queryOptions.Render(c.state.Options)
 	return nil
 }
 
+// applyQueryOptions sets options used by the next query, limit
+// from options replaces the page size
+func (c *Content) applyQueryOptions(ctx context.Context, opts mongo.QueryOptions) {
+	c.state.Options = opts
+	c.state.Page = 0
+	c.state.Limit = c.pageSize()
+	if opts.Limit > 0 {
+		c.state.Limit = opts.Limit
+	}
+	c.stateMap.Set(c.stateMap.Key(c.state.Db, c.state.Coll), c.state)
+	if err := c.updateContent(ctx, false); err != nil {
+		modal.ShowError(c.App.Pages, "Error updating content", err)
+		return
+	}
+	c.App.SetFocus(c.table)
+}
+
+// pageSize returns number of documents that fit in the table,
+// unless page size is set in the config
+func (c *Content) pageSize() int64 {
+	if c.Dao.Config.PageSize > 0 {
+		return int64(c.Dao.Config.PageSize)
+	}
+	_, _, _, height := c.table.GetInnerRect()
+	return int64(height - 1)
+}
+
 func (c *Content) handleToggleSort() *tcell.EventKey {
+	types := mongo.SampleFieldTypes(c.state.GetAllDocs())
+	fields := make([]string, 0, len(types))
+	for field := range types {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	if err := c.sortBuilder.Render(fields, c.state.Sort); err != nil {
+		// sort typed by hand may not be supported by the builder
+		log.Warn().Err(err).Msg("Sort can't be edited in the builder")
+		c.toggleSortBar()
+	}
+	return nil
+}
+
+// toggleSortBar shows the sort bar where the sort can be typed
+func (c *Content) toggleSortBar() {
+	c.refreshAutocompleteKeys()
 	if c.state.Sort != "" {
 		c.sortBar.Toggle(c.state.Sort)
 	} else {
 		c.sortBar.Toggle("")
 	}
 	c.Render(true)
+}
+
+// applySort sets the sort and saves it, so it's used
+// next time the collection is opened
+func (c *Content) applySort(ctx context.Context, sort string) {
+	c.state.UpdateSort(sort)
+	c.stateMap.Set(c.stateMap.Key(c.state.Db, c.state.Coll), c.state)
+	if err := config.SaveSort(c.stateMap.Key(c.state.Db, c.state.Coll), c.state.Sort); err != nil {
+		log.Error().Err(err).Msg("Error saving sort")
+	}
+	if err := c.updateContent(ctx, false); err != nil {
+		modal.ShowError(c.App.Pages, "Error updating content", err)
+	}
+}
+
+// handleResizeColumn widens or narrows the selected column of the table
+// view by delta, the width is saved for the collection
+func (c *Content) handleResizeColumn(col, delta int) *tcell.EventKey {
+	if c.currentView != TableView || col < 0 || col >= len(c.tableFields) {
+		return nil
+	}
+	width := max(c.columnWidth(col)+delta, 1)
+	c.columns.SetWidth(c.tableFields[col], width)
+	c.saveColumnLayout()
+	c.rerender()
+	return nil
+}
+
+// handlePinColumn pins the selected column of the table view,
+// or unpins it, selection follows the column to its new place
+func (c *Content) handlePinColumn(col int) *tcell.EventKey {
+	if c.currentView != TableView || col < 0 || col >= len(c.tableFields) {
+		return nil
+	}
+	field := c.tableFields[col]
+	c.columns.TogglePin(field)
+	c.saveColumnLayout()
+	c.rerender()
+	for newCol, f := range c.tableFields {
+		if f == field {
+			row, _ := c.table.GetSelection()
+			c.table.Select(row, newCol)
+			break
+		}
+	}
 	return nil
 }
 
+// handleToggleFlatten switches between embedded documents
+// shown as a single column and their fields as dotted columns
+func (c *Content) handleToggleFlatten() *tcell.EventKey {
+	if c.currentView != TableView {
+		return nil
+	}
+	c.flatten = !c.flatten
+	c.rerender()
+	c.table.Select(1, 0)
+	return nil
+}
+
+// flattenDepth returns how many levels of embedded
+// documents are flattened in the table view
+func (c *Content) flattenDepth() int {
+	if !c.flatten {
+		return 0
+	}
+	return max(c.App.GetConfig().Table.FlattenDepth, 1)
+}
+
+// handleToggleAutoFit switches between columns fitted
+// to their values and the width of the table split evenly
+func (c *Content) handleToggleAutoFit() *tcell.EventKey {
+	c.columns.AutoFit = !c.columns.AutoFit
+	c.saveColumnLayout()
+	c.rerender()
+	return nil
+}
+
+func (c *Content) saveColumnLayout() {
+	if err := config.SaveColumnLayout(c.stateMap.Key(c.state.Db, c.state.Coll), c.columns); err != nil {
+		log.Error().Err(err).Msg("Error saving column layout")
+	}
+}
+
 func (c *Content) handleDeleteDocument(ctx context.Context, row, coll int) *tcell.EventKey {
+	if !c.checkDocumentsEditable() {
+		return nil
+	}
 	doc, err := c.getDocumentBasedOnView(row, coll)
 	if err != nil {
 		modal.ShowError(c.App.Pages, "Error deleting document", err)
@@ -788,6 +2773,47 @@ func (c *Content) handleDeleteDocument(ctx context.Context, row, coll int) *tcel
 	return nil
 }
 
+// TableBox returns the bordered box of the documents table
+func (c *Content) TableBox() *tview.Box {
+	return c.tableFlex.Box
+}
+
+// Namespace returns the database and collection
+// which documents are shown, empty if none is opened
+func (c *Content) Namespace() (db, coll string) {
+	return c.state.Db, c.state.Coll
+}
+
+// checkDocumentsEditable informs that results of the aggregation pipeline
+// can't be changed, as they are not documents of the collection
+func (c *Content) checkDocumentsEditable() bool {
+	if c.state.Pipeline == "" {
+		return true
+	}
+	modal.ShowInfo(c.App.Pages, "Results of the aggregation pipeline can't be changed, run the empty pipeline to list documents again")
+	return false
+}
+
+// checkPrivilege shows an error and returns false if the user lacks
+// the privilege for the action on the current collection
+func (c *Content) checkPrivilege(action string) bool {
+	if err := c.Dao.CheckPrivilege(action, c.state.Db, c.state.Coll); err != nil {
+		modal.ShowError(c.App.Pages, "Missing privilege", err)
+		return false
+	}
+	return true
+}
+
+// Refresh reloads documents of the current collection,
+// the prefetched page is dropped as it may be outdated
+func (c *Content) Refresh(ctx context.Context) {
+	c.prefetcher.Cancel()
+	if c.state.Coll == "" {
+		return
+	}
+	c.handleRefresh(ctx)
+}
+
 func (c *Content) handleRefresh(ctx context.Context) *tcell.EventKey {
 	err := c.updateContent(ctx, false)
 	if err != nil {
@@ -819,7 +2845,7 @@ func (c *Content) handlePreviousDocument(row, col int) *tcell.EventKey {
 }
 
 func (c *Content) handleNextPage(ctx context.Context) *tcell.EventKey {
-	if c.state.Page+c.state.Limit >= c.state.Count {
+	if c.state.Page+c.state.Limit >= c.state.GetCount() {
 		return nil
 	}
 	c.state.Page += c.state.Limit
@@ -860,6 +2886,9 @@ func (c *Content) handleCopyLine(row, col int) *tcell.EventKey {
 func (c *Content) handleCopyDocument(row, col int) *tcell.EventKey {
 	docId := c.getDocumentId(row, col)
 	doc, err := c.state.GetJsonDocById(docId)
+	if err == nil {
+		doc, err = c.masker().MaskJson(doc)
+	}
 	if err != nil {
 		modal.ShowError(c.App.Pages, "Error copying document", err)
 		return nil
@@ -2,8 +2,8 @@ package component
 
 import (
 	"context"
-	"regexp"
 	"sync"
+	"time"
 
 	"github.com/gdamore/tcell/v2"
 	"github.com/kopecmaciej/tview"
@@ -18,6 +18,10 @@ const (
 	FilterBarView     = "FilterBar"
 )
 
+// filterDebounce is the time after the last key press after which
+// the tree is filtered while typing
+const filterDebounce = 150 * time.Millisecond
+
 // Database is flex container for DatabaseTree and InputBar
 type Database struct {
 	*core.BaseElement
@@ -27,6 +31,11 @@ type Database struct {
 	filterBar    *InputBar
 	mutex        sync.Mutex
 	dbsWithColls []mongo.DBsWithCollections
+	// filterTimer delays filtering until user stops typing
+	filterTimer *time.Timer
+	// filterGen is increased with every filtering, so results
+	// of the outdated ones are dropped
+	filterGen int
 }
 
 func NewDatabase() *Database {
@@ -90,7 +99,7 @@ func (d *Database) handleEvents() {
 			d.setStyle()
 			d.DbTree.RefreshStyle()
 		}
-	})
+	}, manager.StyleChanged)
 }
 
 func (d *Database) Render() {
@@ -110,7 +119,10 @@ func (d *Database) Render() {
 		return
 	}
 
-	d.DbTree.Render(context.Background(), d.dbsWithColls, false)
+	d.mutex.Lock()
+	dbsWithColls := d.dbsWithColls
+	d.mutex.Unlock()
+	d.DbTree.Render(context.Background(), dbsWithColls, false)
 
 	d.Flex.AddItem(d.DbTree, 0, 1, true)
 }
@@ -123,40 +135,57 @@ func (d *Database) filterBarHandler(ctx context.Context) {
 		d.Render()
 	}
 	d.filterBar.DoneFuncHandler(accceptFunc, rejectFunc)
+	d.filterBar.SetChangedFunc(func(text string) {
+		d.debounceFilter(ctx, text)
+	})
 }
 
-func (d *Database) filter(ctx context.Context, text string) {
-	dbsWitColls := d.dbsWithColls
-	expand := true
-	filtered := []mongo.DBsWithCollections{}
-	if text == "" {
-		filtered = dbsWitColls
-		expand = false
-	} else {
-		re := regexp.MustCompile(`(?i)` + regexp.QuoteMeta(text))
-		for _, db := range dbsWitColls {
-			matchedDB := re.MatchString(db.DB)
-			matchedCollections := []string{}
-
-			for _, coll := range db.Collections {
-				if re.MatchString(coll) {
-					matchedCollections = append(matchedCollections, coll)
-				}
-			}
+// debounceFilter filters the tree while typing, once user stops typing
+// for filterDebounce, matching is done outside of the UI goroutine
+func (d *Database) debounceFilter(ctx context.Context, text string) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
 
-			if matchedDB || len(matchedCollections) > 0 {
-				filteredDB := mongo.DBsWithCollections{
-					DB:          db.DB,
-					Collections: matchedCollections,
-				}
-				if matchedDB {
-					filteredDB.Collections = db.Collections
-				}
-				filtered = append(filtered, filteredDB)
-				expand = expand || len(matchedCollections) > 0
-			}
+	d.filterGen++
+	gen := d.filterGen
+	if d.filterTimer != nil {
+		d.filterTimer.Stop()
+	}
+	d.filterTimer = time.AfterFunc(filterDebounce, func() {
+		d.mutex.Lock()
+		dbsWithColls := d.dbsWithColls
+		d.mutex.Unlock()
+
+		filtered, expand := mongo.FilterDBsWithCollections(dbsWithColls, text)
+		// UI updates are not processed after the app is closed
+		if d.App.Context().Err() != nil {
+			return
 		}
+
+		d.App.QueueUpdateDraw(func() {
+			d.mutex.Lock()
+			outdated := gen != d.filterGen
+			d.mutex.Unlock()
+			if outdated || !d.filterBar.IsEnabled() {
+				return
+			}
+			d.DbTree.Render(ctx, filtered, expand)
+		})
+	})
+}
+
+// filter filters the tree immediately and closes the filter bar
+func (d *Database) filter(ctx context.Context, text string) {
+	d.mutex.Lock()
+	// pending filtering is not needed anymore
+	d.filterGen++
+	if d.filterTimer != nil {
+		d.filterTimer.Stop()
 	}
+	dbsWithColls := d.dbsWithColls
+	d.mutex.Unlock()
+
+	filtered, expand := mongo.FilterDBsWithCollections(dbsWithColls, text)
 	d.DbTree.Render(ctx, filtered, expand)
 
 	d.Flex.RemoveItem(d.filterBar)
@@ -169,11 +198,25 @@ func (d *Database) listDbsAndCollections(ctx context.Context) error {
 	if err != nil {
 		return err
 	}
+	d.mutex.Lock()
 	d.dbsWithColls = dbsWitColls
+	d.mutex.Unlock()
 
 	return nil
 }
 
+// SelectCollection moves the cursor to the collection,
+// it returns false if the collection is not in the tree
+func (d *Database) SelectCollection(db, coll string) bool {
+	return d.DbTree.SelectCollection(db, coll)
+}
+
 func (d *Database) SetSelectFunc(f func(ctx context.Context, db string, coll string) error) {
 	d.DbTree.SetSelectFunc(f)
 }
+
+// SetAnnounceFunc sets the function receiving the description
+// of the current node in accessible mode
+func (d *Database) SetAnnounceFunc(onAnnounce func(text string)) {
+	d.DbTree.SetAnnounceFunc(onAnnounce)
+}
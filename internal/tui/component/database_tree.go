@@ -3,6 +3,7 @@ package component
 import (
 	"context"
 	"fmt"
+	"path/filepath"
 	"strings"
 
 	"github.com/gdamore/tcell/v2"
@@ -13,7 +14,7 @@ import (
 	"github.com/kopecmaciej/vi-mongo/internal/tui/core"
 	"github.com/kopecmaciej/vi-mongo/internal/tui/modal"
 	"github.com/kopecmaciej/vi-mongo/internal/tui/primitives"
-	"github.com/rs/zerolog/log"
+	"github.com/kopecmaciej/vi-mongo/internal/util"
 )
 
 const (
@@ -21,6 +22,7 @@ const (
 	ConfirmModalView      = "ConfirmModal"
 	DatabaseTreeComponent = "DatabaseTree"
 	DatabaseDeleteModal   = "DatabaseDeleteModal"
+	DumpPathModal         = "DumpPathModal"
 )
 
 type DatabaseTree struct {
@@ -29,9 +31,12 @@ type DatabaseTree struct {
 
 	addModal    *primitives.InputModal
 	deleteModal *modal.Delete
+	dumpPath    *primitives.InputModal
 	style       *config.DatabasesStyle
 
 	nodeSelectFunc func(ctx context.Context, db string, coll string) error
+	// onAnnounce receives the description of the current node in accessible mode
+	onAnnounce func(text string)
 }
 
 func NewDatabaseTree() *DatabaseTree {
@@ -40,6 +45,7 @@ func NewDatabaseTree() *DatabaseTree {
 		TreeView:    core.NewTreeView(),
 		addModal:    primitives.NewInputModal(),
 		deleteModal: modal.NewDeleteModal(DatabaseDeleteModal),
+		dumpPath:    primitives.NewInputModal(),
 	}
 
 	d.SetIdentifier(DatabaseTreeComponent)
@@ -56,7 +62,9 @@ func (t *DatabaseTree) init() error {
 	t.setKeybindings(ctx)
 	t.SetSelectedFunc(func(node *tview.TreeNode) {
 		t.SetCurrentNode(node)
+		t.announce(node)
 	})
+	t.SetChangedFunc(t.announce)
 
 	if err := t.deleteModal.Init(t.App); err != nil {
 		return err
@@ -69,12 +77,14 @@ func (t *DatabaseTree) init() error {
 
 func (t *DatabaseTree) setStaticLayout() {
 	t.SetBorder(true)
-	t.SetTitle(" Databases ")
+	t.SetTitle(" " + config.Msg("databases.title") + " ")
 	t.SetBorderPadding(0, 0, 1, 1)
 	t.SetGraphics(false)
 
 	t.addModal.SetBorder(true)
-	t.addModal.SetTitle("Add collection")
+	t.addModal.SetTitle(config.Msg("databases.add.title"))
+
+	t.dumpPath.SetBorder(true)
 }
 
 func (t *DatabaseTree) setStyle() {
@@ -86,6 +96,11 @@ func (t *DatabaseTree) setStyle() {
 	t.addModal.SetBackgroundColor(globalStyle.Global.BackgroundColor.Color())
 	t.addModal.SetFieldTextColor(globalStyle.Others.ModalTextColor.Color())
 	t.addModal.SetFieldBackgroundColor(globalStyle.Global.ContrastBackgroundColor.Color())
+
+	t.dumpPath.SetBorderColor(globalStyle.Global.BorderColor.Color())
+	t.dumpPath.SetBackgroundColor(globalStyle.Global.BackgroundColor.Color())
+	t.dumpPath.SetFieldTextColor(globalStyle.Others.ModalTextColor.Color())
+	t.dumpPath.SetFieldBackgroundColor(globalStyle.Global.ContrastBackgroundColor.Color())
 }
 
 func (t *DatabaseTree) setKeybindings(ctx context.Context) {
@@ -106,6 +121,12 @@ func (t *DatabaseTree) setKeybindings(ctx context.Context) {
 		case k.Contains(k.Database.DeleteCollection, event.Name()):
 			t.showDeleteCollectionModal(ctx)
 			return nil
+		case k.Contains(k.Database.Dump, event.Name()):
+			t.showDumpModal(ctx)
+			return nil
+		case k.Contains(k.Database.Restore, event.Name()):
+			t.showRestoreModal(ctx)
+			return nil
 		}
 		return event
 	})
@@ -135,7 +156,7 @@ func (t *DatabaseTree) handleEvents() {
 			t.setStyle()
 			t.RefreshStyle()
 		}
-	})
+	}, manager.StyleChanged)
 }
 
 func (t *DatabaseTree) Render(ctx context.Context, dbsWitColls []mongo.DBsWithCollections, expand bool) {
@@ -205,9 +226,15 @@ func (t *DatabaseTree) handleAddCollection(ctx context.Context, parent *tview.Tr
 		return
 	}
 	db, collectionName = t.removeSymbols(db, collectionName)
+	if err := t.Dao.CheckPrivilege(mongo.ActionCreateCollection, db, collectionName); err != nil {
+		t.closeAddModal()
+		modal.ShowError(t.App.Pages, "Missing privilege", err)
+		return
+	}
 	err := t.Dao.AddCollection(ctx, db, collectionName)
 	if err != nil {
-		log.Error().Err(err).Msg("Error adding collection")
+		t.closeAddModal()
+		modal.ShowError(t.App.Pages, "Error adding collection", err)
 		return
 	}
 	t.addChildNode(ctx, parent, collectionName, true)
@@ -227,15 +254,264 @@ func (t *DatabaseTree) showDeleteCollectionModal(ctx context.Context) error {
 	db, coll := parent.GetText(), t.GetCurrentNode().GetText()
 	t.deleteModal.SetText(t.getDeleteConfirmationText(db, coll))
 	db, coll = t.removeSymbols(db, coll)
+	if err := t.Dao.CheckPrivilege(mongo.ActionDropCollection, db, coll); err != nil {
+		modal.ShowError(t.App.Pages, "Missing privilege", err)
+		return nil
+	}
+	if !t.Dao.Config.ShouldConfirm() {
+		t.handleDeleteCollection(ctx, db, coll, parent)
+		return nil
+	}
 	t.deleteModal.SetDoneFunc(t.createDeleteCollectionDoneFunc(ctx, db, coll, parent))
 	t.App.Pages.AddPage(ConfirmModalView, t.deleteModal, true, true)
 	return nil
 }
 
+// selectedNamespace returns the database and the collection of the
+// current node, the collection is empty if the database is selected
+func (t *DatabaseTree) selectedNamespace() (string, string, bool) {
+	node := t.GetCurrentNode()
+	if node == nil {
+		return "", "", false
+	}
+	switch node.GetLevel() {
+	case 1:
+		db, _ := t.removeSymbols(node.GetText(), "")
+		return db, "", true
+	case 2:
+		parent, ok := node.GetReference().(*tview.TreeNode)
+		if !ok {
+			return "", "", false
+		}
+		db, coll := t.removeSymbols(parent.GetText(), node.GetText())
+		return db, coll, true
+	}
+	return "", "", false
+}
+
+// showDumpModal asks for the directory the selected database
+// or collection is dumped into, in the layout of mongodump
+func (t *DatabaseTree) showDumpModal(ctx context.Context) {
+	db, coll, ok := t.selectedNamespace()
+	if !ok {
+		return
+	}
+	t.showDumpPath(" Dump "+dumpNamespace(db, coll)+" ", "Directory of the dump", "dump", func(dir string) {
+		t.dump(ctx, dir, db, coll)
+	})
+}
+
+// showRestoreModal asks for the dumped directory of the database, or the
+// BSON file of the collection, restored into the selected one
+func (t *DatabaseTree) showRestoreModal(ctx context.Context) {
+	db, coll, ok := t.selectedNamespace()
+	if !ok {
+		return
+	}
+	if err := t.Dao.CheckPrivilege(mongo.ActionInsert, db, coll); err != nil {
+		modal.ShowError(t.App.Pages, "Missing privilege", err)
+		return
+	}
+	label, path := "Directory of the dumped database", filepath.Join("dump", db)
+	if coll != "" {
+		label = "BSON file of the dumped collection, or the archive"
+		path, _ = mongo.DumpPaths("dump", db, coll)
+	}
+	t.showDumpPath(" Restore into "+dumpNamespace(db, coll)+" ", label, path, func(path string) {
+		t.restore(ctx, path, db, coll)
+	})
+}
+
+func (t *DatabaseTree) showDumpPath(title, label, path string, onDone func(path string)) {
+	t.dumpPath.SetTitle(title)
+	t.dumpPath.SetLabel(label)
+	t.dumpPath.SetText(path)
+	t.dumpPath.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyEnter:
+			path := strings.TrimSpace(t.dumpPath.GetText())
+			if path == "" {
+				return nil
+			}
+			t.closeDumpPath()
+			onDone(path)
+			return nil
+		case tcell.KeyEscape:
+			t.closeDumpPath()
+			return nil
+		}
+		return event
+	})
+	t.App.Pages.AddPage(DumpPathModal, t.dumpPath, true, true)
+}
+
+func (t *DatabaseTree) closeDumpPath() {
+	t.App.Pages.RemovePage(DumpPathModal)
+}
+
+// dump dumps the database, or only the collection if it's set, into
+// the directory as a background job
+func (t *DatabaseTree) dump(ctx context.Context, dir, db, coll string) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		modal.ShowError(t.App.Pages, "Error dumping", err)
+		return
+	}
+
+	name := "Dump " + dumpNamespace(db, coll)
+	t.App.GetJobs().Start(ctx, name, func(ctx context.Context, job *manager.Job) error {
+		if coll != "" {
+			count, err := t.Dao.DumpCollection(ctx, dir, db, coll, dumpProgress(job))
+			if err == nil {
+				path, _ := mongo.DumpPaths(dir, db, coll)
+				job.Logf("%d documents dumped into %s", count, path)
+			}
+			return err
+		}
+		count, err := t.Dao.DumpDatabase(ctx, dir, db, dumpProgress(job))
+		job.Logf("%d collections dumped into %s", count, filepath.Join(dir, db))
+		return err
+	})
+	t.showJobStarted(name)
+}
+
+// restore restores the dumped database directory, or the BSON file of
+// the collection if it's set, as a background job. Restored collections
+// missing in the tree are added to it.
+func (t *DatabaseTree) restore(ctx context.Context, path, db, coll string) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		modal.ShowError(t.App.Pages, "Error restoring", err)
+		return
+	}
+	limits := t.App.GetConfig().GetImportLimits(t.Dao.Config)
+	opts := mongo.RestoreOptions{BatchSize: limits.BatchSize, WritesPerSecond: limits.WritesPerSecond}
+
+	name := fmt.Sprintf("Restore %s into %s", filepath.Base(path), dumpNamespace(db, coll))
+	t.App.GetJobs().Start(ctx, name, func(ctx context.Context, job *manager.Job) error {
+		var result mongo.ImportResult
+		var err error
+		if coll != "" {
+			result, err = t.Dao.RestoreCollection(ctx, path, db, coll, opts, dumpProgress(job))
+		} else {
+			result, err = t.Dao.RestoreDatabase(ctx, path, db, opts, dumpProgress(job))
+		}
+		job.Logf("%d inserted", result.Inserted)
+		logRejected(job, fmt.Sprintf("%d documents rejected by the server", len(result.Rejected)), "document", result.Rejected)
+
+		if colls, listErr := t.Dao.ListCollectionNames(ctx, db); listErr == nil {
+			t.App.QueueUpdateDraw(func() {
+				t.addMissingCollections(ctx, db, colls)
+			})
+		}
+		return err
+	})
+	t.showJobStarted(name)
+}
+
+// addMissingCollections adds nodes of collections
+// of the database which aren't in the tree yet
+func (t *DatabaseTree) addMissingCollections(ctx context.Context, db string, colls []string) {
+	if t.GetRoot() == nil {
+		return
+	}
+	for _, dbNode := range t.GetRoot().GetChildren() {
+		if name, _ := t.removeSymbols(dbNode.GetText(), ""); name != db {
+			continue
+		}
+		existing := make(map[string]bool)
+		for _, collNode := range dbNode.GetChildren() {
+			_, name := t.removeSymbols("", collNode.GetText())
+			existing[name] = true
+		}
+		for _, coll := range colls {
+			if !existing[coll] {
+				t.addChildNode(ctx, dbNode, coll, false)
+			}
+		}
+		return
+	}
+}
+
+func (t *DatabaseTree) showJobStarted(name string) {
+	modal.ShowInfo(t.App.Pages, fmt.Sprintf("%s started in the background, press %s to see jobs", name, t.App.GetKeys().Global.ShowJobs.String()))
+}
+
+// dumpProgress returns the function showing the progress in the job
+func dumpProgress(job *manager.Job) func(mongo.DumpProgress) {
+	return func(progress mongo.DumpProgress) {
+		job.SetProgress(util.Gauge(progress.Ratio, importGaugeWidth) + " " + progress.String())
+	}
+}
+
+// dumpNamespace returns "db.coll", or only the database if there is no collection
+func dumpNamespace(db, coll string) string {
+	if coll == "" {
+		return db
+	}
+	return db + "." + coll
+}
+
+// SelectCollection expands the database and moves the cursor
+// to the collection, it returns false if there is no such collection
+func (t *DatabaseTree) SelectCollection(db, coll string) bool {
+	if t.GetRoot() == nil {
+		return false
+	}
+	for _, dbNode := range t.GetRoot().GetChildren() {
+		for _, collNode := range dbNode.GetChildren() {
+			nodeDb, nodeColl := t.removeSymbols(dbNode.GetText(), collNode.GetText())
+			if nodeDb != db || nodeColl != coll {
+				continue
+			}
+			dbNode.SetExpanded(true)
+			t.updateNodeSymbol(dbNode)
+			t.SetCurrentNode(collNode)
+			return true
+		}
+	}
+	return false
+}
+
 func (t *DatabaseTree) SetSelectFunc(f func(ctx context.Context, db string, coll string) error) {
 	t.nodeSelectFunc = f
 }
 
+// SetAnnounceFunc sets the function receiving the description
+// of the current node in accessible mode
+func (t *DatabaseTree) SetAnnounceFunc(onAnnounce func(text string)) {
+	t.onAnnounce = onAnnounce
+}
+
+func (t *DatabaseTree) announce(node *tview.TreeNode) {
+	if t.onAnnounce == nil || node == nil || !t.App.IsAccessible() {
+		return
+	}
+	t.onAnnounce(t.describeNode(node))
+}
+
+// describeNode describes the database or collection
+// of the node in a line of plain text
+func (t *DatabaseTree) describeNode(node *tview.TreeNode) string {
+	switch node.GetLevel() {
+	case 1:
+		db, _ := t.removeSymbols(node.GetText(), "")
+		state := "collapsed"
+		if node.IsExpanded() {
+			state = "expanded"
+		}
+		return util.Describe("Database "+db, state, fmt.Sprintf("%d collections", len(node.GetChildren())))
+	case 2:
+		parent, ok := node.GetReference().(*tview.TreeNode)
+		if !ok {
+			return ""
+		}
+		db, coll := t.removeSymbols(parent.GetText(), node.GetText())
+		return util.Describe("Collection "+coll, "in database "+db)
+	}
+	return node.GetText()
+}
+
 func (t *DatabaseTree) addChildNode(ctx context.Context, parent *tview.TreeNode, collectionName string, expand bool) {
 	collNode := t.collNode(collectionName)
 	parent.AddChild(collNode).SetExpanded(expand)
@@ -339,6 +615,7 @@ func (t *DatabaseTree) createDeleteCollectionDoneFunc(ctx context.Context, db, c
 func (t *DatabaseTree) handleDeleteCollection(ctx context.Context, db, coll string, parent *tview.TreeNode) {
 	err := t.Dao.DeleteCollection(ctx, db, coll)
 	if err != nil {
+		modal.ShowError(t.App.Pages, "Error deleting collection", err)
 		return
 	}
 	t.removeCollectionNode(parent)
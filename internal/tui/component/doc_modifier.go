@@ -9,6 +9,7 @@ import (
 	"os/exec"
 	"strings"
 
+	"github.com/kopecmaciej/vi-mongo/internal/manager"
 	"github.com/kopecmaciej/vi-mongo/internal/mongo"
 	"github.com/kopecmaciej/vi-mongo/internal/tui/core"
 	"github.com/rs/zerolog/log"
@@ -72,14 +73,31 @@ func (d *DocModifier) Edit(ctx context.Context, db, coll string, _id interface{}
 		return "", nil
 	}
 
-	err = d.updateDocument(ctx, db, coll, _id, jsonDoc, updatedDocument)
-	if err != nil {
-		return "", fmt.Errorf("error saving document: %v", err)
+	if err := d.save(ctx, db, coll, _id, jsonDoc, updatedDocument); err != nil {
+		return "", err
 	}
 
 	return updatedDocument, nil
 }
 
+// save updates the document and lets other views know about the change,
+// nothing is broadcast if the update was rejected, e.g. in read-only mode
+func (d *DocModifier) save(ctx context.Context, db, coll string, _id interface{}, originalDoc, updatedDocument string) error {
+	err := d.updateDocument(ctx, db, coll, _id, originalDoc, updatedDocument)
+	if err != nil {
+		return fmt.Errorf("error saving document: %w", err)
+	}
+
+	d.BroadcastEvent(manager.EventMsg{
+		Message: manager.Message{
+			Type: manager.DocumentUpdated,
+			Data: manager.DocumentUpdatedData{Db: db, Coll: coll, ID: _id, Document: updatedDocument},
+		},
+	})
+
+	return nil
+}
+
 // Duplicate opens the editor with the document and saves it as a new document
 func (d *DocModifier) Duplicate(ctx context.Context, db, coll string, rawDocument string) (primitive.ObjectID, error) {
 	replacedDoc, err := removeField(rawDocument, "_id")
@@ -132,12 +150,15 @@ func (d *DocModifier) updateDocument(ctx context.Context, db, coll string, _id i
 		return fmt.Errorf("error parsing JSON: %v", err)
 	}
 
+	if err := mongo.CheckEncryptedFields(parsedOriginalDoc, parsedDoc); err != nil {
+		return err
+	}
+
 	delete(parsedDoc, "_id")
 	delete(parsedOriginalDoc, "_id")
 	err = d.Dao.UpdateDocument(ctx, db, coll, _id, parsedOriginalDoc, parsedDoc)
 	if err != nil {
-		log.Error().Msgf("error updating document: %v", err)
-		return nil
+		return fmt.Errorf("error updating document: %w", err)
 	}
 
 	return nil
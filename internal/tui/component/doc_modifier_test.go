@@ -0,0 +1,31 @@
+package component
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kopecmaciej/vi-mongo/internal/config"
+	"github.com/kopecmaciej/vi-mongo/internal/manager"
+	"github.com/kopecmaciej/vi-mongo/internal/mongo"
+	"github.com/kopecmaciej/vi-mongo/internal/tui/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSaveRejectedDocumentIsNotBroadcast(t *testing.T) {
+	t.Setenv("ENV", "vi-dev")
+	app := core.NewApp(&config.Config{})
+	app.SetDao(mongo.NewDao(nil, &config.MongoConfig{ReadOnly: true}))
+
+	d := NewDocModifier()
+	require.NoError(t, d.Init(app))
+	updates := app.GetManager().Subscribe("test", manager.DocumentUpdated)
+
+	err := d.save(context.Background(), "shop", "orders", int32(1), `{"_id": 1, "status": "new"}`, `{"_id": 1, "status": "paid"}`)
+	assert.ErrorIs(t, err, mongo.ErrReadOnly)
+	select {
+	case event := <-updates:
+		t.Fatalf("rejected update was broadcast: %v", event)
+	default:
+	}
+}
@@ -2,11 +2,16 @@ package component
 
 import (
 	"fmt"
+	"sort"
 	"strings"
+	"sync/atomic"
+	"time"
 
+	"github.com/gdamore/tcell/v2"
 	"github.com/kopecmaciej/tview"
 	"github.com/kopecmaciej/vi-mongo/internal/config"
 	"github.com/kopecmaciej/vi-mongo/internal/manager"
+	"github.com/kopecmaciej/vi-mongo/internal/mongo"
 	"github.com/kopecmaciej/vi-mongo/internal/tui/core"
 	"github.com/rs/zerolog/log"
 )
@@ -34,6 +39,29 @@ type (
 		baseInfo     BaseInfo
 		keys         []config.Key
 		currentFocus tview.Identifier
+
+		poller *mongo.StatusPoller
+		// status and statusErr are results of the last poll
+		status    *mongo.ServerStatus
+		statusErr error
+		// sessionStart is the first status of the connection, used
+		// to warn when the node stops accepting writes mid-session
+		sessionStart *mongo.ServerStatus
+		warning      string
+		// lastDrawn and lastPoll are unix nanoseconds, header which
+		// wasn't drawn since the last poll is hidden, so it's not polled
+		lastDrawn atomic.Int64
+		lastPoll  atomic.Int64
+		// lastSample is unix nanoseconds of the last status
+		// saved to the stats history of the connection
+		lastSample atomic.Int64
+		// scheduler runs scheduled queries of the connection,
+		// alerts are their last results which crossed the threshold
+		scheduler *mongo.QueryScheduler
+		alerts    map[string]mongo.ScheduledQueryResult
+		// namespace returns the opened collection,
+		// actions of the content are checked on it
+		namespace func() (db, coll string)
 	}
 )
 
@@ -56,13 +84,37 @@ func (h *Header) init() error {
 	h.setStaticLayout()
 
 	h.handleEvents()
+	h.startStatusPolling()
+	h.startScheduledQueries()
 
 	return nil
 }
 
+// UpdateDao updates the dao and restarts polling of the server status
+func (h *Header) UpdateDao(dao *mongo.Dao) {
+	h.BaseElement.UpdateDao(dao)
+	h.status = nil
+	h.statusErr = nil
+	h.sessionStart = nil
+	h.warning = ""
+	h.startStatusPolling()
+	h.startScheduledQueries()
+}
+
+// SetNamespaceFunc sets the function returning the opened collection
+func (h *Header) SetNamespaceFunc(namespace func() (db, coll string)) {
+	h.namespace = namespace
+}
+
+// Draw draws the header and records when it was visible
+func (h *Header) Draw(screen tcell.Screen) {
+	h.lastDrawn.Store(time.Now().UnixNano())
+	h.Table.Draw(screen)
+}
+
 func (h *Header) setStaticLayout() {
 	h.Table.SetBorder(true)
-	h.Table.SetTitle(" Basic Info ")
+	h.Table.SetTitle(" " + config.Msg("header.title") + " ")
 	h.Table.SetBorderPadding(0, 0, 1, 1)
 }
 
@@ -73,13 +125,87 @@ func (h *Header) setStyle() {
 
 // SetBaseInfo sets the basic information about the database connection
 func (h *Header) SetBaseInfo() BaseInfo {
+	if h.statusErr != nil {
+		h.setInactiveBaseInfo(h.statusErr)
+		return h.baseInfo
+	}
+
 	h.baseInfo = BaseInfo{
 		0: {"Status", h.style.ActiveSymbol.String()},
-		1: {"Host", h.Dao.Config.Host},
+		1: {"Host", h.hostInfo()},
+	}
+	read := info{"Read", h.Dao.ReadPreference()}
+	if h.status != nil {
+		h.baseInfo[2] = info{"Version", h.status.Version}
+		h.baseInfo[3] = info{"Uptime", (time.Duration(h.status.Uptime) * time.Second).String()}
+		h.baseInfo[4] = info{"Role", h.roleInfo()}
+		h.baseInfo[5] = info{"Ping", h.status.Ping.Round(time.Millisecond).String()}
+	}
+	h.baseInfo[order(len(h.baseInfo))] = read
+	if h.warning != "" {
+		h.baseInfo[order(len(h.baseInfo))] = info{"Warning", h.warning}
+	}
+	if alert := h.alertInfo(); alert != "" {
+		h.baseInfo[order(len(h.baseInfo))] = info{"Alert", alert}
 	}
 	return h.baseInfo
 }
 
+// alertInfo returns scheduled queries which crossed the threshold
+// with their counts, e.g. "failed jobs: 3 (> 0)"
+func (h *Header) alertInfo() string {
+	names := make([]string, 0, len(h.alerts))
+	for name := range h.alerts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	alerts := make([]string, 0, len(names))
+	for _, name := range names {
+		result := h.alerts[name]
+		alerts = append(alerts, fmt.Sprintf("%s: %d (%s)", name, result.Count, result.Threshold))
+	}
+	return strings.Join(alerts, ", ")
+}
+
+func (h *Header) roleInfo() string {
+	role := h.status.Role()
+	if h.status.Repl.SetName != "" {
+		role = fmt.Sprintf("%s (%s)", role, h.status.Repl.SetName)
+	}
+	if h.status.Repl.ReadOnly {
+		role += " read-only"
+	}
+	return role
+}
+
+// setStatus saves the result of the poll and checks
+// if the node still accepts writes
+func (h *Header) setStatus(status *mongo.ServerStatus, err error) {
+	h.status = status
+	h.statusErr = err
+	if status == nil {
+		return
+	}
+	if h.sessionStart == nil {
+		h.sessionStart = status
+	}
+
+	warning := mongo.HealthWarning(h.sessionStart, status)
+	if warning != "" && warning != h.warning {
+		log.Warn().Str("host", h.Dao.Config.Host).Msg(warning)
+	}
+	h.warning = warning
+}
+
+func (h *Header) hostInfo() string {
+	host := h.Dao.Config.Host
+	if h.Dao.Config.ReadOnly {
+		host += " (read-only)"
+	}
+	return host
+}
+
 // Render renders the header view
 func (h *Header) Render() {
 	h.Table.Clear()
@@ -96,12 +222,16 @@ func (h *Header) Render() {
 		}
 		order := order(i)
 		h.Table.SetCell(currRow, currCol, h.keyCell(base[order].label))
-		h.Table.SetCell(currRow, currCol+1, h.valueCell(base[order].value))
+		value := h.valueCell(base[order].value)
+		if base[order].label == "Warning" || base[order].label == "Alert" {
+			value.SetTextColor(h.style.WarningColor.Color())
+		}
+		h.Table.SetCell(currRow, currCol+1, value)
 		currRow++
 	}
 
-	h.Table.SetCell(0, 2, tview.NewTableCell(" "))
-	h.Table.SetCell(1, 2, tview.NewTableCell(" "))
+	h.Table.SetCell(0, currCol+2, tview.NewTableCell(" "))
+	h.Table.SetCell(1, currCol+2, tview.NewTableCell(" "))
 	currCol++
 
 	k, err := h.UpdateKeys()
@@ -110,6 +240,7 @@ func (h *Header) Render() {
 		return
 	}
 
+	denied := h.deniedKeys()
 	for _, key := range k {
 		if currRow%maxInRow == 0 && currRow != 0 {
 			currCol += 2
@@ -132,30 +263,187 @@ func (h *Header) Render() {
 			}
 		}
 
-		h.Table.SetCell(currRow, currCol, h.keyCell(keyString))
-		h.Table.SetCell(currRow, currCol+1, h.valueCell(key.Description))
+		keyCell, descriptionCell := h.keyCell(keyString), h.valueCell(key.Description)
+		if denied[key.Description] {
+			keyCell.SetTextColor(h.style.DisabledColor.Color())
+			descriptionCell.SetTextColor(h.style.DisabledColor.Color())
+		}
+		h.Table.SetCell(currRow, currCol, keyCell)
+		h.Table.SetCell(currRow, currCol+1, descriptionCell)
 		currRow++
 	}
 }
 
+// deniedKeys returns descriptions of the keys which actions the user lacks
+// privileges for. Actions of the content are checked on the opened
+// collection, others on any database, as the target isn't known yet.
+func (h *Header) deniedKeys() map[string]bool {
+	privileges := h.Dao.Privileges()
+	if privileges == nil || !privileges.Enforced {
+		return nil
+	}
+	var db, coll string
+	if h.namespace != nil {
+		db, coll = h.namespace()
+	}
+
+	k := h.App.GetKeys()
+	actions := []struct {
+		key          config.Key
+		action       string
+		onCollection bool
+	}{
+		{k.Database.AddCollection, mongo.ActionCreateCollection, false},
+		{k.Database.DeleteCollection, mongo.ActionDropCollection, false},
+		{k.Database.Restore, mongo.ActionInsert, false},
+		{k.Content.AddDocument, mongo.ActionInsert, true},
+		{k.Content.DuplicateDocument, mongo.ActionInsert, true},
+		{k.Content.EditDocument, mongo.ActionUpdate, true},
+		{k.Content.DeleteDocument, mongo.ActionRemove, true},
+	}
+
+	denied := make(map[string]bool)
+	for _, a := range actions {
+		allowed := privileges.CanAnywhere(a.action)
+		if a.onCollection && coll != "" {
+			allowed = privileges.Can(a.action, db, coll)
+		}
+		if !allowed {
+			denied[a.key.Description] = true
+		}
+	}
+	return denied
+}
+
 func (h *Header) setInactiveBaseInfo(err error) {
 	h.baseInfo = make(BaseInfo)
 	h.baseInfo[0] = info{"Status", h.style.InactiveSymbol.String()}
+	h.baseInfo[1] = info{"Host", h.hostInfo()}
 	if err != nil {
 		if strings.Contains(strings.ToLower(err.Error()), "unauthorized") {
-			h.baseInfo[1] = info{"Error", "Unauthorized, please check your credentials or your privileges"}
+			h.baseInfo[2] = info{"Error", "Unauthorized, please check your credentials or your privileges"}
 		} else {
-			h.baseInfo[1] = info{"Error", err.Error()}
+			h.baseInfo[2] = info{"Error", err.Error()}
 		}
 	}
 }
 
+// startStatusPolling starts polling the server status for the current dao,
+// previous poller is stopped
+func (h *Header) startStatusPolling() {
+	if h.poller != nil {
+		h.poller.Stop()
+		h.poller = nil
+	}
+	if h.Dao == nil {
+		return
+	}
+
+	cfg := h.App.GetConfig().ServerStatus
+	connection := h.Dao.Config.Name
+	h.poller = mongo.NewStatusPoller(
+		h.Dao,
+		time.Duration(cfg.PollInterval())*time.Second,
+		time.Duration(cfg.MaxInterval)*time.Second,
+		h.statusNeeded,
+		func(status *mongo.ServerStatus, err error) {
+			if err != nil {
+				log.Debug().Err(err).Msg("Error while polling server status")
+			} else {
+				h.recordStatsSample(connection, status, cfg)
+			}
+			go h.App.QueueBackgroundUpdate(func() {
+				h.setStatus(status, err)
+				h.Render()
+			})
+		},
+	)
+	h.poller.Start(h.App.Context())
+}
+
+// recordStatsSample saves the status to the stats history of the connection,
+// if HistoryInterval passed since the last saved sample
+func (h *Header) recordStatsSample(connection string, status *mongo.ServerStatus, cfg config.ServerStatusConfig) {
+	interval := time.Duration(cfg.SampleInterval()) * time.Second
+	now := time.Now()
+	if interval <= 0 || now.Sub(time.Unix(0, h.lastSample.Load())) < interval {
+		return
+	}
+	h.lastSample.Store(now.UnixNano())
+
+	retention := time.Duration(cfg.HistoryRetention) * time.Hour
+	if err := config.AddStatsSample(connection, status.StatsSample(now), retention); err != nil {
+		log.Error().Err(err).Msg("Error saving stats history")
+	}
+}
+
+// startScheduledQueries starts scheduled queries of the current
+// connection, previous scheduler is stopped
+func (h *Header) startScheduledQueries() {
+	if h.scheduler != nil {
+		h.scheduler.Stop()
+		h.scheduler = nil
+	}
+	h.alerts = make(map[string]mongo.ScheduledQueryResult)
+	if h.Dao == nil || len(h.Dao.Config.ScheduledQueries) == 0 {
+		return
+	}
+
+	var scheduler *mongo.QueryScheduler
+	scheduler, err := mongo.NewQueryScheduler(h.Dao, h.Dao.Config.ScheduledQueries, func(result mongo.ScheduledQueryResult) {
+		go h.App.QueueBackgroundUpdate(func() {
+			// results of the previous connection may still be queued
+			if h.scheduler != scheduler {
+				return
+			}
+			h.setScheduledQueryResult(result)
+			h.Render()
+		})
+	})
+	if err != nil {
+		log.Warn().Err(err).Msg("Error parsing scheduled queries")
+	}
+	h.scheduler = scheduler
+	h.scheduler.Start(h.App.Context())
+}
+
+// setScheduledQueryResult keeps the result while the query crosses
+// the threshold, failed runs keep the previous state
+func (h *Header) setScheduledQueryResult(result mongo.ScheduledQueryResult) {
+	if result.Err != nil {
+		return
+	}
+	if result.Alert {
+		h.alerts[result.Name] = result
+	} else {
+		delete(h.alerts, result.Name)
+	}
+}
+
+// statusNeeded reports whether the server status should be polled,
+// it's not needed when the header is hidden or the user is away
+func (h *Header) statusNeeded() bool {
+	idleTimeout := time.Duration(h.App.GetConfig().ServerStatus.IdleTimeout) * time.Second
+	if idleTimeout > 0 && h.App.IdleTime() > idleTimeout {
+		return false
+	}
+	if h.lastDrawn.Load() < h.lastPoll.Load() {
+		return false
+	}
+	h.lastPoll.Store(time.Now().UnixNano())
+	return true
+}
+
 // handle events from the manager
 func (h *Header) handleEvents() {
 	go h.HandleEvents(HeaderComponent, func(event manager.EventMsg) {
 		switch event.Message.Type {
 		case manager.FocusChanged:
-			h.currentFocus = tview.Identifier(event.Message.Data.(tview.Identifier))
+			focus, ok := manager.DataOf[tview.Identifier](event.Message)
+			if !ok {
+				return
+			}
+			h.currentFocus = focus
 			go h.App.QueueUpdateDraw(func() {
 				h.Render()
 			})
@@ -164,8 +452,12 @@ func (h *Header) handleEvents() {
 			go h.App.QueueUpdateDraw(func() {
 				h.Render()
 			})
+		case manager.KeysChanged:
+			go h.App.QueueUpdateDraw(func() {
+				h.Render()
+			})
 		}
-	})
+	}, manager.FocusChanged, manager.StyleChanged, manager.KeysChanged)
 }
 
 func (h *Header) keyCell(text string) *tview.TableCell {
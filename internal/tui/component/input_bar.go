@@ -3,6 +3,8 @@ package component
 import (
 	"regexp"
 	"strings"
+	"time"
+	"unicode/utf8"
 
 	"github.com/atotto/clipboard"
 	"github.com/gdamore/tcell/v2"
@@ -12,9 +14,23 @@ import (
 	"github.com/kopecmaciej/vi-mongo/internal/mongo"
 	"github.com/kopecmaciej/vi-mongo/internal/tui/core"
 	"github.com/kopecmaciej/vi-mongo/internal/tui/modal"
+	"github.com/kopecmaciej/vi-mongo/internal/util"
+	"github.com/rivo/uniseg"
 	"github.com/rs/zerolog/log"
 )
 
+// pasteKeyInterval is the time between key events below which
+// they are treated as pasted text, as nobody types that fast
+const pasteKeyInterval = 5 * time.Millisecond
+
+// recentQueriesLimit is the number of recent queries that can be
+// recalled with Up and Down keys
+const recentQueriesLimit = 5
+
+// cursorMarkerRegex matches the place where cursor is put after the text
+// is inserted, e.g. "{ <$0> }"
+var cursorMarkerRegex = regexp.MustCompile(`<\$[0-9]+>`)
+
 type InputBar struct {
 	*core.BaseElement
 	*core.InputField
@@ -23,8 +39,21 @@ type InputBar struct {
 	style          *config.InputBarStyle
 	enabled        bool
 	autocompleteOn bool
-	docKeys        []string
-	defaultText    string
+	// closingAutocomplete disables suggestions while the list is closed
+	closingAutocomplete bool
+	snippetsOn          bool
+	formatOn            bool
+	docKeys             []string
+	fieldValues         map[string][]string
+	defaultText         string
+	namespace           string
+	lastKeyAt           time.Time
+	// recentQueries are browsed with Up and Down keys, recentIndex
+	// is -1 when they are not browsed
+	recentQueries []string
+	recentIndex   int
+	// recentOrigin is the text restored when browsing goes past the newest query
+	recentOrigin string
 }
 
 func NewInputBar(barId tview.Identifier, label string) *InputBar {
@@ -33,6 +62,7 @@ func NewInputBar(barId tview.Identifier, label string) *InputBar {
 		InputField:     core.NewInputField(),
 		enabled:        false,
 		autocompleteOn: false,
+		recentIndex:    -1,
 	}
 
 	i.InputField.SetLabel(" " + label + ": ")
@@ -60,7 +90,7 @@ func (i *InputBar) init() error {
 			log.Error().Err(err).Msg("Error reading from clipboard")
 			return ""
 		}
-		return strings.TrimSpace(text)
+		return util.NormalizePastedJson(text)
 	}
 	i.SetClipboard(cpFunc, pasteFunc)
 
@@ -97,21 +127,61 @@ func (i *InputBar) setStyle() {
 
 func (i *InputBar) setKeybindings() {
 	i.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		// terminals without bracketed paste send pasted text as key events,
+		// so new lines must not submit the query and brackets are not completed
+		pasting := event.When().Sub(i.lastKeyAt) < pasteKeyInterval
+		i.lastKeyAt = event.When()
+		if pasting {
+			if event.Key() == tcell.KeyEnter || event.Key() == tcell.KeyTab {
+				return tcell.NewEventKey(tcell.KeyRune, ' ', tcell.ModNone)
+			}
+			return event
+		}
+
 		k := i.App.GetKeys()
+		if i.historyModal != nil {
+			switch {
+			case k.Contains(k.QueryBar.PreviousQuery, event.Name()):
+				if i.browseRecentQueries(true) {
+					return nil
+				}
+			case k.Contains(k.QueryBar.NextQuery, event.Name()):
+				if i.browseRecentQueries(false) {
+					return nil
+				}
+			case i.recentIndex >= 0:
+				i.stopBrowsingRecentQueries()
+				// escape only closes the list of recent queries
+				if event.Key() == tcell.KeyEscape {
+					return nil
+				}
+			}
+		}
+
 		switch event.Rune() {
 		case '{':
 			if i.GetWordAtCursor() == "" {
-				i.SetWordAtCursor("{ <$0> }")
+				i.setWordAtCursor("{ <$0> }")
 				return nil
 			}
 		case '[':
 			if i.GetWordAtCursor() == "" {
-				i.SetWordAtCursor("[ <$0> ]")
+				i.setWordAtCursor("[ <$0> ]")
 				return nil
 			}
 		}
 
 		switch {
+		case i.snippetsOn && k.Contains(k.QueryBar.ExpandSnippet, event.Name()):
+			if i.expandSnippet() || i.nextPlaceholder() {
+				return nil
+			}
+		case i.formatOn && k.Contains(k.QueryBar.FormatQuery, event.Name()):
+			i.SetText(util.FormatJson(i.GetText()))
+			return nil
+		case i.formatOn && k.Contains(k.QueryBar.MinifyQuery, event.Name()):
+			i.SetText(util.MinifyJson(i.GetText()))
+			return nil
 		case k.Contains(k.QueryBar.ShowHistory, event.Name()):
 			if i.historyModal != nil {
 				i.historyModal.Render()
@@ -136,7 +206,7 @@ func (i *InputBar) handleEvents() {
 		case i.historyModal != nil && sender == i.historyModal.GetIdentifier():
 			i.handleHistoryModalEvent(event.EventKey)
 		}
-	})
+	}, manager.StyleChanged)
 }
 
 // SetDefaultText sets default text for the input bar
@@ -156,10 +226,11 @@ func (i *InputBar) DoneFuncHandler(accept func(string), reject func()) {
 		case tcell.KeyEnter:
 			log.Debug().Msg("Enter key pressed")
 			i.Toggle("")
-			text := i.GetText()
+			// placeholders of snippets that were not visited are removed
+			text := cursorMarkerRegex.ReplaceAllString(i.GetText(), "")
 			log.Debug().Msgf("Saving query to history: %s", text)
 			if i.historyModal != nil {
-				err := i.historyModal.SaveToHistory(text)
+				err := i.historyModal.SaveToHistory(text, i.namespace)
 				if err != nil {
 					log.Error().Err(err).Msg("Error saving query to history")
 				}
@@ -169,6 +240,12 @@ func (i *InputBar) DoneFuncHandler(accept func(string), reject func()) {
 	})
 }
 
+// SetNamespace sets the namespace (db.collection) that is saved
+// together with queries in the history
+func (i *InputBar) SetNamespace(namespace string) {
+	i.namespace = namespace
+}
+
 // EnableHistory enables history modal
 func (i *InputBar) EnableHistory() {
 	i.historyModal = modal.NewHistoryModal()
@@ -178,12 +255,141 @@ func (i *InputBar) EnableHistory() {
 	}
 }
 
+// browseRecentQueries replaces the text with older or newer query from
+// the history, like in the shell, and lists recent queries under the bar.
+// Browsing starts only if nothing was typed yet. It returns true if the key
+// was handled.
+func (i *InputBar) browseRecentQueries(older bool) bool {
+	if i.recentIndex < 0 {
+		text := cursorMarkerRegex.ReplaceAllString(i.GetText(), "")
+		if !older || !util.IsJsonEmpty(text) {
+			return false
+		}
+		entries, err := config.LoadHistory()
+		if err != nil {
+			log.Error().Err(err).Msg("Error loading history")
+			return false
+		}
+		i.recentQueries = config.RecentQueries(entries, i.namespace, recentQueriesLimit)
+		if len(i.recentQueries) == 0 {
+			return false
+		}
+		i.recentOrigin = i.GetText()
+	} else if i.GetText() != i.recentQueries[i.recentIndex] {
+		// recalled query was changed, e.g. by pasting
+		i.stopBrowsingRecentQueries()
+		return false
+	}
+
+	switch {
+	case older && i.recentIndex < len(i.recentQueries)-1:
+		i.recentIndex++
+	case !older && i.recentIndex == 0:
+		i.stopBrowsingRecentQueries()
+		i.SetText(i.recentOrigin)
+		return true
+	case !older:
+		i.recentIndex--
+	}
+
+	i.SetText(i.recentQueries[i.recentIndex])
+	i.Autocomplete()
+	return true
+}
+
+func (i *InputBar) stopBrowsingRecentQueries() {
+	i.recentIndex = -1
+	i.closeAutocomplete()
+}
+
+// recentQueryEntries returns recent queries as autocomplete entries,
+// so they are listed under the bar
+func (i *InputBar) recentQueryEntries() []tview.AutocompleteItem {
+	entries := make([]tview.AutocompleteItem, 0, len(i.recentQueries))
+	for _, query := range i.recentQueries {
+		entries = append(entries, tview.AutocompleteItem{Main: tview.Escape(query)})
+	}
+	return entries
+}
+
+// EnableFormatting enables keys that format and minify the query
+func (i *InputBar) EnableFormatting() {
+	i.formatOn = true
+}
+
+// EnableSnippets enables expanding snippets defined in the config
+func (i *InputBar) EnableSnippets() {
+	i.snippetsOn = true
+}
+
+// expandSnippet replaces the word at cursor with the body of the snippet,
+// if the word is a snippet trigger
+func (i *InputBar) expandSnippet() bool {
+	word := i.GetWordAtCursor()
+	// trigger can be typed right after the bracket, e.g. "{oid"
+	if index := strings.LastIndexAny(word, "{["); index >= 0 {
+		word = word[index+1:]
+	}
+	snippet, ok := i.App.GetConfig().GetSnippet(word)
+	if !ok {
+		return false
+	}
+
+	i.setWordAtCursor(snippet.Body)
+	i.closeAutocomplete()
+	return true
+}
+
+// nextPlaceholder moves the cursor to the first placeholder left in the text
+// and removes it
+func (i *InputBar) nextPlaceholder() bool {
+	text := i.GetText()
+	marker := cursorMarkerRegex.FindStringIndex(text)
+	if marker == nil {
+		return false
+	}
+
+	i.SetText(text[:marker[0]] + text[marker[1]:])
+	i.closeAutocomplete()
+	i.moveCursorBeforeEnd(uniseg.GraphemeClusterCount(text[marker[1]:]))
+	return true
+}
+
+// closeAutocomplete closes autocomplete list, which may be left open
+// when the text is changed outside of the input handler
+func (i *InputBar) closeAutocomplete() {
+	i.closingAutocomplete = true
+	i.Autocomplete()
+	i.closingAutocomplete = false
+}
+
+// moveCursorBeforeEnd moves the cursor to the given number of characters
+// before the end of the text. Input handler can't be called directly,
+// as setWordAtCursor is also called by the input field while it handles
+// the key, so the move is queued
+func (i *InputBar) moveCursorBeforeEnd(chars int) {
+	go i.App.QueueUpdateDraw(func() {
+		handler := i.InputHandler()
+		setFocus := func(p tview.Primitive) {}
+		handler(tcell.NewEventKey(tcell.KeyEnd, 0, tcell.ModNone), setFocus)
+		for j := 0; j < chars; j++ {
+			handler(tcell.NewEventKey(tcell.KeyLeft, 0, tcell.ModNone), setFocus)
+		}
+	})
+}
+
 // EnableAutocomplete enables autocomplete
 func (i *InputBar) EnableAutocomplete() {
 	ma := mongo.NewMongoAutocomplete()
 	mongoKeywords := ma.Operators
 
 	i.SetAutocompleteFunc(func(currentText string) (entries []tview.AutocompleteItem) {
+		if i.closingAutocomplete {
+			return nil
+		}
+		if i.recentIndex >= 0 {
+			return i.recentQueryEntries()
+		}
 		currentText = strings.TrimPrefix(currentText, "\"")
 
 		words := strings.Fields(currentText)
@@ -198,6 +404,20 @@ func (i *InputBar) EnableAutocomplete() {
 				return nil
 			}
 
+			// support for values of enum-like fields
+			if field, ok := fieldAtValue(i.GetText(), i.GetWordAtCursor()); ok {
+				for _, value := range i.fieldValues[field] {
+					// brackets would be treated as color tags by the list
+					if strings.Contains(value, "[") {
+						continue
+					}
+					if strings.HasPrefix(strings.ToLower(value), strings.ToLower(currentWord)) ||
+						strings.HasPrefix(strings.ToLower(strings.Trim(value, `"`)), strings.ToLower(currentWord)) {
+						entries = append(entries, tview.AutocompleteItem{Main: value, Secondary: "value of " + field})
+					}
+				}
+			}
+
 			// support for mongo keywords
 			for _, keyword := range mongoKeywords {
 				escaped := regexp.QuoteMeta(currentWord)
@@ -210,7 +430,7 @@ func (i *InputBar) EnableAutocomplete() {
 			// support for document keys
 			if i.docKeys != nil {
 				for _, keyword := range i.docKeys {
-					if matched, _ := regexp.MatchString("(?i)^"+currentWord, keyword); matched {
+					if matched, _ := regexp.MatchString("(?i)^"+regexp.QuoteMeta(currentWord), keyword); matched {
 						entries = append(entries, tview.AutocompleteItem{Main: keyword})
 					}
 				}
@@ -228,14 +448,76 @@ func (i *InputBar) EnableAutocomplete() {
 		key := ma.GetOperatorByDisplay(text)
 		if key != nil {
 			text = key.InsertText
+			// operator used as field value is wrapped in braces,
+			// unless they were already typed
+			word := i.GetWordAtCursor()
+			_, isValue := fieldAtValue(i.GetText(), word)
+			if isValue && strings.HasPrefix(key.Display, "$") && !strings.HasPrefix(word, "{") && !strings.HasPrefix(word, "[") {
+				text = key.Skeleton()
+			}
 		}
 
-		i.SetWordAtCursor(text)
+		i.setWordAtCursor(text)
 
 		return true
 	})
 }
 
+// setWordAtCursor sets the word under the cursor. tview places the cursor
+// at <$0> marker by counting bytes, which puts it too far to the right if
+// the text has multi-byte characters (e.g. CJK), so when the word was
+// inserted at the end of the text the cursor is moved back by characters
+func (i *InputBar) setWordAtCursor(word string) {
+	i.SetWordAtCursor(word)
+
+	marker := cursorMarkerRegex.FindStringIndex(word)
+	text := i.GetText()
+	if marker == nil || utf8.RuneCountInString(text) == len(text) {
+		return
+	}
+	if !strings.HasSuffix(text, word[:marker[0]]+word[marker[1]:]) {
+		return
+	}
+
+	i.moveCursorBeforeEnd(uniseg.GraphemeClusterCount(word[marker[1]:]))
+}
+
+// LoadFieldValues loads values of enum-like fields for autocomplete
+func (i *InputBar) LoadFieldValues(values map[string][]string) {
+	i.fieldValues = values
+}
+
+// fieldAtValue returns the field which value is typed, e.g. "status" for
+// `{ status: "act`. As tview doesn't expose the cursor position,
+// last occurrence of the word at cursor is used
+func fieldAtValue(text, word string) (string, bool) {
+	index := strings.LastIndex(text, word)
+	if word == "" || index < 0 {
+		return "", false
+	}
+	before, found := strings.CutSuffix(strings.TrimRight(text[:index], " "), ":")
+	if !found {
+		return "", false
+	}
+	fields := strings.FieldsFunc(before, func(r rune) bool {
+		return r == '{' || r == ',' || r == ' '
+	})
+	if len(fields) == 0 {
+		return "", false
+	}
+
+	return strings.Trim(fields[len(fields)-1], `"'`), true
+}
+
+// PasteHandler handles bracketed paste, pasted multi-line JSON
+// is inserted as a single line
+func (i *InputBar) PasteHandler() func(pastedText string, setFocus func(p tview.Primitive)) {
+	handler := i.InputField.PasteHandler()
+	return func(pastedText string, setFocus func(p tview.Primitive)) {
+		handler(util.NormalizePastedJson(pastedText), setFocus)
+	}
+}
+
 // LoadNewKeys loads new keys for autocomplete
 // It is used when switching databases or collections
 func (i *InputBar) LoadNewKeys(keys []string) {
@@ -3,6 +3,7 @@ package component
 import (
 	"context"
 
+	"github.com/kopecmaciej/vi-mongo/internal/config"
 	"github.com/kopecmaciej/vi-mongo/internal/manager"
 	"github.com/kopecmaciej/vi-mongo/internal/mongo"
 	"github.com/kopecmaciej/vi-mongo/internal/tui/core"
@@ -12,6 +13,7 @@ import (
 	"github.com/atotto/clipboard"
 	"github.com/gdamore/tcell/v2"
 	"github.com/kopecmaciej/tview"
+	"github.com/rs/zerolog/log"
 )
 
 const (
@@ -25,8 +27,6 @@ type Peeker struct {
 
 	docModifier *DocModifier
 	currentDoc  string
-
-	doneFunc func()
 }
 
 // NewPeeker creates a new Peeker view
@@ -57,21 +57,27 @@ func (p *Peeker) init() error {
 	return nil
 }
 
+// UpdateDao updates the dao of the peeker and its document modifier
+func (p *Peeker) UpdateDao(dao *mongo.Dao) {
+	p.BaseElement.UpdateDao(dao)
+	p.docModifier.UpdateDao(dao)
+}
+
 func (p *Peeker) handleEvents() {
 	go p.HandleEvents(PeekerComponent, func(event manager.EventMsg) {
 		switch event.Message.Type {
 		case manager.StyleChanged:
 			p.setStyle()
 		}
-	})
+	}, manager.StyleChanged)
 }
 
 func (p *Peeker) setStaticLayout() {
 	p.SetBorder(true)
-	p.SetTitle("Document Details")
+	p.SetTitle(config.Msg("peeker.title"))
 	p.SetTitleAlign(tview.AlignLeft)
 
-	p.ViewModal.AddButtons([]string{"Edit", "Close"})
+	p.ViewModal.AddButtons([]string{config.Msg("button.edit"), config.Msg("button.close")})
 }
 
 func (p *Peeker) setStyle() {
@@ -106,7 +112,7 @@ func (p *Peeker) setKeybindings() {
 			}
 			return nil
 		case k.Contains(k.Peeker.Refresh, event.Name()):
-			p.setText()
+			p.setText(p.App.Context())
 			return nil
 		}
 		return event
@@ -121,10 +127,6 @@ func (p *Peeker) MoveToBottom() {
 	p.ViewModal.MoveToBottom()
 }
 
-func (p *Peeker) SetDoneFunc(doneFunc func()) {
-	p.doneFunc = doneFunc
-}
-
 func (p *Peeker) Render(ctx context.Context, state *mongo.CollectionState, _id interface{}) error {
 	p.MoveToTop()
 	doc, err := state.GetJsonDocById(_id)
@@ -133,36 +135,70 @@ func (p *Peeker) Render(ctx context.Context, state *mongo.CollectionState, _id i
 	}
 
 	p.currentDoc = doc
-	p.setText()
+	p.setText(ctx)
 
 	p.App.Pages.AddPage(p.GetIdentifier(), p.ViewModal, true, true)
 	p.ViewModal.SetDoneFunc(func(buttonIndex int, buttonLabel string) {
-		if buttonLabel == "Edit" {
+		if buttonLabel == config.Msg("button.edit") {
 			updatedDoc, err := p.docModifier.Edit(ctx, state.Db, state.Coll, _id, p.currentDoc)
 			if err != nil {
 				modal.ShowError(p.App.Pages, "Error editing document", err)
 				return
 			}
 
+			// content is updated by the document updated event
 			if updatedDoc != "" {
-				state.UpdateRawDoc(updatedDoc)
 				p.currentDoc = updatedDoc
-				if p.doneFunc != nil {
-					p.doneFunc()
-				}
-				p.setText()
+				p.setText(ctx)
 			}
-		} else if buttonLabel == "Close" || buttonLabel == "" {
+		} else if buttonLabel == config.Msg("button.close") || buttonLabel == "" {
 			p.App.Pages.RemovePage(p.GetIdentifier())
 		}
 	})
 	return nil
 }
 
-func (p *Peeker) setText() {
+// setText shows the current document with sensitive fields masked and
+// values formatted, the document itself is kept as it is for editing
+func (p *Peeker) setText(ctx context.Context) {
+	doc, err := p.displayedDoc(ctx)
+	if err != nil {
+		log.Error().Err(err).Msg("Error masking document")
+		doc = ""
+	}
+	doc = p.App.GetConfig().Display.ValueFormat().JSON(doc)
 	p.ViewModal.SetText(primitives.Text{
-		Content: p.currentDoc,
+		Content: doc,
 		Color:   p.App.GetStyles().DocPeeker.ValueColor.Color(),
 		Align:   tview.AlignLeft,
 	})
 }
+
+// displayedDoc returns the current document as it should be shown, encrypted
+// fields are decrypted if the key vault is configured, otherwise they are
+// shown as placeholders, same as if decryption fails
+func (p *Peeker) displayedDoc(ctx context.Context) (string, error) {
+	masker := mongo.NewMasker(p.Dao.Config.Masking)
+	if p.Dao.Config.KeyVault == nil {
+		return masker.MaskJson(p.currentDoc)
+	}
+
+	doc, err := mongo.ParseJsonToBson(p.currentDoc)
+	if err != nil {
+		return "", err
+	}
+	decrypted, err := p.Dao.DecryptDocument(ctx, doc)
+	if err != nil {
+		log.Error().Err(err).Msg("Error decrypting document")
+		decrypted = doc
+	}
+	jsoned, err := mongo.ParseBsonDocument(masker.MaskDocument(decrypted))
+	if err != nil {
+		return "", err
+	}
+	indented, err := mongo.IndentJson(jsoned)
+	if err != nil {
+		return "", err
+	}
+	return indented.String(), nil
+}
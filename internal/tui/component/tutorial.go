@@ -0,0 +1,139 @@
+package component
+
+import (
+	"fmt"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/kopecmaciej/tview"
+	"github.com/kopecmaciej/vi-mongo/internal/manager"
+	"github.com/kopecmaciej/vi-mongo/internal/tui/core"
+)
+
+const (
+	TutorialComponent = "Tutorial"
+)
+
+// TutorialStep is a step of the guided tour, Target is the element
+// highlighted during the step. Done is checked after every key press
+// with the key and the element focused before it was handled.
+type TutorialStep struct {
+	Text   string
+	Target tview.Identifier
+	Done   func(event *tcell.EventKey, focused tview.Identifier) bool
+}
+
+// Tutorial shows steps of the guided tour one by one,
+// the next one is shown when the current one is performed
+type Tutorial struct {
+	*core.BaseElement
+	*core.TextView
+
+	steps       []TutorialStep
+	step        int
+	onHighlight func(target tview.Identifier, on bool)
+	onFinish    func()
+}
+
+func NewTutorial() *Tutorial {
+	t := &Tutorial{
+		BaseElement: core.NewBaseElement(),
+		TextView:    core.NewTextView(),
+	}
+
+	t.SetIdentifier(TutorialComponent)
+	t.SetAfterInitFunc(t.init)
+
+	return t
+}
+
+func (t *Tutorial) init() error {
+	t.SetBorder(true)
+	t.SetTitleAlign(tview.AlignLeft)
+	t.SetDynamicColors(true)
+	t.SetWordWrap(true)
+	t.setStyle()
+
+	t.handleEvents()
+
+	return nil
+}
+
+func (t *Tutorial) setStyle() {
+	styles := t.App.GetStyles()
+	t.TextView.SetStyle(styles)
+	t.SetBorderColor(styles.Global.FocusColor.Color())
+}
+
+func (t *Tutorial) handleEvents() {
+	go t.HandleEvents(TutorialComponent, func(event manager.EventMsg) {
+		switch event.Message.Type {
+		case manager.StyleChanged:
+			t.App.QueueUpdateDraw(func() {
+				t.setStyle()
+			})
+		}
+	}, manager.StyleChanged)
+}
+
+// SetHighlightFunc sets the function highlighting the target of the step
+func (t *Tutorial) SetHighlightFunc(onHighlight func(target tview.Identifier, on bool)) {
+	t.onHighlight = onHighlight
+}
+
+// SetFinishFunc sets the function called when the tour is finished or stopped
+func (t *Tutorial) SetFinishFunc(onFinish func()) {
+	t.onFinish = onFinish
+}
+
+// IsRunning returns true if the tour is in progress
+func (t *Tutorial) IsRunning() bool {
+	return t.step < len(t.steps)
+}
+
+// Start starts the tour from its first step
+func (t *Tutorial) Start(steps []TutorialStep) {
+	t.Stop()
+	t.steps = steps
+	t.step = 0
+	t.render()
+}
+
+// Stop stops the tour and removes the highlight
+func (t *Tutorial) Stop() {
+	if !t.IsRunning() {
+		return
+	}
+	t.highlight(false)
+	t.steps = nil
+	t.step = 0
+	if t.onFinish != nil {
+		t.onFinish()
+	}
+}
+
+// HandleKey moves to the next step if the key performed the current one,
+// it has to be called after the key is handled
+func (t *Tutorial) HandleKey(event *tcell.EventKey, focused tview.Identifier) {
+	if !t.IsRunning() || !t.steps[t.step].Done(event, focused) {
+		return
+	}
+	if t.step == len(t.steps)-1 {
+		t.Stop()
+		return
+	}
+	t.highlight(false)
+	t.step++
+	t.render()
+}
+
+func (t *Tutorial) render() {
+	t.SetTitle(fmt.Sprintf(" Tour %d/%d (%s - stop) ", t.step+1, len(t.steps), t.App.GetKeys().Main.ToggleTutorial.String()))
+	t.SetText(t.steps[t.step].Text)
+	t.highlight(true)
+}
+
+func (t *Tutorial) highlight(on bool) {
+	if t.onHighlight != nil && t.steps[t.step].Target != "" {
+		t.onHighlight(t.steps[t.step].Target, on)
+	}
+}
@@ -1,13 +1,24 @@
 package core
 
 import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
 	"github.com/kopecmaciej/tview"
 	"github.com/kopecmaciej/vi-mongo/internal/config"
 	"github.com/kopecmaciej/vi-mongo/internal/manager"
 	"github.com/kopecmaciej/vi-mongo/internal/mongo"
+	"github.com/kopecmaciej/vi-mongo/internal/util"
 	"github.com/rs/zerolog/log"
 )
 
+// shutdownTimeout limits how long closing the connection can take
+const shutdownTimeout = 5 * time.Second
+
 type (
 	// App is a main application struct
 	App struct {
@@ -16,32 +27,70 @@ type (
 		Pages         *Pages
 		dao           *mongo.Dao
 		manager       *manager.ElementManager
+		jobs          *manager.JobManager
 		styles        *config.Styles
 		config        *config.Config
 		keys          *config.KeyBindings
 		previousFocus tview.Primitive
+		// lastInput is the time of the last key press in unix nanoseconds
+		lastInput atomic.Int64
+		// ctx is cancelled on shutdown, background goroutines stop with it
+		ctx    context.Context
+		cancel context.CancelFunc
+		// redraw limits draws of background updates in reduced motion mode
+		redraw *redrawThrottle
+		// screen is the screen of the last draw, it's captured as text
+		screen tcell.Screen
 	}
 )
 
 func NewApp(appConfig *config.Config) *App {
-	styles, err := config.LoadStyles(appConfig.Styles.CurrentStyle, appConfig.Styles.BetterSymbols)
+	// styles and keybindings are separate files,
+	// so they are read at the same time
+	var (
+		wg          sync.WaitGroup
+		keyBindings *config.KeyBindings
+		keysErr     error
+	)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		keyBindings, keysErr = config.LoadKeybindings()
+	}()
+
+	styles, err := loadStyles(appConfig)
 	if err != nil {
 		log.Fatal().Err(err).Msg("Failed to load styles")
 	}
-	styles.LoadMainStyles()
-	keyBindings, err := config.LoadKeybindings()
-	if err != nil {
-		log.Fatal().Err(err).Msg("Failed to load keybindings")
+
+	wg.Wait()
+	if keysErr != nil {
+		log.Fatal().Err(keysErr).Msg("Failed to load keybindings")
+	}
+	keyBindings.Translate(loadMessages(appConfig))
+	for _, warning := range util.ConfigWarnings() {
+		log.Warn().Msg(warning.Error())
 	}
 
+	ctx, cancel := context.WithCancel(context.Background())
 	app := &App{
+		ctx:         ctx,
+		cancel:      cancel,
 		Application: tview.NewApplication(),
 		manager:     manager.NewElementManager(),
+		jobs:        manager.NewJobManager(),
 		styles:      styles,
 		config:      appConfig,
 		keys:        keyBindings,
+		redraw: &redrawThrottle{
+			interval: time.Duration(appConfig.Styles.RedrawInterval) * time.Millisecond,
+		},
 	}
 
+	app.MarkActive()
+	app.SetAfterDrawFunc(func(screen tcell.Screen) {
+		app.screen = screen
+	})
 	app.Pages = NewPages(app.manager, app)
 	app.Pages.SetStyle(styles)
 
@@ -55,11 +104,16 @@ func (a *App) SetStyle(styleName string) error {
 		return err
 	}
 
-	a.styles, err = config.LoadStyles(a.config.Styles.CurrentStyle, a.config.Styles.BetterSymbols)
+	return a.reloadStyles()
+}
+
+// reloadStyles loads styles of the config and lets elements know about them
+func (a *App) reloadStyles() error {
+	styles, err := loadStyles(a.config)
 	if err != nil {
 		return err
 	}
-	a.styles.LoadMainStyles()
+	a.styles = styles
 	a.Pages.SetStyle(a.styles)
 	a.manager.Broadcast(manager.EventMsg{
 		Message: manager.Message{
@@ -70,6 +124,151 @@ func (a *App) SetStyle(styleName string) error {
 	return nil
 }
 
+// SwitchProfile loads config, keybindings and styles of the profile,
+// the config is replaced in place, so every element sees the new one.
+// The previous profile is kept if the new one can't be loaded.
+// It has to be called from the main goroutine, e.g. with QueueUpdateDraw.
+func (a *App) SwitchProfile(name string) error {
+	previous := util.GetProfile()
+	if err := util.SetProfile(name); err != nil {
+		return err
+	}
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		util.SetProfile(previous)
+		return err
+	}
+	if err := cfg.MigratePasswords(); err != nil {
+		log.Error().Err(err).Msg("Error moving passwords out of the config file")
+	}
+	cfg.KeepFlags(a.config)
+	*a.config = *cfg
+
+	if err := a.ReloadKeys(); err != nil {
+		return err
+	}
+	return a.reloadStyles()
+}
+
+// loadStyles loads the current style and applies it to tview primitives,
+// in accessible mode its colors are replaced with high contrast ones
+func loadStyles(appConfig *config.Config) (*config.Styles, error) {
+	styles, err := config.LoadStyles(appConfig.Styles.CurrentStyle, appConfig.Styles.BetterSymbols)
+	if err != nil {
+		return nil, err
+	}
+	if appConfig.IsAccessible() {
+		styles.ApplyHighContrast()
+	}
+	styles.LoadMainStyles()
+	return styles, nil
+}
+
+// loadMessages loads the catalog of the configured locale and sets it
+// as the current one, english texts are used if it can't be loaded
+func loadMessages(appConfig *config.Config) config.Messages {
+	messages, err := config.LoadMessages(appConfig.Locale)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to load locale, using english texts")
+		messages, _ = config.LoadMessages(config.DefaultLocale)
+	}
+	config.SetMessages(messages)
+	return messages
+}
+
+// IsAccessible returns true if the accessible mode is on
+func (a *App) IsAccessible() bool {
+	return a.config.IsAccessible()
+}
+
+// IsReducedMotion returns true if redraws should be kept to a minimum
+func (a *App) IsReducedMotion() bool {
+	return a.config.IsReducedMotion()
+}
+
+// QueueBackgroundUpdate applies the update made by a background goroutine,
+// e.g. a poll or a stream of events, and draws the screen. In reduced
+// motion mode draws of such updates are done at most once every redraw
+// interval, as every draw is sent to the terminal, e.g. over SSH.
+func (a *App) QueueBackgroundUpdate(f func()) {
+	if !a.IsReducedMotion() {
+		a.QueueUpdateDraw(f)
+		return
+	}
+	a.QueueUpdate(f)
+	a.redraw.schedule(func() {
+		a.QueueUpdateDraw(func() {})
+	})
+}
+
+// ReloadKeys loads keybindings from the file, together with overrides
+// of the current connection and descriptions from the locale, and applies
+// them in place, so every element that holds the keys sees the new bindings.
+// It has to be called from the main goroutine, e.g. with QueueUpdateDraw.
+func (a *App) ReloadKeys() error {
+	keyBindings, err := config.LoadKeybindings()
+	if err != nil {
+		return err
+	}
+	keyBindings.Translate(loadMessages(a.config))
+	if a.dao != nil && a.dao.Config != nil {
+		if err := keyBindings.ApplyOverrides(a.dao.Config.Keybindings); err != nil {
+			return err
+		}
+	}
+	*a.keys = *keyBindings
+
+	a.manager.Broadcast(manager.EventMsg{
+		Message: manager.Message{
+			Type: manager.KeysChanged,
+		},
+	})
+
+	return nil
+}
+
+// ScreenText returns the screen as it was last drawn as plain text
+func (a *App) ScreenText() (string, error) {
+	if a.screen == nil {
+		return "", fmt.Errorf("screen is not drawn yet")
+	}
+	return util.ScreenText(a.screen), nil
+}
+
+// Context returns the context of the app lifecycle,
+// it's cancelled when the app is shut down
+func (a *App) Context() context.Context {
+	return a.ctx
+}
+
+// Shutdown stops background goroutines and event listeners and closes
+// the connection to the database. It's called after the app stopped,
+// as queued UI updates are not processed anymore.
+func (a *App) Shutdown() {
+	a.cancel()
+	a.manager.Close()
+
+	if a.dao != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		if err := a.dao.ForceClose(ctx); err != nil {
+			log.Error().Err(err).Msg("Error while closing the connection")
+		}
+	}
+	log.Info().Msg("App shut down")
+}
+
+// MarkActive records that the user interacted with the app
+func (a *App) MarkActive() {
+	a.lastInput.Store(time.Now().UnixNano())
+}
+
+// IdleTime returns how long the app is waiting for the user input,
+// it's safe to call from any goroutine
+func (a *App) IdleTime() time.Duration {
+	return time.Since(time.Unix(0, a.lastInput.Load()))
+}
+
 func (a *App) SetPreviousFocus() {
 	a.previousFocus = a.GetFocus()
 }
@@ -103,14 +302,27 @@ func (a *App) GetDao() *mongo.Dao {
 	return a.dao
 }
 
+// SetDao sets the dao and notifies elements that the connection changed
 func (a *App) SetDao(dao *mongo.Dao) {
 	a.dao = dao
+	a.manager.Broadcast(manager.EventMsg{
+		Message: manager.Message{
+			Type: manager.ConnectionChanged,
+			Data: manager.ConnectionChangedData{Name: dao.Config.Name},
+		},
+	})
 }
 
 func (a *App) GetManager() *manager.ElementManager {
 	return a.manager
 }
 
+// GetJobs returns the manager of background jobs, jobs should be
+// started with the context of the app, so they stop on shutdown
+func (a *App) GetJobs() *manager.JobManager {
+	return a.jobs
+}
+
 func (a *App) GetKeys() *config.KeyBindings {
 	return a.keys
 }
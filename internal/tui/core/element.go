@@ -106,15 +106,17 @@ func (c *BaseElement) SetAfterInitFunc(afterInitFunc func() error) {
 	c.afterInitFunc = afterInitFunc
 }
 
-// Subscribe subscribes to the view events.
-func (c *BaseElement) Subscribe(identifier tview.Identifier) {
-	c.Listener = c.App.GetManager().Subscribe(identifier)
+// Subscribe subscribes to the view events, if types are given
+// only broadcasted messages of those types are received.
+func (c *BaseElement) Subscribe(identifier tview.Identifier, types ...manager.MessageType) {
+	c.Listener = c.App.GetManager().Subscribe(identifier, types...)
 }
 
-// HandleEvents handles events from the manager
-func (c *BaseElement) HandleEvents(identifier tview.Identifier, handler func(event manager.EventMsg)) {
+// HandleEvents handles events from the manager, if types are given
+// only broadcasted messages of those types are handled
+func (c *BaseElement) HandleEvents(identifier tview.Identifier, handler func(event manager.EventMsg), types ...manager.MessageType) {
 	if c.Listener == nil {
-		c.Listener = c.App.GetManager().Subscribe(identifier)
+		c.Listener = c.App.GetManager().Subscribe(identifier, types...)
 	}
 	for event := range c.Listener {
 		handler(event)
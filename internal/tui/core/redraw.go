@@ -0,0 +1,34 @@
+package core
+
+import (
+	"sync"
+	"time"
+)
+
+// redrawThrottle batches draws of updates made in the background,
+// the screen is drawn at most once every interval
+type redrawThrottle struct {
+	mu       sync.Mutex
+	interval time.Duration
+	pending  bool
+	lastDraw time.Time
+}
+
+// schedule calls draw once the interval since the last draw passes,
+// draws requested meanwhile are done together with the scheduled one
+func (t *redrawThrottle) schedule(draw func()) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.pending {
+		return
+	}
+	t.pending = true
+	wait := t.interval - time.Since(t.lastDraw)
+	time.AfterFunc(max(wait, 0), func() {
+		t.mu.Lock()
+		t.pending = false
+		t.lastDraw = time.Now()
+		t.mu.Unlock()
+		draw()
+	})
+}
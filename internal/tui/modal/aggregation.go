@@ -0,0 +1,418 @@
+package modal
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/kopecmaciej/tview"
+	"github.com/kopecmaciej/vi-mongo/internal/config"
+	"github.com/kopecmaciej/vi-mongo/internal/mongo"
+	"github.com/kopecmaciej/vi-mongo/internal/tui/core"
+	"github.com/kopecmaciej/vi-mongo/internal/tui/primitives"
+)
+
+const (
+	AggregationModal        = "Aggregation"
+	AggregationPromptModal  = "AggregationPrompt"
+	AggregationConfirmModal = "AggregationConfirm"
+
+	overwriteButton = "button.replace"
+)
+
+// Aggregation is the editor of the aggregation pipeline run on the current
+// collection. The pipeline is written in the shell syntax, its results
+// are listed in the content instead of found documents, or saved into
+// another collection.
+type Aggregation struct {
+	*core.BaseElement
+	*core.Flex
+
+	frame     *core.Flex
+	editor    *tview.TextArea
+	info      *core.TextView
+	stages    *StagePicker
+	prompt    *primitives.InputModal
+	confirm   *core.Modal
+	namespace string
+	onRun     func(pipeline string)
+	onExplain func(pipeline mongo.Pipeline) ([]mongo.StageStats, error)
+	onLookup  func()
+	hasColl   func(name string) (bool, error)
+	onSave    func(pipeline mongo.Pipeline, target string)
+}
+
+func NewAggregationModal() *Aggregation {
+	a := &Aggregation{
+		BaseElement: core.NewBaseElement(),
+		Flex:        core.NewFlex(),
+		frame:       core.NewFlex(),
+		editor:      tview.NewTextArea(),
+		info:        core.NewTextView(),
+		stages:      NewStagePickerModal(),
+		prompt:      primitives.NewInputModal(),
+		confirm:     core.NewModal(),
+	}
+
+	a.SetIdentifier(AggregationModal)
+	a.SetAfterInitFunc(a.init)
+
+	return a
+}
+
+func (a *Aggregation) init() error {
+	a.setStaticLayout()
+	a.setStyle()
+	a.setKeybindings()
+
+	if err := a.stages.Init(a.App); err != nil {
+		return err
+	}
+	a.stages.SetSelectFunc(a.addTemplate)
+
+	return nil
+}
+
+func (a *Aggregation) setStaticLayout() {
+	a.frame.SetBorder(true)
+	a.frame.SetTitle(" " + config.Msg("modal.aggregation.title") + " ")
+	a.frame.SetTitleAlign(tview.AlignCenter)
+	a.frame.SetDirection(tview.FlexRow)
+
+	a.editor.SetPlaceholder("[\n  { $match: { status: \"active\" } },\n  { $group: { _id: \"$country\", count: { $sum: 1 } } }\n]")
+
+	a.info.SetBorder(true)
+	a.info.SetTitle(" " + config.Msg("modal.aggregation.info.title") + " ")
+	a.info.SetDynamicColors(true)
+	a.info.SetScrollable(true)
+
+	a.prompt.SetBorder(true)
+	a.confirm.SetBorder(true)
+	a.confirm.SetTitle(" " + config.Msg("modal.aggregation.confirm.title") + " ")
+
+	a.frame.AddItem(a.editor, 0, 1, true)
+	a.frame.AddItem(a.info, 8, 0, false)
+
+	// easy way to center the frame
+	column := core.NewFlex()
+	column.SetDirection(tview.FlexRow)
+	column.AddItem(tview.NewBox(), 0, 1, false)
+	column.AddItem(a.frame, 0, 6, true)
+	column.AddItem(tview.NewBox(), 0, 1, false)
+
+	a.AddItem(tview.NewBox(), 0, 1, false)
+	a.AddItem(column, 0, 4, true)
+	a.AddItem(tview.NewBox(), 0, 1, false)
+}
+
+func (a *Aggregation) setStyle() {
+	styles := a.App.GetStyles()
+	a.frame.SetStyle(styles)
+	a.info.SetStyle(styles)
+	a.info.SetTextColor(styles.Global.SecondaryTextColor.Color())
+
+	background := styles.Global.BackgroundColor.Color()
+	a.editor.SetBackgroundColor(background)
+	a.editor.SetTextStyle(tcell.StyleDefault.Foreground(styles.Global.TextColor.Color()).Background(background))
+	a.editor.SetPlaceholderStyle(tcell.StyleDefault.Foreground(styles.Global.SecondaryTextColor.Color()).Background(background))
+
+	a.prompt.SetBorderColor(styles.Global.BorderColor.Color())
+	a.prompt.SetBackgroundColor(background)
+	a.prompt.SetFieldTextColor(styles.Others.ModalTextColor.Color())
+	a.prompt.SetFieldBackgroundColor(styles.Global.ContrastBackgroundColor.Color())
+	a.confirm.SetStyle(styles)
+}
+
+func (a *Aggregation) setKeybindings() {
+	a.editor.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyEscape:
+			a.close()
+			return nil
+		case tcell.KeyCtrlR:
+			a.run()
+			return nil
+		case tcell.KeyCtrlN:
+			a.stages.Render()
+			return nil
+		case tcell.KeyCtrlP:
+			a.explain()
+			return nil
+		case tcell.KeyCtrlS:
+			a.showSave()
+			return nil
+		case tcell.KeyCtrlE:
+			a.showExportCompass()
+			return nil
+		case tcell.KeyCtrlF:
+			a.showImportCompass()
+			return nil
+		}
+		return event
+	})
+}
+
+// SetRunFunc sets the function called with the pipeline to run,
+// the empty pipeline switches back to found documents
+func (a *Aggregation) SetRunFunc(onRun func(pipeline string)) {
+	a.onRun = onRun
+}
+
+// SetExplainFunc sets the function returning execution stats of stages
+func (a *Aggregation) SetExplainFunc(onExplain func(pipeline mongo.Pipeline) ([]mongo.StageStats, error)) {
+	a.onExplain = onExplain
+}
+
+// SetLookupFunc sets the function opening the $lookup builder,
+// the built stage is added with AddStage
+func (a *Aggregation) SetLookupFunc(onLookup func()) {
+	a.onLookup = onLookup
+}
+
+// SetSaveFuncs sets the function checking if the collection exists and
+// the function saving results of the pipeline into the target collection
+func (a *Aggregation) SetSaveFuncs(hasColl func(name string) (bool, error), onSave func(pipeline mongo.Pipeline, target string)) {
+	a.hasColl = hasColl
+	a.onSave = onSave
+}
+
+// Render shows the editor with the pipeline of the collection
+func (a *Aggregation) Render(namespace, pipeline string) {
+	a.namespace = namespace
+	a.frame.SetTitle(fmt.Sprintf(" Aggregation on %s (Ctrl+R - run, Ctrl+N - add stage, Ctrl+P - explain, Esc - close) ", namespace))
+	a.editor.SetText(pipeline, true)
+	a.info.SetText("Run the empty pipeline to list documents found with the query again.\n" +
+		"Ctrl+S - save results to a collection, Ctrl+E - export to Compass, Ctrl+F - import from Compass")
+
+	a.App.Pages.AddPage(AggregationModal, a, true, true)
+}
+
+// AddStage appends the stage to the edited pipeline
+func (a *Aggregation) AddStage(stage string) {
+	a.editor.SetText(mongo.AppendStages(a.editor.GetText(), stage), true)
+	a.App.SetFocus(a.editor)
+}
+
+// addTemplate adds the picked stage or template, $lookup is built
+// in the builder when it's available
+func (a *Aggregation) addTemplate(template mongo.PipelineTemplate) {
+	if template.Name == "$lookup" && a.onLookup != nil {
+		a.onLookup()
+		return
+	}
+	a.AddStage(template.Text)
+	if placeholders := template.Placeholders(); len(placeholders) > 0 {
+		a.info.SetText(fmt.Sprintf("Replace placeholders: {{%s}}", strings.Join(placeholders, "}}, {{")))
+	}
+}
+
+// parse returns the pipeline of the editor, placeholders
+// of templates must be replaced first
+func (a *Aggregation) parse() (mongo.Pipeline, error) {
+	text := a.editor.GetText()
+	if placeholders := (mongo.PipelineTemplate{Text: text}).Placeholders(); len(placeholders) > 0 {
+		return nil, fmt.Errorf("replace placeholders: {{%s}}", strings.Join(placeholders, "}}, {{"))
+	}
+	return mongo.ParseShellPipeline(text)
+}
+
+func (a *Aggregation) run() {
+	if _, err := a.parse(); err != nil {
+		a.showError(err)
+		return
+	}
+	a.close()
+	if a.onRun != nil {
+		a.onRun(a.editor.GetText())
+	}
+}
+
+// explain shows execution stats of every stage, the slowest one is highlighted
+func (a *Aggregation) explain() {
+	pipeline, err := a.parse()
+	if err != nil {
+		a.showError(err)
+		return
+	}
+	if len(pipeline) == 0 || a.onExplain == nil {
+		return
+	}
+	stats, err := a.onExplain(pipeline)
+	if err != nil {
+		a.showError(err)
+		return
+	}
+
+	warning := a.App.GetStyles().Header.WarningColor.Color()
+	slowest := mongo.SlowestStage(stats)
+	var b strings.Builder
+	for i, s := range stats {
+		line := fmt.Sprintf("%d. %-12s", i+1, s.Stage)
+		switch {
+		case !s.Known && !s.Pushdown:
+			line += " no stats, merged by the optimizer"
+		default:
+			line += fmt.Sprintf(" %8d docs %10s", s.Returned, s.Duration)
+			if s.Pushdown {
+				line += " (pushed down to the query)"
+			}
+		}
+		if i == slowest {
+			line = fmt.Sprintf("[%s]%s[-]", warning, line)
+		}
+		b.WriteString(line + "\n")
+	}
+	a.info.SetText(b.String())
+	a.info.ScrollToBeginning()
+}
+
+// showSave asks for the collection results of the pipeline are saved
+// into, replacing the existing collection has to be confirmed
+func (a *Aggregation) showSave() {
+	pipeline, err := a.parse()
+	if err != nil {
+		a.showError(err)
+		return
+	}
+	if len(pipeline) == 0 || a.onSave == nil {
+		return
+	}
+	a.showPrompt(" Save results ", "Target collection", "", func(target string) {
+		a.save(pipeline, target)
+	})
+}
+
+func (a *Aggregation) save(pipeline mongo.Pipeline, target string) {
+	// namespace is db.coll and names of databases can't contain dots
+	db, source, _ := strings.Cut(a.namespace, ".")
+	if _, err := pipeline.WithOut(db, source, target); err != nil {
+		a.showError(err)
+		return
+	}
+	exists := false
+	if a.hasColl != nil {
+		var err error
+		if exists, err = a.hasColl(target); err != nil {
+			a.showError(err)
+			return
+		}
+	}
+	if !exists {
+		a.runSave(pipeline, target)
+		return
+	}
+
+	a.confirm.SetText(fmt.Sprintf("Collection %s already exists, replace all its documents with results of the pipeline?", target))
+	a.confirm.ClearButtons()
+	a.confirm.AddButtons([]string{config.Msg(overwriteButton), config.Msg("button.cancel")})
+	a.confirm.SetDoneFunc(func(_ int, buttonLabel string) {
+		a.App.Pages.RemovePage(AggregationConfirmModal)
+		if buttonLabel == config.Msg(overwriteButton) {
+			a.runSave(pipeline, target)
+		}
+	})
+	a.App.Pages.AddPage(AggregationConfirmModal, a.confirm, true, true)
+}
+
+func (a *Aggregation) runSave(pipeline mongo.Pipeline, target string) {
+	a.close()
+	a.onSave(pipeline, target)
+}
+
+// showExportCompass asks for the file the pipeline is exported to
+// in the format of Compass, so it can be imported there
+func (a *Aggregation) showExportCompass() {
+	pipeline, err := a.parse()
+	if err != nil {
+		a.showError(err)
+		return
+	}
+	_, coll, _ := strings.Cut(a.namespace, ".")
+	path := mongo.ExportFileName(coll+"-pipeline", "json", time.Now())
+	a.showPrompt(" Export to Compass ", "File path", path, func(path string) {
+		if err := a.exportCompass(path, pipeline); err != nil {
+			a.showError(err)
+			return
+		}
+		a.info.SetText("Pipeline exported to " + path)
+	})
+}
+
+func (a *Aggregation) exportCompass(path string, pipeline mongo.Pipeline) error {
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	jsoned, err := json.MarshalIndent(mongo.NewCompassPipeline(name, a.namespace, pipeline), "", "  ")
+	if err != nil {
+		return err
+	}
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return err
+	}
+	_, err = file.Write(append(jsoned, '\n'))
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// showImportCompass asks for the pipeline file saved by Compass, or
+// the file with the pipeline text copied from it, which replaces
+// the edited pipeline
+func (a *Aggregation) showImportCompass() {
+	a.showPrompt(" Import from Compass ", "File path", "", func(path string) {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			a.showError(err)
+			return
+		}
+		saved, pipeline, err := mongo.ParseCompassPipeline(data)
+		if err != nil {
+			a.showError(err)
+			return
+		}
+		// text written in Compass keeps its comments and formatting
+		text := saved.PipelineText
+		if text == "" {
+			text = pipeline.ShellString()
+		}
+		a.editor.SetText(text, true)
+		a.info.SetText(fmt.Sprintf("Imported %d stages from %s", len(pipeline), filepath.Base(path)))
+	})
+}
+
+// showPrompt asks for the single value, onDone is called
+// with the value unless it's empty
+func (a *Aggregation) showPrompt(title, label, text string, onDone func(value string)) {
+	a.prompt.SetTitle(title)
+	a.prompt.SetLabel(label)
+	a.prompt.SetText(text)
+	a.prompt.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyEnter:
+			value := strings.TrimSpace(a.prompt.GetText())
+			if value == "" {
+				return nil
+			}
+			a.App.Pages.RemovePage(AggregationPromptModal)
+			onDone(value)
+			return nil
+		case tcell.KeyEscape:
+			a.App.Pages.RemovePage(AggregationPromptModal)
+			return nil
+		}
+		return event
+	})
+	a.App.Pages.AddPage(AggregationPromptModal, a.prompt, true, true)
+}
+
+func (a *Aggregation) showError(err error) {
+	a.info.SetText(fmt.Sprintf("[%s]%s[-]", a.App.GetStyles().Header.WarningColor.Color(), tview.Escape(err.Error())))
+}
+
+func (a *Aggregation) close() {
+	a.App.Pages.RemovePage(AggregationModal)
+}
@@ -0,0 +1,179 @@
+package modal
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/kopecmaciej/tview"
+	"github.com/kopecmaciej/vi-mongo/internal/config"
+	"github.com/kopecmaciej/vi-mongo/internal/tui/core"
+)
+
+const (
+	ArchiveModal        = "Archive"
+	ArchiveConfirmModal = "ArchiveConfirm"
+
+	archiveAndDeleteButton = "button.archiveAndDelete"
+)
+
+// Archive asks where documents matching the filter are archived
+// and if they're deleted from the collection afterward
+type Archive struct {
+	*core.BaseElement
+	*core.Flex
+
+	form      *core.Form
+	namespace *tview.TextView
+	filter    *tview.TextView
+	path      *tview.InputField
+	remove    *tview.Checkbox
+	confirm   *core.Modal
+	count     int64
+	onArchive func(path string, remove bool)
+}
+
+func NewArchiveModal() *Archive {
+	a := &Archive{
+		BaseElement: core.NewBaseElement(),
+		Flex:        core.NewFlex(),
+		form:        core.NewForm(),
+		namespace:   tview.NewTextView(),
+		filter:      tview.NewTextView(),
+		path:        tview.NewInputField(),
+		remove:      tview.NewCheckbox(),
+		confirm:     core.NewModal(),
+	}
+
+	a.SetIdentifier(ArchiveModal)
+	a.SetAfterInitFunc(a.init)
+
+	return a
+}
+
+func (a *Archive) init() error {
+	a.setStaticLayout()
+	a.setStyle()
+	a.setKeybindings()
+
+	return nil
+}
+
+func (a *Archive) setStaticLayout() {
+	a.form.SetBorder(true)
+	a.form.SetTitle(" " + config.Msg("modal.archive.title") + " ")
+	a.form.SetTitleAlign(tview.AlignCenter)
+	a.form.SetButtonsAlign(tview.AlignCenter)
+
+	a.namespace.SetLabel("Collection")
+	a.namespace.SetSize(1, 0)
+	a.filter.SetLabel("Filter")
+	a.filter.SetSize(3, 0)
+	a.filter.SetWrap(true)
+	a.path.SetLabel("Archive file")
+	a.path.SetFieldWidth(50)
+	a.remove.SetLabel("Delete archived")
+
+	a.form.AddFormItem(a.namespace)
+	a.form.AddFormItem(a.filter)
+	a.form.AddFormItem(a.path)
+	a.form.AddFormItem(a.remove)
+
+	a.form.AddButton(config.Msg("button.archive"), a.archive)
+	a.form.AddButton(config.Msg("button.cancel"), a.close)
+
+	a.confirm.SetBorder(true)
+	a.confirm.SetTitle(" " + config.Msg("modal.archive.confirm.title") + " ")
+
+	// easy way to center the form
+	column := core.NewFlex()
+	column.SetDirection(tview.FlexRow)
+	column.AddItem(tview.NewBox(), 0, 1, false)
+	column.AddItem(a.form, 13, 0, true)
+	column.AddItem(tview.NewBox(), 0, 1, false)
+
+	a.AddItem(tview.NewBox(), 0, 1, false)
+	a.AddItem(column, 80, 0, true)
+	a.AddItem(tview.NewBox(), 0, 1, false)
+}
+
+func (a *Archive) setStyle() {
+	styles := a.App.GetStyles()
+	a.form.SetStyle(styles)
+	a.form.SetLabelColor(styles.InputBar.LabelColor.Color())
+	a.form.SetFieldTextColor(styles.InputBar.InputColor.Color())
+	a.form.SetFieldBackgroundColor(styles.Global.ContrastBackgroundColor.Color())
+	for _, view := range []*tview.TextView{a.namespace, a.filter} {
+		view.SetTextColor(styles.Global.SecondaryTextColor.Color())
+		view.SetBackgroundColor(styles.Global.BackgroundColor.Color())
+	}
+	a.confirm.SetStyle(styles)
+}
+
+func (a *Archive) setKeybindings() {
+	a.form.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyEscape {
+			a.close()
+			return nil
+		}
+		return event
+	})
+}
+
+// SetArchiveFunc sets the function called with the path of the archive
+// and whether archived documents are deleted afterward
+func (a *Archive) SetArchiveFunc(onArchive func(path string, remove bool)) {
+	a.onArchive = onArchive
+}
+
+// Render shows the filter of count documents of the collection
+// archived into the file at the path, documents aren't deleted by default
+func (a *Archive) Render(namespace, filter string, count int64, path string) {
+	if strings.TrimSpace(filter) == "" {
+		filter = "{} (all documents)"
+	}
+	a.count = count
+	a.namespace.SetText(fmt.Sprintf("%s, %d documents", namespace, count))
+	a.filter.SetText(filter)
+	a.path.SetText(path)
+	a.remove.SetChecked(false)
+	a.form.SetFocus(2)
+
+	a.App.Pages.AddPage(ArchiveModal, a, true, true)
+}
+
+// archive asks to confirm the deletion before documents are archived
+func (a *Archive) archive() {
+	path := strings.TrimSpace(a.path.GetText())
+	if path == "" {
+		ShowError(a.App.Pages, "Invalid archive", fmt.Errorf("archive file is required"))
+		return
+	}
+	if !a.remove.IsChecked() {
+		a.run(path, false)
+		return
+	}
+
+	a.confirm.SetText(fmt.Sprintf("Delete %d documents matching the filter after they're archived? "+
+		"Nothing is deleted unless the archive has all of them.", a.count))
+	a.confirm.ClearButtons()
+	a.confirm.AddButtons([]string{config.Msg(archiveAndDeleteButton), config.Msg("button.cancel")})
+	a.confirm.SetDoneFunc(func(_ int, buttonLabel string) {
+		a.App.Pages.RemovePage(ArchiveConfirmModal)
+		if buttonLabel == config.Msg(archiveAndDeleteButton) {
+			a.run(path, true)
+		}
+	})
+	a.App.Pages.AddPage(ArchiveConfirmModal, a.confirm, true, true)
+}
+
+func (a *Archive) run(path string, remove bool) {
+	a.close()
+	if a.onArchive != nil {
+		a.onArchive(path, remove)
+	}
+}
+
+func (a *Archive) close() {
+	a.App.Pages.RemovePage(ArchiveModal)
+}
@@ -0,0 +1,209 @@
+package modal
+
+import (
+	"fmt"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/kopecmaciej/tview"
+	"github.com/kopecmaciej/vi-mongo/internal/config"
+	"github.com/kopecmaciej/vi-mongo/internal/mongo"
+	"github.com/kopecmaciej/vi-mongo/internal/tui/core"
+	"github.com/kopecmaciej/vi-mongo/internal/tui/primitives"
+	"github.com/kopecmaciej/vi-mongo/internal/util"
+)
+
+const (
+	CollectionStatsModalView = "CollectionStatsModal"
+	ExpireAfterModal         = "ExpireAfter"
+
+	changeExpiryButton = "button.changeExpiry"
+
+	// growthChartWidth is the number of the newest samples shown in charts
+	growthChartWidth = 40
+)
+
+// CollectionStatsModal shows sizes of the collection and how they grew
+// since the collection was first opened, and the configuration of
+// time-series collections, which expiry can be changed
+type CollectionStatsModal struct {
+	*core.BaseElement
+	*primitives.ViewModal
+
+	expiry   *primitives.InputModal
+	onExpire func(seconds *int64) error
+}
+
+func NewCollectionStatsModal() *CollectionStatsModal {
+	cs := &CollectionStatsModal{
+		BaseElement: core.NewBaseElement(),
+		ViewModal:   primitives.NewViewModal(),
+		expiry:      primitives.NewInputModal(),
+	}
+
+	cs.SetIdentifier(CollectionStatsModalView)
+	return cs
+}
+
+func (cs *CollectionStatsModal) Init(app *core.App) error {
+	cs.App = app
+	cs.expiry.SetBorder(true)
+	cs.expiry.SetTitle(" " + config.Msg("modal.stats.expiry.title") + " ")
+	cs.expiry.SetLabel("Seconds or e.g. 30d, off to never expire")
+	cs.setStyle()
+	return nil
+}
+
+func (cs *CollectionStatsModal) setStyle() {
+	cs.ViewModal.SetBackgroundColor(cs.App.GetStyles().Global.BackgroundColor.Color())
+	cs.ViewModal.SetTextColor(cs.App.GetStyles().Global.TextColor.Color())
+	cs.ViewModal.SetButtonBackgroundColor(cs.App.GetStyles().Global.BackgroundColor.Color())
+	cs.ViewModal.SetButtonTextColor(cs.App.GetStyles().Global.TextColor.Color())
+
+	styles := cs.App.GetStyles()
+	cs.expiry.SetBorderColor(styles.Global.BorderColor.Color())
+	cs.expiry.SetBackgroundColor(styles.Global.BackgroundColor.Color())
+	cs.expiry.SetFieldTextColor(styles.Others.ModalTextColor.Color())
+	cs.expiry.SetFieldBackgroundColor(styles.Global.ContrastBackgroundColor.Color())
+}
+
+// SetExpireFunc sets the function which changes the expiry
+// of measurements of the time-series collection
+func (cs *CollectionStatsModal) SetExpireFunc(onExpire func(seconds *int64) error) {
+	cs.onExpire = onExpire
+}
+
+// Render shows stats of the collection and charts of its growth,
+// time series is nil if the collection isn't a time-series one
+func (cs *CollectionStatsModal) Render(namespace string, stats *mongo.CollectionStats, samples []config.GrowthSample, timeSeries *mongo.TimeSeries) {
+	cs.SetTitle(namespace)
+
+	info := [][2]string{
+		{"Documents", fmt.Sprintf("%d", stats.Count)},
+		{"Data Size", util.FormatBytes(stats.Size)},
+		{"Storage Size", util.FormatBytes(stats.StorageSize)},
+		{"Average Document", util.FormatBytes(stats.AvgObjSize)},
+		{"Indexes", fmt.Sprintf("%d (%s)", stats.Indexes, util.FormatBytes(stats.TotalIndexSize))},
+	}
+	if timeSeries != nil {
+		info = append(info, timeSeriesInfo(timeSeries)...)
+	}
+
+	styles := cs.App.GetStyles()
+	content := ""
+	for _, line := range info {
+		content += fmt.Sprintf("[%s]%s[%s] %s\n", styles.Others.ModalTextColor.Color(), line[0], styles.Others.ModalSecondaryTextColor.Color(), line[1])
+	}
+	content += cs.growth(samples)
+
+	cs.ViewModal.SetText(primitives.Text{
+		Content: content,
+		Align:   tview.AlignLeft,
+	})
+	cs.ViewModal.ClearButtons()
+	buttons := []string{config.Msg("button.close")}
+	if timeSeries != nil && cs.onExpire != nil {
+		buttons = []string{config.Msg(changeExpiryButton), config.Msg("button.close")}
+	}
+	cs.ViewModal.AddButtons(buttons)
+	cs.ViewModal.SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+		cs.App.Pages.RemovePage(CollectionStatsModalView)
+		if buttonLabel == config.Msg(changeExpiryButton) {
+			cs.showExpiry(timeSeries, func() {
+				cs.Render(namespace, stats, samples, timeSeries)
+			})
+		}
+	})
+
+	cs.App.Pages.AddPage(CollectionStatsModalView, cs, true, true)
+}
+
+// timeSeriesInfo returns the configuration of the time-series collection
+func timeSeriesInfo(timeSeries *mongo.TimeSeries) [][2]string {
+	meta, granularity, expiry := "none", "custom", "never"
+	if timeSeries.MetaField != "" {
+		meta = timeSeries.MetaField
+	}
+	if timeSeries.Granularity != "" {
+		granularity = timeSeries.Granularity
+	}
+	if timeSeries.ExpireAfterSeconds != nil {
+		expiry = util.FormatSeconds(*timeSeries.ExpireAfterSeconds)
+	}
+	return [][2]string{
+		{"Time Field", timeSeries.TimeField},
+		{"Meta Field", meta},
+		{"Granularity", granularity},
+		{"Bucket Span", util.FormatSeconds(timeSeries.BucketSpan())},
+		{"Bucket Rounding", util.FormatSeconds(timeSeries.BucketRounding())},
+		{"Expire After", expiry},
+	}
+}
+
+// showExpiry asks for the new expiry of measurements, the stats
+// are shown again with onDone when it's changed or canceled
+func (cs *CollectionStatsModal) showExpiry(timeSeries *mongo.TimeSeries, onDone func()) {
+	expiry := "off"
+	if timeSeries.ExpireAfterSeconds != nil {
+		expiry = fmt.Sprintf("%d", *timeSeries.ExpireAfterSeconds)
+	}
+	cs.expiry.SetText(expiry)
+	cs.expiry.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyEnter:
+			seconds, err := mongo.ParseExpireAfter(cs.expiry.GetText())
+			if err != nil {
+				ShowError(cs.App.Pages, "Invalid expiry", err)
+				return nil
+			}
+			cs.App.Pages.RemovePage(ExpireAfterModal)
+			if err := cs.onExpire(seconds); err != nil {
+				ShowError(cs.App.Pages, "Error changing expiry", err)
+				return nil
+			}
+			timeSeries.ExpireAfterSeconds = seconds
+			onDone()
+			return nil
+		case tcell.KeyEscape:
+			cs.App.Pages.RemovePage(ExpireAfterModal)
+			onDone()
+			return nil
+		}
+		return event
+	})
+	cs.App.Pages.AddPage(ExpireAfterModal, cs.expiry, true, true)
+}
+
+// growth returns charts of the document count and storage size
+// with the growth per day since the first sample
+func (cs *CollectionStatsModal) growth(samples []config.GrowthSample) string {
+	if len(samples) < 2 {
+		return "\nGrowth is shown once the collection is opened again later\n"
+	}
+
+	styles := cs.App.GetStyles()
+	content := fmt.Sprintf("\n[%s]Growth since %s[-]\n", styles.Others.ModalTextColor.Color(), samples[0].Time.Format("2006-01-02"))
+	series := []struct {
+		name   string
+		value  func(config.GrowthSample) int64
+		format func(int64) string
+	}{
+		{"Documents", func(s config.GrowthSample) int64 { return s.Count }, func(n int64) string { return fmt.Sprintf("%d", n) }},
+		{"Storage Size", func(s config.GrowthSample) int64 { return s.StorageSize }, util.FormatBytes},
+	}
+	for _, s := range series {
+		values := make([]float64, len(samples))
+		for i, sample := range samples {
+			values[i] = float64(s.value(sample))
+		}
+		perDay := int64(config.GrowthPerDay(samples, s.value))
+		sign := "+"
+		if perDay < 0 {
+			sign, perDay = "-", -perDay
+		}
+		content += fmt.Sprintf("[%s]%-13s[%s] %s %s (%s%s/day)\n",
+			styles.Others.ModalTextColor.Color(), s.name,
+			styles.Others.ModalSecondaryTextColor.Color(), util.Sparkline(values, growthChartWidth),
+			s.format(s.value(samples[len(samples)-1])), sign, s.format(perDay))
+	}
+	return content
+}
@@ -0,0 +1,144 @@
+package modal
+
+import (
+	"fmt"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/kopecmaciej/tview"
+	"github.com/kopecmaciej/vi-mongo/internal/mongo"
+	"github.com/kopecmaciej/vi-mongo/internal/tui/core"
+	"github.com/kopecmaciej/vi-mongo/internal/util"
+)
+
+const (
+	CountByFieldModal = "CountByField"
+)
+
+// CountByField shows the most common values of the field with their
+// counts, selected value can be applied as the filter to drill down
+type CountByField struct {
+	*core.BaseElement
+	*core.Flex
+
+	table   *core.Table
+	field   string
+	counts  []mongo.FieldCount
+	onApply func(field string, value interface{})
+}
+
+func NewCountByFieldModal() *CountByField {
+	cf := &CountByField{
+		BaseElement: core.NewBaseElement(),
+		Flex:        core.NewFlex(),
+		table:       core.NewTable(),
+	}
+
+	cf.SetIdentifier(CountByFieldModal)
+	cf.SetAfterInitFunc(cf.init)
+
+	return cf
+}
+
+func (cf *CountByField) init() error {
+	cf.setStaticLayout()
+	cf.setStyle()
+	cf.setKeybindings()
+
+	return nil
+}
+
+func (cf *CountByField) setStaticLayout() {
+	cf.table.SetBorder(true)
+	cf.table.SetTitleAlign(tview.AlignCenter)
+	cf.table.SetFixed(1, 0)
+	cf.table.SetSelectable(true, false)
+
+	// easy way to center the table
+	column := core.NewFlex()
+	column.SetDirection(tview.FlexRow)
+	column.AddItem(tview.NewBox(), 0, 1, false)
+	column.AddItem(cf.table, 0, 3, true)
+	column.AddItem(tview.NewBox(), 0, 1, false)
+
+	cf.AddItem(tview.NewBox(), 0, 1, false)
+	cf.AddItem(column, 80, 0, true)
+	cf.AddItem(tview.NewBox(), 0, 1, false)
+}
+
+func (cf *CountByField) setStyle() {
+	styles := cf.App.GetStyles()
+	cf.table.SetStyle(styles)
+}
+
+func (cf *CountByField) setKeybindings() {
+	cf.table.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyEscape:
+			cf.close()
+			return nil
+		case tcell.KeyEnter:
+			row, _ := cf.table.GetSelection()
+			cf.apply(row - 1)
+			return nil
+		}
+		return event
+	})
+}
+
+// SetApplyFunc sets the function called with the field
+// and the value selected to filter by
+func (cf *CountByField) SetApplyFunc(onApply func(field string, value interface{})) {
+	cf.onApply = onApply
+}
+
+// Render shows counts of values of the field, total is the number of
+// documents matching the filter, the share of values is shown if it's set
+func (cf *CountByField) Render(field string, counts []mongo.FieldCount, total int64) {
+	cf.field = field
+	cf.counts = counts
+	cf.table.Clear()
+	cf.table.SetTitle(fmt.Sprintf(" Top values of %s (Enter - filter, Esc - close) ", field))
+
+	style := cf.App.GetStyles().Content
+	for col, header := range []string{"Value", "Count", "Share"} {
+		cf.table.SetCell(0, col, tview.NewTableCell(header).
+			SetTextColor(style.ColumnKeyColor.Color()).
+			SetBackgroundColor(style.HeaderRowBackgroundColor.Color()).
+			SetSelectable(false).
+			SetExpansion(1))
+	}
+	for i, count := range counts {
+		value := util.GetValueByType(count.Value)
+		if count.Value == nil {
+			value = "null / missing"
+		}
+		share := ""
+		if total > 0 {
+			share = fmt.Sprintf("%.1f%%", float64(count.Count)*100/float64(total))
+		}
+		cf.table.SetCell(i+1, 0, tview.NewTableCell(util.TruncateByWidth(value, 50)).SetTextColor(style.CellTextColor.Color()))
+		cf.table.SetCell(i+1, 1, tview.NewTableCell(fmt.Sprintf("%d", count.Count)).SetTextColor(style.CellTextColor.Color()))
+		cf.table.SetCell(i+1, 2, tview.NewTableCell(share).SetTextColor(style.CellTextColor.Color()))
+	}
+	if len(counts) == 0 {
+		cf.table.SetCell(1, 0, tview.NewTableCell("No documents found"))
+	}
+
+	cf.table.Select(1, 0)
+	cf.table.ScrollToBeginning()
+	cf.App.Pages.AddPage(CountByFieldModal, cf, true, true)
+}
+
+func (cf *CountByField) apply(index int) {
+	if index < 0 || index >= len(cf.counts) {
+		return
+	}
+	cf.close()
+	if cf.onApply != nil {
+		cf.onApply(cf.field, cf.counts[index].Value)
+	}
+}
+
+func (cf *CountByField) close() {
+	cf.App.Pages.RemovePage(CountByFieldModal)
+}
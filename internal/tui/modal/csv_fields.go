@@ -0,0 +1,167 @@
+package modal
+
+import (
+	"fmt"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/kopecmaciej/tview"
+	"github.com/kopecmaciej/vi-mongo/internal/tui/core"
+	"github.com/kopecmaciej/vi-mongo/internal/tui/primitives"
+)
+
+const (
+	CSVFieldsModal = "CSVFields"
+)
+
+// CSVFields lets the user pick top-level fields exported as CSV columns,
+// embedded documents are written as JSON or split into dotted columns
+type CSVFields struct {
+	*core.BaseElement
+	*primitives.ListModal
+
+	fields   []string
+	types    map[string]string
+	selected map[string]bool
+	dotted   bool
+	onExport func(fields []string, dotted bool)
+}
+
+func NewCSVFieldsModal() *CSVFields {
+	cf := &CSVFields{
+		BaseElement: core.NewBaseElement(),
+		ListModal:   primitives.NewListModal(),
+	}
+
+	cf.SetIdentifier(CSVFieldsModal)
+	cf.SetAfterInitFunc(cf.init)
+
+	return cf
+}
+
+func (cf *CSVFields) init() error {
+	cf.setStyle()
+	cf.setKeybindings()
+
+	return nil
+}
+
+func (cf *CSVFields) setStyle() {
+	style := cf.App.GetStyles().History
+	globalBackground := cf.App.GetStyles().Global.BackgroundColor.Color()
+
+	cf.SetBorder(true)
+	cf.ShowSecondaryText(false)
+	cf.SetBorderPadding(0, 0, 1, 1)
+
+	mainStyle := tcell.StyleDefault.
+		Foreground(style.TextColor.Color()).
+		Background(globalBackground)
+	cf.SetMainTextStyle(mainStyle)
+
+	selectedStyle := tcell.StyleDefault.
+		Foreground(style.SelectedTextColor.Color()).
+		Background(style.SelectedBackgroundColor.Color())
+	cf.SetSelectedStyle(selectedStyle)
+}
+
+func (cf *CSVFields) setKeybindings() {
+	cf.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch {
+		case event.Key() == tcell.KeyEscape:
+			cf.close()
+			return nil
+		case event.Key() == tcell.KeyEnter:
+			cf.export()
+			return nil
+		case event.Rune() == ' ':
+			if index := cf.GetCurrentItem(); index >= 0 && index < len(cf.fields) {
+				field := cf.fields[index]
+				cf.selected[field] = !cf.selected[field]
+				cf.renderFields()
+			}
+			return nil
+		case event.Rune() == 'a':
+			all := !cf.allSelected()
+			for _, field := range cf.fields {
+				cf.selected[field] = all
+			}
+			cf.renderFields()
+			return nil
+		case event.Rune() == 'n':
+			cf.dotted = !cf.dotted
+			cf.renderFields()
+			return nil
+		}
+		return event
+	})
+}
+
+// SetExportFunc sets the function called with selected fields, in their
+// order, and whether embedded documents are split into dotted columns
+func (cf *CSVFields) SetExportFunc(onExport func(fields []string, dotted bool)) {
+	cf.onExport = onExport
+}
+
+// Render shows fields with their types, all of them are selected
+func (cf *CSVFields) Render(fields []string, types map[string]string) {
+	cf.fields = fields
+	cf.types = types
+	cf.selected = make(map[string]bool, len(fields))
+	for _, field := range fields {
+		cf.selected[field] = true
+	}
+	cf.renderFields()
+	cf.SetCurrentItem(0)
+
+	cf.App.Pages.AddPage(CSVFieldsModal, cf, true, true)
+}
+
+func (cf *CSVFields) renderFields() {
+	nested := "as JSON"
+	if cf.dotted {
+		nested = "as dotted columns"
+	}
+	cf.SetTitle(fmt.Sprintf(" CSV columns, embedded documents %s (Space - toggle, a - all, n - nested, Enter - export) ", nested))
+
+	current := cf.GetCurrentItem()
+	cf.Clear()
+	for _, field := range cf.fields {
+		mark := "[ ]"
+		if cf.selected[field] {
+			mark = "[x]"
+		}
+		cf.AddItem(tview.Escape(fmt.Sprintf("%s %s (%s)", mark, field, cf.types[field])), "", 0, nil)
+	}
+	if current >= 0 && current < len(cf.fields) {
+		cf.SetCurrentItem(current)
+	}
+}
+
+func (cf *CSVFields) allSelected() bool {
+	for _, field := range cf.fields {
+		if !cf.selected[field] {
+			return false
+		}
+	}
+	return true
+}
+
+func (cf *CSVFields) export() {
+	var fields []string
+	for _, field := range cf.fields {
+		if cf.selected[field] {
+			fields = append(fields, field)
+		}
+	}
+	if len(fields) == 0 {
+		return
+	}
+	cf.close()
+	if cf.onExport != nil {
+		cf.onExport(fields, cf.dotted)
+	}
+}
+
+func (cf *CSVFields) close() {
+	cf.App.Pages.RemovePage(CSVFieldsModal)
+}
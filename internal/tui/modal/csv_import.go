@@ -0,0 +1,528 @@
+package modal
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/kopecmaciej/tview"
+	"github.com/kopecmaciej/vi-mongo/internal/config"
+	"github.com/kopecmaciej/vi-mongo/internal/mongo"
+	"github.com/kopecmaciej/vi-mongo/internal/tui/core"
+	"github.com/kopecmaciej/vi-mongo/internal/util"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	CSVImportModal = "CSVImport"
+)
+
+// csvPreviewSize is the number of parsed documents shown before importing
+const csvPreviewSize = 5
+
+// CSVImport imports the CSV file into the collection. After the file is
+// loaded, every column is mapped to the field and the type its values
+// are converted to, first documents are previewed as they will be inserted.
+// When the column is chosen as the key, documents are upserted by its field.
+// Dry run reports what the import would do without writing anything.
+// The import of the same file interrupted before is resumed from its checkpoint.
+type CSVImport struct {
+	*core.BaseElement
+	*core.Flex
+
+	frame      *core.Flex
+	form       *core.Form
+	path       *tview.InputField
+	batch      *tview.InputField
+	rate       *tview.InputField
+	mappings   *core.Table
+	field      *tview.InputField
+	info       *core.TextView
+	preview    *core.Table
+	file       *mongo.CSVFile
+	source     string
+	sourceStat os.FileInfo
+	columns    []mongo.ColumnMapping
+	// upsertKey is the index of the key column, -1 for plain insert
+	upsertKey int
+	// resumeFrom is the number of rows imported before the import
+	// was interrupted, they are skipped when it's resumed
+	resumeFrom   int
+	onImport     func(documents []primitive.M, opts mongo.ImportOptions, checkpoint config.ImportCheckpoint)
+	onDryRun     func(documents []primitive.M, upsertKey string) (mongo.DryRunReport, error)
+	onCheckpoint func(source string) *config.ImportCheckpoint
+}
+
+func NewCSVImportModal() *CSVImport {
+	ci := &CSVImport{
+		BaseElement: core.NewBaseElement(),
+		Flex:        core.NewFlex(),
+		frame:       core.NewFlex(),
+		form:        core.NewForm(),
+		path:        tview.NewInputField(),
+		batch:       tview.NewInputField(),
+		rate:        tview.NewInputField(),
+		mappings:    core.NewTable(),
+		field:       tview.NewInputField(),
+		info:        core.NewTextView(),
+		preview:     core.NewTable(),
+	}
+
+	ci.SetIdentifier(CSVImportModal)
+	ci.SetAfterInitFunc(ci.init)
+
+	return ci
+}
+
+func (ci *CSVImport) init() error {
+	ci.setStaticLayout()
+	ci.setStyle()
+	ci.setKeybindings()
+
+	return nil
+}
+
+func (ci *CSVImport) setStaticLayout() {
+	ci.frame.SetBorder(true)
+	ci.frame.SetTitleAlign(tview.AlignCenter)
+	ci.frame.SetDirection(tview.FlexRow)
+
+	ci.path.SetLabel("CSV file")
+	ci.path.SetPlaceholder("path of the file with the header line")
+	ci.batch.SetLabel("Batch size")
+	ci.batch.SetFieldWidth(10)
+	ci.batch.SetAcceptanceFunc(tview.InputFieldInteger)
+	ci.rate.SetLabel("Writes per second")
+	ci.rate.SetFieldWidth(10)
+	ci.rate.SetPlaceholder("unlimited")
+	ci.rate.SetAcceptanceFunc(tview.InputFieldInteger)
+	ci.form.AddFormItem(ci.path)
+	ci.form.AddFormItem(ci.batch)
+	ci.form.AddFormItem(ci.rate)
+	ci.form.AddButton(config.Msg("button.load"), ci.load)
+	ci.form.SetButtonsAlign(tview.AlignCenter)
+
+	ci.mappings.SetBorder(true)
+	ci.mappings.SetTitle(" " + config.Msg("modal.csvImport.columns.title") + " ")
+	ci.mappings.SetFixed(1, 0)
+	ci.mappings.SetSelectable(true, false)
+
+	ci.field.SetLabel("Field ")
+
+	ci.preview.SetBorder(true)
+	ci.preview.SetFixed(1, 0)
+
+	ci.frame.AddItem(ci.form, 9, 0, true)
+	ci.frame.AddItem(ci.mappings, 0, 1, false)
+	ci.frame.AddItem(ci.field, 1, 0, false)
+	ci.frame.AddItem(ci.info, 1, 0, false)
+	ci.frame.AddItem(ci.preview, csvPreviewSize+3, 0, false)
+
+	ci.AddItem(tview.NewBox(), 0, 1, false)
+	ci.AddItem(ci.frame, 0, 8, true)
+	ci.AddItem(tview.NewBox(), 0, 1, false)
+}
+
+func (ci *CSVImport) setStyle() {
+	styles := ci.App.GetStyles()
+	ci.frame.SetStyle(styles)
+	ci.form.SetStyle(styles)
+	ci.form.SetLabelColor(styles.InputBar.LabelColor.Color())
+	ci.form.SetFieldTextColor(styles.InputBar.InputColor.Color())
+	ci.form.SetFieldBackgroundColor(styles.Global.ContrastBackgroundColor.Color())
+	ci.path.SetPlaceholderTextColor(styles.Global.SecondaryTextColor.Color())
+	ci.rate.SetPlaceholderTextColor(styles.Global.SecondaryTextColor.Color())
+	ci.field.SetLabelColor(styles.InputBar.LabelColor.Color())
+	ci.field.SetFieldTextColor(styles.InputBar.InputColor.Color())
+	ci.field.SetFieldBackgroundColor(styles.Global.ContrastBackgroundColor.Color())
+	ci.field.SetBackgroundColor(styles.Global.BackgroundColor.Color())
+	ci.mappings.SetStyle(styles)
+	ci.preview.SetStyle(styles)
+	ci.info.SetStyle(styles)
+	ci.info.SetTextColor(styles.Content.StatusTextColor.Color())
+}
+
+func (ci *CSVImport) setKeybindings() {
+	ci.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyEscape:
+			if ci.field.HasFocus() {
+				ci.stopRename()
+				return nil
+			}
+			ci.close()
+			return nil
+		case tcell.KeyTab:
+			// form handles Tab itself, unless the columns are focused
+			if ci.mappings.HasFocus() {
+				ci.App.SetFocus(ci.form)
+				return nil
+			}
+		}
+		return event
+	})
+	ci.form.SetCancelFunc(ci.close)
+	ci.path.SetDoneFunc(func(key tcell.Key) {
+		if key == tcell.KeyEnter {
+			ci.load()
+		}
+	})
+
+	ci.mappings.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		row, _ := ci.mappings.GetSelection()
+		col := row - 1
+		if col < 0 || col >= len(ci.columns) {
+			return event
+		}
+		switch {
+		case event.Key() == tcell.KeyEnter:
+			ci.startRename(col)
+			return nil
+		case event.Rune() == 't':
+			ci.nextType(col)
+			return nil
+		case event.Rune() == 's':
+			ci.toggleSkip(col)
+			return nil
+		case event.Rune() == 'k':
+			ci.toggleUpsertKey(col)
+			return nil
+		case event.Rune() == 'd':
+			ci.dryRun()
+			return nil
+		case event.Rune() == 'r':
+			ci.resumeFrom = 0
+			ci.renderMappings()
+			return nil
+		case event.Rune() == 'i':
+			ci.importDocuments()
+			return nil
+		}
+		return event
+	})
+
+	ci.field.SetDoneFunc(func(key tcell.Key) {
+		if key != tcell.KeyEnter {
+			return
+		}
+		row, _ := ci.mappings.GetSelection()
+		if field := ci.field.GetText(); field != "" && row > 0 {
+			ci.columns[row-1].Field = field
+		}
+		ci.stopRename()
+	})
+}
+
+// SetImportFunc sets the function called with all parsed documents and
+// options of the import, upsert key is empty for plain insert. Checkpoint
+// describes the import, so it can be saved as it progresses.
+func (ci *CSVImport) SetImportFunc(onImport func(documents []primitive.M, opts mongo.ImportOptions, checkpoint config.ImportCheckpoint)) {
+	ci.onImport = onImport
+}
+
+// SetCheckpointFunc sets the function returning the checkpoint of
+// the interrupted import of the source file, or nil if there is none
+func (ci *CSVImport) SetCheckpointFunc(onCheckpoint func(source string) *config.ImportCheckpoint) {
+	ci.onCheckpoint = onCheckpoint
+}
+
+// SetDryRunFunc sets the function returning what importing all parsed
+// documents would do, called with the same arguments as the import
+func (ci *CSVImport) SetDryRunFunc(onDryRun func(documents []primitive.M, upsertKey string) (mongo.DryRunReport, error)) {
+	ci.onDryRun = onDryRun
+}
+
+// Render shows the import into the collection, starting with the file path,
+// limits of writes are prefilled with batchSize and writesPerSecond
+func (ci *CSVImport) Render(db, coll string, batchSize, writesPerSecond int) {
+	ci.batch.SetText(strconv.Itoa(batchSize))
+	ci.rate.SetText("")
+	if writesPerSecond > 0 {
+		ci.rate.SetText(strconv.Itoa(writesPerSecond))
+	}
+	ci.frame.SetTitle(fmt.Sprintf(" Import CSV into %s.%s (Tab - switch, Esc - close) ", db, coll))
+	ci.file = nil
+	ci.columns = nil
+	ci.upsertKey = -1
+	ci.resumeFrom = 0
+	ci.mappings.Clear()
+	ci.preview.Clear()
+	ci.info.SetText("")
+	ci.form.SetFocus(0)
+
+	ci.App.Pages.AddPage(CSVImportModal, ci, true, true)
+	ci.App.SetFocus(ci.form)
+}
+
+func (ci *CSVImport) load() {
+	source, err := filepath.Abs(strings.TrimSpace(ci.path.GetText()))
+	if err != nil {
+		ShowError(ci.App.Pages, "Error reading CSV file", err)
+		return
+	}
+	f, err := os.Open(source)
+	if err != nil {
+		ShowError(ci.App.Pages, "Error reading CSV file", err)
+		return
+	}
+	defer f.Close()
+
+	stat, err := f.Stat()
+	if err != nil {
+		ShowError(ci.App.Pages, "Error reading CSV file", err)
+		return
+	}
+	file, err := mongo.ReadCSV(f)
+	if err != nil {
+		ShowError(ci.App.Pages, "Error reading CSV file", err)
+		return
+	}
+	ci.file = file
+	ci.source = source
+	ci.sourceStat = stat
+	ci.columns = file.DefaultMappings()
+	ci.upsertKey = -1
+	ci.resumeFrom = 0
+	if ci.onCheckpoint != nil {
+		if checkpoint := ci.onCheckpoint(source); checkpoint != nil {
+			ci.restore(*checkpoint)
+		}
+	}
+	ci.renderMappings()
+	ci.mappings.Select(1, 0)
+	ci.App.SetFocus(ci.mappings)
+}
+
+func (ci *CSVImport) renderMappings() {
+	ci.mappings.Clear()
+	style := ci.App.GetStyles().Content
+	for col, header := range []string{"Column", "Field", "Type", "Sample"} {
+		ci.mappings.SetCell(0, col, tview.NewTableCell(header).
+			SetTextColor(style.ColumnKeyColor.Color()).
+			SetBackgroundColor(style.HeaderRowBackgroundColor.Color()).
+			SetSelectable(false).
+			SetExpansion(1))
+	}
+	for i, mapping := range ci.columns {
+		sample := ""
+		if len(ci.file.Rows) > 0 && i < len(ci.file.Rows[0]) {
+			sample = ci.file.Rows[0][i]
+		}
+		field := mapping.Field
+		switch {
+		case mapping.Type == mongo.TypeSkip:
+			field = "-"
+		case i == ci.upsertKey:
+			field += " (key)"
+		}
+		for col, text := range []string{mapping.Column, field, string(mapping.Type), util.TruncateByWidth(sample, 30)} {
+			ci.mappings.SetCell(i+1, col, tview.NewTableCell(text).SetTextColor(style.CellTextColor.Color()))
+		}
+	}
+	ci.renderPreview()
+}
+
+// renderPreview shows first documents as they will be inserted,
+// or the first value which can't be converted
+func (ci *CSVImport) renderPreview() {
+	ci.preview.Clear()
+	ci.preview.SetTitle(fmt.Sprintf(" Preview of first %d documents ", csvPreviewSize))
+	documents, err := ci.file.Documents(ci.columns, csvPreviewSize)
+	if err != nil {
+		ci.info.SetText(err.Error())
+		return
+	}
+	mode := "insert"
+	if key := ci.upsertKeyField(); key != "" {
+		mode = "upsert by " + key
+	}
+	info := fmt.Sprintf("Rows: %d, Columns: %d, Mode: %s", len(ci.file.Rows), len(ci.columns), mode)
+	if ci.resumeFrom > 0 {
+		info += fmt.Sprintf(", resuming after row %d", ci.resumeFrom)
+	}
+	ci.info.SetText(info)
+	if len(documents) == 0 {
+		ci.preview.SetCell(0, 0, tview.NewTableCell("No rows to import"))
+		return
+	}
+	ci.renderDocuments(documents)
+}
+
+// renderDocuments shows documents in the preview table
+func (ci *CSVImport) renderDocuments(documents []primitive.M) {
+	style := ci.App.GetStyles().Content
+	headers, fields := documentColumns(documents, style.ColumnTypeColor.Color().String(), "_id")
+	for col, header := range headers {
+		ci.preview.SetCell(0, col, tview.NewTableCell(header).
+			SetTextColor(style.ColumnKeyColor.Color()).
+			SetBackgroundColor(style.HeaderRowBackgroundColor.Color()).
+			SetSelectable(false).
+			SetAlign(tview.AlignCenter))
+	}
+	for row, doc := range documents {
+		for col, field := range fields {
+			var text string
+			if val, ok := doc[field]; ok {
+				text = util.GetValueByType(val)
+			}
+			ci.preview.SetCell(row+1, col, tview.NewTableCell(util.TruncateByWidth(text, 30)).
+				SetTextColor(style.CellTextColor.Color()).
+				SetMaxWidth(30))
+		}
+	}
+}
+
+func (ci *CSVImport) nextType(col int) {
+	types := mongo.ColumnTypes
+	for i, columnType := range types {
+		if columnType == ci.columns[col].Type {
+			ci.columns[col].Type = types[(i+1)%len(types)]
+			break
+		}
+	}
+	ci.renderMappings()
+}
+
+// toggleUpsertKey sets the column as the one documents are upserted by,
+// or goes back to plain insert if it's already the key
+func (ci *CSVImport) toggleUpsertKey(col int) {
+	if ci.upsertKey == col {
+		ci.upsertKey = -1
+	} else {
+		ci.upsertKey = col
+	}
+	ci.renderMappings()
+}
+
+// upsertKeyField returns the field of the key column,
+// empty if documents are inserted
+func (ci *CSVImport) upsertKeyField() string {
+	if ci.upsertKey < 0 || ci.upsertKey >= len(ci.columns) || ci.columns[ci.upsertKey].Type == mongo.TypeSkip {
+		return ""
+	}
+	return ci.columns[ci.upsertKey].Field
+}
+
+func (ci *CSVImport) toggleSkip(col int) {
+	if ci.columns[col].Type == mongo.TypeSkip {
+		ci.columns[col].Type = mongo.TypeString
+	} else {
+		ci.columns[col].Type = mongo.TypeSkip
+	}
+	ci.renderMappings()
+}
+
+// startRename fills the input with the field name of the column
+func (ci *CSVImport) startRename(col int) {
+	ci.field.SetText(ci.columns[col].Field)
+	ci.App.SetFocus(ci.field)
+}
+
+func (ci *CSVImport) stopRename() {
+	ci.field.SetText("")
+	ci.renderMappings()
+	ci.App.SetFocus(ci.mappings)
+}
+
+// dryRun shows what the import would do and existing
+// documents which would be replaced or conflict
+func (ci *CSVImport) dryRun() {
+	if ci.onDryRun == nil {
+		return
+	}
+	documents, err := ci.file.Documents(ci.columns, 0)
+	if err != nil {
+		ShowError(ci.App.Pages, "Error converting CSV", err)
+		return
+	}
+	report, err := ci.onDryRun(documents[ci.resumeFrom:], ci.upsertKeyField())
+	if err != nil {
+		ShowError(ci.App.Pages, "Error running dry run", err)
+		return
+	}
+
+	ci.info.SetText("Dry run: " + report.String())
+	ci.preview.Clear()
+	ci.preview.SetTitle(" " + config.Msg("modal.csvImport.preview.title") + " ")
+	if len(report.Samples) == 0 {
+		ci.preview.SetCell(0, 0, tview.NewTableCell("No existing documents are affected"))
+		return
+	}
+	ci.renderDocuments(report.Samples)
+}
+
+func (ci *CSVImport) importDocuments() {
+	documents, err := ci.file.Documents(ci.columns, 0)
+	if err != nil {
+		ShowError(ci.App.Pages, "Error converting CSV", err)
+		return
+	}
+	// empty or invalid values mean the default batch size and no limit
+	batchSize, _ := strconv.Atoi(ci.batch.GetText())
+	writesPerSecond, _ := strconv.Atoi(ci.rate.GetText())
+
+	opts := mongo.ImportOptions{
+		UpsertKey:       ci.upsertKeyField(),
+		BatchSize:       batchSize,
+		WritesPerSecond: writesPerSecond,
+		Skip:            ci.resumeFrom,
+	}
+	checkpoint := config.ImportCheckpoint{
+		Source:          ci.source,
+		SourceSize:      ci.sourceStat.Size(),
+		SourceModTime:   ci.sourceStat.ModTime(),
+		UpsertKey:       opts.UpsertKey,
+		BatchSize:       batchSize,
+		WritesPerSecond: writesPerSecond,
+		Done:            ci.resumeFrom,
+		Total:           len(documents),
+	}
+	for _, column := range ci.columns {
+		checkpoint.Columns = append(checkpoint.Columns, config.ImportColumn{
+			Column: column.Column,
+			Field:  column.Field,
+			Type:   string(column.Type),
+		})
+	}
+
+	ci.close()
+	if ci.onImport != nil {
+		ci.onImport(documents, opts, checkpoint)
+	}
+}
+
+// restore applies mappings and options of the interrupted import, if
+// the file wasn't changed since, so the import continues where it stopped
+func (ci *CSVImport) restore(checkpoint config.ImportCheckpoint) {
+	if checkpoint.SourceSize != ci.sourceStat.Size() || !checkpoint.SourceModTime.Equal(ci.sourceStat.ModTime()) ||
+		len(checkpoint.Columns) != len(ci.columns) || checkpoint.Done >= len(ci.file.Rows) {
+		return
+	}
+	for i, column := range checkpoint.Columns {
+		if column.Column != ci.columns[i].Column {
+			return
+		}
+	}
+
+	for i, column := range checkpoint.Columns {
+		ci.columns[i].Field = column.Field
+		ci.columns[i].Type = mongo.ColumnType(column.Type)
+		if checkpoint.UpsertKey != "" && column.Field == checkpoint.UpsertKey {
+			ci.upsertKey = i
+		}
+	}
+	if checkpoint.BatchSize > 0 {
+		ci.batch.SetText(strconv.Itoa(checkpoint.BatchSize))
+	}
+	if checkpoint.WritesPerSecond > 0 {
+		ci.rate.SetText(strconv.Itoa(checkpoint.WritesPerSecond))
+	}
+	ci.resumeFrom = checkpoint.Done
+}
+
+func (ci *CSVImport) close() {
+	ci.App.Pages.RemovePage(CSVImportModal)
+}
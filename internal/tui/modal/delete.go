@@ -38,9 +38,9 @@ func (d *Delete) init() error {
 }
 
 func (d *Delete) setStaticLayout() {
-	d.AddButtons([]string{"Delete", "Cancel"})
+	d.AddButtons([]string{config.Msg("button.delete"), config.Msg("button.cancel")})
 	d.SetBorder(true)
-	d.SetTitle(" Delete ")
+	d.SetTitle(" " + config.Msg("modal.delete.title") + " ")
 	d.SetBorderPadding(0, 0, 1, 1)
 }
 
@@ -70,5 +70,5 @@ func (d *Delete) handleEvents() {
 		case manager.StyleChanged:
 			d.setStyle()
 		}
-	})
+	}, manager.StyleChanged)
 }
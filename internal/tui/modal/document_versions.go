@@ -0,0 +1,158 @@
+package modal
+
+import (
+	"fmt"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/kopecmaciej/tview"
+	"github.com/kopecmaciej/vi-mongo/internal/config"
+	"github.com/kopecmaciej/vi-mongo/internal/mongo"
+	"github.com/kopecmaciej/vi-mongo/internal/tui/core"
+)
+
+const (
+	DocumentVersionsModal = "DocumentVersions"
+)
+
+// DocumentVersions lists previous versions of the document,
+// selected version is previewed and can be restored
+type DocumentVersions struct {
+	*core.BaseElement
+	*core.Flex
+
+	frame     *core.Flex
+	list      *core.List
+	preview   *core.TextView
+	versions  []mongo.DocumentVersion
+	onRestore func(version mongo.DocumentVersion)
+}
+
+func NewDocumentVersionsModal() *DocumentVersions {
+	dv := &DocumentVersions{
+		BaseElement: core.NewBaseElement(),
+		Flex:        core.NewFlex(),
+		frame:       core.NewFlex(),
+		list:        core.NewList(),
+		preview:     core.NewTextView(),
+	}
+
+	dv.SetIdentifier(DocumentVersionsModal)
+	dv.SetAfterInitFunc(dv.init)
+
+	return dv
+}
+
+func (dv *DocumentVersions) init() error {
+	dv.setStaticLayout()
+	dv.setStyle()
+	dv.setKeybindings()
+
+	return nil
+}
+
+func (dv *DocumentVersions) setStaticLayout() {
+	dv.frame.SetBorder(true)
+	dv.frame.SetTitle(" " + config.Msg("modal.versions.title") + " ")
+	dv.frame.SetTitleAlign(tview.AlignCenter)
+	dv.frame.SetDirection(tview.FlexRow)
+
+	dv.list.ShowSecondaryText(false)
+	dv.list.SetHighlightFullLine(true)
+	dv.list.SetChangedFunc(func(index int, _, _ string, _ rune) {
+		dv.renderPreview(index)
+	})
+	dv.list.SetSelectedFunc(func(index int, _, _ string, _ rune) {
+		dv.restore(index)
+	})
+
+	dv.preview.SetBorder(true)
+	dv.preview.SetTitle(" " + config.Msg("modal.versions.preview.title") + " ")
+	dv.preview.SetScrollable(true)
+
+	dv.frame.AddItem(dv.list, 0, 1, true)
+	dv.frame.AddItem(dv.preview, 0, 2, false)
+
+	// easy way to center the form
+	column := core.NewFlex()
+	column.SetDirection(tview.FlexRow)
+	column.AddItem(tview.NewBox(), 0, 1, false)
+	column.AddItem(dv.frame, 30, 0, true)
+	column.AddItem(tview.NewBox(), 0, 1, false)
+
+	dv.AddItem(tview.NewBox(), 0, 1, false)
+	dv.AddItem(column, 100, 0, true)
+	dv.AddItem(tview.NewBox(), 0, 1, false)
+}
+
+func (dv *DocumentVersions) setStyle() {
+	styles := dv.App.GetStyles()
+	dv.frame.SetStyle(styles)
+	dv.list.SetStyle(styles)
+	dv.list.SetMainTextColor(styles.Global.TextColor.Color())
+	dv.list.SetSelectedTextColor(styles.History.SelectedTextColor.Color())
+	dv.list.SetSelectedBackgroundColor(styles.History.SelectedBackgroundColor.Color())
+	dv.preview.SetStyle(styles)
+	dv.preview.SetTextColor(styles.Global.SecondaryTextColor.Color())
+}
+
+func (dv *DocumentVersions) setKeybindings() {
+	dv.list.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyEscape {
+			dv.close()
+			return nil
+		}
+		return event
+	})
+}
+
+// SetRestoreFunc sets the function called with the version to restore
+func (dv *DocumentVersions) SetRestoreFunc(onRestore func(version mongo.DocumentVersion)) {
+	dv.onRestore = onRestore
+}
+
+// Render shows versions of the document, newest first
+func (dv *DocumentVersions) Render(versions []mongo.DocumentVersion) {
+	dv.versions = versions
+	dv.list.Clear()
+	for _, version := range versions {
+		text := fmt.Sprintf("%s  %s", version.SavedAt.Local().Format("2006-01-02 15:04:05"), version.Operation)
+		dv.list.AddItem(text, "", 0, nil)
+	}
+	dv.renderPreview(0)
+
+	dv.App.Pages.AddPage(DocumentVersionsModal, dv, true, true)
+}
+
+func (dv *DocumentVersions) renderPreview(index int) {
+	if index < 0 || index >= len(dv.versions) {
+		dv.preview.SetText("")
+		return
+	}
+
+	jsoned, err := mongo.ParseBsonDocument(dv.versions[index].Document)
+	if err != nil {
+		dv.preview.SetText(err.Error())
+		return
+	}
+	indented, err := mongo.IndentJson(jsoned)
+	if err != nil {
+		dv.preview.SetText(jsoned)
+		return
+	}
+	dv.preview.SetText(indented.String())
+	dv.preview.ScrollToBeginning()
+}
+
+func (dv *DocumentVersions) restore(index int) {
+	if index < 0 || index >= len(dv.versions) {
+		return
+	}
+	dv.close()
+	if dv.onRestore != nil {
+		dv.onRestore(dv.versions[index])
+	}
+}
+
+func (dv *DocumentVersions) close() {
+	dv.App.Pages.RemovePage(DocumentVersionsModal)
+}
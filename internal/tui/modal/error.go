@@ -3,6 +3,7 @@ package modal
 import (
 	"github.com/gdamore/tcell/v2"
 	"github.com/kopecmaciej/tview"
+	"github.com/kopecmaciej/vi-mongo/internal/config"
 	"github.com/kopecmaciej/vi-mongo/internal/tui/core"
 	"github.com/rs/zerolog/log"
 )
@@ -23,12 +24,12 @@ func NewError(message string, err error) *tview.Modal {
 	}
 
 	errModal := tview.NewModal()
-	errModal.SetTitle(" Error ")
+	errModal.SetTitle(" " + config.Msg("modal.error.title") + " ")
 	errModal.SetBorderPadding(0, 0, 1, 1)
 	errModal.SetBackgroundColor(tview.Styles.ContrastBackgroundColor)
 	errModal.SetTextColor(tcell.ColorRed)
 	errModal.SetText(message)
-	errModal.AddButtons([]string{"Ok"})
+	errModal.AddButtons([]string{config.Msg("button.ok")})
 
 	return errModal
 }
@@ -39,7 +40,7 @@ func ShowError(page *core.Pages, message string, err error) {
 	errModal := NewError(message, err)
 
 	errModal.SetDoneFunc(func(buttonIndex int, buttonLabel string) {
-		if buttonLabel == "Ok" {
+		if buttonLabel == config.Msg("button.ok") {
 			page.RemovePage(ErrorModal)
 		}
 	})
@@ -49,7 +50,7 @@ func ShowError(page *core.Pages, message string, err error) {
 func ShowErrorAndSetFocus(page *core.Pages, message string, err error, setFocus func()) {
 	errModal := NewError(message, err)
 	errModal.SetDoneFunc(func(buttonIndex int, buttonLabel string) {
-		if buttonLabel == "Ok" {
+		if buttonLabel == config.Msg("button.ok") {
 			page.RemovePage(ErrorModal)
 			setFocus()
 		}
@@ -0,0 +1,126 @@
+package modal
+
+import (
+	"fmt"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/kopecmaciej/vi-mongo/internal/config"
+	"github.com/kopecmaciej/vi-mongo/internal/mongo"
+	"github.com/kopecmaciej/vi-mongo/internal/tui/core"
+	"github.com/kopecmaciej/vi-mongo/internal/tui/primitives"
+)
+
+const (
+	ExportFormatModal = "ExportFormat"
+)
+
+// ExportFormat lets the user pick the format documents are exported in,
+// external exporters from the config are listed after built-in formats
+type ExportFormat struct {
+	*core.BaseElement
+	*primitives.ListModal
+
+	exporters  []config.ExporterConfig
+	onSelect   func(format mongo.ExportFormat)
+	onExporter func(exporter config.ExporterConfig)
+}
+
+func NewExportFormatModal() *ExportFormat {
+	ef := &ExportFormat{
+		BaseElement: core.NewBaseElement(),
+		ListModal:   primitives.NewListModal(),
+	}
+
+	ef.SetIdentifier(ExportFormatModal)
+	ef.SetAfterInitFunc(ef.init)
+
+	return ef
+}
+
+func (ef *ExportFormat) init() error {
+	ef.setStyle()
+	ef.setKeybindings()
+
+	return nil
+}
+
+func (ef *ExportFormat) setStyle() {
+	style := ef.App.GetStyles().History
+	globalBackground := ef.App.GetStyles().Global.BackgroundColor.Color()
+
+	ef.SetBorder(true)
+	ef.ShowSecondaryText(false)
+	ef.SetBorderPadding(0, 0, 1, 1)
+
+	mainStyle := tcell.StyleDefault.
+		Foreground(style.TextColor.Color()).
+		Background(globalBackground)
+	ef.SetMainTextStyle(mainStyle)
+
+	selectedStyle := tcell.StyleDefault.
+		Foreground(style.SelectedTextColor.Color()).
+		Background(style.SelectedBackgroundColor.Color())
+	ef.SetSelectedStyle(selectedStyle)
+}
+
+func (ef *ExportFormat) setKeybindings() {
+	ef.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyEscape:
+			ef.close()
+			return nil
+		case tcell.KeyEnter:
+			ef.selectFormat()
+			return nil
+		}
+		return event
+	})
+}
+
+// SetSelectFunc sets the function called with the selected format
+func (ef *ExportFormat) SetSelectFunc(onSelect func(format mongo.ExportFormat)) {
+	ef.onSelect = onSelect
+}
+
+// SetExporterFunc sets the function called with the selected external exporter
+func (ef *ExportFormat) SetExporterFunc(onExporter func(exporter config.ExporterConfig)) {
+	ef.onExporter = onExporter
+}
+
+// Render shows supported formats and external exporters,
+// title describes what's exported
+func (ef *ExportFormat) Render(title string, exporters []config.ExporterConfig) {
+	ef.SetTitle(fmt.Sprintf(" %s ", title))
+	ef.exporters = exporters
+	ef.Clear()
+	for _, format := range mongo.ExportFormats {
+		ef.AddItem(string(format), "", 0, nil)
+	}
+	for _, exporter := range exporters {
+		ef.AddItem(exporter.Name+" (to file)", "", 0, nil)
+	}
+	ef.SetCurrentItem(0)
+
+	ef.App.Pages.AddPage(ExportFormatModal, ef, true, true)
+}
+
+func (ef *ExportFormat) selectFormat() {
+	index := ef.GetCurrentItem()
+	if index < 0 || index >= len(mongo.ExportFormats)+len(ef.exporters) {
+		return
+	}
+	ef.close()
+	if index < len(mongo.ExportFormats) {
+		if ef.onSelect != nil {
+			ef.onSelect(mongo.ExportFormats[index])
+		}
+		return
+	}
+	if ef.onExporter != nil {
+		ef.onExporter(ef.exporters[index-len(mongo.ExportFormats)])
+	}
+}
+
+func (ef *ExportFormat) close() {
+	ef.App.Pages.RemovePage(ExportFormatModal)
+}
@@ -0,0 +1,207 @@
+package modal
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/kopecmaciej/tview"
+	"github.com/kopecmaciej/vi-mongo/internal/config"
+	"github.com/kopecmaciej/vi-mongo/internal/mongo"
+	"github.com/kopecmaciej/vi-mongo/internal/tui/core"
+	"github.com/kopecmaciej/vi-mongo/internal/util"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	FanOutModal = "FanOut"
+)
+
+// FanOut runs a filter against many collections of the database
+// with the same shape, e.g. per-tenant collections, and shows
+// merged results with the collection of every document
+type FanOut struct {
+	*core.BaseElement
+	*core.Flex
+
+	frame       *core.Flex
+	form        *core.Form
+	collections *tview.InputField
+	filter      *tview.InputField
+	results     *core.Table
+	info        *core.TextView
+	onRun       func(patterns, filter string)
+}
+
+func NewFanOutModal() *FanOut {
+	fo := &FanOut{
+		BaseElement: core.NewBaseElement(),
+		Flex:        core.NewFlex(),
+		frame:       core.NewFlex(),
+		form:        core.NewForm(),
+		collections: tview.NewInputField(),
+		filter:      tview.NewInputField(),
+		results:     core.NewTable(),
+		info:        core.NewTextView(),
+	}
+
+	fo.SetIdentifier(FanOutModal)
+	fo.SetAfterInitFunc(fo.init)
+
+	return fo
+}
+
+func (fo *FanOut) init() error {
+	fo.setStaticLayout()
+	fo.setStyle()
+	fo.setKeybindings()
+
+	return nil
+}
+
+func (fo *FanOut) setStaticLayout() {
+	fo.frame.SetBorder(true)
+	fo.frame.SetTitle(" " + config.Msg("modal.fanOut.title") + " ")
+	fo.frame.SetTitleAlign(tview.AlignCenter)
+	fo.frame.SetDirection(tview.FlexRow)
+
+	fo.collections.SetLabel("Collections")
+	fo.collections.SetPlaceholder("tenant_*, shared")
+	fo.filter.SetLabel("Filter")
+	fo.filter.SetPlaceholder("{}")
+	fo.form.AddFormItem(fo.collections)
+	fo.form.AddFormItem(fo.filter)
+	fo.form.AddButton(config.Msg("button.run"), fo.run)
+	fo.form.SetButtonsAlign(tview.AlignCenter)
+
+	fo.results.SetFixed(1, 0)
+	fo.results.SetSelectable(true, false)
+	fo.results.SetBorder(true)
+
+	fo.frame.AddItem(fo.form, 7, 0, true)
+	fo.frame.AddItem(fo.info, 1, 0, false)
+	fo.frame.AddItem(fo.results, 0, 1, false)
+
+	fo.AddItem(tview.NewBox(), 0, 1, false)
+	fo.AddItem(fo.frame, 0, 8, true)
+	fo.AddItem(tview.NewBox(), 0, 1, false)
+}
+
+func (fo *FanOut) setStyle() {
+	styles := fo.App.GetStyles()
+	fo.frame.SetStyle(styles)
+	fo.form.SetStyle(styles)
+	fo.form.SetLabelColor(styles.InputBar.LabelColor.Color())
+	fo.form.SetFieldTextColor(styles.InputBar.InputColor.Color())
+	fo.form.SetFieldBackgroundColor(styles.Global.ContrastBackgroundColor.Color())
+	for _, input := range []*tview.InputField{fo.collections, fo.filter} {
+		input.SetPlaceholderTextColor(styles.Global.SecondaryTextColor.Color())
+	}
+	fo.results.SetStyle(styles)
+	fo.info.SetStyle(styles)
+	fo.info.SetTextColor(styles.Content.StatusTextColor.Color())
+}
+
+func (fo *FanOut) setKeybindings() {
+	fo.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyEscape:
+			fo.close()
+			return nil
+		case tcell.KeyTab:
+			// form handles Tab itself, unless the results are focused
+			if fo.results.HasFocus() {
+				fo.App.SetFocus(fo.form)
+				return nil
+			}
+		}
+		return event
+	})
+	fo.form.SetCancelFunc(fo.close)
+	fo.filter.SetDoneFunc(func(key tcell.Key) {
+		if key == tcell.KeyEnter {
+			fo.run()
+		}
+	})
+}
+
+// SetRunFunc sets the function called with collection patterns and
+// the filter, results are expected to be passed to RenderResults
+func (fo *FanOut) SetRunFunc(onRun func(patterns, filter string)) {
+	fo.onRun = onRun
+}
+
+// Render shows the modal with the filter prefilled
+func (fo *FanOut) Render(patterns, filter string) {
+	if fo.collections.GetText() == "" {
+		fo.collections.SetText(patterns)
+	}
+	fo.filter.SetText(filter)
+	fo.form.SetFocus(0)
+
+	fo.App.Pages.AddPage(FanOutModal, fo, true, true)
+}
+
+// RenderResults shows merged documents, the collection of
+// the document is in the first column
+func (fo *FanOut) RenderResults(colls []string, documents []primitive.M) {
+	fo.results.Clear()
+	fo.info.SetText(fmt.Sprintf("Documents: %d, Collections: %d (%s)", len(documents), len(colls), strings.Join(colls, ", ")))
+	if len(documents) == 0 {
+		fo.results.SetCell(0, 0, tview.NewTableCell("No documents found"))
+		return
+	}
+
+	style := fo.App.GetStyles().Content
+	headers, fields := documentColumns(documents, style.ColumnTypeColor.Color().String(), mongo.FanOutCollectionField)
+
+	for col, header := range headers {
+		fo.results.SetCell(0, col, tview.NewTableCell(header).
+			SetTextColor(style.ColumnKeyColor.Color()).
+			SetBackgroundColor(style.HeaderRowBackgroundColor.Color()).
+			SetSelectable(false).
+			SetAlign(tview.AlignCenter))
+	}
+	for row, doc := range documents {
+		for col, field := range fields {
+			var text string
+			if val, ok := doc[field]; ok {
+				text = util.GetValueByType(val)
+			}
+			fo.results.SetCell(row+1, col, tview.NewTableCell(util.TruncateByWidth(text, 30)).
+				SetTextColor(style.CellTextColor.Color()).
+				SetMaxWidth(30))
+		}
+	}
+	fo.results.Select(1, 0)
+	fo.results.ScrollToBeginning()
+	fo.App.SetFocus(fo.results)
+}
+
+func (fo *FanOut) run() {
+	if fo.onRun != nil {
+		fo.onRun(fo.collections.GetText(), fo.filter.GetText())
+	}
+}
+
+func (fo *FanOut) close() {
+	fo.App.Pages.RemovePage(FanOutModal)
+}
+
+// documentColumns returns headers with types and fields of the columns
+// of the documents, the first field is moved to the front if it exists
+func documentColumns(documents []primitive.M, typeColor, first string) (headers, fields []string) {
+	headers = util.GetSortedKeysWithTypes(documents, typeColor)
+	fields = make([]string, 0, len(headers))
+	for _, header := range headers {
+		fields = append(fields, strings.Split(header, " ")[0])
+	}
+	for i, field := range fields {
+		if field == first {
+			headers = append([]string{headers[i]}, append(headers[:i:i], headers[i+1:]...)...)
+			fields = append([]string{field}, append(fields[:i:i], fields[i+1:]...)...)
+			break
+		}
+	}
+	return headers, fields
+}
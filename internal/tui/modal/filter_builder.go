@@ -0,0 +1,240 @@
+package modal
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/kopecmaciej/tview"
+	"github.com/kopecmaciej/vi-mongo/internal/config"
+	"github.com/kopecmaciej/vi-mongo/internal/mongo"
+	"github.com/kopecmaciej/vi-mongo/internal/tui/core"
+)
+
+const (
+	FilterBuilderModal = "FilterBuilder"
+
+	combineAnd = "AND"
+	combineOr  = "OR"
+)
+
+// FilterBuilder is a form that builds the filter from conditions, where
+// field is picked from the sampled documents and operator from the list,
+// for users not fluent in MongoDB syntax
+type FilterBuilder struct {
+	*core.BaseElement
+	*core.Flex
+
+	form       *core.Form
+	field      *tview.DropDown
+	operator   *tview.DropDown
+	value      *tview.InputField
+	combine    *tview.DropDown
+	preview    *tview.TextView
+	fields     []string
+	types      map[string]string
+	conditions []mongo.FilterCondition
+	onApply    func(filter string)
+}
+
+func NewFilterBuilderModal() *FilterBuilder {
+	fb := &FilterBuilder{
+		BaseElement: core.NewBaseElement(),
+		Flex:        core.NewFlex(),
+		form:        core.NewForm(),
+		field:       tview.NewDropDown(),
+		operator:    tview.NewDropDown(),
+		value:       tview.NewInputField(),
+		combine:     tview.NewDropDown(),
+		preview:     tview.NewTextView(),
+	}
+
+	fb.SetIdentifier(FilterBuilderModal)
+	fb.SetAfterInitFunc(fb.init)
+
+	return fb
+}
+
+func (fb *FilterBuilder) init() error {
+	fb.setStaticLayout()
+	fb.setStyle()
+	fb.setKeybindings()
+
+	return nil
+}
+
+func (fb *FilterBuilder) setStaticLayout() {
+	fb.form.SetBorder(true)
+	fb.form.SetTitle(" " + config.Msg("modal.filter.title") + " ")
+	fb.form.SetTitleAlign(tview.AlignCenter)
+	fb.form.SetButtonsAlign(tview.AlignCenter)
+
+	fb.field.SetLabel("Field")
+
+	operators := make([]string, 0, len(mongo.FilterOperators))
+	for _, op := range mongo.FilterOperators {
+		operators = append(operators, fmt.Sprintf("%s (%s)", op.Label, op.Operator))
+	}
+	fb.operator.SetLabel("Operator")
+	fb.operator.SetOptions(operators, nil)
+
+	fb.value.SetLabel("Value")
+	fb.value.SetFieldWidth(40)
+
+	fb.combine.SetLabel("Combine with")
+	fb.combine.SetOptions([]string{combineAnd, combineOr}, func(text string, index int) {
+		fb.renderPreview()
+	})
+
+	fb.preview.SetLabel("Filter")
+	fb.preview.SetSize(3, 0)
+	fb.preview.SetWrap(true)
+
+	fb.form.AddFormItem(fb.field)
+	fb.form.AddFormItem(fb.operator)
+	fb.form.AddFormItem(fb.value)
+	fb.form.AddFormItem(fb.combine)
+	fb.form.AddFormItem(fb.preview)
+
+	fb.form.AddButton(config.Msg("button.add"), func() { fb.addCondition() })
+	fb.form.AddButton(config.Msg("button.removeLast"), fb.removeCondition)
+	fb.form.AddButton(config.Msg("button.apply"), fb.apply)
+	fb.form.AddButton(config.Msg("button.cancel"), fb.close)
+
+	// easy way to center the form
+	column := core.NewFlex()
+	column.SetDirection(tview.FlexRow)
+	column.AddItem(tview.NewBox(), 0, 1, false)
+	column.AddItem(fb.form, 15, 0, true)
+	column.AddItem(tview.NewBox(), 0, 1, false)
+
+	fb.AddItem(tview.NewBox(), 0, 1, false)
+	fb.AddItem(column, 70, 0, true)
+	fb.AddItem(tview.NewBox(), 0, 1, false)
+}
+
+func (fb *FilterBuilder) setStyle() {
+	styles := fb.App.GetStyles()
+	fb.form.SetStyle(styles)
+	fb.form.SetLabelColor(styles.InputBar.LabelColor.Color())
+	fb.form.SetFieldTextColor(styles.InputBar.InputColor.Color())
+	fb.form.SetFieldBackgroundColor(styles.Global.ContrastBackgroundColor.Color())
+	fb.preview.SetTextColor(styles.Global.SecondaryTextColor.Color())
+	fb.preview.SetBackgroundColor(styles.Global.BackgroundColor.Color())
+}
+
+func (fb *FilterBuilder) setKeybindings() {
+	fb.form.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyEscape {
+			fb.close()
+			return nil
+		}
+		return event
+	})
+}
+
+// SetApplyFunc sets the function called with the built filter
+func (fb *FilterBuilder) SetApplyFunc(onApply func(filter string)) {
+	fb.onApply = onApply
+}
+
+// Render shows the builder with fields of given types, as returned
+// by mongo.SampleFieldTypes
+func (fb *FilterBuilder) Render(types map[string]string) {
+	fb.types = types
+	fb.fields = make([]string, 0, len(types))
+	for field := range types {
+		fb.fields = append(fb.fields, field)
+	}
+	sort.Strings(fb.fields)
+
+	fb.conditions = nil
+	fb.field.SetOptions(fb.fields, fb.fieldSelected)
+	fb.field.SetCurrentOption(0)
+	fb.operator.SetCurrentOption(0)
+	fb.combine.SetCurrentOption(0)
+	fb.value.SetText("")
+	fb.renderPreview()
+	fb.form.SetFocus(0)
+
+	fb.App.Pages.AddPage(FilterBuilderModal, fb, true, true)
+}
+
+// fieldSelected shows hint how the value of selected field should be typed
+func (fb *FilterBuilder) fieldSelected(field string, index int) {
+	fb.value.SetPlaceholder(mongo.FilterValueHint(fb.types[field]))
+}
+
+// currentCondition returns condition from the form fields
+func (fb *FilterBuilder) currentCondition() mongo.FilterCondition {
+	_, field := fb.field.GetCurrentOption()
+	index, _ := fb.operator.GetCurrentOption()
+	operator := mongo.FilterOperators[0].Operator
+	if index >= 0 {
+		operator = mongo.FilterOperators[index].Operator
+	}
+
+	return mongo.FilterCondition{
+		Field:    field,
+		Operator: operator,
+		Value:    fb.value.GetText(),
+		Type:     fb.types[field],
+	}
+}
+
+func (fb *FilterBuilder) addCondition() bool {
+	condition := fb.currentCondition()
+	if _, err := condition.Query(); err != nil {
+		ShowError(fb.App.Pages, "Invalid condition", err)
+		return false
+	}
+
+	fb.conditions = append(fb.conditions, condition)
+	fb.value.SetText("")
+	fb.renderPreview()
+	return true
+}
+
+func (fb *FilterBuilder) removeCondition() {
+	if len(fb.conditions) == 0 {
+		return
+	}
+	fb.conditions = fb.conditions[:len(fb.conditions)-1]
+	fb.renderPreview()
+}
+
+// apply builds the filter, condition which is filled in
+// but not added yet is added as well
+func (fb *FilterBuilder) apply() {
+	if fb.value.GetText() != "" && !fb.addCondition() {
+		return
+	}
+
+	filter, err := fb.buildFilter()
+	if err != nil {
+		ShowError(fb.App.Pages, "Error building filter", err)
+		return
+	}
+
+	fb.close()
+	if fb.onApply != nil {
+		fb.onApply(filter)
+	}
+}
+
+func (fb *FilterBuilder) buildFilter() (string, error) {
+	_, combine := fb.combine.GetCurrentOption()
+	return mongo.BuildFilter(fb.conditions, combine == combineOr)
+}
+
+func (fb *FilterBuilder) renderPreview() {
+	filter, err := fb.buildFilter()
+	if err != nil {
+		filter = err.Error()
+	}
+	fb.preview.SetText(filter)
+}
+
+func (fb *FilterBuilder) close() {
+	fb.App.Pages.RemovePage(FilterBuilderModal)
+}
@@ -0,0 +1,274 @@
+package modal
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/kopecmaciej/tview"
+	"github.com/kopecmaciej/vi-mongo/internal/config"
+	"github.com/kopecmaciej/vi-mongo/internal/mongo"
+	"github.com/kopecmaciej/vi-mongo/internal/tui/core"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	GeoQueryModal = "GeoQuery"
+	GeoIndexModal = "GeoIndex"
+
+	geoWithin = "$geoWithin (any order)"
+	geoNear   = "$near (nearest first, needs 2dsphere index)"
+
+	createIndexButton  = "button.createIndex"
+	withoutIndexButton = "button.runWithoutIndex"
+)
+
+// GeoQuery builds the filter of documents which GeoJSON field
+// is within the radius from the center point
+type GeoQuery struct {
+	*core.BaseElement
+	*core.Flex
+
+	form      *core.Form
+	field     *tview.DropDown
+	operator  *tview.DropDown
+	longitude *tview.InputField
+	latitude  *tview.InputField
+	radius    *tview.InputField
+	preview   *tview.TextView
+	confirm   *core.Modal
+	onApply   func(filter string)
+	hasIndex  func(field string) (bool, error)
+	addIndex  func(field string) error
+}
+
+func NewGeoQueryModal() *GeoQuery {
+	gq := &GeoQuery{
+		BaseElement: core.NewBaseElement(),
+		Flex:        core.NewFlex(),
+		form:        core.NewForm(),
+		field:       tview.NewDropDown(),
+		operator:    tview.NewDropDown(),
+		longitude:   tview.NewInputField(),
+		latitude:    tview.NewInputField(),
+		radius:      tview.NewInputField(),
+		preview:     tview.NewTextView(),
+		confirm:     core.NewModal(),
+	}
+
+	gq.SetIdentifier(GeoQueryModal)
+	gq.SetAfterInitFunc(gq.init)
+
+	return gq
+}
+
+func (gq *GeoQuery) init() error {
+	gq.setStaticLayout()
+	gq.setStyle()
+	gq.setKeybindings()
+
+	return nil
+}
+
+func (gq *GeoQuery) setStaticLayout() {
+	gq.form.SetBorder(true)
+	gq.form.SetTitle(" " + config.Msg("modal.geoQuery.title") + " ")
+	gq.form.SetTitleAlign(tview.AlignCenter)
+	gq.form.SetButtonsAlign(tview.AlignCenter)
+
+	gq.field.SetLabel("GeoJSON field")
+	gq.operator.SetLabel("Operator")
+	gq.operator.SetOptions([]string{geoWithin, geoNear}, func(string, int) {
+		gq.renderPreview()
+	})
+
+	changed := func(string) { gq.renderPreview() }
+	gq.longitude.SetLabel("Longitude")
+	gq.longitude.SetFieldWidth(20)
+	gq.longitude.SetAcceptanceFunc(tview.InputFieldFloat)
+	gq.longitude.SetChangedFunc(changed)
+	gq.latitude.SetLabel("Latitude")
+	gq.latitude.SetFieldWidth(20)
+	gq.latitude.SetAcceptanceFunc(tview.InputFieldFloat)
+	gq.latitude.SetChangedFunc(changed)
+	gq.radius.SetLabel("Radius in meters")
+	gq.radius.SetFieldWidth(20)
+	gq.radius.SetAcceptanceFunc(tview.InputFieldFloat)
+	gq.radius.SetChangedFunc(changed)
+
+	gq.preview.SetLabel("Filter")
+	gq.preview.SetSize(4, 0)
+	gq.preview.SetWrap(true)
+
+	gq.form.AddFormItem(gq.field)
+	gq.form.AddFormItem(gq.operator)
+	gq.form.AddFormItem(gq.longitude)
+	gq.form.AddFormItem(gq.latitude)
+	gq.form.AddFormItem(gq.radius)
+	gq.form.AddFormItem(gq.preview)
+
+	gq.form.AddButton(config.Msg("button.apply"), gq.apply)
+	gq.form.AddButton(config.Msg("button.cancel"), gq.close)
+
+	gq.confirm.SetBorder(true)
+	gq.confirm.SetTitle(" " + config.Msg("modal.geoQuery.confirm.title") + " ")
+
+	// easy way to center the form
+	column := core.NewFlex()
+	column.SetDirection(tview.FlexRow)
+	column.AddItem(tview.NewBox(), 0, 1, false)
+	column.AddItem(gq.form, 18, 0, true)
+	column.AddItem(tview.NewBox(), 0, 1, false)
+
+	gq.AddItem(tview.NewBox(), 0, 1, false)
+	gq.AddItem(column, 80, 0, true)
+	gq.AddItem(tview.NewBox(), 0, 1, false)
+}
+
+func (gq *GeoQuery) setStyle() {
+	styles := gq.App.GetStyles()
+	gq.form.SetStyle(styles)
+	gq.form.SetLabelColor(styles.InputBar.LabelColor.Color())
+	gq.form.SetFieldTextColor(styles.InputBar.InputColor.Color())
+	gq.form.SetFieldBackgroundColor(styles.Global.ContrastBackgroundColor.Color())
+	gq.preview.SetTextColor(styles.Global.SecondaryTextColor.Color())
+	gq.preview.SetBackgroundColor(styles.Global.BackgroundColor.Color())
+	gq.confirm.SetStyle(styles)
+}
+
+func (gq *GeoQuery) setKeybindings() {
+	gq.form.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyEscape {
+			gq.close()
+			return nil
+		}
+		return event
+	})
+}
+
+// SetApplyFunc sets the function called with the built filter
+func (gq *GeoQuery) SetApplyFunc(onApply func(filter string)) {
+	gq.onApply = onApply
+}
+
+// SetIndexFuncs sets functions which check whether the field has
+// the 2dsphere index and create it, the query is applied without
+// the check if they're not set
+func (gq *GeoQuery) SetIndexFuncs(hasIndex func(field string) (bool, error), addIndex func(field string) error) {
+	gq.hasIndex = hasIndex
+	gq.addIndex = addIndex
+}
+
+// Render shows the query of GeoJSON fields, field and center
+// are prefilled from the selected document if it has one
+func (gq *GeoQuery) Render(fields []string, field string, center *mongo.GeoJSON) {
+	gq.field.SetOptions(fields, func(string, int) {
+		gq.renderPreview()
+	})
+	gq.field.SetCurrentOption(max(slices.Index(fields, field), 0))
+	gq.operator.SetCurrentOption(0)
+	gq.longitude.SetText("")
+	gq.latitude.SetText("")
+	if center != nil {
+		lng, lat := center.Center()
+		gq.longitude.SetText(strconv.FormatFloat(lng, 'f', -1, 64))
+		gq.latitude.SetText(strconv.FormatFloat(lat, 'f', -1, 64))
+	}
+	gq.radius.SetText("1000")
+	gq.renderPreview()
+	gq.form.SetFocus(0)
+
+	gq.App.Pages.AddPage(GeoQueryModal, gq, true, true)
+}
+
+// query returns the query from the form, empty or invalid
+// numbers are 0 and are reported by the filter
+func (gq *GeoQuery) query() mongo.GeoQuery {
+	_, field := gq.field.GetCurrentOption()
+	_, operator := gq.operator.GetCurrentOption()
+	parse := func(input *tview.InputField) float64 {
+		n, _ := strconv.ParseFloat(strings.TrimSpace(input.GetText()), 64)
+		return n
+	}
+	return mongo.GeoQuery{
+		Field:     field,
+		Longitude: parse(gq.longitude),
+		Latitude:  parse(gq.latitude),
+		Radius:    parse(gq.radius),
+		Near:      operator == geoNear,
+	}
+}
+
+func (gq *GeoQuery) renderPreview() {
+	filter, err := gq.query().Filter()
+	if err != nil {
+		filter = err.Error()
+	}
+	gq.preview.SetText(filter)
+}
+
+func (gq *GeoQuery) apply() {
+	query := gq.query()
+	filter, err := query.Filter()
+	if err != nil {
+		ShowError(gq.App.Pages, "Invalid geo query", err)
+		return
+	}
+
+	if gq.hasIndex != nil {
+		indexed, err := gq.hasIndex(query.Field)
+		if err != nil {
+			// indexes may be not listed e.g. without the privilege,
+			// the server reports it if the query needs one
+			log.Warn().Err(err).Str("field", query.Field).Msg("Error checking 2dsphere index")
+		} else if !indexed {
+			gq.confirmIndex(query, filter)
+			return
+		}
+	}
+	gq.run(filter)
+}
+
+// confirmIndex warns that the field has no 2dsphere index and offers
+// to create it, $geoWithin can be still run by scanning the collection
+func (gq *GeoQuery) confirmIndex(query mongo.GeoQuery, filter string) {
+	text := fmt.Sprintf("%s has no 2dsphere index, $geoWithin will scan the whole collection.", query.Field)
+	buttons := []string{config.Msg(createIndexButton), config.Msg(withoutIndexButton), config.Msg("button.cancel")}
+	if query.Near {
+		text = fmt.Sprintf("%s has no 2dsphere index, which $near requires.", query.Field)
+		buttons = []string{config.Msg(createIndexButton), config.Msg("button.cancel")}
+	}
+	gq.confirm.SetText(text + " Create the index?")
+	gq.confirm.ClearButtons()
+	gq.confirm.AddButtons(buttons)
+	gq.confirm.SetDoneFunc(func(_ int, buttonLabel string) {
+		gq.App.Pages.RemovePage(GeoIndexModal)
+		switch buttonLabel {
+		case config.Msg(createIndexButton):
+			if gq.addIndex == nil {
+				return
+			}
+			if err := gq.addIndex(query.Field); err != nil {
+				ShowError(gq.App.Pages, "Error creating 2dsphere index", err)
+				return
+			}
+			gq.run(filter)
+		case config.Msg(withoutIndexButton):
+			gq.run(filter)
+		}
+	})
+	gq.App.Pages.AddPage(GeoIndexModal, gq.confirm, true, true)
+}
+
+func (gq *GeoQuery) run(filter string) {
+	gq.close()
+	if gq.onApply != nil {
+		gq.onApply(filter)
+	}
+}
+
+func (gq *GeoQuery) close() {
+	gq.App.Pages.RemovePage(GeoQueryModal)
+}
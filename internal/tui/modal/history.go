@@ -1,22 +1,22 @@
 package modal
 
 import (
-	"os"
+	"fmt"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gdamore/tcell/v2"
 	"github.com/kopecmaciej/vi-mongo/internal/config"
 	"github.com/kopecmaciej/vi-mongo/internal/manager"
 	"github.com/kopecmaciej/vi-mongo/internal/tui/core"
 	"github.com/kopecmaciej/vi-mongo/internal/tui/primitives"
-	"github.com/kopecmaciej/vi-mongo/internal/util"
 )
 
 const (
-	HistoryModal = "History"
-	QueryBar     = "QueryBar"
-
-	maxHistory = 10
+	HistoryModal      = "History"
+	HistoryPruneModal = "HistoryPrune"
+	QueryBar          = "QueryBar"
 )
 
 // History is a modal with history of queries
@@ -24,13 +24,22 @@ type History struct {
 	*core.BaseElement
 	*primitives.ListModal
 
-	style *config.HistoryStyle
+	pruneModal *primitives.InputModal
+
+	style   *config.HistoryStyle
+	entries []config.HistoryEntry
+	// visible holds entries in the order they are displayed,
+	// nil for headers of the pinned and other entries
+	visible []*config.HistoryEntry
+	sortBy  config.HistorySort
 }
 
 func NewHistoryModal() *History {
 	h := &History{
 		BaseElement: core.NewBaseElement(),
 		ListModal:   primitives.NewListModal(),
+		pruneModal:  primitives.NewInputModal(),
+		sortBy:      config.SortByRecency,
 	}
 
 	h.SetIdentifier(HistoryModal)
@@ -44,6 +53,10 @@ func (h *History) init() error {
 	h.setStyle()
 	h.setKeybindings()
 
+	h.EnableSearch(" Search: ", func(text string) {
+		h.renderEntries()
+	})
+
 	return nil
 }
 
@@ -51,30 +64,77 @@ func (h *History) setStyle() {
 	h.style = &h.App.GetStyles().History
 	globalBackground := h.App.GetStyles().Global.BackgroundColor.Color()
 
-	h.SetTitle(" History ")
+	h.setTitle()
 	h.SetBorder(true)
-	h.ShowSecondaryText(false)
+	h.ShowSecondaryText(true)
 	mainStyle := tcell.StyleDefault.
 		Foreground(h.style.TextColor.Color()).
 		Background(globalBackground)
 	h.SetMainTextStyle(mainStyle)
 
+	secondaryStyle := tcell.StyleDefault.
+		Foreground(h.App.GetStyles().Global.SecondaryTextColor.Color()).
+		Background(globalBackground).
+		Italic(true)
+	h.SetSecondaryTextStyle(secondaryStyle)
+
 	selectedStyle := tcell.StyleDefault.
 		Foreground(h.style.SelectedTextColor.Color()).
 		Background(h.style.SelectedBackgroundColor.Color())
 	h.SetSelectedStyle(selectedStyle)
+
+	h.SetSearchStyle(mainStyle, mainStyle.Background(h.App.GetStyles().Global.ContrastBackgroundColor.Color()))
+
+	globalStyle := h.App.GetStyles()
+	h.pruneModal.SetBorder(true)
+	h.pruneModal.SetTitle(" " + config.Msg("modal.history.prune.title") + " ")
+	h.pruneModal.SetLabel("Remove entries not used for more than N days")
+	h.pruneModal.SetBorderColor(globalStyle.Global.BorderColor.Color())
+	h.pruneModal.SetBackgroundColor(globalStyle.Global.BackgroundColor.Color())
+	h.pruneModal.SetFieldTextColor(globalStyle.Others.ModalTextColor.Color())
+	h.pruneModal.SetFieldBackgroundColor(globalStyle.Global.ContrastBackgroundColor.Color())
+	h.pruneModal.SetInputCapture(h.pruneInputCapture)
+}
+
+func (h *History) setTitle() {
+	h.SetTitle(fmt.Sprintf(" History (by %s) ", h.sortBy))
 }
 
 func (h *History) setKeybindings() {
 	keys := h.App.GetKeys()
 	h.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if h.IsSearching() {
+			switch event.Key() {
+			case tcell.KeyEnter, tcell.KeyEsc, tcell.KeyDown:
+				h.SetSearching(false)
+				return nil
+			}
+			return event
+		}
+
 		switch {
 		case keys.Contains(keys.History.AcceptEntry, event.Name()):
+			if h.selected() == nil {
+				return nil
+			}
 			return h.sendEventAndClose(event)
 		case keys.Contains(keys.History.CloseHistory, event.Name()):
 			return h.sendEventAndClose(event)
 		case keys.Contains(keys.History.ClearHistory, event.Name()):
 			return h.clearHistory()
+		case keys.Contains(keys.History.Search, event.Name()):
+			h.SetSearching(true)
+			return nil
+		case keys.Contains(keys.History.ToggleSort, event.Name()):
+			h.sortBy = h.sortBy.Next()
+			h.setTitle()
+			h.renderEntries()
+			return nil
+		case keys.Contains(keys.History.TogglePin, event.Name()):
+			return h.togglePinned()
+		case keys.Contains(keys.History.PruneHistory, event.Name()):
+			h.App.Pages.AddPage(HistoryPruneModal, h.pruneModal, true, true)
+			return nil
 		}
 		return event
 	})
@@ -89,7 +149,7 @@ func (h *History) sendEventAndClose(event *tcell.EventKey) *tcell.EventKey {
 }
 
 func (h *History) clearHistory() *tcell.EventKey {
-	err := os.WriteFile(getHisotryFilePath(), []byte{}, 0644)
+	err := config.ClearHistory()
 	if err != nil {
 		ShowError(h.App.Pages, "Failed to clear history", err)
 	}
@@ -99,95 +159,157 @@ func (h *History) clearHistory() *tcell.EventKey {
 	return nil
 }
 
-// Render loads history from file and renders it
-func (h *History) Render() {
-	h.Clear()
+func (h *History) togglePinned() *tcell.EventKey {
+	entry := h.selected()
+	if entry == nil {
+		return nil
+	}
+	if err := config.TogglePinned(entry.Query); err != nil {
+		ShowError(h.App.Pages, "Failed to pin history entry", err)
+		return nil
+	}
 
-	history, err := h.loadHistory()
+	entries, err := config.LoadHistory()
 	if err != nil {
 		ShowError(h.App.Pages, "Failed to load history", err)
-		return
+		return nil
 	}
+	h.entries = entries
+	h.renderEntries()
 
-	// load in reverse order
-	for i := len(history) - 1; i >= 0; i-- {
-		rune := 57 - i
-		entry := history[i]
-		h.AddItem(entry, "", int32(rune), nil)
-	}
+	return nil
+}
 
-	h.App.Pages.AddPage(h.GetIdentifier(), h, true, true)
+func (h *History) pruneInputCapture(event *tcell.EventKey) *tcell.EventKey {
+	switch event.Key() {
+	case tcell.KeyEnter:
+		h.pruneHistory()
+		return nil
+	case tcell.KeyEscape:
+		h.closePruneModal()
+		return nil
+	}
+	return event
 }
 
-// SaveToHistory saves text to history file, if it's not already there.
-// It will overwrite oldest entry if history is full.
-func (h *History) SaveToHistory(text string) error {
-	history, err := h.loadHistory()
+func (h *History) pruneHistory() {
+	days, err := strconv.Atoi(strings.TrimSpace(h.pruneModal.GetText()))
+	if err == nil && days <= 0 {
+		err = fmt.Errorf("got %d", days)
+	}
 	if err != nil {
-		return err
+		ShowError(h.App.Pages, "Number of days must be a positive number", err)
+		return
 	}
+	h.closePruneModal()
 
-	var updatedHistory []string
-	for _, line := range history {
-		if line != text {
-			updatedHistory = append(updatedHistory, line)
-			if len(updatedHistory) >= maxHistory {
-				updatedHistory = updatedHistory[1:]
-			}
-		}
+	removed, err := config.PruneHistory(time.Duration(days) * 24 * time.Hour)
+	if err != nil {
+		ShowError(h.App.Pages, "Failed to prune history", err)
+		return
 	}
-	updatedHistory = append(updatedHistory, text)
+	h.App.Pages.RemovePage(h.GetIdentifier())
+	ShowInfo(h.App.Pages, fmt.Sprintf("Removed %d history entries", removed))
+}
 
-	historyFile, err := os.OpenFile(getHisotryFilePath(), os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+func (h *History) closePruneModal() {
+	h.pruneModal.SetText("")
+	h.App.Pages.RemovePage(HistoryPruneModal)
+}
+
+// Render loads history from file and renders it
+func (h *History) Render() {
+	entries, err := config.LoadHistory()
 	if err != nil {
-		return err
+		ShowError(h.App.Pages, "Failed to load history", err)
+		return
 	}
-	defer historyFile.Close()
+	h.entries = entries
 
-	for _, entry := range updatedHistory {
-		_, err = historyFile.WriteString(entry + "\n")
-		if err != nil {
-			return err
-		}
-	}
+	h.SetSearching(false)
+	h.SetSearchText("")
+	h.renderEntries()
 
-	return nil
+	h.App.Pages.AddPage(h.GetIdentifier(), h, true, true)
 }
 
-// GetText returns text from selected item
-func (h *History) GetText() string {
-	text := h.ListModal.GetText()
+// renderEntries renders sorted history entries that match the search text,
+// pinned entries are rendered in their own section above the others
+func (h *History) renderEntries() {
+	h.Clear()
+	h.visible = nil
 
-	return strings.TrimSpace(text)
-}
+	pinned, others := config.GroupHistory(h.entries, h.sortBy)
+	pinned = config.FilterHistory(pinned, h.GetSearchText())
+	others = config.FilterHistory(others, h.GetSearchText())
 
-// loadHistory loads history from history file
-func (h *History) loadHistory() ([]string, error) {
-	bytes, err := os.ReadFile(getHisotryFilePath())
-	if err != nil {
-		if os.IsNotExist(err) {
-			err = os.WriteFile(getHisotryFilePath(), []byte{}, 0644)
+	addHeader := func(title string) {
+		h.AddItem("[::b]"+title, "", 0, nil)
+		h.visible = append(h.visible, nil)
+	}
+	shortcuts := 0
+	addEntries := func(entries []config.HistoryEntry) {
+		for i := range entries {
+			var shortcut rune
+			if shortcuts < 9 {
+				shortcut = rune('1' + shortcuts)
+			}
+			shortcuts++
+			h.AddItem(entries[i].Query, h.entryDetails(entries[i]), shortcut, nil)
+			h.visible = append(h.visible, &entries[i])
 		}
-		return nil, err
 	}
 
-	history := []string{}
-	lines := strings.Split(string(bytes), "\n")
-
-	for _, line := range lines {
-		if line != "" {
-			history = append(history, line)
+	if len(pinned) > 0 {
+		addHeader(config.Msg("modal.history.pinned"))
+		addEntries(pinned)
+		if len(others) > 0 {
+			addHeader(config.Msg("modal.history.others"))
 		}
 	}
+	addEntries(others)
 
-	return history, nil
+	// the first entry is selected, not the header
+	if len(h.visible) > 1 && h.visible[0] == nil {
+		h.SetCurrentItem(1)
+	}
 }
 
-func getHisotryFilePath() string {
-	configDir, err := util.GetConfigDir()
-	if err != nil {
+// selected returns the selected entry, nil if a header is selected
+func (h *History) selected() *config.HistoryEntry {
+	index := h.GetCurrentItem()
+	if index < 0 || index >= len(h.visible) {
+		return nil
+	}
+	return h.visible[index]
+}
+
+// entryDetails returns the namespace, timestamp and usage count of the entry
+func (h *History) entryDetails(entry config.HistoryEntry) string {
+	details := []string{}
+	if entry.Namespace != "" {
+		details = append(details, entry.Namespace)
+	}
+	if !entry.Timestamp.IsZero() {
+		details = append(details, entry.Timestamp.Format("2006-01-02 15:04"))
+	}
+	details = append(details, fmt.Sprintf("used %dx", entry.Count))
+
+	return "  " + strings.Join(details, " | ")
+}
+
+// SaveToHistory saves text to history file with the namespace
+// in which it was executed
+func (h *History) SaveToHistory(text, namespace string) error {
+	return config.AddToHistory(text, namespace, h.App.GetConfig().History.MaxEntries)
+}
+
+// GetText returns query of the selected entry
+func (h *History) GetText() string {
+	entry := h.selected()
+	if entry == nil {
 		return ""
 	}
 
-	return configDir + "/history.txt"
+	return strings.TrimSpace(entry.Query)
 }
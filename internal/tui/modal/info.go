@@ -3,6 +3,7 @@ package modal
 import (
 	"github.com/gdamore/tcell/v2"
 	"github.com/kopecmaciej/tview"
+	"github.com/kopecmaciej/vi-mongo/internal/config"
 	"github.com/kopecmaciej/vi-mongo/internal/tui/core"
 	"github.com/rs/zerolog/log"
 )
@@ -17,12 +18,12 @@ func NewInfo(message string) *tview.Modal {
 	message = "[White::b] " + message + " [::]"
 
 	infoModal := tview.NewModal()
-	infoModal.SetTitle(" Info ")
+	infoModal.SetTitle(" " + config.Msg("modal.info.title") + " ")
 	infoModal.SetBorderPadding(0, 0, 1, 1)
 	infoModal.SetBackgroundColor(tview.Styles.ContrastBackgroundColor)
 	infoModal.SetTextColor(tcell.ColorGreen)
 	infoModal.SetText(message)
-	infoModal.AddButtons([]string{"Ok"})
+	infoModal.AddButtons([]string{config.Msg("button.ok")})
 
 	return infoModal
 }
@@ -32,7 +33,7 @@ func ShowInfo(page *core.Pages, message string) {
 	infoModal := NewInfo(message)
 
 	infoModal.SetDoneFunc(func(buttonIndex int, buttonLabel string) {
-		if buttonLabel == "Ok" {
+		if buttonLabel == config.Msg("button.ok") {
 			page.RemovePage(InfoModal)
 		}
 	})
@@ -42,7 +43,7 @@ func ShowInfo(page *core.Pages, message string) {
 func ShowInfoModalAndFocus(page *core.Pages, message string, setFocus func()) {
 	infoModal := NewInfo(message)
 	infoModal.SetDoneFunc(func(buttonIndex int, buttonLabel string) {
-		if buttonLabel == "Ok" {
+		if buttonLabel == config.Msg("button.ok") {
 			page.RemovePage(InfoModal)
 			setFocus()
 		}
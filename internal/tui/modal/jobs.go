@@ -0,0 +1,180 @@
+package modal
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/kopecmaciej/tview"
+	"github.com/kopecmaciej/vi-mongo/internal/config"
+	"github.com/kopecmaciej/vi-mongo/internal/manager"
+	"github.com/kopecmaciej/vi-mongo/internal/tui/core"
+)
+
+const (
+	JobsModal = "Jobs"
+)
+
+// Jobs lists running and finished background jobs with their progress,
+// log of the selected job is shown below the list
+type Jobs struct {
+	*core.BaseElement
+	*core.Flex
+
+	frame *core.Flex
+	table *core.Table
+	logs  *core.TextView
+	jobs  []*manager.Job
+}
+
+func NewJobsModal() *Jobs {
+	j := &Jobs{
+		BaseElement: core.NewBaseElement(),
+		Flex:        core.NewFlex(),
+		frame:       core.NewFlex(),
+		table:       core.NewTable(),
+		logs:        core.NewTextView(),
+	}
+
+	j.SetIdentifier(JobsModal)
+	j.SetAfterInitFunc(j.init)
+
+	return j
+}
+
+func (j *Jobs) init() error {
+	j.setStaticLayout()
+	j.setStyle()
+	j.setKeybindings()
+
+	// list is refreshed while it's shown
+	j.App.GetJobs().SetChangedFunc(func() {
+		j.App.QueueBackgroundUpdate(func() {
+			if j.App.Pages.HasPage(JobsModal) {
+				j.render()
+			}
+		})
+	})
+
+	return nil
+}
+
+func (j *Jobs) setStaticLayout() {
+	j.frame.SetBorder(true)
+	j.frame.SetTitle(" " + config.Msg("modal.jobs.title") + " ")
+	j.frame.SetTitleAlign(tview.AlignCenter)
+	j.frame.SetDirection(tview.FlexRow)
+
+	j.table.SetFixed(1, 0)
+	j.table.SetSelectable(true, false)
+	j.table.SetSelectionChangedFunc(func(row, column int) {
+		j.renderLogs()
+	})
+
+	j.logs.SetBorder(true)
+	j.logs.SetTitle(" " + config.Msg("modal.jobs.logs.title") + " ")
+	j.logs.SetScrollable(true)
+
+	j.frame.AddItem(j.table, 0, 1, true)
+	j.frame.AddItem(j.logs, 10, 0, false)
+
+	// easy way to center the frame
+	column := core.NewFlex()
+	column.SetDirection(tview.FlexRow)
+	column.AddItem(tview.NewBox(), 0, 1, false)
+	column.AddItem(j.frame, 0, 4, true)
+	column.AddItem(tview.NewBox(), 0, 1, false)
+
+	j.AddItem(tview.NewBox(), 0, 1, false)
+	j.AddItem(column, 0, 6, true)
+	j.AddItem(tview.NewBox(), 0, 1, false)
+}
+
+func (j *Jobs) setStyle() {
+	styles := j.App.GetStyles()
+	j.frame.SetStyle(styles)
+	j.table.SetStyle(styles)
+	j.logs.SetStyle(styles)
+	j.logs.SetTextColor(styles.Global.SecondaryTextColor.Color())
+}
+
+func (j *Jobs) setKeybindings() {
+	j.table.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch {
+		case event.Key() == tcell.KeyEscape:
+			j.close()
+			return nil
+		case event.Rune() == 'c':
+			if job := j.selectedJob(); job != nil && job.Status() == manager.JobRunning {
+				job.Cancel()
+			}
+			return nil
+		case event.Rune() == 'x':
+			j.App.GetJobs().ClearFinished()
+			return nil
+		}
+		return event
+	})
+}
+
+// Render shows all jobs, the newest first
+func (j *Jobs) Render() {
+	j.render()
+	j.table.Select(1, 0)
+	j.table.ScrollToBeginning()
+	j.renderLogs()
+	j.App.Pages.AddPage(JobsModal, j, true, true)
+}
+
+func (j *Jobs) render() {
+	j.jobs = j.App.GetJobs().Jobs()
+	j.table.Clear()
+
+	style := j.App.GetStyles().Content
+	for col, header := range []string{"#", "Job", "Status", "Progress", "Duration"} {
+		j.table.SetCell(0, col, tview.NewTableCell(header).
+			SetTextColor(style.ColumnKeyColor.Color()).
+			SetBackgroundColor(style.HeaderRowBackgroundColor.Color()).
+			SetSelectable(false).
+			SetExpansion(1))
+	}
+	for i, job := range j.jobs {
+		values := []string{
+			fmt.Sprintf("%d", job.ID),
+			job.Name,
+			string(job.Status()),
+			job.Progress(),
+			job.Duration().Round(time.Second).String(),
+		}
+		for col, value := range values {
+			j.table.SetCell(i+1, col, tview.NewTableCell(value).SetTextColor(style.CellTextColor.Color()))
+		}
+	}
+	if len(j.jobs) == 0 {
+		j.table.SetCell(1, 0, tview.NewTableCell("No jobs"))
+	}
+	j.renderLogs()
+}
+
+func (j *Jobs) renderLogs() {
+	job := j.selectedJob()
+	if job == nil {
+		j.logs.SetText("")
+		return
+	}
+	j.logs.SetText(strings.Join(job.Logs(), "\n"))
+	j.logs.ScrollToEnd()
+}
+
+func (j *Jobs) selectedJob() *manager.Job {
+	row, _ := j.table.GetSelection()
+	if row < 1 || row > len(j.jobs) {
+		return nil
+	}
+	return j.jobs[row-1]
+}
+
+func (j *Jobs) close() {
+	j.App.Pages.RemovePage(JobsModal)
+}
@@ -0,0 +1,289 @@
+package modal
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/kopecmaciej/tview"
+	"github.com/kopecmaciej/vi-mongo/internal/config"
+	"github.com/kopecmaciej/vi-mongo/internal/mongo"
+	"github.com/kopecmaciej/vi-mongo/internal/tui/core"
+	"github.com/kopecmaciej/vi-mongo/internal/util"
+)
+
+const (
+	JSONImportModal = "JSONImport"
+)
+
+// JSONImport imports the JSON array or NDJSON file into the collection.
+// Every document is validated when the file is loaded, invalid ones are
+// listed with the reason and only valid documents are inserted.
+type JSONImport struct {
+	*core.BaseElement
+	*core.Flex
+
+	frame    *core.Flex
+	form     *core.Form
+	path     *tview.InputField
+	batch    *tview.InputField
+	rate     *tview.InputField
+	skipErrs *tview.Checkbox
+	info     *core.TextView
+	rejected *core.Table
+	preview  *core.Table
+	file     *mongo.JSONFile
+	source   string
+	onImport func(file *mongo.JSONFile, source string, opts mongo.ImportOptions)
+}
+
+func NewJSONImportModal() *JSONImport {
+	ji := &JSONImport{
+		BaseElement: core.NewBaseElement(),
+		Flex:        core.NewFlex(),
+		frame:       core.NewFlex(),
+		form:        core.NewForm(),
+		path:        tview.NewInputField(),
+		batch:       tview.NewInputField(),
+		rate:        tview.NewInputField(),
+		skipErrs:    tview.NewCheckbox(),
+		info:        core.NewTextView(),
+		rejected:    core.NewTable(),
+		preview:     core.NewTable(),
+	}
+
+	ji.SetIdentifier(JSONImportModal)
+	ji.SetAfterInitFunc(ji.init)
+
+	return ji
+}
+
+func (ji *JSONImport) init() error {
+	ji.setStaticLayout()
+	ji.setStyle()
+	ji.setKeybindings()
+
+	return nil
+}
+
+func (ji *JSONImport) setStaticLayout() {
+	ji.frame.SetBorder(true)
+	ji.frame.SetTitleAlign(tview.AlignCenter)
+	ji.frame.SetDirection(tview.FlexRow)
+
+	ji.path.SetLabel("JSON file")
+	ji.path.SetPlaceholder("path of the JSON array or NDJSON file")
+	ji.batch.SetLabel("Batch size")
+	ji.batch.SetFieldWidth(10)
+	ji.batch.SetAcceptanceFunc(tview.InputFieldInteger)
+	ji.rate.SetLabel("Writes per second")
+	ji.rate.SetFieldWidth(10)
+	ji.rate.SetPlaceholder("unlimited")
+	ji.rate.SetAcceptanceFunc(tview.InputFieldInteger)
+	ji.skipErrs.SetLabel("Skip documents rejected by the server")
+	ji.form.AddFormItem(ji.path)
+	ji.form.AddFormItem(ji.batch)
+	ji.form.AddFormItem(ji.rate)
+	ji.form.AddFormItem(ji.skipErrs)
+	ji.form.AddButton(config.Msg("button.load"), ji.load)
+	ji.form.AddButton(config.Msg("button.import"), ji.importDocuments)
+	ji.form.SetButtonsAlign(tview.AlignCenter)
+
+	ji.rejected.SetBorder(true)
+	ji.rejected.SetTitle(" " + config.Msg("modal.jsonImport.rejected.title") + " ")
+	ji.rejected.SetFixed(1, 0)
+	ji.rejected.SetSelectable(true, false)
+
+	ji.preview.SetBorder(true)
+	ji.preview.SetTitle(fmt.Sprintf(" Preview of first %d documents ", csvPreviewSize))
+	ji.preview.SetFixed(1, 0)
+
+	ji.frame.AddItem(ji.form, 11, 0, true)
+	ji.frame.AddItem(ji.info, 1, 0, false)
+	ji.frame.AddItem(ji.rejected, 0, 1, false)
+	ji.frame.AddItem(ji.preview, csvPreviewSize+3, 0, false)
+
+	ji.AddItem(tview.NewBox(), 0, 1, false)
+	ji.AddItem(ji.frame, 0, 8, true)
+	ji.AddItem(tview.NewBox(), 0, 1, false)
+}
+
+func (ji *JSONImport) setStyle() {
+	styles := ji.App.GetStyles()
+	ji.frame.SetStyle(styles)
+	ji.form.SetStyle(styles)
+	ji.form.SetLabelColor(styles.InputBar.LabelColor.Color())
+	ji.form.SetFieldTextColor(styles.InputBar.InputColor.Color())
+	ji.form.SetFieldBackgroundColor(styles.Global.ContrastBackgroundColor.Color())
+	ji.path.SetPlaceholderTextColor(styles.Global.SecondaryTextColor.Color())
+	ji.rate.SetPlaceholderTextColor(styles.Global.SecondaryTextColor.Color())
+	ji.rejected.SetStyle(styles)
+	ji.preview.SetStyle(styles)
+	ji.info.SetStyle(styles)
+	ji.info.SetTextColor(styles.Content.StatusTextColor.Color())
+}
+
+func (ji *JSONImport) setKeybindings() {
+	ji.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyEscape:
+			ji.close()
+			return nil
+		case tcell.KeyTab:
+			// form handles Tab itself, unless rejected documents are focused
+			if ji.rejected.HasFocus() {
+				ji.App.SetFocus(ji.form)
+				return nil
+			}
+		}
+		return event
+	})
+	ji.form.SetCancelFunc(ji.close)
+	ji.path.SetDoneFunc(func(key tcell.Key) {
+		if key == tcell.KeyEnter {
+			ji.load()
+		}
+	})
+}
+
+// SetImportFunc sets the function called with valid documents
+// of the loaded file, the path of the file and import options
+func (ji *JSONImport) SetImportFunc(onImport func(file *mongo.JSONFile, source string, opts mongo.ImportOptions)) {
+	ji.onImport = onImport
+}
+
+// Render shows the import into the collection, starting with the file path,
+// limits of writes are prefilled with batchSize and writesPerSecond
+func (ji *JSONImport) Render(db, coll string, batchSize, writesPerSecond int) {
+	ji.batch.SetText(strconv.Itoa(batchSize))
+	ji.rate.SetText("")
+	if writesPerSecond > 0 {
+		ji.rate.SetText(strconv.Itoa(writesPerSecond))
+	}
+	ji.skipErrs.SetChecked(true)
+	ji.frame.SetTitle(fmt.Sprintf(" Import JSON into %s.%s (Tab - switch, Esc - close) ", db, coll))
+	ji.file = nil
+	ji.rejected.Clear()
+	ji.preview.Clear()
+	ji.info.SetText("")
+	ji.form.SetFocus(0)
+
+	ji.App.Pages.AddPage(JSONImportModal, ji, true, true)
+	ji.App.SetFocus(ji.form)
+}
+
+func (ji *JSONImport) load() {
+	source, err := filepath.Abs(strings.TrimSpace(ji.path.GetText()))
+	if err != nil {
+		ShowError(ji.App.Pages, "Error reading JSON file", err)
+		return
+	}
+	f, err := os.Open(source)
+	if err != nil {
+		ShowError(ji.App.Pages, "Error reading JSON file", err)
+		return
+	}
+	defer f.Close()
+
+	file, err := mongo.ReadJSON(f)
+	if err != nil {
+		ShowError(ji.App.Pages, "Error reading JSON file", err)
+		return
+	}
+	ji.file = file
+	ji.source = source
+
+	format, position := "JSON array", "Document"
+	if file.NDJSON {
+		format, position = "NDJSON", "Line"
+	}
+	ji.info.SetText(fmt.Sprintf("Format: %s, Documents: %d, Rejected: %d", format, len(file.Documents), len(file.Rejected)))
+	ji.renderRejected(position)
+	ji.renderPreview()
+	// Import button is the next to focus
+	ji.form.SetFocus(ji.form.GetFormItemCount() + 1)
+	ji.App.SetFocus(ji.form)
+}
+
+// renderRejected lists invalid documents with the reason
+func (ji *JSONImport) renderRejected(position string) {
+	ji.rejected.Clear()
+	style := ji.App.GetStyles().Content
+	for col, header := range []string{position, "Reason"} {
+		ji.rejected.SetCell(0, col, tview.NewTableCell(header).
+			SetTextColor(style.ColumnKeyColor.Color()).
+			SetBackgroundColor(style.HeaderRowBackgroundColor.Color()).
+			SetSelectable(false))
+	}
+	if len(ji.file.Rejected) == 0 {
+		ji.rejected.SetCell(1, 0, tview.NewTableCell("All documents are valid").SetSelectable(false))
+		return
+	}
+	for i, rejected := range ji.file.Rejected {
+		ji.rejected.SetCell(i+1, 0, tview.NewTableCell(strconv.Itoa(rejected.Position)).SetTextColor(style.CellTextColor.Color()))
+		ji.rejected.SetCell(i+1, 1, tview.NewTableCell(tview.Escape(rejected.Reason)).
+			SetTextColor(style.CellTextColor.Color()).
+			SetExpansion(1))
+	}
+}
+
+// renderPreview shows first valid documents as they will be inserted
+func (ji *JSONImport) renderPreview() {
+	ji.preview.Clear()
+	documents := ji.file.Documents[:min(csvPreviewSize, len(ji.file.Documents))]
+	if len(documents) == 0 {
+		ji.preview.SetCell(0, 0, tview.NewTableCell("No documents to import"))
+		return
+	}
+	style := ji.App.GetStyles().Content
+	headers, fields := documentColumns(documents, style.ColumnTypeColor.Color().String(), "_id")
+	for col, header := range headers {
+		ji.preview.SetCell(0, col, tview.NewTableCell(header).
+			SetTextColor(style.ColumnKeyColor.Color()).
+			SetBackgroundColor(style.HeaderRowBackgroundColor.Color()).
+			SetSelectable(false).
+			SetAlign(tview.AlignCenter))
+	}
+	for row, doc := range documents {
+		for col, field := range fields {
+			var text string
+			if val, ok := doc[field]; ok {
+				text = util.GetValueByType(val)
+			}
+			ji.preview.SetCell(row+1, col, tview.NewTableCell(util.TruncateByWidth(text, 30)).
+				SetTextColor(style.CellTextColor.Color()).
+				SetMaxWidth(30))
+		}
+	}
+}
+
+func (ji *JSONImport) importDocuments() {
+	if ji.file == nil {
+		ji.load()
+		return
+	}
+	if len(ji.file.Documents) == 0 {
+		ShowInfo(ji.App.Pages, "No valid documents to import")
+		return
+	}
+	// empty or invalid values mean the default batch size and no limit
+	batchSize, _ := strconv.Atoi(ji.batch.GetText())
+	writesPerSecond, _ := strconv.Atoi(ji.rate.GetText())
+	opts := mongo.ImportOptions{
+		BatchSize:       batchSize,
+		WritesPerSecond: writesPerSecond,
+		ContinueOnError: ji.skipErrs.IsChecked(),
+	}
+
+	ji.close()
+	if ji.onImport != nil {
+		ji.onImport(ji.file, ji.source, opts)
+	}
+}
+
+func (ji *JSONImport) close() {
+	ji.App.Pages.RemovePage(JSONImportModal)
+}
@@ -0,0 +1,28 @@
+package modal
+
+import (
+	"github.com/kopecmaciej/tview"
+	"github.com/kopecmaciej/vi-mongo/internal/config"
+	"github.com/kopecmaciej/vi-mongo/internal/tui/core"
+)
+
+const (
+	LoadingModal = "Loading"
+)
+
+// ShowLoading shows a modal without buttons, which is displayed
+// until HideLoading is called, e.g. when the connection is established
+func ShowLoading(page *core.Pages, message string) {
+	loadingModal := tview.NewModal()
+	loadingModal.SetTitle(" " + config.Msg("modal.loading.title") + " ")
+	loadingModal.SetBorderPadding(0, 0, 1, 1)
+	loadingModal.SetBackgroundColor(tview.Styles.ContrastBackgroundColor)
+	loadingModal.SetText("[White::b] " + message + " [::]")
+
+	page.AddPage(LoadingModal, loadingModal, true, true)
+}
+
+// HideLoading removes the loading modal
+func HideLoading(page *core.Pages) {
+	page.RemovePage(LoadingModal)
+}
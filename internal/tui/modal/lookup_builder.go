@@ -0,0 +1,215 @@
+package modal
+
+import (
+	"github.com/gdamore/tcell/v2"
+	"github.com/kopecmaciej/tview"
+	"github.com/kopecmaciej/vi-mongo/internal/config"
+	"github.com/kopecmaciej/vi-mongo/internal/mongo"
+	"github.com/kopecmaciej/vi-mongo/internal/tui/core"
+)
+
+const (
+	LookupBuilderModal = "LookupBuilder"
+)
+
+// LookupBuilder is a form that builds the $lookup stage, where the foreign
+// collection and both fields are picked from lists, fields of the foreign
+// collection are sampled when it's picked
+type LookupBuilder struct {
+	*core.BaseElement
+	*core.Flex
+
+	form          *core.Form
+	from          *tview.DropDown
+	localField    *tview.DropDown
+	foreignField  *tview.DropDown
+	as            *tview.InputField
+	preview       *tview.TextView
+	foreignFields []string
+	onLoadFields  func(collection string) ([]string, error)
+	onApply       func(stage string)
+}
+
+func NewLookupBuilderModal() *LookupBuilder {
+	lb := &LookupBuilder{
+		BaseElement:  core.NewBaseElement(),
+		Flex:         core.NewFlex(),
+		form:         core.NewForm(),
+		from:         tview.NewDropDown(),
+		localField:   tview.NewDropDown(),
+		foreignField: tview.NewDropDown(),
+		as:           tview.NewInputField(),
+		preview:      tview.NewTextView(),
+	}
+
+	lb.SetIdentifier(LookupBuilderModal)
+	lb.SetAfterInitFunc(lb.init)
+
+	return lb
+}
+
+func (lb *LookupBuilder) init() error {
+	lb.setStaticLayout()
+	lb.setStyle()
+	lb.setKeybindings()
+
+	return nil
+}
+
+func (lb *LookupBuilder) setStaticLayout() {
+	lb.form.SetBorder(true)
+	lb.form.SetTitle(" " + config.Msg("modal.lookup.title") + " ")
+	lb.form.SetTitleAlign(tview.AlignCenter)
+	lb.form.SetButtonsAlign(tview.AlignCenter)
+
+	lb.from.SetLabel("From collection")
+	lb.localField.SetLabel("Local field")
+	lb.foreignField.SetLabel("Foreign field")
+	lb.as.SetLabel("As")
+	lb.as.SetFieldWidth(30)
+	lb.as.SetChangedFunc(func(text string) {
+		lb.renderPreview()
+	})
+
+	lb.preview.SetLabel("Stage")
+	lb.preview.SetSize(3, 0)
+	lb.preview.SetWrap(true)
+
+	lb.form.AddFormItem(lb.from)
+	lb.form.AddFormItem(lb.localField)
+	lb.form.AddFormItem(lb.foreignField)
+	lb.form.AddFormItem(lb.as)
+	lb.form.AddFormItem(lb.preview)
+
+	lb.form.AddButton(config.Msg("button.apply"), lb.apply)
+	lb.form.AddButton(config.Msg("button.cancel"), lb.close)
+
+	// easy way to center the form
+	column := core.NewFlex()
+	column.SetDirection(tview.FlexRow)
+	column.AddItem(tview.NewBox(), 0, 1, false)
+	column.AddItem(lb.form, 15, 0, true)
+	column.AddItem(tview.NewBox(), 0, 1, false)
+
+	lb.AddItem(tview.NewBox(), 0, 1, false)
+	lb.AddItem(column, 80, 0, true)
+	lb.AddItem(tview.NewBox(), 0, 1, false)
+}
+
+func (lb *LookupBuilder) setStyle() {
+	styles := lb.App.GetStyles()
+	lb.form.SetStyle(styles)
+	lb.form.SetLabelColor(styles.InputBar.LabelColor.Color())
+	lb.form.SetFieldTextColor(styles.InputBar.InputColor.Color())
+	lb.form.SetFieldBackgroundColor(styles.Global.ContrastBackgroundColor.Color())
+	lb.as.SetPlaceholderTextColor(styles.Global.SecondaryTextColor.Color())
+	lb.preview.SetTextColor(styles.Global.SecondaryTextColor.Color())
+	lb.preview.SetBackgroundColor(styles.Global.BackgroundColor.Color())
+}
+
+func (lb *LookupBuilder) setKeybindings() {
+	lb.form.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyEscape {
+			lb.close()
+			return nil
+		}
+		return event
+	})
+}
+
+// SetLoadFieldsFunc sets the function returning sampled fields
+// of the collection picked as the foreign one
+func (lb *LookupBuilder) SetLoadFieldsFunc(onLoadFields func(collection string) ([]string, error)) {
+	lb.onLoadFields = onLoadFields
+}
+
+// SetApplyFunc sets the function called with the built stage
+func (lb *LookupBuilder) SetApplyFunc(onApply func(stage string)) {
+	lb.onApply = onApply
+}
+
+// Render shows the builder with collections of the database
+// and sampled fields of the current collection
+func (lb *LookupBuilder) Render(collections, localFields []string) {
+	lb.foreignFields = nil
+	lb.foreignField.SetOptions(nil, nil)
+	lb.localField.SetOptions(localFields, func(text string, index int) {
+		lb.suggestForeignField()
+	})
+	lb.localField.SetCurrentOption(0)
+	lb.as.SetText("")
+	lb.from.SetOptions(collections, lb.fromSelected)
+	lb.from.SetCurrentOption(0)
+	lb.form.SetFocus(0)
+
+	lb.App.Pages.AddPage(LookupBuilderModal, lb, true, true)
+}
+
+// fromSelected loads fields of the picked collection
+func (lb *LookupBuilder) fromSelected(collection string, index int) {
+	lb.as.SetPlaceholder(collection)
+	lb.foreignFields = nil
+	if lb.onLoadFields != nil {
+		fields, err := lb.onLoadFields(collection)
+		if err != nil {
+			ShowError(lb.App.Pages, "Error sampling fields of "+collection, err)
+		}
+		lb.foreignFields = fields
+	}
+	lb.foreignField.SetOptions(lb.foreignFields, func(text string, index int) {
+		lb.renderPreview()
+	})
+	lb.suggestForeignField()
+}
+
+// suggestForeignField selects the foreign field most likely
+// referenced by the local field
+func (lb *LookupBuilder) suggestForeignField() {
+	_, local := lb.localField.GetCurrentOption()
+	suggested := mongo.SuggestForeignField(local, lb.foreignFields)
+	for i, field := range lb.foreignFields {
+		if field == suggested {
+			lb.foreignField.SetCurrentOption(i)
+			break
+		}
+	}
+	lb.renderPreview()
+}
+
+func (lb *LookupBuilder) currentLookup() mongo.Lookup {
+	_, from := lb.from.GetCurrentOption()
+	_, local := lb.localField.GetCurrentOption()
+	_, foreign := lb.foreignField.GetCurrentOption()
+	return mongo.Lookup{
+		From:         from,
+		LocalField:   local,
+		ForeignField: foreign,
+		As:           lb.as.GetText(),
+	}
+}
+
+func (lb *LookupBuilder) renderPreview() {
+	stage, err := lb.currentLookup().Stage()
+	if err != nil {
+		lb.preview.SetText(err.Error())
+		return
+	}
+	lb.preview.SetText(mongo.StageShellString(stage))
+}
+
+func (lb *LookupBuilder) apply() {
+	stage, err := lb.currentLookup().Stage()
+	if err != nil {
+		ShowError(lb.App.Pages, "Invalid $lookup stage", err)
+		return
+	}
+
+	lb.close()
+	if lb.onApply != nil {
+		lb.onApply(mongo.StageShellString(stage))
+	}
+}
+
+func (lb *LookupBuilder) close() {
+	lb.App.Pages.RemovePage(LookupBuilderModal)
+}
@@ -0,0 +1,208 @@
+package modal
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/kopecmaciej/tview"
+	"github.com/kopecmaciej/vi-mongo/internal/config"
+	"github.com/kopecmaciej/vi-mongo/internal/mongo"
+	"github.com/kopecmaciej/vi-mongo/internal/tui/core"
+)
+
+const (
+	QueryOptionsModal = "QueryOptions"
+)
+
+// QueryOptions is a form with find options, like skip or batch size,
+// which are applied to the next execution of the query
+type QueryOptions struct {
+	*core.BaseElement
+	*core.Flex
+
+	form         *core.Form
+	limit        *tview.InputField
+	skip         *tview.InputField
+	batchSize    *tview.InputField
+	maxTimeMS    *tview.InputField
+	allowDiskUse *tview.Checkbox
+	onApply      func(opts mongo.QueryOptions)
+}
+
+func NewQueryOptionsModal() *QueryOptions {
+	qo := &QueryOptions{
+		BaseElement:  core.NewBaseElement(),
+		Flex:         core.NewFlex(),
+		form:         core.NewForm(),
+		limit:        tview.NewInputField(),
+		skip:         tview.NewInputField(),
+		batchSize:    tview.NewInputField(),
+		maxTimeMS:    tview.NewInputField(),
+		allowDiskUse: tview.NewCheckbox(),
+	}
+
+	qo.SetIdentifier(QueryOptionsModal)
+	qo.SetAfterInitFunc(qo.init)
+
+	return qo
+}
+
+func (qo *QueryOptions) init() error {
+	qo.setStaticLayout()
+	qo.setStyle()
+	qo.setKeybindings()
+
+	return nil
+}
+
+func (qo *QueryOptions) setStaticLayout() {
+	qo.form.SetBorder(true)
+	qo.form.SetTitle(" " + config.Msg("modal.queryOptions.title") + " ")
+	qo.form.SetTitleAlign(tview.AlignCenter)
+	qo.form.SetButtonsAlign(tview.AlignCenter)
+
+	for _, field := range []struct {
+		input       *tview.InputField
+		label       string
+		placeholder string
+	}{
+		{qo.limit, "Limit", "page size"},
+		{qo.skip, "Skip", "0"},
+		{qo.batchSize, "Batch size", "server default"},
+		{qo.maxTimeMS, "Max time (ms)", "no limit"},
+	} {
+		field.input.SetLabel(field.label)
+		field.input.SetPlaceholder(field.placeholder)
+		field.input.SetFieldWidth(20)
+		field.input.SetAcceptanceFunc(tview.InputFieldInteger)
+		qo.form.AddFormItem(field.input)
+	}
+
+	qo.allowDiskUse.SetLabel("Allow disk use")
+	qo.form.AddFormItem(qo.allowDiskUse)
+
+	qo.form.AddButton(config.Msg("button.apply"), qo.apply)
+	qo.form.AddButton(config.Msg("button.reset"), qo.reset)
+	qo.form.AddButton(config.Msg("button.cancel"), qo.close)
+
+	// easy way to center the form
+	column := core.NewFlex()
+	column.SetDirection(tview.FlexRow)
+	column.AddItem(tview.NewBox(), 0, 1, false)
+	column.AddItem(qo.form, 15, 0, true)
+	column.AddItem(tview.NewBox(), 0, 1, false)
+
+	qo.AddItem(tview.NewBox(), 0, 1, false)
+	qo.AddItem(column, 50, 0, true)
+	qo.AddItem(tview.NewBox(), 0, 1, false)
+}
+
+func (qo *QueryOptions) setStyle() {
+	styles := qo.App.GetStyles()
+	qo.form.SetStyle(styles)
+	qo.form.SetLabelColor(styles.InputBar.LabelColor.Color())
+	qo.form.SetFieldTextColor(styles.InputBar.InputColor.Color())
+	qo.form.SetFieldBackgroundColor(styles.Global.ContrastBackgroundColor.Color())
+	for _, input := range []*tview.InputField{qo.limit, qo.skip, qo.batchSize, qo.maxTimeMS} {
+		input.SetPlaceholderTextColor(styles.Global.SecondaryTextColor.Color())
+	}
+}
+
+func (qo *QueryOptions) setKeybindings() {
+	qo.form.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyEscape {
+			qo.close()
+			return nil
+		}
+		return event
+	})
+}
+
+// SetApplyFunc sets the function called with options from the form
+func (qo *QueryOptions) SetApplyFunc(onApply func(opts mongo.QueryOptions)) {
+	qo.onApply = onApply
+}
+
+// Render shows the form filled with current options
+func (qo *QueryOptions) Render(opts mongo.QueryOptions) {
+	qo.limit.SetText(formatOption(opts.Limit))
+	qo.skip.SetText(formatOption(opts.Skip))
+	qo.batchSize.SetText(formatOption(int64(opts.BatchSize)))
+	qo.maxTimeMS.SetText(formatOption(opts.MaxTimeMS))
+	qo.allowDiskUse.SetChecked(opts.AllowDiskUse)
+	qo.form.SetFocus(0)
+
+	qo.App.Pages.AddPage(QueryOptionsModal, qo, true, true)
+}
+
+func (qo *QueryOptions) apply() {
+	opts, err := qo.options()
+	if err == nil {
+		err = opts.Validate()
+	}
+	if err != nil {
+		ShowError(qo.App.Pages, "Invalid query options", err)
+		return
+	}
+
+	qo.close()
+	if qo.onApply != nil {
+		qo.onApply(opts)
+	}
+}
+
+func (qo *QueryOptions) reset() {
+	qo.limit.SetText("")
+	qo.skip.SetText("")
+	qo.batchSize.SetText("")
+	qo.maxTimeMS.SetText("")
+	qo.allowDiskUse.SetChecked(false)
+}
+
+// options returns the options from the form, empty fields are left unset
+func (qo *QueryOptions) options() (mongo.QueryOptions, error) {
+	var opts mongo.QueryOptions
+	var err error
+
+	if opts.Limit, err = parseOption(qo.limit, 64); err != nil {
+		return opts, err
+	}
+	if opts.Skip, err = parseOption(qo.skip, 64); err != nil {
+		return opts, err
+	}
+	batchSize, err := parseOption(qo.batchSize, 32)
+	if err != nil {
+		return opts, err
+	}
+	opts.BatchSize = int32(batchSize)
+	if opts.MaxTimeMS, err = parseOption(qo.maxTimeMS, 64); err != nil {
+		return opts, err
+	}
+	opts.AllowDiskUse = qo.allowDiskUse.IsChecked()
+
+	return opts, nil
+}
+
+func (qo *QueryOptions) close() {
+	qo.App.Pages.RemovePage(QueryOptionsModal)
+}
+
+func parseOption(input *tview.InputField, bitSize int) (int64, error) {
+	text := input.GetText()
+	if text == "" {
+		return 0, nil
+	}
+	value, err := strconv.ParseInt(text, 10, bitSize)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %s is not a valid number", input.GetLabel(), text)
+	}
+	return value, nil
+}
+
+func formatOption(value int64) string {
+	if value == 0 {
+		return ""
+	}
+	return strconv.FormatInt(value, 10)
+}
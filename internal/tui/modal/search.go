@@ -0,0 +1,252 @@
+package modal
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/kopecmaciej/tview"
+	"github.com/kopecmaciej/vi-mongo/internal/config"
+	"github.com/kopecmaciej/vi-mongo/internal/mongo"
+	"github.com/kopecmaciej/vi-mongo/internal/tui/core"
+	"github.com/kopecmaciej/vi-mongo/internal/util"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	SearchModal = "Search"
+)
+
+// Search builds $search queries against Atlas Search indexes
+// of the collection and shows scored results with highlights
+type Search struct {
+	*core.BaseElement
+	*core.Flex
+
+	frame   *core.Flex
+	form    *core.Form
+	index   *tview.DropDown
+	mode    *tview.DropDown
+	path    *tview.InputField
+	query   *tview.InputField
+	results *core.Table
+	info    *core.TextView
+	onRun   func(query mongo.SearchQuery)
+}
+
+func NewSearchModal() *Search {
+	s := &Search{
+		BaseElement: core.NewBaseElement(),
+		Flex:        core.NewFlex(),
+		frame:       core.NewFlex(),
+		form:        core.NewForm(),
+		index:       tview.NewDropDown(),
+		mode:        tview.NewDropDown(),
+		path:        tview.NewInputField(),
+		query:       tview.NewInputField(),
+		results:     core.NewTable(),
+		info:        core.NewTextView(),
+	}
+
+	s.SetIdentifier(SearchModal)
+	s.SetAfterInitFunc(s.init)
+
+	return s
+}
+
+func (s *Search) init() error {
+	s.setStaticLayout()
+	s.setStyle()
+	s.setKeybindings()
+
+	return nil
+}
+
+func (s *Search) setStaticLayout() {
+	s.frame.SetBorder(true)
+	s.frame.SetTitle(" " + config.Msg("modal.search.title") + " ")
+	s.frame.SetTitleAlign(tview.AlignCenter)
+	s.frame.SetDirection(tview.FlexRow)
+
+	s.index.SetLabel("Index")
+	s.mode.SetLabel("Mode")
+	s.mode.SetOptions([]string{mongo.SearchText, mongo.SearchAutocomplete, mongo.SearchFuzzy}, nil)
+	s.mode.SetCurrentOption(0)
+	s.path.SetLabel("Path")
+	s.path.SetPlaceholder("all indexed fields")
+	s.query.SetLabel("Query")
+	s.form.AddFormItem(s.index)
+	s.form.AddFormItem(s.mode)
+	s.form.AddFormItem(s.path)
+	s.form.AddFormItem(s.query)
+	s.form.AddButton(config.Msg("button.search"), s.run)
+	s.form.SetButtonsAlign(tview.AlignCenter)
+
+	s.results.SetFixed(1, 0)
+	s.results.SetSelectable(true, false)
+	s.results.SetBorder(true)
+
+	s.frame.AddItem(s.form, 11, 0, true)
+	s.frame.AddItem(s.info, 1, 0, false)
+	s.frame.AddItem(s.results, 0, 1, false)
+
+	s.AddItem(tview.NewBox(), 0, 1, false)
+	s.AddItem(s.frame, 0, 8, true)
+	s.AddItem(tview.NewBox(), 0, 1, false)
+}
+
+func (s *Search) setStyle() {
+	styles := s.App.GetStyles()
+	s.frame.SetStyle(styles)
+	s.form.SetStyle(styles)
+	s.form.SetLabelColor(styles.InputBar.LabelColor.Color())
+	s.form.SetFieldTextColor(styles.InputBar.InputColor.Color())
+	s.form.SetFieldBackgroundColor(styles.Global.ContrastBackgroundColor.Color())
+	s.path.SetPlaceholderTextColor(styles.Global.SecondaryTextColor.Color())
+	s.results.SetStyle(styles)
+	s.info.SetStyle(styles)
+	s.info.SetTextColor(styles.Content.StatusTextColor.Color())
+}
+
+func (s *Search) setKeybindings() {
+	s.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyEscape:
+			s.close()
+			return nil
+		case tcell.KeyTab:
+			// form handles Tab itself, unless the results are focused
+			if s.results.HasFocus() {
+				s.App.SetFocus(s.form)
+				return nil
+			}
+		}
+		return event
+	})
+	s.form.SetCancelFunc(s.close)
+	s.query.SetDoneFunc(func(key tcell.Key) {
+		if key == tcell.KeyEnter {
+			s.run()
+		}
+	})
+}
+
+// SetRunFunc sets the function called with the query from the form,
+// results are expected to be passed to RenderResults
+func (s *Search) SetRunFunc(onRun func(query mongo.SearchQuery)) {
+	s.onRun = onRun
+}
+
+// Render shows the form with indexes to choose from, previous
+// query is kept if the collection has the same indexes
+func (s *Search) Render(indexes []string) {
+	_, current := s.index.GetCurrentOption()
+	s.index.SetOptions(indexes, nil)
+	s.index.SetCurrentOption(0)
+	for i, index := range indexes {
+		if index == current {
+			s.index.SetCurrentOption(i)
+		}
+	}
+	s.results.Clear()
+	s.info.SetText("")
+	s.form.SetFocus(s.form.GetFormItemIndex("Query"))
+
+	s.App.Pages.AddPage(SearchModal, s, true, true)
+}
+
+// RenderResults shows found documents with their score and highlights
+func (s *Search) RenderResults(results []mongo.SearchResult) {
+	s.info.SetText(fmt.Sprintf("Documents: %d", len(results)))
+	renderSearchResults(s.results, s.App.GetStyles(), results, true)
+	if len(results) > 0 {
+		s.App.SetFocus(s.results)
+	}
+}
+
+// renderSearchResults fills the table with found documents, the score
+// and optionally highlights are in the first columns
+func renderSearchResults(table *core.Table, styles *config.Styles, results []mongo.SearchResult, withHighlights bool) {
+	table.Clear()
+	if len(results) == 0 {
+		table.SetCell(0, 0, tview.NewTableCell("No documents found"))
+		return
+	}
+
+	style := styles.Content
+	documents := make([]primitive.M, 0, len(results))
+	for _, result := range results {
+		documents = append(documents, result.Document)
+	}
+	headers, fields := documentColumns(documents, style.ColumnTypeColor.Color().String(), "_id")
+	leading := []string{"Score"}
+	if withHighlights {
+		leading = append(leading, "Highlights")
+	}
+	headers = append(leading, headers...)
+
+	for col, header := range headers {
+		table.SetCell(0, col, tview.NewTableCell(header).
+			SetTextColor(style.ColumnKeyColor.Color()).
+			SetBackgroundColor(style.HeaderRowBackgroundColor.Color()).
+			SetSelectable(false).
+			SetAlign(tview.AlignCenter))
+	}
+	for row, result := range results {
+		table.SetCell(row+1, 0, tview.NewTableCell(fmt.Sprintf("%.3f", result.Score)).
+			SetTextColor(style.CellTextColor.Color()))
+		if withHighlights {
+			table.SetCell(row+1, 1, tview.NewTableCell(formatHighlights(result.Highlights, style.ColumnKeyColor.Color().String())).
+				SetTextColor(style.CellTextColor.Color()).
+				SetMaxWidth(60))
+		}
+		for col, field := range fields {
+			var text string
+			if val, ok := result.Document[field]; ok {
+				text = util.GetValueByType(val)
+			}
+			table.SetCell(row+1, col+len(leading), tview.NewTableCell(util.TruncateByWidth(text, 30)).
+				SetTextColor(style.CellTextColor.Color()).
+				SetMaxWidth(30))
+		}
+	}
+	table.Select(1, 0)
+	table.ScrollToBeginning()
+}
+
+// formatHighlights joins highlights of all fields,
+// matched terms are shown in the hit color
+func formatHighlights(highlights []mongo.SearchHighlight, hitColor string) string {
+	parts := make([]string, 0, len(highlights))
+	for _, highlight := range highlights {
+		var b strings.Builder
+		b.WriteString(tview.Escape(highlight.Path) + ": ")
+		for _, text := range highlight.Texts {
+			value := tview.Escape(text.Value)
+			if text.Hit {
+				value = fmt.Sprintf("[%s::b]%s[-::-]", hitColor, value)
+			}
+			b.WriteString(value)
+		}
+		parts = append(parts, b.String())
+	}
+	return strings.Join(parts, " | ")
+}
+
+func (s *Search) run() {
+	if s.onRun == nil {
+		return
+	}
+	_, index := s.index.GetCurrentOption()
+	_, mode := s.mode.GetCurrentOption()
+	s.onRun(mongo.SearchQuery{
+		Index: index,
+		Mode:  mode,
+		Path:  strings.TrimSpace(s.path.GetText()),
+		Query: s.query.GetText(),
+	})
+}
+
+func (s *Search) close() {
+	s.App.Pages.RemovePage(SearchModal)
+}
@@ -0,0 +1,277 @@
+package modal
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/kopecmaciej/tview"
+	"github.com/kopecmaciej/vi-mongo/internal/config"
+	"github.com/kopecmaciej/vi-mongo/internal/mongo"
+	"github.com/kopecmaciej/vi-mongo/internal/tui/core"
+	"github.com/kopecmaciej/vi-mongo/internal/util"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	ServerDashboardModal = "ServerDashboard"
+	serverDashboardTitle = " Server dashboard (Esc - close) "
+
+	// statsChartWidth is the number of the newest samples shown in charts
+	statsChartWidth = 40
+	// gaugeWidth is the width of bars of connections and cache usage
+	gaugeWidth = 20
+)
+
+// ServerDashboard shows the status of the server polled periodically
+// while it's open, counters are charted as rates per second next to
+// the stats history of the connection
+type ServerDashboard struct {
+	*core.BaseElement
+	*core.Flex
+
+	view     *core.TextView
+	stats    *mongo.LiveStats
+	interval time.Duration
+	cancel   context.CancelFunc
+}
+
+func NewServerDashboardModal() *ServerDashboard {
+	d := &ServerDashboard{
+		BaseElement: core.NewBaseElement(),
+		Flex:        core.NewFlex(),
+		view:        core.NewTextView(),
+		stats:       mongo.NewLiveStats(statsChartWidth),
+	}
+
+	d.SetIdentifier(ServerDashboardModal)
+	d.SetAfterInitFunc(d.init)
+
+	return d
+}
+
+func (d *ServerDashboard) init() error {
+	d.setStaticLayout()
+	d.setStyle()
+	d.setKeybindings()
+
+	return nil
+}
+
+func (d *ServerDashboard) setStaticLayout() {
+	d.view.SetBorder(true)
+	d.view.SetTitle(serverDashboardTitle)
+	d.view.SetTitleAlign(tview.AlignCenter)
+	d.view.SetBorderPadding(1, 1, 2, 2)
+	d.view.SetDynamicColors(true)
+	d.view.SetScrollable(true)
+
+	d.AddItem(tview.NewBox(), 0, 1, false)
+	d.AddItem(d.view, 0, 8, true)
+	d.AddItem(tview.NewBox(), 0, 1, false)
+}
+
+func (d *ServerDashboard) setStyle() {
+	d.view.SetStyle(d.App.GetStyles())
+}
+
+func (d *ServerDashboard) setKeybindings() {
+	d.view.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyEscape {
+			d.close()
+			return nil
+		}
+		return event
+	})
+}
+
+// Render shows the dashboard and polls the server every interval until it's closed
+func (d *ServerDashboard) Render(interval time.Duration) {
+	d.stopPolling()
+	ctx, cancel := context.WithCancel(d.App.Context())
+	d.cancel = cancel
+	d.interval = interval
+
+	d.stats.Reset()
+	d.view.SetTitle(serverDashboardTitle)
+	d.view.SetText("Loading...")
+	d.App.Pages.AddPage(ServerDashboardModal, d, true, true)
+
+	dao := d.Dao
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			d.refresh(ctx, dao, interval)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+func (d *ServerDashboard) refresh(ctx context.Context, dao *mongo.Dao, timeout time.Duration) {
+	pollCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	status, err := dao.GetServerStatus(pollCtx)
+	if ctx.Err() != nil {
+		return
+	}
+	polledAt := time.Now()
+	d.App.QueueBackgroundUpdate(func() {
+		if err != nil {
+			log.Error().Err(err).Msg("Error polling server status")
+			d.view.SetTitle(fmt.Sprintf(" Server dashboard - error: %s ", err))
+			return
+		}
+		d.view.SetTitle(serverDashboardTitle)
+		d.stats.Add(status, polledAt)
+		d.renderStats(dao)
+	})
+}
+
+// renderStats shows the newest status with charts of its values and rates
+func (d *ServerDashboard) renderStats(dao *mongo.Dao) {
+	ss := d.stats.Latest()
+	if ss == nil {
+		return
+	}
+
+	styles := d.App.GetStyles()
+	label := styles.Others.ModalTextColor.Color()
+	value := styles.Others.ModalSecondaryTextColor.Color()
+	var content strings.Builder
+	line := func(name, text string) {
+		fmt.Fprintf(&content, "[%s]%-16s[%s] %s\n", label, name, value, text)
+	}
+	section := func(name string) {
+		fmt.Fprintf(&content, "\n[%s::b]%s[-::-]\n", label, name)
+	}
+
+	for _, warning := range ss.StorageWarnings() {
+		fmt.Fprintf(&content, "[%s]Warning:[-] %s\n", styles.Header.WarningColor.Color(), warning)
+	}
+	line("Host", fmt.Sprintf("%s:%d", dao.Config.Host, dao.Config.Port))
+	line("Database", dao.Config.Database)
+	line("Version", ss.Version)
+	line("Role", ss.Role())
+	line("Uptime", (time.Duration(ss.Uptime) * time.Second).String())
+	line("Ping", ss.Ping.Round(time.Millisecond).String())
+
+	gauges := d.stats.Gauges()
+	section("Connections")
+	conns := ss.Connections
+	total := conns.Current + conns.Available
+	usage := 0.0
+	if total > 0 {
+		usage = float64(conns.Current) / float64(total)
+	}
+	line("In use", fmt.Sprintf("%s %d / %d (%.1f%%)", util.Gauge(usage, gaugeWidth), conns.Current, total, usage*100))
+	line("Trend", util.Sparkline(gauges[0].Values, statsChartWidth))
+	line("Total created", fmt.Sprintf("%d", conns.TotalCreated))
+
+	section("Memory")
+	line("Resident", fmt.Sprintf("%d MB", ss.Mem.Resident))
+	line("Virtual", fmt.Sprintf("%d MB", ss.Mem.Virtual))
+	line("Trend", util.Sparkline(gauges[1].Values, statsChartWidth))
+	if wt := ss.WiredTiger; wt != nil {
+		line("Cache", fmt.Sprintf("%s %.1f%%", util.Gauge(wt.CacheUsage(), gaugeWidth), wt.CacheUsage()*100))
+		line("Cache dirty", fmt.Sprintf("%s %.1f%%", util.Gauge(wt.DirtyUsage(), gaugeWidth), wt.DirtyUsage()*100))
+	}
+
+	section(fmt.Sprintf("Operations and network (polled every %s)", d.interval))
+	for _, series := range d.stats.Rates() {
+		line(series.Name, fmt.Sprintf("%-*s %s", statsChartWidth, util.Sparkline(series.Values, statsChartWidth), formatRate(series)))
+	}
+
+	section("Storage")
+	for _, info := range storageInfo(ss) {
+		line(info[0], info[1])
+	}
+
+	content.WriteString(d.statsHistory(dao.Config.Name))
+
+	row, col := d.view.GetScrollOffset()
+	d.view.SetText(content.String())
+	d.view.ScrollTo(row, col)
+}
+
+func (d *ServerDashboard) stopPolling() {
+	if d.cancel != nil {
+		d.cancel()
+		d.cancel = nil
+	}
+}
+
+func (d *ServerDashboard) close() {
+	d.stopPolling()
+	d.App.Pages.RemovePage(ServerDashboardModal)
+}
+
+// formatRate returns the newest rate of the series with its unit
+func formatRate(series mongo.StatsSeries) string {
+	if series.Unit == "B/s" {
+		return util.FormatBytes(int64(series.Last())) + "/s"
+	}
+	return fmt.Sprintf("%.1f%s", series.Last(), series.Unit)
+}
+
+// storageInfo returns the storage engine, WiredTiger cache
+// and ticket usage, in the order they're shown
+func storageInfo(ss *mongo.ServerStatus) [][2]string {
+	info := [][2]string{{"Storage Engine", ss.StorageEngine.Name}}
+	if wt := ss.WiredTiger; wt != nil {
+		cache := wt.Cache
+		info = append(info,
+			[2]string{"Cache Used", fmt.Sprintf("%s / %s (%.1f%%)", util.FormatBytes(cache.Bytes), util.FormatBytes(cache.MaxBytes), wt.CacheUsage()*100)},
+			[2]string{"Cache Dirty", fmt.Sprintf("%s (%.1f%%)", util.FormatBytes(cache.DirtyBytes), wt.DirtyUsage()*100)},
+			[2]string{"Pages Read / Written", fmt.Sprintf("%d / %d", cache.PagesRead, cache.PagesWritten)},
+			[2]string{"Pages Evicted", fmt.Sprintf("%d unmodified, %d modified", cache.UnmodifiedEvicted, cache.ModifiedEvicted)},
+			[2]string{"Evicted By", fmt.Sprintf("%d workers, %d application threads", cache.EvictionWorkerEvicted, cache.ApplicationEvicted)},
+		)
+	}
+	tickets := ss.Tickets()
+	for _, t := range []struct {
+		name  string
+		usage mongo.TicketUsage
+	}{{"Read Tickets", tickets.Read}, {"Write Tickets", tickets.Write}} {
+		if t.usage.Total > 0 {
+			info = append(info, [2]string{t.name, fmt.Sprintf("%d in use, %d available of %d", t.usage.Out, t.usage.Available, t.usage.Total)})
+		}
+	}
+	return info
+}
+
+// statsHistory returns charts of the stats history of the connection
+func (d *ServerDashboard) statsHistory(connection string) string {
+	samples, err := config.LoadStatsHistory(connection)
+	if err != nil {
+		return fmt.Sprintf("\nError loading stats history: %v\n", err)
+	}
+	if len(samples) < 2 {
+		return ""
+	}
+	if len(samples) > statsChartWidth+1 {
+		samples = samples[len(samples)-statsChartWidth-1:]
+	}
+
+	styles := d.App.GetStyles()
+	span := samples[len(samples)-1].Time.Sub(samples[0].Time).Round(time.Minute)
+	content := fmt.Sprintf("\n[%s::b]History (last %s)[-::-]\n", styles.Others.ModalTextColor.Color(), span)
+	for _, series := range mongo.StatsHistorySeries(samples) {
+		high := 0.0
+		for _, v := range series.Values {
+			high = max(high, v)
+		}
+		content += fmt.Sprintf("[%s]%-16s[%s] %s %.1f%s (max %.1f)\n",
+			styles.Others.ModalTextColor.Color(), series.Name,
+			styles.Others.ModalSecondaryTextColor.Color(), util.Sparkline(series.Values, statsChartWidth),
+			series.Last(), series.Unit, high)
+	}
+	return content
+}
@@ -0,0 +1,202 @@
+package modal
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/kopecmaciej/tview"
+	"github.com/kopecmaciej/vi-mongo/internal/config"
+	"github.com/kopecmaciej/vi-mongo/internal/mongo"
+	"github.com/kopecmaciej/vi-mongo/internal/tui/core"
+	"github.com/kopecmaciej/vi-mongo/internal/util"
+)
+
+const (
+	ShardingModal   = "Sharding"
+	shardingTimeout = 10 * time.Second
+)
+
+// Sharding shows the topology of the sharded cluster, its shards,
+// distribution of chunks of sharded collections and the balancer state
+type Sharding struct {
+	*core.BaseElement
+	*core.Flex
+
+	frame       *core.Flex
+	shards      *core.Table
+	collections *core.TextView
+}
+
+func NewShardingModal() *Sharding {
+	s := &Sharding{
+		BaseElement: core.NewBaseElement(),
+		Flex:        core.NewFlex(),
+		frame:       core.NewFlex(),
+		shards:      core.NewTable(),
+		collections: core.NewTextView(),
+	}
+
+	s.SetIdentifier(ShardingModal)
+	s.SetAfterInitFunc(s.init)
+
+	return s
+}
+
+func (s *Sharding) init() error {
+	s.setStaticLayout()
+	s.setStyle()
+	s.setKeybindings()
+
+	return nil
+}
+
+func (s *Sharding) setStaticLayout() {
+	s.frame.SetBorder(true)
+	s.frame.SetTitleAlign(tview.AlignCenter)
+	s.frame.SetDirection(tview.FlexRow)
+
+	s.shards.SetFixed(1, 0)
+	s.shards.SetSelectable(true, false)
+
+	s.collections.SetBorder(true)
+	s.collections.SetTitle(" " + config.Msg("modal.sharding.chunks.title") + " ")
+	s.collections.SetDynamicColors(true)
+	s.collections.SetScrollable(true)
+
+	s.frame.AddItem(s.shards, 0, 1, true)
+	s.frame.AddItem(s.collections, 0, 2, false)
+
+	s.AddItem(tview.NewBox(), 0, 1, false)
+	s.AddItem(s.frame, 0, 8, true)
+	s.AddItem(tview.NewBox(), 0, 1, false)
+}
+
+func (s *Sharding) setStyle() {
+	styles := s.App.GetStyles()
+	s.frame.SetStyle(styles)
+	s.shards.SetStyle(styles)
+	s.collections.SetStyle(styles)
+}
+
+func (s *Sharding) setKeybindings() {
+	capture := func(event *tcell.EventKey) *tcell.EventKey {
+		switch {
+		case event.Key() == tcell.KeyEscape:
+			s.App.Pages.RemovePage(ShardingModal)
+			return nil
+		case event.Key() == tcell.KeyTab:
+			if s.shards.HasFocus() {
+				s.App.SetFocus(s.collections)
+			} else {
+				s.App.SetFocus(s.shards)
+			}
+			return nil
+		case event.Rune() == 'r':
+			s.refresh()
+			return nil
+		}
+		return event
+	}
+	s.shards.SetInputCapture(capture)
+	s.collections.SetInputCapture(capture)
+}
+
+// Render shows the topology of the cluster
+func (s *Sharding) Render() {
+	s.App.Pages.AddPage(ShardingModal, s, true, true)
+	s.refresh()
+}
+
+func (s *Sharding) refresh() {
+	ctx, cancel := context.WithTimeout(s.App.Context(), shardingTimeout)
+	defer cancel()
+
+	status, err := s.Dao.GetShardingStatus(ctx)
+	if err != nil {
+		s.frame.SetTitle(" " + config.Msg("modal.sharding.title") + " ")
+		s.shards.Clear()
+		s.collections.SetText(tview.Escape(err.Error()))
+		return
+	}
+
+	balancer := "disabled"
+	if status.Balancer.Enabled() {
+		balancer = "enabled"
+	}
+	if status.Balancer.InBalancerRound {
+		balancer += ", balancing"
+	}
+	s.frame.SetTitle(fmt.Sprintf(" Sharding - %d shards, balancer %s (r - refresh, Tab - switch, Esc - close) ", len(status.Shards), balancer))
+	s.renderShards(status)
+	s.renderCollections(status)
+}
+
+func (s *Sharding) renderShards(status *mongo.ShardingStatus) {
+	chunks := map[string]int64{}
+	for _, coll := range status.Collections {
+		for _, count := range coll.Chunks {
+			chunks[count.Shard] += count.Chunks
+		}
+	}
+
+	style := s.App.GetStyles().Content
+	s.shards.Clear()
+	for col, header := range []string{"Shard", "Host", "State", "Chunks", "Tags"} {
+		s.shards.SetCell(0, col, tview.NewTableCell(header).
+			SetTextColor(style.ColumnKeyColor.Color()).
+			SetBackgroundColor(style.HeaderRowBackgroundColor.Color()).
+			SetSelectable(false))
+	}
+	for i, shard := range status.Shards {
+		state := "active"
+		if shard.Draining {
+			state = "draining"
+		}
+		cells := []string{shard.ID, shard.Host, state, fmt.Sprintf("%d", chunks[shard.ID]), strings.Join(shard.Tags, ", ")}
+		for col, text := range cells {
+			s.shards.SetCell(i+1, col, tview.NewTableCell(tview.Escape(text)).
+				SetTextColor(style.CellTextColor.Color()))
+		}
+	}
+	s.shards.Select(1, 0)
+}
+
+// renderCollections shows the shard key of every sharded collection
+// and bars of its chunks on every shard
+func (s *Sharding) renderCollections(status *mongo.ShardingStatus) {
+	styles := s.App.GetStyles()
+	label := styles.Others.ModalTextColor.Color()
+	value := styles.Others.ModalSecondaryTextColor.Color()
+
+	if len(status.Collections) == 0 {
+		s.collections.SetText("No sharded collections")
+		return
+	}
+
+	var text strings.Builder
+	for _, coll := range status.Collections {
+		fmt.Fprintf(&text, "[%s::b]%s[-::-] [%s]key %s", label, tview.Escape(coll.Namespace), value, tview.Escape(coll.KeyString()))
+		if coll.Unique {
+			text.WriteString(", unique")
+		}
+		fmt.Fprintf(&text, ", %d chunks, imbalance %d\n", coll.Total(), coll.Imbalance(len(status.Shards)))
+
+		counts := map[string]int64{}
+		for _, count := range coll.Chunks {
+			counts[count.Shard] = count.Chunks
+		}
+		for _, shard := range status.Shards {
+			ratio := 0.0
+			if total := coll.Total(); total > 0 {
+				ratio = float64(counts[shard.ID]) / float64(total)
+			}
+			fmt.Fprintf(&text, "  [%s]%-16s[%s] %s %d\n", label, tview.Escape(shard.ID), value, util.Gauge(ratio, gaugeWidth), counts[shard.ID])
+		}
+		text.WriteString("\n")
+	}
+	s.collections.SetText(text.String())
+	s.collections.ScrollToBeginning()
+}
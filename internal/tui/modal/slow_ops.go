@@ -0,0 +1,277 @@
+package modal
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/kopecmaciej/tview"
+	"github.com/kopecmaciej/vi-mongo/internal/config"
+	"github.com/kopecmaciej/vi-mongo/internal/mongo"
+	"github.com/kopecmaciej/vi-mongo/internal/tui/core"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	SlowOpsModal     = "SlowOps"
+	SlowOpsKillModal = "SlowOpsKill"
+)
+
+// SlowOps lists operations exceeding the threshold, refreshed
+// periodically while it's shown. Selected operation is shown
+// with its query shape and can be killed if it's still running.
+type SlowOps struct {
+	*core.BaseElement
+	*core.Flex
+
+	frame   *core.Flex
+	table   *core.Table
+	details *core.TextView
+	confirm *core.Modal
+	ops     []mongo.SlowOp
+	cancel  context.CancelFunc
+	onLoad  func(ctx context.Context) ([]mongo.SlowOp, error)
+	onKill  func(op mongo.SlowOp)
+}
+
+func NewSlowOpsModal() *SlowOps {
+	so := &SlowOps{
+		BaseElement: core.NewBaseElement(),
+		Flex:        core.NewFlex(),
+		frame:       core.NewFlex(),
+		table:       core.NewTable(),
+		details:     core.NewTextView(),
+		confirm:     core.NewModal(),
+	}
+
+	so.SetIdentifier(SlowOpsModal)
+	so.SetAfterInitFunc(so.init)
+
+	return so
+}
+
+func (so *SlowOps) init() error {
+	so.setStaticLayout()
+	so.setStyle()
+	so.setKeybindings()
+
+	return nil
+}
+
+func (so *SlowOps) setStaticLayout() {
+	so.frame.SetBorder(true)
+	so.frame.SetTitle(" " + config.Msg("modal.slowOps.title") + " ")
+	so.frame.SetTitleAlign(tview.AlignCenter)
+	so.frame.SetDirection(tview.FlexRow)
+
+	so.table.SetFixed(1, 0)
+	so.table.SetSelectable(true, false)
+	so.table.SetSelectionChangedFunc(func(row, _ int) {
+		so.renderDetails(row - 1)
+	})
+
+	so.details.SetBorder(true)
+	so.details.SetTitle(" " + config.Msg("modal.slowOps.details.title") + " ")
+	so.details.SetScrollable(true)
+
+	so.frame.AddItem(so.table, 0, 1, true)
+	so.frame.AddItem(so.details, 0, 1, false)
+
+	so.confirm.AddButtons([]string{config.Msg("button.kill"), config.Msg("button.cancel")})
+	so.confirm.SetBorder(true)
+	so.confirm.SetTitle(" " + config.Msg("modal.slowOps.confirm.title") + " ")
+
+	so.AddItem(tview.NewBox(), 0, 1, false)
+	so.AddItem(so.frame, 0, 8, true)
+	so.AddItem(tview.NewBox(), 0, 1, false)
+}
+
+func (so *SlowOps) setStyle() {
+	styles := so.App.GetStyles()
+	so.frame.SetStyle(styles)
+	so.table.SetStyle(styles)
+	so.details.SetStyle(styles)
+	so.details.SetTextColor(styles.Global.SecondaryTextColor.Color())
+	so.confirm.SetStyle(styles)
+	so.confirm.SetButtonActivatedStyle(tcell.StyleDefault.
+		Background(styles.Others.DeleteButtonSelectedBackgroundColor.Color()))
+}
+
+func (so *SlowOps) setKeybindings() {
+	so.table.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch {
+		case event.Key() == tcell.KeyEscape:
+			so.close()
+			return nil
+		case event.Rune() == 'K':
+			row, _ := so.table.GetSelection()
+			so.confirmKill(row - 1)
+			return nil
+		}
+		return event
+	})
+}
+
+// SetLoadFunc sets the function loading slow operations,
+// it's called outside of the main goroutine
+func (so *SlowOps) SetLoadFunc(onLoad func(ctx context.Context) ([]mongo.SlowOp, error)) {
+	so.onLoad = onLoad
+}
+
+// SetKillFunc sets the function called with the operation to kill
+func (so *SlowOps) SetKillFunc(onKill func(op mongo.SlowOp)) {
+	so.onKill = onKill
+}
+
+// Render shows the panel and refreshes it every interval until it's closed
+func (so *SlowOps) Render(interval time.Duration) {
+	so.stopPolling()
+	ctx, cancel := context.WithCancel(so.App.Context())
+	so.cancel = cancel
+
+	so.table.Clear()
+	so.details.SetText("")
+	so.App.Pages.AddPage(SlowOpsModal, so, true, true)
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			so.refresh(ctx)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+func (so *SlowOps) refresh(ctx context.Context) {
+	if so.onLoad == nil {
+		return
+	}
+	ops, err := so.onLoad(ctx)
+	if ctx.Err() != nil {
+		return
+	}
+	so.App.QueueBackgroundUpdate(func() {
+		if err != nil {
+			log.Error().Err(err).Msg("Error loading slow operations")
+			so.frame.SetTitle(fmt.Sprintf(" Slow operations - error: %s ", err))
+			return
+		}
+		so.frame.SetTitle(" " + config.Msg("modal.slowOps.title") + " ")
+		so.renderOps(ops)
+	})
+}
+
+// renderOps replaces listed operations, selection is kept
+// on the same operation if it's still listed
+func (so *SlowOps) renderOps(ops []mongo.SlowOp) {
+	row, _ := so.table.GetSelection()
+	var selected interface{}
+	if row > 0 && row <= len(so.ops) {
+		selected = so.ops[row-1].OpID
+	}
+	so.ops = ops
+
+	style := so.App.GetStyles().Content
+	so.table.Clear()
+	for col, header := range []string{"State", "Duration", "Op", "Namespace", "Client", "Plan"} {
+		so.table.SetCell(0, col, tview.NewTableCell(header).
+			SetTextColor(style.ColumnKeyColor.Color()).
+			SetBackgroundColor(style.HeaderRowBackgroundColor.Color()).
+			SetSelectable(false))
+	}
+	if len(ops) == 0 {
+		so.table.SetCell(1, 0, tview.NewTableCell("No slow operations").SetSelectable(false))
+		so.details.SetText("")
+		return
+	}
+
+	selectedRow := 1
+	for i, op := range ops {
+		state := "finished"
+		if op.Running {
+			state = "running"
+		}
+		cells := []string{state, op.Duration.Round(time.Millisecond).String(), op.Op, op.Ns, op.Client, op.PlanSummary}
+		for col, text := range cells {
+			so.table.SetCell(i+1, col, tview.NewTableCell(text).
+				SetTextColor(style.CellTextColor.Color()).
+				SetMaxWidth(40))
+		}
+		if selected != nil && op.OpID == selected {
+			selectedRow = i + 1
+		}
+	}
+	so.table.Select(selectedRow, 0)
+	so.renderDetails(selectedRow - 1)
+}
+
+func (so *SlowOps) renderDetails(index int) {
+	if index < 0 || index >= len(so.ops) {
+		so.details.SetText("")
+		return
+	}
+	op := so.ops[index]
+
+	shape, err := mongo.ParseBsonDocument(mongo.QueryShape(op.Command))
+	if err != nil {
+		so.details.SetText(err.Error())
+		return
+	}
+	command, err := mongo.ParseBsonDocument(op.Command)
+	if err != nil {
+		so.details.SetText(err.Error())
+		return
+	}
+	text := fmt.Sprintf("Shape:\n%s\n\nCommand:\n%s", indent(shape), indent(command))
+	if op.OpID != nil {
+		text = fmt.Sprintf("Operation id: %v\n\n%s", op.OpID, text)
+	}
+	so.details.SetText(tview.Escape(text))
+	so.details.ScrollToBeginning()
+}
+
+func (so *SlowOps) confirmKill(index int) {
+	if index < 0 || index >= len(so.ops) {
+		return
+	}
+	op := so.ops[index]
+	if !op.Running {
+		ShowInfo(so.App.Pages, "Only running operations can be killed")
+		return
+	}
+
+	so.confirm.SetText(fmt.Sprintf("Kill %s operation %v on %s?", op.Op, op.OpID, op.Ns))
+	so.confirm.SetDoneFunc(func(_ int, buttonLabel string) {
+		so.App.Pages.RemovePage(SlowOpsKillModal)
+		if buttonLabel == config.Msg("button.kill") && so.onKill != nil {
+			so.onKill(op)
+		}
+	})
+	so.App.Pages.AddPage(SlowOpsKillModal, so.confirm, true, true)
+}
+
+func (so *SlowOps) stopPolling() {
+	if so.cancel != nil {
+		so.cancel()
+		so.cancel = nil
+	}
+}
+
+func (so *SlowOps) close() {
+	so.stopPolling()
+	so.App.Pages.RemovePage(SlowOpsModal)
+}
+
+func indent(jsoned string) string {
+	indented, err := mongo.IndentJson(jsoned)
+	if err != nil {
+		return jsoned
+	}
+	return indented.String()
+}
@@ -0,0 +1,189 @@
+package modal
+
+import (
+	"github.com/gdamore/tcell/v2"
+	"github.com/kopecmaciej/tview"
+	"github.com/kopecmaciej/vi-mongo/internal/config"
+	"github.com/kopecmaciej/vi-mongo/internal/mongo"
+	"github.com/kopecmaciej/vi-mongo/internal/tui/core"
+)
+
+const (
+	SortBuilderModal = "SortBuilder"
+
+	sortAscending  = "ascending"
+	sortDescending = "descending"
+)
+
+// SortBuilder is a form that builds the sort from multiple fields,
+// each with its own direction
+type SortBuilder struct {
+	*core.BaseElement
+	*core.Flex
+
+	form      *core.Form
+	field     *tview.DropDown
+	direction *tview.DropDown
+	preview   *tview.TextView
+	keys      mongo.SortKeys
+	onApply   func(sort string)
+	onEditRaw func()
+}
+
+func NewSortBuilderModal() *SortBuilder {
+	sb := &SortBuilder{
+		BaseElement: core.NewBaseElement(),
+		Flex:        core.NewFlex(),
+		form:        core.NewForm(),
+		field:       tview.NewDropDown(),
+		direction:   tview.NewDropDown(),
+		preview:     tview.NewTextView(),
+	}
+
+	sb.SetIdentifier(SortBuilderModal)
+	sb.SetAfterInitFunc(sb.init)
+
+	return sb
+}
+
+func (sb *SortBuilder) init() error {
+	sb.setStaticLayout()
+	sb.setStyle()
+	sb.setKeybindings()
+
+	return nil
+}
+
+func (sb *SortBuilder) setStaticLayout() {
+	sb.form.SetBorder(true)
+	sb.form.SetTitle(" " + config.Msg("modal.sort.title") + " ")
+	sb.form.SetTitleAlign(tview.AlignCenter)
+	sb.form.SetButtonsAlign(tview.AlignCenter)
+
+	sb.field.SetLabel("Field")
+	sb.direction.SetLabel("Direction")
+	sb.direction.SetOptions([]string{sortAscending, sortDescending}, nil)
+
+	sb.preview.SetLabel("Sort")
+	sb.preview.SetSize(2, 0)
+	sb.preview.SetWrap(true)
+
+	sb.form.AddFormItem(sb.field)
+	sb.form.AddFormItem(sb.direction)
+	sb.form.AddFormItem(sb.preview)
+
+	sb.form.AddButton(config.Msg("button.add"), sb.addKey)
+	sb.form.AddButton(config.Msg("button.removeLast"), sb.removeKey)
+	sb.form.AddButton(config.Msg("button.apply"), sb.apply)
+	sb.form.AddButton(config.Msg("button.editRaw"), sb.editRaw)
+	sb.form.AddButton(config.Msg("button.cancel"), sb.close)
+
+	// easy way to center the form
+	column := core.NewFlex()
+	column.SetDirection(tview.FlexRow)
+	column.AddItem(tview.NewBox(), 0, 1, false)
+	column.AddItem(sb.form, 11, 0, true)
+	column.AddItem(tview.NewBox(), 0, 1, false)
+
+	sb.AddItem(tview.NewBox(), 0, 1, false)
+	sb.AddItem(column, 70, 0, true)
+	sb.AddItem(tview.NewBox(), 0, 1, false)
+}
+
+func (sb *SortBuilder) setStyle() {
+	styles := sb.App.GetStyles()
+	sb.form.SetStyle(styles)
+	sb.form.SetLabelColor(styles.InputBar.LabelColor.Color())
+	sb.form.SetFieldTextColor(styles.InputBar.InputColor.Color())
+	sb.form.SetFieldBackgroundColor(styles.Global.ContrastBackgroundColor.Color())
+	sb.preview.SetTextColor(styles.Global.SecondaryTextColor.Color())
+	sb.preview.SetBackgroundColor(styles.Global.BackgroundColor.Color())
+}
+
+func (sb *SortBuilder) setKeybindings() {
+	sb.form.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyEscape {
+			sb.close()
+			return nil
+		}
+		return event
+	})
+}
+
+// SetApplyFunc sets the function called with the built sort,
+// sort is empty if all keys were removed
+func (sb *SortBuilder) SetApplyFunc(onApply func(sort string)) {
+	sb.onApply = onApply
+}
+
+// SetEditRawFunc sets the function called when user wants to edit
+// the sort as text
+func (sb *SortBuilder) SetEditRawFunc(onEditRaw func()) {
+	sb.onEditRaw = onEditRaw
+}
+
+// Render shows the builder with available fields and current sort
+func (sb *SortBuilder) Render(fields []string, currentSort string) error {
+	keys, err := mongo.ParseSortKeys(currentSort)
+	if err != nil {
+		return err
+	}
+	sb.keys = keys
+
+	sb.field.SetOptions(fields, nil)
+	sb.field.SetCurrentOption(0)
+	sb.direction.SetCurrentOption(0)
+	sb.renderPreview()
+	sb.form.SetFocus(0)
+
+	sb.App.Pages.AddPage(SortBuilderModal, sb, true, true)
+	return nil
+}
+
+func (sb *SortBuilder) addKey() {
+	_, field := sb.field.GetCurrentOption()
+	if field == "" {
+		return
+	}
+	direction := mongo.SortAscending
+	if _, text := sb.direction.GetCurrentOption(); text == sortDescending {
+		direction = mongo.SortDescending
+	}
+
+	sb.keys = sb.keys.Set(field, direction)
+	sb.renderPreview()
+}
+
+func (sb *SortBuilder) removeKey() {
+	if len(sb.keys) == 0 {
+		return
+	}
+	sb.keys = sb.keys[:len(sb.keys)-1]
+	sb.renderPreview()
+}
+
+func (sb *SortBuilder) apply() {
+	sb.close()
+	if sb.onApply != nil {
+		sb.onApply(sb.keys.Query())
+	}
+}
+
+func (sb *SortBuilder) editRaw() {
+	sb.close()
+	if sb.onEditRaw != nil {
+		sb.onEditRaw()
+	}
+}
+
+func (sb *SortBuilder) renderPreview() {
+	if len(sb.keys) == 0 {
+		sb.preview.SetText("none")
+		return
+	}
+	sb.preview.SetText(sb.keys.String())
+}
+
+func (sb *SortBuilder) close() {
+	sb.App.Pages.RemovePage(SortBuilderModal)
+}
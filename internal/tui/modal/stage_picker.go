@@ -0,0 +1,134 @@
+package modal
+
+import (
+	"github.com/gdamore/tcell/v2"
+	"github.com/kopecmaciej/vi-mongo/internal/config"
+	"github.com/kopecmaciej/vi-mongo/internal/mongo"
+	"github.com/kopecmaciej/vi-mongo/internal/tui/core"
+	"github.com/kopecmaciej/vi-mongo/internal/tui/primitives"
+)
+
+const (
+	StagePickerModal = "StagePicker"
+)
+
+// StagePicker lists skeletons of common stages followed by templates
+// of whole pipelines, typed text searches templates
+type StagePicker struct {
+	*core.BaseElement
+	*primitives.ListModal
+
+	visible  []mongo.PipelineTemplate
+	onSelect func(template mongo.PipelineTemplate)
+}
+
+func NewStagePickerModal() *StagePicker {
+	sp := &StagePicker{
+		BaseElement: core.NewBaseElement(),
+		ListModal:   primitives.NewListModal(),
+	}
+
+	sp.SetIdentifier(StagePickerModal)
+	sp.SetAfterInitFunc(sp.init)
+
+	return sp
+}
+
+func (sp *StagePicker) init() error {
+	sp.setStyle()
+	sp.setKeybindings()
+
+	sp.EnableSearch(" Search: ", func(text string) {
+		sp.renderList()
+	})
+
+	return nil
+}
+
+func (sp *StagePicker) setStyle() {
+	style := sp.App.GetStyles().History
+	globalBackground := sp.App.GetStyles().Global.BackgroundColor.Color()
+
+	sp.SetBorder(true)
+	sp.SetTitle(" " + config.Msg("modal.stagePicker.title") + " ")
+	sp.ShowSecondaryText(true)
+	sp.SetBorderPadding(0, 0, 1, 1)
+
+	mainStyle := tcell.StyleDefault.
+		Foreground(style.TextColor.Color()).
+		Background(globalBackground)
+	sp.SetMainTextStyle(mainStyle)
+	sp.SetSecondaryTextStyle(mainStyle.Foreground(sp.App.GetStyles().Global.SecondaryTextColor.Color()))
+	sp.SetSearchStyle(mainStyle, mainStyle.Background(sp.App.GetStyles().Global.ContrastBackgroundColor.Color()))
+
+	selectedStyle := tcell.StyleDefault.
+		Foreground(style.SelectedTextColor.Color()).
+		Background(style.SelectedBackgroundColor.Color())
+	sp.SetSelectedStyle(selectedStyle)
+}
+
+func (sp *StagePicker) setKeybindings() {
+	sp.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyEscape:
+			sp.close()
+			return nil
+		case tcell.KeyEnter:
+			sp.selectTemplate()
+			return nil
+		case tcell.KeyUp:
+			sp.SetCurrentItem(max(sp.GetCurrentItem()-1, 0))
+			return nil
+		case tcell.KeyDown:
+			sp.SetCurrentItem(min(sp.GetCurrentItem()+1, sp.GetItemCount()-1))
+			return nil
+		}
+		return event
+	})
+}
+
+// SetSelectFunc sets the function called with the picked stage or template
+func (sp *StagePicker) SetSelectFunc(onSelect func(template mongo.PipelineTemplate)) {
+	sp.onSelect = onSelect
+}
+
+// Render shows all stages and templates, typed text goes to the search
+func (sp *StagePicker) Render() {
+	sp.SetSearchText("")
+	sp.SetSearching(true)
+	sp.renderList()
+
+	sp.App.Pages.AddPage(StagePickerModal, sp, true, true)
+}
+
+// renderList shows stages when nothing is searched,
+// and templates matching the search
+func (sp *StagePicker) renderList() {
+	query := sp.GetSearchText()
+	sp.visible = nil
+	if query == "" {
+		sp.visible = append(sp.visible, mongo.StageTemplates()...)
+	}
+	sp.visible = append(sp.visible, mongo.SearchPipelineTemplates(query)...)
+
+	sp.Clear()
+	for _, template := range sp.visible {
+		sp.AddItem(template.Name, template.Description, 0, nil)
+	}
+	sp.SetCurrentItem(0)
+}
+
+func (sp *StagePicker) selectTemplate() {
+	index := sp.GetCurrentItem()
+	if index < 0 || index >= len(sp.visible) {
+		return
+	}
+	sp.close()
+	if sp.onSelect != nil {
+		sp.onSelect(sp.visible[index])
+	}
+}
+
+func (sp *StagePicker) close() {
+	sp.App.Pages.RemovePage(StagePickerModal)
+}
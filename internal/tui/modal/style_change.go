@@ -41,7 +41,7 @@ func (sc *StyleChange) init() error {
 }
 
 func (sc *StyleChange) setStaticLayout() {
-	sc.SetTitle(" Change Style ")
+	sc.SetTitle(" " + config.Msg("modal.style.title") + " ")
 	sc.SetBorder(true)
 	sc.ShowSecondaryText(false)
 	sc.SetBorderPadding(0, 0, 1, 1)
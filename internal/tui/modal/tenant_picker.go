@@ -0,0 +1,144 @@
+package modal
+
+import (
+	"fmt"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/kopecmaciej/vi-mongo/internal/tui/core"
+	"github.com/kopecmaciej/vi-mongo/internal/tui/primitives"
+	"github.com/kopecmaciej/vi-mongo/internal/util"
+)
+
+const (
+	TenantPickerModal = "TenantPicker"
+)
+
+// TenantPicker lists tenants of the namespace template,
+// selected tenant replaces the current one
+type TenantPicker struct {
+	*core.BaseElement
+	*primitives.ListModal
+
+	tenants  []string
+	visible  []string
+	onSelect func(tenant string)
+}
+
+func NewTenantPickerModal() *TenantPicker {
+	tp := &TenantPicker{
+		BaseElement: core.NewBaseElement(),
+		ListModal:   primitives.NewListModal(),
+	}
+
+	tp.SetIdentifier(TenantPickerModal)
+	tp.SetAfterInitFunc(tp.init)
+
+	return tp
+}
+
+func (tp *TenantPicker) init() error {
+	tp.setStyle()
+	tp.setKeybindings()
+
+	tp.EnableSearch(" Search: ", func(text string) {
+		tp.renderTenants()
+	})
+
+	return nil
+}
+
+func (tp *TenantPicker) setStyle() {
+	style := tp.App.GetStyles().History
+	globalBackground := tp.App.GetStyles().Global.BackgroundColor.Color()
+
+	tp.SetBorder(true)
+	tp.ShowSecondaryText(false)
+	tp.SetBorderPadding(0, 0, 1, 1)
+
+	mainStyle := tcell.StyleDefault.
+		Foreground(style.TextColor.Color()).
+		Background(globalBackground)
+	tp.SetMainTextStyle(mainStyle)
+
+	selectedStyle := tcell.StyleDefault.
+		Foreground(style.SelectedTextColor.Color()).
+		Background(style.SelectedBackgroundColor.Color())
+	tp.SetSelectedStyle(selectedStyle)
+
+	tp.SetSearchStyle(mainStyle, mainStyle.Background(tp.App.GetStyles().Global.ContrastBackgroundColor.Color()))
+}
+
+func (tp *TenantPicker) setKeybindings() {
+	tp.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if tp.IsSearching() {
+			switch event.Key() {
+			case tcell.KeyEnter, tcell.KeyEsc, tcell.KeyDown:
+				tp.SetSearching(false)
+				return nil
+			}
+			return event
+		}
+
+		switch event.Key() {
+		case tcell.KeyEscape:
+			tp.close()
+			return nil
+		case tcell.KeyEnter:
+			tp.selectTenant()
+			return nil
+		}
+		if event.Rune() == '/' {
+			tp.SetSearching(true)
+			return nil
+		}
+		return event
+	})
+}
+
+// SetSelectFunc sets the function called with the selected tenant
+func (tp *TenantPicker) SetSelectFunc(onSelect func(tenant string)) {
+	tp.onSelect = onSelect
+}
+
+// Render shows tenants of the template, current tenant is marked
+func (tp *TenantPicker) Render(template string, tenants []string, current string) {
+	tp.SetTitle(fmt.Sprintf(" Tenants of %s (/ - search) ", template))
+	tp.tenants = tenants
+	tp.SetSearchText("")
+	tp.renderTenants()
+	for i, tenant := range tp.visible {
+		if tenant == current {
+			tp.SetCurrentItem(i)
+		}
+	}
+
+	tp.App.Pages.AddPage(TenantPickerModal, tp, true, true)
+}
+
+func (tp *TenantPicker) renderTenants() {
+	tp.Clear()
+	tp.visible = nil
+	search := tp.GetSearchText()
+	for _, tenant := range tp.tenants {
+		if _, ok := util.FuzzyMatch(search, tenant); !ok {
+			continue
+		}
+		tp.visible = append(tp.visible, tenant)
+		tp.AddItem(tenant, "", 0, nil)
+	}
+}
+
+func (tp *TenantPicker) selectTenant() {
+	index := tp.GetCurrentItem()
+	if index < 0 || index >= len(tp.visible) {
+		return
+	}
+	tp.close()
+	if tp.onSelect != nil {
+		tp.onSelect(tp.visible[index])
+	}
+}
+
+func (tp *TenantPicker) close() {
+	tp.App.Pages.RemovePage(TenantPickerModal)
+}
@@ -0,0 +1,213 @@
+package modal
+
+import (
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/kopecmaciej/tview"
+	"github.com/kopecmaciej/vi-mongo/internal/config"
+	"github.com/kopecmaciej/vi-mongo/internal/mongo"
+	"github.com/kopecmaciej/vi-mongo/internal/tui/core"
+)
+
+const (
+	TimeRangeModal = "TimeRange"
+
+	// timeRangeLayout is how the range is shown in from and to fields
+	timeRangeLayout = "2006-01-02 15:04:05"
+)
+
+// TimeRange builds the filter of measurements of the time-series
+// collection taken in the last hour, day, week or in the custom range
+type TimeRange struct {
+	*core.BaseElement
+	*core.Flex
+
+	form       *core.Form
+	field      *tview.TextView
+	preset     *tview.DropDown
+	from       *tview.InputField
+	to         *tview.InputField
+	preview    *tview.TextView
+	timeSeries *mongo.TimeSeries
+	onApply    func(filter string)
+	// presetting is set while from and to are filled by the preset,
+	// otherwise typing into them switches the range to the custom one
+	presetting bool
+}
+
+func NewTimeRangeModal() *TimeRange {
+	tr := &TimeRange{
+		BaseElement: core.NewBaseElement(),
+		Flex:        core.NewFlex(),
+		form:        core.NewForm(),
+		field:       tview.NewTextView(),
+		preset:      tview.NewDropDown(),
+		from:        tview.NewInputField(),
+		to:          tview.NewInputField(),
+		preview:     tview.NewTextView(),
+	}
+
+	tr.SetIdentifier(TimeRangeModal)
+	tr.SetAfterInitFunc(tr.init)
+
+	return tr
+}
+
+func (tr *TimeRange) init() error {
+	tr.setStaticLayout()
+	tr.setStyle()
+	tr.setKeybindings()
+
+	return nil
+}
+
+func (tr *TimeRange) setStaticLayout() {
+	tr.form.SetBorder(true)
+	tr.form.SetTitle(" " + config.Msg("modal.timeRange.title") + " ")
+	tr.form.SetTitleAlign(tview.AlignCenter)
+	tr.form.SetButtonsAlign(tview.AlignCenter)
+
+	tr.field.SetLabel("Time field")
+	tr.field.SetSize(1, 0)
+
+	tr.preset.SetLabel("Range")
+	tr.preset.SetOptions(mongo.TimeRanges, func(preset string, _ int) {
+		tr.selectPreset(preset)
+	})
+
+	changed := func(string) {
+		if _, preset := tr.preset.GetCurrentOption(); !tr.presetting && preset != mongo.TimeRangeCustom {
+			tr.preset.SetCurrentOption(slices.Index(mongo.TimeRanges, mongo.TimeRangeCustom))
+			return
+		}
+		tr.renderPreview()
+	}
+	tr.from.SetLabel("From")
+	tr.from.SetFieldWidth(25)
+	tr.from.SetChangedFunc(changed)
+	tr.to.SetLabel("To")
+	tr.to.SetFieldWidth(25)
+	tr.to.SetChangedFunc(changed)
+
+	tr.preview.SetLabel("Filter")
+	tr.preview.SetSize(3, 0)
+	tr.preview.SetWrap(true)
+
+	tr.form.AddFormItem(tr.field)
+	tr.form.AddFormItem(tr.preset)
+	tr.form.AddFormItem(tr.from)
+	tr.form.AddFormItem(tr.to)
+	tr.form.AddFormItem(tr.preview)
+
+	tr.form.AddButton(config.Msg("button.apply"), tr.apply)
+	tr.form.AddButton(config.Msg("button.cancel"), tr.close)
+
+	// easy way to center the form
+	column := core.NewFlex()
+	column.SetDirection(tview.FlexRow)
+	column.AddItem(tview.NewBox(), 0, 1, false)
+	column.AddItem(tr.form, 15, 0, true)
+	column.AddItem(tview.NewBox(), 0, 1, false)
+
+	tr.AddItem(tview.NewBox(), 0, 1, false)
+	tr.AddItem(column, 80, 0, true)
+	tr.AddItem(tview.NewBox(), 0, 1, false)
+}
+
+func (tr *TimeRange) setStyle() {
+	styles := tr.App.GetStyles()
+	tr.form.SetStyle(styles)
+	tr.form.SetLabelColor(styles.InputBar.LabelColor.Color())
+	tr.form.SetFieldTextColor(styles.InputBar.InputColor.Color())
+	tr.form.SetFieldBackgroundColor(styles.Global.ContrastBackgroundColor.Color())
+	for _, view := range []*tview.TextView{tr.field, tr.preview} {
+		view.SetTextColor(styles.Global.SecondaryTextColor.Color())
+		view.SetBackgroundColor(styles.Global.BackgroundColor.Color())
+	}
+}
+
+func (tr *TimeRange) setKeybindings() {
+	tr.form.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyEscape {
+			tr.close()
+			return nil
+		}
+		return event
+	})
+}
+
+// SetApplyFunc sets the function called with the built filter
+func (tr *TimeRange) SetApplyFunc(onApply func(filter string)) {
+	tr.onApply = onApply
+}
+
+// Render shows the time range of the time field of the collection,
+// the last hour is selected
+func (tr *TimeRange) Render(timeSeries *mongo.TimeSeries) {
+	tr.timeSeries = timeSeries
+	tr.field.SetText(timeSeries.TimeField)
+	tr.preset.SetCurrentOption(0)
+	tr.form.SetFocus(1)
+
+	tr.App.Pages.AddPage(TimeRangeModal, tr, true, true)
+}
+
+// selectPreset shows the range of the preset in from and to fields,
+// the custom range keeps what is typed there
+func (tr *TimeRange) selectPreset(preset string) {
+	if from, to, err := mongo.TimeRange(preset, time.Now()); err == nil {
+		tr.presetting = true
+		tr.from.SetText(from.Format(timeRangeLayout))
+		tr.to.SetText(to.Format(timeRangeLayout))
+		tr.presetting = false
+	}
+	tr.renderPreview()
+}
+
+// filter returns the filter of the range, presets end at the time
+// of the call, so the range is up to date when it's applied
+func (tr *TimeRange) filter() (string, error) {
+	if tr.timeSeries == nil {
+		return "", nil
+	}
+	_, preset := tr.preset.GetCurrentOption()
+	from, to, err := mongo.TimeRange(preset, time.Now())
+	if preset == mongo.TimeRangeCustom {
+		from, err = mongo.ParseTime(strings.TrimSpace(tr.from.GetText()))
+		if err == nil {
+			to, err = mongo.ParseTime(strings.TrimSpace(tr.to.GetText()))
+		}
+	}
+	if err != nil {
+		return "", err
+	}
+	return mongo.TimeRangeFilter(tr.timeSeries.TimeField, from, to)
+}
+
+func (tr *TimeRange) renderPreview() {
+	filter, err := tr.filter()
+	if err != nil {
+		filter = err.Error()
+	}
+	tr.preview.SetText(filter)
+}
+
+func (tr *TimeRange) apply() {
+	filter, err := tr.filter()
+	if err != nil {
+		ShowError(tr.App.Pages, "Invalid time range", err)
+		return
+	}
+
+	tr.close()
+	if tr.onApply != nil {
+		tr.onApply(filter)
+	}
+}
+
+func (tr *TimeRange) close() {
+	tr.App.Pages.RemovePage(TimeRangeModal)
+}
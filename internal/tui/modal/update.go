@@ -0,0 +1,76 @@
+package modal
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kopecmaciej/tview"
+	"github.com/kopecmaciej/vi-mongo/internal/config"
+	"github.com/kopecmaciej/vi-mongo/internal/tui/core"
+	"github.com/kopecmaciej/vi-mongo/internal/tui/primitives"
+	"github.com/kopecmaciej/vi-mongo/internal/util"
+)
+
+const (
+	UpdateModalView = "UpdateModal"
+)
+
+// UpdateModal shows the changelog of the new version
+// together with the command to update
+type UpdateModal struct {
+	*core.BaseElement
+	*primitives.ViewModal
+}
+
+func NewUpdateModal() *UpdateModal {
+	u := &UpdateModal{
+		BaseElement: core.NewBaseElement(),
+		ViewModal:   primitives.NewViewModal(),
+	}
+
+	u.SetIdentifier(UpdateModalView)
+	return u
+}
+
+func (u *UpdateModal) Init(app *core.App) error {
+	u.App = app
+	u.setStyle()
+	u.ViewModal.SetScrollable(true)
+	return nil
+}
+
+func (u *UpdateModal) setStyle() {
+	u.ViewModal.SetBackgroundColor(u.App.GetStyles().Global.BackgroundColor.Color())
+	u.ViewModal.SetTextColor(u.App.GetStyles().Global.TextColor.Color())
+	u.ViewModal.SetButtonBackgroundColor(u.App.GetStyles().Global.BackgroundColor.Color())
+	u.ViewModal.SetButtonTextColor(u.App.GetStyles().Global.TextColor.Color())
+}
+
+// Render shows the release newer than the current version
+func (u *UpdateModal) Render(current string, release *util.Release) {
+	u.SetTitle(fmt.Sprintf(" New version %s available ", release.Version))
+
+	styles := u.App.GetStyles()
+	content := fmt.Sprintf("[%s]Current version[%s] %s\n", styles.Others.ModalTextColor.Color(), styles.Others.ModalSecondaryTextColor.Color(), current)
+	content += fmt.Sprintf("[%s]Update with[%s] %s\n", styles.Others.ModalTextColor.Color(), styles.Others.ModalSecondaryTextColor.Color(), fmt.Sprintf(util.InstallCommand, release.Version))
+	if release.URL != "" {
+		content += fmt.Sprintf("[%s]or download[%s] %s\n", styles.Others.ModalTextColor.Color(), styles.Others.ModalSecondaryTextColor.Color(), release.URL)
+	}
+
+	changelog := strings.TrimSpace(strings.ReplaceAll(release.Changelog, "\r\n", "\n"))
+	if changelog != "" {
+		content += fmt.Sprintf("\n[%s]Changelog[-]\n%s\n", styles.Others.ModalTextColor.Color(), tview.Escape(changelog))
+	}
+
+	u.ViewModal.SetText(primitives.Text{
+		Content: content,
+		Align:   tview.AlignLeft,
+	})
+	u.ViewModal.ClearButtons()
+	u.ViewModal.AddButtons([]string{config.Msg("button.close")})
+	u.ViewModal.SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+		u.App.Pages.RemovePage(UpdateModalView)
+	})
+
+	u.App.Pages.AddPage(UpdateModalView, u, true, true)
+}
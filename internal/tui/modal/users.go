@@ -0,0 +1,373 @@
+package modal
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/kopecmaciej/tview"
+	"github.com/kopecmaciej/vi-mongo/internal/config"
+	"github.com/kopecmaciej/vi-mongo/internal/mongo"
+	"github.com/kopecmaciej/vi-mongo/internal/tui/core"
+)
+
+const (
+	UsersModal         = "Users"
+	UserFormModal      = "UserForm"
+	UserDropModal      = "UserDrop"
+	usersTitle         = " Users (a - add, p - change password, D - drop, Esc - close) "
+	usersTimeout       = 10 * time.Second
+	noRolesPlaceholder = "no roles"
+)
+
+// Users lists database users with their roles and roles of the database,
+// users can be created, dropped and their passwords changed
+type Users struct {
+	*core.BaseElement
+	*core.Flex
+
+	frame   *core.Flex
+	table   *core.Table
+	roles   *core.TextView
+	form    *core.Form
+	confirm *core.Modal
+	db      string
+	users   []mongo.DbUser
+}
+
+func NewUsersModal() *Users {
+	u := &Users{
+		BaseElement: core.NewBaseElement(),
+		Flex:        core.NewFlex(),
+		frame:       core.NewFlex(),
+		table:       core.NewTable(),
+		roles:       core.NewTextView(),
+		form:        core.NewForm(),
+		confirm:     core.NewModal(),
+	}
+
+	u.SetIdentifier(UsersModal)
+	u.SetAfterInitFunc(u.init)
+
+	return u
+}
+
+func (u *Users) init() error {
+	u.setStaticLayout()
+	u.setStyle()
+	u.setKeybindings()
+
+	return nil
+}
+
+func (u *Users) setStaticLayout() {
+	u.frame.SetBorder(true)
+	u.frame.SetTitle(usersTitle)
+	u.frame.SetTitleAlign(tview.AlignCenter)
+	u.frame.SetDirection(tview.FlexRow)
+
+	u.table.SetFixed(1, 0)
+	u.table.SetSelectable(true, false)
+
+	u.roles.SetBorder(true)
+	u.roles.SetScrollable(true)
+
+	u.frame.AddItem(u.table, 0, 2, true)
+	u.frame.AddItem(u.roles, 0, 1, false)
+
+	u.form.SetBorder(true)
+	u.form.SetTitleAlign(tview.AlignCenter)
+	u.form.SetButtonsAlign(tview.AlignCenter)
+
+	u.confirm.AddButtons([]string{config.Msg("button.drop"), config.Msg("button.cancel")})
+	u.confirm.SetBorder(true)
+	u.confirm.SetTitle(" " + config.Msg("modal.users.drop.title") + " ")
+
+	u.AddItem(tview.NewBox(), 0, 1, false)
+	u.AddItem(u.frame, 0, 8, true)
+	u.AddItem(tview.NewBox(), 0, 1, false)
+}
+
+func (u *Users) setStyle() {
+	styles := u.App.GetStyles()
+	u.frame.SetStyle(styles)
+	u.table.SetStyle(styles)
+	u.roles.SetStyle(styles)
+	u.roles.SetTextColor(styles.Global.SecondaryTextColor.Color())
+	u.form.SetStyle(styles)
+	u.form.SetLabelColor(styles.InputBar.LabelColor.Color())
+	u.form.SetFieldTextColor(styles.InputBar.InputColor.Color())
+	u.form.SetFieldBackgroundColor(styles.Global.ContrastBackgroundColor.Color())
+	u.confirm.SetStyle(styles)
+	u.confirm.SetButtonActivatedStyle(tcell.StyleDefault.
+		Background(styles.Others.DeleteButtonSelectedBackgroundColor.Color()))
+}
+
+func (u *Users) setKeybindings() {
+	u.table.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch {
+		case event.Key() == tcell.KeyEscape:
+			u.App.Pages.RemovePage(UsersModal)
+			return nil
+		case event.Rune() == 'a':
+			u.showCreateForm()
+			return nil
+		case event.Rune() == 'p':
+			if user, ok := u.selectedUser(); ok {
+				u.showPasswordForm(user)
+			}
+			return nil
+		case event.Rune() == 'D':
+			if user, ok := u.selectedUser(); ok {
+				u.confirmDrop(user)
+			}
+			return nil
+		}
+		return event
+	})
+	u.form.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyEscape {
+			u.closeForm()
+			return nil
+		}
+		return event
+	})
+}
+
+// Render shows users and roles of the database,
+// users of all databases if the database is empty
+func (u *Users) Render(db string) {
+	u.db = db
+	u.App.Pages.AddPage(UsersModal, u, true, true)
+	u.refresh()
+}
+
+func (u *Users) refresh() {
+	ctx, cancel := context.WithTimeout(u.App.Context(), usersTimeout)
+	defer cancel()
+
+	users, err := u.Dao.ListUsers(ctx, u.db)
+	if err != nil {
+		u.frame.SetTitle(fmt.Sprintf(" Users - error: %s ", err))
+		u.renderUsers(nil)
+		return
+	}
+	u.frame.SetTitle(usersTitle)
+	u.renderUsers(users)
+	u.renderRoles(ctx)
+}
+
+func (u *Users) renderUsers(users []mongo.DbUser) {
+	u.users = users
+
+	style := u.App.GetStyles().Content
+	u.table.Clear()
+	for col, header := range []string{"User", "Database", "Roles"} {
+		u.table.SetCell(0, col, tview.NewTableCell(header).
+			SetTextColor(style.ColumnKeyColor.Color()).
+			SetBackgroundColor(style.HeaderRowBackgroundColor.Color()).
+			SetSelectable(false))
+	}
+	if len(users) == 0 {
+		u.table.SetCell(1, 0, tview.NewTableCell("No users").SetSelectable(false))
+		return
+	}
+
+	for i, user := range users {
+		cells := []string{user.User, user.Db, rolesText(user.Roles)}
+		for col, text := range cells {
+			u.table.SetCell(i+1, col, tview.NewTableCell(tview.Escape(text)).
+				SetTextColor(style.CellTextColor.Color()))
+		}
+	}
+	u.table.Select(1, 0)
+}
+
+// renderRoles lists roles which can be granted in the database
+func (u *Users) renderRoles(ctx context.Context) {
+	db := u.roleDb()
+	u.roles.SetTitle(fmt.Sprintf(" Roles of %s ", db))
+	roles, err := u.Dao.ListRoles(ctx, db)
+	if err != nil {
+		u.roles.SetText(err.Error())
+		return
+	}
+
+	var text strings.Builder
+	for _, role := range roles {
+		kind := "built-in"
+		if !role.IsBuiltin {
+			kind = "inherits " + rolesText(role.Roles)
+		}
+		fmt.Fprintf(&text, "%s (%s)\n", role.Role, kind)
+	}
+	u.roles.SetText(tview.Escape(text.String()))
+	u.roles.ScrollToBeginning()
+}
+
+// roleDb is the database roles are listed for and new users are created in
+func (u *Users) roleDb() string {
+	if u.db == "" {
+		return "admin"
+	}
+	return u.db
+}
+
+func (u *Users) selectedUser() (mongo.DbUser, bool) {
+	row, _ := u.table.GetSelection()
+	if row < 1 || row > len(u.users) {
+		return mongo.DbUser{}, false
+	}
+	return u.users[row-1], true
+}
+
+// showCreateForm shows the form of a new user, roles are picked from
+// the roles of the database or typed in as "role" or "role@db"
+func (u *Users) showCreateForm() {
+	ctx, cancel := context.WithTimeout(u.App.Context(), usersTimeout)
+	defer cancel()
+
+	db := u.roleDb()
+	roleNames := []string{}
+	if roles, err := u.Dao.ListRoles(ctx, db); err == nil {
+		for _, role := range roles {
+			roleNames = append(roleNames, role.Role)
+		}
+	}
+
+	u.form.Clear(true)
+	u.form.SetTitle(" " + config.Msg("modal.users.create.title") + " ")
+	u.form.AddInputField("Database", db, 30, nil, nil)
+	u.form.AddInputField("User", "", 30, nil, nil)
+	u.form.AddPasswordField("Password", "", 30, '*', nil)
+	rolesField := tview.NewInputField().
+		SetLabel("Roles").
+		SetFieldWidth(30).
+		SetPlaceholder("readWrite, read@admin")
+	rolesField.SetPlaceholderTextColor(u.App.GetStyles().Global.SecondaryTextColor.Color())
+	u.form.AddDropDown("Add role", roleNames, -1, func(role string, index int) {
+		if index < 0 {
+			return
+		}
+		roles := strings.TrimSpace(rolesField.GetText())
+		if roles != "" {
+			roles += ", "
+		}
+		rolesField.SetText(roles + role)
+	})
+	u.form.AddFormItem(rolesField)
+	u.form.AddButton(config.Msg("button.create"), func() {
+		u.createUser(
+			strings.TrimSpace(u.formText("Database")),
+			strings.TrimSpace(u.formText("User")),
+			u.formText("Password"),
+			rolesField.GetText(),
+		)
+	})
+	u.form.AddButton(config.Msg("button.cancel"), u.closeForm)
+	u.showForm(15)
+}
+
+func (u *Users) createUser(db, user, password, rolesInput string) {
+	roles, err := mongo.ParseRoles(rolesInput, db)
+	if err != nil {
+		ShowError(u.App.Pages, "Invalid roles", err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(u.App.Context(), usersTimeout)
+	defer cancel()
+	if err := u.Dao.CreateUser(ctx, db, user, password, roles); err != nil {
+		ShowError(u.App.Pages, "Error creating user", err)
+		return
+	}
+	u.closeForm()
+	u.refresh()
+}
+
+func (u *Users) showPasswordForm(user mongo.DbUser) {
+	u.form.Clear(true)
+	u.form.SetTitle(fmt.Sprintf(" Change password of %s@%s ", user.User, user.Db))
+	u.form.AddPasswordField("New password", "", 30, '*', nil)
+	u.form.AddPasswordField("Repeat password", "", 30, '*', nil)
+	u.form.AddButton(config.Msg("button.change"), func() {
+		password := u.formText("New password")
+		if password != u.formText("Repeat password") {
+			ShowError(u.App.Pages, "Error changing password", fmt.Errorf("passwords don't match"))
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(u.App.Context(), usersTimeout)
+		defer cancel()
+		if err := u.Dao.ChangePassword(ctx, user.Db, user.User, password); err != nil {
+			ShowError(u.App.Pages, "Error changing password", err)
+			return
+		}
+		u.closeForm()
+		ShowInfo(u.App.Pages, fmt.Sprintf("Password of %s changed", user.User))
+	})
+	u.form.AddButton(config.Msg("button.cancel"), u.closeForm)
+	u.showForm(9)
+}
+
+func (u *Users) confirmDrop(user mongo.DbUser) {
+	u.confirm.SetText(fmt.Sprintf("Drop user %s from %s?", user.User, user.Db))
+	u.confirm.SetDoneFunc(func(_ int, buttonLabel string) {
+		u.App.Pages.RemovePage(UserDropModal)
+		if buttonLabel != "Drop" {
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(u.App.Context(), usersTimeout)
+		defer cancel()
+		if err := u.Dao.DropUser(ctx, user.Db, user.User); err != nil {
+			ShowError(u.App.Pages, "Error dropping user", err)
+			return
+		}
+		u.refresh()
+	})
+	u.App.Pages.AddPage(UserDropModal, u.confirm, true, true)
+}
+
+func (u *Users) formText(label string) string {
+	input, ok := u.form.GetFormItemByLabel(label).(*tview.InputField)
+	if !ok {
+		return ""
+	}
+	return input.GetText()
+}
+
+// showForm shows the form in the middle of the screen
+func (u *Users) showForm(height int) {
+	// easy way to center the form
+	column := core.NewFlex()
+	column.SetDirection(tview.FlexRow)
+	column.AddItem(tview.NewBox(), 0, 1, false)
+	column.AddItem(u.form, height, 0, true)
+	column.AddItem(tview.NewBox(), 0, 1, false)
+
+	row := core.NewFlex()
+	row.AddItem(tview.NewBox(), 0, 1, false)
+	row.AddItem(column, 60, 0, true)
+	row.AddItem(tview.NewBox(), 0, 1, false)
+
+	u.form.SetFocus(0)
+	u.App.Pages.AddPage(UserFormModal, row, true, true)
+}
+
+func (u *Users) closeForm() {
+	u.App.Pages.RemovePage(UserFormModal)
+}
+
+func rolesText(roles []mongo.RoleRef) string {
+	if len(roles) == 0 {
+		return noRolesPlaceholder
+	}
+	texts := make([]string, len(roles))
+	for i, role := range roles {
+		texts[i] = role.String()
+	}
+	return strings.Join(texts, ", ")
+}
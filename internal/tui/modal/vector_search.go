@@ -0,0 +1,191 @@
+package modal
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/kopecmaciej/tview"
+	"github.com/kopecmaciej/vi-mongo/internal/config"
+	"github.com/kopecmaciej/vi-mongo/internal/mongo"
+	"github.com/kopecmaciej/vi-mongo/internal/tui/core"
+)
+
+const (
+	VectorSearchModal = "VectorSearch"
+)
+
+// VectorSearch runs $vectorSearch queries with a pasted vector or
+// the vector of the selected document and shows similarity scores
+type VectorSearch struct {
+	*core.BaseElement
+	*core.Flex
+
+	frame      *core.Flex
+	form       *core.Form
+	index      *tview.DropDown
+	path       *tview.InputField
+	vector     *tview.InputField
+	candidates *tview.InputField
+	results    *core.Table
+	info       *core.TextView
+	indexes    []mongo.SearchIndex
+	onRun      func(query mongo.VectorSearchQuery, vector string)
+}
+
+func NewVectorSearchModal() *VectorSearch {
+	vs := &VectorSearch{
+		BaseElement: core.NewBaseElement(),
+		Flex:        core.NewFlex(),
+		frame:       core.NewFlex(),
+		form:        core.NewForm(),
+		index:       tview.NewDropDown(),
+		path:        tview.NewInputField(),
+		vector:      tview.NewInputField(),
+		candidates:  tview.NewInputField(),
+		results:     core.NewTable(),
+		info:        core.NewTextView(),
+	}
+
+	vs.SetIdentifier(VectorSearchModal)
+	vs.SetAfterInitFunc(vs.init)
+
+	return vs
+}
+
+func (vs *VectorSearch) init() error {
+	vs.setStaticLayout()
+	vs.setStyle()
+	vs.setKeybindings()
+
+	return nil
+}
+
+func (vs *VectorSearch) setStaticLayout() {
+	vs.frame.SetBorder(true)
+	vs.frame.SetTitle(" " + config.Msg("modal.vectorSearch.title") + " ")
+	vs.frame.SetTitleAlign(tview.AlignCenter)
+	vs.frame.SetDirection(tview.FlexRow)
+
+	vs.index.SetLabel("Index")
+	vs.path.SetLabel("Path")
+	vs.vector.SetLabel("Vector")
+	vs.vector.SetPlaceholder("[0.12, -0.4, ...] or field of the selected document")
+	vs.candidates.SetLabel("Candidates")
+	vs.candidates.SetPlaceholder("10 per result")
+	vs.candidates.SetAcceptanceFunc(tview.InputFieldInteger)
+	vs.form.AddFormItem(vs.index)
+	vs.form.AddFormItem(vs.path)
+	vs.form.AddFormItem(vs.vector)
+	vs.form.AddFormItem(vs.candidates)
+	vs.form.AddButton(config.Msg("button.search"), vs.run)
+	vs.form.SetButtonsAlign(tview.AlignCenter)
+
+	vs.results.SetFixed(1, 0)
+	vs.results.SetSelectable(true, false)
+	vs.results.SetBorder(true)
+
+	vs.frame.AddItem(vs.form, 11, 0, true)
+	vs.frame.AddItem(vs.info, 1, 0, false)
+	vs.frame.AddItem(vs.results, 0, 1, false)
+
+	vs.AddItem(tview.NewBox(), 0, 1, false)
+	vs.AddItem(vs.frame, 0, 8, true)
+	vs.AddItem(tview.NewBox(), 0, 1, false)
+}
+
+func (vs *VectorSearch) setStyle() {
+	styles := vs.App.GetStyles()
+	vs.frame.SetStyle(styles)
+	vs.form.SetStyle(styles)
+	vs.form.SetLabelColor(styles.InputBar.LabelColor.Color())
+	vs.form.SetFieldTextColor(styles.InputBar.InputColor.Color())
+	vs.form.SetFieldBackgroundColor(styles.Global.ContrastBackgroundColor.Color())
+	for _, input := range []*tview.InputField{vs.vector, vs.candidates} {
+		input.SetPlaceholderTextColor(styles.Global.SecondaryTextColor.Color())
+	}
+	vs.results.SetStyle(styles)
+	vs.info.SetStyle(styles)
+	vs.info.SetTextColor(styles.Content.StatusTextColor.Color())
+}
+
+func (vs *VectorSearch) setKeybindings() {
+	vs.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyEscape:
+			vs.close()
+			return nil
+		case tcell.KeyTab:
+			// form handles Tab itself, unless the results are focused
+			if vs.results.HasFocus() {
+				vs.App.SetFocus(vs.form)
+				return nil
+			}
+		}
+		return event
+	})
+	vs.form.SetCancelFunc(vs.close)
+	vs.vector.SetDoneFunc(func(key tcell.Key) {
+		if key == tcell.KeyEnter {
+			vs.run()
+		}
+	})
+}
+
+// SetRunFunc sets the function called with the query and the vector
+// as typed, which can be the name of a field of the selected document.
+// Results are expected to be passed to RenderResults.
+func (vs *VectorSearch) SetRunFunc(onRun func(query mongo.VectorSearchQuery, vector string)) {
+	vs.onRun = onRun
+}
+
+// Render shows the form with vector search indexes to choose from,
+// path is filled with the vector field of the chosen index
+func (vs *VectorSearch) Render(indexes []mongo.SearchIndex) {
+	vs.indexes = indexes
+	names := make([]string, 0, len(indexes))
+	for _, index := range indexes {
+		names = append(names, index.Name)
+	}
+	vs.index.SetOptions(names, func(_ string, i int) {
+		if paths := vs.indexes[i].VectorPaths(); len(paths) > 0 {
+			vs.path.SetText(paths[0])
+		}
+	})
+	vs.index.SetCurrentOption(0)
+	vs.results.Clear()
+	vs.info.SetText("")
+	vs.form.SetFocus(vs.form.GetFormItemIndex("Vector"))
+
+	vs.App.Pages.AddPage(VectorSearchModal, vs, true, true)
+}
+
+// RenderResults shows found documents with their similarity score
+func (vs *VectorSearch) RenderResults(results []mongo.SearchResult) {
+	vs.info.SetText(fmt.Sprintf("Documents: %d", len(results)))
+	renderSearchResults(vs.results, vs.App.GetStyles(), results, false)
+	if len(results) > 0 {
+		vs.App.SetFocus(vs.results)
+	}
+}
+
+func (vs *VectorSearch) run() {
+	if vs.onRun == nil {
+		return
+	}
+	candidates, err := parseOption(vs.candidates, 64)
+	if err != nil {
+		ShowError(vs.App.Pages, "Invalid number of candidates", err)
+		return
+	}
+	_, index := vs.index.GetCurrentOption()
+	vs.onRun(mongo.VectorSearchQuery{
+		Index:         index,
+		Path:          strings.TrimSpace(vs.path.GetText()),
+		NumCandidates: candidates,
+	}, vs.vector.GetText())
+}
+
+func (vs *VectorSearch) close() {
+	vs.App.Pages.RemovePage(VectorSearchModal)
+}
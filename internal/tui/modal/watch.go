@@ -0,0 +1,244 @@
+package modal
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/kopecmaciej/tview"
+	"github.com/kopecmaciej/vi-mongo/internal/mongo"
+	"github.com/kopecmaciej/vi-mongo/internal/tui/core"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	WatchModal = "Watch"
+
+	// maxWatchEvents is the number of the newest events kept
+	maxWatchEvents = 1000
+)
+
+// watchFilters are operation types that can be hidden, with their keys,
+// replaces are shown together with updates
+var watchFilters = []struct {
+	key   rune
+	types []string
+}{
+	{'i', []string{mongo.ChangeInsert}},
+	{'u', []string{mongo.ChangeUpdate, mongo.ChangeReplace}},
+	{'d', []string{mongo.ChangeDelete}},
+}
+
+// Watch tails the change stream of the collection. Events are appended
+// while it's live, when it's paused events are still received
+// and shown once it's resumed.
+type Watch struct {
+	*core.BaseElement
+	*core.Flex
+
+	frame     *core.Flex
+	view      *core.TextView
+	namespace string
+	events    []mongo.ChangeEvent
+	paused    bool
+	hidden    map[string]bool
+	err       error
+	cancel    context.CancelFunc
+	onWatch   func(ctx context.Context, onEvent func(event mongo.ChangeEvent)) error
+}
+
+func NewWatchModal() *Watch {
+	w := &Watch{
+		BaseElement: core.NewBaseElement(),
+		Flex:        core.NewFlex(),
+		frame:       core.NewFlex(),
+		view:        core.NewTextView(),
+		hidden:      map[string]bool{},
+	}
+
+	w.SetIdentifier(WatchModal)
+	w.SetAfterInitFunc(w.init)
+
+	return w
+}
+
+func (w *Watch) init() error {
+	w.setStaticLayout()
+	w.setStyle()
+	w.setKeybindings()
+
+	return nil
+}
+
+func (w *Watch) setStaticLayout() {
+	w.frame.SetBorder(true)
+	w.frame.SetTitleAlign(tview.AlignCenter)
+	w.frame.SetDirection(tview.FlexRow)
+
+	w.view.SetDynamicColors(true)
+	w.view.SetScrollable(true)
+	w.view.SetWrap(false)
+
+	w.frame.AddItem(w.view, 0, 1, true)
+
+	w.AddItem(tview.NewBox(), 0, 1, false)
+	w.AddItem(w.frame, 0, 8, true)
+	w.AddItem(tview.NewBox(), 0, 1, false)
+}
+
+func (w *Watch) setStyle() {
+	styles := w.App.GetStyles()
+	w.frame.SetStyle(styles)
+	w.view.SetStyle(styles)
+}
+
+func (w *Watch) setKeybindings() {
+	w.view.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch {
+		case event.Key() == tcell.KeyEscape:
+			w.close()
+			return nil
+		case event.Rune() == 'p':
+			w.paused = !w.paused
+			w.render()
+			return nil
+		case event.Rune() == 'c':
+			w.events = nil
+			w.render()
+			return nil
+		}
+		for _, filter := range watchFilters {
+			if event.Rune() == filter.key {
+				for _, opType := range filter.types {
+					w.hidden[opType] = !w.hidden[opType]
+				}
+				w.render()
+				return nil
+			}
+		}
+		return event
+	})
+}
+
+// SetWatchFunc sets the function watching the change stream, it's called
+// outside of the main goroutine and runs until the context is canceled
+func (w *Watch) SetWatchFunc(onWatch func(ctx context.Context, onEvent func(event mongo.ChangeEvent)) error) {
+	w.onWatch = onWatch
+}
+
+// Render shows the panel and starts watching the collection until it's closed
+func (w *Watch) Render(namespace string) {
+	w.stopWatching()
+	ctx, cancel := context.WithCancel(w.App.Context())
+	w.cancel = cancel
+
+	w.namespace = namespace
+	w.events = nil
+	w.paused = false
+	w.err = nil
+	w.render()
+	w.App.Pages.AddPage(WatchModal, w, true, true)
+
+	if w.onWatch == nil {
+		return
+	}
+	go func() {
+		err := w.onWatch(ctx, func(event mongo.ChangeEvent) {
+			w.App.QueueBackgroundUpdate(func() {
+				if ctx.Err() != nil {
+					return
+				}
+				w.addEvent(event)
+			})
+		})
+		if err == nil || ctx.Err() != nil {
+			return
+		}
+		log.Error().Err(err).Str("namespace", namespace).Msg("Error watching collection")
+		w.App.QueueUpdateDraw(func() {
+			w.err = err
+			w.render()
+		})
+	}()
+}
+
+func (w *Watch) addEvent(event mongo.ChangeEvent) {
+	w.events = append(w.events, event)
+	if len(w.events) > maxWatchEvents {
+		w.events = w.events[len(w.events)-maxWatchEvents:]
+	}
+	if !w.paused {
+		w.render()
+	}
+}
+
+// render shows events which operation type isn't hidden,
+// the newest event is at the bottom
+func (w *Watch) render() {
+	w.frame.SetTitle(w.title())
+
+	styles := w.App.GetStyles()
+	var b strings.Builder
+	if w.err != nil {
+		fmt.Fprintf(&b, "[%s]%s[-]\n", styles.Header.WarningColor.Color(), tview.Escape(w.err.Error()))
+	}
+	shown := 0
+	for _, event := range w.events {
+		if w.hidden[event.OperationType] {
+			continue
+		}
+		shown++
+		fmt.Fprintf(&b, "[%s]%s[-] [%s]%-7s[-] %s\n",
+			styles.Global.SecondaryTextColor.Color(), event.Time.Format("15:04:05"),
+			w.operationColor(event.OperationType), event.OperationType,
+			tview.Escape(event.Summary()))
+	}
+	if shown == 0 && w.err == nil {
+		b.WriteString("Waiting for changes...\n")
+	}
+
+	w.view.SetText(b.String())
+	w.view.ScrollToEnd()
+}
+
+func (w *Watch) title() string {
+	state := "live"
+	if w.paused {
+		state = "paused"
+	}
+	var shown []string
+	for _, filter := range watchFilters {
+		if !w.hidden[filter.types[0]] {
+			shown = append(shown, filter.types[0]+"s")
+		}
+	}
+	if len(shown) == 0 {
+		shown = append(shown, "nothing")
+	}
+	return fmt.Sprintf(" Watch %s - %s, showing %s (p - pause, i/u/d - toggle inserts/updates/deletes, c - clear, Esc - close) ",
+		w.namespace, state, strings.Join(shown, ", "))
+}
+
+func (w *Watch) operationColor(opType string) tcell.Color {
+	colors := w.App.GetStyles().Content.DiffColors
+	switch opType {
+	case mongo.ChangeInsert:
+		return colors.AddedColor.Color()
+	case mongo.ChangeDelete:
+		return colors.RemovedColor.Color()
+	}
+	return colors.ChangedColor.Color()
+}
+
+func (w *Watch) stopWatching() {
+	if w.cancel != nil {
+		w.cancel()
+		w.cancel = nil
+	}
+}
+
+func (w *Watch) close() {
+	w.stopWatching()
+	w.App.Pages.RemovePage(WatchModal)
+}
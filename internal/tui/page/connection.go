@@ -67,23 +67,27 @@ func (c *Connection) handleEvents() {
 			go c.App.QueueUpdateDraw(func() {
 				c.Render()
 			})
+		case manager.KeysChanged:
+			go c.App.QueueUpdateDraw(func() {
+				c.Render()
+			})
 		}
-	})
+	}, manager.StyleChanged, manager.KeysChanged)
 }
 
 func (c *Connection) setStaticLayout() {
-	c.form.SetTitle(" New connection ")
+	c.form.SetTitle(" " + config.Msg("connection.form.title") + " ")
 	c.form.SetBorder(true)
 
-	c.list.SetTitle(" Saved connections ")
+	c.list.SetTitle(" " + config.Msg("connection.list.title") + " ")
 	c.list.SetBorder(true)
 	c.list.ShowSecondaryText(true)
 	c.list.SetWrapText(true)
 	c.list.SetBorderPadding(1, 1, 1, 1)
 	c.list.SetItemGap(1)
 
-	c.form.AddButton("Save", c.saveButtonFunc)
-	c.form.AddButton("Cancel", c.cancelButtonFunc)
+	c.form.AddButton(config.Msg("button.save"), c.saveButtonFunc)
+	c.form.AddButton(config.Msg("button.cancel"), c.cancelButtonFunc)
 
 }
 
@@ -178,6 +182,11 @@ func (c *Connection) renderForm() *core.Form {
 	c.form.AddPasswordField("Password", "", 40, '*', nil)
 	c.form.AddInputField("Database", "", 40, nil, nil)
 	c.form.AddInputField("Timeout", "5", 10, nil, nil)
+	c.form.AddTextView("  ", "-- TLS, used with Url as well -------------", 40, 1, true, false)
+	c.form.AddInputField("CA file", "", 40, nil, nil)
+	c.form.AddInputField("Cert file", "", 40, nil, nil)
+	c.form.AddInputField("Key file", "", 40, nil, nil)
+	c.form.AddCheckbox("Skip verify", false, nil)
 
 	c.AddItem(c.form, 60, 0, true)
 
@@ -252,6 +261,7 @@ func (c *Connection) saveButtonFunc() {
 			Name:    name,
 			Uri:     url,
 			Timeout: intTimeout,
+			TLS:     c.tlsConfig(),
 		})
 		if err != nil {
 			modal.ShowError(c.App.Pages, "Failed to save connection", err)
@@ -281,6 +291,7 @@ func (c *Connection) saveButtonFunc() {
 			Password: password,
 			Database: database,
 			Timeout:  intTimeout,
+			TLS:      c.tlsConfig(),
 		})
 		if err != nil {
 			modal.ShowError(c.App.Pages, "Failed to save connection", err)
@@ -291,6 +302,21 @@ func (c *Connection) saveButtonFunc() {
 	c.list.SetCurrentItem(c.list.GetItemCount())
 }
 
+// tlsConfig returns TLS options from the form, nil if none are set,
+// so TLS is then enabled only by options of the URI
+func (c *Connection) tlsConfig() *config.TLSConfig {
+	tlsConfig := &config.TLSConfig{
+		CAFile:             c.form.GetFormItemByLabel("CA file").(*tview.InputField).GetText(),
+		CertFile:           c.form.GetFormItemByLabel("Cert file").(*tview.InputField).GetText(),
+		KeyFile:            c.form.GetFormItemByLabel("Key file").(*tview.InputField).GetText(),
+		InsecureSkipVerify: c.form.GetFormItemByLabel("Skip verify").(*tview.Checkbox).IsChecked(),
+	}
+	if *tlsConfig == (config.TLSConfig{}) {
+		return nil
+	}
+	return tlsConfig
+}
+
 // cancelButtonFunc is a function for canceling the form
 func (c *Connection) cancelButtonFunc() {
 	c.renderForm()
@@ -8,6 +8,7 @@ import (
 	"github.com/kopecmaciej/vi-mongo/internal/config"
 	"github.com/kopecmaciej/vi-mongo/internal/manager"
 	"github.com/kopecmaciej/vi-mongo/internal/tui/core"
+	"github.com/rivo/uniseg"
 )
 
 const (
@@ -55,8 +56,12 @@ func (h *Help) handleEvents() {
 			go h.App.QueueUpdateDraw(func() {
 				h.Render()
 			})
+		case manager.KeysChanged:
+			go h.App.QueueUpdateDraw(func() {
+				h.Render()
+			})
 		}
-	})
+	}, manager.StyleChanged, manager.KeysChanged)
 }
 
 func (h *Help) Render() error {
@@ -109,8 +114,8 @@ func (h *Help) calculateMaxWidth(keys []config.OrderedKeys) (int, int) {
 				keyWidth = len(key.Runes)
 			}
 
-			if len(key.Description) > descWidth {
-				descWidth = len(key.Description)
+			if width := uniseg.StringWidth(key.Description); width > descWidth {
+				descWidth = width
 			}
 		}
 	}
@@ -120,10 +125,7 @@ func (h *Help) calculateMaxWidth(keys []config.OrderedKeys) (int, int) {
 // Add this new method to render key sections
 func (h *Help) renderKeySection(keys []config.OrderedKeys, row *int, col int) {
 	for _, viewKeys := range keys {
-		viewName := viewKeys.Element
-		if viewName == "Main" {
-			viewName = "Main Layout"
-		}
+		viewName := config.Msg("help.section." + viewKeys.Element)
 		h.addHeaderSection(viewName, *row, col)
 		*row += 2
 		h.AddKeySection(viewName, viewKeys.Keys, row, col)
@@ -172,7 +174,7 @@ func (h *Help) setStyle() {
 
 func (h *Help) setStaticLayout() {
 	h.Table.SetBorder(true)
-	h.Table.SetTitle(" Help ")
+	h.Table.SetTitle(" " + config.Msg("help.title") + " ")
 	h.Table.SetBorderPadding(1, 1, 3, 3)
 	h.Table.SetSelectable(false, false)
 	h.Table.SetTitleAlign(tview.AlignLeft)
@@ -12,7 +12,6 @@ import (
 	"github.com/kopecmaciej/vi-mongo/internal/tui/component"
 	"github.com/kopecmaciej/vi-mongo/internal/tui/core"
 	"github.com/kopecmaciej/vi-mongo/internal/tui/modal"
-	"github.com/rs/zerolog/log"
 )
 
 const (
@@ -28,16 +27,33 @@ type Main struct {
 	header    *component.Header
 	databases *component.Database
 	content   *component.Content
+	slowOps   *modal.SlowOps
+	users     *modal.Users
+	dashboard *modal.ServerDashboard
+	sharding  *modal.Sharding
+	tutorial  *component.Tutorial
+	// announcement describes the selected row or node in plain text
+	// in accessible mode, at the same place of the screen
+	announcement *core.TextView
+	// highlighted is the box highlighted by the tutorial with its border color
+	highlighted      *tview.Box
+	highlightedColor tcell.Color
 }
 
 func NewMain() *Main {
 	m := &Main{
-		BaseElement: core.NewBaseElement(),
-		Flex:        core.NewFlex(),
-		innerFlex:   core.NewFlex(),
-		header:      component.NewHeader(),
-		databases:   component.NewDatabase(),
-		content:     component.NewContent(),
+		BaseElement:  core.NewBaseElement(),
+		Flex:         core.NewFlex(),
+		innerFlex:    core.NewFlex(),
+		header:       component.NewHeader(),
+		databases:    component.NewDatabase(),
+		content:      component.NewContent(),
+		slowOps:      modal.NewSlowOpsModal(),
+		users:        modal.NewUsersModal(),
+		dashboard:    modal.NewServerDashboardModal(),
+		sharding:     modal.NewShardingModal(),
+		tutorial:     component.NewTutorial(),
+		announcement: core.NewTextView(),
 	}
 
 	m.SetIdentifier(MainPage)
@@ -59,6 +75,7 @@ func (m *Main) setStyles() {
 	m.SetStyle(m.App.GetStyles())
 	m.innerFlex.SetStyle(m.App.GetStyles())
 	m.innerFlex.SetDirection(tview.FlexRow)
+	m.announcement.SetStyle(m.App.GetStyles())
 }
 
 func (m *Main) handleEvents() {
@@ -67,7 +84,7 @@ func (m *Main) handleEvents() {
 		case manager.StyleChanged:
 			m.setStyles()
 		}
-	})
+	}, manager.StyleChanged)
 }
 
 func (m *Main) Render() {
@@ -80,6 +97,13 @@ func (m *Main) Render() {
 	m.render()
 }
 
+// OpenNamespace selects the collection in the database tree
+// and shows its documents
+func (m *Main) OpenNamespace(ctx context.Context, db, coll string) error {
+	m.databases.SelectCollection(db, coll)
+	return m.content.HandleDatabaseSelection(ctx, db, coll)
+}
+
 // UpdateDao updates the dao in the components
 func (m *Main) UpdateDao(dao *mongo.Dao) {
 	m.databases.UpdateDao(dao)
@@ -97,6 +121,30 @@ func (m *Main) initComponents() error {
 	if err := m.content.Init(m.App); err != nil {
 		return err
 	}
+	if err := m.slowOps.Init(m.App); err != nil {
+		return err
+	}
+	if err := m.users.Init(m.App); err != nil {
+		return err
+	}
+	if err := m.dashboard.Init(m.App); err != nil {
+		return err
+	}
+	if err := m.sharding.Init(m.App); err != nil {
+		return err
+	}
+	if err := m.tutorial.Init(m.App); err != nil {
+		return err
+	}
+	m.header.SetNamespaceFunc(m.content.Namespace)
+	m.slowOps.SetLoadFunc(m.loadSlowOps)
+	m.slowOps.SetKillFunc(m.killSlowOp)
+	m.tutorial.SetHighlightFunc(m.highlight)
+	m.tutorial.SetFinishFunc(func() {
+		m.innerFlex.RemoveItem(m.tutorial)
+	})
+	m.databases.SetAnnounceFunc(m.announce)
+	m.content.SetAnnounceFunc(m.announce)
 	return nil
 }
 
@@ -108,6 +156,12 @@ func (m *Main) render() error {
 	m.AddItem(m.innerFlex, 0, 7, false)
 	m.innerFlex.AddItem(m.header, 4, 0, false)
 	m.innerFlex.AddItem(m.content, 0, 7, true)
+	if m.tutorial.IsRunning() {
+		m.innerFlex.AddItem(m.tutorial, tutorialHeight, 0, false)
+	}
+	if m.App.IsAccessible() {
+		m.innerFlex.AddItem(m.announcement, 1, 0, false)
+	}
 
 	m.App.Pages.AddPage(m.GetIdentifier(), m, true, true)
 	m.App.SetFocus(m)
@@ -115,9 +169,26 @@ func (m *Main) render() error {
 	return nil
 }
 
+// announce shows the description of the selected element
+func (m *Main) announce(text string) {
+	m.announcement.SetText(tview.Escape(text))
+}
+
 func (m *Main) setKeybindings() {
 	k := m.App.GetKeys()
 	m.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if k.Contains(k.Main.ToggleTutorial, event.Name()) {
+			m.toggleTutorial()
+			return nil
+		}
+		if m.tutorial.IsRunning() {
+			// the step is checked once the key is handled
+			focused := m.focusedIdentifier()
+			go m.App.QueueUpdateDraw(func() {
+				m.tutorial.HandleKey(event, focused)
+			})
+		}
+
 		switch {
 		case k.Contains(k.Main.ToggleFocus, event.Name()):
 			if m.App.GetFocus() == m.databases.DbTree {
@@ -142,27 +213,78 @@ func (m *Main) setKeybindings() {
 			}
 			return nil
 		case k.Contains(k.Main.ShowServerInfo, event.Name()):
-			m.ShowServerInfoModal()
+			m.showServerDashboard()
+			return nil
+		case k.Contains(k.Main.ToggleReadPreference, event.Name()):
+			m.toggleReadPreference()
+			return nil
+		case k.Contains(k.Main.ShowSlowOps, event.Name()):
+			m.showSlowOps()
+			return nil
+		case k.Contains(k.Main.ShowUsers, event.Name()):
+			m.showUsers()
+			return nil
+		case k.Contains(k.Main.ShowSharding, event.Name()):
+			m.sharding.UpdateDao(m.Dao)
+			m.sharding.Render()
 			return nil
 		}
 		return event
 	})
 }
 
-func (m *Main) ShowServerInfoModal() {
-	serverInfoModal := modal.NewServerInfoModal(m.Dao)
-	if err := serverInfoModal.Init(m.App); err != nil {
-		log.Error().Err(err).Msg("Failed to initialize server info modal")
-		return
+// toggleReadPreference switches reads between primary and secondaryPreferred
+// for the current connection, documents are reloaded from the new source
+func (m *Main) toggleReadPreference() {
+	m.Dao.SetReadSecondary(m.Dao.ReadPreference() != mongo.ReadSecondaryPreferred)
+	m.header.Render()
+	m.content.Refresh(m.App.Context())
+}
+
+// slowOpsWindow is how far back the profiler is looked through
+const slowOpsWindow = 5 * time.Minute
+
+// showSlowOps opens the slow operations panel, refreshed
+// in the configured interval while it's open
+func (m *Main) showSlowOps() {
+	interval := time.Duration(m.App.GetConfig().SlowOps.Interval) * time.Second
+	if interval <= 0 {
+		interval = 2 * time.Second
 	}
+	m.slowOps.Render(interval)
+}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
+// loadSlowOps lists running operations and operations profiled
+// in the database of the opened collection
+func (m *Main) loadSlowOps(ctx context.Context) ([]mongo.SlowOp, error) {
+	db, _ := m.content.Namespace()
+	threshold := time.Duration(m.App.GetConfig().SlowOps.Threshold) * time.Millisecond
+	return m.Dao.ListSlowOps(ctx, db, threshold, time.Now().Add(-slowOpsWindow))
+}
 
-	if err := serverInfoModal.Render(ctx); err != nil {
-		log.Error().Err(err).Msg("Failed to render server info modal")
+func (m *Main) killSlowOp(op mongo.SlowOp) {
+	if err := m.Dao.KillOp(m.App.Context(), op.OpID); err != nil {
+		modal.ShowError(m.App.Pages, "Error killing operation", err)
 		return
 	}
+	modal.ShowInfo(m.App.Pages, "Operation killed")
+}
+
+// showUsers opens the users panel with users of the database of the
+// opened collection, or users of all databases if none is opened
+func (m *Main) showUsers() {
+	db, _ := m.content.Namespace()
+	m.users.UpdateDao(m.Dao)
+	m.users.Render(db)
+}
 
-	m.App.Pages.AddPage(modal.ServerInfoModalView, serverInfoModal, true, true)
+// showServerDashboard opens the server dashboard, polled
+// in the configured interval while it's open
+func (m *Main) showServerDashboard() {
+	interval := time.Duration(m.App.GetConfig().ServerStatus.DashboardInterval) * time.Second
+	if interval <= 0 {
+		interval = 2 * time.Second
+	}
+	m.dashboard.UpdateDao(m.Dao)
+	m.dashboard.Render(interval)
 }
@@ -0,0 +1,130 @@
+package page
+
+import (
+	"fmt"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/kopecmaciej/tview"
+	"github.com/kopecmaciej/vi-mongo/internal/config"
+	"github.com/kopecmaciej/vi-mongo/internal/tui/component"
+)
+
+// tutorialHeight is the height of the tour panel below the content
+const tutorialHeight = 5
+
+// toggleTutorial starts the guided tour, or stops it if it's running
+func (m *Main) toggleTutorial() {
+	if m.tutorial.IsRunning() {
+		m.tutorial.Stop()
+		return
+	}
+	m.innerFlex.AddItem(m.tutorial, tutorialHeight, 0, false)
+	m.tutorial.Start(m.tutorialSteps())
+}
+
+// tutorialSteps walks through the main views, keys are taken from
+// the current keybindings, so changed keys are shown
+func (m *Main) tutorialSteps() []component.TutorialStep {
+	k := m.App.GetKeys()
+	color := m.App.GetStyles().Header.KeyColor.Color()
+	key := func(key config.Key) string {
+		return fmt.Sprintf("[%s]%s[-]", color, key.String())
+	}
+	pressed := func(key config.Key, in ...tview.Identifier) func(*tcell.EventKey, tview.Identifier) bool {
+		return func(event *tcell.EventKey, focused tview.Identifier) bool {
+			if !k.Contains(key, event.Name()) {
+				return false
+			}
+			for _, id := range in {
+				if focused == id {
+					return true
+				}
+			}
+			return len(in) == 0
+		}
+	}
+
+	return []component.TutorialStep{
+		{
+			Text:   fmt.Sprintf("Databases and their collections are listed on the left. Press %s to focus them.", key(k.Main.FocusDatabase)),
+			Target: component.DatabaseTreeComponent,
+			Done: func(*tcell.EventKey, tview.Identifier) bool {
+				return m.focusedIdentifier() == component.DatabaseTreeComponent
+			},
+		},
+		{
+			Text:   fmt.Sprintf("Move with j/k, expand the database and open a collection with [%s]Enter[-]. Press %s to filter the list.", color, key(k.Database.FilterBar)),
+			Target: component.DatabaseTreeComponent,
+			Done: func(event *tcell.EventKey, focused tview.Identifier) bool {
+				_, coll := m.content.Namespace()
+				return focused == component.DatabaseTreeComponent && event.Key() == tcell.KeyEnter && coll != ""
+			},
+		},
+		{
+			Text:   fmt.Sprintf("Documents of the collection are shown here. Press %s to move to them, %s goes back to databases.", key(k.Main.FocusContent), key(k.Main.FocusDatabase)),
+			Target: component.ContentComponent,
+			Done: func(*tcell.EventKey, tview.Identifier) bool {
+				return m.focusedIdentifier() == component.ContentComponent
+			},
+		},
+		{
+			Text:   fmt.Sprintf("Press %s to filter documents, e.g. { status: \"active\" }, and [%s]Enter[-] to run the query.", key(k.Content.ToggleQuery), color),
+			Target: component.ContentComponent,
+			Done: func(event *tcell.EventKey, focused tview.Identifier) bool {
+				return focused == component.QueryBarComponent && event.Key() == tcell.KeyEnter
+			},
+		},
+		{
+			Text:   fmt.Sprintf("Press %s to peek the selected document, %s edits it in your editor and %s adds a new one.", key(k.Content.PeekDocument), key(k.Content.EditDocument), key(k.Content.AddDocument)),
+			Target: component.ContentComponent,
+			Done:   pressed(k.Content.PeekDocument, component.ContentComponent),
+		},
+		{
+			Text:   fmt.Sprintf("Connection details are in the header, press %s for the server dashboard.", key(k.Main.ShowServerInfo)),
+			Target: component.HeaderComponent,
+			Done:   pressed(k.Main.ShowServerInfo),
+		},
+		{
+			Text: fmt.Sprintf("That's all! Keys of the focused view are listed in the header, %s shows all of them and %s switches connections. Press any key to finish.",
+				key(k.Global.ToggleFullScreenHelp), key(k.Global.OpenConnection)),
+			Done: func(*tcell.EventKey, tview.Identifier) bool {
+				return true
+			},
+		},
+	}
+}
+
+// focusedIdentifier returns the identifier of the focused element
+func (m *Main) focusedIdentifier() tview.Identifier {
+	if focused := m.App.GetFocus(); focused != nil {
+		return focused.GetIdentifier()
+	}
+	return ""
+}
+
+// highlight sets the border of the tutorial target to the focus color,
+// the previous color is restored when the highlight is removed
+func (m *Main) highlight(target tview.Identifier, on bool) {
+	if !on {
+		if m.highlighted != nil {
+			m.highlighted.SetBorderColor(m.highlightedColor)
+			m.highlighted = nil
+		}
+		return
+	}
+
+	var box *tview.Box
+	switch target {
+	case component.DatabaseTreeComponent:
+		box = m.databases.DbTree.Box
+	case component.ContentComponent:
+		box = m.content.TableBox()
+	case component.HeaderComponent:
+		box = m.header.Box
+	}
+	if box == nil {
+		return
+	}
+	m.highlighted, m.highlightedColor = box, box.GetBorderColor()
+	box.SetBorderColor(m.App.GetStyles().Global.FocusColor.Color())
+}
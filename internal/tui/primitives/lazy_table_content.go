@@ -0,0 +1,69 @@
+package primitives
+
+import (
+	"github.com/kopecmaciej/tview"
+)
+
+// LazyTableContent is a read-only table content that creates cells only
+// when the table asks for them, which is when they are drawn. Tables with
+// many rows and columns are rendered without creating all of their cells.
+type LazyTableContent struct {
+	tview.TableContentReadOnly
+
+	rows    int
+	columns int
+	newCell func(row, column int) *tview.TableCell
+	// cells are cached, so the same cell is returned every time it's drawn
+	cells map[int][]*tview.TableCell
+}
+
+// NewLazyTableContent returns a content of given size, newCell is called
+// once for every cell that is requested
+func NewLazyTableContent(rows, columns int, newCell func(row, column int) *tview.TableCell) *LazyTableContent {
+	return &LazyTableContent{
+		rows:    rows,
+		columns: columns,
+		newCell: newCell,
+		cells:   make(map[int][]*tview.TableCell),
+	}
+}
+
+// GetCell returns the cell at given position, creating it if needed
+func (lc *LazyTableContent) GetCell(row, column int) *tview.TableCell {
+	if row < 0 || row >= lc.rows || column < 0 || column >= lc.columns {
+		return nil
+	}
+
+	cells, ok := lc.cells[row]
+	if !ok {
+		cells = make([]*tview.TableCell, lc.columns)
+		lc.cells[row] = cells
+	}
+	if cells[column] == nil {
+		cells[column] = lc.newCell(row, column)
+	}
+
+	return cells[column]
+}
+
+// ResetRow drops cached cells of the row,
+// so they are created again next time they are drawn
+func (lc *LazyTableContent) ResetRow(row int) {
+	delete(lc.cells, row)
+}
+
+// GetRowCount returns the number of rows
+func (lc *LazyTableContent) GetRowCount() int {
+	return lc.rows
+}
+
+// GetColumnCount returns the number of columns
+func (lc *LazyTableContent) GetColumnCount() int {
+	return lc.columns
+}
+
+// Clear removes all cells
+func (lc *LazyTableContent) Clear() {
+	lc.rows, lc.columns = 0, 0
+	lc.cells = make(map[int][]*tview.TableCell)
+}
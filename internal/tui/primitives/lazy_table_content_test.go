@@ -0,0 +1,55 @@
+package primitives
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/kopecmaciej/tview"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLazyTableContent(t *testing.T) {
+	created := 0
+	content := NewLazyTableContent(500, 40, func(row, column int) *tview.TableCell {
+		created++
+		return tview.NewTableCell(fmt.Sprintf("%d:%d", row, column))
+	})
+
+	assert.Equal(t, 500, content.GetRowCount())
+	assert.Equal(t, 40, content.GetColumnCount())
+	assert.Nil(t, content.GetCell(500, 0))
+	assert.Nil(t, content.GetCell(0, -1))
+
+	cell := content.GetCell(10, 5)
+	assert.Equal(t, "10:5", cell.Text)
+	assert.Same(t, cell, content.GetCell(10, 5))
+	assert.Equal(t, 1, created)
+
+	content.ResetRow(10)
+	assert.NotSame(t, cell, content.GetCell(10, 5))
+	assert.Equal(t, 2, created)
+
+	content.Clear()
+	assert.Equal(t, 0, content.GetRowCount())
+	assert.Nil(t, content.GetCell(10, 5))
+}
+
+func TestLazyTableContentDrawsOnlyVisibleCells(t *testing.T) {
+	created := 0
+	content := NewLazyTableContent(500, 40, func(row, column int) *tview.TableCell {
+		created++
+		return tview.NewTableCell("value")
+	})
+
+	screen := tcell.NewSimulationScreen("")
+	assert.NoError(t, screen.Init())
+	screen.SetSize(80, 20)
+
+	table := tview.NewTable().SetContent(content)
+	table.SetRect(0, 0, 80, 20)
+	table.Draw(screen)
+
+	assert.Greater(t, created, 0)
+	assert.Less(t, created, 20*40)
+}
@@ -10,12 +10,18 @@ type ListModal struct {
 	*tview.Box
 
 	list *tview.List
+
+	// search is an optional input field displayed above the list
+	search     *tview.InputField
+	showSearch bool
+	searching  bool
 }
 
 func NewListModal() *ListModal {
 	return &ListModal{
-		Box:  tview.NewBox(),
-		list: tview.NewList(),
+		Box:    tview.NewBox(),
+		list:   tview.NewList(),
+		search: tview.NewInputField(),
 	}
 }
 
@@ -37,6 +43,12 @@ func (lm *ListModal) Draw(screen tcell.Screen) {
 	// add padding to the list
 	x, y, width, height = x+1, y+1, width-2, height-2
 
+	if lm.showSearch {
+		lm.search.SetRect(x, y, width, 1)
+		lm.search.Draw(screen)
+		y, height = y+2, height-2
+	}
+
 	// Set the list's dimensions and position and draw it
 	lm.list.SetRect(x, y, width, height)
 	lm.list.Draw(screen)
@@ -66,6 +78,12 @@ func (lm *ListModal) GetCurrentItem() int {
 	return lm.list.GetCurrentItem()
 }
 
+// SetCurrentItem selects the item at the given index
+func (lm *ListModal) SetCurrentItem(index int) *ListModal {
+	lm.list.SetCurrentItem(index)
+	return lm
+}
+
 // Clear removes all items from the list
 func (lm *ListModal) Clear() *ListModal {
 	lm.list.Clear()
@@ -96,9 +114,56 @@ func (lm *ListModal) SetSelectedStyle(style tcell.Style) *ListModal {
 	return lm
 }
 
+// EnableSearch shows the search input above the list, changed func
+// is called every time the search text changes
+func (lm *ListModal) EnableSearch(label string, changed func(text string)) *ListModal {
+	lm.showSearch = true
+	lm.search.SetLabel(label)
+	lm.search.SetChangedFunc(changed)
+	return lm
+}
+
+// SetSearching sets whether or not key events are passed to the search input
+func (lm *ListModal) SetSearching(searching bool) *ListModal {
+	lm.searching = searching && lm.showSearch
+	return lm
+}
+
+// IsSearching returns true if key events are passed to the search input
+func (lm *ListModal) IsSearching() bool {
+	return lm.searching
+}
+
+// GetSearchText returns the text of the search input
+func (lm *ListModal) GetSearchText() string {
+	return lm.search.GetText()
+}
+
+// SetSearchText sets the text of the search input
+func (lm *ListModal) SetSearchText(text string) *ListModal {
+	lm.search.SetText(text)
+	return lm
+}
+
+// SetSearchStyle sets the style of the search input
+func (lm *ListModal) SetSearchStyle(label, field tcell.Style) *ListModal {
+	lm.search.SetLabelStyle(label)
+	lm.search.SetFieldStyle(field)
+	return lm
+}
+
+// GetItemCount returns the number of items in the list
+func (lm *ListModal) GetItemCount() int {
+	return lm.list.GetItemCount()
+}
+
 // InputHandler returns the handler for this primitive.
 func (lm *ListModal) InputHandler() func(event *tcell.EventKey, setFocus func(p tview.Primitive)) {
 	return lm.WrapInputHandler(func(event *tcell.EventKey, setFocus func(p tview.Primitive)) {
+		if lm.searching {
+			lm.search.InputHandler()(event, setFocus)
+			return
+		}
 		lm.list.InputHandler()(event, setFocus)
 	})
 }
@@ -6,6 +6,11 @@ import (
 	"os"
 	"path/filepath"
 	"reflect"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
 
 	"github.com/adrg/xdg"
 	"gopkg.in/yaml.v3"
@@ -13,6 +18,32 @@ import (
 
 const (
 	ConfigDir = "vi-mongo"
+	// ConfigDirEnv points at the directory used instead of the default
+	// config directory, e.g. to keep separate profiles
+	ConfigDirEnv = "VI_MONGO_CONFIG_DIR"
+	// PortableEnv turns on the portable mode when it's set
+	PortableEnv = "VI_MONGO_PORTABLE"
+	// PortableDir is the config directory next to the binary in portable mode
+	PortableDir = "vi-mongo-config"
+	// ProfileEnv is the name of the profile used when it's not set by the flag
+	ProfileEnv = "VI_MONGO_PROFILE"
+	// ProfilesDir is the directory of profiles in the config directory
+	ProfilesDir = "profiles"
+	// DefaultProfile uses files of the config directory itself
+	DefaultProfile = "default"
+)
+
+var (
+	// configDirOverride is the config directory set from the command line
+	configDirOverride string
+	// profile is the name of the current profile, empty for the default one
+	profile string
+
+	profileNameRegex = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+	configWarningsMu sync.Mutex
+	// configWarnings are warnings of the last load of every config file
+	configWarnings = map[string][]*ConfigError{}
 )
 
 // MergeConfigs merges the loaded config with the default config
@@ -31,16 +62,62 @@ func mergeConfigsRecursive(loaded, defaultValue reflect.Value) {
 			if field.String() == "" {
 				field.Set(defaultField)
 			}
+		case reflect.Int, reflect.Int64:
+			if field.Int() == 0 {
+				field.Set(defaultField)
+			}
 		case reflect.Slice:
-			if field.Len() == 0 {
+			// empty slice is set explicitly, e.g. to disable a key,
+			// so only missing values are taken from defaults
+			if field.IsNil() {
 				field.Set(defaultField)
 			}
+		case reflect.Pointer:
+			// pointers are used for numbers where 0 is meaningful,
+			// e.g. to turn something off, so only missing ones are
+			// taken from defaults, copied so defaults aren't shared
+			if field.IsNil() && !defaultField.IsNil() {
+				value := reflect.New(defaultField.Type().Elem())
+				value.Elem().Set(defaultField.Elem())
+				field.Set(value)
+			}
 		case reflect.Struct:
 			mergeConfigsRecursive(field, defaultField)
 		}
 	}
 }
 
+// ResetSection sets the field of config which has given name in the struct
+// tag to its default value
+func ResetSection(config, defaultConfig interface{}, tagName, section string) error {
+	loaded := reflect.ValueOf(config).Elem()
+	defaultValue := reflect.ValueOf(defaultConfig).Elem()
+
+	sections := []string{}
+	for i := 0; i < loaded.NumField(); i++ {
+		name := strings.Split(loaded.Type().Field(i).Tag.Get(tagName), ",")[0]
+		if name == section {
+			loaded.Field(i).Set(defaultValue.Field(i))
+			return nil
+		}
+		sections = append(sections, name)
+	}
+
+	return fmt.Errorf("unknown section %q, available sections: %s", section, strings.Join(sections, ", "))
+}
+
+// WriteConfigFile writes config to the file under given path
+func WriteConfigFile[T any](config *T, configPath string) error {
+	return WithFileLock(configPath, func() error {
+		bytes, err := marshalConfig(config, configPath)
+		if err != nil {
+			return err
+		}
+
+		return WriteFileAtomic(configPath, bytes, 0644)
+	})
+}
+
 // LoadConfigFile loads a configuration file, merges it with defaults, and returns the result
 func LoadConfigFile[T any](defaultConfig *T, configPath string) (*T, error) {
 	// Ensure the config directory exists
@@ -53,12 +130,9 @@ func LoadConfigFile[T any](defaultConfig *T, configPath string) (*T, error) {
 	bytes, err := os.ReadFile(configPath)
 	if err != nil {
 		if os.IsNotExist(err) {
+			setConfigWarnings(configPath, nil)
 			// If the file does not exist, create it with default settings
-			bytes, err = marshalConfig(defaultConfig, configPath)
-			if err != nil {
-				return nil, err
-			}
-			err = os.WriteFile(configPath, bytes, 0644)
+			err = writeDefaultConfig(defaultConfig, configPath)
 			if err != nil {
 				return nil, err
 			}
@@ -67,19 +141,44 @@ func LoadConfigFile[T any](defaultConfig *T, configPath string) (*T, error) {
 		return nil, err
 	}
 
-	// Unmarshal the config file
+	// Unmarshal the config file, unknown keys don't stop it from loading,
+	// e.g. keys removed in newer versions
 	config := new(T)
-	err = unmarshalConfig(bytes, configPath, config)
+	warnings, err := unmarshalConfig(bytes, configPath, config)
 	if err != nil {
 		return nil, err
 	}
+	setConfigWarnings(configPath, warnings)
 
 	// Merge loaded config with default config
 	MergeConfigs(config, defaultConfig)
 
+	// values missing in the file are validated along with set ones
+	err = validateConfig(bytes, configPath, config)
+	if err != nil {
+		return nil, err
+	}
+
 	return config, nil
 }
 
+// writeDefaultConfig creates config file with default settings, unless
+// other running instance has created it in the meantime
+func writeDefaultConfig[T any](defaultConfig *T, configPath string) error {
+	return WithFileLock(configPath, func() error {
+		if _, err := os.Stat(configPath); err == nil {
+			return nil
+		}
+
+		bytes, err := marshalConfig(defaultConfig, configPath)
+		if err != nil {
+			return err
+		}
+
+		return WriteFileAtomic(configPath, bytes, 0644)
+	})
+}
+
 // marshalConfig marshals the config based on the file extension
 func marshalConfig[T any](config *T, configPath string) ([]byte, error) {
 	switch filepath.Ext(configPath) {
@@ -93,15 +192,45 @@ func marshalConfig[T any](config *T, configPath string) ([]byte, error) {
 }
 
 // unmarshalConfig unmarshals the config based on the file extension
-func unmarshalConfig[T any](data []byte, configPath string, config *T) error {
+func unmarshalConfig[T any](data []byte, configPath string, config *T) ([]*ConfigError, error) {
 	switch filepath.Ext(configPath) {
 	case ".json":
-		return json.Unmarshal(data, config)
+		return unmarshalJson(data, configPath, config)
 	case ".yaml", ".yml":
-		return yaml.Unmarshal(data, config)
+		return unmarshalYaml(data, configPath, config)
 	default:
-		return fmt.Errorf("unsupported file extension: %s", configPath)
+		return nil, fmt.Errorf("unsupported file extension: %s", configPath)
+	}
+}
+
+// setConfigWarnings replaces warnings of the config file
+// with those found while it was loaded again
+func setConfigWarnings(configPath string, warnings []*ConfigError) {
+	configWarningsMu.Lock()
+	defer configWarningsMu.Unlock()
+	if len(warnings) == 0 {
+		delete(configWarnings, configPath)
+		return
+	}
+	configWarnings[configPath] = warnings
+}
+
+// ConfigWarnings returns problems found in loaded config files which
+// don't stop them from loading, e.g. unknown keys, sorted by file
+func ConfigWarnings() []*ConfigError {
+	configWarningsMu.Lock()
+	defer configWarningsMu.Unlock()
+	paths := make([]string, 0, len(configWarnings))
+	for path := range configWarnings {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	warnings := []*ConfigError{}
+	for _, path := range paths {
+		warnings = append(warnings, configWarnings[path]...)
 	}
+	return warnings
 }
 
 // ensureConfigDirExist ensures the config directory exists
@@ -117,11 +246,134 @@ func ensureConfigDirExist() error {
 	return nil
 }
 
-// GetConfigDir returns the path to the config directory
+// GetConfigDir returns the path to the config directory of the current
+// profile, every profile has its own directory in the base config directory
 func GetConfigDir() (string, error) {
+	baseDir, err := baseConfigDir()
+	if err != nil {
+		return "", err
+	}
+	if profile == "" {
+		return baseDir, nil
+	}
+	return filepath.Join(baseDir, ProfilesDir, profile), nil
+}
+
+// baseConfigDir returns the directory set from the command line, the one
+// from the environment or the XDG config directory, in this order
+func baseConfigDir() (string, error) {
+	if configDirOverride != "" {
+		return configDirOverride, nil
+	}
+	if dir := os.Getenv(ConfigDirEnv); dir != "" {
+		return filepath.Abs(dir)
+	}
 	configPath, err := xdg.ConfigFile(ConfigDir)
 	if err != nil {
 		return "", err
 	}
 	return configPath, nil
 }
+
+// SetConfigDir sets the directory all config files are stored in,
+// empty dir brings back the default one
+func SetConfigDir(dir string) error {
+	if dir == "" {
+		configDirOverride = ""
+		return nil
+	}
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return err
+	}
+	configDirOverride = abs
+	return nil
+}
+
+// ConfigureConfigDir sets the config directory from command line flags.
+// Dir takes precedence over the environment. In portable mode config
+// is stored next to the binary, the mode is on when it's requested
+// by the flag or the environment, or when such directory already exists.
+// It returns true if the portable mode is on.
+func ConfigureConfigDir(dir string, portable bool) (bool, error) {
+	if dir != "" {
+		return false, SetConfigDir(dir)
+	}
+	if os.Getenv(ConfigDirEnv) != "" {
+		return false, nil
+	}
+
+	portableDir, err := PortableConfigDir()
+	if err != nil {
+		if portable {
+			return false, err
+		}
+		return false, nil
+	}
+	if !portable && os.Getenv(PortableEnv) == "" {
+		if info, err := os.Stat(portableDir); err != nil || !info.IsDir() {
+			return false, nil
+		}
+	}
+	return true, SetConfigDir(portableDir)
+}
+
+// SetProfile switches config files to the profile with given name,
+// empty name or "default" brings back the default profile
+func SetProfile(name string) error {
+	if name == "" || name == DefaultProfile {
+		profile = ""
+		return nil
+	}
+	if !profileNameRegex.MatchString(name) {
+		return fmt.Errorf("invalid profile name %q, only letters, digits, - and _ are allowed", name)
+	}
+	profile = name
+	return nil
+}
+
+// GetProfile returns the name of the current profile
+func GetProfile() string {
+	if profile == "" {
+		return DefaultProfile
+	}
+	return profile
+}
+
+// ListProfiles returns the default profile followed by names
+// of other profiles, including the current one
+func ListProfiles() ([]string, error) {
+	baseDir, err := baseConfigDir()
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(filepath.Join(baseDir, ProfilesDir))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	names := []string{}
+	for _, entry := range entries {
+		if entry.IsDir() && profileNameRegex.MatchString(entry.Name()) {
+			names = append(names, entry.Name())
+		}
+	}
+	if profile != "" && !slices.Contains(names, profile) {
+		names = append(names, profile)
+	}
+	sort.Strings(names)
+	return append([]string{DefaultProfile}, names...), nil
+}
+
+// PortableConfigDir returns the config directory of the portable mode,
+// which is next to the binary
+func PortableConfigDir() (string, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return "", err
+	}
+	if resolved, err := filepath.EvalSymlinks(exe); err == nil {
+		exe = resolved
+	}
+	return filepath.Join(filepath.Dir(exe), PortableDir), nil
+}
@@ -0,0 +1,190 @@
+package util
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mergeTestConfig struct {
+	Name   string
+	Limit  int
+	Keys   []string
+	Nested struct {
+		Runes []string
+	}
+}
+
+func TestMergeConfigs(t *testing.T) {
+	defaults := &mergeTestConfig{Name: "default", Limit: 10, Keys: []string{"Enter"}}
+	defaults.Nested.Runes = []string{"a"}
+
+	t.Run("Missing values are taken from defaults", func(t *testing.T) {
+		loaded := &mergeTestConfig{}
+		MergeConfigs(loaded, defaults)
+		assert.Equal(t, "default", loaded.Name)
+		assert.Equal(t, 10, loaded.Limit)
+		assert.Equal(t, []string{"Enter"}, loaded.Keys)
+		assert.Equal(t, []string{"a"}, loaded.Nested.Runes)
+	})
+
+	t.Run("Empty slices are kept", func(t *testing.T) {
+		loaded := &mergeTestConfig{Keys: []string{}}
+		loaded.Nested.Runes = []string{}
+		MergeConfigs(loaded, defaults)
+		assert.Empty(t, loaded.Keys)
+		assert.NotNil(t, loaded.Keys)
+		assert.Empty(t, loaded.Nested.Runes)
+	})
+
+	t.Run("Set values are kept", func(t *testing.T) {
+		loaded := &mergeTestConfig{Name: "custom", Limit: 5, Keys: []string{"Esc"}}
+		MergeConfigs(loaded, defaults)
+		assert.Equal(t, "custom", loaded.Name)
+		assert.Equal(t, 5, loaded.Limit)
+		assert.Equal(t, []string{"Esc"}, loaded.Keys)
+	})
+}
+
+type loadTestConfig struct {
+	Limit  int `yaml:"limit" json:"limit"`
+	Pages  int `yaml:"pages" json:"pages"`
+	Status struct {
+		Interval *int `yaml:"interval" json:"interval"`
+		Timeout  int  `yaml:"timeout" json:"timeout"`
+	} `yaml:"status" json:"status"`
+}
+
+func TestLoadConfigFileKeepsZeroOfPointers(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, SetConfigDir(dir))
+	t.Cleanup(func() { SetConfigDir("") })
+
+	interval := 30
+	defaults := &loadTestConfig{Limit: 10, Pages: 5}
+	defaults.Status.Interval = &interval
+	defaults.Status.Timeout = 60
+
+	files := map[string]string{
+		"config.yaml": "limit: 0\nstatus:\n  interval: 0\n",
+		"config.json": `{"limit": 0, "status": {"interval": 0}}`,
+	}
+	for name, content := range files {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(dir, name)
+			require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+
+			loaded, err := LoadConfigFile(defaults, path)
+			require.NoError(t, err)
+			assert.Equal(t, 10, loaded.Limit)
+			assert.Equal(t, 5, loaded.Pages)
+			require.NotNil(t, loaded.Status.Interval)
+			assert.Equal(t, 0, *loaded.Status.Interval)
+			assert.Equal(t, 60, loaded.Status.Timeout)
+		})
+	}
+
+	path := filepath.Join(dir, "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("limit: 3\n"), 0644))
+	loaded, err := LoadConfigFile(defaults, path)
+	require.NoError(t, err)
+	require.NotNil(t, loaded.Status.Interval)
+	assert.Equal(t, 30, *loaded.Status.Interval)
+	// the default isn't shared with the loaded config
+	assert.NotSame(t, defaults.Status.Interval, loaded.Status.Interval)
+}
+
+func TestLoadConfigFileWarnsAboutUnknownKeys(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, SetConfigDir(dir))
+	t.Cleanup(func() { SetConfigDir("") })
+
+	path := filepath.Join(dir, "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("limit: 3\nremoved: true\nstatus:\n  oldInterval: 1\n"), 0644))
+	loaded, err := LoadConfigFile(&loadTestConfig{Limit: 10, Pages: 5}, path)
+	require.NoError(t, err)
+	assert.Equal(t, 3, loaded.Limit)
+	assert.Equal(t, 5, loaded.Pages)
+
+	warnings := ConfigWarnings()
+	require.Len(t, warnings, 2)
+	assert.Contains(t, warnings[0].Error(), "config.yaml:2: field removed not found")
+	assert.Contains(t, warnings[1].Error(), "config.yaml:4: field oldInterval not found")
+
+	// warnings are cleared once the file is fixed
+	require.NoError(t, os.WriteFile(path, []byte("limit: 3\n"), 0644))
+	_, err = LoadConfigFile(&loadTestConfig{}, path)
+	require.NoError(t, err)
+	assert.Empty(t, ConfigWarnings())
+}
+
+func TestConfigureConfigDir(t *testing.T) {
+	t.Cleanup(func() { SetConfigDir("") })
+	t.Setenv(PortableEnv, "")
+	envDir := t.TempDir()
+	t.Setenv(ConfigDirEnv, envDir)
+
+	portable, err := ConfigureConfigDir("", false)
+	require.NoError(t, err)
+	assert.False(t, portable)
+	dir, err := GetConfigDir()
+	require.NoError(t, err)
+	assert.Equal(t, envDir, dir)
+
+	// flag takes precedence over the environment
+	flagDir := t.TempDir()
+	portable, err = ConfigureConfigDir(flagDir, true)
+	require.NoError(t, err)
+	assert.False(t, portable)
+	dir, err = GetConfigDir()
+	require.NoError(t, err)
+	assert.Equal(t, flagDir, dir)
+
+	require.NoError(t, SetConfigDir(""))
+	t.Setenv(ConfigDirEnv, "")
+	t.Setenv(PortableEnv, "1")
+	portable, err = ConfigureConfigDir("", false)
+	require.NoError(t, err)
+	assert.True(t, portable)
+	dir, err = GetConfigDir()
+	require.NoError(t, err)
+	exe, err := os.Executable()
+	require.NoError(t, err)
+	exe, err = filepath.EvalSymlinks(exe)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(filepath.Dir(exe), PortableDir), dir)
+}
+
+func TestProfiles(t *testing.T) {
+	baseDir := t.TempDir()
+	require.NoError(t, SetConfigDir(baseDir))
+	t.Cleanup(func() {
+		SetConfigDir("")
+		SetProfile("")
+	})
+
+	assert.Equal(t, DefaultProfile, GetProfile())
+	dir, err := GetConfigDir()
+	require.NoError(t, err)
+	assert.Equal(t, baseDir, dir)
+
+	assert.Error(t, SetProfile("../work"))
+	require.NoError(t, SetProfile("work"))
+	assert.Equal(t, "work", GetProfile())
+	dir, err = GetConfigDir()
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(baseDir, ProfilesDir, "work"), dir)
+
+	require.NoError(t, os.MkdirAll(filepath.Join(baseDir, ProfilesDir, "personal"), 0755))
+	profiles, err := ListProfiles()
+	require.NoError(t, err)
+	assert.Equal(t, []string{DefaultProfile, "personal", "work"}, profiles)
+
+	require.NoError(t, SetProfile(DefaultProfile))
+	dir, err = GetConfigDir()
+	require.NoError(t, err)
+	assert.Equal(t, baseDir, dir)
+}
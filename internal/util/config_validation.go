@@ -0,0 +1,239 @@
+package util
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigError describes a single problem found in a config file
+type ConfigError struct {
+	Path string
+	// Line is 1-based, 0 means the line is unknown
+	Line int
+	// Value is used to find the line if it's not known,
+	// e.g. for errors reported by ConfigValidator
+	Value string
+	Msg   string
+}
+
+func (e *ConfigError) Error() string {
+	if e.Line > 0 {
+		return fmt.Sprintf("%s:%d: %s", e.Path, e.Line, e.Msg)
+	}
+	return fmt.Sprintf("%s: %s", e.Path, e.Msg)
+}
+
+// ConfigValidator is implemented by configs that need additional
+// validation after they are unmarshaled
+type ConfigValidator interface {
+	Validate() []ConfigError
+}
+
+var (
+	yamlLineRegexp         = regexp.MustCompile(`^(?:yaml: )?line (\d+): (.*)$`)
+	yamlUnknownFieldRegexp = regexp.MustCompile(`^field \S+ not found in type`)
+)
+
+// unmarshalYaml unmarshals yaml data, reporting wrong types with line
+// numbers, unknown fields are ignored and returned as warnings
+func unmarshalYaml(data []byte, configPath string, config any) ([]*ConfigError, error) {
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+
+	err := decoder.Decode(config)
+	if err == nil || errors.Is(err, io.EOF) {
+		return nil, nil
+	}
+
+	messages := []string{err.Error()}
+	var typeErr *yaml.TypeError
+	if errors.As(err, &typeErr) {
+		messages = typeErr.Errors
+	}
+
+	warnings := []*ConfigError{}
+	errs := []error{}
+	for _, msg := range messages {
+		configErr := &ConfigError{Path: configPath, Msg: msg}
+		if match := yamlLineRegexp.FindStringSubmatch(msg); match != nil {
+			configErr.Line, _ = strconv.Atoi(match[1])
+			configErr.Msg = match[2]
+		}
+		// the rest of the file is decoded despite unknown fields
+		if typeErr != nil && yamlUnknownFieldRegexp.MatchString(configErr.Msg) {
+			warnings = append(warnings, configErr)
+			continue
+		}
+		errs = append(errs, configErr)
+	}
+
+	return warnings, errors.Join(errs...)
+}
+
+// unmarshalJson unmarshals json data, reporting wrong types with line
+// numbers, unknown fields are ignored and returned as warnings
+func unmarshalJson(data []byte, configPath string, config any) ([]*ConfigError, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+
+	err := decoder.Decode(config)
+	if errors.Is(err, io.EOF) {
+		return nil, nil
+	}
+	if err != nil {
+		offset := decoder.InputOffset()
+		msg := strings.TrimPrefix(err.Error(), "json: ")
+
+		var syntaxErr *json.SyntaxError
+		var typeErr *json.UnmarshalTypeError
+		switch {
+		case errors.As(err, &syntaxErr):
+			offset = syntaxErr.Offset
+		case errors.As(err, &typeErr):
+			offset = typeErr.Offset
+			msg = fmt.Sprintf("field %s must be %s, got %s", typeErr.Field, typeErr.Type, typeErr.Value)
+		}
+
+		return nil, &ConfigError{Path: configPath, Line: lineAtOffset(data, offset), Msg: msg}
+	}
+
+	var value any
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil, nil
+	}
+	warnings := []*ConfigError{}
+	for _, field := range unknownJsonFields(value, reflect.TypeOf(config), "") {
+		name := field[strings.LastIndex(field, ".")+1:]
+		warnings = append(warnings, &ConfigError{
+			Path: configPath,
+			Line: lineOfValue(data, name),
+			Msg:  fmt.Sprintf("unknown field %q", field),
+		})
+	}
+
+	return warnings, nil
+}
+
+// unknownJsonFields returns paths of keys of the decoded json value
+// which have no field in the type, e.g. "content.oldKey"
+func unknownJsonFields(value any, typ reflect.Type, path string) []string {
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+
+	var unknown []string
+	switch typ.Kind() {
+	case reflect.Struct:
+		object, ok := value.(map[string]any)
+		if !ok {
+			return nil
+		}
+		names := make([]string, 0, len(object))
+		for name := range object {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			fieldPath := joinFieldPath(path, name)
+			field, ok := jsonField(typ, name)
+			if !ok {
+				unknown = append(unknown, fieldPath)
+				continue
+			}
+			unknown = append(unknown, unknownJsonFields(object[name], field.Type, fieldPath)...)
+		}
+	case reflect.Slice, reflect.Array:
+		items, _ := value.([]any)
+		for i, item := range items {
+			unknown = append(unknown, unknownJsonFields(item, typ.Elem(), fmt.Sprintf("%s[%d]", path, i))...)
+		}
+	case reflect.Map:
+		object, _ := value.(map[string]any)
+		for name, item := range object {
+			unknown = append(unknown, unknownJsonFields(item, typ.Elem(), joinFieldPath(path, name))...)
+		}
+	}
+	return unknown
+}
+
+func joinFieldPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+// jsonField returns the field of the struct the json key is decoded into,
+// keys are matched case-insensitively, as encoding/json does
+func jsonField(typ reflect.Type, key string) (reflect.StructField, bool) {
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" || !field.IsExported() {
+			continue
+		}
+		name := strings.Split(tag, ",")[0]
+		if name == "" {
+			if field.Anonymous && field.Type.Kind() == reflect.Struct {
+				if embedded, ok := jsonField(field.Type, key); ok {
+					return embedded, true
+				}
+				continue
+			}
+			name = field.Name
+		}
+		if strings.EqualFold(name, key) {
+			return field, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+// validateConfig runs additional validation if config implements
+// ConfigValidator and finds the lines of reported values
+func validateConfig(data []byte, configPath string, config any) error {
+	validator, ok := config.(ConfigValidator)
+	if !ok {
+		return nil
+	}
+
+	errs := []error{}
+	for _, configErr := range validator.Validate() {
+		configErr := configErr
+		configErr.Path = configPath
+		if configErr.Line == 0 && configErr.Value != "" {
+			configErr.Line = lineOfValue(data, configErr.Value)
+		}
+		errs = append(errs, &configErr)
+	}
+
+	return errors.Join(errs...)
+}
+
+// lineAtOffset returns 1-based line number of the byte offset
+func lineAtOffset(data []byte, offset int64) int {
+	if offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+	return bytes.Count(data[:offset], []byte("\n")) + 1
+}
+
+// lineOfValue returns line of the first occurrence of quoted value,
+// or 0 if it can't be found
+func lineOfValue(data []byte, value string) int {
+	for _, candidate := range []string{strconv.Quote(value), value} {
+		if i := bytes.Index(data, []byte(candidate)); i >= 0 {
+			return lineAtOffset(data, int64(i))
+		}
+	}
+	return 0
+}
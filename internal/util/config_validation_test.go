@@ -0,0 +1,96 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testConfig struct {
+	Name  string `yaml:"name" json:"name"`
+	Count int    `yaml:"count" json:"count"`
+	Keys  []string
+}
+
+func (c *testConfig) Validate() []ConfigError {
+	var errs []ConfigError
+	for _, k := range c.Keys {
+		if k == "bad" {
+			errs = append(errs, ConfigError{Value: k, Msg: "invalid key"})
+		}
+	}
+	return errs
+}
+
+func TestUnmarshalYaml(t *testing.T) {
+	t.Run("Valid", func(t *testing.T) {
+		config := &testConfig{}
+		warnings, err := unmarshalYaml([]byte("name: test\ncount: 1\n"), "config.yaml", config)
+		require.NoError(t, err)
+		assert.Empty(t, warnings)
+		assert.Equal(t, "test", config.Name)
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		_, err := unmarshalYaml([]byte(""), "config.yaml", &testConfig{})
+		require.NoError(t, err)
+	})
+
+	t.Run("Unknown field", func(t *testing.T) {
+		config := &testConfig{}
+		warnings, err := unmarshalYaml([]byte("nmae: typo\nname: test\n"), "config.yaml", config)
+		require.NoError(t, err)
+		assert.Equal(t, "test", config.Name)
+		require.Len(t, warnings, 1)
+		assert.Contains(t, warnings[0].Error(), "config.yaml:1: field nmae not found")
+	})
+
+	t.Run("Wrong type", func(t *testing.T) {
+		_, err := unmarshalYaml([]byte("name: test\nnmae: typo\ncount: many\n"), "config.yaml", &testConfig{})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "config.yaml:3:")
+		assert.NotContains(t, err.Error(), "nmae")
+	})
+}
+
+func TestUnmarshalJson(t *testing.T) {
+	t.Run("Valid", func(t *testing.T) {
+		config := &testConfig{}
+		warnings, err := unmarshalJson([]byte(`{"name": "test"}`), "keys.json", config)
+		require.NoError(t, err)
+		assert.Empty(t, warnings)
+		assert.Equal(t, "test", config.Name)
+	})
+
+	t.Run("Unknown field", func(t *testing.T) {
+		config := &testConfig{}
+		warnings, err := unmarshalJson([]byte("{\n  \"nmae\": \"typo\",\n  \"name\": \"test\",\n  \"old\": 1\n}"), "keys.json", config)
+		require.NoError(t, err)
+		assert.Equal(t, "test", config.Name)
+		require.Len(t, warnings, 2)
+		assert.Equal(t, `keys.json:2: unknown field "nmae"`, warnings[0].Error())
+		assert.Equal(t, `keys.json:4: unknown field "old"`, warnings[1].Error())
+	})
+
+	t.Run("Wrong type", func(t *testing.T) {
+		_, err := unmarshalJson([]byte("{\n  \"count\": \"many\"\n}"), "keys.json", &testConfig{})
+		require.Error(t, err)
+		assert.Equal(t, "keys.json:2: field count must be int, got string", err.Error())
+	})
+
+	t.Run("Syntax error", func(t *testing.T) {
+		_, err := unmarshalJson([]byte("{\n  \"name\": \"test\"\n  \"count\": 1\n}"), "keys.json", &testConfig{})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "keys.json:3:")
+	})
+}
+
+func TestValidateConfig(t *testing.T) {
+	data := []byte("{\n  \"Keys\": [\n    \"good\",\n    \"bad\"\n  ]\n}")
+	config := &testConfig{Keys: []string{"good", "bad"}}
+
+	err := validateConfig(data, "keys.json", config)
+	require.Error(t, err)
+	assert.Equal(t, "keys.json:4: invalid key", err.Error())
+}
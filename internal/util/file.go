@@ -0,0 +1,107 @@
+package util
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	lockSuffix = ".lock"
+	// lockTimeout is how long we wait for other instance to release the lock
+	lockTimeout = 2 * time.Second
+	// staleLockAge is the age after which lock is considered abandoned,
+	// e.g. when other instance crashed while holding it
+	staleLockAge = 10 * time.Second
+	lockRetry    = 20 * time.Millisecond
+)
+
+// FileLock is an advisory lock guarding a file shared between
+// multiple running instances of the application
+type FileLock struct {
+	path string
+}
+
+// LockFile acquires a lock for the file under given path. Lock is held
+// by creating a lock file next to it, which works the same on every platform.
+func LockFile(path string) (*FileLock, error) {
+	lockPath := path + lockSuffix
+	deadline := time.Now().Add(lockTimeout)
+
+	for {
+		f, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if err == nil {
+			fmt.Fprintf(f, "%d", os.Getpid())
+			f.Close()
+			return &FileLock{path: lockPath}, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, err
+		}
+
+		if info, err := os.Stat(lockPath); err == nil && time.Since(info.ModTime()) > staleLockAge {
+			os.Remove(lockPath)
+			continue
+		}
+
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("timeout waiting for lock %s", lockPath)
+		}
+		time.Sleep(lockRetry)
+	}
+}
+
+// Unlock releases the lock
+func (l *FileLock) Unlock() error {
+	return os.Remove(l.path)
+}
+
+// WithFileLock runs fn while holding the lock for the file under given path
+func WithFileLock(path string, fn func() error) error {
+	lock, err := LockFile(path)
+	if err != nil {
+		return err
+	}
+	defer lock.Unlock()
+
+	return fn()
+}
+
+// WriteFileAtomic writes data to a temporary file in the same directory
+// and renames it to the target path, so readers never see a partially
+// written file
+func WriteFileAtomic(path string, data []byte, perm os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := os.Chmod(tmpPath, perm); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+
+	return nil
+}
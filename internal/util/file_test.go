@@ -0,0 +1,66 @@
+package util
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteFileAtomic(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "history.json")
+
+	require.NoError(t, WriteFileAtomic(path, []byte("first"), 0644))
+	require.NoError(t, WriteFileAtomic(path, []byte("second"), 0644))
+
+	bytes, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "second", string(bytes))
+
+	// no temporary files should be left behind
+	files, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, files, 1)
+}
+
+func TestLockFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.json")
+
+	lock, err := LockFile(path)
+	require.NoError(t, err)
+	assert.FileExists(t, path+lockSuffix)
+
+	require.NoError(t, lock.Unlock())
+	assert.NoFileExists(t, path+lockSuffix)
+}
+
+func TestLockFile_Stale(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.json")
+	lockPath := path + lockSuffix
+
+	require.NoError(t, os.WriteFile(lockPath, []byte("1"), 0644))
+	old := time.Now().Add(-2 * staleLockAge)
+	require.NoError(t, os.Chtimes(lockPath, old, old))
+
+	lock, err := LockFile(path)
+	require.NoError(t, err)
+	require.NoError(t, lock.Unlock())
+}
+
+func TestWithFileLock(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.json")
+
+	called := false
+	err := WithFileLock(path, func() error {
+		called = true
+		assert.FileExists(t, path+lockSuffix)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.True(t, called)
+	assert.NoFileExists(t, path+lockSuffix)
+}
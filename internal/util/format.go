@@ -0,0 +1,184 @@
+package util
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/rivo/uniseg"
+)
+
+// ValueFormat is how values are displayed. Digits of the integer part of
+// numbers are grouped by ThousandsSeparator, doubles which absolute value
+// is at least ScientificThreshold, or below its inverse, are shown in
+// scientific notation and strings longer than MaxStringLength are
+// truncated. Zero value displays values as they are.
+type ValueFormat struct {
+	ThousandsSeparator  string
+	ScientificThreshold float64
+	MaxStringLength     int
+}
+
+// Value returns the value as it's displayed, values other
+// than numbers and strings are the same as of GetValueByType
+func (f ValueFormat) Value(v interface{}) string {
+	switch t := v.(type) {
+	case string:
+		return f.String(t)
+	case int:
+		return f.Int(int64(t))
+	case int32:
+		return f.Int(int64(t))
+	case int64:
+		return f.Int(t)
+	case float32:
+		return f.Double(float64(t), fmt.Sprintf("%f", t))
+	case float64:
+		return f.Double(t, fmt.Sprintf("%f", t))
+	}
+	return GetValueByType(v)
+}
+
+// Int returns the integer with grouped digits
+func (f ValueFormat) Int(n int64) string {
+	return f.group(strconv.FormatInt(n, 10))
+}
+
+// Double returns text of the double with grouped digits,
+// or the double in scientific notation if it crosses the threshold
+func (f ValueFormat) Double(n float64, text string) string {
+	if f.scientific(n) {
+		return strconv.FormatFloat(n, 'e', -1, 64)
+	}
+	return f.group(text)
+}
+
+// String returns the string truncated to MaxStringLength
+func (f ValueFormat) String(s string) string {
+	if f.MaxStringLength <= 0 {
+		return s
+	}
+	return TruncateByWidth(s, f.MaxStringLength)
+}
+
+func (f ValueFormat) scientific(n float64) bool {
+	if f.ScientificThreshold <= 0 || n == 0 || math.IsInf(n, 0) || math.IsNaN(n) {
+		return false
+	}
+	abs := math.Abs(n)
+	return abs >= f.ScientificThreshold || abs < 1/f.ScientificThreshold
+}
+
+// group inserts the separator between every three digits of the integer
+// part of the number, text in scientific notation is left as it is
+func (f ValueFormat) group(text string) string {
+	if f.ThousandsSeparator == "" || strings.ContainsAny(text, "eE") {
+		return text
+	}
+	sign := ""
+	if strings.HasPrefix(text, "-") {
+		sign, text = "-", text[1:]
+	}
+	integer, fraction := text, ""
+	if dot := strings.IndexByte(text, '.'); dot >= 0 {
+		integer, fraction = text[:dot], text[dot:]
+	}
+	if len(integer) <= 3 {
+		return sign + text
+	}
+
+	var grouped strings.Builder
+	for i, digit := range integer {
+		if i > 0 && (len(integer)-i)%3 == 0 {
+			grouped.WriteString(f.ThousandsSeparator)
+		}
+		grouped.WriteRune(digit)
+	}
+	return sign + grouped.String() + fraction
+}
+
+// JSON formats numbers and string values of the JSON text, keys are left
+// as they are. The text is meant to be read, it's not valid JSON anymore
+// if numbers are grouped.
+func (f ValueFormat) JSON(text string) string {
+	if f == (ValueFormat{}) {
+		return text
+	}
+	var out strings.Builder
+	for i := 0; i < len(text); {
+		c := text[i]
+		switch {
+		case c == '"':
+			end := jsonStringEnd(text, i)
+			token := text[i:end]
+			if isJSONKey(text, end) {
+				out.WriteString(token)
+			} else {
+				out.WriteString(f.jsonString(token))
+			}
+			i = end
+		case c == '-' || (c >= '0' && c <= '9'):
+			end := i + 1
+			for end < len(text) && strings.IndexByte("0123456789.eE+-", text[end]) >= 0 {
+				end++
+			}
+			out.WriteString(f.jsonNumber(text[i:end]))
+			i = end
+		default:
+			out.WriteByte(c)
+			i++
+		}
+	}
+	return out.String()
+}
+
+// jsonStringEnd returns the index after the closing quote
+// of the string starting at start
+func jsonStringEnd(text string, start int) int {
+	for i := start + 1; i < len(text); i++ {
+		switch text[i] {
+		case '\\':
+			i++
+		case '"':
+			return i + 1
+		}
+	}
+	return len(text)
+}
+
+// isJSONKey returns true if the string ending at end is followed by ":"
+func isJSONKey(text string, end int) bool {
+	rest := strings.TrimLeft(text[end:], " \t\r\n")
+	return strings.HasPrefix(rest, ":")
+}
+
+func (f ValueFormat) jsonString(token string) string {
+	var s string
+	if f.MaxStringLength <= 0 || json.Unmarshal([]byte(token), &s) != nil {
+		return token
+	}
+	if uniseg.StringWidth(s) <= f.MaxStringLength {
+		return token
+	}
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(f.String(s)); err != nil {
+		return token
+	}
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+func (f ValueFormat) jsonNumber(token string) string {
+	if !strings.ContainsAny(token, ".eE") {
+		return f.group(token)
+	}
+	n, err := strconv.ParseFloat(token, 64)
+	if err != nil {
+		return token
+	}
+	return f.Double(n, token)
+}
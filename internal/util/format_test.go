@@ -0,0 +1,57 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValueFormat(t *testing.T) {
+	format := ValueFormat{ThousandsSeparator: ",", ScientificThreshold: 1e9, MaxStringLength: 5}
+
+	testCases := []struct {
+		name     string
+		value    interface{}
+		expected string
+	}{
+		{"Small int", int32(999), "999"},
+		{"Large int", int64(1234567), "1,234,567"},
+		{"Negative int", -12345, "-12,345"},
+		{"Double", 12345.5, "12,345.500000"},
+		{"Large double", 1.5e12, "1.5e+12"},
+		{"Tiny double", 0.0000000001, "1e-10"},
+		{"Zero double", 0.0, "0.000000"},
+		{"Short string", "abc", "abc"},
+		{"Long string", "abcdefgh", "abcde..."},
+		{"Bool", true, "true"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, format.Value(tc.value))
+		})
+	}
+
+	assert.Equal(t, "1234567", ValueFormat{}.Value(1234567))
+	assert.Equal(t, "abcdefgh", ValueFormat{}.Value("abcdefgh"))
+}
+
+func TestValueFormatJSON(t *testing.T) {
+	format := ValueFormat{ThousandsSeparator: " ", ScientificThreshold: 1e6, MaxStringLength: 6}
+	text := `{
+  "description": "a \"quoted\" <text>",
+  "count": 1500000,
+  "price": -2500.25,
+  "distance": 12000000.5,
+  "tags": ["short", "much longer"]
+}`
+	expected := `{
+  "description": "a \"quo...",
+  "count": 1 500 000,
+  "price": -2 500.25,
+  "distance": 1.20000005e+07,
+  "tags": ["short", "much l..."]
+}`
+	assert.Equal(t, expected, format.JSON(text))
+	assert.Equal(t, text, ValueFormat{}.JSON(text))
+}
@@ -0,0 +1,47 @@
+package util
+
+import (
+	"strings"
+	"unicode"
+)
+
+// FuzzyMatch checks if all characters of the pattern appear in the text
+// in the same order (case insensitive). It returns a score that is higher
+// for consecutive matches and matches at the beginning of words.
+func FuzzyMatch(pattern, text string) (int, bool) {
+	if pattern == "" {
+		return 0, true
+	}
+
+	patternRunes := []rune(strings.ToLower(pattern))
+	textRunes := []rune(strings.ToLower(text))
+
+	score := 0
+	consecutive := 0
+	pIdx := 0
+	for tIdx, r := range textRunes {
+		if pIdx == len(patternRunes) {
+			break
+		}
+		if r != patternRunes[pIdx] {
+			consecutive = 0
+			continue
+		}
+
+		score++
+		if consecutive > 0 {
+			score += consecutive * 2
+		}
+		if tIdx == 0 || !unicode.IsLetter(textRunes[tIdx-1]) && !unicode.IsDigit(textRunes[tIdx-1]) {
+			score += 3
+		}
+		consecutive++
+		pIdx++
+	}
+
+	if pIdx < len(patternRunes) {
+		return 0, false
+	}
+
+	return score, true
+}
@@ -0,0 +1,39 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFuzzyMatch(t *testing.T) {
+	testCases := []struct {
+		name     string
+		pattern  string
+		text     string
+		expected bool
+	}{
+		{"Empty pattern", "", `{ "name": "John" }`, true},
+		{"Exact substring", "name", `{ "name": "John" }`, true},
+		{"Subsequence", "nmjhn", `{ "name": "John" }`, true},
+		{"Case insensitive", "JOHN", `{ "name": "john" }`, true},
+		{"Wrong order", "nhoj", `{ "name": "John" }`, false},
+		{"Missing character", "age", `{ "name": "John" }`, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, ok := FuzzyMatch(tc.pattern, tc.text)
+			assert.Equal(t, tc.expected, ok)
+		})
+	}
+}
+
+func TestFuzzyMatch_Score(t *testing.T) {
+	consecutive, ok := FuzzyMatch("name", `{ "name": 1 }`)
+	assert.True(t, ok)
+	scattered, ok := FuzzyMatch("name", `{ "n": 1, "a": 2, "m": 3, "e": 4 }`)
+	assert.True(t, ok)
+
+	assert.Greater(t, consecutive, scattered)
+}
@@ -14,7 +14,20 @@ func IsJsonEmpty(s string) bool {
 // CleanJsonWhitespaces removes new lines and redundant spaces from a JSON string
 // and also removes comma from the end of the string
 func CleanJsonWhitespaces(s string) string {
-	s = strings.TrimSuffix(s, ",")
+	return collapseJsonWhitespaces(strings.TrimSuffix(s, ","))
+}
+
+// NormalizePastedJson turns pasted multi-line JSON into a single line,
+// so it can be used in the input bars. Unlike CleanJsonWhitespaces it keeps
+// trailing comma, as pasted text can be a fragment of the query
+func NormalizePastedJson(s string) string {
+	s = strings.ReplaceAll(s, "\r", "\n")
+	return strings.TrimSpace(collapseJsonWhitespaces(s))
+}
+
+// collapseJsonWhitespaces replaces new lines, tabs and repeated spaces
+// with single space, except within quotes
+func collapseJsonWhitespaces(s string) string {
 	s = strings.ReplaceAll(s, "\n", " ")
 	s = strings.ReplaceAll(s, "\t", "")
 
@@ -41,3 +54,108 @@ func CleanJsonWhitespaces(s string) string {
 
 	return result.String()
 }
+
+// StripJsonWhitespaces removes all whitespaces from a JSON string,
+// except those within quotes, so semantically identical queries
+// can be compared
+func StripJsonWhitespaces(s string) string {
+	var result strings.Builder
+	inQuotes := false
+	prevChar := ' '
+
+	for _, char := range s {
+		if char == '"' && prevChar != '\\' {
+			inQuotes = !inQuotes
+		}
+
+		if inQuotes || !unicode.IsSpace(char) {
+			result.WriteRune(char)
+		}
+
+		prevChar = char
+	}
+
+	return result.String()
+}
+
+// FormatJson formats JSON-like query in a single line with consistent
+// spacing, e.g. `{ "name": "John", "age": { "$gt": 18 } }`.
+// Unquoted keys are quoted and the order of keys is preserved
+func FormatJson(s string) string {
+	s = MinifyJson(s)
+
+	var result strings.Builder
+	inQuotes := false
+	prevChar := ' '
+	runes := []rune(s)
+
+	for i, char := range runes {
+		if char == '"' && prevChar != '\\' {
+			inQuotes = !inQuotes
+		}
+		prevChar = char
+
+		if inQuotes {
+			result.WriteRune(char)
+			continue
+		}
+
+		var next rune
+		if i+1 < len(runes) {
+			next = runes[i+1]
+		}
+		switch char {
+		case '{', '[':
+			result.WriteRune(char)
+			if next != 0 && next != '}' && next != ']' {
+				result.WriteRune(' ')
+			}
+		case '}', ']':
+			if i > 0 && runes[i-1] != '{' && runes[i-1] != '[' {
+				result.WriteRune(' ')
+			}
+			result.WriteRune(char)
+		case ':', ',':
+			result.WriteRune(char)
+			result.WriteRune(' ')
+		default:
+			result.WriteRune(char)
+		}
+	}
+
+	return result.String()
+}
+
+// MinifyJson removes all whitespaces from JSON-like query, except within
+// quotes, and quotes unquoted keys
+func MinifyJson(s string) string {
+	return StripJsonWhitespaces(mapOutsideQuotes(StripJsonWhitespaces(s), QuoteUnquotedKeys))
+}
+
+// mapOutsideQuotes applies the function to parts of the string
+// which are not within quotes
+func mapOutsideQuotes(s string, fn func(string) string) string {
+	var result, part strings.Builder
+	inQuotes := false
+	prevChar := ' '
+
+	for _, char := range s {
+		if char == '"' && prevChar != '\\' {
+			if !inQuotes {
+				result.WriteString(fn(part.String()))
+				part.Reset()
+			}
+			inQuotes = !inQuotes
+		}
+		prevChar = char
+
+		if inQuotes || char == '"' {
+			result.WriteRune(char)
+		} else {
+			part.WriteRune(char)
+		}
+	}
+	result.WriteString(fn(part.String()))
+
+	return result.String()
+}
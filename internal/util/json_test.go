@@ -70,3 +70,73 @@ func TestCleanJsonWhitespaces(t *testing.T) {
 		})
 	}
 }
+
+func TestStripJsonWhitespaces(t *testing.T) {
+	testCases := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"Already stripped", `{"key":"value"}`, `{"key":"value"}`},
+		{"Spaces and newlines", "{ \"key\" :\n  \"value\" }", `{"key":"value"}`},
+		{"Preserve spaces in quotes", `{ "key 1": "value with spaces" }`, `{"key 1":"value with spaces"}`},
+		{"Escaped quotes", `{ "key": "a \" b " }`, `{"key":"a \" b "}`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result := StripJsonWhitespaces(tc.input)
+			assert.Equal(t, tc.expected, result)
+		})
+	}
+}
+
+func TestNormalizePastedJson(t *testing.T) {
+	testCases := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"Single line", `{"key": "value"}`, `{"key": "value"}`},
+		{"Multi-line with indentation", "{\n\t\"key\": \"value\",\n\t\"age\": 1\n}\n", `{ "key": "value", "age": 1 }`},
+		{"Windows new lines", "{\r\n  \"key\": 1\r\n}", `{ "key": 1 }`},
+		{"Keep trailing comma", "\"key\": 1,\n", `"key": 1,`},
+		{"Preserve spaces in quotes", "{ \"key\":   \"a   b\" }", `{ "key": "a   b" }`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result := NormalizePastedJson(tc.input)
+			assert.Equal(t, tc.expected, result)
+		})
+	}
+}
+
+func TestFormatJson(t *testing.T) {
+	testCases := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"Empty object", "{}", "{}"},
+		{"Compact", `{"name":"John","age":{"$gt":18}}`, `{ "name": "John", "age": { "$gt": 18 } }`},
+		{"Unquoted keys", `{name: "John", $or: [{a: 1}, {b: 2}]}`, `{ "name": "John", "$or": [ { "a": 1 }, { "b": 2 } ] }`},
+		{"Multi-line", "{\n  name:   \"John\",\n\n  tags: []\n}", `{ "name": "John", "tags": [] }`},
+		{"Key order is preserved", `{z: 1, a: 2, m: 3}`, `{ "z": 1, "a": 2, "m": 3 }`},
+		{"Spaces in quotes", `{"full name":"John  Doe, Jr: {x}"}`, `{ "full name": "John  Doe, Jr: {x}" }`},
+		{"ObjectID", `{_id:ObjectID("5f8f9e5f1c9d440000d1b3c5")}`, `{ "_id": ObjectID("5f8f9e5f1c9d440000d1b3c5") }`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result := FormatJson(tc.input)
+			assert.Equal(t, tc.expected, result)
+			assert.Equal(t, tc.expected, FormatJson(result), "formatting is idempotent")
+		})
+	}
+}
+
+func TestMinifyJson(t *testing.T) {
+	assert.Equal(t, `{"name":"John","age":{"$gt":18}}`, MinifyJson(`{ name: "John", age: { $gt: 18 } }`))
+	assert.Equal(t, `{"a":"x  y"}`, MinifyJson("{\n  \"a\" : \"x  y\"\n}"))
+}
@@ -1,6 +1,7 @@
 package util
 
 import (
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"sort"
@@ -20,8 +21,14 @@ const (
 	TypeObject   = "Object"
 	TypeMixed    = "Mixed"
 	TypeNull     = "Null"
+	TypeBinary   = "Binary"
+	// TypeEncrypted is a binary value encrypted with CSFLE or Queryable Encryption
+	TypeEncrypted = "Encrypted"
 )
 
+// encryptedSubtype is the binary subtype of encrypted values
+const encryptedSubtype = 6
+
 func GetSortedKeysWithTypes(documents []primitive.M, typeColor string) []string {
 	keys := make(map[string]string)
 	for _, doc := range documents {
@@ -34,6 +41,12 @@ func GetSortedKeysWithTypes(documents []primitive.M, typeColor string) []string
 		}
 	}
 
+	return FormatKeysWithTypes(keys, typeColor)
+}
+
+// FormatKeysWithTypes returns sorted keys followed by their types
+// in given color, e.g. "name [yellow]String"
+func FormatKeysWithTypes(keys map[string]string, typeColor string) []string {
 	// Sort the keys for consistent column order
 	sortedKeys := make([]string, 0, len(keys))
 	for k, t := range keys {
@@ -58,6 +71,11 @@ func GetValueByType(v interface{}) string {
 		return t.Hex()
 	case primitive.DateTime:
 		return t.Time().Format(time.RFC3339)
+	case primitive.Binary:
+		if t.Subtype == encryptedSubtype {
+			return "<encrypted>"
+		}
+		return base64.StdEncoding.EncodeToString(t.Data)
 	case primitive.A, primitive.D, primitive.M, map[string]interface{}, []interface{}:
 		b, _ := json.Marshal(t)
 		return string(b)
@@ -68,7 +86,7 @@ func GetValueByType(v interface{}) string {
 
 // Helper function to determine MongoDB type
 func GetMongoType(v interface{}) string {
-	switch v.(type) {
+	switch t := v.(type) {
 	case string:
 		return TypeString
 	case int, int32, int64:
@@ -81,6 +99,11 @@ func GetMongoType(v interface{}) string {
 		return TypeObjectId
 	case primitive.DateTime:
 		return TypeDate
+	case primitive.Binary:
+		if t.Subtype == encryptedSubtype {
+			return TypeEncrypted
+		}
+		return TypeBinary
 	case primitive.A:
 		return TypeArray
 	case primitive.D, primitive.M:
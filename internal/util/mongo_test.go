@@ -41,6 +41,8 @@ func TestGetValueByType(t *testing.T) {
 		{"DateTime", primitive.NewDateTimeFromTime(time.Now()), ""}, // Formatted time will be different
 		{"Array", primitive.A{"a", "b"}, `["a","b"]`},
 		{"Object", primitive.M{"key": "value"}, `{"key":"value"}`},
+		{"Binary", primitive.Binary{Subtype: 0, Data: []byte("vi")}, "dmk="},
+		{"Encrypted", primitive.Binary{Subtype: 6, Data: []byte("vi")}, "<encrypted>"},
 		{"Null", nil, "null"},
 	}
 
@@ -73,6 +75,8 @@ func TestGetMongoType(t *testing.T) {
 		{"DateTime", primitive.NewDateTimeFromTime(time.Now()), TypeDate},
 		{"Array", primitive.A{"a", "b"}, TypeArray},
 		{"Object", primitive.M{"key": "value"}, TypeObject},
+		{"Binary", primitive.Binary{Subtype: 0, Data: []byte{1}}, TypeBinary},
+		{"Encrypted", primitive.Binary{Subtype: 6, Data: []byte{1}}, TypeEncrypted},
 		{"Null", nil, TypeNull},
 	}
 
@@ -12,6 +12,8 @@ var (
 	unquotedKeysRegex   = regexp.MustCompile(`(\{|\,)\s*([a-zA-Z\d()!@#$%&*._]+)\s*:`)
 	multipleSpacesRegex = regexp.MustCompile(`\s+`)
 	uriPasswordRegex    = regexp.MustCompile(`://([^:]+):([^@]+)(@.*)`)
+	uriCredentialsRegex = regexp.MustCompile(`://([^:@/]+):([^@/]+)@`)
+	uriUsernameRegex    = regexp.MustCompile(`://([^:@/]+)@`)
 	hexColorRegex       = regexp.MustCompile(`^#(?:[0-9a-fA-F]{3}){1,2}$`)
 	dateRegex           = regexp.MustCompile(`\{\s*\"\$date\"\s*:\s*\"(.*?)\"\s*\}`)
 )
@@ -37,6 +39,26 @@ func HidePasswordInUri(s string) string {
 	return uriPasswordRegex.ReplaceAllString(s, "://$1:********$3")
 }
 
+// SplitPasswordFromUri returns the connection string without
+// the password and the password, empty if there is none
+func SplitPasswordFromUri(uri string) (string, string) {
+	match := uriCredentialsRegex.FindStringSubmatchIndex(uri)
+	if match == nil {
+		return uri, ""
+	}
+	return uri[:match[3]] + uri[match[5]:], uri[match[4]:match[5]]
+}
+
+// SetPasswordInUri puts the password after the username of the
+// connection string, which is returned as it is without the username
+func SetPasswordInUri(uri, password string) string {
+	match := uriUsernameRegex.FindStringSubmatchIndex(uri)
+	if match == nil {
+		return uri
+	}
+	return uri[:match[3]] + ":" + password + uri[match[3]:]
+}
+
 // ParseDateToBson parses a date in a JSON string into a BSON date
 func ParseDateToBson(s string) (string, error) {
 	var parseError error
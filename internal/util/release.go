@@ -0,0 +1,93 @@
+package util
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	// LatestReleaseURL is the GitHub API endpoint of the latest release
+	LatestReleaseURL = "https://api.github.com/repos/kopecmaciej/vi-mongo/releases/latest"
+	// InstallCommand installs the version given as the argument
+	InstallCommand = "go install github.com/kopecmaciej/vi-mongo@%s"
+)
+
+// Release is the published release of the app
+type Release struct {
+	Version   string `json:"tag_name"`
+	Changelog string `json:"body"`
+	URL       string `json:"html_url"`
+}
+
+// GetLatestRelease fetches the latest release from the GitHub API
+func GetLatestRelease(ctx context.Context, url string) (*Release, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status of the release check: %s", resp.Status)
+	}
+
+	var release Release
+	if err := json.NewDecoder(resp.Body).Decode(&release); err != nil {
+		return nil, err
+	}
+	if release.Version == "" {
+		return nil, fmt.Errorf("release without version")
+	}
+
+	return &release, nil
+}
+
+// IsNewerVersion returns true if latest is a newer version than current,
+// versions are compared as "v1.2.3", pre-release suffixes are ignored.
+// Versions that can't be parsed are never newer.
+func IsNewerVersion(current, latest string) bool {
+	currentParts, ok := parseVersion(current)
+	if !ok {
+		return false
+	}
+	latestParts, ok := parseVersion(latest)
+	if !ok {
+		return false
+	}
+
+	for i := range latestParts {
+		if latestParts[i] != currentParts[i] {
+			return latestParts[i] > currentParts[i]
+		}
+	}
+	return false
+}
+
+func parseVersion(version string) ([3]int, bool) {
+	var parts [3]int
+	version = strings.TrimPrefix(strings.TrimSpace(version), "v")
+	version, _, _ = strings.Cut(version, "-")
+
+	fields := strings.Split(version, ".")
+	if len(fields) == 0 || len(fields) > 3 {
+		return parts, false
+	}
+	for i, field := range fields {
+		n, err := strconv.Atoi(field)
+		if err != nil || n < 0 {
+			return parts, false
+		}
+		parts[i] = n
+	}
+	return parts, true
+}
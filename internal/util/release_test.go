@@ -0,0 +1,53 @@
+package util
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsNewerVersion(t *testing.T) {
+	tests := []struct {
+		current string
+		latest  string
+		want    bool
+	}{
+		{"v0.1.20", "v0.1.21", true},
+		{"v0.1.21", "v0.1.21", false},
+		{"v0.2.0", "v0.1.21", false},
+		{"v0.9.9", "v1.0.0", true},
+		{"0.1.2", "v0.1.10", true},
+		{"v1.0.0-rc1", "v1.0.0", false},
+		{"v1.2", "v1.2.1", true},
+		{"v0.0.0", "latest", false},
+		{"dev", "v1.0.0", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.current+"->"+tt.latest, func(t *testing.T) {
+			assert.Equal(t, tt.want, IsNewerVersion(tt.current, tt.latest))
+		})
+	}
+}
+
+func TestGetLatestRelease(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(`{"tag_name": "v1.2.3", "body": "## Fixes\n- faster", "html_url": "https://example.com/v1.2.3"}`))
+	}))
+	defer server.Close()
+
+	release, err := GetLatestRelease(context.Background(), server.URL+"/latest")
+	require.NoError(t, err)
+	assert.Equal(t, &Release{Version: "v1.2.3", Changelog: "## Fixes\n- faster", URL: "https://example.com/v1.2.3"}, release)
+
+	_, err = GetLatestRelease(context.Background(), server.URL+"/missing")
+	assert.Error(t, err)
+}
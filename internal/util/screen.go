@@ -0,0 +1,45 @@
+package util
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// ScreenText returns the content of the screen as plain text, colors
+// are dropped, box drawing characters are kept as they are shown.
+// Trailing spaces and empty lines at the bottom are trimmed.
+func ScreenText(screen tcell.Screen) string {
+	width, height := screen.Size()
+	lines := make([]string, 0, height)
+	for y := 0; y < height; y++ {
+		var line strings.Builder
+		for x := 0; x < width; x++ {
+			mainc, combc, _, cellWidth := screen.GetContent(x, y)
+			if mainc == 0 {
+				mainc = ' '
+			}
+			line.WriteRune(mainc)
+			for _, c := range combc {
+				line.WriteRune(c)
+			}
+			// wide characters, e.g. CJK, take the next cell too
+			if cellWidth > 1 {
+				x += cellWidth - 1
+			}
+		}
+		lines = append(lines, strings.TrimRight(line.String(), " "))
+	}
+
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// ScreenshotFileName returns the name of the file the screen is saved to
+func ScreenshotFileName(now time.Time) string {
+	return fmt.Sprintf("vi-mongo-screen-%s.txt", now.Format("20060102-150405"))
+}
@@ -0,0 +1,37 @@
+package util
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/uniseg"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScreenText(t *testing.T) {
+	screen := tcell.NewSimulationScreen("UTF-8")
+	require.NoError(t, screen.Init())
+	defer screen.Fini()
+	screen.SetSize(12, 4)
+
+	style := tcell.StyleDefault.Foreground(tcell.ColorRed)
+	put := func(x, y int, text string) {
+		for _, r := range text {
+			screen.SetContent(x, y, r, nil, style)
+			x += uniseg.StringWidth(string(r))
+		}
+	}
+	put(0, 0, "┌ users ─┐")
+	put(0, 1, "│顧客 1  │")
+	put(0, 2, "└────────┘")
+	screen.Show()
+
+	assert.Equal(t, "┌ users ─┐\n│顧客 1  │\n└────────┘\n", ScreenText(screen))
+}
+
+func TestScreenshotFileName(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC)
+	assert.Equal(t, "vi-mongo-screen-20240501-123000.txt", ScreenshotFileName(now))
+}
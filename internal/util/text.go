@@ -0,0 +1,134 @@
+package util
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/rivo/uniseg"
+)
+
+// TruncateByWidth truncates the string to the given display width and adds
+// "..." if it was truncated. Wide characters (e.g. CJK) take two cells and
+// are never cut in half, as slicing bytes would break multi-byte runes
+func TruncateByWidth(s string, width int) string {
+	if uniseg.StringWidth(s) <= width {
+		return s
+	}
+
+	var result strings.Builder
+	currentWidth := 0
+	graphemes := uniseg.NewGraphemes(s)
+	for graphemes.Next() {
+		if currentWidth+graphemes.Width() > width {
+			break
+		}
+		result.WriteString(graphemes.Str())
+		currentWidth += graphemes.Width()
+	}
+
+	return result.String() + "..."
+}
+
+// FormatBytes returns the size in the largest binary unit
+// in which it's at least 1, e.g. 1.5 GiB
+func FormatBytes(bytes int64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+	div, exp := int64(unit), 0
+	for n := bytes / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(bytes)/float64(div), "KMGTPE"[exp])
+}
+
+// FormatSeconds returns the duration in days, hours, minutes and
+// seconds, units which are 0 are left out, e.g. 1d 12h
+func FormatSeconds(seconds int64) string {
+	if seconds == 0 {
+		return "0s"
+	}
+	units := []struct {
+		suffix  string
+		seconds int64
+	}{{"d", 86400}, {"h", 3600}, {"m", 60}, {"s", 1}}
+	parts := []string{}
+	for _, unit := range units {
+		if n := seconds / unit.seconds; n > 0 {
+			parts = append(parts, fmt.Sprintf("%d%s", n, unit.suffix))
+			seconds -= n * unit.seconds
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// ColumnWidth splits the available width evenly between columns,
+// keeping the width of every column between min and max
+func ColumnWidth(available, columns, min, max int) int {
+	if columns <= 0 {
+		return max
+	}
+	width := available / columns
+	if width < min {
+		return min
+	}
+	if width > max {
+		return max
+	}
+	return width
+}
+
+// sparkBlocks are the bars of the sparkline, from the lowest to the highest
+var sparkBlocks = []rune("▁▂▃▄▅▆▇█")
+
+// Sparkline returns the chart of the last width values, every value is
+// a bar scaled between the lowest and the highest of the shown values
+func Sparkline(values []float64, width int) string {
+	if width <= 0 || len(values) == 0 {
+		return ""
+	}
+	if len(values) > width {
+		values = values[len(values)-width:]
+	}
+
+	low, high := values[0], values[0]
+	for _, v := range values {
+		low = min(low, v)
+		high = max(high, v)
+	}
+
+	var chart strings.Builder
+	for _, v := range values {
+		level := 0
+		if high > low {
+			level = int((v - low) / (high - low) * float64(len(sparkBlocks)-1))
+		}
+		chart.WriteRune(sparkBlocks[level])
+	}
+	return chart.String()
+}
+
+// Gauge returns a bar of given width filled in the ratio, e.g. "███░░░░"
+// for 0.4, ratio is clamped between 0 and 1
+func Gauge(ratio float64, width int) string {
+	if width <= 0 {
+		return ""
+	}
+	ratio = max(0, min(1, ratio))
+	filled := int(ratio*float64(width) + 0.5)
+	return strings.Repeat("█", filled) + strings.Repeat("░", width-filled)
+}
+
+// Describe joins parts of the description of an element into a single
+// line, which is read the same way it's shown, empty parts are skipped
+func Describe(parts ...string) string {
+	nonEmpty := make([]string, 0, len(parts))
+	for _, part := range parts {
+		if part = strings.TrimSpace(part); part != "" {
+			nonEmpty = append(nonEmpty, part)
+		}
+	}
+	return strings.Join(nonEmpty, ", ")
+}
@@ -0,0 +1,77 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTruncateByWidth(t *testing.T) {
+	testCases := []struct {
+		name     string
+		input    string
+		width    int
+		expected string
+	}{
+		{"Short ASCII", "users", 10, "users"},
+		{"Long ASCII", "collection_name", 10, "collection..."},
+		{"Exact width", "abcde", 5, "abcde"},
+		{"CJK fits", "顧客", 4, "顧客"},
+		{"CJK truncated", "顧客データ", 5, "顧客..."},
+		{"Polish letters", "zażółćgęśląjaźń", 6, "zażółć..."},
+		{"Combining mark is not split", "cafés", 4, "café..."},
+		{"Emoji", "🍕🍕🍕", 4, "🍕🍕..."},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, TruncateByWidth(tc.input, tc.width))
+		})
+	}
+}
+
+func TestFormatBytes(t *testing.T) {
+	assert.Equal(t, "0 B", FormatBytes(0))
+	assert.Equal(t, "1023 B", FormatBytes(1023))
+	assert.Equal(t, "1.0 KiB", FormatBytes(1024))
+	assert.Equal(t, "1.5 MiB", FormatBytes(1536*1024))
+	assert.Equal(t, "2.0 GiB", FormatBytes(2<<30))
+}
+
+func TestFormatSeconds(t *testing.T) {
+	assert.Equal(t, "0s", FormatSeconds(0))
+	assert.Equal(t, "45s", FormatSeconds(45))
+	assert.Equal(t, "1h", FormatSeconds(3600))
+	assert.Equal(t, "1d 12h", FormatSeconds(129600))
+	assert.Equal(t, "30d", FormatSeconds(2592000))
+}
+
+func TestColumnWidth(t *testing.T) {
+	assert.Equal(t, 20, ColumnWidth(100, 5, 10, 50))
+	assert.Equal(t, 10, ColumnWidth(100, 30, 10, 50))
+	assert.Equal(t, 50, ColumnWidth(300, 2, 10, 50))
+	assert.Equal(t, 50, ColumnWidth(300, 0, 10, 50))
+}
+
+func TestSparkline(t *testing.T) {
+	assert.Equal(t, "", Sparkline(nil, 10))
+	assert.Equal(t, "", Sparkline([]float64{1, 2}, 0))
+	assert.Equal(t, "▁▁▁", Sparkline([]float64{5, 5, 5}, 10))
+	assert.Equal(t, "▁▄█", Sparkline([]float64{0, 5, 10}, 10))
+	// only the last values which fit are shown
+	assert.Equal(t, "▁█", Sparkline([]float64{100, 0, 0, 10}, 2))
+}
+
+func TestGauge(t *testing.T) {
+	assert.Equal(t, "", Gauge(0.5, 0))
+	assert.Equal(t, "░░░░", Gauge(0, 4))
+	assert.Equal(t, "██░░", Gauge(0.5, 4))
+	assert.Equal(t, "████", Gauge(1.5, 4))
+	assert.Equal(t, "░░░░", Gauge(-1, 4))
+}
+
+func TestDescribe(t *testing.T) {
+	assert.Equal(t, "", Describe())
+	assert.Equal(t, "Document 1 of 3, name: John", Describe("Document 1 of 3", "name: John"))
+	assert.Equal(t, "Document 2 of 3, changed", Describe("Document 2 of 3", "", " changed "))
+}
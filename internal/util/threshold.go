@@ -0,0 +1,60 @@
+package util
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// thresholdOperators are checked longest first,
+// so ">=" is not read as ">"
+var thresholdOperators = []string{">=", "<=", "==", "!=", ">", "<"}
+
+// Threshold is a comparison of a count with the value, e.g. "> 0"
+type Threshold struct {
+	Op    string
+	Value int64
+}
+
+// ParseThreshold parses the threshold written as the operator and
+// the number, e.g. "> 0" or ">=100", a bare number means ">="
+func ParseThreshold(text string) (Threshold, error) {
+	text = strings.TrimSpace(text)
+	op := ">="
+	for _, candidate := range thresholdOperators {
+		if strings.HasPrefix(text, candidate) {
+			op = candidate
+			text = strings.TrimSpace(strings.TrimPrefix(text, candidate))
+			break
+		}
+	}
+
+	value, err := strconv.ParseInt(text, 10, 64)
+	if err != nil {
+		return Threshold{}, fmt.Errorf("threshold must be an operator and a number, e.g. \"> 0\", got %q", text)
+	}
+	return Threshold{Op: op, Value: value}, nil
+}
+
+// Crossed returns true if the count satisfies the threshold
+func (t Threshold) Crossed(count int64) bool {
+	switch t.Op {
+	case ">":
+		return count > t.Value
+	case ">=":
+		return count >= t.Value
+	case "<":
+		return count < t.Value
+	case "<=":
+		return count <= t.Value
+	case "==":
+		return count == t.Value
+	case "!=":
+		return count != t.Value
+	}
+	return false
+}
+
+func (t Threshold) String() string {
+	return fmt.Sprintf("%s %d", t.Op, t.Value)
+}
@@ -0,0 +1,41 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseThreshold(t *testing.T) {
+	tests := []struct {
+		text     string
+		expected Threshold
+	}{
+		{"> 0", Threshold{Op: ">", Value: 0}},
+		{">=100", Threshold{Op: ">=", Value: 100}},
+		{" != -1 ", Threshold{Op: "!=", Value: -1}},
+		{"5", Threshold{Op: ">=", Value: 5}},
+	}
+	for _, tt := range tests {
+		threshold, err := ParseThreshold(tt.text)
+		require.NoError(t, err, tt.text)
+		assert.Equal(t, tt.expected, threshold, tt.text)
+	}
+
+	_, err := ParseThreshold("> many")
+	assert.Error(t, err)
+	_, err = ParseThreshold("")
+	assert.Error(t, err)
+}
+
+func TestThresholdCrossed(t *testing.T) {
+	failed := Threshold{Op: ">", Value: 0}
+	assert.False(t, failed.Crossed(0))
+	assert.True(t, failed.Crossed(3))
+
+	low := Threshold{Op: "<=", Value: 10}
+	assert.True(t, low.Crossed(10))
+	assert.False(t, low.Crossed(11))
+	assert.Equal(t, "<= 10", low.String())
+}